@@ -0,0 +1,398 @@
+// Package config loads the settings of the server and of its subcommands, from the environment
+// and an optional config file, and validates them all before anything is opened, so that a
+// misconfigured instance fails at once with the report of all its invalid settings.
+//
+// The settings keep the names of their environment variables. The settings only read by a
+// component, e.g. PPI_ARCHIVE_DIR, are still read by it, and ExportFile makes those of the
+// config file visible to it as well.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/delivery"
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+)
+
+const (
+	// DefaultGCPProjectID is the project of BigQuery and Cloud Logging without PPI_PROJECT_ID.
+	DefaultGCPProjectID = "ppi-gcp-pj001"
+	// DatabaseName is the name of the MySQL and MongoDB databases.
+	DatabaseName = "central30"
+	// DefaultGRPCAddress is the address of the gRPC API without PPI_GRPC_ADDRESS.
+	DefaultGRPCAddress = ":4001"
+	// DefaultSubsystemProbeInterval is how often the optional subsystems are probed without
+	// PPI_SUBSYSTEM_PROBE_INTERVAL.
+	DefaultSubsystemProbeInterval = 60 * time.Second
+)
+
+// Lookup looks a setting up by the name of its environment variable, as os.LookupEnv.
+type Lookup func(key string) (string, bool)
+
+// Map returns the Lookup of fixed settings, e.g. to load a configuration in tests.
+func Map(settings map[string]string) Lookup {
+	return func(key string) (string, bool) {
+		v, ok := settings[key]
+		return v, ok
+	}
+}
+
+type Config struct {
+	GCP   GCP
+	MySQL MySQL
+	Mongo Mongo
+	// Neo4j is nil when it is not configured, which disables the DataDependency API.
+	Neo4j  *Neo4j
+	Server Server
+
+	// SeedEnabled makes the seed subcommand available. It must never be enabled in
+	// production as the generator may delete the projects it generates.
+	SeedEnabled bool
+	// AnonymizeEnabled makes the anonymize subcommand available. It must never be enabled in
+	// production as it rewrites the personal data in place.
+	AnonymizeEnabled bool
+	// FaultInjectionEnabled simulates faults on Mongo and Neo4j. It must never be enabled in
+	// production.
+	FaultInjectionEnabled bool
+}
+
+type GCP struct {
+	ProjectID           string
+	PublishLogDatasetID string
+}
+
+type MySQL struct {
+	User     string
+	Password string
+	Host     string
+	Port     string
+	Name     string
+	// ReplicaHosts are the read replicas, sharing the user, password and port of the primary.
+	ReplicaHosts []string
+	// TimeZone is the time zone of the datetimes of the database, UTC by default.
+	TimeZone *time.Location
+	// DevLegacyDSN is the legacy database of the local development environment, if any.
+	DevLegacyDSN string
+}
+
+type Mongo struct {
+	User     string
+	Password string
+	Host     string
+	Port     string
+	Name     string
+}
+
+// Neo4j holds the configuration details required to connect to a Neo4j database.
+type Neo4j struct {
+	URI      string
+	Username string
+	Password string
+}
+
+type Server struct {
+	BasePath               string
+	ErrorDocsURL           string
+	GRPCAddress            string
+	SubsystemProbeInterval time.Duration
+	APIDeprecations        []*delivery.APIDeprecation
+	JSONEncodings          map[int]delivery.JSONEncoding
+	IDMode                 repository.IDMode
+	TranscodeBackend       repository.TranscodeBackend
+
+	// TrustedProxies are the IPs and CIDRs of the proxies whose X-Forwarded-For headers give the
+	// client IP of the requests. The peer address is the client IP when there is none.
+	TrustedProxies []string
+}
+
+// Error reports all the invalid or missing settings of a configuration at once.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// loader collects the problems of the settings while they are read.
+type loader struct {
+	lookup   Lookup
+	problems []string
+}
+
+func (l *loader) get(key string) string {
+	v, _ := l.lookup(key)
+	return strings.TrimSpace(v)
+}
+
+func (l *loader) fail(key string, format string, args ...interface{}) {
+	l.problems = append(l.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (l *loader) bool(key string) bool {
+	v := l.get(key)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.fail(key, "%q is not a boolean", v)
+	}
+	return b
+}
+
+func (l *loader) port(key string) string {
+	v := l.get(key)
+	if v == "" {
+		return ""
+	}
+	if n, err := strconv.Atoi(v); err != nil || n < 1 || n > 65535 {
+		l.fail(key, "%q is not a port", v)
+	}
+	return v
+}
+
+func (l *loader) err() error {
+	if len(l.problems) == 0 {
+		return nil
+	}
+	return &Error{Problems: l.problems}
+}
+
+// Load reads the configuration with lookup and validates the format of its settings. The
+// settings required by a command are checked by Require.
+func Load(lookup Lookup) (*Config, error) {
+	l := &loader{lookup: lookup}
+	c := &Config{
+		GCP: GCP{
+			ProjectID:           l.get("PPI_PROJECT_ID"),
+			PublishLogDatasetID: l.get("PPI_PUBLISH_LOG_DATASET_ID"),
+		},
+		MySQL: MySQL{
+			User:         l.get("PPI_MYSQL_USER"),
+			Password:     l.get("PPI_MYSQL_PASSWORD"),
+			Host:         l.get("PPI_MYSQL_HOST"),
+			Port:         l.port("PPI_MYSQL_PORT"),
+			Name:         DatabaseName,
+			DevLegacyDSN: l.get("PPI_DEV_LEGACY_DB"),
+		},
+		Mongo: Mongo{
+			User:     l.get("PPI_MONGODB_USER"),
+			Password: l.get("PPI_MONGODB_PASSWORD"),
+			Host:     l.get("PPI_MONGODB_HOST"),
+			Port:     l.port("PPI_MONGODB_PORT"),
+			Name:     DatabaseName,
+		},
+		Server: Server{
+			ErrorDocsURL:           l.get("PPI_ERROR_DOCS_URL"),
+			GRPCAddress:            l.get("PPI_GRPC_ADDRESS"),
+			SubsystemProbeInterval: DefaultSubsystemProbeInterval,
+		},
+		SeedEnabled:           l.bool("PPI_SEED_ENABLED"),
+		AnonymizeEnabled:      l.bool("PPI_ANONYMIZE_ENABLED"),
+		FaultInjectionEnabled: l.bool("PPI_FAULT_INJECTION_ENABLED"),
+	}
+	if c.GCP.ProjectID == "" {
+		c.GCP.ProjectID = DefaultGCPProjectID
+	}
+	if c.Server.GRPCAddress == "" {
+		c.Server.GRPCAddress = DefaultGRPCAddress
+	}
+	for _, proxy := range strings.Split(l.get("PPI_TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				l.fail("PPI_TRUSTED_PROXIES", "%q is not an IP or a CIDR", proxy)
+				continue
+			}
+		}
+		c.Server.TrustedProxies = append(c.Server.TrustedProxies, proxy)
+	}
+	for _, host := range strings.Split(l.get("PPI_MYSQL_REPLICA_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			c.MySQL.ReplicaHosts = append(c.MySQL.ReplicaHosts, host)
+		}
+	}
+	var err error
+	c.MySQL.TimeZone, err = repository.ParseDBTimeZone(l.get("PPI_MYSQL_TIME_ZONE"))
+	if err != nil {
+		l.fail("PPI_MYSQL_TIME_ZONE", "%v", err)
+	}
+	c.Neo4j = loadNeo4j(l)
+
+	if v := l.get("PPI_SUBSYSTEM_PROBE_INTERVAL"); v != "" {
+		c.Server.SubsystemProbeInterval, err = time.ParseDuration(v)
+		if err != nil || c.Server.SubsystemProbeInterval <= 0 {
+			l.fail("PPI_SUBSYSTEM_PROBE_INTERVAL", "%q is not a positive duration", v)
+		}
+	}
+	if c.Server.BasePath, err = entity.ParseBasePath(l.get("PPI_BASE_PATH")); err != nil {
+		l.fail("PPI_BASE_PATH", "%v", err)
+	}
+	if v := c.Server.ErrorDocsURL; v != "" {
+		if _, err := url.Parse(v); err != nil {
+			l.fail("PPI_ERROR_DOCS_URL", "%v", err)
+		}
+	}
+	c.Server.APIDeprecations, err = delivery.ParseAPIDeprecations(l.get("PPI_API_DEPRECATIONS"))
+	if err != nil {
+		l.fail("PPI_API_DEPRECATIONS", "%v", err)
+	}
+	c.Server.JSONEncodings, err = delivery.ParseJSONEncodings(l.get("PPI_API_JSON_ENCODINGS"))
+	if err != nil {
+		l.fail("PPI_API_JSON_ENCODINGS", "%v", err)
+	}
+	if c.Server.IDMode, err = repository.ParseIDMode(l.get("PPI_ID_MODE")); err != nil {
+		l.fail("PPI_ID_MODE", "%v", err)
+	}
+	c.Server.TranscodeBackend, err = repository.ParseTranscodeBackend(
+		l.get("PPI_TRANSCODE_BACKEND"),
+	)
+	if err != nil {
+		l.fail("PPI_TRANSCODE_BACKEND", "%v", err)
+	}
+	if err := l.err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadNeo4j returns the configuration of Neo4j, or nil when none of its settings is given. A
+// partial configuration is reported rather than disabling Neo4j silently.
+func loadNeo4j(l *loader) *Neo4j {
+	c := &Neo4j{
+		URI:      l.get("NEO4J_URI"),
+		Username: l.get("NEO4J_USER"),
+		Password: l.get("NEO4J_PASSWORD"),
+	}
+	if c.URI == "" && c.Username == "" && c.Password == "" {
+		return nil
+	}
+	for _, setting := range [][2]string{
+		{"NEO4J_URI", c.URI},
+		{"NEO4J_USER", c.Username},
+		{"NEO4J_PASSWORD", c.Password},
+	} {
+		if setting[1] == "" {
+			l.fail(setting[0], "required with the other NEO4J_* settings")
+		}
+	}
+	if c.URI != "" {
+		u, err := url.Parse(c.URI)
+		switch {
+		case err != nil:
+			l.fail("NEO4J_URI", "%v", err)
+		case !strings.HasPrefix(u.Scheme, "neo4j") && !strings.HasPrefix(u.Scheme, "bolt"):
+			l.fail("NEO4J_URI", "%q is not a neo4j:// or bolt:// URI", c.URI)
+		}
+	}
+	return c
+}
+
+// Need is a part of the configuration a command cannot run without.
+type Need int
+
+const (
+	NeedMySQL Need = iota + 1
+	NeedMongo
+)
+
+// Require checks that the settings of the parts needed by a command are given.
+func (c *Config) Require(needs ...Need) error {
+	l := &loader{}
+	for _, need := range needs {
+		switch need {
+		case NeedMySQL:
+			if c.MySQL.User == "" {
+				l.fail("PPI_MYSQL_USER", "required")
+			}
+			if c.MySQL.Host == "" {
+				l.fail("PPI_MYSQL_HOST", "required")
+			}
+			if c.MySQL.Port == "" {
+				l.fail("PPI_MYSQL_PORT", "required")
+			}
+		case NeedMongo:
+			if c.Mongo.Host == "" {
+				l.fail("PPI_MONGODB_HOST", "required")
+			}
+			if c.Mongo.Port == "" {
+				l.fail("PPI_MONGODB_PORT", "required")
+			}
+		}
+	}
+	return l.err()
+}
+
+// ReadFile reads the settings of a config file, one KEY=VALUE line per setting named as its
+// environment variable. Blank lines and lines starting with # are skipped, and the values may
+// be quoted.
+func ReadFile(name string) (map[string]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	settings := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: not a KEY=VALUE line", name, n)
+		}
+		settings[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// unquote strips the double or single quotes around a value.
+func unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	first, last := value[0], value[len(value)-1]
+	if (first == '"' || first == '\'') && last == first {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// ExportFile sets the settings of a config file which are not set in the environment as
+// environment variables, so that the environment overrides the file and the components
+// reading their own settings see those of the file. An empty name exports nothing.
+func ExportFile(name string) error {
+	if name == "" {
+		return nil
+	}
+	settings, err := ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	for key, value := range settings {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
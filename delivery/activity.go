@@ -0,0 +1,78 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewActivity(
+	uc *usecase.Activity,
+) *Activity {
+	return &Activity{
+		uc: uc,
+	}
+}
+
+type Activity struct {
+	uc *usecase.Activity
+}
+
+type listActivitiesParams struct {
+	After   *time.Time `form:"after" time_format:"2006-01-02T15:04:05Z07:00"`
+	Types   *string    `form:"types"`
+	Actor   *string    `form:"actor"`
+	PerPage *int       `form:"per_page"`
+	Page    *int       `form:"page"`
+}
+
+// List is the activity feed of a project, newest first. `after` is an RFC 3339 time and
+// defaults to the start of the current day (UTC); `types` is a comma-separated list of
+// activity types.
+func (h *Activity) List(c *gin.Context) {
+	var p listActivitiesParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	after := time.Now().UTC().Truncate(24 * time.Hour)
+	if p.After != nil {
+		after = p.After.UTC()
+	}
+	var types []entity.ActivityType
+	if p.Types != nil {
+		for _, t := range splitCSV(*p.Types) {
+			types = append(types, entity.ActivityType(t))
+		}
+	}
+	params := &entity.ListActivitiesParams{
+		Project: c.Param("project"),
+		After:   after,
+		Types:   types,
+		Actor:   p.Actor,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	res := libs.CreateListResponse("activities", entities, c.Request, params, total)
+	c.PureJSON(http.StatusOK, res)
+}
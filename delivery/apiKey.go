@@ -0,0 +1,231 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewAPIKey(
+	uc *usecase.APIKey,
+) *APIKey {
+	return &APIKey{
+		uc: uc,
+	}
+}
+
+// APIKey authenticates the service accounts of pipeline scripts with their API keys, and
+// manages the keys, which is restricted to admins.
+type APIKey struct {
+	uc *usecase.APIKey
+}
+
+func apiKeyError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrUnauthorized) {
+		unauthorized(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		forbidden(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// apiKeyAdmin returns the studio of an admin, or responds with an error and returns false.
+func apiKeyAdmin(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf("%w: API keys can only be managed by admins", entity.ErrForbidden))
+		return "", false
+	}
+	return studio, true
+}
+
+// Authenticate is the middleware authenticating the requests with the entity.APIKeyHeader
+// header as the studio of their key, when the key may call the route. The name of the key is
+// the user whose roles are checked. It must run before the token middlewares, which skip the
+// requests it authenticated.
+func (h *APIKey) Authenticate(c *gin.Context) {
+	key := c.GetHeader(entity.APIKeyHeader)
+	if key == "" {
+		return
+	}
+	params := &entity.AuthenticateAPIKeyParams{
+		Key:     key,
+		Method:  c.Request.Method,
+		Path:    c.FullPath(),
+		Project: c.Param("project"),
+	}
+	e, err := h.uc.Authenticate(c.Request.Context(), params)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.Set(entity.APIKeyContextKey, e.ID)
+	c.Set("studio", e.Studio)
+	setUser(c, e.Name)
+}
+
+type listAPIKeysParams struct {
+	Studio  *string `form:"studio"`
+	Revoked bool    `form:"revoked"`
+}
+
+func (h *APIKey) List(c *gin.Context) {
+	if _, ok := apiKeyAdmin(c); !ok {
+		return
+	}
+	var p listAPIKeysParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListAPIKeysParams{
+		Studio:  p.Studio,
+		Revoked: p.Revoked,
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"api_keys": entities})
+}
+
+func (h *APIKey) Get(c *gin.Context) {
+	if _, ok := apiKeyAdmin(c); !ok {
+		return
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := h.uc.Get(c.Request.Context(), &entity.GetAPIKeyParams{ID: id})
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createAPIKeyParams struct {
+	Name         string                `json:"name"`
+	Studio       string                `json:"studio"`
+	Projects     []string              `json:"projects"`
+	Routes       []*entity.APIKeyRoute `json:"routes"`
+	ExpiresAtUTC *time.Time            `json:"expires_at_utc"`
+	CreatedBy    *string               `json:"created_by"`
+}
+
+// Post creates an API key acting as a studio on the given routes and, when projects are
+// given, only on those projects. The key is only returned in the response.
+func (h *APIKey) Post(c *gin.Context) {
+	studio, ok := apiKeyAdmin(c)
+	if !ok {
+		return
+	}
+	var p createAPIKeyParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateAPIKeyParams{
+		Name:         p.Name,
+		Studio:       p.Studio,
+		Projects:     p.Projects,
+		Routes:       p.Routes,
+		ExpiresAtUTC: p.ExpiresAtUTC,
+		CreatedBy:    studio,
+	}
+	if p.CreatedBy != nil {
+		params.CreatedBy = *p.CreatedBy
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type modifyAPIKeyParams struct {
+	ModifiedBy *string `json:"modified_by"`
+}
+
+// modifyParams reads the ID of the API key and the optional body of its rotation or
+// revocation.
+func (h *APIKey) modifyParams(c *gin.Context) (int32, string, bool) {
+	studio, ok := apiKeyAdmin(c)
+	if !ok {
+		return 0, "", false
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return 0, "", false
+	}
+	var p modifyAPIKeyParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return 0, "", false
+		}
+	}
+	if p.ModifiedBy != nil {
+		studio = *p.ModifiedBy
+	}
+	return id, studio, true
+}
+
+// Rotate replaces the key of an API key, rejecting the previous key at once. The new key is
+// only returned in the response.
+func (h *APIKey) Rotate(c *gin.Context) {
+	id, modifiedBy, ok := h.modifyParams(c)
+	if !ok {
+		return
+	}
+	e, err := h.uc.Rotate(c.Request.Context(), &entity.RotateAPIKeyParams{
+		ID:         id,
+		ModifiedBy: modifiedBy,
+	})
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.PureJSON(http.StatusOK, e)
+}
+
+// Delete revokes an API key, which is kept for the audit of its uses.
+func (h *APIKey) Delete(c *gin.Context) {
+	id, modifiedBy, ok := h.modifyParams(c)
+	if !ok {
+		return
+	}
+	e, err := h.uc.Revoke(c.Request.Context(), &entity.RevokeAPIKeyParams{
+		ID:         id,
+		ModifiedBy: modifiedBy,
+	})
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
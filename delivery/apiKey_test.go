@@ -0,0 +1,157 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	db := openTestDB(t)
+	studio := os.Getenv(testStudioEnv)
+	if studio == "" {
+		t.Skipf("%s is not set", testStudioEnv)
+	}
+	stuRepo, err := repository.NewStudioInfo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := repository.NewAPIKey(db, stuRepo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suffix := time.Now().UnixNano() % 1e12
+	project := fmt.Sprintf("test%d", suffix)
+	var ids []int32
+	t.Cleanup(func() {
+		if len(ids) != 0 {
+			db.Where("`id` IN ?", ids).Delete(&model.APIKey{})
+		}
+	})
+	create := func(name string, expiresAt *time.Time) *entity.APIKey {
+		t.Helper()
+		e, err := repo.Create(db, &entity.CreateAPIKeyParams{
+			Name:     fmt.Sprintf("%s%d", name, suffix),
+			Studio:   studio,
+			Projects: []string{project},
+			Routes: []*entity.APIKeyRoute{
+				{Method: http.MethodGet, Path: "/api/projects/:project/*"},
+			},
+			ExpiresAtUTC: expiresAt,
+			CreatedBy:    "test",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+		return e
+	}
+	valid := create("valid", nil)
+	revoked := create("revoked", nil)
+	if _, err := repo.Revoke(db, &entity.RevokeAPIKeyParams{
+		ID:         revoked.ID,
+		ModifiedBy: "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := time.Now().UTC().Add(-time.Minute)
+	expired := create("expired", &expiresAt)
+
+	h := NewAPIKey(usecase.NewAPIKey(repo, 10*time.Second, 10*time.Second))
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"studio": c.GetString("studio"),
+			"user":   requestUser(c),
+		})
+	}
+	r.GET("/api/projects/:project/reviewInfos", h.Authenticate, handler)
+	r.POST("/api/projects/:project/reviewInfos", h.Authenticate, handler)
+
+	reviews := "/api/projects/" + project + "/reviewInfos"
+	tests := []struct {
+		desc     string
+		key      string
+		method   string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "the route and the project are in the scope of the key",
+			key:      valid.Key,
+			method:   http.MethodGet,
+			path:     reviews,
+			wantCode: http.StatusOK,
+			wantBody: fmt.Sprintf(`{"studio":%q,"user":%q}`, studio, valid.Name),
+		},
+		{
+			desc:     "the method is out of the scope of the key",
+			key:      valid.Key,
+			method:   http.MethodPost,
+			path:     reviews,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			desc:     "the project is out of the scope of the key",
+			key:      valid.Key,
+			method:   http.MethodGet,
+			path:     "/api/projects/other" + project + "/reviewInfos",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			desc:     "the key is revoked",
+			key:      revoked.Key,
+			method:   http.MethodGet,
+			path:     reviews,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			desc:     "the key has expired",
+			key:      expired.Key,
+			method:   http.MethodGet,
+			path:     reviews,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			desc:     "the key is unknown",
+			key:      valid.Key + "x",
+			method:   http.MethodGet,
+			path:     reviews,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			desc:     "no key is left to the token middlewares",
+			method:   http.MethodGet,
+			path:     reviews,
+			wantCode: http.StatusOK,
+			wantBody: `{"studio":"","user":""}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(entity.APIKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", w.Body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API versions. Requests without a version are served as APIVersion1 so that tools pinned to
+// the original responses keep working. APIVersion3 is APIVersion2 with the JSON encoding of
+// the Python clients, see JSONEncoding.
+const (
+	APIVersion1      = 1
+	APIVersion2      = 2
+	APIVersion3      = 3
+	LatestAPIVersion = APIVersion3
+)
+
+const (
+	// APIVersionHeader selects the version of a request, as in "Accept-Version: 2".
+	APIVersionHeader = "Accept-Version"
+	// apiVersionResponseHeader reports the version a response was served with.
+	apiVersionResponseHeader = "API-Version"
+	apiVersionKey            = "apiVersion"
+)
+
+// APIDeprecation deprecates an endpoint, identified by its method and route pattern such as
+// "/api/projects/:project/reviews/assets", for the requests of MaxVersion and older.
+type APIDeprecation struct {
+	Method     string     `json:"method"`
+	Path       string     `json:"path"`
+	MaxVersion int        `json:"max_version"`
+	Since      *time.Time `json:"since"`
+	Sunset     *time.Time `json:"sunset"`
+	Link       string     `json:"link"`
+}
+
+// ParseAPIDeprecations parses the JSON array of deprecations given by the environment.
+func ParseAPIDeprecations(s string) ([]*APIDeprecation, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var deprecations []*APIDeprecation
+	if err := json.Unmarshal([]byte(s), &deprecations); err != nil {
+		return nil, fmt.Errorf("invalid API deprecations: %w", err)
+	}
+	for _, d := range deprecations {
+		if d.Method == "" || d.Path == "" {
+			return nil, fmt.Errorf("invalid API deprecation without method or path: %+v", d)
+		}
+		d.Method = strings.ToUpper(d.Method)
+		if d.MaxVersion == 0 {
+			d.MaxVersion = LatestAPIVersion
+		}
+	}
+	return deprecations, nil
+}
+
+// APIVersionUsage counts the requests of a version to an endpoint.
+type APIVersionUsage struct {
+	Version       int       `json:"version"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Client        string    `json:"client"`
+	Deprecated    bool      `json:"deprecated"`
+	Count         int64     `json:"count"`
+	LastUsedAtUTC time.Time `json:"last_used_at_utc"`
+}
+
+type apiVersionUsageKey struct {
+	version int
+	method  string
+	path    string
+	client  string
+}
+
+// APIVersioning negotiates the version of API requests, emits the deprecation headers of
+// deprecated endpoints and counts the requests per version. Counts are kept in memory since
+// the start of the instance.
+type APIVersioning struct {
+	deprecations map[string]*APIDeprecation
+	startedAt    time.Time
+
+	mu    sync.Mutex
+	usage map[apiVersionUsageKey]*APIVersionUsage
+}
+
+func NewAPIVersioning(deprecations []*APIDeprecation) *APIVersioning {
+	v := &APIVersioning{
+		deprecations: map[string]*APIDeprecation{},
+		startedAt:    time.Now().UTC(),
+		usage:        map[apiVersionUsageKey]*APIVersionUsage{},
+	}
+	for _, d := range deprecations {
+		v.deprecations[d.Method+" "+d.Path] = d
+	}
+	return v
+}
+
+// RewriteVersionedPath serves "/api/v<N>/..." as "/api/..." with the version N, so that every
+// endpoint is available under the versioned prefix without registering it twice.
+func RewriteVersionedPath(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, "/api/v")
+		if ok {
+			num, path, _ := strings.Cut(rest, "/")
+			if _, err := strconv.Atoi(num); err == nil {
+				r.Header.Set(APIVersionHeader, num)
+				prefix := "/api/v" + num
+				r.URL.Path = "/api/" + path
+				if r.URL.RawPath != "" {
+					r.URL.RawPath = "/api" + strings.TrimPrefix(r.URL.RawPath, prefix)
+				}
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func parseAPIVersion(s string) (int, error) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "v")
+	if s == "" {
+		return APIVersion1, nil
+	}
+	version, err := strconv.Atoi(s)
+	if err != nil || version < APIVersion1 || version > LatestAPIVersion {
+		return 0, fmt.Errorf(
+			"unsupported API version %q: versions 1 to %d are supported", s, LatestAPIVersion,
+		)
+	}
+	return version, nil
+}
+
+// RequestAPIVersion returns the negotiated version of the request.
+func RequestAPIVersion(c *gin.Context) int {
+	if version, ok := c.Get(apiVersionKey); ok {
+		return version.(int)
+	}
+	return APIVersion1
+}
+
+// Negotiate is the middleware resolving the version of a request.
+func (v *APIVersioning) Negotiate(c *gin.Context) {
+	version, err := parseAPIVersion(c.GetHeader(APIVersionHeader))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	c.Set(apiVersionKey, version)
+	c.Header(apiVersionResponseHeader, strconv.Itoa(version))
+
+	d, deprecated := v.deprecations[c.Request.Method+" "+c.FullPath()]
+	deprecated = deprecated && version <= d.MaxVersion
+	if deprecated {
+		if d.Since != nil {
+			c.Header("Deprecation", fmt.Sprintf("@%d", d.Since.Unix()))
+		} else {
+			c.Header("Deprecation", "true")
+		}
+		if d.Sunset != nil {
+			c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Link != "" {
+			c.Header("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+		}
+	}
+	if c.FullPath() != "" {
+		v.count(version, c.Request.Method, c.FullPath(), c.Request.UserAgent(), deprecated)
+	}
+	c.Next()
+}
+
+func (v *APIVersioning) count(version int, method, path, client string, deprecated bool) {
+	key := apiVersionUsageKey{version: version, method: method, path: path, client: client}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	u, ok := v.usage[key]
+	if !ok {
+		u = &APIVersionUsage{
+			Version:    version,
+			Method:     method,
+			Path:       path,
+			Client:     client,
+			Deprecated: deprecated,
+		}
+		v.usage[key] = u
+	}
+	u.Count++
+	u.LastUsedAtUTC = time.Now().UTC()
+}
+
+// ListUsage reports the requests per version, endpoint and client since the instance started.
+// The "deprecated" query parameter narrows it down to the requests to deprecated endpoints.
+func (v *APIVersioning) ListUsage(c *gin.Context) {
+	deprecatedOnly := c.Query("deprecated") == "true"
+	v.mu.Lock()
+	usage := make([]APIVersionUsage, 0, len(v.usage))
+	for _, u := range v.usage {
+		if deprecatedOnly && !u.Deprecated {
+			continue
+		}
+		usage = append(usage, *u)
+	}
+	v.mu.Unlock()
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Path != usage[j].Path {
+			return usage[i].Path < usage[j].Path
+		}
+		if usage[i].Method != usage[j].Method {
+			return usage[i].Method < usage[j].Method
+		}
+		if usage[i].Version != usage[j].Version {
+			return usage[i].Version < usage[j].Version
+		}
+		return usage[i].Client < usage[j].Client
+	})
+
+	deprecations := make([]*APIDeprecation, 0, len(v.deprecations))
+	for _, d := range v.deprecations {
+		deprecations = append(deprecations, d)
+	}
+	sort.Slice(deprecations, func(i, j int) bool {
+		return deprecations[i].Method+" "+deprecations[i].Path <
+			deprecations[j].Method+" "+deprecations[j].Path
+	})
+
+	c.PureJSON(http.StatusOK, gin.H{
+		"latest_version": LatestAPIVersion,
+		"since_utc":      v.startedAt,
+		"deprecations":   deprecations,
+		"usage":          usage,
+	})
+}
@@ -0,0 +1,150 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewArchive(
+	uc *usecase.Archive,
+) *Archive {
+	return &Archive{
+		uc: uc,
+	}
+}
+
+// Archive serves the archives of whole projects, which are restricted to admins as they hold
+// all the data of the projects.
+type Archive struct {
+	uc *usecase.Archive
+}
+
+func archiveError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		log.Println("ERROR:", err)
+		c.AbortWithStatusJSON(http.StatusConflict, errorBody(http.StatusConflict, err))
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// admin tells whether the studio of the request is an admin one, and responds 403 otherwise.
+func (h *Archive) admin(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, errors.New("the archives of the projects are restricted to admins"))
+		return studio, false
+	}
+	return studio, true
+}
+
+func (h *Archive) List(c *gin.Context) {
+	if _, ok := h.admin(c); !ok {
+		return
+	}
+	params := &entity.ListArchiveJobsParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		archiveError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"archives": entities})
+}
+
+func (h *Archive) Get(c *gin.Context) {
+	if _, ok := h.admin(c); !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetArchiveJobParams{
+		Project: c.Param("project"),
+		ID:      int32(id),
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		archiveError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createArchiveJobParams struct {
+	CreatedBy *string `json:"created_by"`
+}
+
+// Post queues the archive of a project, the progress of which is polled with Get. The body is
+// optional and the creator defaults to the studio of the request.
+func (h *Archive) Post(c *gin.Context) {
+	studio, ok := h.admin(c)
+	if !ok {
+		return
+	}
+	var p createArchiveJobParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.CreateArchiveJobParams{
+		Project:   c.Param("project"),
+		CreatedBy: studio,
+	}
+	if p.CreatedBy != nil {
+		params.CreatedBy = *p.CreatedBy
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		archiveError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusAccepted, e)
+}
+
+// Download serves the bundle of a completed archive job.
+func (h *Archive) Download(c *gin.Context) {
+	if _, ok := h.admin(c); !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetArchiveJobParams{
+		Project: c.Param("project"),
+		ID:      int32(id),
+	}
+	e, f, err := h.uc.Open(c.Request.Context(), params)
+	if err != nil {
+		archiveError(c, err)
+		return
+	}
+	defer f.Close()
+	fileName := fmt.Sprintf("archive_%s_%d.tar.gz", e.Project, e.ID)
+	c.DataFromReader(http.StatusOK, e.Size, "application/gzip", f, map[string]string{
+		"Content-Disposition": "attachment;filename=" + fileName,
+	})
+}
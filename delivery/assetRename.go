@@ -0,0 +1,107 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewAssetRename(
+	uc *usecase.AssetRename,
+) *AssetRename {
+	return &AssetRename{
+		uc: uc,
+	}
+}
+
+type AssetRename struct {
+	uc *usecase.AssetRename
+}
+
+func assetRenameError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+type listAssetRenamesParams struct {
+	Asset *string `form:"asset"`
+}
+
+// List lists the renames of the assets of a project, latest first.
+func (h *AssetRename) List(c *gin.Context) {
+	var p listAssetRenamesParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListAssetRenamesParams{
+		Project: c.Param("project"),
+		Asset:   p.Asset,
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		assetRenameError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"renames": entities})
+}
+
+type renameAssetParams struct {
+	Root      *string `json:"root"`
+	From      string  `json:"from" binding:"required"`
+	To        string  `json:"to" binding:"required"`
+	DryRun    bool    `json:"dry_run"`
+	CreatedBy *string `json:"created_by"`
+}
+
+// Rename moves the history of an asset to its new name in the assets root unless `root` is
+// given. It is restricted to admins. A dry run responds the changes without applying them.
+func (h *AssetRename) Rename(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf("%w: assets can only be renamed by admins", entity.ErrForbidden))
+		return
+	}
+	var p renameAssetParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.RenameAssetParams{
+		Project:   c.Param("project"),
+		Root:      "assets",
+		From:      p.From,
+		To:        p.To,
+		DryRun:    p.DryRun,
+		Studio:    studio,
+		CreatedBy: studio,
+	}
+	if p.Root != nil {
+		params.Root = *p.Root
+	}
+	if p.CreatedBy != nil {
+		params.CreatedBy = *p.CreatedBy
+	}
+	e, err := h.uc.Rename(c.Request.Context(), params)
+	if err != nil {
+		assetRenameError(c, err)
+		return
+	}
+	if e.DryRun {
+		c.PureJSON(http.StatusOK, e)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
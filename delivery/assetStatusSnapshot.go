@@ -0,0 +1,128 @@
+package delivery
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewAssetStatusSnapshot(
+	uc *usecase.AssetStatusSnapshot,
+) *AssetStatusSnapshot {
+	return &AssetStatusSnapshot{
+		uc: uc,
+	}
+}
+
+type AssetStatusSnapshot struct {
+	uc *usecase.AssetStatusSnapshot
+}
+
+type getPivotDiffParams struct {
+	From   string  `form:"from" binding:"required"`
+	To     *string `form:"to"`
+	Format *string `form:"format"`
+}
+
+// Diff is the change of the phase statuses of the assets of a project between the snapshots
+// of `from` and `to`, as JSON or CSV according to `format`. The dates are formatted as
+// YYYY-MM-DD; `to` defaults to today.
+func (h *AssetStatusSnapshot) Diff(c *gin.Context) {
+	var p getPivotDiffParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	from, err := time.Parse("2006-01-02", p.From)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if p.To != nil {
+		t, err := time.Parse("2006-01-02", *p.To)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		to = t
+	}
+	params := &entity.GetPivotDiffParams{
+		Project: c.Param("project"),
+		From:    from,
+		To:      to,
+		Format:  entity.PivotDiffFormatJSON,
+	}
+	if p.Format != nil {
+		params.Format = *p.Format
+	}
+	diff, err := h.uc.Diff(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	if params.Format != entity.PivotDiffFormatCSV {
+		c.PureJSON(http.StatusOK, diff)
+		return
+	}
+	fileName := fmt.Sprintf("pivotDiff_%s_%s_%s", diff.Project, diff.From, diff.To)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment;filename="+fileName+".csv")
+	writer := csv.NewWriter(c.Writer)
+	defer writer.Flush()
+	if err := writer.WriteAll(pivotDiffRecords(diff)); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to generate CSV")
+	}
+}
+
+// pivotDiffRecords lists a phase per row, the regressions being the changed phases marked so.
+func pivotDiffRecords(diff *entity.PivotDiff) [][]string {
+	records := [][]string{
+		{
+			"change", "group", "relation", "phase",
+			"from_approval_status", "to_approval_status",
+			"from_work_status", "to_work_status",
+			"from_take", "to_take",
+		},
+	}
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	add := func(kind string, assets []*entity.PivotDiffAsset) {
+		for _, a := range assets {
+			for _, p := range a.Phases {
+				change := kind
+				if p.Regression {
+					change = "regression"
+				}
+				records = append(records, []string{
+					change, a.Group, a.Relation, p.Phase,
+					value(p.FromApprovalStatus), p.ToApprovalStatus,
+					value(p.FromWorkStatus), p.ToWorkStatus,
+					value(p.FromTake), p.ToTake,
+				})
+			}
+		}
+	}
+	add("added", diff.Added)
+	add("changed", diff.Changed)
+	return records
+}
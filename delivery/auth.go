@@ -0,0 +1,577 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type Auth struct {
+	uc     *usecase.Auth
+	roles  *Role
+	routes func() gin.RoutesInfo
+}
+
+// NewAuth returns the Auth delivery. routes returns the routes registered on the router, from
+// which the permissions of tokens are expanded with the permissions roles register them with.
+func NewAuth(uc *usecase.Auth, roles *Role, routes func() gin.RoutesInfo) *Auth {
+	return &Auth{
+		uc:     uc,
+		roles:  roles,
+		routes: routes,
+	}
+}
+
+/********************* Token Authentication Handlers *********************/
+
+// middleware to parse token placed in authrorization header
+func (d *Auth) ParseHeaderToken(c *gin.Context) {
+	req := c.Request
+	if strings.HasPrefix(req.URL.Path, "/api/auth/login") ||
+		strings.HasPrefix(req.URL.Path, "/api/auth/oidc/") ||
+		strings.HasPrefix(req.URL.Path, "/api/setting/rc1") ||
+		req.URL.Path == "/api/compat" ||
+		req.URL.Path == "/api/openapi.json" ||
+		req.URL.Path == "/api/docs" {
+		return
+	}
+	// authenticated by its API key
+	if _, ok := c.Get(entity.APIKeyContextKey); ok {
+		return
+	}
+	params := &entity.StudioAuthParams{
+		Project:    c.Param("project"),
+		AuthHeader: req.Header.Get(entity.AuthHeader),
+	}
+	token, err := d.uc.ParseHeaderToken(req.Context(), params)
+	if err != nil && !entity.SkipAuth {
+		if errors.Is(err, entity.ErrUnauthorized) {
+			tokenUnauthorized(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			forbidden(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	var name, user string
+	if token != nil {
+		name, user = token.Studio, token.User
+	}
+	c.Set("studio", name)
+	setUser(c, user)
+}
+
+// setUser sets the authenticated user of the request, and responds with 403 and returns false
+// when the entity.UserHeader header names another user. The header is only trusted when the
+// authentication is skipped.
+func setUser(c *gin.Context, user string) bool {
+	header := c.GetHeader(entity.UserHeader)
+	if entity.SkipAuth && user == "" {
+		user = header
+	}
+	if header != "" && header != user {
+		forbidden(c, fmt.Errorf(
+			"%w: the %s header %q is not the authenticated user",
+			entity.ErrForbidden, entity.UserHeader, header,
+		))
+		return false
+	}
+	if user != "" {
+		c.Set(entity.UserContextKey, user)
+	}
+	return true
+}
+
+// requestUser returns the authenticated user of the request, which is empty when the request
+// is only authenticated as a studio.
+func requestUser(c *gin.Context) string {
+	name, _ := c.Get(entity.UserContextKey)
+	user, _ := name.(string)
+	return user
+}
+
+// middleware to parse token in query parameter
+func (d *Auth) ParseQueryToken(c *gin.Context) {
+	tokenStr := c.Query(entity.QueryToken)
+	if tokenStr == "" {
+		return
+	}
+	env := ""
+	if os.Getenv(entity.RunEnv) == entity.LocalEnv {
+		env = entity.Localdev
+	}
+	token, err := d.uc.ParseQueryToken(c.Request.Context(), tokenStr)
+	if !entity.SkipAuth && err != nil {
+		if !errors.Is(err, entity.ErrUnauthorized) {
+			internalServerError(c, err)
+			return
+		}
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			tokenUnauthorized(c, err)
+			return
+		}
+		log.Println("ERROR:", err)
+		c.Redirect(
+			http.StatusSeeOther,
+			env+entity.PublicPath("/login?error="+url.QueryEscape(entity.TokenErrorCode(err))),
+		)
+		c.Abort()
+		return
+	}
+	var name, user string
+	if token != nil {
+		name, user = token.Studio, token.User
+	}
+	newToken, err := d.uc.CreateNewToken(name, user)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, env+entity.PublicPath("/login"))
+		c.Abort()
+		return
+	}
+	c.Redirect(
+		http.StatusSeeOther,
+		env+entity.PublicPath(c.Request.URL.Path)+"?"+entity.QueryToken+"="+newToken,
+	)
+	c.Abort()
+}
+
+// tokenUnauthorized responds to an authentication failure with an error code telling the
+// client whether the token is invalid, expired or replayed.
+func tokenUnauthorized(c *gin.Context, err error) {
+	log.Println("ERROR:", err)
+	code := entity.TokenErrorCode(err)
+	body := errorBody(http.StatusUnauthorized, err)
+	body["code"] = code
+	if docs := errorDocs(code); docs != "" {
+		body["docs"] = docs
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, body)
+}
+
+/********************* Project/Studio Access Handler *********************/
+func (d *Auth) CheckAccessPermission(c *gin.Context) {
+	req := c.Request
+	if entity.SkipAuth ||
+		!strings.Contains(req.URL.Path, "/projects") &&
+			!strings.Contains(req.URL.Path, "/studios") {
+		return
+	}
+	name, ok := c.Get("studio")
+	if !ok {
+		unauthorized(c, entity.ErrUnauthorized)
+		return
+	}
+	nameStr, ok := name.(string)
+	if !ok {
+		badRequest(c, entity.ErrBadRequest)
+		return
+	}
+
+	// check access permission on studios
+	if strings.Contains(req.URL.Path, "/studios") {
+
+		if strings.HasPrefix(req.URL.Path, "/api/studios") {
+			// StudioInfo API
+			if !isAdminStudio(nameStr) {
+				switch req.Method {
+				case "GET":
+					if req.URL.Path == "/api/studios" {
+						return
+					}
+					if nameStr != c.Param("studio") {
+						forbidden(c, entity.ErrForbidden)
+					}
+				default:
+					forbidden(c, entity.ErrForbidden)
+				}
+			}
+			return
+		}
+
+		// Other APIs, mainly for PipelineSetting API
+		if !isAdminStudio(nameStr) && nameStr != c.Param("studio") {
+			forbidden(c, entity.ErrForbidden)
+			return
+		}
+	}
+
+	// check access permission on projects
+	if strings.Contains(req.URL.Path, "/projects") {
+		switch req.URL.Path {
+		case "/api/projects":
+			switch req.Method {
+			case "GET":
+				return
+			case "POST":
+				if nameStr != "ppi" && nameStr != "ppidev" {
+					forbidden(c, entity.ErrForbidden)
+				}
+				return
+			default:
+				return
+			}
+		default:
+			params := &entity.ProjectAccessParams{
+				Studio:  nameStr,
+				Project: c.Param("project"),
+			}
+			if err := d.uc.CheckProjectAccess(req.Context(), params); err != nil {
+				if errors.Is(err, entity.ErrUnauthorized) {
+					unauthorized(c, err)
+					return
+				}
+				if errors.Is(err, entity.ErrForbidden) {
+					forbidden(c, err)
+					return
+				}
+				if errors.Is(err, entity.ErrBadRequest) {
+					badRequest(c, err)
+					return
+				}
+				internalServerError(c, err)
+				return
+			}
+		}
+	}
+}
+
+func isAdminStudio(studio string) bool {
+	return studio == "ppi" || studio == "ppidev"
+}
+
+// routePermission returns the permission of the scope on the route, or nil when the route is
+// forbidden to it. It applies the rules of CheckAccessPermission to a route pattern instead of
+// a request, so both must be kept in sync, then the routes and projects of the API key and
+// the requirement the route was registered with by Role.Handle.
+func routePermission(
+	scope *entity.TokenScope,
+	requirement *entity.PermissionRequirement,
+	method string,
+	path string,
+) *entity.RoutePermission {
+	if scope.APIKey != nil && !slices.ContainsFunc(
+		scope.APIKey.Routes,
+		func(r *entity.APIKeyRoute) bool { return r.Matches(method, path) },
+	) {
+		return nil
+	}
+	p := &entity.RoutePermission{
+		Method: method,
+		Path:   path,
+	}
+	studio := scope.Studio
+	projects := scope.Projects
+	admin := isAdminStudio(studio)
+	// the handlers of the admin API check it themselves
+	if strings.HasPrefix(path, "/api/admin/") && !admin {
+		return nil
+	}
+	if !strings.Contains(path, "/projects") && !strings.Contains(path, "/studios") {
+		return p
+	}
+
+	if strings.Contains(path, "/studios") {
+		if strings.HasPrefix(path, "/api/studios") {
+			if !admin {
+				if method != http.MethodGet {
+					return nil
+				}
+				if path != "/api/studios" {
+					if !hasRouteParam(path, "studio") {
+						return nil
+					}
+					p.Studios = []string{studio}
+				}
+			}
+			return p
+		}
+		if !admin {
+			if !hasRouteParam(path, "studio") {
+				return nil
+			}
+			p.Studios = []string{studio}
+		}
+	}
+
+	if strings.Contains(path, "/projects") {
+		if path == "/api/projects" {
+			if method == http.MethodPost && !admin {
+				return nil
+			}
+			return p
+		}
+		if !hasRouteParam(path, "project") || len(projects) == 0 {
+			return nil
+		}
+		p.Projects = projects
+		if requirement != nil && !admin {
+			permitted := permittedProjects(scope, requirement.Permission)
+			p.Permission = requirement.Permission
+			if len(requirement.Fields) == 0 {
+				if len(permitted) == 0 {
+					return nil
+				}
+				p.Projects = permitted
+			} else {
+				p.Fields = requirement.Fields
+				p.FieldProjects = permitted
+			}
+		}
+	}
+	return p
+}
+
+// permittedProjects returns the projects of the scope whose roles grant the permission to its
+// user, or which do not enforce roles.
+func permittedProjects(scope *entity.TokenScope, permission entity.Permission) []string {
+	var projects []string
+	for _, project := range scope.Projects {
+		permissions, enforced := scope.Enforced[project]
+		if enforced && !slices.Contains(permissions, permission) {
+			continue
+		}
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+func hasRouteParam(path, name string) bool {
+	for _, s := range strings.Split(path, "/") {
+		if s == ":"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTokenPermissions lists the API routes and methods the token, or the API key of the
+// request, is permitted to call, with the projects and studios they are restricted to.
+func (d *Auth) GetTokenPermissions(c *gin.Context) {
+	req := c.Request
+	params := &entity.GetTokenPermissionsParams{
+		ID:         c.Param("id"),
+		AuthHeader: req.Header.Get(entity.AuthHeader),
+	}
+	if id, ok := c.Get(entity.APIKeyContextKey); ok {
+		if keyID, ok := id.(int32); ok {
+			params.APIKeyID = &keyID
+		}
+	}
+	scope, err := d.uc.GetTokenPermissions(req.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrUnauthorized) {
+			tokenUnauthorized(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			forbidden(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	routes := []*entity.RoutePermission{}
+	for _, r := range d.routes() {
+		if !strings.HasPrefix(r.Path, "/api/") {
+			continue
+		}
+		requirement := d.roles.Requirement(r.Method, r.Path)
+		if p := routePermission(scope, requirement, r.Method, r.Path); p != nil {
+			routes = append(routes, p)
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	c.JSON(http.StatusOK, &entity.TokenPermissions{
+		Token:  scope.Token,
+		APIKey: scope.APIKey,
+		Admin:  isAdminStudio(scope.Studio),
+		Routes: routes,
+	})
+}
+
+func (d *Auth) CreateNewToken(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/auth/login") ||
+		strings.HasPrefix(c.Request.URL.Path, "/api/auth/oidc/") ||
+		strings.HasPrefix(c.Request.URL.Path, "/api/setting/rc1") ||
+		c.Request.URL.Path == "/api/compat" ||
+		c.Request.URL.Path == "/api/openapi.json" ||
+		c.Request.URL.Path == "/api/docs" {
+		return
+	}
+	// API keys are not exchanged for tokens
+	if _, ok := c.Get(entity.APIKeyContextKey); ok {
+		return
+	}
+	name, ok := c.Get("studio")
+	if !entity.SkipAuth && !ok {
+		unauthorized(c, entity.ErrUnauthorized)
+		return
+	}
+	if entity.SkipAuth && name == "" {
+		name = "skipauth"
+	}
+	nameStr, ok := name.(string)
+	if !ok {
+		badRequest(c, entity.ErrBadRequest)
+	}
+	token, err := d.uc.CreateNewToken(nameStr, requestUser(c))
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.Header("WWW-Authenticate", token)
+	rawstudio, ok := c.Get("studio")
+	if !ok {
+		return
+	}
+	studio, ok := rawstudio.(string)
+	if !ok {
+		return
+	}
+	c.Header("Studio", studio)
+}
+
+/********************* Login Handler *********************/
+
+type loginParams struct {
+	Studio   string `json:"studio"`
+	Password string `json:"pass"`
+}
+
+func (p *loginParams) Entity() *entity.LoginParams {
+	return &entity.LoginParams{
+		Studio:   p.Studio,
+		Password: p.Password,
+	}
+}
+
+// API handler for login function
+func (d *Auth) Login(c *gin.Context) {
+	var p loginParams
+	if err := bindBody(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := p.Entity()
+	name, err := d.uc.Login(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrUnauthorized) {
+			unauthorized(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	token, err := d.uc.CreateNewToken(name, "")
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.Header("WWW-Authenticate", token)
+}
+
+/********************* OIDC Login Handlers *********************/
+
+// oidcReturnTo returns the path of this site to return the user to after the login, the top
+// page when it is not one.
+func oidcReturnTo(raw string) string {
+	if !strings.HasPrefix(raw, "/") || strings.HasPrefix(raw, "//") ||
+		strings.HasPrefix(raw, "/\\") {
+		return entity.PublicPath("/")
+	}
+	return raw
+}
+
+// OIDCLogin redirects the user to log in with the OpenID Connect provider, which returns them
+// to OIDCCallback.
+func (d *Auth) OIDCLogin(c *gin.Context) {
+	authURL, state, err := d.uc.OIDCLogin(c.Request.Context(), oidcReturnTo(c.Query("return_to")))
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		entity.OIDCStateCookie, state, 600, entity.PublicPath("/api/auth/oidc/"), "",
+		os.Getenv(entity.RunEnv) != entity.LocalEnv, true,
+	)
+	c.Redirect(http.StatusSeeOther, authURL)
+}
+
+// OIDCCallback logs in the user returned by the OpenID Connect provider, and returns them to
+// the page they logged in from with a query token of their studio, as the token login does.
+func (d *Auth) OIDCCallback(c *gin.Context) {
+	env := ""
+	if os.Getenv(entity.RunEnv) == entity.LocalEnv {
+		env = entity.Localdev
+	}
+	state, _ := c.Cookie(entity.OIDCStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		entity.OIDCStateCookie, "", -1, entity.PublicPath("/api/auth/oidc/"), "",
+		os.Getenv(entity.RunEnv) != entity.LocalEnv, true,
+	)
+	if e := c.Query("error"); e != "" {
+		log.Printf("ERROR: OIDC login: %s: %s", e, c.Query("error_description"))
+		c.Redirect(
+			http.StatusSeeOther, env+entity.PublicPath("/login?error="+url.QueryEscape(entity.TokenErrorOIDC)),
+		)
+		return
+	}
+	token, returnTo, err := d.uc.OIDCCallback(c.Request.Context(), &entity.OIDCCallbackParams{
+		Code:        c.Query("code"),
+		State:       c.Query("state"),
+		StateCookie: state,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		log.Println("ERROR: OIDC login:", err)
+		c.Redirect(
+			http.StatusSeeOther, env+entity.PublicPath("/login?error="+url.QueryEscape(entity.TokenErrorOIDC)),
+		)
+		return
+	}
+	sep := "?"
+	if strings.Contains(returnTo, "?") {
+		sep = "&"
+	}
+	c.Redirect(http.StatusSeeOther, env+returnTo+sep+entity.QueryToken+"="+token)
+}
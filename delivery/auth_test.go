@@ -0,0 +1,303 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRoutePermission(t *testing.T) {
+	submit := &entity.PermissionRequirement{Permission: entity.PermissionReviewSubmit}
+	approve := &entity.PermissionRequirement{
+		Permission: entity.PermissionReviewApprove,
+		Fields:     []string{"approval_status"},
+	}
+	const reviews = "/api/projects/:project/reviewInfos"
+	tests := []struct {
+		desc        string
+		scope       *entity.TokenScope
+		requirement *entity.PermissionRequirement
+		method      string
+		path        string
+		// want is nil when the route is forbidden
+		want *entity.RoutePermission
+	}{
+		{
+			desc: "the role is allowed",
+			scope: &entity.TokenScope{
+				Studio:   "studioa",
+				Projects: []string{"potoo", "kiwi"},
+				Enforced: map[string][]entity.Permission{
+					"potoo": {entity.PermissionReviewRead, entity.PermissionReviewSubmit},
+				},
+			},
+			requirement: submit,
+			method:      http.MethodPost,
+			path:        reviews,
+			want: &entity.RoutePermission{
+				Permission: entity.PermissionReviewSubmit,
+				Projects:   []string{"potoo", "kiwi"},
+			},
+		},
+		{
+			desc: "the role is denied",
+			scope: &entity.TokenScope{
+				Studio:   "studioa",
+				Projects: []string{"potoo"},
+				Enforced: map[string][]entity.Permission{
+					"potoo": {entity.PermissionReviewRead},
+				},
+			},
+			requirement: submit,
+			method:      http.MethodPost,
+			path:        reviews,
+		},
+		{
+			desc: "the role is denied in one of the projects",
+			scope: &entity.TokenScope{
+				Studio:   "studioa",
+				Projects: []string{"potoo", "kiwi"},
+				Enforced: map[string][]entity.Permission{
+					"potoo": {entity.PermissionReviewRead},
+				},
+			},
+			requirement: submit,
+			method:      http.MethodPost,
+			path:        reviews,
+			want: &entity.RoutePermission{
+				Permission: entity.PermissionReviewSubmit,
+				Projects:   []string{"kiwi"},
+			},
+		},
+		{
+			desc: "the route is unmapped",
+			scope: &entity.TokenScope{
+				Studio:   "studioa",
+				Projects: []string{"potoo"},
+				Enforced: map[string][]entity.Permission{
+					"potoo": {entity.PermissionReviewRead},
+				},
+			},
+			method: http.MethodGet,
+			path:   "/api/projects/:project/assets",
+			want: &entity.RoutePermission{
+				Projects: []string{"potoo"},
+			},
+		},
+		{
+			desc: "the fields of the route are restricted to the permitted projects",
+			scope: &entity.TokenScope{
+				Studio:   "studioa",
+				Projects: []string{"potoo", "kiwi"},
+				Enforced: map[string][]entity.Permission{
+					"potoo": {entity.PermissionReviewApprove},
+					"kiwi":  {entity.PermissionReviewSubmit},
+				},
+			},
+			requirement: approve,
+			method:      http.MethodPatch,
+			path:        reviews + "/:id",
+			want: &entity.RoutePermission{
+				Permission:    entity.PermissionReviewApprove,
+				Projects:      []string{"potoo", "kiwi"},
+				Fields:        []string{"approval_status"},
+				FieldProjects: []string{"potoo"},
+			},
+		},
+		{
+			desc: "admin studios are not restricted by roles",
+			scope: &entity.TokenScope{
+				Studio:   "ppi",
+				Projects: []string{"potoo"},
+				Enforced: map[string][]entity.Permission{
+					"potoo": {},
+				},
+			},
+			requirement: submit,
+			method:      http.MethodPost,
+			path:        reviews,
+			want: &entity.RoutePermission{
+				Projects: []string{"potoo"},
+			},
+		},
+		{
+			desc:   "the admin API is forbidden to other studios",
+			scope:  &entity.TokenScope{Studio: "studioa"},
+			method: http.MethodGet,
+			path:   "/api/admin/timings",
+		},
+		{
+			desc: "the route is not one of the API key",
+			scope: &entity.TokenScope{
+				APIKey: &entity.APIKey{Routes: []*entity.APIKeyRoute{
+					{Method: http.MethodGet, Path: "/api/projects/*"},
+				}},
+				Studio:   "studioa",
+				Projects: []string{"potoo"},
+			},
+			requirement: submit,
+			method:      http.MethodPost,
+			path:        reviews,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := routePermission(tt.scope, tt.requirement, tt.method, tt.path)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want the route to be forbidden", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("the route is forbidden")
+			}
+			if got.Method != tt.method || got.Path != tt.path {
+				t.Errorf("route = %s %s, want %s %s", got.Method, got.Path, tt.method, tt.path)
+			}
+			if got.Permission != tt.want.Permission {
+				t.Errorf("permission = %q, want %q", got.Permission, tt.want.Permission)
+			}
+			if !slices.Equal(got.Projects, tt.want.Projects) {
+				t.Errorf("projects = %v, want %v", got.Projects, tt.want.Projects)
+			}
+			if !slices.Equal(got.Fields, tt.want.Fields) {
+				t.Errorf("fields = %v, want %v", got.Fields, tt.want.Fields)
+			}
+			if !slices.Equal(got.FieldProjects, tt.want.FieldProjects) {
+				t.Errorf("field projects = %v, want %v", got.FieldProjects, tt.want.FieldProjects)
+			}
+		})
+	}
+}
+
+func TestSetUser(t *testing.T) {
+	tests := []struct {
+		desc     string
+		user     string
+		header   string
+		wantCode int
+		wantUser string
+	}{
+		{"no header", "alice", "", http.StatusOK, "alice"},
+		{"the header is the authenticated user", "alice", "alice", http.StatusOK, "alice"},
+		{"the header differs from the authenticated user", "alice", "bob", http.StatusForbidden, ""},
+		{"the header without an authenticated user", "", "bob", http.StatusForbidden, ""},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotUser string
+			r := gin.New()
+			r.GET("/api/projects", func(c *gin.Context) {
+				if !setUser(c, tt.user) {
+					return
+				}
+				gotUser = requestUser(c)
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+			if tt.header != "" {
+				req.Header.Set(entity.UserHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if gotUser != tt.wantUser {
+				t.Errorf("user = %q, want %q", gotUser, tt.wantUser)
+			}
+		})
+	}
+}
+
+// TestRoleRequire checks the middleware of the routes registered with Role.Handle against the
+// roles of the database.
+func TestRoleRequire(t *testing.T) {
+	db := openTestDB(t)
+	roleRepo, err := repository.NewRole(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := fmt.Sprintf("test%d", time.Now().UnixNano()%1e12)
+	t.Cleanup(func() {
+		db.Where("`project` = ?", project).Delete(&model.UserRole{})
+	})
+	for user, role := range map[string]string{
+		"artist@example.com": "artist",
+		"viewer@example.com": "viewer",
+	} {
+		if _, err := roleRepo.CreateUserRole(db, &entity.CreateUserRoleParams{
+			Role:      role,
+			Project:   project,
+			User:      user,
+			CreatedBy: "test",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewRole(usecase.NewRole(roleRepo, nil, 10*time.Second, 10*time.Second))
+
+	tests := []struct {
+		desc     string
+		studio   string
+		user     string
+		header   string
+		method   string
+		wantCode int
+	}{
+		{"the role is allowed", "studioa", "artist@example.com", "", http.MethodPost, http.StatusCreated},
+		{"the role is denied", "studioa", "viewer@example.com", "", http.MethodPost, http.StatusForbidden},
+		{"a user without a role is denied", "studioa", "other@example.com", "", http.MethodPost, http.StatusForbidden},
+		{"the route is unmapped", "studioa", "viewer@example.com", "", http.MethodGet, http.StatusOK},
+		{"the admin studio is not restricted", "ppi", "viewer@example.com", "", http.MethodPost, http.StatusCreated},
+		{
+			"X-User differs from the authenticated user", "studioa", "viewer@example.com",
+			"artist@example.com", http.MethodPost, http.StatusForbidden,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := gin.New()
+			// authenticates the request as ParseHeaderToken does
+			r.Use(func(c *gin.Context) {
+				c.Set("studio", tt.studio)
+				if !setUser(c, tt.user) {
+					c.Abort()
+				}
+			})
+			g := r.Group("/api")
+			h.Handle(g, http.MethodPost, "/projects/:project/reviewInfos",
+				&entity.PermissionRequirement{Permission: entity.PermissionReviewSubmit},
+				func(c *gin.Context) { c.Status(http.StatusCreated) },
+			)
+			g.GET("/projects/:project/reviewInfos", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			if h.Requirement(http.MethodGet, "/api/projects/:project/reviewInfos") != nil {
+				t.Fatal("the GET route has a requirement")
+			}
+
+			req := httptest.NewRequest(tt.method, "/api/projects/"+project+"/reviewInfos", nil)
+			if tt.header != "" {
+				req.Header.Set(entity.UserHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
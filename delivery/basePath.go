@@ -0,0 +1,33 @@
+package delivery
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// StripBasePath serves the requests under entity.BasePath with their path at the root, as
+// the routes are registered. The requests whose reverse proxy already stripped the base path
+// are served as they are.
+func StripBasePath(h http.Handler) http.Handler {
+	if entity.BasePath == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, entity.BasePath)
+		if ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+			if rest == "" {
+				rest = "/"
+			}
+			r.URL.Path = rest
+			if r.URL.RawPath != "" {
+				r.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, entity.BasePath)
+				if r.URL.RawPath == "" {
+					r.URL.RawPath = "/"
+				}
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
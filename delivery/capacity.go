@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// capacityPath is the route of the capacity, which is not counted in flight itself.
+const capacityPath = "/api/capacity"
+
+func NewCapacity(
+	uc *usecase.Capacity,
+) *Capacity {
+	return &Capacity{
+		uc: uc,
+	}
+}
+
+type Capacity struct {
+	uc *usecase.Capacity
+}
+
+// Track is the middleware counting the requests in flight.
+func (h *Capacity) Track(c *gin.Context) {
+	if c.FullPath() == capacityPath {
+		c.Next()
+		return
+	}
+	h.uc.Enter()
+	defer h.uc.Leave()
+	c.Next()
+}
+
+// Get reports the load of the API and advises the clients to back off while it is busy, with
+// a Retry-After header too.
+func (h *Capacity) Get(c *gin.Context) {
+	capacity, err := h.uc.Get()
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	if capacity.RetryAfterSeconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(capacity.RetryAfterSeconds))
+	}
+	c.PureJSON(http.StatusOK, capacity)
+}
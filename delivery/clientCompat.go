@@ -0,0 +1,142 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewClientCompat(
+	uc *usecase.ClientCompat,
+) *ClientCompat {
+	return &ClientCompat{
+		uc: uc,
+	}
+}
+
+// ClientCompat tells the desktop tools whether their version is still supported. Its
+// compatibility matrix is managed by admins.
+type ClientCompat struct {
+	uc *usecase.ClientCompat
+}
+
+func clientCompatError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// clientCompatAdmin returns the studio of an admin, or responds with an error and returns
+// false.
+func clientCompatAdmin(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf(
+			"%w: the compatibility matrix can only be managed by admins", entity.ErrForbidden,
+		))
+		return "", false
+	}
+	return studio, true
+}
+
+type getClientCompatParams struct {
+	Client  string `form:"client"`
+	Version string `form:"version"`
+}
+
+// Get tells whether the `version` of the desktop tool `client` is supported, deprecated or
+// blocked. It is called before logging in, so it does not require a token.
+func (h *ClientCompat) Get(c *gin.Context) {
+	var p getClientCompatParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetClientCompatParams{
+		Client:  p.Client,
+		Version: p.Version,
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		clientCompatError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "max-age=300")
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ClientCompat) ListRules(c *gin.Context) {
+	if _, ok := clientCompatAdmin(c); !ok {
+		return
+	}
+	entities, err := h.uc.ListRules(c.Request.Context())
+	if err != nil {
+		clientCompatError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"clients": entities})
+}
+
+type updateClientCompatRuleParams struct {
+	MinVersion      string  `json:"min_version"`
+	DeprecatedBelow *string `json:"deprecated_below"`
+	LatestVersion   *string `json:"latest_version"`
+	ChangelogURL    *string `json:"changelog_url"`
+	Message         string  `json:"message"`
+	ModifiedBy      *string `json:"modified_by"`
+}
+
+// PutRule creates or replaces the row of a desktop tool in the compatibility matrix.
+func (h *ClientCompat) PutRule(c *gin.Context) {
+	studio, ok := clientCompatAdmin(c)
+	if !ok {
+		return
+	}
+	var p updateClientCompatRuleParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateClientCompatRuleParams{
+		Client:          c.Param("client"),
+		MinVersion:      p.MinVersion,
+		DeprecatedBelow: p.DeprecatedBelow,
+		LatestVersion:   p.LatestVersion,
+		ChangelogURL:    p.ChangelogURL,
+		Message:         p.Message,
+		ModifiedBy:      studio,
+	}
+	if p.ModifiedBy != nil {
+		params.ModifiedBy = *p.ModifiedBy
+	}
+	e, err := h.uc.UpdateRule(c.Request.Context(), params)
+	if err != nil {
+		clientCompatError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ClientCompat) DeleteRule(c *gin.Context) {
+	if _, ok := clientCompatAdmin(c); !ok {
+		return
+	}
+	if err := h.uc.DeleteRule(c.Request.Context(), &entity.DeleteClientCompatRuleParams{
+		Client: c.Param("client"),
+	}); err != nil {
+		clientCompatError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewClientHeartbeat(
+	uc *usecase.ClientHeartbeat,
+) *ClientHeartbeat {
+	return &ClientHeartbeat{
+		uc: uc,
+	}
+}
+
+// ClientHeartbeat records the heartbeats the desktop tools and DCC plugins send while they are
+// running, and reports them to admins.
+type ClientHeartbeat struct {
+	uc *usecase.ClientHeartbeat
+}
+
+func clientHeartbeatError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// clientHeartbeatAdmin responds with an error and returns false unless the studio of the
+// request is an admin one.
+func clientHeartbeatAdmin(c *gin.Context) bool {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf(
+			"%w: the connected clients can only be listed by admins", entity.ErrForbidden,
+		))
+		return false
+	}
+	return true
+}
+
+type recordClientHeartbeatParams struct {
+	Client  string `json:"client" binding:"required"`
+	Version string `json:"version" binding:"required"`
+	Host    string `json:"host" binding:"required"`
+}
+
+// Post records a heartbeat of the `version` of the desktop tool `client` running on `host`,
+// under the credential of the request. The tools send one every few minutes while running.
+func (h *ClientHeartbeat) Post(c *gin.Context) {
+	var p recordClientHeartbeatParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if entity.SkipAuth && studio == "" {
+		studio = "skipauth"
+	}
+	params := &entity.RecordClientHeartbeatParams{
+		Studio:  studio,
+		Client:  p.Client,
+		Version: p.Version,
+		Host:    p.Host,
+	}
+	if id, ok := c.Get(entity.APIKeyContextKey); ok {
+		params.APIKeyID, _ = id.(int32)
+	}
+	if err := h.uc.Record(c.Request.Context(), params); err != nil {
+		clientHeartbeatError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type listConnectedClientsParams struct {
+	Studio *string `form:"studio"`
+	Client *string `form:"client"`
+	// Within is in seconds.
+	Within int `form:"within"`
+}
+
+// List returns the clients which sent a heartbeat in the last `within` seconds, 300 by
+// default, with the number of hosts running each of their versions.
+func (h *ClientHeartbeat) List(c *gin.Context) {
+	if !clientHeartbeatAdmin(c) {
+		return
+	}
+	var p listConnectedClientsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListConnectedClientsParams{
+		Studio: p.Studio,
+		Client: p.Client,
+		Within: time.Duration(p.Within) * time.Second,
+	}
+	e, err := h.uc.ListConnected(c.Request.Context(), params)
+	if err != nil {
+		clientHeartbeatError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type listClientAdoptionParams struct {
+	Studio *string    `form:"studio"`
+	Client *string    `form:"client"`
+	From   *time.Time `form:"from" time_format:"2006-01-02"`
+	To     *time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+// Adoption reports the hosts and studios which ran each version of the clients per day, from
+// `from` to `to`, the last 30 days by default.
+func (h *ClientHeartbeat) Adoption(c *gin.Context) {
+	if !clientHeartbeatAdmin(c) {
+		return
+	}
+	var p listClientAdoptionParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListClientAdoptionParams{
+		Studio: p.Studio,
+		Client: p.Client,
+		From:   p.From,
+		To:     p.To,
+	}
+	entities, err := h.uc.Adoption(c.Request.Context(), params)
+	if err != nil {
+		clientHeartbeatError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"adoption": entities})
+}
@@ -0,0 +1,172 @@
+package delivery
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewCompliance(
+	uc *usecase.Compliance,
+) *Compliance {
+	return &Compliance{
+		uc: uc,
+	}
+}
+
+type Compliance struct {
+	uc *usecase.Compliance
+}
+
+type getComplianceReportParams struct {
+	Studio *string `form:"studio"`
+	From   *string `form:"from"`
+	To     *string `form:"to"`
+	Format *string `form:"format"`
+}
+
+// GetReport is the report of the data shared with the external studios of a project, as JSON,
+// CSV or PDF according to `format`. `from` and `to` are dates formatted as YYYY-MM-DD; they
+// default to the last 30 days.
+func (h *Compliance) GetReport(c *gin.Context) {
+	var p getComplianceReportParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if p.To != nil {
+		t, err := time.Parse("2006-01-02", *p.To)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -29)
+	if p.From != nil {
+		t, err := time.Parse("2006-01-02", *p.From)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		from = t
+	}
+	params := &entity.GetComplianceReportParams{
+		Project: c.Param("project"),
+		Studio:  p.Studio,
+		From:    from,
+		To:      to,
+		Format:  entity.ComplianceFormatJSON,
+	}
+	if p.Format != nil {
+		params.Format = *p.Format
+	}
+	report, err := h.uc.GetReport(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("compliance_%s_%s_%s", report.Project, report.From, report.To)
+	switch params.Format {
+	case entity.ComplianceFormatCSV:
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment;filename="+fileName+".csv")
+		writer := csv.NewWriter(c.Writer)
+		defer writer.Flush()
+		if err := writer.WriteAll(complianceRecords(report)); err != nil {
+			c.String(http.StatusInternalServerError, "Failed to generate CSV")
+		}
+	case entity.ComplianceFormatPDF:
+		c.Header("Content-Type", "application/pdf")
+		c.Header("Content-Disposition", "attachment;filename="+fileName+".pdf")
+		if err := writeTextPDF(c.Writer, complianceLines(report)); err != nil {
+			c.String(http.StatusInternalServerError, "Failed to generate PDF")
+		}
+	default:
+		c.PureJSON(http.StatusOK, report)
+	}
+}
+
+func complianceRecords(report *entity.ComplianceReport) [][]string {
+	records := [][]string{
+		{"studio", "kind", "occurred_at_utc", "actor", "target", "detail"},
+	}
+	for _, t := range report.Transfers {
+		records = append(records, []string{
+			t.Studio,
+			t.Kind,
+			t.OccurredAtUTC.Format(time.RFC3339),
+			t.Actor,
+			t.Target,
+			t.Detail,
+		})
+	}
+	return records
+}
+
+func complianceLines(report *entity.ComplianceReport) []string {
+	lines := []string{
+		fmt.Sprintf("External data transfers of project %s", report.Project),
+		fmt.Sprintf("Period: %s to %s (UTC)", report.From, report.To),
+		fmt.Sprintf("Generated at: %s", report.GeneratedAtUTC.Format(time.RFC3339)),
+		"",
+		fmt.Sprintf("%-20s %10s %20s %21s", "Studio", "Publishes", "Directory deletions",
+			"Attachment downloads"),
+	}
+	for _, s := range report.Studios {
+		lines = append(lines, fmt.Sprintf(
+			"%-20s %10d %20d %21d",
+			s.Studio, s.Publishes, s.DirectoryDeletions, s.AttachmentDownloads,
+		))
+	}
+	lines = append(lines, "", fmt.Sprintf(
+		"%-20s %-18s %-20s %-20s %s", "Occurred at (UTC)", "Kind", "Studio", "Actor", "Target",
+	))
+	for _, t := range report.Transfers {
+		lines = append(lines, fmt.Sprintf(
+			"%-20s %-18s %-20s %-20s %s",
+			t.OccurredAtUTC.Format("2006-01-02 15:04:05"), t.Kind, t.Studio, t.Actor, t.Target,
+		))
+	}
+	return lines
+}
+
+// RecordAttachmentDownload records the download of a comment attachment by the studio of the
+// request, once the attachment is served. Failures are only logged, so that they never fail
+// the download.
+func (h *Compliance) RecordAttachmentDownload(c *gin.Context) {
+	if c.Writer.Status() != http.StatusOK {
+		return
+	}
+	studio, _ := c.Get("studio")
+	studioStr, _ := studio.(string)
+	if studioStr == "" {
+		return
+	}
+	params := &entity.RecordTransferParams{
+		Project: c.Param("project"),
+		Studio:  studioStr,
+		Kind:    entity.TransferAttachmentDownload,
+		Target:  c.Param("attachment_id"),
+		Detail:  c.Param("id"),
+	}
+	if err := h.uc.RecordTransfer(c.Request.Context(), params); err != nil {
+		NewLogger(c.Request).Errorf("failed to record attachment download: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewConsistency(
+	uc *usecase.Consistency,
+) *Consistency {
+	return &Consistency{
+		uc: uc,
+	}
+}
+
+type Consistency struct {
+	uc *usecase.Consistency
+}
+
+// Track puts the consistency token sent by the client in the context of the request, so that
+// its reads see the writes the token was returned for, and returns a new token on successful
+// writes.
+func (h *Consistency) Track(c *gin.Context) {
+	if s := c.GetHeader(entity.ConsistencyTokenHeader); s != "" {
+		token, err := entity.ParseConsistencyToken(s)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		c.Request = c.Request.WithContext(
+			context.WithValue(c.Request.Context(), entity.KeyConsistencyToken, token),
+		)
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+
+	w := &consistencyWriter{
+		ResponseWriter: c.Writer,
+		ctx:            c.Request.Context(),
+		uc:             h.uc,
+	}
+	c.Writer = w
+	c.Next()
+	// responses without body are written by gin after the handlers
+	if !w.Written() {
+		w.setToken()
+	}
+}
+
+// consistencyWriter sets the token header right before the response is written, when the
+// write of the request has been committed.
+type consistencyWriter struct {
+	gin.ResponseWriter
+	ctx    context.Context
+	uc     *usecase.Consistency
+	issued bool
+}
+
+func (w *consistencyWriter) setToken() {
+	if w.issued {
+		return
+	}
+	w.issued = true
+	if w.Status() < http.StatusBadRequest {
+		w.Header().Set(entity.ConsistencyTokenHeader, w.uc.Token(w.ctx).String())
+	}
+}
+
+func (w *consistencyWriter) WriteHeaderNow() {
+	w.setToken()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *consistencyWriter) Write(data []byte) (int, error) {
+	w.setToken()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *consistencyWriter) WriteString(s string) (int, error) {
+	w.setToken()
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,202 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// MetadataFilters extracts the custom field filters given as `meta.<key>=<value>` query
+// parameters.
+func MetadataFilters(q url.Values) map[string]string {
+	var filters map[string]string
+	for k, v := range q {
+		if !strings.HasPrefix(k, entity.MetadataFilterPrefix) || len(v) == 0 {
+			continue
+		}
+		if filters == nil {
+			filters = map[string]string{}
+		}
+		filters[strings.TrimPrefix(k, entity.MetadataFilterPrefix)] = v[0]
+	}
+	return filters
+}
+
+func NewCustomField(
+	uc *usecase.CustomField,
+) *CustomField {
+	return &CustomField{
+		uc: uc,
+	}
+}
+
+type CustomField struct {
+	uc *usecase.CustomField
+}
+
+type listCustomFieldsParams struct {
+	Target *string `form:"target"`
+}
+
+func (h *CustomField) List(c *gin.Context) {
+	var p listCustomFieldsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListCustomFieldDefinitionsParams{
+		Project: c.Param("project"),
+		Target:  p.Target,
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"custom_fields": entities})
+}
+
+type createCustomFieldParams struct {
+	Target      string   `json:"target"`
+	Key         string   `json:"key"`
+	DisplayName string   `json:"display_name"`
+	Type        string   `json:"type"`
+	EnumValues  []string `json:"enum_values"`
+	Required    bool     `json:"required"`
+}
+
+func (h *CustomField) Post(c *gin.Context) {
+	var p createCustomFieldParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateCustomFieldDefinitionParams{
+		Project:     c.Param("project"),
+		Target:      p.Target,
+		Key:         p.Key,
+		DisplayName: p.DisplayName,
+		Type:        p.Type,
+		EnumValues:  p.EnumValues,
+		Required:    p.Required,
+		CreatedBy:   nil,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateCustomFieldParams struct {
+	DisplayName *string  `json:"display_name,omitempty"`
+	EnumValues  []string `json:"enum_values,omitempty"`
+	Required    *bool    `json:"required,omitempty"`
+}
+
+func (h *CustomField) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p updateCustomFieldParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateCustomFieldDefinitionParams{
+		Project:     c.Param("project"),
+		ID:          int32(id),
+		DisplayName: p.DisplayName,
+		EnumValues:  p.EnumValues,
+		Required:    p.Required,
+		ModifiedBy:  nil,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) || errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *CustomField) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteCustomFieldDefinitionParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CustomField) GetAssetMetadata(c *gin.Context) {
+	params := &entity.GetAssetMetadataParams{
+		Project:  c.Param("project"),
+		Asset:    c.Param("asset"),
+		Relation: c.Param("relation"),
+	}
+	e, err := h.uc.GetAssetMetadata(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateAssetMetadataParams struct {
+	Metadata entity.JSONObject `json:"metadata" binding:"required"`
+}
+
+func (h *CustomField) UpdateAssetMetadata(c *gin.Context) {
+	var p updateAssetMetadataParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateAssetMetadataParams{
+		Project:    c.Param("project"),
+		Asset:      c.Param("asset"),
+		Relation:   c.Param("relation"),
+		Metadata:   p.Metadata,
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.UpdateAssetMetadata(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
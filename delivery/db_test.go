@@ -0,0 +1,33 @@
+package delivery
+
+import (
+	"os"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSNEnv is the DSN of the MySQL database the database tests run against, one migrated by
+// the server. The tests are skipped when it is not set.
+const testDSNEnv = "PPI_TEST_MYSQL_DSN"
+
+// testStudioEnv names a studio of the test database, for the tests requiring one.
+const testStudioEnv = "PPI_TEST_STUDIO"
+
+// openTestDB opens the test database, skipping t when there is none.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open %s: %v", testDSNEnv, err)
+	}
+	return db
+}
@@ -0,0 +1,195 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewDelegation(
+	uc *usecase.Delegation,
+) *Delegation {
+	return &Delegation{
+		uc: uc,
+	}
+}
+
+type Delegation struct {
+	uc *usecase.Delegation
+}
+
+func delegationError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		forbidden(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		log.Println("ERROR:", err)
+		c.AbortWithStatusJSON(http.StatusConflict, errorBody(http.StatusConflict, err))
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// delegationAdmin reports whether the requester may manage the delegations of anyone.
+func delegationAdmin(c *gin.Context) bool {
+	if entity.SkipAuth {
+		return true
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	return isAdminStudio(studio)
+}
+
+type listDelegationsParams struct {
+	PerPage   *int                     `form:"per_page"`
+	Page      *int                     `form:"page"`
+	Delegator *string                  `form:"delegator"`
+	Delegate  *string                  `form:"delegate"`
+	Project   *string                  `form:"project"`
+	Status    *entity.DelegationStatus `form:"status"`
+}
+
+func (h *Delegation) List(c *gin.Context) {
+	var p listDelegationsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListDelegationsParams{
+		Delegator: p.Delegator,
+		Delegate:  p.Delegate,
+		Project:   p.Project,
+		Status:    p.Status,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		delegationError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"delegations",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
+
+func (h *Delegation) Get(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := h.uc.Get(c.Request.Context(), &entity.GetDelegationParams{ID: id})
+	if err != nil {
+		delegationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createDelegationParams struct {
+	Delegator   string    `json:"delegator"`
+	Delegate    string    `json:"delegate"`
+	Project     *string   `json:"project"`
+	StartsAtUTC time.Time `json:"starts_at_utc"`
+	EndsAtUTC   time.Time `json:"ends_at_utc"`
+	Reason      string    `json:"reason"`
+	CreatedBy   string    `json:"created_by"`
+}
+
+// Post delegates the approvals of a supervisor to another one for a time window. Only the
+// delegator or an admin may create it.
+func (h *Delegation) Post(c *gin.Context) {
+	var p createDelegationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	if p.CreatedBy != p.Delegator && !delegationAdmin(c) {
+		forbidden(c, fmt.Errorf(
+			"%w: approvals can only be delegated by the delegator or an admin",
+			entity.ErrForbidden,
+		))
+		return
+	}
+	params := &entity.CreateDelegationParams{
+		Delegator:   p.Delegator,
+		Delegate:    p.Delegate,
+		Project:     p.Project,
+		StartsAtUTC: p.StartsAtUTC,
+		EndsAtUTC:   p.EndsAtUTC,
+		Reason:      p.Reason,
+		CreatedBy:   p.CreatedBy,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		delegationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type revokeDelegationParams struct {
+	RevokedBy string `json:"revoked_by"`
+}
+
+// Revoke ends an active delegation early. Only its delegator, its delegate or an admin may
+// revoke it.
+func (h *Delegation) Revoke(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p revokeDelegationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	current, err := h.uc.Get(c.Request.Context(), &entity.GetDelegationParams{ID: id})
+	if err != nil {
+		delegationError(c, err)
+		return
+	}
+	if p.RevokedBy != current.Delegator && p.RevokedBy != current.Delegate &&
+		!delegationAdmin(c) {
+		forbidden(c, fmt.Errorf(
+			"%w: delegations can only be revoked by their delegator, their delegate or an admin",
+			entity.ErrForbidden,
+		))
+		return
+	}
+	params := &entity.RevokeDelegationParams{
+		ID:        id,
+		RevokedBy: p.RevokedBy,
+	}
+	e, err := h.uc.Revoke(c.Request.Context(), params)
+	if err != nil {
+		delegationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
@@ -0,0 +1,185 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewDirectoryTemplate(
+	uc *usecase.DirectoryTemplate,
+) *DirectoryTemplate {
+	return &DirectoryTemplate{
+		uc: uc,
+	}
+}
+
+type DirectoryTemplate struct {
+	uc *usecase.DirectoryTemplate
+}
+
+func (h *DirectoryTemplate) List(c *gin.Context) {
+	params := &entity.ListDirectoryTemplatesParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{
+		"directory_templates": entities,
+	})
+}
+
+type createDirectoryTemplateParams struct {
+	Name     string   `json:"name" binding:"required"`
+	Patterns []string `json:"patterns" binding:"required"`
+}
+
+func (h *DirectoryTemplate) Post(c *gin.Context) {
+	var p createDirectoryTemplateParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateDirectoryTemplateParams{
+		Project:   c.Param("project"),
+		Name:      p.Name,
+		Patterns:  p.Patterns,
+		CreatedBy: nil,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateDirectoryTemplateParams struct {
+	Name     *string  `json:"name"`
+	Patterns []string `json:"patterns"`
+}
+
+func (h *DirectoryTemplate) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p updateDirectoryTemplateParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateDirectoryTemplateParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		Name:       p.Name,
+		Patterns:   p.Patterns,
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *DirectoryTemplate) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteDirectoryTemplateParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type scaffoldAssetParams struct {
+	Relation    *string  `json:"relation"`
+	Phases      []string `json:"phases"`
+	TemplateIDs []int32  `json:"template_ids"`
+	DryRun      bool     `json:"dry_run"`
+}
+
+// ScaffoldAsset creates the directories of the project's directory templates for an asset.
+// The "dry_run" query parameter may be given instead of the body field.
+func (h *DirectoryTemplate) ScaffoldAsset(c *gin.Context) {
+	var p scaffoldAssetParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	if v, ok := c.GetQuery("dry_run"); ok {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		p.DryRun = p.DryRun || dryRun
+	}
+	params := &entity.ScaffoldAssetParams{
+		Project:     c.Param("project"),
+		Asset:       c.Param("asset"),
+		Relation:    p.Relation,
+		Phases:      p.Phases,
+		TemplateIDs: p.TemplateIDs,
+		DryRun:      p.DryRun,
+		CreatedBy:   nil,
+	}
+	result, err := h.uc.ScaffoldAsset(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, result)
+}
@@ -0,0 +1,154 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// errorBody returns the body of the error responses: the code of the status, like
+// "bad_request", the message, the link to the documentation of the code and, for invalid
+// requests, the errors of the fields so that client forms can highlight them.
+func errorBody(status int, err error) gin.H {
+	code := strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	body := gin.H{
+		"code":    code,
+		"message": err.Error(),
+	}
+	if docs := errorDocs(code); docs != "" {
+		body["docs"] = docs
+	}
+	if fields := fieldErrors(err); len(fields) != 0 {
+		body["errors"] = fields
+	}
+	return body
+}
+
+// errorDocs returns the link to the documentation of an error code, or "" when
+// entity.ErrorDocsURL is not set.
+func errorDocs(code string) string {
+	if entity.ErrorDocsURL == "" {
+		return ""
+	}
+	return entity.ErrorDocsURL + "#" + code
+}
+
+// fieldErrors translates the errors of the binders and of the validator into the errors of
+// the fields they failed on, named in snake case like in the JSON bodies and the queries.
+func fieldErrors(err error) []*entity.FieldError {
+	var fields []*entity.FieldError
+	var invalidErr *entity.InvalidFieldsError
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &invalidErr):
+		fields = invalidErr.Fields
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			field := fieldPath(fe.Namespace())
+			fields = append(fields, &entity.FieldError{
+				Code:    fe.Tag(),
+				Field:   field,
+				Message: validationMessage(field, fe),
+			})
+		}
+	case errors.As(err, &typeErr):
+		fields = append(fields, &entity.FieldError{
+			Code:    "invalid_type",
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("%s must be a %s, not a %s", typeErr.Field, typeErr.Type, typeErr.Value),
+		})
+	case errors.As(err, &syntaxErr):
+		fields = append(fields, &entity.FieldError{
+			Code:    "invalid_json",
+			Message: syntaxErr.Error(),
+		})
+	}
+	for _, f := range fields {
+		f.Docs = errorDocs(f.Code)
+	}
+	return fields
+}
+
+// fieldPath returns the path of a field from its namespace in the validated struct, e.g.
+// "comments[0].txt" for "CreateParams.Comments[0].Txt".
+func fieldPath(namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+	for i, p := range parts {
+		parts[i] = snakeCase(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// snakeCase converts a Go field name to snake case, keeping the initialisms together, e.g.
+// "ModifiedAtUTC" to "modified_at_utc".
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+var validationBounds = map[string]string{
+	"min": "at least",
+	"max": "at most",
+	"len": "exactly",
+	"gte": "at least",
+	"lte": "at most",
+	"gt":  "more than",
+	"lt":  "less than",
+}
+
+// validationMessage returns the message of a failed validation rule of a field.
+func validationMessage(field string, fe validator.FieldError) string {
+	if bound, ok := validationBounds[fe.Tag()]; ok {
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be %s %s characters long", field, bound, fe.Param())
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("%s must have %s %s items", field, bound, fe.Param())
+		default:
+			return fmt.Sprintf("%s must be %s %s", field, bound, fe.Param())
+		}
+	}
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "oneof":
+		return fmt.Sprintf(
+			"%s must be one of %s", field, strings.ReplaceAll(fe.Param(), " ", ", "),
+		)
+	case "alphanum":
+		return fmt.Sprintf("%s must only contain letters and digits", field)
+	case "lowercase":
+		return fmt.Sprintf("%s must be lowercase", field)
+	case "startsnotwithdigit":
+		return fmt.Sprintf("%s must not start with a digit", field)
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("%s failed the %s=%s rule", field, fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+}
@@ -0,0 +1,127 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewExportJob(
+	uc *usecase.ExportJob,
+) *ExportJob {
+	return &ExportJob{
+		uc: uc,
+	}
+}
+
+type ExportJob struct {
+	uc *usecase.ExportJob
+}
+
+func exportJobError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+func (h *ExportJob) List(c *gin.Context) {
+	params := &entity.ListExportJobsParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		exportJobError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"exports": entities})
+}
+
+func (h *ExportJob) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetExportJobParams{
+		Project: c.Param("project"),
+		ID:      int32(id),
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		exportJobError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createExportJobParams struct {
+	Kind      *string `json:"kind"`
+	Format    *string `json:"format"`
+	CreatedBy *string `json:"created_by"`
+}
+
+// Post queues the generation of the assets tracker CSV or of the pivot export. The pivot
+// export takes the filters and the sort of the query, parsed by the caller as for the pivot
+// API. The body is optional and defaults to the assets tracker as CSV.
+func (h *ExportJob) Post(c *gin.Context, query repository.ListAssetsPivotParams) {
+	var p createExportJobParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.CreateExportJobParams{
+		Project:   c.Param("project"),
+		Kind:      entity.ExportKindAssets,
+		Format:    entity.PivotExportFormatCSV,
+		CreatedBy: p.CreatedBy,
+	}
+	if p.Kind != nil {
+		params.Kind = *p.Kind
+	}
+	if p.Format != nil {
+		params.Format = *p.Format
+	}
+	e, err := h.uc.Create(c.Request.Context(), params, query)
+	if err != nil {
+		exportJobError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusAccepted, e)
+}
+
+// Download serves the document of a completed export job.
+func (h *ExportJob) Download(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetExportJobParams{
+		Project: c.Param("project"),
+		ID:      int32(id),
+	}
+	e, f, err := h.uc.Open(c.Request.Context(), params)
+	if err != nil {
+		exportJobError(c, err)
+		return
+	}
+	defer f.Close()
+	fileName := fmt.Sprintf("%s_%s_%d.%s", e.Kind, e.Project, e.ID, e.Format)
+	c.DataFromReader(http.StatusOK, e.Size, exportContentType(e.Format), f, map[string]string{
+		"Content-Disposition": "attachment;filename=" + fileName,
+	})
+}
@@ -0,0 +1,74 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewFault(
+	uc *usecase.Fault,
+) *Fault {
+	return &Fault{
+		uc: uc,
+	}
+}
+
+type Fault struct {
+	uc *usecase.Fault
+}
+
+func (h *Fault) List(c *gin.Context) {
+	c.PureJSON(http.StatusOK, gin.H{
+		"faults": h.uc.List(),
+	})
+}
+
+type setFaultParams struct {
+	LatencyMs       int     `json:"latency_ms"`
+	ErrorRate       float64 `json:"error_rate"`
+	DurationSeconds *int    `json:"duration_seconds"`
+}
+
+// Put simulates a degraded dependency, replacing its current fault.
+func (h *Fault) Put(c *gin.Context) {
+	var p setFaultParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.SetFaultParams{
+		Dependency:      entity.Dependency(c.Param("dependency")),
+		LatencyMs:       p.LatencyMs,
+		ErrorRate:       p.ErrorRate,
+		DurationSeconds: p.DurationSeconds,
+	}
+	f, err := h.uc.Set(params)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, f)
+}
+
+func (h *Fault) Delete(c *gin.Context) {
+	params := &entity.ClearFaultParams{
+		Dependency: entity.Dependency(c.Param("dependency")),
+	}
+	if err := h.uc.Clear(params); err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,154 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// stubDocuments is a Mongo that is always up.
+type stubDocuments struct {
+	entity.DocumentRepository
+}
+
+func (stubDocuments) GetDocumentByID(
+	ctx context.Context,
+	project string,
+	collection string,
+	id string,
+) (*entity.DocumentInfo, error) {
+	return &entity.DocumentInfo{}, nil
+}
+
+// newFaultRouter routes the fault admin API and an endpoint reading a document through the
+// fault layer, as the document endpoints do.
+func newFaultRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	faults := repository.NewFaultInjector()
+	docs := repository.NewFaultyDocumentRepository(stubDocuments{}, faults)
+	h := NewFault(usecase.NewFault(faults))
+
+	r := gin.New()
+	r.GET("/admin/faults", h.List)
+	r.PUT("/admin/faults/:dependency", h.Put)
+	r.DELETE("/admin/faults/:dependency", h.Delete)
+	r.GET("/documents/:id", func(c *gin.Context) {
+		doc, err := docs.GetDocumentByID(c.Request.Context(), "potoo", "assets", c.Param("id"))
+		if err != nil {
+			internalServerError(c, err)
+			return
+		}
+		c.PureJSON(http.StatusOK, doc)
+	})
+	return r
+}
+
+func serveFault(t *testing.T, r *gin.Engine, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp map[string]interface{}
+	if w.Body.Len() != 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: invalid body %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code, resp
+}
+
+func TestFaultInjectionDegradesDocuments(t *testing.T) {
+	r := newFaultRouter()
+
+	if code, _ := serveFault(t, r, http.MethodGet, "/documents/1", ""); code != http.StatusOK {
+		t.Fatalf("GET without fault = %d, want %d", code, http.StatusOK)
+	}
+
+	code, fault := serveFault(t, r, http.MethodPut, "/admin/faults/mongo", `{"error_rate": 1}`)
+	if code != http.StatusOK {
+		t.Fatalf("PUT fault = %d, want %d", code, http.StatusOK)
+	}
+	if fault["dependency"] != "mongo" || fault["error_rate"] != 1.0 {
+		t.Errorf("PUT fault = %v", fault)
+	}
+
+	code, body := serveFault(t, r, http.MethodGet, "/documents/1", "")
+	if code != http.StatusBadGateway {
+		t.Fatalf("GET with fault = %d, want %d", code, http.StatusBadGateway)
+	}
+	if body["code"] != "bad_gateway" {
+		t.Errorf("code = %v, want %q", body["code"], "bad_gateway")
+	}
+	if msg, _ := body["message"].(string); !strings.Contains(msg, "mongo is unavailable") {
+		t.Errorf("message = %q, want the unavailable dependency", msg)
+	}
+
+	// a fault on another dependency leaves the documents alone
+	if code, _ := serveFault(t, r, http.MethodDelete, "/admin/faults/mongo", ""); code != http.StatusNoContent {
+		t.Fatalf("DELETE fault = %d, want %d", code, http.StatusNoContent)
+	}
+	if code, _ := serveFault(t, r, http.MethodPut, "/admin/faults/neo4j", `{"error_rate": 1}`); code != http.StatusOK {
+		t.Fatalf("PUT neo4j fault = %d, want %d", code, http.StatusOK)
+	}
+	if code, _ := serveFault(t, r, http.MethodGet, "/documents/1", ""); code != http.StatusOK {
+		t.Fatalf("GET with neo4j fault = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestFaultInjectionLatencyTimesOut(t *testing.T) {
+	r := newFaultRouter()
+	if code, _ := serveFault(t, r, http.MethodPut, "/admin/faults/mongo", `{"latency_ms": 60000}`); code != http.StatusOK {
+		t.Fatalf("PUT fault = %d, want %d", code, http.StatusOK)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/documents/1", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("GET cancelled during the latency = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestFaultAdmin(t *testing.T) {
+	r := newFaultRouter()
+
+	for _, tt := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPut, "/admin/faults/redis", `{"error_rate": 1}`, http.StatusBadRequest},
+		{http.MethodPut, "/admin/faults/mongo", `{"error_rate": 2}`, http.StatusBadRequest},
+		{http.MethodDelete, "/admin/faults/mongo", "", http.StatusNotFound},
+		{http.MethodDelete, "/admin/faults/redis", "", http.StatusBadRequest},
+		{http.MethodPut, "/admin/faults/neo4j", `{"latency_ms": 100, "duration_seconds": 60}`, http.StatusOK},
+	} {
+		if code, body := serveFault(t, r, tt.method, tt.path, tt.body); code != tt.want {
+			t.Errorf("%s %s %s = %d %v, want %d", tt.method, tt.path, tt.body, code, body, tt.want)
+		}
+	}
+
+	code, body := serveFault(t, r, http.MethodGet, "/admin/faults", "")
+	if code != http.StatusOK {
+		t.Fatalf("GET faults = %d, want %d", code, http.StatusOK)
+	}
+	faults, _ := body["faults"].([]interface{})
+	if len(faults) != 1 {
+		t.Fatalf("faults = %v, want the neo4j fault", body["faults"])
+	}
+	if f := faults[0].(map[string]interface{}); f["dependency"] != "neo4j" || f["expires_at_utc"] == nil {
+		t.Errorf("fault = %v, want the expiring neo4j fault", f)
+	}
+}
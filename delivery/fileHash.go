@@ -0,0 +1,134 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultDuplicateReportLimit is the number of duplicate groups reported by default.
+const defaultDuplicateReportLimit = 100
+
+func NewFileHash(
+	uc *usecase.FileHash,
+) *FileHash {
+	return &FileHash{
+		uc: uc,
+	}
+}
+
+type FileHash struct {
+	uc *usecase.FileHash
+}
+
+func fileHashError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+type registerFileHashesParams struct {
+	Files     []*entity.FileHashEntry `json:"files" binding:"required"`
+	CreatedBy *string                 `json:"created_by"`
+}
+
+// Register records the SHA-256 hashes of files of the AllFiles manifests of a review, as
+// computed by the publisher while copying them.
+func (h *FileHash) Register(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p registerFileHashesParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.RegisterFileHashesParams{
+		Project:      c.Param("project"),
+		ReviewInfoID: int32(id),
+		Files:        p.Files,
+		CreatedBy:    p.CreatedBy,
+	}
+	entities, err := h.uc.Register(c.Request.Context(), params)
+	if err != nil {
+		fileHashError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"files": entities})
+}
+
+// ListByHash returns where a file with the content hash already exists in the project.
+func (h *FileHash) ListByHash(c *gin.Context) {
+	params := &entity.ListFilesByHashParams{
+		Project: c.Param("project"),
+		Hash:    c.Param("sha"),
+	}
+	entities, err := h.uc.ListByHash(c.Request.Context(), params)
+	if err != nil {
+		fileHashError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"files": entities})
+}
+
+type duplicateReportParams struct {
+	Limit *int `form:"limit"`
+}
+
+func (h *FileHash) DuplicateReport(c *gin.Context) {
+	var p duplicateReportParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetDuplicateReportParams{
+		Project: c.Param("project"),
+		Limit:   defaultDuplicateReportLimit,
+	}
+	if p.Limit != nil {
+		params.Limit = *p.Limit
+	}
+	e, err := h.uc.DuplicateReport(c.Request.Context(), params)
+	if err != nil {
+		fileHashError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type negotiateUploadParams struct {
+	Files []*entity.UploadManifestEntry `json:"files" binding:"required"`
+}
+
+// NegotiateUpload takes the manifest of the files a client is about to upload for a review,
+// and returns which of them must be transferred and which are copied from the files of the
+// project with the same content, as found in the content hash registry.
+func (h *FileHash) NegotiateUpload(c *gin.Context) {
+	var p negotiateUploadParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.NegotiateUploadParams{
+		Project: c.Param("project"),
+		Files:   p.Files,
+	}
+	e, err := h.uc.NegotiateUpload(c.Request.Context(), params)
+	if err != nil {
+		fileHashError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
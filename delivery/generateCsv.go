@@ -0,0 +1,912 @@
+package delivery
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GenerateCsv struct {
+	uc *usecase.GenerateCsv
+}
+
+func NewGenerateCsv(uc *usecase.GenerateCsv) *GenerateCsv {
+	return &GenerateCsv{
+		uc: uc,
+	}
+}
+
+func (gc *GenerateCsv) GenerateAssetsCsv(c *gin.Context) {
+	params := &entity.GenerateTrackerCsvParams{
+		Project: c.Param("project"),
+	}
+
+	// Add time
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Now().Add(gc.uc.ReadTimeout)); err != nil {
+		log.Printf("ERROR: failed to set the deadline for the writing response: method=%s, url=%s, err=%s", c.Request.Method, c.Request.URL, err)
+	}
+
+	records, err := gc.assetRecords(c.Request.Context(), params.Project)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "text/csv")
+	c.Writer.Header().Set("Content-Disposition", "attachment;filename=asset_data.csv")
+	writer := csv.NewWriter(c.Writer)
+	defer writer.Flush()
+	if err := writer.WriteAll(records); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to generate CSV")
+		return
+	}
+	// totalTime := time.Since(start)
+	// log.Printf("Total time to generate CSV: %s", totalTime)
+}
+
+func generateCsvError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// recordWriter writes the records of a download, a CSV or XLSX document.
+type recordWriter interface {
+	Write(record []string) error
+	Close() error
+}
+
+// csvRecordWriter closes a csv.Writer by flushing it.
+type csvRecordWriter struct {
+	*csv.Writer
+}
+
+func (w csvRecordWriter) Close() error {
+	w.Flush()
+	return w.Error()
+}
+
+// exportContentType returns the content type of the documents of an export format.
+func exportContentType(format string) string {
+	if format == entity.PivotExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// newRecordWriter returns the writer of the records of a document of an export format.
+func newRecordWriter(w io.Writer, format string, sheet string) (recordWriter, error) {
+	if format == entity.PivotExportFormatXLSX {
+		return newXLSXWriter(w, sheet)
+	}
+	return csvRecordWriter{csv.NewWriter(w)}, nil
+}
+
+// ExportAssetsPivot downloads every asset of the pivot view matching params, which are parsed
+// from the same query as the pivot API, as CSV or XLSX according to `format`. The rows are
+// streamed as they are read, with the columns of each phase of the project's phase template.
+func (gc *GenerateCsv) ExportAssetsPivot(c *gin.Context, params repository.ListAssetsPivotParams) {
+	format := c.DefaultQuery("format", entity.PivotExportFormatCSV)
+	if format != entity.PivotExportFormatCSV && format != entity.PivotExportFormatXLSX {
+		badRequest(c, fmt.Errorf("%w: invalid format %q", entity.ErrBadRequest, format))
+		return
+	}
+
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Now().Add(gc.uc.ReadTimeout)); err != nil {
+		log.Printf("ERROR: failed to set the deadline for the writing response: method=%s, url=%s, err=%s", c.Request.Method, c.Request.URL, err)
+	}
+
+	started, err := gc.exportAssetsPivot(c.Request.Context(), params, format, c.Writer, func() {
+		fileName := "assets_pivot_" + params.Project + "." + format
+		c.Header("Content-Disposition", "attachment;filename="+fileName)
+		c.Header("Content-Type", exportContentType(format))
+	})
+	if err != nil {
+		if !started {
+			generateCsvError(c, err)
+			return
+		}
+		// The status is already sent: the download is left incomplete.
+		log.Printf("ERROR: failed to export the asset pivot: project=%s, err=%s", params.Project, err)
+	}
+}
+
+// exportAssetsPivot writes the pivot export to w. The document only starts with the first
+// rows, so that the errors before them can still be reported with their status: begin is
+// called before and started tells whether it was.
+func (gc *GenerateCsv) exportAssetsPivot(
+	ctx context.Context,
+	params repository.ListAssetsPivotParams,
+	format string,
+	w io.Writer,
+	begin func(),
+) (started bool, err error) {
+	phases, err := gc.uc.ListPivotPhases(ctx, params.Project, params.Root)
+	if err != nil {
+		return false, err
+	}
+	params.Phases = phases
+
+	var out recordWriter
+	start := func() error {
+		begin()
+		var err error
+		if out, err = newRecordWriter(w, format, "pivot"); err != nil {
+			return err
+		}
+		return out.Write(pivotExportHeader(phases))
+	}
+	err = gc.uc.ExportAssetsPivot(ctx, params, func(rows []repository.AssetPivot) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			if err := out.Write(pivotExportRow(row, phases)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil && out == nil {
+		err = start()
+	}
+	if err != nil {
+		return out != nil, err
+	}
+	return true, out.Close()
+}
+
+// RenderExport writes the document of an export job, for the export workers.
+func (gc *GenerateCsv) RenderExport(ctx context.Context, job *entity.ExportJob, w io.Writer) error {
+	if job.Kind == entity.ExportKindPivot {
+		var params repository.ListAssetsPivotParams
+		if err := json.Unmarshal(job.Query, &params); err != nil {
+			return fmt.Errorf("invalid pivot query: %w", err)
+		}
+		params.Project = job.Project
+		_, err := gc.exportAssetsPivot(ctx, params, job.Format, w, func() {})
+		return err
+	}
+	records, err := gc.assetRecords(ctx, job.Project)
+	if err != nil {
+		return err
+	}
+	out, err := newRecordWriter(w, job.Format, "assets")
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// pivotExportHeader returns the header of the pivot export, named after the fields of the
+// pivot API.
+func pivotExportHeader(phases []string) []string {
+	header := []string{
+		"group_1", "relation", "leaf_group_name", "group_category_path", "top_group_node", "tags",
+	}
+	for _, phase := range phases {
+		for _, column := range []string{
+			"work_status", "approval_status", "submitted_at_utc", "take",
+			"official_revision", "is_official", "sla_state",
+		} {
+			header = append(header, phase+"_"+column)
+		}
+	}
+	return header
+}
+
+func pivotExportRow(row repository.AssetPivot, phases []string) []string {
+	record := []string{
+		row.Group1, row.Relation, row.LeafGroupName, row.GroupCategoryPath, row.TopGroupNode,
+		strings.Join(row.Tags, "\n"),
+	}
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	for _, phase := range phases {
+		p := row.Phases[strings.ToLower(phase)]
+		if p == nil {
+			record = append(record, "", "", "", "", "", "", "")
+			continue
+		}
+		var submittedAt string
+		if p.SubmittedAtUTC != nil {
+			submittedAt = p.SubmittedAtUTC.UTC().Format(time.RFC3339)
+		}
+		record = append(record,
+			str(p.WorkStatus), str(p.ApprovalStatus), submittedAt, str(p.Take),
+			str(p.OfficialRevision), strconv.FormatBool(p.IsOfficial), str(p.SLAState),
+		)
+	}
+	return record
+}
+
+// assetRecords returns the records of the assets tracker CSV of the project.
+func (gc *GenerateCsv) assetRecords(ctx context.Context, project string) ([][]string, error) {
+	start := time.Now()
+	groupCategoryData, err := gc.uc.ListAssetsGroupCategory(ctx, project)
+	log.Printf("ListAssetsGroupCategory took %s", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	start = time.Now()
+	AllBldReviewData, err := gc.uc.ListAllBldReviews(ctx, project)
+	log.Printf("ListAllBldReviews took %s", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	start = time.Now()
+	commentsData, err := gc.uc.ListComments(ctx, project)
+	log.Printf("ListComments took %s", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	start = time.Now()
+	shotAssetsAllData, err := gc.uc.ListShotAssetsAll(ctx, project)
+	log.Printf("ListShotAssetsAll took %s", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	start = time.Now()
+	bldDocumentData, err := gc.uc.ListBldAnmBldRendDocuments(ctx, project)
+	log.Printf("ListBldAnmBldRendDocuments took %s", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	start = time.Now()
+	publishOperationInfoData, err := gc.uc.ListPublishOperationInfos(ctx, project)
+	log.Printf("ListPublishOperationInfos took %s", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	start = time.Now()
+	latestReviewInfoData, err := gc.uc.ListLatestAssetsReviews(ctx, project)
+	log.Printf("ListLatestAssetsReviews took %s", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	assetFields, assetMetadata, err := gc.uc.ListAssetCustomFields(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	assetTags, err := gc.uc.ListAssetTags(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	phases, err := gc.uc.ListAssetPhases(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	generateData := entity.GenerateData{
+		ReviewInfo:            latestReviewInfoData,
+		GroupCategories:       groupCategoryData,
+		LatestDocuments:       bldDocumentData,
+		Comments:              commentsData,
+		ShotAssetsAlls:        shotAssetsAllData,
+		PublishOperationInfos: publishOperationInfoData,
+		ComponentReviewInfos:  AllBldReviewData,
+
+		AssetFields:   assetFields,
+		AssetMetadata: assetMetadata,
+		AssetTags:     assetTags,
+
+		Phases: phases,
+	}
+
+	return generateRecords(&generateData), nil
+}
+
+func toStrSlice(v interface{}) []string {
+	if v == nil {
+		return nil
+	}
+	switch val := v.(type) {
+	case []string:
+		return val
+	case primitive.A: // MongoDBの配列型
+		var res []string
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	case []interface{}:
+		var res []string
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func toMapSlice(v interface{}) []map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	switch val := v.(type) {
+	case []map[string]interface{}:
+		return val
+	case primitive.A:
+		var res []map[string]interface{}
+		for _, item := range val {
+			if m, ok := item.(map[string]interface{}); ok {
+				res = append(res, m)
+			} else if m, ok := item.(primitive.M); ok {
+				res = append(res, (map[string]interface{})(m))
+			}
+		}
+		return res
+	case []interface{}:
+		var res []map[string]interface{}
+		for _, item := range val {
+			if m, ok := item.(map[string]interface{}); ok {
+				res = append(res, m)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func toTimeStrFromInterface(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	switch val := v.(type) {
+	case string:
+		// 文字列の場合はそのまま返す（必要であればフォーマットチェックを入れる）
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case primitive.DateTime:
+		return val.Time().Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
+
+func collectRowData(gd *entity.GenerateData) map[string]*entity.AssetRowData {
+	var assetRowsData = make(map[string]*entity.AssetRowData)
+
+	// ReviewInfo
+	for _, ri := range gd.ReviewInfo {
+		assetRelation := ri.Group1 + "/" + ri.Relation
+		assetRowData, ok := assetRowsData[assetRelation]
+		if !ok {
+			assetRowData = &entity.AssetRowData{
+				MdlData: &entity.PhaseRowData{},
+				RigData: &entity.PhaseRowData{},
+				LdvData: &entity.PhaseRowData{},
+			}
+			assetRowsData[assetRelation] = assetRowData
+		}
+
+		switch ri.Phase {
+		case "mdl":
+			assetRowData.MdlData.WorkStatus = ri.WorkStatus
+			assetRowData.MdlData.ApprovalStatus = ri.ApprovalStatus
+		case "rig":
+			assetRowData.RigData.WorkStatus = ri.WorkStatus
+			assetRowData.RigData.ApprovalStatus = ri.ApprovalStatus
+		case "lookDev":
+			assetRowData.LdvData.WorkStatus = ri.WorkStatus
+			assetRowData.LdvData.ApprovalStatus = ri.ApprovalStatus
+		}
+	}
+
+	// GroupCategory
+	for _, gc := range gd.GroupCategories {
+		categoryParts := strings.Split(gc.CategoryPath, "/")
+		if len(categoryParts) < 2 {
+			continue
+		}
+		for key, assetRowData := range assetRowsData {
+			keyParts := strings.Split(key, "/")
+			if keyParts[0] == gc.GroupPath {
+				assetRowData.AssetTypes = append(assetRowData.AssetTypes, categoryParts[0])
+				assetRowData.AssetGroups = append(assetRowData.AssetGroups, categoryParts[1])
+			}
+		}
+	}
+
+	// bld Latest Publish Operation Info
+	for _, ld := range gd.LatestDocuments {
+		rawGroups, okGroups := (*ld)["groups"]
+		relation, okRelation := (*ld)["relation"]
+		submittedAtUtc, okSubmittedAtUtc := (*ld)["submitted_at_utc"]
+
+		groups := toStrSlice(rawGroups)
+
+		if !okGroups || !okRelation || !okSubmittedAtUtc || len(groups) == 0 {
+			continue
+		}
+		assetRelation := groups[0] + "/" + relation.(string)
+		if _, ok := assetRowsData[assetRelation]; !ok {
+			continue
+		}
+		assetRowData := assetRowsData[assetRelation]
+
+		dateStr := toTimeStrFromInterface(submittedAtUtc)
+		switch (*ld)["component"].(string) {
+		case "bldAnm":
+			if assetRowData.BldAnmReleaseDate != "" {
+				continue
+			}
+			assetRowData.BldAnmReleaseDate = dateStr
+		case "bldRend":
+			if assetRowData.BldRendReleaseDate != "" {
+				continue
+			}
+			assetRowData.BldRendReleaseDate = dateStr
+		}
+	}
+
+	// Comment
+	for _, comment := range gd.Comments {
+		rawGroups, okGroups := (*comment)["groups"]
+		relation, okRelation := (*comment)["relation"]
+		rawCommentsData, okCommentsData := (*comment)["comment_data"]
+		phase, okPhase := (*comment)["phase"]
+
+		if !okGroups || !okRelation || !okCommentsData || !okPhase {
+			continue
+		}
+
+		groups := toStrSlice(rawGroups)
+		commentsData := toMapSlice(rawCommentsData)
+
+		if len(groups) == 0 || len(commentsData) == 0 {
+			continue
+		}
+
+		assetRelation := groups[0] + "/" + relation.(string)
+		if _, ok := assetRowsData[assetRelation]; !ok {
+			continue
+		}
+		assetRowData := assetRowsData[assetRelation]
+
+		for _, cd := range commentsData {
+			language, okLanguage := cd["language"]
+			role, okRole := cd["responsible_person_role"]
+			text, okText := cd["text"]
+			if !okLanguage || !okRole || !okText {
+				continue
+			}
+			switch phase.(string) {
+			case "mdl":
+				switch language.(string) {
+				case "ja":
+					switch role.(string) {
+					case "artist":
+						if assetRowData.MdlData.LatestArtistCommentJa != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestArtistCommentJa = text.(string)
+					case "supervisor":
+						if assetRowData.MdlData.LatestSupervisorCommentJa != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestSupervisorCommentJa = text.(string)
+					case "director":
+						if assetRowData.MdlData.LatestDirectorCommentJa != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestDirectorCommentJa = text.(string)
+					case "client":
+						if assetRowData.MdlData.LatestClientCommentJa != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestClientCommentJa = text.(string)
+					}
+				case "en":
+					switch role.(string) {
+					case "artist":
+						if assetRowData.MdlData.LatestArtistCommentEn != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestArtistCommentEn = text.(string)
+					case "supervisor":
+						if assetRowData.MdlData.LatestSupervisorCommentEn != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestSupervisorCommentEn = text.(string)
+					case "director":
+						if assetRowData.MdlData.LatestDirectorCommentEn != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestDirectorCommentEn = text.(string)
+					case "client":
+						if assetRowData.MdlData.LatestClientCommentEn != "" {
+							continue
+						}
+						assetRowData.MdlData.LatestClientCommentEn = text.(string)
+					}
+				}
+			case "rig":
+				switch language.(string) {
+				case "ja":
+					switch role.(string) {
+					case "artist":
+						if assetRowData.RigData.LatestArtistCommentJa != "" {
+							continue
+						}
+						assetRowData.RigData.LatestArtistCommentJa = text.(string)
+					case "supervisor":
+						if assetRowData.RigData.LatestSupervisorCommentJa != "" {
+							continue
+						}
+						assetRowData.RigData.LatestSupervisorCommentJa = text.(string)
+					case "director":
+						if assetRowData.RigData.LatestDirectorCommentJa != "" {
+							continue
+						}
+						assetRowData.RigData.LatestDirectorCommentJa = text.(string)
+					case "client":
+						if assetRowData.RigData.LatestClientCommentJa != "" {
+							continue
+						}
+						assetRowData.RigData.LatestClientCommentJa = text.(string)
+					}
+				case "en":
+					switch role.(string) {
+					case "artist":
+						if assetRowData.RigData.LatestArtistCommentEn != "" {
+							continue
+						}
+						assetRowData.RigData.LatestArtistCommentEn = text.(string)
+					case "supervisor":
+						if assetRowData.RigData.LatestSupervisorCommentEn != "" {
+							continue
+						}
+						assetRowData.RigData.LatestSupervisorCommentEn = text.(string)
+					case "director":
+						if assetRowData.RigData.LatestDirectorCommentEn != "" {
+							continue
+						}
+						assetRowData.RigData.LatestDirectorCommentEn = text.(string)
+					case "client":
+						if assetRowData.RigData.LatestClientCommentEn != "" {
+							continue
+						}
+						assetRowData.RigData.LatestClientCommentEn = text.(string)
+					}
+				}
+			case "ldv":
+				switch language.(string) {
+				case "ja":
+					switch role.(string) {
+					case "artist":
+						if assetRowData.LdvData.LatestArtistCommentJa != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestArtistCommentJa = text.(string)
+					case "supervisor":
+						if assetRowData.LdvData.LatestSupervisorCommentJa != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestSupervisorCommentJa = text.(string)
+					case "director":
+						if assetRowData.LdvData.LatestDirectorCommentJa != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestDirectorCommentJa = text.(string)
+					case "client":
+						if assetRowData.LdvData.LatestClientCommentJa != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestClientCommentJa = text.(string)
+					}
+				case "en":
+					switch role.(string) {
+					case "artist":
+						if assetRowData.LdvData.LatestArtistCommentEn != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestArtistCommentEn = text.(string)
+					case "supervisor":
+						if assetRowData.LdvData.LatestSupervisorCommentEn != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestSupervisorCommentEn = text.(string)
+					case "director":
+						if assetRowData.LdvData.LatestDirectorCommentEn != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestDirectorCommentEn = text.(string)
+					case "client":
+						if assetRowData.LdvData.LatestClientCommentEn != "" {
+							continue
+						}
+						assetRowData.LdvData.LatestClientCommentEn = text.(string)
+					}
+				}
+			}
+		}
+	}
+
+	// Shot AssetsAll
+	for _, sa := range gd.ShotAssetsAlls {
+		rawComponentInfos, okComponentInfos := (*sa)["component_info"]
+		rawGroups, okGroups := (*sa)["groups"]
+
+		groups := toStrSlice(rawGroups)
+		componentInfos := toMapSlice(rawComponentInfos)
+
+		if !okComponentInfos || !okGroups || len(groups) == 0 {
+			continue
+		}
+		groupsPath := strings.Join(groups, "/")
+		for _, componentInfo := range componentInfos {
+			rawSceneAssets, okSceneAssets := componentInfo["scene_assets"]
+			if !okSceneAssets {
+				continue
+			}
+
+			sceneAssets := toMapSlice(rawSceneAssets)
+
+			for _, sceneAsset := range sceneAssets {
+				group, okGroup := sceneAsset["group"]
+				relation, okRelation := sceneAsset["relation"]
+				if !okGroup || !okRelation {
+					continue
+				}
+				assetRelation := group.(string) + "/" + relation.(string)
+				if _, ok := assetRowsData[assetRelation]; !ok {
+					continue
+				}
+				assetRowData := assetRowsData[assetRelation]
+				assetRowData.ShotAssetsAll = append(assetRowData.ShotAssetsAll, groupsPath)
+			}
+		}
+	}
+
+	// PublishOperationInfo
+	for _, poi := range gd.PublishOperationInfos {
+		phase, okPhase := (*poi)["phase"]
+		revision, okRevision := (*poi)["revision"]
+		submittedUser, okSubmittedUser := (*poi)["submitted_user"]
+		submittedAtUtc, okSubmittedAtUtc := (*poi)["submitted_at_utc"]
+		rawGroups, okGroups := (*poi)["groups"]
+		relation, okRelation := (*poi)["relation"]
+
+		if !okPhase || !okRevision || !okSubmittedUser || !okSubmittedAtUtc || !okGroups || !okRelation {
+			continue
+		}
+
+		groups := toStrSlice(rawGroups)
+
+		if len(groups) == 0 {
+			continue
+		}
+
+		assetRelation := groups[0] + "/" + relation.(string)
+		if _, ok := assetRowsData[assetRelation]; !ok {
+			continue
+		}
+		assetRowData := assetRowsData[assetRelation]
+		dateStr := toTimeStrFromInterface(submittedAtUtc)
+		switch phase.(string) {
+		case "mdl":
+			assetRowData.MdlData.Versions = append(assetRowData.MdlData.Versions, revision.(string))
+			assetRowData.MdlData.LatestPublisher = submittedUser.(string)
+			assetRowData.MdlData.LatestPublishAt = dateStr
+		case "rig":
+			assetRowData.RigData.Versions = append(assetRowData.RigData.Versions, revision.(string))
+			assetRowData.RigData.LatestPublisher = submittedUser.(string)
+			assetRowData.RigData.LatestPublishAt = dateStr
+		case "ldv":
+			assetRowData.LdvData.Versions = append(assetRowData.LdvData.Versions, revision.(string))
+			assetRowData.LdvData.LatestPublisher = submittedUser.(string)
+			assetRowData.LdvData.LatestPublishAt = dateStr
+		}
+	}
+
+	// bldAnm, bldRend Status
+	for i := len(gd.ComponentReviewInfos) - 1; i >= 0; i-- {
+		bri := gd.ComponentReviewInfos[i]
+		assetRelation := bri.Group1 + "/" + bri.Relation
+		if _, ok := assetRowsData[assetRelation]; !ok {
+			continue
+		}
+		assetRowData := assetRowsData[assetRelation]
+
+		if slices.Contains(bri.TargetComponents, "bldAnm") {
+			if assetRowData.BldAnmStatus != "" {
+				continue
+			}
+			assetRowData.BldAnmStatus = bri.ApprovalStatus
+		}
+		if slices.Contains(bri.TargetComponents, "bldRend") {
+			if assetRowData.BldRendStatus != "" {
+				continue
+			}
+			assetRowData.BldRendStatus = bri.ApprovalStatus
+		}
+	}
+
+	// Tags
+	for assetRelation, assetRowData := range assetRowsData {
+		assetRowData.Tags = gd.AssetTags[assetRelation]
+	}
+
+	return assetRowsData
+}
+
+func generateRow(assetRelation string, gd *entity.AssetRowData, phases []string) []string {
+	// Build Row
+	row := []string{}
+	// New, Episode, Thumbnail
+	row = append(row, "", "", "")
+	// Asset Type, Asset Group, Asset Name
+	sort.Strings(gd.AssetTypes)
+	sort.Strings(gd.AssetGroups)
+	row = append(row, strings.Join(gd.AssetTypes, "\n"), strings.Join(gd.AssetGroups, "\n"), assetRelation)
+	// Tags, Parent Asset, Description, Asset Info Jp, Asset Info En, Dir Notes Jp, Dir Notes En, CGSV Notes Jp, CGSV Notes En
+	row = append(row, strings.Join(gd.Tags, "\n"), "", "", "", "", "", "", "", "")
+	// shots <-> Assets All
+	sort.Strings(gd.ShotAssetsAll)
+	row = append(row, strings.Join(gd.ShotAssetsAll, "\n"))
+	// row = append(row, "")
+	for _, phase := range phases {
+		row = append(row, phaseRow(phase, gd)...)
+	}
+
+	return row
+}
+
+// csvPhases are the phases exported to the assets CSV, in their default order.
+var csvPhases = []string{"mdl", "rig", "ldv", "bld"}
+
+// csvPhaseLabels are the names of the phases in the CSV header.
+var csvPhaseLabels = map[string]string{
+	"mdl": "Model",
+	"rig": "Rig",
+	"ldv": "LookDev",
+}
+
+// csvPhaseHandoffs are the columns following the columns of a phase, about its handoff to the
+// next one.
+var csvPhaseHandoffs = map[string][]string{
+	"mdl": {"to Rig Jp", "to Rig En", "Elements Sim"},
+	"rig": {"to LookDev Jp", "to LookDev En"},
+	"ldv": {"to Composite Jp", "to Composite En"},
+}
+
+// exportedPhases returns the CSV phases in the order of the phase template, or in the default
+// order when the project has no template.
+func exportedPhases(template []string) []string {
+	if len(template) == 0 {
+		return csvPhases
+	}
+	phases := []string{}
+	for _, phase := range template {
+		if slices.Contains(csvPhases, phase) && !slices.Contains(phases, phase) {
+			phases = append(phases, phase)
+		}
+	}
+	return phases
+}
+
+func phaseHeader(phase string) []string {
+	if phase == "bld" {
+		return []string{
+			"buildAnim Status", "buildAnim Release Date",
+			"buildRend Status", "buildRend Release Date",
+		}
+	}
+	header := []string{}
+	for _, column := range []string{
+		"Status", "Work Status", "submitted at", "Published artist", "Pipeline Step", "Task Name", "Versions",
+		"latest_comment_artist_ja", "latest_comment_artist_en", "latest_comment_supervisor_ja", "latest_comment_supervisor_en", "latest_comment_director_ja", "latest_comment_director_en", "latest_comment_client_ja", "latest_comment_client_en",
+		"Note", "Start Date", "T1 Due Date", "Due Date", "Description", "Bid", "Assigned Studio", "Assigned To",
+	} {
+		header = append(header, csvPhaseLabels[phase]+" "+column)
+	}
+	return append(header, csvPhaseHandoffs[phase]...)
+}
+
+func phaseRow(phase string, gd *entity.AssetRowData) []string {
+	var data *entity.PhaseRowData
+	switch phase {
+	case "mdl":
+		data = gd.MdlData
+	case "rig":
+		data = gd.RigData
+	case "ldv":
+		data = gd.LdvData
+	case "bld":
+		// buildAnim Status, buildAnim Release Date, buildRend Status, buildRend Release Date
+		return []string{gd.BldAnmStatus, gd.BldAnmReleaseDate, gd.BldRendStatus, gd.BldRendReleaseDate}
+	default:
+		return nil
+	}
+	row := []string{}
+	// Status, Work Status, submitted at, Published artist, Pipeline Step, Task Name, Versions
+	sort.Strings(data.Versions)
+	row = append(row, data.ApprovalStatus, data.WorkStatus, data.LatestPublishAt, data.LatestPublisher, phase, phase, strings.Join(data.Versions, "\n"))
+	// latest_comment_artist_ja, latest_comment_artist_en, latest_comment_supervisor_ja, latest_comment_supervisor_en, latest_comment_director_ja, latest_comment_director_en, latest_comment_client_ja, latest_comment_client_en
+	row = append(row, data.LatestArtistCommentJa, data.LatestArtistCommentEn, data.LatestSupervisorCommentJa, data.LatestSupervisorCommentEn,
+		data.LatestDirectorCommentJa, data.LatestDirectorCommentEn, data.LatestClientCommentJa, data.LatestClientCommentEn)
+	// Note, Start Date, T1 Due Date, Due Date, Description, Bid, Assigned Studio, Assigned To
+	row = append(row, "", "", "", "", "", "", "", "")
+	// Handoff to the next phase
+	for range csvPhaseHandoffs[phase] {
+		row = append(row, "")
+	}
+	return row
+}
+
+func generateRecords(gds *entity.GenerateData) [][]string {
+	records := [][]string{}
+
+	// Header
+	header := []string{
+		"New", "Episode", "Thumbnail",
+		"Asset Type", "Asset Group", "Asset Name",
+		"Tags", "Parent Asset", "Description", "Asset Info Jp", "Asset Info En", "Dir Notes Jp", "Dir Notes En", "CGSV Notes Jp", "CGSV Notes En",
+		"shots <-> Assets All",
+	}
+	phases := exportedPhases(gds.Phases)
+	for _, phase := range phases {
+		header = append(header, phaseHeader(phase)...)
+	}
+	for _, f := range gds.AssetFields {
+		header = append(header, f.DisplayName)
+	}
+	records = append(records, header)
+
+	// Data Rows
+	rowsData := collectRowData(gds)
+	assetRelations := make([]string, 0, len(rowsData))
+	for k := range rowsData {
+		assetRelations = append(assetRelations, k)
+	}
+	sort.Strings(assetRelations)
+	for _, assetRelation := range assetRelations {
+		row := generateRow(assetRelation, rowsData[assetRelation], phases)
+		metadata := gds.AssetMetadata[assetRelation]
+		for _, f := range gds.AssetFields {
+			row = append(row, entity.FormatCustomValue(metadata[f.Key]))
+		}
+		records = append(records, row)
+	}
+
+	return records
+}
@@ -0,0 +1,397 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+func NewGraphQL(
+	uc *usecase.GraphQL,
+	role *Role,
+) (*GraphQL, error) {
+	h := &GraphQL{
+		uc:   uc,
+		role: role,
+	}
+	schema, err := h.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	h.schema = schema
+	return h, nil
+}
+
+// GraphQL serves the reviews, the pivot rows of the assets, the publish transactions and the
+// dependencies of the revisions in a single query, as for an asset card.
+type GraphQL struct {
+	uc     *usecase.GraphQL
+	role   *Role
+	schema graphql.Schema
+}
+
+type graphQLRequestKey struct{}
+
+// graphQLRequest is the caller of a query, passed to the resolvers in the context.
+type graphQLRequest struct {
+	studio string
+	user   string
+	lgr    entity.Logger
+}
+
+type graphQLParams struct {
+	Query         string                 `json:"query" binding:"required"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Query runs a GraphQL query. The errors of the resolvers are responded in `errors` beside
+// the data which could be resolved.
+func (h *GraphQL) Query(c *gin.Context) {
+	var p graphQLParams
+	if err := c.ShouldBindJSON(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	ctx := context.WithValue(c.Request.Context(), graphQLRequestKey{}, &graphQLRequest{
+		studio: studio,
+		user:   requestUser(c),
+		lgr:    NewLogger(c.Request),
+	})
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  p.Query,
+		VariableValues: p.Variables,
+		OperationName:  p.OperationName,
+		Context:        ctx,
+	})
+	c.PureJSON(http.StatusOK, result)
+}
+
+// checkProject checks the caller of the query may read the project, with the permission of
+// its role the REST routes reading the reviews require, since every root resolves reviews.
+func (h *GraphQL) checkProject(ctx context.Context, project string) error {
+	req, _ := ctx.Value(graphQLRequestKey{}).(*graphQLRequest)
+	if req == nil {
+		return entity.ErrForbidden
+	}
+	if err := h.uc.CheckProjectAccess(ctx, req.studio, project); err != nil {
+		return err
+	}
+	return h.role.check(ctx, req.studio, project, req.user, entity.PermissionReviewRead)
+}
+
+func graphQLLogger(ctx context.Context) entity.Logger {
+	if req, ok := ctx.Value(graphQLRequestKey{}).(*graphQLRequest); ok {
+		return req.lgr
+	}
+	return nil
+}
+
+// graphQLAsset is the source of the Asset type, whose fields are resolved on demand.
+type graphQLAsset struct {
+	Project  string `json:"project"`
+	Root     string `json:"root"`
+	Group    string `json:"group"`
+	Relation string `json:"relation"`
+}
+
+func (h *GraphQL) newSchema() (graphql.Schema, error) {
+	dependencyType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DataDependency",
+		Fields: graphql.Fields{
+			"root":      &graphql.Field{Type: graphql.String},
+			"group":     &graphql.Field{Type: graphql.String},
+			"relation":  &graphql.Field{Type: graphql.String},
+			"phase":     &graphql.Field{Type: graphql.String},
+			"component": &graphql.Field{Type: graphql.String},
+			"revision":  &graphql.Field{Type: graphql.String},
+			"file_name": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	var reviewInfoType *graphql.Object
+	publishTransactionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PublishTransactionInfo",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"log_id":         &graphql.Field{Type: graphql.String},
+				"project":        &graphql.Field{Type: graphql.String},
+				"studio":         &graphql.Field{Type: graphql.String},
+				"revision_path":  &graphql.Field{Type: graphql.String},
+				"operation":      &graphql.Field{Type: graphql.String},
+				"event":          &graphql.Field{Type: graphql.String},
+				"user":           &graphql.Field{Type: graphql.String},
+				"computer":       &graphql.Field{Type: graphql.String},
+				"created_at_utc": &graphql.Field{Type: graphql.DateTime},
+				"created_by":     &graphql.Field{Type: graphql.String},
+				"reviews": &graphql.Field{
+					Type: graphql.NewList(reviewInfoType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						pt := p.Source.(*entity.PublishTransactionSummary)
+						reviews, _, err := h.uc.ListPublishTransactionReviewInfos(
+							p.Context,
+							&entity.PublishTransactionReviewInfoListParams{
+								Project:        pt.Project,
+								LogID:          pt.LogID,
+								BaseListParams: &entity.BaseListParams{},
+							},
+						)
+						return reviews, err
+					},
+				},
+			}
+		}),
+	})
+
+	reviewInfoType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReviewInfo",
+		Fields: graphql.Fields{
+			"id":                             &graphql.Field{Type: graphql.Int},
+			"uid":                            &graphql.Field{Type: graphql.String},
+			"studio":                         &graphql.Field{Type: graphql.String},
+			"project":                        &graphql.Field{Type: graphql.String},
+			"root":                           &graphql.Field{Type: graphql.String},
+			"groups":                         &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"relation":                       &graphql.Field{Type: graphql.String},
+			"phase":                          &graphql.Field{Type: graphql.String},
+			"component":                      &graphql.Field{Type: graphql.String},
+			"take":                           &graphql.Field{Type: graphql.String},
+			"take_path":                      &graphql.Field{Type: graphql.String},
+			"approval_status":                &graphql.Field{Type: graphql.String},
+			"approval_status_updated_user":   &graphql.Field{Type: graphql.String},
+			"approval_status_updated_at_utc": &graphql.Field{Type: graphql.DateTime},
+			"work_status":                    &graphql.Field{Type: graphql.String},
+			"work_status_updated_user":       &graphql.Field{Type: graphql.String},
+			"work_status_updated_at_utc":     &graphql.Field{Type: graphql.DateTime},
+			"submitted_at_utc":               &graphql.Field{Type: graphql.DateTime},
+			"submitted_user":                 &graphql.Field{Type: graphql.String},
+			"tags":                           &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"publish_transaction_id":         &graphql.Field{Type: graphql.String},
+			"created_at_utc":                 &graphql.Field{Type: graphql.DateTime},
+			"modified_at_utc":                &graphql.Field{Type: graphql.DateTime},
+			"created_by":                     &graphql.Field{Type: graphql.String},
+			"modified_by":                    &graphql.Field{Type: graphql.String},
+			"publish_transaction": &graphql.Field{
+				Type: publishTransactionType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					review := p.Source.(*entity.ReviewInfo)
+					if review.PublishTransactionID == nil {
+						return nil, nil
+					}
+					pt, err := h.uc.GetPublishTransaction(
+						p.Context,
+						&entity.GetPublishTransactionSummaryParams{
+							Project: review.Project,
+							LogID:   *review.PublishTransactionID,
+						},
+					)
+					if errors.Is(err, entity.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return pt, err
+				},
+			},
+			"dependencies": &graphql.Field{
+				Type: graphql.NewList(dependencyType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return h.uc.ListRevisionDependencies(
+						p.Context, graphQLLogger(p.Context), p.Source.(*entity.ReviewInfo),
+					)
+				},
+			},
+		},
+	})
+
+	pivotPhaseType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AssetPivotPhase",
+		Fields: graphql.Fields{
+			"phase":                  &graphql.Field{Type: graphql.String},
+			"work_status":            &graphql.Field{Type: graphql.String},
+			"approval_status":        &graphql.Field{Type: graphql.String},
+			"submitted_at_utc":       &graphql.Field{Type: graphql.DateTime},
+			"take":                   &graphql.Field{Type: graphql.String},
+			"official_revision":      &graphql.Field{Type: graphql.String},
+			"is_official":            &graphql.Field{Type: graphql.Boolean},
+			"sla_state":              &graphql.Field{Type: graphql.String},
+			"publish_transaction_id": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	assetPivotType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AssetPivot",
+		Fields: graphql.Fields{
+			"root":                &graphql.Field{Type: graphql.String},
+			"project":             &graphql.Field{Type: graphql.String},
+			"group_1":             &graphql.Field{Type: graphql.String},
+			"relation":            &graphql.Field{Type: graphql.String},
+			"leaf_group_name":     &graphql.Field{Type: graphql.String},
+			"group_category_path": &graphql.Field{Type: graphql.String},
+			"top_group_node":      &graphql.Field{Type: graphql.String},
+			"tags":                &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"watchers":            &graphql.Field{Type: graphql.Int},
+			"phases": &graphql.Field{
+				Type: graphql.NewList(pivotPhaseType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					pivot := p.Source.(*repository.AssetPivot)
+					// The phases are a list in GraphQL, whose keys cannot be arbitrary.
+					phases := make([]map[string]interface{}, 0, len(pivot.Phases))
+					for phase, columns := range pivot.Phases {
+						phases = append(phases, map[string]interface{}{
+							"phase":                  phase,
+							"work_status":            columns.WorkStatus,
+							"approval_status":        columns.ApprovalStatus,
+							"submitted_at_utc":       columns.SubmittedAtUTC,
+							"take":                   columns.Take,
+							"official_revision":      columns.OfficialRevision,
+							"is_official":            columns.IsOfficial,
+							"sla_state":              columns.SLAState,
+							"publish_transaction_id": columns.PublishTransactionID,
+						})
+					}
+					sort.Slice(phases, func(i, j int) bool {
+						return phases[i]["phase"].(string) < phases[j]["phase"].(string)
+					})
+					return phases, nil
+				},
+			},
+		},
+	})
+
+	assetType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Asset",
+		Fields: graphql.Fields{
+			"project":  &graphql.Field{Type: graphql.String},
+			"root":     &graphql.Field{Type: graphql.String},
+			"group":    &graphql.Field{Type: graphql.String},
+			"relation": &graphql.Field{Type: graphql.String},
+			"pivot": &graphql.Field{
+				Type: assetPivotType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					asset := p.Source.(*graphQLAsset)
+					return h.uc.GetAssetPivot(p.Context, &entity.GetAssetPivotParams{
+						Project:  asset.Project,
+						Root:     asset.Root,
+						Asset:    asset.Group,
+						Relation: asset.Relation,
+					})
+				},
+			},
+			"reviews": &graphql.Field{
+				Type: graphql.NewList(reviewInfoType),
+				Args: graphql.FieldConfigArgument{
+					"phase": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					asset := p.Source.(*graphQLAsset)
+					reviews, err := h.uc.ListAssetReviewInfos(
+						p.Context,
+						&entity.AssetReviewInfoListParams{
+							Project:  asset.Project,
+							Asset:    asset.Group,
+							Relation: asset.Relation,
+						},
+					)
+					if err != nil {
+						return nil, err
+					}
+					phase, ok := p.Args["phase"].(string)
+					if !ok {
+						return reviews, nil
+					}
+					filtered := []*entity.ReviewInfo{}
+					for _, r := range reviews {
+						if r.Phase == phase {
+							filtered = append(filtered, r)
+						}
+					}
+					return filtered, nil
+				},
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"review": &graphql.Field{
+				Type: reviewInfoType,
+				Args: graphql.FieldConfigArgument{
+					"project": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"id":      &graphql.ArgumentConfig{Type: graphql.Int},
+					"uid":     &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					project := p.Args["project"].(string)
+					if err := h.checkProject(p.Context, project); err != nil {
+						return nil, err
+					}
+					params := &entity.GetReviewParams{
+						Project: project,
+					}
+					if id, ok := p.Args["id"].(int); ok {
+						params.ID = int32(id)
+					}
+					if uid, ok := p.Args["uid"].(string); ok {
+						params.UID = &uid
+					}
+					return h.uc.GetReviewInfo(p.Context, params)
+				},
+			},
+			"asset": &graphql.Field{
+				Type: assetType,
+				Args: graphql.FieldConfigArgument{
+					"project":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"root":     &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "assets"},
+					"group":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"relation": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					project := p.Args["project"].(string)
+					if err := h.checkProject(p.Context, project); err != nil {
+						return nil, err
+					}
+					return &graphQLAsset{
+						Project:  project,
+						Root:     p.Args["root"].(string),
+						Group:    p.Args["group"].(string),
+						Relation: p.Args["relation"].(string),
+					}, nil
+				},
+			},
+			"publish_transaction": &graphql.Field{
+				Type: publishTransactionType,
+				Args: graphql.FieldConfigArgument{
+					"project": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"log_id":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					project := p.Args["project"].(string)
+					if err := h.checkProject(p.Context, project); err != nil {
+						return nil, err
+					}
+					return h.uc.GetPublishTransaction(
+						p.Context,
+						&entity.GetPublishTransactionSummaryParams{
+							Project: project,
+							LogID:   p.Args["log_id"].(string),
+						},
+					)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: queryType,
+	})
+}
@@ -0,0 +1,161 @@
+package delivery
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// setTestKeys sets the keys of the tokens to a key of the test.
+func setTestKeys(t *testing.T) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PPI_KEY_PRIVATE", string(pem.EncodeToMemory(&pem.Block{
+		Type:  entity.KeytypePrivate,
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})))
+	t.Setenv("PPI_KEY_PUBLIC", string(pem.EncodeToMemory(&pem.Block{
+		Type:  entity.KeytypePublic,
+		Bytes: public,
+	})))
+}
+
+// TestGraphQLRolePermission checks the root resolvers require the permission of the role the
+// REST routes reading the reviews do, in a project of the test studio.
+func TestGraphQLRolePermission(t *testing.T) {
+	db := openTestDB(t)
+	studio := os.Getenv(testStudioEnv)
+	if studio == "" {
+		t.Skipf("%s is not set", testStudioEnv)
+	}
+	setTestKeys(t)
+	psRepo, err := repository.NewProjectStudioMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stuRepo, err := repository.NewStudioInfo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authRepo, err := repository.NewAuth(db, psRepo, stuRepo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects, err := authRepo.ListStudioProjects(db, studio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) == 0 {
+		t.Skipf("studio %s has no project", studio)
+	}
+	project := projects[0]
+	roleRepo, err := repository.NewRole(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the role makes the project enforce the roles until it is deleted
+	viewer := fmt.Sprintf("viewer%d@example.com", time.Now().UnixNano()%1e12)
+	if _, err := roleRepo.CreateUserRole(db, &entity.CreateUserRoleParams{
+		Role:      entity.RoleViewer,
+		Project:   project,
+		User:      viewer,
+		CreatedBy: "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Where("`project` = ?", project).Where("`user` = ?", viewer).Delete(&model.UserRole{})
+	})
+
+	authUC := usecase.NewAuth(authRepo, nil, roleRepo, nil, 10*time.Second, 10*time.Second)
+	h, err := NewGraphQL(
+		usecase.NewGraphQL(nil, nil, authUC, 10*time.Second),
+		NewRole(usecase.NewRole(roleRepo, nil, 10*time.Second, 10*time.Second)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc    string
+		user    string
+		wantErr bool
+	}{
+		{"the role grants the permission", viewer, false},
+		{"a user without a role is denied", "other" + viewer, true},
+		{"no user is denied", "", true},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := gin.New()
+			// authenticates the request as ParseHeaderToken does
+			r.Use(func(c *gin.Context) {
+				c.Set("studio", studio)
+				setUser(c, tt.user)
+			})
+			r.POST("/api/graphql", h.Query)
+
+			body, err := json.Marshal(graphQLParams{
+				Query: `query($project: String!) {
+					asset(project: $project, group: "g", relation: "r") { project }
+				}`,
+				Variables: map[string]interface{}{"project": project},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/graphql", bytes.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			var result struct {
+				Data struct {
+					Asset *graphQLAsset `json:"asset"`
+				} `json:"data"`
+				Errors []struct {
+					Message string `json:"message"`
+				} `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if result.Data.Asset != nil || len(result.Errors) == 0 {
+					t.Fatalf("the asset was resolved: %s", w.Body)
+				}
+				return
+			}
+			if len(result.Errors) != 0 || result.Data.Asset == nil {
+				t.Fatalf("the asset was not resolved: %s", w.Body)
+			}
+			if result.Data.Asset.Project != project {
+				t.Errorf("project = %q, want %q", result.Data.Asset.Project, project)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/entity/groupCategory"
+	"github.com/gin-gonic/gin"
+)
+
+type assignGroupCategoryParams struct {
+	Groups     []string `json:"groups"`
+	ModifiedBy *string  `json:"modified_by"`
+}
+
+// Assign assigns the groups selected in the pivot to a category at once.
+func (h *GroupCategory) Assign(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p assignGroupCategoryParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &groupCategory.AssignParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		Groups:     p.Groups,
+		ModifiedBy: p.ModifiedBy,
+	}
+	e, err := h.uc.Assign(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
@@ -0,0 +1,351 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/proto/pipelinepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// grpcMaxMessageSize allows the review infos with large AllFiles manifests.
+const grpcMaxMessageSize = 64 << 20
+
+// NewGRPCServer returns the gRPC server of the pipeline clients, which serves the calls with
+// the handler of the REST API.
+func NewGRPCServer(handler http.Handler) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.MaxRecvMsgSize(grpcMaxMessageSize),
+		grpc.MaxSendMsgSize(grpcMaxMessageSize),
+	)
+	pipelinepb.RegisterPipelineServiceServer(s, &GRPC{
+		handler: handler,
+	})
+	return s
+}
+
+// GRPC serves the calls of the pipeline clients as requests of the REST API dispatched in
+// process, so that they go through the same authentication, limits, validation and usecases
+// without the cost of a HTTP request per record. The metadata of a call are the headers of
+// its requests.
+type GRPC struct {
+	pipelinepb.UnimplementedPipelineServiceServer
+	handler http.Handler
+}
+
+// grpcResponseWriter records the response of a dispatched request.
+type grpcResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *grpcResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *grpcResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *grpcResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// grpcCode returns the gRPC status code of the HTTP status of a dispatched request.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// dispatch serves a request of the REST API with the JSON body and decodes the JSON of its
+// response into out. The failures are returned as gRPC statuses with the message of the
+// response.
+func (h *GRPC) dispatch(
+	ctx context.Context,
+	method string,
+	path string,
+	body []byte,
+	out proto.Message,
+) error {
+	req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			// the pseudo-headers and the headers of the gRPC transport
+			if strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-") ||
+				key == "content-type" || key == "te" {
+				continue
+			}
+			for _, v := range values {
+				req.Header.Add(key, v)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	w := &grpcResponseWriter{header: http.Header{}}
+	h.handler.ServeHTTP(w, req)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status >= http.StatusBadRequest {
+		var res struct {
+			Message           string `json:"message"`
+			RetryAfterSeconds int    `json:"retry_after_seconds"`
+		}
+		msg := http.StatusText(w.status)
+		if json.Unmarshal(w.body.Bytes(), &res) == nil && res.Message != "" {
+			msg = res.Message
+		}
+		if res.RetryAfterSeconds > 0 {
+			msg = fmt.Sprintf("%s (retry after %d seconds)", msg, res.RetryAfterSeconds)
+		}
+		return status.Error(grpcCode(w.status), msg)
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(
+		w.body.Bytes(), out,
+	); err != nil {
+		return status.Errorf(codes.Internal, "invalid response of %s %s: %s", method, path, err)
+	}
+	return nil
+}
+
+func projectPath(project string, elems ...string) string {
+	path := "/api/projects/" + url.PathEscape(project)
+	for _, e := range elems {
+		path += "/" + url.PathEscape(e)
+	}
+	return path
+}
+
+// reviewInfoBody returns the REST body of a review info. protojson formats the 64-bit
+// integers as strings, which the REST API reads as numbers.
+func reviewInfoBody(e *pipelinepb.ReviewInfo) ([]byte, error) {
+	b, err := (protojson.MarshalOptions{UseProtoNames: true}).Marshal(e)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if size, ok := fields["size_all_files"]; ok {
+		var s string
+		if json.Unmarshal(size, &s) == nil {
+			fields["size_all_files"] = json.RawMessage(s)
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// structBody returns the REST body of a JSON object, which must be given.
+func structBody(name string, m interface{ GetBody() *structpb.Struct }) ([]byte, error) {
+	if m.GetBody() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is required", name)
+	}
+	return protojson.Marshal(m.GetBody())
+}
+
+// resultError sets the status of a failed request of a bulk stream.
+func resultError(result *pipelinepb.CreateResult, err error) {
+	s := status.Convert(err)
+	result.Code = int32(s.Code())
+	result.Message = s.Message()
+}
+
+func (h *GRPC) CreateReviewInfo(
+	ctx context.Context,
+	req *pipelinepb.CreateReviewInfoRequest,
+) (*pipelinepb.ReviewInfo, error) {
+	if req.GetReviewInfo() == nil {
+		return nil, status.Error(codes.InvalidArgument, "review_info is required")
+	}
+	body, err := reviewInfoBody(req.GetReviewInfo())
+	if err != nil {
+		return nil, err
+	}
+	e := &pipelinepb.ReviewInfo{}
+	if err := h.dispatch(
+		ctx, http.MethodPost, projectPath(req.GetProject(), "reviews"), body, e,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (h *GRPC) GetReviewInfo(
+	ctx context.Context,
+	req *pipelinepb.GetReviewInfoRequest,
+) (*pipelinepb.ReviewInfo, error) {
+	e := &pipelinepb.ReviewInfo{}
+	if err := h.dispatch(
+		ctx, http.MethodGet, projectPath(req.GetProject(), "reviews", req.GetId()), nil, e,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (h *GRPC) CreateReviewInfos(stream pipelinepb.PipelineService_CreateReviewInfosServer) error {
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result := &pipelinepb.CreateResult{Index: index}
+		if e, err := h.CreateReviewInfo(stream.Context(), req); err != nil {
+			resultError(result, err)
+		} else {
+			result.Record = &pipelinepb.CreateResult_ReviewInfo{ReviewInfo: e}
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *GRPC) CreatePublishLog(
+	ctx context.Context,
+	req *pipelinepb.CreatePublishLogRequest,
+) (*pipelinepb.PublishLog, error) {
+	body, err := structBody("publish_log", req.GetPublishLog())
+	if err != nil {
+		return nil, err
+	}
+	e := &pipelinepb.PublishLog{Body: &structpb.Struct{}}
+	if err := h.dispatch(
+		ctx, http.MethodPost, projectPath(req.GetProject(), "publishLogs"), body, e.Body,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (h *GRPC) CreatePublishLogs(stream pipelinepb.PipelineService_CreatePublishLogsServer) error {
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result := &pipelinepb.CreateResult{Index: index}
+		if e, err := h.CreatePublishLog(stream.Context(), req); err != nil {
+			resultError(result, err)
+		} else {
+			result.Record = &pipelinepb.CreateResult_PublishLog{PublishLog: e}
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *GRPC) CreatePublishTransactionInfo(
+	ctx context.Context,
+	req *pipelinepb.CreatePublishTransactionInfoRequest,
+) (*pipelinepb.PublishTransactionInfo, error) {
+	body, err := structBody("publish_transaction_info", req.GetPublishTransactionInfo())
+	if err != nil {
+		return nil, err
+	}
+	e := &pipelinepb.PublishTransactionInfo{Body: &structpb.Struct{}}
+	if err := h.dispatch(
+		ctx, http.MethodPost, projectPath(req.GetProject(), "publishTransactionInfos"), body, e.Body,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (h *GRPC) GetPublishTransactionInfo(
+	ctx context.Context,
+	req *pipelinepb.GetPublishTransactionInfoRequest,
+) (*pipelinepb.PublishTransactionInfo, error) {
+	e := &pipelinepb.PublishTransactionInfo{Body: &structpb.Struct{}}
+	if err := h.dispatch(
+		ctx,
+		http.MethodGet,
+		projectPath(req.GetProject(), "publishTransactionInfos", req.GetLogId()),
+		nil,
+		e.Body,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (h *GRPC) CreatePublishTransactionInfos(
+	stream pipelinepb.PipelineService_CreatePublishTransactionInfosServer,
+) error {
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result := &pipelinepb.CreateResult{Index: index}
+		if e, err := h.CreatePublishTransactionInfo(stream.Context(), req); err != nil {
+			resultError(result, err)
+		} else {
+			result.Record = &pipelinepb.CreateResult_PublishTransactionInfo{
+				PublishTransactionInfo: e,
+			}
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
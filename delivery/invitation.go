@@ -0,0 +1,200 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewInvitation(
+	uc *usecase.Invitation,
+) *Invitation {
+	return &Invitation{
+		uc: uc,
+	}
+}
+
+// Invitation onboards users, e.g. freelancers, to projects with invitations scoped to a role,
+// which are managed by admins and redeemed by the invitees after their first login.
+type Invitation struct {
+	uc *usecase.Invitation
+}
+
+func invitationError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		forbidden(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// invitationAdmin returns the studio of an admin, or responds with an error and returns false.
+func invitationAdmin(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf("%w: invitations can only be managed by admins", entity.ErrForbidden))
+		return "", false
+	}
+	return studio, true
+}
+
+type listInvitationsParams struct {
+	PerPage *int    `form:"per_page"`
+	Page    *int    `form:"page"`
+	Project *string `form:"project"`
+	Status  *string `form:"status"`
+}
+
+func (h *Invitation) List(c *gin.Context) {
+	if _, ok := invitationAdmin(c); !ok {
+		return
+	}
+	var p listInvitationsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListInvitationsParams{
+		Project: p.Project,
+		Status:  p.Status,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"invitations",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
+
+func (h *Invitation) Get(c *gin.Context) {
+	if _, ok := invitationAdmin(c); !ok {
+		return
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := h.uc.Get(c.Request.Context(), &entity.GetInvitationParams{ID: id})
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createInvitationParams struct {
+	Project                string     `json:"project"`
+	Role                   string     `json:"role"`
+	Invitee                *string    `json:"invitee"`
+	ExpiresAtUTC           time.Time  `json:"expires_at_utc"`
+	MembershipExpiresAtUTC *time.Time `json:"membership_expires_at_utc"`
+	CreatedBy              *string    `json:"created_by"`
+}
+
+// Post creates an invitation to a role of a project. Its code, to be sent to the invitee, is
+// only returned in the response.
+func (h *Invitation) Post(c *gin.Context) {
+	studio, ok := invitationAdmin(c)
+	if !ok {
+		return
+	}
+	var p createInvitationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateInvitationParams{
+		Project:                p.Project,
+		Role:                   p.Role,
+		Invitee:                p.Invitee,
+		ExpiresAtUTC:           p.ExpiresAtUTC,
+		MembershipExpiresAtUTC: p.MembershipExpiresAtUTC,
+		CreatedBy:              studio,
+	}
+	if p.CreatedBy != nil {
+		params.CreatedBy = *p.CreatedBy
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type redeemInvitationParams struct {
+	Code string  `json:"code"`
+	User *string `json:"user"`
+}
+
+// Redeem assigns the role of an invitation to the user, given by `user` or authenticated by
+// the SSO login or the API key.
+func (h *Invitation) Redeem(c *gin.Context) {
+	var p redeemInvitationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	params := &entity.RedeemInvitationParams{
+		Code:   p.Code,
+		Studio: studio,
+		User:   requestUser(c),
+	}
+	if p.User != nil {
+		params.User = *p.User
+	}
+	e, err := h.uc.Redeem(c.Request.Context(), params)
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+// Delete revokes a pending invitation, which is kept for the audit of the onboardings.
+func (h *Invitation) Delete(c *gin.Context) {
+	if _, ok := invitationAdmin(c); !ok {
+		return
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := h.uc.Revoke(c.Request.Context(), &entity.RevokeInvitationParams{ID: id})
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
@@ -0,0 +1,349 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// JSONEncoding is the encoding of the JSON responses of an API version, for the clients which
+// cannot read the default one, e.g. Python clients.
+type JSONEncoding struct {
+	// UTCTimes writes the datetimes in UTC with a "Z" designator and microseconds. The
+	// datetimes without time zone, read as strings from the database, are in its time zone.
+	UTCTimes bool `json:"utc_times"`
+	// StringIDs writes the integer IDs, the "id" and "*_id" members and the items of the
+	// "ids" and "*_ids" arrays, as strings, so that int64 IDs are not rounded.
+	StringIDs bool `json:"string_ids"`
+	// OmitNulls omits the null members of objects, so that a missing value is always omitted
+	// whether its field is a nil pointer or omitted when empty.
+	OmitNulls bool `json:"omit_nulls"`
+}
+
+func (e JSONEncoding) isDefault() bool {
+	return e == JSONEncoding{}
+}
+
+// defaultJSONEncodings are the encodings of the API versions. The versions without one are
+// encoded as the handlers write them.
+var defaultJSONEncodings = map[int]JSONEncoding{
+	APIVersion3: {UTCTimes: true, StringIDs: true, OmitNulls: true},
+}
+
+// ParseJSONEncodings parses the JSON object of the encodings of the API versions given by the
+// environment, e.g. {"2": {"utc_times": true}}, which replace their default encodings.
+func ParseJSONEncodings(s string) (map[int]JSONEncoding, error) {
+	encodings := map[int]JSONEncoding{}
+	for version, e := range defaultJSONEncodings {
+		encodings[version] = e
+	}
+	if strings.TrimSpace(s) == "" {
+		return encodings, nil
+	}
+	var overrides map[string]JSONEncoding
+	if err := json.Unmarshal([]byte(s), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid JSON encodings: %w", err)
+	}
+	for key, e := range overrides {
+		version, err := parseAPIVersion(key)
+		if err != nil || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid JSON encoding of API version %q", key)
+		}
+		encodings[version] = e
+	}
+	return encodings, nil
+}
+
+// ResponseEncoding re-encodes the JSON responses of the API versions with an encoding, so that
+// every handler is serialized the same whatever the way it writes its response.
+type ResponseEncoding struct {
+	encodings map[int]JSONEncoding
+}
+
+func NewResponseEncoding(encodings map[int]JSONEncoding) *ResponseEncoding {
+	return &ResponseEncoding{
+		encodings: encodings,
+	}
+}
+
+// Encode is the middleware re-encoding the JSON responses of the negotiated version, which
+// must run after APIVersioning.Negotiate. The other responses, e.g. downloads, are written as
+// they are.
+func (e *ResponseEncoding) Encode(c *gin.Context) {
+	enc := e.encodings[RequestAPIVersion(c)]
+	if enc.isDefault() {
+		c.Next()
+		return
+	}
+	w := &encodingWriter{
+		ResponseWriter: c.Writer,
+		encoding:       enc,
+		status:         http.StatusOK,
+	}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+	w.flush()
+}
+
+// encodingWriter holds the JSON responses until flush to re-encode them. Whether a response
+// is held is decided on its first write, from its Content-Type.
+type encodingWriter struct {
+	gin.ResponseWriter
+	encoding JSONEncoding
+	status   int
+	held     bool
+	passed   bool
+	body     bytes.Buffer
+}
+
+func (w *encodingWriter) decide() {
+	if w.held || w.passed {
+		return
+	}
+	if strings.HasPrefix(w.ResponseWriter.Header().Get("Content-Type"), "application/json") {
+		w.held = true
+		return
+	}
+	w.passed = true
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *encodingWriter) WriteHeader(code int) {
+	if w.passed {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 && !w.held {
+		w.status = code
+	}
+}
+
+func (w *encodingWriter) WriteHeaderNow() {
+	w.decide()
+	if w.passed {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *encodingWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.passed {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *encodingWriter) WriteString(s string) (int, error) {
+	w.decide()
+	if w.passed {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *encodingWriter) Status() int {
+	if w.passed {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *encodingWriter) Size() int {
+	if w.passed {
+		return w.ResponseWriter.Size()
+	}
+	if !w.held {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *encodingWriter) Written() bool {
+	return w.held || w.passed
+}
+
+// Flush flushes the responses which are not held, e.g. streams.
+func (w *encodingWriter) Flush() {
+	if w.passed {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *encodingWriter) flush() {
+	if w.passed {
+		return
+	}
+	if !w.held {
+		// responses without body are written by gin after the handlers
+		w.ResponseWriter.WriteHeader(w.status)
+		return
+	}
+	body := w.body.Bytes()
+	if encoded, err := encodeJSON(body, w.encoding); err == nil {
+		body = encoded
+	}
+	w.ResponseWriter.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	w.ResponseWriter.Write(body)
+}
+
+// jsonDateTimePattern matches the datetimes of the responses, with or without time zone.
+var jsonDateTimePattern = regexp.MustCompile(
+	`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d{1,9})?(Z|[+-]\d{2}:\d{2})?$`,
+)
+
+// encodeJSON re-encodes a JSON document with an encoding, keeping the order of the members of
+// its objects. An indented document stays indented.
+func encodeJSON(data []byte, enc JSONEncoding) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out bytes.Buffer
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if err := encodeJSONValue(&out, dec, tok, "", enc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON document")
+	}
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 1 &&
+		(trimmed[0] == '{' || trimmed[0] == '[') && (trimmed[1] == '\n' || trimmed[1] == '\r') {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, out.Bytes(), "", "    "); err != nil {
+			return nil, err
+		}
+		return indented.Bytes(), nil
+	}
+	return out.Bytes(), nil
+}
+
+// encodeJSONValue writes the value starting with tok, the member key of its object or the key
+// of its array for the items of an array.
+func encodeJSONValue(
+	out *bytes.Buffer,
+	dec *json.Decoder,
+	tok json.Token,
+	key string,
+	enc JSONEncoding,
+) error {
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			out.WriteByte('{')
+			first := true
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				member, _ := keyTok.(string)
+				valueTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if valueTok == nil && enc.OmitNulls {
+					continue
+				}
+				if !first {
+					out.WriteByte(',')
+				}
+				first = false
+				writeJSONString(out, member)
+				out.WriteByte(':')
+				if err := encodeJSONValue(out, dec, valueTok, member, enc); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			out.WriteByte('}')
+		case '[':
+			out.WriteByte('[')
+			item := ""
+			if key == "ids" || strings.HasSuffix(key, "_ids") {
+				item = "id"
+			}
+			for first := true; dec.More(); first = false {
+				itemTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if !first {
+					out.WriteByte(',')
+				}
+				if err := encodeJSONValue(out, dec, itemTok, item, enc); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			out.WriteByte(']')
+		}
+	case json.Number:
+		if enc.StringIDs && (key == "id" || strings.HasSuffix(key, "_id")) {
+			if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				writeJSONString(out, string(v))
+				return nil
+			}
+		}
+		out.WriteString(string(v))
+	case string:
+		if enc.UTCTimes {
+			v = utcDateTime(v)
+		}
+		writeJSONString(out, v)
+	case bool:
+		out.WriteString(strconv.FormatBool(v))
+	case nil:
+		out.WriteString("null")
+	}
+	return nil
+}
+
+// utcDateTime returns s in UTC with microseconds when it is a datetime. The datetimes without
+// time zone are in the time zone of the database.
+func utcDateTime(s string) string {
+	m := jsonDateTimePattern.FindStringSubmatch(s)
+	if m == nil {
+		return s
+	}
+	// the fractional seconds are parsed without being in the layout
+	layout := "2006-01-02T15:04:05"
+	value := strings.Replace(s, " ", "T", 1)
+	var t time.Time
+	var err error
+	if m[2] == "" {
+		t, err = time.ParseInLocation(layout, value, repository.DBLocation)
+	} else {
+		t, err = time.Parse(layout+"Z07:00", value)
+	}
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+}
+
+// writeJSONString writes s as a JSON string without escaping HTML, as PureJSON.
+func writeJSONString(out *bytes.Buffer, s string) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// Encode ends the value with a newline
+	out.Truncate(out.Len() - 1)
+}
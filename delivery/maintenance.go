@@ -0,0 +1,152 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewMaintenance(
+	uc *usecase.Maintenance,
+) *Maintenance {
+	return &Maintenance{
+		uc: uc,
+	}
+}
+
+// Maintenance serves the admin endpoints dropping caches and rebuilding materialized data.
+type Maintenance struct {
+	uc *usecase.Maintenance
+}
+
+func maintenanceError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// requireAdmin responds forbidden and returns false unless the studio of the request is an
+// admin one, returning the studio otherwise.
+func (h *Maintenance) requireAdmin(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf("%w: maintenance is restricted to admins", entity.ErrForbidden))
+		return "", false
+	}
+	return studio, true
+}
+
+type projectQueryParams struct {
+	Project *string `form:"project"`
+}
+
+// InvalidateCache drops a cache namespace, of the project given by `project` or of all the
+// projects.
+func (h *Maintenance) InvalidateCache(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	var p projectQueryParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.InvalidateCacheParams{
+		Namespace: c.Param("namespace"),
+		Project:   p.Project,
+	}
+	e, err := h.uc.InvalidateCache(c.Request.Context(), params)
+	if err != nil {
+		maintenanceError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *Maintenance) ListReindexJobs(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	var p projectQueryParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListReindexJobsParams{
+		Project: p.Project,
+	}
+	entities, err := h.uc.ListReindexJobs(c.Request.Context(), params)
+	if err != nil {
+		maintenanceError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"jobs": entities})
+}
+
+func (h *Maintenance) GetReindexJob(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetReindexJobParams{
+		ID: int32(id),
+	}
+	e, err := h.uc.GetReindexJob(c.Request.Context(), params)
+	if err != nil {
+		maintenanceError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createReindexJobParams struct {
+	Project string  `json:"project"`
+	Kind    *string `json:"kind"`
+}
+
+// PostReindexJob queues the rebuild of the materialized data of a project, which defaults to
+// the latest reviews the asset pivot reads. Its progress is polled with GetReindexJob.
+func (h *Maintenance) PostReindexJob(c *gin.Context) {
+	studio, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+	var p createReindexJobParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateReindexJobParams{
+		Project:   p.Project,
+		Kind:      entity.ReindexKindPivot,
+		CreatedBy: studio,
+	}
+	if p.Kind != nil {
+		params.Kind = *p.Kind
+	}
+	if params.CreatedBy == "" {
+		params.CreatedBy = "admin"
+	}
+	e, err := h.uc.CreateReindexJob(c.Request.Context(), params)
+	if err != nil {
+		maintenanceError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusAccepted, e)
+}
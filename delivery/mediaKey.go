@@ -0,0 +1,161 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewMediaKey(
+	uc *usecase.MediaKey,
+) *MediaKey {
+	return &MediaKey{
+		uc: uc,
+	}
+}
+
+// MediaKey manages the keys encrypting the media of the sensitive projects. It is restricted
+// to admins.
+type MediaKey struct {
+	uc *usecase.MediaKey
+}
+
+func mediaKeyError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// mediaKeyAdmin returns the studio of an admin, or responds with an error and returns false.
+func mediaKeyAdmin(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf("%w: media keys can only be managed by admins", entity.ErrForbidden))
+		return "", false
+	}
+	return studio, true
+}
+
+func (h *MediaKey) List(c *gin.Context) {
+	if _, ok := mediaKeyAdmin(c); !ok {
+		return
+	}
+	params := &entity.ListMediaKeysParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		mediaKeyError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"media_keys": entities})
+}
+
+type rotateMediaKeyParams struct {
+	CreatedBy *string `json:"created_by"`
+}
+
+// Rotate creates a new active key of the project, which enables the encryption of its media
+// on the first call. The body is optional.
+func (h *MediaKey) Rotate(c *gin.Context) {
+	studio, ok := mediaKeyAdmin(c)
+	if !ok {
+		return
+	}
+	var p rotateMediaKeyParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.RotateMediaKeyParams{
+		Project:   c.Param("project"),
+		CreatedBy: studio,
+	}
+	if p.CreatedBy != nil {
+		params.CreatedBy = *p.CreatedBy
+	}
+	e, err := h.uc.Rotate(c.Request.Context(), params)
+	if err != nil {
+		mediaKeyError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+// EncryptThumbnails encrypts the thumbnails of the project with its active key, to be called
+// after the first key is created and after each rotation.
+func (h *MediaKey) EncryptThumbnails(c *gin.Context) {
+	if _, ok := mediaKeyAdmin(c); !ok {
+		return
+	}
+	params := &entity.EncryptThumbnailsParams{
+		Project: c.Param("project"),
+	}
+	result, err := h.uc.EncryptThumbnails(c.Request.Context(), params)
+	if err != nil {
+		mediaKeyError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, result)
+}
+
+type listMediaAccessLogsParams struct {
+	From *string `form:"from"`
+	To   *string `form:"to"`
+}
+
+// ListAccessLogs lists the accesses to the encrypted media of the project. `from` and `to` are
+// dates formatted as YYYY-MM-DD; they default to the last 30 days.
+func (h *MediaKey) ListAccessLogs(c *gin.Context) {
+	if _, ok := mediaKeyAdmin(c); !ok {
+		return
+	}
+	var p listMediaAccessLogsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if p.To != nil {
+		t, err := time.Parse("2006-01-02", *p.To)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -29)
+	if p.From != nil {
+		t, err := time.Parse("2006-01-02", *p.From)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		from = t
+	}
+	params := &entity.ListMediaAccessLogsParams{
+		Project: c.Param("project"),
+		From:    from,
+		To:      to,
+	}
+	entities, err := h.uc.ListAccessLogs(c.Request.Context(), params)
+	if err != nil {
+		mediaKeyError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"media_access_logs": entities})
+}
@@ -0,0 +1,431 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type Notification struct {
+	uc *usecase.Notification
+}
+
+func NewNotification(uc *usecase.Notification) *Notification {
+	return &Notification{
+		uc: uc,
+	}
+}
+
+// middleware to send notifications after each API process
+// Notifications are not sent inline but stored in the outbox, which is drained by
+// RunOutboxDispatcher. Review status notifications are enqueued by the review status log
+// usecase within the transaction of the status change.
+func (d *Notification) SendNotification(c *gin.Context) {
+	// call c.Next() to send notification after each API process
+	c.Next()
+
+	lgr := NewLogger(c.Request)
+	req := c.Request
+	project := c.Param("project")
+
+	// APIs to send notification
+	publish := fmt.Sprintf("/api/projects/%s/publishTransactionInfos", project)
+	review := fmt.Sprintf("/api/projects/%s/reviewStatusLogs", project)
+	review2 := fmt.Sprintf("/api/projects/%s/reviewStatusLogs2", project)
+
+	path := req.URL.Path
+	if req.Method != "POST" ||
+		path != publish && path != review && path != review2 {
+		return
+	}
+
+	// failures of review status logs are still reported here, while their notifications are
+	// enqueued by the usecase
+
+	if rawError, ok := c.Get("notificationErrorInfo"); ok {
+		if err, ok := rawError.(*entity.ApiProcessError); ok {
+			d.uc.SendApiProcessFailure(err)
+			return
+		}
+	}
+
+	switch path {
+	case publish:
+		rawinfo, ok := c.Get("publishNotificationInfo")
+		if !ok {
+			// send error chat
+			d.uc.SendGeneralFailure(errors.New("no info is set to send publish notification"))
+			return
+		}
+		info, ok := rawinfo.(*entity.PublishTransactionInfoNotification)
+		if !ok {
+			// send error chat
+			d.uc.SendGeneralFailure(errors.New("conversion of PublishTransactionInfoNotification failed"))
+			return
+		}
+
+		lgr.Set("publishNotificationInfo", info)
+		if err := d.uc.EnqueuePublishNotification(req.Context(), info); err != nil {
+			d.uc.SendGeneralFailure(fmt.Errorf("failed to enqueue publish notification: %w", err))
+		} else {
+			lgr.Info("[EmailSender] enqueue publish notification")
+		}
+
+		return
+	}
+}
+
+func deadLetterError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// deadLetterAdmin responds with 403 and returns false unless the requester is an admin. The
+// dead letters of all the projects are managed by admins only.
+func deadLetterAdmin(c *gin.Context) bool {
+	if entity.SkipAuth {
+		return true
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !isAdminStudio(studio) {
+		forbidden(c, errors.New("managing the dead letters is restricted to admins"))
+		return false
+	}
+	return true
+}
+
+type listDeadLettersParams struct {
+	PerPage *int                           `form:"per_page"`
+	Page    *int                           `form:"page"`
+	Project *string                        `form:"project"`
+	Kind    *entity.NotificationOutboxKind `form:"kind"`
+}
+
+// ListDeadLetters lists the notifications which failed too many times, with their last error.
+func (d *Notification) ListDeadLetters(c *gin.Context) {
+	if !deadLetterAdmin(c) {
+		return
+	}
+	var p listDeadLettersParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListDeadLettersParams{
+		Project: p.Project,
+		Kind:    p.Kind,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := d.uc.ListDeadLetters(c.Request.Context(), params)
+	if err != nil {
+		deadLetterError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"dead_letters",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
+
+func (d *Notification) GetDeadLetter(c *gin.Context) {
+	if !deadLetterAdmin(c) {
+		return
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := d.uc.GetDeadLetter(c.Request.Context(), &entity.GetDeadLetterParams{ID: id})
+	if err != nil {
+		deadLetterError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+// RetryDeadLetter sends a dead letter again with the next dispatch of the outbox.
+func (d *Notification) RetryDeadLetter(c *gin.Context) {
+	if !deadLetterAdmin(c) {
+		return
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := d.uc.RetryDeadLetter(c.Request.Context(), &entity.GetDeadLetterParams{ID: id})
+	if err != nil {
+		deadLetterError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type retryDeadLettersParams struct {
+	IDs     []int32                        `json:"ids"`
+	Project *string                        `json:"project"`
+	Kind    *entity.NotificationOutboxKind `json:"kind"`
+}
+
+// RetryDeadLetters sends the dead letters of `ids` again, or all the ones of `project` and
+// `kind` when no ID is given.
+func (d *Notification) RetryDeadLetters(c *gin.Context) {
+	if !deadLetterAdmin(c) {
+		return
+	}
+	var p retryDeadLettersParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := d.uc.RetryDeadLetters(c.Request.Context(), &entity.RetryDeadLettersParams{
+		IDs:     p.IDs,
+		Project: p.Project,
+		Kind:    p.Kind,
+	})
+	if err != nil {
+		deadLetterError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type purgeDeadLettersParams struct {
+	Before  *time.Time                     `form:"before"`
+	Project *string                        `form:"project"`
+	Kind    *entity.NotificationOutboxKind `form:"kind"`
+}
+
+// PurgeDeadLetters deletes the dead letters which failed for the last time before `before`.
+func (d *Notification) PurgeDeadLetters(c *gin.Context) {
+	if !deadLetterAdmin(c) {
+		return
+	}
+	var p purgeDeadLettersParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	if p.Before == nil {
+		badRequest(c, errors.New("before is required"))
+		return
+	}
+	e, err := d.uc.PurgeDeadLetters(c.Request.Context(), &entity.PurgeDeadLettersParams{
+		Before:  *p.Before,
+		Project: p.Project,
+		Kind:    p.Kind,
+	})
+	if err != nil {
+		deadLetterError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+// GetDeadLetterStats is the depth of the dead letter queue, also exported by the metrics API
+// as notification_dead_letters.
+func (d *Notification) GetDeadLetterStats(c *gin.Context) {
+	if !deadLetterAdmin(c) {
+		return
+	}
+	stats, err := d.uc.GetDeadLetterStats(c.Request.Context())
+	if err != nil {
+		deadLetterError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, stats)
+}
+
+func webhookError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// webhookUser returns the given user of a change of the webhooks, or the studio by default.
+func webhookUser(c *gin.Context, user *string) string {
+	if user != nil {
+		return *user
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	return studio
+}
+
+func (d *Notification) ListWebhooks(c *gin.Context) {
+	entities, err := d.uc.ListWebhooks(c.Request.Context(), &entity.ListWebhooksParams{
+		Project: c.Param("project"),
+	})
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"webhooks": entities})
+}
+
+func (d *Notification) GetWebhook(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := d.uc.GetWebhook(c.Request.Context(), &entity.GetWebhookParams{
+		Project: c.Param("project"),
+		ID:      id,
+	})
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createWebhookParams struct {
+	URL       string                `json:"url"`
+	Events    []entity.WebhookEvent `json:"events"`
+	Active    *bool                 `json:"active"`
+	CreatedBy *string               `json:"created_by"`
+}
+
+// PostWebhook registers a webhook of the project. Its secret, to verify the signatures of the
+// posted events, is only returned in the response.
+func (d *Notification) PostWebhook(c *gin.Context) {
+	var p createWebhookParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := d.uc.CreateWebhook(c.Request.Context(), &entity.CreateWebhookParams{
+		Project:   c.Param("project"),
+		URL:       p.URL,
+		Events:    p.Events,
+		Active:    p.Active,
+		CreatedBy: webhookUser(c, p.CreatedBy),
+	})
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type updateWebhookParams struct {
+	URL          *string               `json:"url"`
+	Events       []entity.WebhookEvent `json:"events"`
+	Active       *bool                 `json:"active"`
+	RotateSecret bool                  `json:"rotate_secret"`
+	ModifiedBy   *string               `json:"modified_by"`
+}
+
+func (d *Notification) UpdateWebhook(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p updateWebhookParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	e, err := d.uc.UpdateWebhook(c.Request.Context(), &entity.UpdateWebhookParams{
+		Project:      c.Param("project"),
+		ID:           id,
+		URL:          p.URL,
+		Events:       p.Events,
+		Active:       p.Active,
+		RotateSecret: p.RotateSecret,
+		ModifiedBy:   webhookUser(c, p.ModifiedBy),
+	})
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (d *Notification) DeleteWebhook(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	if err := d.uc.DeleteWebhook(c.Request.Context(), &entity.DeleteWebhookParams{
+		Project: c.Param("project"),
+		ID:      id,
+	}); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesParams struct {
+	PerPage *int  `form:"per_page"`
+	Page    *int  `form:"page"`
+	Failed  *bool `form:"failed"`
+}
+
+// ListWebhookDeliveries lists the attempts to post the events to a webhook, latest first.
+func (d *Notification) ListWebhookDeliveries(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p listWebhookDeliveriesParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListWebhookDeliveriesParams{
+		Project: c.Param("project"),
+		ID:      id,
+		Failed:  p.Failed,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := d.uc.ListWebhookDeliveries(c.Request.Context(), params)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"deliveries",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPublishEnqueuesNotification(t *testing.T) {
+	db := openTestDB(t)
+	outboxRepo, err := repository.NewNotificationOutbox(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhookRepo, err := repository.NewWebhook(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := fmt.Sprintf("test%d", time.Now().UnixNano()%1e12)
+	t.Cleanup(func() {
+		db.Where("`project` = ?", project).Delete(&model.NotificationOutbox{})
+	})
+	d := NewNotification(usecase.NewNotification(
+		nil, outboxRepo, webhookRepo, 10*time.Second, 10*time.Second,
+	))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(d.SendNotification)
+	root := "assets"
+	r.POST("/api/projects/:project/publishTransactionInfos", func(c *gin.Context) {
+		c.Set("publishNotificationInfo", &entity.PublishTransactionInfoNotification{
+			Studio:  "ppi",
+			Project: c.Param("project"),
+			Root:    &root,
+		})
+		c.Status(http.StatusCreated)
+	})
+	r.POST("/api/projects/:project/reviewInfos", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	for _, path := range []string{
+		"/api/projects/" + project + "/reviewInfos",
+		"/api/projects/" + project + "/publishTransactionInfos",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("POST %s = %d, want %d", path, w.Code, http.StatusCreated)
+		}
+	}
+
+	var rows []*model.NotificationOutbox
+	if err := db.Where("`project` = ?", project).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("%d outbox rows, want the publish notification only", len(rows))
+	}
+	if rows[0].Kind != string(entity.PublishNotificationKind) {
+		t.Errorf("kind = %q, want %q", rows[0].Kind, entity.PublishNotificationKind)
+	}
+}
@@ -0,0 +1,409 @@
+package delivery
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIOperation describes the request and the response of a handler. Query is a value of
+// the type its query parameters are bound to with form tags, Body and Response are values of
+// the types of its JSON bodies.
+type OpenAPIOperation struct {
+	Summary  string
+	Query    interface{}
+	Body     interface{}
+	Response interface{}
+	// Status is the status of a successful response, http.StatusOK by default.
+	Status int
+}
+
+// NewOpenAPI returns the OpenAPI document of the routes of the router, which is built from its
+// route table at each request so that it covers the routes registered after it.
+func NewOpenAPI(routes func() gin.RoutesInfo) *OpenAPI {
+	h := &OpenAPI{
+		routes:     routes,
+		operations: map[string]*OpenAPIOperation{},
+	}
+	registerOpenAPIOperations(h)
+	return h
+}
+
+// OpenAPI serves the OpenAPI 3 document of the API and the Swagger UI of it. The operations
+// are keyed by the names of their handlers, which are the names of the route table.
+type OpenAPI struct {
+	routes     func() gin.RoutesInfo
+	operations map[string]*OpenAPIOperation
+}
+
+// handlerName returns the name of a handler as the route table of gin reports it, without the
+// suffix of the method values so that a method expression names the same handler.
+func handlerName(handler interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// Describe sets the operation of the handler, a gin.HandlerFunc or a method expression of a
+// handler such as (*ReviewInfo).Get.
+func (h *OpenAPI) Describe(handler interface{}, op *OpenAPIOperation) {
+	h.operations[handlerName(handler)] = op
+}
+
+// openAPIPath returns the path of a route in the OpenAPI syntax, and its path parameters.
+func openAPIPath(path string) (string, []string) {
+	elems := strings.Split(path, "/")
+	var params []string
+	for i, e := range elems {
+		if strings.HasPrefix(e, ":") || strings.HasPrefix(e, "*") {
+			params = append(params, e[1:])
+			elems[i] = "{" + e[1:] + "}"
+		}
+	}
+	return strings.Join(elems, "/"), params
+}
+
+// openAPITag groups the routes by their first static path element after the project or, for
+// the other routes, after /api.
+func openAPITag(path string) string {
+	elems := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	if len(elems) > 2 && elems[0] == "projects" && strings.HasPrefix(elems[1], ":") {
+		elems = elems[2:]
+	}
+	return elems[0]
+}
+
+// Document returns the OpenAPI document of the /api routes.
+func (h *OpenAPI) Document() map[string]interface{} {
+	routes := h.routes()
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	schemas := &openAPISchemas{
+		components: map[string]interface{}{},
+		names:      map[reflect.Type]string{},
+	}
+	paths := map[string]map[string]interface{}{}
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		path, pathParams := openAPIPath(route.Path)
+		name := strings.TrimSuffix(route.Handler, "-fm")
+		op, ok := h.operations[name]
+		if !ok {
+			op = &OpenAPIOperation{}
+		}
+
+		operation := map[string]interface{}{
+			"tags":        []string{openAPITag(route.Path)},
+			"x-handler":   name,
+			"operationId": strings.ToLower(route.Method) + path,
+		}
+		if op.Summary != "" {
+			operation["summary"] = op.Summary
+		}
+		parameters := []interface{}{}
+		for _, p := range pathParams {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     p,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+		if op.Query != nil {
+			parameters = append(parameters, schemas.queryParameters(reflect.TypeOf(op.Query))...)
+		}
+		if len(parameters) != 0 {
+			operation["parameters"] = parameters
+		}
+		if op.Body != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schemas.schema(reflect.TypeOf(op.Body)),
+					},
+				},
+			}
+		}
+		statusCode := op.Status
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+		response := map[string]interface{}{
+			"description": http.StatusText(statusCode),
+		}
+		if op.Response != nil {
+			response["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": schemas.schema(reflect.TypeOf(op.Response)),
+				},
+			}
+		}
+		operation["responses"] = map[string]interface{}{
+			strconv.Itoa(statusCode): response,
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+					},
+				},
+			},
+		}
+
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+	schemas.components["Error"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"code":    map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{"type": "string"},
+			"docs":    map[string]interface{}{"type": "string"},
+			"errors": map[string]interface{}{
+				"type":  "array",
+				"items": schemas.schema(reflect.TypeOf(entity.FieldError{})),
+			},
+		},
+		"required": []interface{}{"code", "message"},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Central 3.0 API",
+			"version": strconv.Itoa(LatestAPIVersion),
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": entity.PublicPath("/")},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"bearer": []string{}},
+		},
+	}
+}
+
+// Spec serves the OpenAPI document.
+func (h *OpenAPI) Spec(c *gin.Context) {
+	c.PureJSON(http.StatusOK, h.Document())
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Central 3.0 API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
+
+// SwaggerUI serves the Swagger UI of the OpenAPI document.
+func (h *OpenAPI) SwaggerUI(c *gin.Context) {
+	c.Data(
+		http.StatusOK,
+		"text/html; charset=utf-8",
+		[]byte(fmt.Sprintf(swaggerUIPage, entity.PublicPath("/api/openapi.json"))),
+	)
+}
+
+var (
+	openAPITimeType          = reflect.TypeOf(time.Time{})
+	openAPITextMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// openAPISchemas builds the schemas of the Go types, the named structs as components.
+type openAPISchemas struct {
+	components map[string]interface{}
+	names      map[reflect.Type]string
+}
+
+// componentName returns the name of the component of a named struct, qualified with its
+// package when another package has a struct of the same name.
+func (s *openAPISchemas) componentName(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, ok := s.components[name]; ok {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	s.names[t] = name
+	return name
+}
+
+func (s *openAPISchemas) schema(t reflect.Type) map[string]interface{} {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	var schema map[string]interface{}
+	switch {
+	case t == openAPITimeType:
+		schema = map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() != reflect.String && reflect.PtrTo(t).Implements(openAPITextMarshalerType):
+		schema = map[string]interface{}{"type": "string"}
+	default:
+		switch t.Kind() {
+		case reflect.String:
+			schema = map[string]interface{}{"type": "string"}
+		case reflect.Bool:
+			schema = map[string]interface{}{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			schema = map[string]interface{}{"type": "integer", "format": "int32"}
+		case reflect.Int64, reflect.Uint64:
+			schema = map[string]interface{}{"type": "integer", "format": "int64"}
+		case reflect.Float32, reflect.Float64:
+			schema = map[string]interface{}{"type": "number"}
+		case reflect.Slice, reflect.Array:
+			if t.Elem().Kind() == reflect.Uint8 {
+				schema = map[string]interface{}{"type": "string", "format": "byte"}
+			} else {
+				schema = map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+			}
+		case reflect.Map:
+			schema = map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": s.schema(t.Elem()),
+			}
+		case reflect.Struct:
+			if t.Name() == "" {
+				schema = s.object(t)
+				break
+			}
+			name, ok := s.names[t]
+			if !ok {
+				name = s.componentName(t)
+				// set before the fields for the recursive types
+				s.components[name] = map[string]interface{}{}
+				s.components[name] = s.object(t)
+			}
+			ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+			if nullable {
+				return map[string]interface{}{"allOf": []interface{}{ref}, "nullable": true}
+			}
+			return ref
+		default:
+			schema = map[string]interface{}{}
+		}
+	}
+	if nullable {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+// openAPIField is a field of a struct with the name of its tag.
+type openAPIField struct {
+	name     string
+	field    reflect.StructField
+	required bool
+}
+
+// openAPIFields returns the fields of a struct with the names of the tag, the ones of the embedded
+// structs included.
+func openAPIFields(t reflect.Type, tag string) []openAPIField {
+	var fields []openAPIField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		value, ok := f.Tag.Lookup(tag)
+		if f.Anonymous && !ok {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, openAPIFields(ft, tag)...)
+			}
+			continue
+		}
+		if f.PkgPath != "" || value == "-" {
+			continue
+		}
+		name := strings.Split(value, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		required := false
+		for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				required = true
+			}
+		}
+		fields = append(fields, openAPIField{name: name, field: f, required: required})
+	}
+	return fields
+}
+
+func (s *openAPISchemas) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for _, f := range openAPIFields(t, "json") {
+		properties[f.name] = s.schema(f.field.Type)
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) != 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// queryParameters returns the query parameters of a struct bound with form tags.
+func (s *openAPISchemas) queryParameters(t reflect.Type) []interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var parameters []interface{}
+	for _, f := range openAPIFields(t, "form") {
+		parameters = append(parameters, map[string]interface{}{
+			"name":     f.name,
+			"in":       "query",
+			"required": f.required,
+			"schema":   s.schema(f.field.Type),
+		})
+	}
+	return parameters
+}
@@ -0,0 +1,238 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/entity/groupCategory"
+)
+
+// registerOpenAPIOperations describes the request and the response bodies of the handlers in
+// the OpenAPI document. The routes of the other handlers are listed with their path
+// parameters only.
+func registerOpenAPIOperations(api *OpenAPI) {
+	// Review Info API
+	api.Describe((*ReviewInfo).List, &OpenAPIOperation{
+		Summary: "List the reviews of a project",
+		Query:   listReviewInfoParams{},
+		Response: struct {
+			Reviews []*entity.ReviewInfo `json:"reviews"`
+			Total   int                  `json:"total"`
+		}{},
+	})
+	api.Describe((*ReviewInfo).Get, &OpenAPIOperation{
+		Summary:  "Get a review by its ID or ULID",
+		Response: entity.ReviewInfo{},
+	})
+	api.Describe((*ReviewInfo).Post, &OpenAPIOperation{
+		Summary:  "Submit a review",
+		Body:     createReviewInfoParams{},
+		Response: entity.ReviewInfo{},
+	})
+	api.Describe((*ReviewInfo).Update, &OpenAPIOperation{
+		Summary:  "Update the statuses of a review",
+		Body:     updateReviewInfoParams{},
+		Response: entity.ReviewInfo{},
+	})
+	api.Describe((*ReviewInfo).Delete, &OpenAPIOperation{
+		Summary: "Delete a review",
+		Status:  http.StatusNoContent,
+	})
+	api.Describe((*ReviewInfo).Restore, &OpenAPIOperation{
+		Summary:  "Restore a deleted review",
+		Response: entity.ReviewInfo{},
+	})
+	api.Describe((*ReviewInfo).ListDeleted, &OpenAPIOperation{
+		Summary: "List the deleted reviews of a project",
+		Query:   listDeletedReviewInfosParams{},
+		Response: struct {
+			Reviews []*entity.ReviewInfo `json:"reviews"`
+			Total   int                  `json:"total"`
+		}{},
+	})
+	api.Describe((*ReviewInfo).Purge, &OpenAPIOperation{
+		Summary:  "Purge the reviews of a project deleted long ago",
+		Body:     purgeReviewInfosParams{},
+		Response: entity.PurgeReviewInfosResult{},
+	})
+
+	// Project Quota API
+	api.Describe((*ProjectQuota).List, &OpenAPIOperation{
+		Summary: "List the quotas of a project",
+		Response: struct {
+			Quotas []*entity.ProjectQuota `json:"quotas"`
+		}{},
+	})
+	api.Describe((*ProjectQuota).Update, &OpenAPIOperation{
+		Summary:  "Set the daily limit of an operation of a project",
+		Body:     updateProjectQuotaParams{},
+		Response: entity.ProjectQuota{},
+	})
+	api.Describe((*ProjectQuota).Delete, &OpenAPIOperation{
+		Summary: "Remove the quota of an operation of a project",
+		Status:  http.StatusNoContent,
+	})
+
+	// Submission Limit API
+	api.Describe((*SubmissionLimit).Get, &OpenAPIOperation{
+		Summary:  "Get the submission limit of a project with its active overrides",
+		Response: entity.SubmissionLimit{},
+	})
+	api.Describe((*SubmissionLimit).Update, &OpenAPIOperation{
+		Summary:  "Set the submission limit of a project",
+		Body:     updateSubmissionLimitParams{},
+		Response: entity.SubmissionLimit{},
+	})
+	api.Describe((*SubmissionLimit).Delete, &OpenAPIOperation{
+		Summary: "Remove the submission limit of a project",
+		Status:  http.StatusNoContent,
+	})
+	api.Describe((*SubmissionLimit).CreateOverride, &OpenAPIOperation{
+		Summary:  "Lift the submission limit of a project or an asset until it expires",
+		Body:     createSubmissionLimitOverrideParams{},
+		Response: entity.SubmissionLimitOverride{},
+		Status:   http.StatusCreated,
+	})
+	api.Describe((*SubmissionLimit).DeleteOverride, &OpenAPIOperation{
+		Summary: "Remove an override of the submission limit",
+		Status:  http.StatusNoContent,
+	})
+
+	// Status Mapping API
+	api.Describe((*StatusMapping).List, &OpenAPIOperation{
+		Summary: "List the legacy status mappings of a project",
+		Response: struct {
+			StatusMappings []*entity.StatusMapping `json:"status_mappings"`
+		}{},
+	})
+	api.Describe((*StatusMapping).Update, &OpenAPIOperation{
+		Summary:  "Map a legacy status of a project to the current vocabulary",
+		Body:     updateStatusMappingParams{},
+		Response: entity.StatusMapping{},
+	})
+	api.Describe((*StatusMapping).Delete, &OpenAPIOperation{
+		Summary: "Remove a legacy status mapping",
+		Status:  http.StatusNoContent,
+	})
+	api.Describe((*StatusMapping).Backfill, &OpenAPIOperation{
+		Summary:  "Rewrite the legacy statuses stored in the reviews of a project",
+		Body:     backfillStatusMappingsParams{},
+		Response: entity.StatusBackfillResult{},
+	})
+
+	// Asset Rename API
+	api.Describe((*AssetRename).List, &OpenAPIOperation{
+		Summary: "List the renames of the assets of a project",
+		Query:   listAssetRenamesParams{},
+		Response: struct {
+			Renames []*entity.AssetRename `json:"renames"`
+		}{},
+	})
+	api.Describe((*AssetRename).Rename, &OpenAPIOperation{
+		Summary:  "Rename an asset",
+		Body:     renameAssetParams{},
+		Response: entity.AssetRename{},
+		Status:   http.StatusCreated,
+	})
+
+	// GroupCategory API
+	api.Describe((*GroupCategory).Assign, &OpenAPIOperation{
+		Summary:  "Assign groups to a category at once",
+		Body:     assignGroupCategoryParams{},
+		Response: groupCategory.CategoryEntity{},
+	})
+
+	// Work Queue API
+	api.Describe((*WorkQueue).List, &OpenAPIOperation{
+		Summary: "List the work awaiting the user across the projects, the most urgent first",
+		Query:   listWorkItemsParams{},
+		Response: struct {
+			Items []*entity.WorkItem `json:"items"`
+			Total int                `json:"total"`
+		}{},
+	})
+
+	// Review Digest API
+	api.Describe((*ReviewDigest).Run, &OpenAPIOperation{
+		Summary:  "Send the digests of the pending reviews",
+		Body:     runReviewDigestsParams{},
+		Response: entity.ReviewDigestRun{},
+	})
+
+	// Pivot Diff API
+	api.Describe((*AssetStatusSnapshot).Diff, &OpenAPIOperation{
+		Summary:  "Diff the phase statuses of the assets between two snapshots",
+		Query:    getPivotDiffParams{},
+		Response: entity.PivotDiff{},
+	})
+
+	// Archive API
+	api.Describe((*Archive).List, &OpenAPIOperation{
+		Summary: "List the archives of a project",
+		Response: struct {
+			Archives []*entity.ArchiveJob `json:"archives"`
+		}{},
+	})
+	api.Describe((*Archive).Get, &OpenAPIOperation{
+		Summary:  "Get the progress of an archive",
+		Response: entity.ArchiveJob{},
+	})
+	api.Describe((*Archive).Post, &OpenAPIOperation{
+		Summary:  "Archive a whole project into a bundle for cold storage",
+		Body:     createArchiveJobParams{},
+		Response: entity.ArchiveJob{},
+		Status:   http.StatusAccepted,
+	})
+	api.Describe((*Archive).Download, &OpenAPIOperation{
+		Summary: "Download the bundle of a completed archive",
+	})
+
+	// Project Bootstrap API
+	api.Describe((*ProjectBootstrap).Get, &OpenAPIOperation{
+		Summary:  "Get the progress of a project bootstrap",
+		Response: entity.ProjectBootstrapJob{},
+	})
+	api.Describe((*ProjectBootstrap).Post, &OpenAPIOperation{
+		Summary:  "Create a project with the scaffolding of a template project",
+		Body:     createProjectBootstrapJobParams{},
+		Response: entity.ProjectBootstrapJob{},
+		Status:   http.StatusAccepted,
+	})
+
+	// Client Heartbeat API
+	api.Describe((*ClientHeartbeat).Post, &OpenAPIOperation{
+		Summary: "Record a heartbeat of a desktop tool",
+		Body:    recordClientHeartbeatParams{},
+		Status:  http.StatusNoContent,
+	})
+	api.Describe((*ClientHeartbeat).List, &OpenAPIOperation{
+		Summary:  "List the desktop tools connected lately",
+		Query:    listConnectedClientsParams{},
+		Response: entity.ConnectedClients{},
+	})
+	api.Describe((*ClientHeartbeat).Adoption, &OpenAPIOperation{
+		Summary: "Report the adoption of the versions of the desktop tools per day",
+		Query:   listClientAdoptionParams{},
+		Response: struct {
+			Adoption []*entity.ClientAdoption `json:"adoption"`
+		}{},
+	})
+
+	// GraphQL API
+	api.Describe((*GraphQL).Query, &OpenAPIOperation{
+		Summary: "Run a GraphQL query",
+		Body:    graphQLParams{},
+		Response: struct {
+			Data   map[string]interface{}   `json:"data"`
+			Errors []map[string]interface{} `json:"errors"`
+		}{},
+	})
+
+	// OpenAPI
+	api.Describe((*OpenAPI).Spec, &OpenAPIOperation{
+		Summary: "Get the OpenAPI document of the API",
+	})
+	api.Describe((*OpenAPI).SwaggerUI, &OpenAPIOperation{
+		Summary: "Browse the API with Swagger UI",
+	})
+}
@@ -0,0 +1,107 @@
+package delivery
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Layout of the text PDF documents: A4 landscape pages of monospaced lines, in points.
+const (
+	pdfPageWidth    = 842
+	pdfPageHeight   = 595
+	pdfMargin       = 40
+	pdfFontSize     = 8
+	pdfLeading      = 11
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	pdfMaxLineWidth = 160
+)
+
+// pdfEscape escapes a line for a PDF string literal. Characters out of printable ASCII are
+// replaced, as the standard font is used without embedding.
+func pdfEscape(line string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		if n == pdfMaxLineWidth {
+			break
+		}
+		n++
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// writeTextPDF writes lines of text as a PDF document, paginating them.
+func writeTextPDF(w io.Writer, lines []string) error {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// Objects 1 to 3 are the catalog, the page tree and the font, then each page is followed
+	// by its content stream.
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf(
+			"<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages),
+		),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	)
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(
+			&content, "BT /F1 %d Tf %d TL %d %d Td\n",
+			pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin,
+		)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+		objects = append(objects,
+			fmt.Sprintf(
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+					"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 5+2*i,
+			),
+			fmt.Sprintf(
+				"<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String(),
+			),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(
+		&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, xref,
+	)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,40 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewPhaseTemplate(
+	uc *usecase.PhaseTemplate,
+) *PhaseTemplate {
+	return &PhaseTemplate{
+		uc: uc,
+	}
+}
+
+type PhaseTemplate struct {
+	uc *usecase.PhaseTemplate
+}
+
+// Get returns the ordered phases of a root of the project, `assets` by default.
+func (h *PhaseTemplate) Get(c *gin.Context) {
+	params := &entity.GetPhaseTemplateParams{
+		Project: c.Param("project"),
+		Root:    c.DefaultQuery("root", "assets"),
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
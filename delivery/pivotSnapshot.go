@@ -0,0 +1,134 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPivotSnapshotExpiresInHours is the lifetime of the share links by default, a week.
+const defaultPivotSnapshotExpiresInHours = 168
+
+func NewPivotSnapshot(
+	uc *usecase.PivotSnapshot,
+) *PivotSnapshot {
+	return &PivotSnapshot{
+		uc: uc,
+	}
+}
+
+type PivotSnapshot struct {
+	uc *usecase.PivotSnapshot
+}
+
+func pivotSnapshotError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		forbidden(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrShareLinkExpired) {
+		c.AbortWithStatusJSON(http.StatusGone, errorBody(http.StatusGone, err))
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+type createPivotSnapshotParams struct {
+	ExpiresInHours *int    `json:"expires_in_hours"`
+	CreatedBy      *string `json:"created_by"`
+}
+
+// Post shares the result of the pivot query, parsed from the request by the caller as for
+// the pivot API. The body is optional.
+func (h *PivotSnapshot) Post(c *gin.Context, query repository.ListAssetsPivotParams, view string) {
+	var p createPivotSnapshotParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	expiresInHours := defaultPivotSnapshotExpiresInHours
+	if p.ExpiresInHours != nil {
+		expiresInHours = *p.ExpiresInHours
+	}
+	filters := c.Request.URL.Query()
+	filters.Del(entity.QueryToken)
+	params := &entity.CreatePivotSnapshotParams{
+		Project:        c.Param("project"),
+		Root:           query.Root,
+		View:           view,
+		Filters:        filters,
+		ExpiresInHours: expiresInHours,
+		CreatedBy:      p.CreatedBy,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params, query)
+	if err != nil {
+		pivotSnapshotError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type getSharedPivotSnapshotParams struct {
+	Expires   int64  `form:"expires" binding:"required"`
+	Signature string `form:"sig" binding:"required"`
+}
+
+// GetShared serves a snapshot to the public by its signed link.
+func (h *PivotSnapshot) GetShared(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		notFound(c, err)
+		return
+	}
+	var p getSharedPivotSnapshotParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		notFound(c, err)
+		return
+	}
+	params := &entity.GetSharedPivotSnapshotParams{
+		ID:        int32(id),
+		Expires:   p.Expires,
+		Signature: p.Signature,
+	}
+	e, err := h.uc.GetShared(c.Request.Context(), params)
+	if err != nil {
+		pivotSnapshotError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.PureJSON(http.StatusOK, e)
+}
+
+// Delete revokes the share link of a snapshot.
+func (h *PivotSnapshot) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeletePivotSnapshotParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		pivotSnapshotError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,209 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewPivotView(
+	uc *usecase.PivotView,
+) *PivotView {
+	return &PivotView{
+		uc: uc,
+	}
+}
+
+type PivotView struct {
+	uc *usecase.PivotView
+}
+
+func pivotViewError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// pivotViewOwner returns the name authenticated by the access token, which owns the views.
+func pivotViewOwner(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	owner, _ := name.(string)
+	if owner == "" && entity.SkipAuth {
+		owner = "skipauth"
+	}
+	if owner == "" {
+		unauthorized(c, entity.ErrUnauthorized)
+		return "", false
+	}
+	return owner, true
+}
+
+func pivotViewID(c *gin.Context) (int32, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// List lists the views of the authenticated user, by name.
+func (h *PivotView) List(c *gin.Context) {
+	owner, ok := pivotViewOwner(c)
+	if !ok {
+		return
+	}
+	params := &entity.ListPivotViewsParams{
+		Project: c.Param("project"),
+		Owner:   owner,
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		pivotViewError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"views": entities})
+}
+
+func (h *PivotView) Get(c *gin.Context) {
+	owner, ok := pivotViewOwner(c)
+	if !ok {
+		return
+	}
+	id, ok := pivotViewID(c)
+	if !ok {
+		return
+	}
+	params := &entity.GetPivotViewParams{
+		Project: c.Param("project"),
+		Owner:   owner,
+		ID:      id,
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		pivotViewError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createPivotViewParams struct {
+	Name    string              `json:"name" binding:"required"`
+	Root    *string             `json:"root"`
+	Filters map[string][]string `json:"filters"`
+	Sort    *string             `json:"sort"`
+	Dir     *string             `json:"dir"`
+	Columns []string            `json:"columns"`
+}
+
+// Post saves a view. It is sorted by group_1 ascending unless `sort` and `dir` are given.
+func (h *PivotView) Post(c *gin.Context) {
+	owner, ok := pivotViewOwner(c)
+	if !ok {
+		return
+	}
+	var p createPivotViewParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreatePivotViewParams{
+		Project: c.Param("project"),
+		Owner:   owner,
+		Name:    p.Name,
+		Root:    "assets",
+		Filters: p.Filters,
+		Sort:    "group_1",
+		Dir:     "ASC",
+		Columns: p.Columns,
+	}
+	if p.Root != nil {
+		params.Root = *p.Root
+	}
+	if p.Sort != nil {
+		params.Sort = *p.Sort
+	}
+	if p.Dir != nil {
+		params.Dir = *p.Dir
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		pivotViewError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type updatePivotViewParams struct {
+	Name    *string             `json:"name"`
+	Root    *string             `json:"root"`
+	Filters map[string][]string `json:"filters"`
+	Sort    *string             `json:"sort"`
+	Dir     *string             `json:"dir"`
+	Columns []string            `json:"columns"`
+}
+
+// Patch updates the given fields of a view. Filters and columns are replaced as a whole.
+func (h *PivotView) Patch(c *gin.Context) {
+	owner, ok := pivotViewOwner(c)
+	if !ok {
+		return
+	}
+	id, ok := pivotViewID(c)
+	if !ok {
+		return
+	}
+	var p updatePivotViewParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdatePivotViewParams{
+		Project: c.Param("project"),
+		Owner:   owner,
+		ID:      id,
+		Name:    p.Name,
+		Root:    p.Root,
+		Filters: p.Filters,
+		Sort:    p.Sort,
+		Dir:     p.Dir,
+		Columns: p.Columns,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		pivotViewError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *PivotView) Delete(c *gin.Context) {
+	owner, ok := pivotViewOwner(c)
+	if !ok {
+		return
+	}
+	id, ok := pivotViewID(c)
+	if !ok {
+		return
+	}
+	params := &entity.DeletePivotViewParams{
+		Project: c.Param("project"),
+		Owner:   owner,
+		ID:      id,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		pivotViewError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,114 @@
+package delivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewProjectBootstrap(
+	uc *usecase.ProjectBootstrap,
+) *ProjectBootstrap {
+	return &ProjectBootstrap{
+		uc: uc,
+	}
+}
+
+// ProjectBootstrap serves the bootstraps of new projects, which are restricted to admins as
+// they copy the settings of their template projects.
+type ProjectBootstrap struct {
+	uc *usecase.ProjectBootstrap
+}
+
+func projectBootstrapError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		log.Println("ERROR:", err)
+		c.AbortWithStatusJSON(http.StatusConflict, errorBody(http.StatusConflict, err))
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// admin tells whether the studio of the request is an admin one, and responds 403 otherwise.
+func (h *ProjectBootstrap) admin(c *gin.Context) (string, bool) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, errors.New("the bootstraps of the projects are restricted to admins"))
+		return studio, false
+	}
+	return studio, true
+}
+
+func (h *ProjectBootstrap) Get(c *gin.Context) {
+	if _, ok := h.admin(c); !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetProjectBootstrapJobParams{
+		ID: int32(id),
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		projectBootstrapError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type createProjectBootstrapJobParams struct {
+	Project     string  `json:"project" binding:"required"`
+	Template    *string `json:"template"`
+	Settings    bool    `json:"settings"`
+	Directories bool    `json:"directories"`
+	Categories  bool    `json:"categories"`
+	CreatedBy   *string `json:"created_by"`
+}
+
+// Post queues the bootstrap of `project`, copying the selected scaffolding of `template`, the
+// progress of which is polled with Get. The creator defaults to the studio of the request.
+func (h *ProjectBootstrap) Post(c *gin.Context) {
+	studio, ok := h.admin(c)
+	if !ok {
+		return
+	}
+	var p createProjectBootstrapJobParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateProjectBootstrapJobParams{
+		Project:     p.Project,
+		Template:    p.Template,
+		Settings:    p.Settings,
+		Directories: p.Directories,
+		Categories:  p.Categories,
+		CreatedBy:   studio,
+	}
+	if p.CreatedBy != nil {
+		params.CreatedBy = *p.CreatedBy
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		projectBootstrapError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusAccepted, e)
+}
@@ -0,0 +1,143 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewProjectQuota(
+	uc *usecase.ProjectQuota,
+) *ProjectQuota {
+	return &ProjectQuota{
+		uc: uc,
+	}
+}
+
+type ProjectQuota struct {
+	uc *usecase.ProjectQuota
+}
+
+func projectQuotaError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+func quotaOperationParam(c *gin.Context) (entity.QuotaOperation, bool) {
+	op, ok := entity.ParseQuotaOperation(c.Param("operation"))
+	if !ok {
+		badRequest(c, fmt.Errorf("unknown quota operation %q", c.Param("operation")))
+	}
+	return op, ok
+}
+
+func (h *ProjectQuota) List(c *gin.Context) {
+	params := &entity.ListProjectQuotasParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		projectQuotaError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"quotas": entities})
+}
+
+type updateProjectQuotaParams struct {
+	DailyLimit *int32  `json:"daily_limit" binding:"required"`
+	ModifiedBy *string `json:"modified_by"`
+}
+
+func (h *ProjectQuota) Update(c *gin.Context) {
+	op, ok := quotaOperationParam(c)
+	if !ok {
+		return
+	}
+	var p updateProjectQuotaParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateProjectQuotaParams{
+		Project:    c.Param("project"),
+		Operation:  op,
+		DailyLimit: *p.DailyLimit,
+		ModifiedBy: p.ModifiedBy,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		projectQuotaError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ProjectQuota) Delete(c *gin.Context) {
+	op, ok := quotaOperationParam(c)
+	if !ok {
+		return
+	}
+	params := &entity.DeleteProjectQuotaParams{
+		Project:    c.Param("project"),
+		Operation:  op,
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		projectQuotaError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Enforce returns a middleware which counts the request against the daily quota of the
+// operation of the project, reports the quota in the response headers and responds 429 once
+// it is used up. Failed requests are not counted.
+func (h *ProjectQuota) Enforce(op entity.QuotaOperation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := &entity.ConsumeProjectQuotaParams{
+			Project:   c.Param("project"),
+			Operation: op,
+		}
+		usage, err := h.uc.Consume(c.Request.Context(), params)
+		if usage != nil {
+			c.Header(entity.QuotaLimitHeader, strconv.Itoa(int(usage.Limit)))
+			c.Header(entity.QuotaRemainingHeader, strconv.Itoa(int(usage.Remaining())))
+			c.Header(entity.QuotaResetHeader, strconv.FormatInt(usage.ResetAtUTC.Unix(), 10))
+		}
+		if err != nil {
+			if errors.Is(err, entity.ErrQuotaExceeded) {
+				log.Println("ERROR:", err)
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, errorBody(http.StatusTooManyRequests, err))
+				return
+			}
+			projectQuotaError(c, err)
+			return
+		}
+		if usage == nil {
+			return
+		}
+
+		c.Next()
+		if c.Writer.Status() < http.StatusBadRequest {
+			return
+		}
+		// the request may have been canceled by the client
+		if err := h.uc.Release(context.Background(), params, usage); err != nil {
+			log.Println("ERROR:", err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewPublishPropagation(
+	uc *usecase.PublishPropagation,
+) *PublishPropagation {
+	return &PublishPropagation{
+		uc: uc,
+	}
+}
+
+type PublishPropagation struct {
+	uc *usecase.PublishPropagation
+}
+
+func (h *PublishPropagation) Get(c *gin.Context) {
+	params := &entity.GetPublishPropagationParams{
+		Project: c.Param("project"),
+		LogID:   c.Param("logID"),
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type reportPublishPropagationParams struct {
+	Studio       string                   `json:"studio" binding:"required"`
+	Status       entity.PropagationStatus `json:"status" binding:"required"`
+	ArrivedAtUTC *time.Time               `json:"arrived_at_utc"`
+	Message      string                   `json:"message"`
+}
+
+// Report is called by the sync client of a studio when the files of the publish transaction
+// arrived, or failed to.
+func (h *PublishPropagation) Report(c *gin.Context) {
+	var p reportPublishPropagationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ReportPublishPropagationParams{
+		Project:      c.Param("project"),
+		LogID:        c.Param("logID"),
+		Studio:       p.Studio,
+		Status:       p.Status,
+		ArrivedAtUTC: p.ArrivedAtUTC,
+		Message:      p.Message,
+		CreatedBy:    nil,
+	}
+	e, err := h.uc.Report(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
@@ -0,0 +1,100 @@
+package delivery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewRateLimit(
+	uc *usecase.RateLimit,
+) *RateLimit {
+	return &RateLimit{
+		uc: uc,
+	}
+}
+
+type RateLimit struct {
+	uc *usecase.RateLimit
+}
+
+// rateLimitToken identifies the authenticated client of the request: its API key, or a digest
+// of its token, so that the tokens are not kept in memory. It is "" for the anonymous requests
+// and those whose credentials were not validated, which are only limited per IP.
+func rateLimitToken(c *gin.Context) string {
+	if id, ok := c.Get(entity.APIKeyContextKey); ok {
+		return fmt.Sprintf("key:%v", id)
+	}
+	// the studio is only known once the token is validated
+	if c.GetString("studio") == "" {
+		return ""
+	}
+	credentials := c.GetHeader(entity.AuthHeader)
+	if credentials == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(credentials))
+	return "token:" + hex.EncodeToString(sum[:16])
+}
+
+func rateLimitWrite(c *gin.Context) bool {
+	method := c.Request.Method
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
+
+// Limit is the middleware rejecting the requests over the limits of their IP with 429 and a
+// Retry-After header, so that batch scripts cannot starve the UI. It is used before the
+// authentication, so that the requests with invalid credentials are limited too. The client
+// IP is only read from X-Forwarded-For when the peer is a trusted proxy. The methods other
+// than GET, HEAD and OPTIONS are limited as writes.
+func (h *RateLimit) Limit(c *gin.Context) {
+	h.allow(c, &entity.RateLimitParams{
+		IP:    c.ClientIP(),
+		Write: rateLimitWrite(c),
+	})
+}
+
+// LimitCredentials is the middleware rejecting the requests over the limits of their API key
+// or token, as Limit does. It is used after the authentication, so that the buckets are only
+// those of valid credentials.
+func (h *RateLimit) LimitCredentials(c *gin.Context) {
+	token := rateLimitToken(c)
+	if token == "" {
+		return
+	}
+	h.allow(c, &entity.RateLimitParams{
+		Token: token,
+		Write: rateLimitWrite(c),
+	})
+}
+
+func (h *RateLimit) allow(c *gin.Context, params *entity.RateLimitParams) {
+	decision := h.uc.Allow(params)
+	// the headers tell the tightest of the limits of the request
+	if decision.Limit != 0 {
+		remaining, err := strconv.Atoi(c.Writer.Header().Get("X-RateLimit-Remaining"))
+		if err != nil || decision.Remaining < remaining {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		}
+	}
+	if decision.Allowed {
+		return
+	}
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	body := errorBody(http.StatusTooManyRequests, errors.New("too many requests"))
+	body["retry_after_seconds"] = retryAfter
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, body)
+}
@@ -0,0 +1,127 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// newRateLimitRouter limits the requests as the API does, with an authentication accepting
+// the API key "valid" only. The buckets barely refill during a test.
+func newRateLimitRouter(t *testing.T, ipBurst, tokenBurst int, proxies []string) *gin.Engine {
+	t.Helper()
+	t.Setenv("PPI_RATE_LIMIT_IP_READ", "0.001:"+strconv.Itoa(ipBurst))
+	t.Setenv("PPI_RATE_LIMIT_TOKEN_READ", "0.001:"+strconv.Itoa(tokenBurst))
+	limiter, err := repository.NewRateLimiterFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewRateLimit(usecase.NewRateLimit(limiter))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		t.Fatal(err)
+	}
+	r.Use(h.Limit)
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader(entity.APIKeyHeader) == "valid" {
+			c.Set(entity.APIKeyContextKey, int32(1))
+		}
+	})
+	r.Use(h.LimitCredentials)
+	r.GET("/api/projects", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func serveRateLimited(r *gin.Engine, peer string, header map[string]string) int {
+	req := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+	req.RemoteAddr = peer + ":40000"
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	tests := []struct {
+		desc       string
+		ipBurst    int
+		tokenBurst int
+		proxies    []string
+		peer       string
+		headers    []map[string]string
+		want       []int
+	}{
+		{
+			desc:    "spoofed X-Forwarded-For shares the bucket of the peer",
+			ipBurst: 2, tokenBurst: 100,
+			peer: "203.0.113.7",
+			headers: []map[string]string{
+				{"X-Forwarded-For": "198.51.100.1"},
+				{"X-Forwarded-For": "198.51.100.2"},
+				{"X-Forwarded-For": "198.51.100.3"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			desc:    "X-Forwarded-For of a trusted proxy gives the client IP",
+			ipBurst: 2, tokenBurst: 100,
+			proxies: []string{"10.0.0.0/8"},
+			peer:    "10.0.0.1",
+			headers: []map[string]string{
+				{"X-Forwarded-For": "198.51.100.1"},
+				{"X-Forwarded-For": "198.51.100.2"},
+				{"X-Forwarded-For": "198.51.100.3"},
+				{"X-Forwarded-For": "198.51.100.1"},
+				{"X-Forwarded-For": "198.51.100.1"},
+			},
+			want: []int{
+				http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK,
+				http.StatusTooManyRequests,
+			},
+		},
+		{
+			desc:    "invalid credentials are limited on the IP only",
+			ipBurst: 2, tokenBurst: 100,
+			peer: "203.0.113.7",
+			headers: []map[string]string{
+				{entity.APIKeyHeader: "random1"},
+				{entity.AuthHeader: "Bearer random2"},
+				{entity.APIKeyHeader: "random3"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			desc:    "valid credentials are limited on their bucket",
+			ipBurst: 100, tokenBurst: 1,
+			peer: "203.0.113.7",
+			headers: []map[string]string{
+				{entity.APIKeyHeader: "valid"},
+				{entity.APIKeyHeader: "valid"},
+				{},
+			},
+			want: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := newRateLimitRouter(t, tt.ipBurst, tt.tokenBurst, tt.proxies)
+			for i, header := range tt.headers {
+				if got := serveRateLimited(r, tt.peer, header); got != tt.want[i] {
+					t.Errorf("request %d with %v = %d, want %d", i+1, header, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package delivery
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewReclamation(
+	uc *usecase.Reclamation,
+) *Reclamation {
+	return &Reclamation{
+		uc: uc,
+	}
+}
+
+type Reclamation struct {
+	uc *usecase.Reclamation
+}
+
+func reclamationError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		forbidden(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		log.Println("ERROR:", err)
+		c.AbortWithStatusJSON(http.StatusConflict, errorBody(http.StatusConflict, err))
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+type listReclamationsParams struct {
+	PerPage *int                      `form:"per_page"`
+	Page    *int                      `form:"page"`
+	Status  *entity.ReclamationStatus `form:"status"`
+}
+
+func (h *Reclamation) List(c *gin.Context) {
+	var p listReclamationsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListReclamationsParams{
+		Project: c.Param("project"),
+		Status:  p.Status,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		reclamationError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"reclamations",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
+
+func (h *Reclamation) Get(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetReclamationParams{
+		Project: c.Param("project"),
+		ID:      id,
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		reclamationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+// Manifest downloads the takes of an approved reclamation as CSV, for the storage team to
+// delete them.
+func (h *Reclamation) Manifest(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetReclamationParams{
+		Project: c.Param("project"),
+		ID:      id,
+	}
+	e, err := h.uc.Manifest(c.Request.Context(), params)
+	if err != nil {
+		reclamationError(c, err)
+		return
+	}
+	fileName := fmt.Sprintf("reclamation_%s_%d.csv", e.Project, e.ID)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment;filename="+fileName)
+	writer := csv.NewWriter(c.Writer)
+	defer writer.Flush()
+	records := [][]string{
+		{"review_info_id", "root", "group_1", "relation", "phase", "take", "take_path"},
+	}
+	for _, t := range e.Takes {
+		records = append(records, []string{
+			strconv.Itoa(int(t.ReviewInfoID)), t.Root, t.Group1, t.Relation, t.Phase, t.Take,
+			t.TakePath,
+		})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to generate CSV")
+	}
+}
+
+type createReclamationParams struct {
+	ReviewInfoIDs []int32 `json:"review_info_ids"`
+	Reason        string  `json:"reason"`
+	CreatedBy     string  `json:"created_by"`
+}
+
+// Post requests the deletion of the takes of review infos, to be approved by a supervisor.
+func (h *Reclamation) Post(c *gin.Context) {
+	var p createReclamationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateReclamationParams{
+		Project:       c.Param("project"),
+		ReviewInfoIDs: p.ReviewInfoIDs,
+		Reason:        p.Reason,
+		CreatedBy:     p.CreatedBy,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		reclamationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type reviewReclamationParams struct {
+	Approve    *bool   `json:"approve" binding:"required"`
+	Comment    *string `json:"comment"`
+	ReviewedBy string  `json:"reviewed_by"`
+}
+
+// Review approves or rejects a requested reclamation, by a supervisor other than its
+// requester.
+func (h *Reclamation) Review(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p reviewReclamationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ReviewReclamationParams{
+		Project:    c.Param("project"),
+		ID:         id,
+		Approve:    *p.Approve,
+		Comment:    p.Comment,
+		ReviewedBy: p.ReviewedBy,
+	}
+	e, err := h.uc.Review(c.Request.Context(), params)
+	if err != nil {
+		reclamationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type completeReclamationParams struct {
+	FreedBytes  *int64 `json:"freed_bytes"`
+	CompletedBy string `json:"completed_by"`
+}
+
+// Complete confirms the deletion of the takes of an approved reclamation with the bytes it
+// freed. It is restricted to admins, as the storage team.
+func (h *Reclamation) Complete(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf(
+			"%w: reclamations can only be completed by admins", entity.ErrForbidden,
+		))
+		return
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p completeReclamationParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CompleteReclamationParams{
+		Project:     c.Param("project"),
+		ID:          id,
+		FreedBytes:  p.FreedBytes,
+		CompletedBy: p.CompletedBy,
+	}
+	e, err := h.uc.Complete(c.Request.Context(), params)
+	if err != nil {
+		reclamationError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
@@ -0,0 +1,126 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewReport(
+	uc *usecase.Report,
+) *Report {
+	return &Report{
+		uc: uc,
+	}
+}
+
+type Report struct {
+	uc *usecase.Report
+}
+
+type getReviewerLoadParams struct {
+	Root  *string `form:"root"`
+	Phase *string `form:"phase"`
+	Days  *int    `form:"days"`
+}
+
+// GetReviewerLoad is the reviewer workload report of a project. Response latencies are
+// computed over the last `days` days, 30 by default.
+func (h *Report) GetReviewerLoad(c *gin.Context) {
+	var p getReviewerLoadParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetReviewerLoadParams{
+		Project: c.Param("project"),
+		Root:    p.Root,
+		Phase:   p.Phase,
+		Days:    30,
+	}
+	if p.Days != nil {
+		params.Days = *p.Days
+	}
+	report, err := h.uc.GetReviewerLoad(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, report)
+}
+
+type getSubmissionHeatmapParams struct {
+	From    *string `form:"from"`
+	To      *string `form:"to"`
+	GroupBy *string `form:"group_by"`
+}
+
+// GetSubmissionHeatmap counts the submissions of a project per day, week or month, root and
+// phase. `from` and `to` are dates formatted as YYYY-MM-DD; they default to the last year.
+// `max_staleness` bounds the age of a cached heatmap.
+func (h *Report) GetSubmissionHeatmap(c *gin.Context) {
+	var p getSubmissionHeatmapParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	maxStaleness, err := MaxStaleness(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if p.To != nil {
+		t, err := time.Parse("2006-01-02", *p.To)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(-1, 0, 1)
+	if p.From != nil {
+		t, err := time.Parse("2006-01-02", *p.From)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		from = t
+	}
+	params := &entity.GetSubmissionHeatmapParams{
+		Project:      c.Param("project"),
+		From:         from,
+		To:           to,
+		GroupBy:      entity.HeatmapGroupByDay,
+		MaxStaleness: maxStaleness,
+	}
+	if p.GroupBy != nil {
+		params.GroupBy = *p.GroupBy
+	}
+	heatmap, err := h.uc.GetSubmissionHeatmap(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, heatmap)
+}
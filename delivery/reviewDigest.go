@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewReviewDigest(
+	uc *usecase.ReviewDigest,
+) *ReviewDigest {
+	return &ReviewDigest{
+		uc: uc,
+	}
+}
+
+type ReviewDigest struct {
+	uc *usecase.ReviewDigest
+}
+
+type runReviewDigestsParams struct {
+	PendingHours *int32 `json:"pending_hours"`
+	DryRun       bool   `json:"dry_run"`
+}
+
+// Run compiles and sends the digests of the reviews pending for the supervisors, for the cron
+// jobs triggering them instead of the scheduler. The body is optional.
+func (h *ReviewDigest) Run(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf("%w: review digests are restricted to admins", entity.ErrForbidden))
+		return
+	}
+	var p runReviewDigestsParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.RunReviewDigestsParams{
+		PendingHours: h.uc.PendingHours(),
+		DryRun:       p.DryRun,
+	}
+	if p.PendingHours != nil {
+		params.PendingHours = *p.PendingHours
+	}
+	run, err := h.uc.Run(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, run)
+}
@@ -0,0 +1,1223 @@
+package delivery
+
+/* ──────────────────────────────────────────────────────────────────────────
+	Module Name:
+    	delivery/reviewInfo.go
+
+	Module Description:
+		HTTP delivery handlers for review information management.
+
+	Details:
+
+	Update and Modification History:
+		* - 29-10-2025 - SanjayK PSI - Initial creation sorting pagination implementation.
+		* - 07-11-2025 - SanjayK PSI - Column visibility toggling implementation.
+		* - 20-11-2025 - SanjayK PSI - Fixed typo in filter property names handling.
+		* - 15-10-2026 - Added review intent filters and project intent settings.
+		* - 15-10-2026 - Added approval gating and project approval gate settings.
+		* - 15-10-2026 - Added custom metadata and tag filters.
+		* - 15-10-2026 - Added admin corrections of submitted fields and their audit log.
+		* - 15-10-2026 - Added the admin rebuild of the materialized latest reviews.
+		* - 15-10-2026 - Added the group path prefix filter to the review list.
+		* - 15-10-2026 - Added per phase status filters to the asset pivot.
+		* - 15-10-2026 - Added submitted and modified date range filters to the review list and the asset pivot.
+		* - 15-10-2026 - Added the per phase rollup of the shots of a sequence, as JSON or CSV.
+		* - 15-10-2026 - Added multi-key sorting to the asset pivot.
+		* - 15-10-2026 - Added the latest approved phase values to the asset pivot.
+		* - 15-10-2026 - Added the deferred upload of the AllFiles manifest of reviews.
+		* - 15-10-2026 - Reported the unknown fields of the request bodies of reviews.
+		* - 15-10-2026 - Added the link of reviews to publish transactions and their reverse lookup.
+		* - 15-10-2026 - Responded 429 to the submissions over the submission limit of their asset.
+		* - 15-10-2026 - Added the restore, listing and admin purge of deleted reviews.
+		* - 15-10-2026 - Responded 409 with the current review to the updates of stale reviews.
+		* - 15-10-2026 - Responded the shared error format with the current review and unapproved upstreams.
+		* - 15-10-2026 - Timed the serialization of the asset pivot for the debug timing breakdown.
+
+	Functions:
+		* NewReviewInfo: Creates a new ReviewInfo handler.
+		* (ReviewInfo) List: Handles listing review information with filtering and pagination.
+		* (ReviewInfo) Get: Handles retrieving a specific review information by ID.
+		* (ReviewInfo) Post: Handles creating new review information.
+		* (ReviewInfo) Update: Handles updating existing review information.
+		* (ReviewInfo) PutManifest: Handles uploading the pending AllFiles manifest of a review information.
+		* (ReviewInfo) Delete: Handles deleting review information by ID.
+		* (ReviewInfo) Restore: Handles undoing the deletion of review information by ID.
+		* (ReviewInfo) ListDeleted: Handles listing the deleted review information of a project.
+		* (ReviewInfo) Purge: Handles removing for good the review information deleted long ago.
+		* (canCorrectReviewInfo) – utility function: Restricts corrections of submitted fields to admins.
+		* (ReviewInfo) ListAuditLogs: Handles listing the corrections of a review information.
+		* (ReviewInfo) ListAssets: Handles listing assets with filtering and pagination.
+		* (ReviewInfo) ListAssetReviewInfos: Handles listing review information for a specific asset.
+		* (ReviewInfo) ListShotReviewInfos: Handles listing review information for specific shots.
+		* (ReviewInfo) ListPublishTransactionReviewInfos: Handles listing the review information linked to a publish transaction.
+		* (splitCSV) – utility function: Splits a comma-separated string into a slice of trimmed strings.
+		* (PhaseStatusFilters) – utility function: Extracts the per phase status filters of the pivot.
+		* (PivotTimeFilters) – utility function: Parses the submitted and modified date ranges of the pivot.
+		* (PivotSort) – utility function: Parses a multi-key sort of the pivot.
+		* (ReviewInfo) ListAssetsPivot: Handles listing pivoted assets with filtering and sorting.
+		* (ReviewInfo) GetIntentSetting: Handles retrieving the intents hidden by default for a project.
+		* (ReviewInfo) UpdateIntentSetting: Handles changing the intents hidden by default for a project.
+		* (ReviewInfo) ListApprovalGates: Handles listing the approval gates of a project.
+		* (ReviewInfo) UpdateApprovalGate: Handles changing the approval gate of a project's phase.
+		* (ReviewInfo) RebuildLatest: Handles rebuilding the latest reviews of a project.
+		* (ReviewInfo) GetSequenceRollup: Handles the per phase rollup of the shots of a sequence.
+	────────────────────────────────────────────────────────────────────────── */
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type listReviewInfoParams struct {
+	Studio        *string    `form:"studio"`
+	TaskID        *string    `form:"task_id"`
+	SubtaskID     *string    `form:"subtask_id"`
+	Root          *string    `form:"root"`
+	Group         *string    `form:"groups"`
+	GroupPrefix   *string    `form:"group_prefix"`
+	Relation      *string    `form:"relation"`
+	Phase         *string    `form:"phase"`
+	Component     *string    `form:"component"`
+	Take          *string    `form:"take"`
+	Intent        *string    `form:"intent"`
+	Tags          *string    `form:"tags"`
+	PerPage       *int       `form:"per_page"`
+	Page          *int       `form:"page"`
+	ModifiedSince *time.Time `form:"modified_since"`
+	SubmittedFrom *time.Time `form:"submitted_from"`
+	SubmittedTo   *time.Time `form:"submitted_to"`
+	ModifiedFrom  *time.Time `form:"modified_from"`
+	ModifiedTo    *time.Time `form:"modified_to"`
+}
+
+func (p *listReviewInfoParams) Entity(project string) *entity.ListReviewInfoParams {
+	var group []string
+	if p.Group != nil {
+		group = strings.Split(*p.Group, "/")
+	}
+	var relation []string
+	if p.Relation != nil {
+		relation = strings.Split(*p.Relation, ",")
+	}
+	var phase []string
+	if p.Phase != nil {
+		phase = strings.Split(*p.Phase, ",")
+	}
+	var intent []string
+	if p.Intent != nil {
+		intent = strings.Split(*p.Intent, ",")
+	}
+	var tags []string
+	if p.Tags != nil {
+		tags = strings.Split(*p.Tags, ",")
+	}
+	params := &entity.ListReviewInfoParams{
+		Project:       project,
+		Studio:        p.Studio,
+		TaskID:        p.TaskID,
+		SubtaskID:     p.SubtaskID,
+		Root:          p.Root,
+		Group:         group,
+		GroupPrefix:   p.GroupPrefix,
+		Relation:      relation,
+		Phase:         phase,
+		Component:     p.Component,
+		Take:          p.Take,
+		Intent:        intent,
+		Tags:          tags,
+		SubmittedFrom: p.SubmittedFrom,
+		SubmittedTo:   p.SubmittedTo,
+		ModifiedFrom:  p.ModifiedFrom,
+		ModifiedTo:    p.ModifiedTo,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	if p.ModifiedSince != nil {
+		params.ModifiedSince = p.ModifiedSince
+	}
+
+	return params
+}
+
+type createReviewInfoParams struct {
+	TaskID                    string              `json:"task_id"`
+	SubtaskID                 string              `json:"subtask_id"`
+	Studio                    string              `json:"studio"`
+	ProjectPath               string              `json:"project_path"`
+	ReviewComments            []*libs.CommentInfo `json:"review_comments"`
+	Path                      *string             `json:"path"`
+	TakePath                  string              `json:"take_path" binding:"required_without=Path"`
+	Root                      string              `json:"root"`
+	Groups                    []string            `json:"groups"`
+	Relation                  string              `json:"relation"`
+	Phase                     string              `json:"phase"`
+	Component                 string              `json:"component"`
+	Take                      string              `json:"take"`
+	Intent                    *string             `json:"intent"`
+	ApprovalStatus            string              `json:"approval_status"`
+	ApprovalStatusUpdatedUser string              `json:"approval_status_updated_user"`
+	WorkStatus                string              `json:"work_status"`
+	WorkStatusUpdatedUser     string              `json:"work_status_updated_user"`
+	ReviewTarget              []*libs.Content     `json:"review_target"`
+	ReviewData                []*libs.Content     `json:"review_data"`
+	OutputContents            []*libs.Content     `json:"output_contents"`
+	SubmittedAtUtc            time.Time           `json:"submitted_at_utc"`
+	SubmittedComputer         string              `json:"submitted_computer"`
+	SubmittedOS               string              `json:"submitted_os"`
+	SubmittedOSVersion        string              `json:"submitted_os_version"`
+	SubmittedUser             string              `json:"submitted_user"`
+	ExecutedAtUtc             time.Time           `json:"executed_at_utc"`
+	ExecutedComputer          string              `json:"executed_computer"`
+	ExecutedOS                string              `json:"executed_os"`
+	ExecutedOSVersion         string              `json:"executed_os_version"`
+	ExecutedUser              string              `json:"executed_user"`
+	AllFiles                  []*libs.File        `json:"all_files"`
+	NumAllFiles               uint32              `json:"num_all_files"`
+	SizeAllFiles              uint64              `json:"size_all_files"`
+	TargetComponents          []string            `json:"target_components"`
+	ManifestPending           bool                `json:"manifest_pending"`
+	PublishTransactionID      *string             `json:"publish_transaction_id"`
+
+	Metadata entity.JSONObject `json:"metadata"`
+
+	Duration                    *int32  `json:"duration,omitempty"`
+	DurationTimeline            *string `json:"duration_timeline,omitempty"`
+	ExportShotsVersions         *bool   `json:"export_shotsVersions,omitempty"`
+	ExportShotsVersionsRevision *string `json:"export_shotsVersions_revision,omitempty"`
+	ExportShotsVersionsPath     *string `json:"export_shotsVersions_path,omitempty"`
+}
+
+func (p *createReviewInfoParams) Entity(
+	project string,
+	createdBy *string,
+) *entity.CreateReviewInfoParams {
+	takePath := p.TakePath
+	if takePath == "" && p.Path != nil {
+		takePath = *p.Path
+	}
+	return &entity.CreateReviewInfoParams{
+		Project:   project,
+		CreatedBy: createdBy,
+
+		TaskID:                    p.TaskID,
+		SubtaskID:                 p.SubtaskID,
+		Studio:                    p.Studio,
+		ProjectPath:               p.ProjectPath,
+		ReviewComments:            p.ReviewComments,
+		TakePath:                  takePath,
+		Root:                      p.Root,
+		Groups:                    p.Groups,
+		Relation:                  p.Relation,
+		Phase:                     p.Phase,
+		Component:                 p.Component,
+		Take:                      p.Take,
+		Intent:                    p.Intent,
+		ApprovalStatus:            p.ApprovalStatus,
+		ApprovalStatusUpdatedUser: p.ApprovalStatusUpdatedUser,
+		WorkStatus:                p.WorkStatus,
+		WorkStatusUpdatedUser:     p.WorkStatusUpdatedUser,
+		ReviewTarget:              p.ReviewTarget,
+		ReviewData:                p.ReviewData,
+		OutputContents:            p.OutputContents,
+		SubmittedAtUtc:            p.SubmittedAtUtc,
+		SubmittedComputer:         p.SubmittedComputer,
+		SubmittedOS:               p.SubmittedOS,
+		SubmittedOSVersion:        p.SubmittedOSVersion,
+		SubmittedUser:             p.SubmittedUser,
+		ExecutedAtUtc:             p.ExecutedAtUtc,
+		ExecutedComputer:          p.ExecutedComputer,
+		ExecutedOS:                p.ExecutedOS,
+		ExecutedOSVersion:         p.ExecutedOSVersion,
+		ExecutedUser:              p.ExecutedUser,
+		AllFiles:                  p.AllFiles,
+		NumAllFiles:               p.NumAllFiles,
+		SizeAllFiles:              p.SizeAllFiles,
+		TargetComponents:          p.TargetComponents,
+		ManifestPending:           p.ManifestPending,
+		PublishTransactionID:      p.PublishTransactionID,
+
+		Metadata: p.Metadata,
+
+		Duration:                    p.Duration,
+		DurationTimeline:            p.DurationTimeline,
+		ExportShotsVersions:         p.ExportShotsVersions,
+		ExportShotsVersionsRevision: p.ExportShotsVersionsRevision,
+		ExportShotsVersionsPath:     p.ExportShotsVersionsPath,
+	}
+}
+
+type updateReviewInfoParams struct {
+	ApprovalStatus            *string `json:"approval_status,omitempty"`
+	ApprovalStatusUpdatedUser *string `json:"approval_status_updated_user,omitempty"`
+	WorkStatus                *string `json:"work_status,omitempty"`
+	WorkStatusUpdatedUser     *string `json:"work_status_updated_user,omitempty"`
+
+	Metadata entity.JSONObject `json:"metadata,omitempty"`
+
+	TakePath     *string         `json:"take_path,omitempty"`
+	Duration     *int32          `json:"duration,omitempty"`
+	ReviewTarget []*libs.Content `json:"review_target,omitempty"`
+
+	// Version is the version of the review the update was made from.
+	Version *uint32 `json:"version,omitempty"`
+}
+
+func (p *updateReviewInfoParams) Entity(
+	project string,
+	id int32,
+	modifiedBy *string,
+) *entity.UpdateReviewInfoParams {
+	return &entity.UpdateReviewInfoParams{
+		ApprovalStatus:            p.ApprovalStatus,
+		ApprovalStatusUpdatedUser: p.ApprovalStatusUpdatedUser,
+		WorkStatus:                p.WorkStatus,
+		WorkStatusUpdatedUser:     p.WorkStatusUpdatedUser,
+		Project:                   project,
+		ID:                        id,
+		ModifiedBy:                modifiedBy,
+
+		Metadata: p.Metadata,
+
+		TakePath:     p.TakePath,
+		Duration:     p.Duration,
+		ReviewTarget: p.ReviewTarget,
+
+		Version: p.Version,
+	}
+}
+
+// canCorrectReviewInfo reports whether the requester may correct the submitted fields of
+// reviews, which is restricted to admin studios.
+func canCorrectReviewInfo(c *gin.Context) bool {
+	if entity.SkipAuth {
+		return true
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	return isAdminStudio(studio)
+}
+
+func NewReviewInfo(
+	uc *usecase.ReviewInfo,
+) *ReviewInfo {
+	return &ReviewInfo{
+		uc: uc,
+	}
+}
+
+type ReviewInfo struct {
+	uc *usecase.ReviewInfo
+}
+
+func (h *ReviewInfo) List(c *gin.Context) {
+	var p listReviewInfoParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := p.Entity(c.Param("project"))
+	params.Metadata = MetadataFilters(c.Request.URL.Query())
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	res := libs.CreateListResponse("reviews", entities, c.Request, params, total)
+	c.PureJSON(http.StatusOK, res)
+}
+
+// getReviewParams accepts both the numeric ID and the ULID of a record as the "id" path
+// parameter.
+func getReviewParams(c *gin.Context) *entity.GetReviewParams {
+	params := &entity.GetReviewParams{
+		Project: c.Param("project"),
+	}
+	rawID := c.Param("id")
+	if id, err := strconv.Atoi(rawID); err == nil {
+		params.ID = int32(id)
+	} else {
+		params.UID = &rawID
+	}
+	return params
+}
+
+func (h *ReviewInfo) Get(c *gin.Context) {
+	params := getReviewParams(c)
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			badRequest(c, fmt.Errorf("review info with ID %s not found", c.Param("id")))
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Header("Last-Modified", e.ModifiedAtUTC.Format(http.TimeFormat))
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ReviewInfo) Post(c *gin.Context) {
+	var p createReviewInfoParams
+	if err := bindBody(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := p.Entity(c.Param("project"), nil)
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		if submissionRateLimited(c, err) {
+			return
+		}
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ReviewInfo) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p updateReviewInfoParams
+	if err := bindBody(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := p.Entity(c.Param("project"), int32(id), nil)
+	if v := c.GetHeader("If-Unmodified-Since"); v != "" {
+		t, err := http.ParseTime(v)
+		if err != nil {
+			badRequest(c, fmt.Errorf("invalid If-Unmodified-Since header %q: %w", v, err))
+			return
+		}
+		params.UnmodifiedSince = &t
+	}
+	if params.HasCorrections() {
+		if !canCorrectReviewInfo(c) {
+			forbidden(c, fmt.Errorf(
+				"%w: take_path, duration and review_target can only be corrected by admins",
+				entity.ErrForbidden,
+			))
+			return
+		}
+		studio, _ := c.Get("studio")
+		params.Studio, _ = studio.(string)
+	}
+	e, err := h.uc.Update(c.Request.Context(), NewLogger(c.Request), params)
+	if err != nil {
+		var staleErr *entity.StaleReviewInfoError
+		if errors.As(err, &staleErr) {
+			log.Println("ERROR:", err)
+			body := errorBody(http.StatusConflict, err)
+			body["current"] = staleErr.Current
+			c.AbortWithStatusJSON(http.StatusConflict, body)
+			return
+		}
+		var gateErr *entity.ApprovalGateError
+		if errors.As(err, &gateErr) {
+			log.Println("ERROR:", err)
+			body := errorBody(http.StatusConflict, err)
+			body["unapproved"] = gateErr.Unapproved
+			c.AbortWithStatusJSON(http.StatusConflict, body)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			badRequest(c, fmt.Errorf("review info with ID %d not found", params.ID))
+			return
+		}
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Header("Last-Modified", e.ModifiedAtUTC.Format(http.TimeFormat))
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateReviewManifestParams struct {
+	AllFiles   []*libs.File `json:"all_files"`
+	ModifiedBy *string      `json:"modified_by"`
+}
+
+// PutManifest uploads the AllFiles manifest of a review created with manifest_pending.
+func (h *ReviewInfo) PutManifest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p updateReviewManifestParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateReviewManifestParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		AllFiles:   p.AllFiles,
+		ModifiedBy: p.ModifiedBy,
+	}
+	e, err := h.uc.UpdateManifest(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, fmt.Errorf("review info with ID %d not found", params.ID))
+			return
+		}
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ReviewInfo) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteReviewInfoParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			badRequest(c, fmt.Errorf("review info with ID %d not found", params.ID))
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ReviewInfo) Restore(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.RestoreReviewInfoParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.Restore(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, fmt.Errorf("deleted review info with ID %d not found", params.ID))
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type listDeletedReviewInfosParams struct {
+	PerPage       *int       `form:"per_page"`
+	Page          *int       `form:"page"`
+	DeletedBefore *time.Time `form:"deleted_before" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// ListDeleted lists the deleted reviews of a project, which can be restored until they are
+// purged.
+func (h *ReviewInfo) ListDeleted(c *gin.Context) {
+	var p listDeletedReviewInfosParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListDeletedReviewInfosParams{
+		Project:       c.Param("project"),
+		DeletedBefore: p.DeletedBefore,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.ListDeleted(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	res := libs.CreateListResponse("reviews", entities, c.Request, params, total)
+	c.PureJSON(http.StatusOK, res)
+}
+
+type purgeReviewInfosParams struct {
+	RetentionDays *int32 `json:"retention_days"`
+	DryRun        bool   `json:"dry_run"`
+}
+
+// Purge removes for good the reviews of a project deleted more than `retention_days` days
+// ago, 90 by default. It is restricted to admins.
+func (h *ReviewInfo) Purge(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, errors.New("purging the deleted reviews is restricted to admins"))
+		return
+	}
+	var p purgeReviewInfosParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.PurgeReviewInfosParams{
+		Project:       c.Param("project"),
+		RetentionDays: entity.DefaultReviewPurgeRetentionDays,
+		DryRun:        p.DryRun,
+		PurgedBy:      studio,
+	}
+	if p.RetentionDays != nil {
+		params.RetentionDays = *p.RetentionDays
+	}
+	e, err := h.uc.Purge(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ReviewInfo) ListAuditLogs(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListReviewInfoAuditLogsParams{
+		Project:      c.Param("project"),
+		ReviewInfoID: int32(id),
+	}
+	entities, err := h.uc.ListAuditLogs(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"audit_logs": entities})
+}
+
+type assetListParams struct {
+	Studio  *string `form:"studio"`
+	PerPage *int    `form:"per_page"`
+	Page    *int    `form:"page"`
+}
+
+func (p *assetListParams) Entity(project string) *entity.AssetListParams {
+	params := &entity.AssetListParams{
+		Project: project,
+		Studio:  p.Studio,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+
+	return params
+}
+
+func (h *ReviewInfo) ListAssets(c *gin.Context) {
+	var p assetListParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := p.Entity(c.Param("project"))
+	entities, total, err := h.uc.ListAssets(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	res := libs.CreateListResponse("assets", entities, c.Request, params, total)
+	c.PureJSON(http.StatusOK, res)
+}
+
+func (p *listReviewInfoParams) assetReviewInfoEntity(
+	project string,
+	asset string,
+	relation string,
+) *entity.AssetReviewInfoListParams {
+	params := &entity.AssetReviewInfoListParams{
+		Project:  project,
+		Asset:    asset,
+		Relation: relation,
+	}
+
+	return params
+}
+
+func (h *ReviewInfo) ListAssetReviewInfos(c *gin.Context) {
+	var p listReviewInfoParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	params := p.assetReviewInfoEntity(
+		c.Param("project"),
+		c.Param("asset"),
+		c.Param("relation"),
+	)
+	entities, err := h.uc.ListAssetReviewInfos(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	res := map[string]interface{}{
+		"reviews": entities,
+	}
+	c.PureJSON(http.StatusOK, res)
+}
+
+type listPublishTransactionReviewInfosParams struct {
+	PerPage *int `form:"per_page"`
+	Page    *int `form:"page"`
+}
+
+// ListPublishTransactionReviewInfos lists the reviews linked to the publish transaction of
+// the log ID.
+func (h *ReviewInfo) ListPublishTransactionReviewInfos(c *gin.Context) {
+	var p listPublishTransactionReviewInfosParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.PublishTransactionReviewInfoListParams{
+		Project: c.Param("project"),
+		LogID:   c.Param("logID"),
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.ListPublishTransactionReviewInfos(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"reviews",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
+
+func (p *listReviewInfoParams) shotReviewInfoEntity(
+	project string,
+	group string,
+	relation string,
+) *entity.ShotReviewInfoListParams {
+	var groups []string
+	if group != "" {
+		groups = strings.Split(group, "/")
+	}
+	params := &entity.ShotReviewInfoListParams{
+		Project:  project,
+		Groups:   groups,
+		Relation: relation,
+	}
+
+	return params
+}
+
+func (h *ReviewInfo) ListShotReviewInfos(c *gin.Context) {
+	var p listReviewInfoParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	params := p.shotReviewInfoEntity(
+		c.Param("project"),
+		c.Query("groups"),
+		c.Query("relation"),
+	)
+	entities, err := h.uc.ListShotReviewInfos(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	res := map[string]interface{}{
+		"reviews": entities,
+	}
+	c.PureJSON(http.StatusOK, res)
+}
+
+/*
+* ========================================================================================
+  - splitCSV – utility function
+  - Splits a comma-separated string into a slice of trimmed strings.
+  - Ignores empty entries.
+
+==========================================================================================
+*/
+func splitCSV(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if t := strings.TrimSpace(p); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// phaseStatusFilterPattern matches the per phase status filters of the pivot, e.g.
+// mdl_approval_status or rig_work_status.
+var phaseStatusFilterPattern = regexp.MustCompile(`^([a-z][a-z0-9]{0,19})_(approval|work)_status$`)
+
+// PhaseStatusFilters extracts the per phase status filters given as
+// `<phase>_approval_status=<statuses>` and `<phase>_work_status=<statuses>` query parameters,
+// keyed by phase.
+func PhaseStatusFilters(q url.Values) (approval, work map[string][]string) {
+	for k, v := range q {
+		m := phaseStatusFilterPattern.FindStringSubmatch(strings.ToLower(k))
+		if m == nil || len(v) == 0 {
+			continue
+		}
+		statuses := splitCSV(strings.ToLower(v[0]))
+		if len(statuses) == 0 {
+			continue
+		}
+		if m[2] == "approval" {
+			if approval == nil {
+				approval = map[string][]string{}
+			}
+			approval[m[1]] = statuses
+		} else {
+			if work == nil {
+				work = map[string][]string{}
+			}
+			work[m[1]] = statuses
+		}
+	}
+	return approval, work
+}
+
+// PivotTimeFilters parses the submitted_from, submitted_to, modified_from and modified_to
+// query parameters, in RFC 3339, into the pivot params.
+func PivotTimeFilters(c *gin.Context, p *repository.ListAssetsPivotParams) error {
+	for key, dst := range map[string]**time.Time{
+		"submitted_from": &p.SubmittedFrom,
+		"submitted_to":   &p.SubmittedTo,
+		"modified_from":  &p.ModifiedFrom,
+		"modified_to":    &p.ModifiedTo,
+	} {
+		raw := strings.TrimSpace(c.Query(key))
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		t = t.UTC()
+		*dst = &t
+	}
+	return nil
+}
+
+// maxPivotSortKeys is the most keys of a multi-key sort of the pivot.
+const maxPivotSortKeys = 5
+
+// PivotSort parses a multi-key sort of the pivot given as `sort=<key>:<dir>,<key>:<dir>`, e.g.
+// `mdl_appr:desc,group_1:asc`, the direction being asc when omitted. It returns nil for a
+// single key without direction, which is sorted on as before in the direction of `dir`. The
+// keys are checked by the repository.
+func PivotSort(raw string) ([]repository.PivotSortKey, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if !strings.ContainsAny(raw, ",:") {
+		return nil, nil
+	}
+	terms := strings.Split(raw, ",")
+	if len(terms) > maxPivotSortKeys {
+		return nil, fmt.Errorf("sort has more than %d keys", maxPivotSortKeys)
+	}
+	keys := make([]repository.PivotSortKey, len(terms))
+	for i, term := range terms {
+		key, dir, _ := strings.Cut(term, ":")
+		key, dir = strings.TrimSpace(key), strings.TrimSpace(dir)
+		if key == "" {
+			return nil, fmt.Errorf("invalid sort %q: empty key", raw)
+		}
+		if dir != "" && dir != "asc" && dir != "desc" {
+			return nil, fmt.Errorf("invalid sort direction %q of %s", dir, key)
+		}
+		keys[i] = repository.PivotSortKey{Key: key, Desc: dir == "desc"}
+	}
+	return keys, nil
+}
+
+/*
+========================================================================================
+  - ListAssetsPivot – handler function
+  - Handles HTTP requests to list pivoted assets with filtering, sorting, and pagination.
+  - Extracts parameters from the request, invokes the usecase, and returns the results as JSON.
+
+========================================================================================
+*/
+func (h *ReviewInfo) ListAssetsPivot(c *gin.Context) {
+	// ---- Required path param ----
+	project := strings.TrimSpace(c.Param("project"))
+	if project == "" {
+		badRequest(c, fmt.Errorf("project is required"))
+		return
+	}
+
+	// ---- Query params ----
+	root := strings.TrimSpace(c.DefaultQuery("root", "assets"))
+	if root == "" {
+		root = "assets"
+	}
+
+	view := strings.TrimSpace(c.DefaultQuery("view", "list")) // list | grouped
+
+	sortKey := strings.TrimSpace(c.DefaultQuery("sort", "group_1"))
+	dir := strings.TrimSpace(c.DefaultQuery("dir", "asc")) // usecase will normalize
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+	if perPage < 1 {
+		perPage = 15
+	}
+
+	assetNameKey := strings.TrimSpace(c.DefaultQuery("name", ""))
+
+	// Support both new & old query keys
+	approvalRaw := c.Query("approval_status")
+	if approvalRaw == "" {
+		approvalRaw = c.Query("appr")
+	}
+	workRaw := c.Query("work_status")
+	if workRaw == "" {
+		workRaw = c.Query("work")
+	}
+
+	approvalStatuses := splitCSV(approvalRaw)
+	workStatuses := splitCSV(workRaw)
+	intents := splitCSV(c.Query("intent"))
+	metadata := MetadataFilters(c.Request.URL.Query())
+	tags := TagFilters(c)
+	phaseApprovalStatuses, phaseWorkStatuses := PhaseStatusFilters(c.Request.URL.Query())
+
+	// ---- Context timeout ----
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	// ---- NEW usecase signature: (ctx, params) -> (result, error) ----
+	params := repository.ListAssetsPivotParams{
+		Project:               project,
+		Root:                  root,
+		OrderKey:              sortKey,
+		Direction:             dir,
+		Page:                  page,
+		PerPage:               perPage,
+		AssetNameKey:          assetNameKey,
+		ApprovalStatuses:      approvalStatuses,
+		WorkStatuses:          workStatuses,
+		PhaseApprovalStatuses: phaseApprovalStatuses,
+		PhaseWorkStatuses:     phaseWorkStatuses,
+		Intents:               intents,
+		Metadata:              metadata,
+		Tags:                  tags,
+		View:                  view,
+		PhaseValue:            strings.ToLower(strings.TrimSpace(c.Query("phase_value"))),
+	}
+	if err := PivotTimeFilters(c, &params); err != nil {
+		badRequest(c, err)
+		return
+	}
+	sortKeys, err := PivotSort(sortKey)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params.Sort = sortKeys
+
+	result, err := h.uc.ListAssetsPivot(ctx, params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	res := gin.H{
+		"assets":    result.Assets,
+		"total":     result.Total,
+		"page":      result.Page,
+		"per_page":  result.PerPage,
+		"page_last": result.PageLast,
+		"has_next":  result.HasNext,
+		"has_prev":  result.HasPrev,
+		"sort":      result.Sort,
+		"dir":       result.Dir,
+		"project":   project,
+		"root":      root,
+		"view":      view,
+	}
+	if len(result.Groups) > 0 {
+		res["groups"] = result.Groups
+	}
+
+	stopSerialize := entity.StartTiming(c.Request.Context(), entity.TimingSerialize)
+	c.PureJSON(http.StatusOK, res)
+	stopSerialize()
+}
+
+func (h *ReviewInfo) GetIntentSetting(c *gin.Context) {
+	params := &entity.GetReviewIntentSettingParams{
+		Project: c.Param("project"),
+	}
+	e, err := h.uc.GetIntentSetting(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateReviewIntentSettingParams struct {
+	ExcludedIntents []string `json:"excluded_intents" binding:"required"`
+}
+
+func (h *ReviewInfo) UpdateIntentSetting(c *gin.Context) {
+	var p updateReviewIntentSettingParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateReviewIntentSettingParams{
+		Project:         c.Param("project"),
+		ExcludedIntents: p.ExcludedIntents,
+		ModifiedBy:      nil,
+	}
+	e, err := h.uc.UpdateIntentSetting(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ReviewInfo) ListApprovalGates(c *gin.Context) {
+	params := &entity.ListReviewApprovalGatesParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.ListApprovalGates(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"approval_gates": entities})
+}
+
+type updateReviewApprovalGateParams struct {
+	Mode string `json:"mode" binding:"required"`
+}
+
+func (h *ReviewInfo) UpdateApprovalGate(c *gin.Context) {
+	var p updateReviewApprovalGateParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateReviewApprovalGateParams{
+		Project:    c.Param("project"),
+		Phase:      c.Param("phase"),
+		Mode:       p.Mode,
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.UpdateApprovalGate(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+// RebuildLatest rebuilds the latest reviews of the project the asset pivot and the latest
+// submission counts read, to backfill them or to repair them. It is restricted to admins.
+func (h *ReviewInfo) RebuildLatest(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, errors.New("rebuilding the latest reviews is restricted to admins"))
+		return
+	}
+	params := &entity.RebuildReviewLatestParams{
+		Project:   c.Param("project"),
+		RebuiltBy: studio,
+	}
+	e, err := h.uc.RebuildLatest(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type getSequenceRollupParams struct {
+	Episode *string `form:"episode"`
+	Format  *string `form:"format"`
+}
+
+// GetSequenceRollup is the percentage of the shots of a sequence submitted and approved per
+// phase, as JSON or, with `format=csv`, as CSV. `max_staleness` bounds the age of a cached
+// rollup.
+func (h *ReviewInfo) GetSequenceRollup(c *gin.Context) {
+	var p getSequenceRollupParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	maxStaleness, err := MaxStaleness(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetSequenceRollupParams{
+		Project:      c.Param("project"),
+		Episode:      p.Episode,
+		Sequence:     c.Param("sequence"),
+		Format:       entity.SequenceRollupFormatJSON,
+		MaxStaleness: maxStaleness,
+	}
+	if p.Format != nil {
+		params.Format = *p.Format
+	}
+	rollup, err := h.uc.GetSequenceRollup(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	CacheControl(c, 15*time.Second, maxStaleness)
+	if params.Format != entity.SequenceRollupFormatCSV {
+		c.PureJSON(http.StatusOK, rollup)
+		return
+	}
+	fileName := fmt.Sprintf("rollup_%s_%s.csv", rollup.Project, rollup.Sequence)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment;filename="+fileName)
+	writer := csv.NewWriter(c.Writer)
+	defer writer.Flush()
+	records := [][]string{
+		{"phase", "shots", "submitted", "approved", "submitted_percent", "approved_percent"},
+	}
+	for _, phase := range rollup.Phases {
+		records = append(records, []string{
+			phase.Phase,
+			strconv.Itoa(rollup.Shots),
+			strconv.Itoa(phase.Submitted),
+			strconv.Itoa(phase.Approved),
+			strconv.FormatFloat(phase.SubmittedPercent, 'f', 2, 64),
+			strconv.FormatFloat(phase.ApprovedPercent, 'f', 2, 64),
+		})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to generate CSV")
+	}
+}
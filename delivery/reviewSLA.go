@@ -0,0 +1,110 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewReviewSLA(
+	uc *usecase.ReviewSLA,
+) *ReviewSLA {
+	return &ReviewSLA{
+		uc: uc,
+	}
+}
+
+type ReviewSLA struct {
+	uc *usecase.ReviewSLA
+}
+
+func (h *ReviewSLA) List(c *gin.Context) {
+	params := &entity.ListReviewSLAsParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"slas": entities})
+}
+
+type updateReviewSLAParams struct {
+	FeedbackWithinHours int32    `json:"feedback_within_hours" binding:"required"`
+	MailAddresses       []string `json:"mail_addresses"`
+}
+
+func (h *ReviewSLA) Update(c *gin.Context) {
+	var p updateReviewSLAParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateReviewSLAParams{
+		Project:             c.Param("project"),
+		Phase:               c.Param("phase"),
+		FeedbackWithinHours: p.FeedbackWithinHours,
+		MailAddresses:       p.MailAddresses,
+		ModifiedBy:          nil,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *ReviewSLA) Delete(c *gin.Context) {
+	params := &entity.DeleteReviewSLAParams{
+		Project:    c.Param("project"),
+		Phase:      c.Param("phase"),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type listReviewSLABreachesParams struct {
+	Phase      *string `form:"phase"`
+	Unresolved bool    `form:"unresolved"`
+	PerPage    *int    `form:"per_page"`
+	Page       *int    `form:"page"`
+}
+
+// ListBreaches is the SLA breach report of a project, newest first.
+func (h *ReviewSLA) ListBreaches(c *gin.Context) {
+	var p listReviewSLABreachesParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListReviewSLABreachesParams{
+		Project:    c.Param("project"),
+		Phase:      p.Phase,
+		Unresolved: p.Unresolved,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.ListBreaches(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	res := libs.CreateListResponse("breaches", entities, c.Request, params, total)
+	c.PureJSON(http.StatusOK, res)
+}
@@ -0,0 +1,199 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewThumbnail struct {
+	uc *usecase.ReviewThumbnail
+}
+
+func NewReviewThumbnail(uc *usecase.ReviewThumbnail) *ReviewThumbnail {
+	return &ReviewThumbnail{
+		uc: uc,
+	}
+}
+
+func (rt *ReviewThumbnail) GetAssetThumbnail(c *gin.Context) {
+	params := &entity.GetAssetThumbnailParams{
+		Project:  c.Param("project"),
+		Asset:    c.Param("asset"),
+		Relation: c.Param("relation"),
+	}
+
+	thumbnailPath, err := rt.uc.GetAssetThumbnail(c.Request.Context(), params)
+	if thumbnailPath == "" || err != nil {
+		if err == os.ErrNotExist {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Thumbnail Filepath is missing"})
+		return
+	}
+	rt.serveThumbnail(c, thumbnailPath)
+}
+
+// serveThumbnail serves a thumbnail file, decrypting the thumbnails encrypted with a key of the
+// project. The decrypted thumbnails are not cached by the browsers nor the proxies.
+func (rt *ReviewThumbnail) serveThumbnail(c *gin.Context, thumbnailPath string) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	r, err := rt.uc.OpenEncryptedThumbnail(
+		c.Request.Context(), c.Param("project"), thumbnailPath, studio,
+	)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	if r == nil {
+		c.File(thumbnailPath)
+		return
+	}
+	defer r.Close()
+	c.DataFromReader(
+		http.StatusOK, -1, repository.ThumbnailContentType(thumbnailPath), r,
+		map[string]string{"Cache-Control": "no-store"},
+	)
+}
+
+type shotThumbnailParams struct {
+	Group1   string `form:"group1"`
+	Group2   string `form:"group2"`
+	Group3   string `form:"group3"`
+	Relation string `form:"relation"`
+}
+
+func (p *shotThumbnailParams) Entity(project string) *entity.GetShotThumbnailParams {
+	return &entity.GetShotThumbnailParams{
+		Project:  project,
+		Group1:   p.Group1,
+		Group2:   p.Group2,
+		Group3:   p.Group3,
+		Relation: p.Relation,
+	}
+}
+
+func (rt *ReviewThumbnail) GetShotThumbnail(c *gin.Context) {
+	var p shotThumbnailParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params := p.Entity(c.Param("project"))
+	thumbnailPath, err := rt.uc.GetShotThumbnail(params)
+	if thumbnailPath == "" || err != nil {
+		if err == os.ErrNotExist {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Thumbnail Filepath is missing"})
+		return
+	}
+	rt.serveThumbnail(c, thumbnailPath)
+}
+
+const maxBatchThumbnailKeys = 100
+
+type batchAssetThumbnailsParams struct {
+	Keys   []entity.AssetThumbnailKey `json:"keys"`
+	Inline bool                       `json:"inline"`
+}
+
+func (p *batchAssetThumbnailsParams) Entity(project string) *entity.BatchGetAssetThumbnailsParams {
+	return &entity.BatchGetAssetThumbnailsParams{
+		Project: project,
+		Keys:    p.Keys,
+		Inline:  p.Inline,
+	}
+}
+
+func (rt *ReviewThumbnail) BatchGetAssetThumbnails(c *gin.Context) {
+	var p batchAssetThumbnailsParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	if len(p.Keys) == 0 || len(p.Keys) > maxBatchThumbnailKeys {
+		badRequest(c, fmt.Errorf(
+			"keys must contain between 1 and %d entries", maxBatchThumbnailKeys,
+		))
+		return
+	}
+	params := p.Entity(c.Param("project"))
+	name, _ := c.Get("studio")
+	params.Studio, _ = name.(string)
+	thumbnails, err := rt.uc.BatchGetAssetThumbnails(c.Request.Context(), params)
+	if err != nil {
+		reviewThumbnailError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"thumbnails": thumbnails})
+}
+
+func reviewThumbnailError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+type pinAssetThumbnailParams struct {
+	Phase    string `json:"phase"`
+	Revision string `json:"revision"`
+	Name     string `json:"name"`
+	PinnedBy string `json:"pinned_by"`
+}
+
+// PinAssetThumbnail pins the thumbnail Name of the take Revision of Phase as the image of the
+// asset, which defaults to the small thumbnail.
+func (rt *ReviewThumbnail) PinAssetThumbnail(c *gin.Context) {
+	var p pinAssetThumbnailParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.PinAssetThumbnailParams{
+		Project:  c.Param("project"),
+		Asset:    c.Param("asset"),
+		Relation: c.Param("relation"),
+		Phase:    p.Phase,
+		Revision: p.Revision,
+		Name:     p.Name,
+		PinnedBy: p.PinnedBy,
+	}
+	if params.Name == "" {
+		params.Name = "thumbnail_s.png"
+	}
+	e, err := rt.uc.PinAssetThumbnail(c.Request.Context(), params)
+	if err != nil {
+		reviewThumbnailError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (rt *ReviewThumbnail) UnpinAssetThumbnail(c *gin.Context) {
+	params := &entity.UnpinAssetThumbnailParams{
+		Project:  c.Param("project"),
+		Asset:    c.Param("asset"),
+		Relation: c.Param("relation"),
+	}
+	if err := rt.uc.UnpinAssetThumbnail(c.Request.Context(), params); err != nil {
+		reviewThumbnailError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
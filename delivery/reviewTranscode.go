@@ -0,0 +1,87 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewReviewTranscode(
+	uc *usecase.ReviewTranscode,
+) *ReviewTranscode {
+	return &ReviewTranscode{
+		uc: uc,
+	}
+}
+
+type ReviewTranscode struct {
+	uc *usecase.ReviewTranscode
+}
+
+func reviewTranscodeError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+func (h *ReviewTranscode) List(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListReviewTranscodesParams{
+		Project:      c.Param("project"),
+		ReviewInfoID: int32(id),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		reviewTranscodeError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"transcodes": entities})
+}
+
+type createReviewTranscodeParams struct {
+	Source    *string `json:"source"`
+	CreatedBy *string `json:"created_by"`
+}
+
+// Post queues the transcode of an image sequence of the review data to a proxy movie, which is
+// registered as review data once completed.
+func (h *ReviewTranscode) Post(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p createReviewTranscodeParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.CreateReviewTranscodeParams{
+		Project:      c.Param("project"),
+		ReviewInfoID: int32(id),
+		Source:       p.Source,
+		CreatedBy:    p.CreatedBy,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		reviewTranscodeError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusAccepted, e)
+}
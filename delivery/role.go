@@ -0,0 +1,321 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewRole(
+	uc *usecase.Role,
+) *Role {
+	return &Role{
+		uc:     uc,
+		routes: map[string]*entity.PermissionRequirement{},
+	}
+}
+
+// Role checks the permissions the routes of projects require, and keeps them in a route table
+// from which the permissions of tokens are listed, so that both agree.
+type Role struct {
+	uc     *usecase.Role
+	routes map[string]*entity.PermissionRequirement
+}
+
+func roleError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		forbidden(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// roleAdmin reports whether the requester may manage the roles, which the access check of the
+// routes does not restrict outside of projects and studios.
+func roleAdmin(c *gin.Context) bool {
+	if entity.SkipAuth {
+		return true
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if isAdminStudio(studio) {
+		return true
+	}
+	forbidden(c, fmt.Errorf("%w: roles can only be managed by an admin", entity.ErrForbidden))
+	return false
+}
+
+// bodySets tells whether the JSON object of the request body sets any of the fields. The body
+// is restored for the handler.
+func bodySets(c *gin.Context, fields []string) (bool, error) {
+	if c.Request.Body == nil {
+		return false, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return false, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		// left to the handler to reject
+		return false, nil
+	}
+	for _, field := range fields {
+		if _, ok := object[field]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Handle registers the route of the group behind the middleware of require, and keeps its
+// requirement in the route table.
+func (h *Role) Handle(
+	g *gin.RouterGroup,
+	method string,
+	relativePath string,
+	requirement *entity.PermissionRequirement,
+	handlers ...gin.HandlerFunc,
+) {
+	h.routes[method+" "+path.Join(g.BasePath(), relativePath)] = requirement
+	g.Handle(method, relativePath, append(
+		[]gin.HandlerFunc{h.require(requirement.Permission, requirement.Fields...)}, handlers...,
+	)...)
+}
+
+// Requirement returns the permission the route pattern requires, nil when it requires none.
+func (h *Role) Requirement(method, fullPath string) *entity.PermissionRequirement {
+	return h.routes[method+" "+fullPath]
+}
+
+// require is the middleware of the routes of a project requiring a permission of the
+// authenticated user, from the claims of the token or the API key. When fields are given, the
+// permission is only required by the requests whose JSON body sets any of them, e.g. only
+// approvals among the updates of reviews. Admin studios are not restricted.
+func (h *Role) require(permission entity.Permission, fields ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if entity.SkipAuth {
+			return
+		}
+		name, _ := c.Get("studio")
+		studio, _ := name.(string)
+		if isAdminStudio(studio) {
+			return
+		}
+		if len(fields) != 0 {
+			sets, err := bodySets(c, fields)
+			if err != nil {
+				badRequest(c, err)
+				return
+			}
+			if !sets {
+				return
+			}
+		}
+		if err := h.check(
+			c.Request.Context(), studio, c.Param("project"), requestUser(c), permission,
+		); err != nil {
+			roleError(c, err)
+			return
+		}
+	}
+}
+
+// check checks the user authenticated as the studio has the permission in the project, for
+// the handlers which cannot be registered with Handle, such as the resolvers of GraphQL.
+// Admin studios are not restricted.
+func (h *Role) check(
+	ctx context.Context,
+	studio string,
+	project string,
+	user string,
+	permission entity.Permission,
+) error {
+	if entity.SkipAuth || isAdminStudio(studio) {
+		return nil
+	}
+	return h.uc.CheckPermission(ctx, &entity.CheckPermissionParams{
+		Project:    project,
+		User:       user,
+		Permission: permission,
+	})
+}
+
+func (h *Role) List(c *gin.Context) {
+	if !roleAdmin(c) {
+		return
+	}
+	entities, err := h.uc.List(c.Request.Context())
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"roles": entities})
+}
+
+func (h *Role) Get(c *gin.Context) {
+	if !roleAdmin(c) {
+		return
+	}
+	e, err := h.uc.Get(c.Request.Context(), &entity.GetRoleParams{Name: c.Param("role")})
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateRoleParams struct {
+	Description string              `json:"description"`
+	Permissions []entity.Permission `json:"permissions"`
+	ModifiedBy  string              `json:"modified_by"`
+}
+
+// Put creates the role or replaces its description and permissions.
+func (h *Role) Put(c *gin.Context) {
+	if !roleAdmin(c) {
+		return
+	}
+	var p updateRoleParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateRoleParams{
+		Name:        c.Param("role"),
+		Description: p.Description,
+		Permissions: p.Permissions,
+		ModifiedBy:  p.ModifiedBy,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *Role) Delete(c *gin.Context) {
+	if !roleAdmin(c) {
+		return
+	}
+	if err := h.uc.Delete(
+		c.Request.Context(), &entity.DeleteRoleParams{Name: c.Param("role")},
+	); err != nil {
+		roleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type listUserRolesParams struct {
+	PerPage *int    `form:"per_page"`
+	Page    *int    `form:"page"`
+	Project *string `form:"project"`
+	User    *string `form:"user"`
+}
+
+func (h *Role) ListUsers(c *gin.Context) {
+	if !roleAdmin(c) {
+		return
+	}
+	var p listUserRolesParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	role := c.Param("role")
+	params := &entity.ListUserRolesParams{
+		Role:    &role,
+		Project: p.Project,
+		User:    p.User,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.ListUserRoles(c.Request.Context(), params)
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"user_roles",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
+
+type createUserRoleParams struct {
+	Project      string     `json:"project"`
+	User         string     `json:"user"`
+	ExpiresAtUTC *time.Time `json:"expires_at_utc"`
+	CreatedBy    string     `json:"created_by"`
+}
+
+// PostUser assigns the role to a user in a project.
+func (h *Role) PostUser(c *gin.Context) {
+	if !roleAdmin(c) {
+		return
+	}
+	var p createUserRoleParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateUserRoleParams{
+		Role:         c.Param("role"),
+		Project:      p.Project,
+		User:         p.User,
+		ExpiresAtUTC: p.ExpiresAtUTC,
+		CreatedBy:    p.CreatedBy,
+	}
+	e, err := h.uc.CreateUserRole(c.Request.Context(), params)
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+func (h *Role) DeleteUser(c *gin.Context) {
+	if !roleAdmin(c) {
+		return
+	}
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	if err := h.uc.DeleteUserRole(c.Request.Context(), &entity.DeleteUserRoleParams{
+		Role: c.Param("role"),
+		ID:   id,
+	}); err != nil {
+		roleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
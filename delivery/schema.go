@@ -0,0 +1,225 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewSchema(
+	uc *usecase.Schema,
+) *Schema {
+	return &Schema{
+		uc: uc,
+	}
+}
+
+type Schema struct {
+	uc *usecase.Schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// bindingRules returns the validation rules of a field, which are those of the request body
+// or, when it has none, those of the field with the same name in the entity parameters.
+func bindingRules(f reflect.StructField, rules reflect.Type) string {
+	if tag, ok := f.Tag.Lookup("binding"); ok {
+		return tag
+	}
+	if rules != nil {
+		if rf, ok := rules.FieldByName(f.Name); ok {
+			return rf.Tag.Get("binding")
+		}
+	}
+	return ""
+}
+
+// jsonSchemaOf describes the type t validated with the binding rules. The rules after "dive"
+// apply to the items of slices.
+func jsonSchemaOf(t reflect.Type, rules []string) *entity.RequestSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := &entity.RequestSchema{}
+	var itemRules []string
+	for i, rule := range rules {
+		if rule == "dive" {
+			itemRules = rules[i+1:]
+			rules = rules[:i]
+			break
+		}
+	}
+
+	switch {
+	case t == timeType:
+		s.Type = "string"
+		s.Format = "date-time"
+	case t.Kind() == reflect.String:
+		s.Type = "string"
+	case t.Kind() == reflect.Bool:
+		s.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s.Type = "integer"
+		if t.Kind() >= reflect.Uint {
+			zero := 0.0
+			s.Minimum = &zero
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s.Type = "number"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s.Type = "array"
+		s.Items = jsonSchemaOf(t.Elem(), itemRules)
+	case t.Kind() == reflect.Map:
+		s.Type = "object"
+	case t.Kind() == reflect.Struct:
+		return jsonSchemaOfStruct(t, nil)
+	}
+
+	for _, rule := range rules {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max", "len":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			var lower, upper **int
+			switch s.Type {
+			case "string":
+				lower, upper = &s.MinLength, &s.MaxLength
+			case "array":
+				lower, upper = &s.MinItems, &s.MaxItems
+			case "integer", "number":
+				f := float64(n)
+				if name != "max" {
+					s.Minimum = &f
+				}
+				if name != "min" {
+					s.Maximum = &f
+				}
+				continue
+			default:
+				continue
+			}
+			if name != "max" {
+				*lower = &n
+			}
+			if name != "min" {
+				*upper = &n
+			}
+		case "oneof":
+			s.Enum = strings.Fields(value)
+		case "uuid":
+			s.Format = "uuid"
+		case "email":
+			s.Format = "email"
+		}
+	}
+	return s
+}
+
+// isRequiredField tells whether the validation fails when the field is left out.
+func isRequiredField(f reflect.StructField, rules []string) bool {
+	if f.Type.Kind() == reflect.Pointer {
+		return false
+	}
+	for _, rule := range rules {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return false
+		case "omitempty":
+			return false
+		case "required", "len", "uuid":
+			return true
+		case "min":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonSchemaOfStruct describes the JSON body bound to t, taking the validation rules missing
+// from t from the fields of rules.
+func jsonSchemaOfStruct(t reflect.Type, rules reflect.Type) *entity.RequestSchema {
+	s := &entity.RequestSchema{
+		Type:       "object",
+		Properties: map[string]*entity.RequestSchema{},
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		var fieldRules []string
+		if r := bindingRules(f, rules); r != "" {
+			fieldRules = strings.Split(r, ",")
+		}
+		s.Properties[name] = jsonSchemaOf(f.Type, fieldRules)
+		if isRequiredField(f, fieldRules) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+type getReviewInfoSchemaParams struct {
+	Project *string `form:"project"`
+}
+
+// GetReviewInfo returns the JSON Schema of the body creating a review info. With the
+// "project" parameter, the custom fields and statuses of the project are included.
+func (h *Schema) GetReviewInfo(c *gin.Context) {
+	var p getReviewInfoSchemaParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	ext, err := h.uc.GetReviewInfoExtensions(
+		c.Request.Context(),
+		&entity.GetReviewInfoSchemaParams{
+			Project: p.Project,
+		},
+	)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	s := jsonSchemaOfStruct(
+		reflect.TypeOf(createReviewInfoParams{}),
+		reflect.TypeOf(entity.CreateReviewInfoParams{}),
+	)
+	s.Schema = entity.JSONSchemaDraft
+	s.Title = "reviewInfo"
+	if len(ext.ApprovalStatuses) > 0 {
+		s.Properties["approval_status"].Enum = ext.ApprovalStatuses
+	}
+	if len(ext.WorkStatuses) > 0 {
+		s.Properties["work_status"].Enum = ext.WorkStatuses
+	}
+	if p.Project != nil {
+		s.Properties["metadata"] = entity.MetadataSchema(ext.CustomFields)
+	}
+	c.PureJSON(http.StatusOK, s)
+}
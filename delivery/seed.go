@@ -0,0 +1,42 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewSeed(
+	uc *usecase.Seed,
+) *Seed {
+	return &Seed{
+		uc: uc,
+	}
+}
+
+type Seed struct {
+	uc *usecase.Seed
+}
+
+// Post generates load testing data. The parameters omitted from the body keep the values of
+// entity.DefaultSeedParams.
+func (h *Seed) Post(c *gin.Context) {
+	params := entity.DefaultSeedParams()
+	if err := bindJSON(c, params); err != nil {
+		badRequest(c, err)
+		return
+	}
+	result, err := h.uc.Seed(c.Request.Context(), NewLogger(c.Request), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, result)
+}
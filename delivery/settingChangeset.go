@@ -0,0 +1,319 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewSettingChangeset(
+	uc *usecase.SettingChangeset,
+) *SettingChangeset {
+	return &SettingChangeset{
+		uc: uc,
+	}
+}
+
+type SettingChangeset struct {
+	uc *usecase.SettingChangeset
+}
+
+// settingChangesetSection returns the section of the route, which is authorized by
+// CheckAccessPermission for studios and projects.
+func settingChangesetSection(c *gin.Context) entity.SettingChangesetSection {
+	var s entity.SettingChangesetSection
+	if common := c.Param("common"); common != "" {
+		s.Common = &common
+	}
+	if studio := c.Param("studio"); studio != "" {
+		s.Studio = &studio
+	}
+	if project := c.Param("project"); project != "" {
+		s.Project = &project
+	}
+	return s
+}
+
+func settingChangesetError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+func paramID(c *gin.Context, name string) (int32, error) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int32(id), nil
+}
+
+type listSettingChangesetsParams struct {
+	PerPage *int                           `form:"per_page"`
+	Page    *int                           `form:"page"`
+	Status  *entity.SettingChangesetStatus `form:"status"`
+}
+
+func (h *SettingChangeset) List(c *gin.Context) {
+	var p listSettingChangesetsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListSettingChangesetsParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		Status:                  p.Status,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	res := libs.CreateListResponse(
+		"changesets",
+		entities,
+		c.Request,
+		params,
+		int(total),
+	)
+	c.PureJSON(http.StatusOK, res)
+}
+
+func (h *SettingChangeset) Get(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetSettingChangesetParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		ID:                      id,
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *SettingChangeset) Diff(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetSettingChangesetParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		ID:                      id,
+	}
+	diffs, err := h.uc.Diff(c.Request.Context(), params)
+	if err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"diffs": diffs})
+}
+
+type createSettingChangesetParams struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	CreatedBy   *string `json:"created_by"`
+}
+
+func (h *SettingChangeset) Post(c *gin.Context) {
+	var p createSettingChangesetParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateSettingChangesetParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		Title:                   p.Title,
+		Description:             p.Description,
+		CreatedBy:               p.CreatedBy,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+type deleteSettingChangesetParams struct {
+	ModifiedBy *string `form:"modified_by"`
+}
+
+func (h *SettingChangeset) Delete(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p deleteSettingChangesetParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteSettingChangesetParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		ID:                      id,
+		ModifiedBy:              p.ModifiedBy,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type putSettingChangeParams struct {
+	Group     string                        `json:"group" binding:"required"`
+	Key       string                        `json:"key" binding:"required"`
+	Operation entity.SettingChangeOperation `json:"operation" binding:"required"`
+	Value     interface{}                   `json:"value"`
+	CreatedBy *string                       `json:"created_by"`
+}
+
+// PutChange adds the change of a key to the draft, replacing its previous change of the key.
+func (h *SettingChangeset) PutChange(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p putSettingChangeParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	group, ok := entity.ParsePipelineSettingGroup(p.Group)
+	if !ok || group != entity.Config && group != entity.Preference {
+		badRequest(c, errors.New("group must be config or preference"))
+		return
+	}
+	params := &entity.PutSettingChangeParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		ChangesetID:             id,
+		Group:                   group,
+		Key:                     p.Key,
+		Operation:               p.Operation,
+		Value:                   p.Value,
+		CreatedBy:               p.CreatedBy,
+	}
+	e, err := h.uc.PutChange(c.Request.Context(), params)
+	if err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *SettingChangeset) DeleteChange(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	changeID, err := paramID(c, "changeID")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p deleteSettingChangesetParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteSettingChangeParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		ChangesetID:             id,
+		ChangeID:                changeID,
+		ModifiedBy:              p.ModifiedBy,
+	}
+	if err := h.uc.DeleteChange(c.Request.Context(), params); err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type publishSettingChangesetParams struct {
+	PublishAtUTC *time.Time `json:"publish_at_utc"`
+	PublishedBy  *string    `json:"published_by"`
+}
+
+// Publish publishes the draft now, or at publish_at_utc when it is in the future.
+func (h *SettingChangeset) Publish(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p publishSettingChangesetParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.PublishSettingChangesetParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		ID:                      id,
+		PublishAtUTC:            p.PublishAtUTC,
+		PublishedBy:             p.PublishedBy,
+	}
+	e, err := h.uc.Publish(c.Request.Context(), params)
+	if err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type rollbackSettingChangesetParams struct {
+	RolledBackBy *string `json:"rolled_back_by"`
+}
+
+func (h *SettingChangeset) Rollback(c *gin.Context) {
+	id, err := paramID(c, "id")
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p rollbackSettingChangesetParams
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &p); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	params := &entity.RollbackSettingChangesetParams{
+		SettingChangesetSection: settingChangesetSection(c),
+		ID:                      id,
+		RolledBackBy:            p.RolledBackBy,
+	}
+	e, err := h.uc.Rollback(c.Request.Context(), params)
+	if err != nil {
+		settingChangesetError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
@@ -0,0 +1,45 @@
+package delivery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxStaleness parses the max_staleness query parameter, the age of the oldest cached data
+// the caller accepts, as a duration like "90s" or a number of seconds. It returns nil when
+// the parameter is absent, and 0 makes the caller bypass the caches.
+func MaxStaleness(c *gin.Context) (*time.Duration, error) {
+	v := strings.TrimSpace(c.Query("max_staleness"))
+	if v == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(v)
+		if convErr != nil {
+			return nil, fmt.Errorf("invalid max_staleness %q: %w", v, err)
+		}
+		d = time.Duration(seconds) * time.Second
+	}
+	if d < 0 {
+		return nil, fmt.Errorf("invalid max_staleness %q: must not be negative", v)
+	}
+	return &d, nil
+}
+
+// CacheControl lets shared caches keep a response for maxAge, or for the max_staleness of the
+// caller when it is shorter.
+func CacheControl(c *gin.Context, maxAge time.Duration, maxStaleness *time.Duration) {
+	if maxStaleness != nil && *maxStaleness < maxAge {
+		maxAge = *maxStaleness
+	}
+	if maxAge < time.Second {
+		c.Header("Cache-Control", "no-cache")
+		return
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge/time.Second)))
+}
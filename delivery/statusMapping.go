@@ -0,0 +1,119 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewStatusMapping(
+	uc *usecase.StatusMapping,
+) *StatusMapping {
+	return &StatusMapping{
+		uc: uc,
+	}
+}
+
+type StatusMapping struct {
+	uc *usecase.StatusMapping
+}
+
+func statusMappingError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+func (h *StatusMapping) List(c *gin.Context) {
+	params := &entity.ListStatusMappingsParams{
+		Project: c.Param("project"),
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		statusMappingError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"status_mappings": entities})
+}
+
+type updateStatusMappingParams struct {
+	Kind        string  `json:"kind" binding:"required"`
+	LegacyValue string  `json:"legacy_value" binding:"required"`
+	Value       string  `json:"value" binding:"required"`
+	ModifiedBy  *string `json:"modified_by"`
+}
+
+// Update maps the legacy status `legacy_value` of `kind`, "approval" or "work", to `value`,
+// replacing its previous mapping.
+func (h *StatusMapping) Update(c *gin.Context) {
+	var p updateStatusMappingParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateStatusMappingParams{
+		Project:     c.Param("project"),
+		Kind:        p.Kind,
+		LegacyValue: p.LegacyValue,
+		Value:       p.Value,
+		ModifiedBy:  p.ModifiedBy,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		statusMappingError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *StatusMapping) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteStatusMappingParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		statusMappingError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type backfillStatusMappingsParams struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// Backfill rewrites the legacy statuses stored in the reviews of the project to their mapped
+// values. A dry run responds the number of reviews to rewrite without rewriting them.
+func (h *StatusMapping) Backfill(c *gin.Context) {
+	var p backfillStatusMappingsParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.BackfillStatusMappingsParams{
+		Project: c.Param("project"),
+		DryRun:  p.DryRun,
+	}
+	e, err := h.uc.Backfill(c.Request.Context(), params)
+	if err != nil {
+		statusMappingError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
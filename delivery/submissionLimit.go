@@ -0,0 +1,174 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewSubmissionLimit(
+	uc *usecase.SubmissionLimit,
+) *SubmissionLimit {
+	return &SubmissionLimit{
+		uc: uc,
+	}
+}
+
+type SubmissionLimit struct {
+	uc *usecase.SubmissionLimit
+}
+
+func submissionLimitError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+// submissionRateLimited responds 429 with a Retry-After header when err is the submission
+// rate limit of an asset, and reports whether it did.
+func submissionRateLimited(c *gin.Context, err error) bool {
+	var limitErr *entity.SubmissionRateLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+	log.Println("ERROR:", err)
+	retryAfter := int(math.Ceil(limitErr.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	body := errorBody(http.StatusTooManyRequests, err)
+	body["max_submissions"] = limitErr.MaxSubmissions
+	body["window_minutes"] = limitErr.WindowMinutes
+	body["retry_after_seconds"] = retryAfter
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, body)
+	return true
+}
+
+func (h *SubmissionLimit) Get(c *gin.Context) {
+	params := &entity.GetSubmissionLimitParams{
+		Project: c.Param("project"),
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		submissionLimitError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateSubmissionLimitParams struct {
+	MaxSubmissions *int32  `json:"max_submissions" binding:"required"`
+	WindowMinutes  *int32  `json:"window_minutes" binding:"required"`
+	ModifiedBy     *string `json:"modified_by"`
+}
+
+func (h *SubmissionLimit) Update(c *gin.Context) {
+	var p updateSubmissionLimitParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateSubmissionLimitParams{
+		Project:        c.Param("project"),
+		MaxSubmissions: *p.MaxSubmissions,
+		WindowMinutes:  *p.WindowMinutes,
+		ModifiedBy:     p.ModifiedBy,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		submissionLimitError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *SubmissionLimit) Delete(c *gin.Context) {
+	params := &entity.DeleteSubmissionLimitParams{
+		Project:    c.Param("project"),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		submissionLimitError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type createSubmissionLimitOverrideParams struct {
+	Asset        *string    `json:"asset"`
+	Reason       string     `json:"reason" binding:"required"`
+	ExpiresAtUTC *time.Time `json:"expires_at_utc" binding:"required"`
+	CreatedBy    *string    `json:"created_by"`
+}
+
+// CreateOverride lifts the submission limit of the project, or of the assets of `asset`,
+// until `expires_at_utc` for a bulk migration. It is restricted to admins.
+func (h *SubmissionLimit) CreateOverride(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf(
+			"%w: submission limits can only be overridden by admins", entity.ErrForbidden,
+		))
+		return
+	}
+	var p createSubmissionLimitOverrideParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateSubmissionLimitOverrideParams{
+		Project:      c.Param("project"),
+		Asset:        p.Asset,
+		Reason:       p.Reason,
+		ExpiresAtUTC: *p.ExpiresAtUTC,
+		CreatedBy:    p.CreatedBy,
+	}
+	e, err := h.uc.CreateOverride(c.Request.Context(), params)
+	if err != nil {
+		submissionLimitError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusCreated, e)
+}
+
+func (h *SubmissionLimit) DeleteOverride(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf(
+			"%w: submission limits can only be overridden by admins", entity.ErrForbidden,
+		))
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteSubmissionLimitOverrideParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.DeleteOverride(c.Request.Context(), params); err != nil {
+		submissionLimitError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
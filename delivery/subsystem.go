@@ -0,0 +1,54 @@
+package delivery
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewSubsystems(
+	uc *usecase.Subsystems,
+	probeInterval time.Duration,
+) *Subsystems {
+	return &Subsystems{
+		uc:            uc,
+		probeInterval: probeInterval,
+	}
+}
+
+type Subsystems struct {
+	uc            *usecase.Subsystems
+	probeInterval time.Duration
+}
+
+// Require serves the routes of an optional subsystem only while it is up, and responds 503 with
+// a Retry-After header of the probe interval otherwise, so that the routes come and go with
+// the subsystem without restarting the API.
+func (h *Subsystems) Require(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := h.uc.Check(name)
+		if err == nil {
+			return
+		}
+		log.Println("ERROR:", err)
+		c.Header("Retry-After", strconv.Itoa(int(h.probeInterval.Seconds())))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, errorBody(http.StatusServiceUnavailable, err))
+	}
+}
+
+// Health returns the body of the health checks: "ok", or "degraded" while an optional
+// subsystem is not up, with the states of the subsystems.
+func (h *Subsystems) Health() gin.H {
+	status := "ok"
+	if h.uc.Degraded() {
+		status = "degraded"
+	}
+	return gin.H{
+		"status":     status,
+		"subsystems": h.uc.List(),
+	}
+}
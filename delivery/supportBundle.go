@@ -0,0 +1,147 @@
+package delivery
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultSupportBundleWindow is the window of the support bundles without `window`.
+const defaultSupportBundleWindow = time.Hour
+
+func NewSupportBundle(
+	uc *usecase.SupportBundle,
+) *SupportBundle {
+	return &SupportBundle{
+		uc: uc,
+	}
+}
+
+type SupportBundle struct {
+	uc *usecase.SupportBundle
+}
+
+type createSupportBundleParams struct {
+	Project *string `form:"project"`
+	Window  *string `form:"window"`
+}
+
+// Create downloads a zip archive of the recent errors and slow queries of the instance, its
+// redacted configuration and the health of its dependencies, for the support team. `window`
+// is a duration like "1h" or a number of seconds, and `project` keeps only the logs and the
+// queries mentioning the project. It is restricted to admins.
+func (h *SupportBundle) Create(c *gin.Context) {
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		forbidden(c, fmt.Errorf("%w: support bundles can only be made by admins", entity.ErrForbidden))
+		return
+	}
+	var p createSupportBundleParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	window := defaultSupportBundleWindow
+	if p.Window != nil {
+		v := strings.TrimSpace(*p.Window)
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			seconds, convErr := strconv.Atoi(v)
+			if convErr != nil {
+				badRequest(c, fmt.Errorf("%w: invalid window %q", entity.ErrBadRequest, v))
+				return
+			}
+			d = time.Duration(seconds) * time.Second
+		}
+		window = d
+	}
+	params := &entity.CreateSupportBundleParams{
+		Project:       p.Project,
+		WindowSeconds: int(window / time.Second),
+		CreatedBy:     studio,
+	}
+	bundle, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	scope := "all"
+	if bundle.Project != nil {
+		scope = *bundle.Project
+	}
+	fileName := fmt.Sprintf(
+		"support_bundle_%s_%s.zip", scope, bundle.GeneratedAtUTC.Format("20060102T150405Z"),
+	)
+	// The archive is small enough to be written first, so that its errors are still reported.
+	var buf bytes.Buffer
+	if err := writeSupportBundle(&buf, bundle); err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", "attachment;filename="+fileName)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// writeSupportBundle writes the bundle as a zip archive of a manifest, the errors as a log
+// file and the other parts as JSON files.
+func writeSupportBundle(w io.Writer, bundle *entity.SupportBundle) error {
+	zw := zip.NewWriter(w)
+	manifest := gin.H{
+		"project":            bundle.Project,
+		"window_seconds":     bundle.WindowSeconds,
+		"buffered_since_utc": bundle.BufferedSinceUTC,
+		"generated_at_utc":   bundle.GeneratedAtUTC,
+		"generated_by":       bundle.GeneratedBy,
+		"errors":             len(bundle.Errors),
+		"slow_queries":       len(bundle.SlowQueries),
+	}
+	parts := []struct {
+		name string
+		v    interface{}
+	}{
+		{"manifest.json", manifest},
+		{"slow_queries.json", bundle.SlowQueries},
+		{"config.json", bundle.Config},
+		{"dependencies.json", bundle.Dependencies},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(part.v); err != nil {
+			return err
+		}
+	}
+	f, err := zw.Create("errors.log")
+	if err != nil {
+		return err
+	}
+	for _, e := range bundle.Errors {
+		line := e.LoggedAtUTC.Format(time.RFC3339Nano) + " " + e.Message + "\n"
+		if _, err := io.WriteString(f, line); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,219 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// TagFilters splits the comma-separated `tags` query parameter used to filter listings.
+func TagFilters(c *gin.Context) []string {
+	return splitCSV(c.Query("tags"))
+}
+
+func NewTag(
+	uc *usecase.Tag,
+) *Tag {
+	return &Tag{
+		uc: uc,
+	}
+}
+
+type Tag struct {
+	uc *usecase.Tag
+}
+
+type listTagsParams struct {
+	Query   *string `form:"q"`
+	PerPage *int    `form:"per_page"`
+	Page    *int    `form:"page"`
+}
+
+// List lists the tags of a project. The "q" parameter is used for autocompletion and only
+// returns the tags starting with it.
+func (h *Tag) List(c *gin.Context) {
+	var p listTagsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListTagsParams{
+		Project: c.Param("project"),
+		Query:   p.Query,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	res := libs.CreateListResponse("tags", entities, c.Request, params, total)
+	c.PureJSON(http.StatusOK, res)
+}
+
+type tagParams struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (h *Tag) Post(c *gin.Context) {
+	var p tagParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateTagParams{
+		Project:   c.Param("project"),
+		Name:      p.Name,
+		CreatedBy: nil,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *Tag) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	var p tagParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateTagParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		Name:       p.Name,
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) || errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *Tag) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteTagParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type tagTargetParams struct {
+	TargetType string `form:"target_type" json:"target_type" binding:"required"`
+	Target     string `form:"target" json:"target" binding:"required"`
+}
+
+func (h *Tag) GetTarget(c *gin.Context) {
+	var p tagTargetParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetTagTargetParams{
+		Project:    c.Param("project"),
+		TargetType: p.TargetType,
+		Target:     p.Target,
+	}
+	e, err := h.uc.GetTarget(c.Request.Context(), params)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type attachTagsParams struct {
+	tagTargetParams
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// Attach attaches tags to an asset, shot or review, creating the tags which do not exist yet.
+func (h *Tag) Attach(c *gin.Context) {
+	var p attachTagsParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateTagTargetParams{
+		Project:    c.Param("project"),
+		TargetType: p.TargetType,
+		Target:     p.Target,
+		Tags:       p.Tags,
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.Attach(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) || errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+// Detach detaches the comma-separated "tags" from an asset, shot or review.
+func (h *Tag) Detach(c *gin.Context) {
+	var p tagTargetParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateTagTargetParams{
+		Project:    c.Param("project"),
+		TargetType: p.TargetType,
+		Target:     p.Target,
+		Tags:       TagFilters(c),
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.Detach(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) || errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
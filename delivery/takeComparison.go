@@ -0,0 +1,104 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewTakeComparison(
+	uc *usecase.TakeComparison,
+) *TakeComparison {
+	return &TakeComparison{
+		uc: uc,
+	}
+}
+
+type TakeComparison struct {
+	uc *usecase.TakeComparison
+}
+
+type contactSheetParams struct {
+	Before int32 `form:"before" binding:"required"`
+	After  int32 `form:"after" binding:"required"`
+	Frames *int  `form:"frames"`
+	Width  *int  `form:"width"`
+}
+
+// GetContactSheet responds the before/after contact sheet of two takes as a PNG image. It is
+// cached by the content of the takes, and its key is the ETag.
+func (h *TakeComparison) GetContactSheet(c *gin.Context) {
+	var p contactSheetParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.GetContactSheetParams{
+		Project: c.Param("project"),
+		Before:  p.Before,
+		After:   p.After,
+		Frames:  4,
+		Width:   320,
+	}
+	if p.Frames != nil {
+		params.Frames = *p.Frames
+	}
+	if p.Width != nil {
+		params.Width = *p.Width
+	}
+	sheet, err := h.uc.GetContactSheet(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) || errors.Is(err, os.ErrNotExist) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.Header("ETag", `"`+sheet.Key+`"`)
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.File(sheet.Path)
+}
+
+type compareTakesParams struct {
+	From string `form:"from" binding:"required"`
+	To   string `form:"to" binding:"required"`
+}
+
+// CompareTakes responds the diff of two takes of a phase of an asset.
+func (h *TakeComparison) CompareTakes(c *gin.Context) {
+	var p compareTakesParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	comparison, err := h.uc.CompareTakes(c.Request.Context(), &entity.CompareTakesParams{
+		Project:  c.Param("project"),
+		Asset:    c.Param("asset"),
+		Relation: c.Param("relation"),
+		Phase:    c.Param("phase"),
+		From:     p.From,
+		To:       p.To,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			notFound(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, comparison)
+}
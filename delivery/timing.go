@@ -0,0 +1,97 @@
+package delivery
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// DebugTiming is the middleware collecting the timing breakdown of the requests of admins
+// sending a true X-Debug-Timing header. The response is held until the handlers return, so
+// that the header reports the serialization of the body too.
+func DebugTiming(c *gin.Context) {
+	enabled, _ := strconv.ParseBool(c.GetHeader(entity.DebugTimingHeader))
+	if !enabled {
+		c.Next()
+		return
+	}
+	name, _ := c.Get("studio")
+	studio, _ := name.(string)
+	if !entity.SkipAuth && !isAdminStudio(studio) {
+		c.Next()
+		return
+	}
+
+	timings := entity.NewTimings()
+	c.Request = c.Request.WithContext(entity.WithTimings(c.Request.Context(), timings))
+	w := &timingWriter{
+		ResponseWriter: c.Writer,
+		status:         http.StatusOK,
+	}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+	if s := timings.String(); s != "" {
+		c.Header(entity.DebugTimingHeader, s)
+	}
+	w.flush()
+}
+
+// timingWriter holds the status and the body of a response until flush, for its headers to be
+// set after the handlers.
+type timingWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *timingWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *timingWriter) Status() int {
+	return w.status
+}
+
+func (w *timingWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timingWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op, as the body is held until the handlers return.
+func (w *timingWriter) Flush() {}
+
+func (w *timingWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	// responses without body are written by gin after the handlers
+	if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,324 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// UnknownFields sets the handling of the unknown JSON fields of the request bodies per route,
+// and adds the warnings of the requests to their JSON responses.
+type UnknownFields struct {
+	mode string
+	// routes overrides the mode of routes, keyed by "METHOD /api/route/:pattern".
+	routes map[string]string
+}
+
+// NewUnknownFieldsFromEnv returns the handling configured by PPI_UNKNOWN_FIELDS, one of
+// "warn", the default, "reject" and "ignore", and PPI_UNKNOWN_FIELDS_ROUTES, which overrides
+// it per route with a comma separated list like
+// "POST /api/projects/:project/reviews=reject,PUT /api/projects/:project/reviews/:id=reject".
+func NewUnknownFieldsFromEnv() (*UnknownFields, error) {
+	h := &UnknownFields{
+		mode:   entity.UnknownFieldsWarn,
+		routes: map[string]string{},
+	}
+	if v := os.Getenv("PPI_UNKNOWN_FIELDS"); v != "" {
+		if !validUnknownFieldsMode(v) {
+			return nil, fmt.Errorf("invalid PPI_UNKNOWN_FIELDS: %q", v)
+		}
+		h.mode = v
+	}
+	if v := os.Getenv("PPI_UNKNOWN_FIELDS_ROUTES"); v != "" {
+		for _, pair := range strings.Split(v, ",") {
+			route, mode, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !validUnknownFieldsMode(mode) || len(strings.Fields(route)) != 2 {
+				return nil, fmt.Errorf("invalid PPI_UNKNOWN_FIELDS_ROUTES: %q", pair)
+			}
+			h.routes[strings.Join(strings.Fields(route), " ")] = mode
+		}
+	}
+	return h, nil
+}
+
+func validUnknownFieldsMode(mode string) bool {
+	return mode == entity.UnknownFieldsIgnore ||
+		mode == entity.UnknownFieldsWarn ||
+		mode == entity.UnknownFieldsReject
+}
+
+// warningsWriter holds back the JSON response of a request with warnings, so that they are
+// added to it.
+type warningsWriter struct {
+	gin.ResponseWriter
+	c   *gin.Context
+	buf *bytes.Buffer
+}
+
+func (w *warningsWriter) holding() bool {
+	if w.buf != nil {
+		return true
+	}
+	if _, ok := w.c.Get(entity.PayloadWarningsKey); !ok {
+		return false
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		return false
+	}
+	w.buf = &bytes.Buffer{}
+	return true
+}
+
+func (w *warningsWriter) Write(data []byte) (int, error) {
+	if w.holding() {
+		return w.buf.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *warningsWriter) WriteString(s string) (int, error) {
+	if w.holding() {
+		return w.buf.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Track is the middleware setting the unknown fields mode of the route, which is read when the
+// body is bound, and adding the warnings of the request to its response when it is a JSON
+// object.
+func (h *UnknownFields) Track(c *gin.Context) {
+	mode := h.mode
+	if m, ok := h.routes[c.Request.Method+" "+c.FullPath()]; ok {
+		mode = m
+	}
+	c.Set(entity.UnknownFieldsModeKey, mode)
+	if mode != entity.UnknownFieldsWarn {
+		c.Next()
+		return
+	}
+
+	w := &warningsWriter{ResponseWriter: c.Writer, c: c}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+	if w.buf == nil {
+		return
+	}
+	body := w.buf.Bytes()
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err == nil && object != nil {
+		warnings, _ := c.Get(entity.PayloadWarningsKey)
+		if data, err := json.Marshal(warnings); err == nil {
+			object["warnings"] = data
+			if merged, err := json.Marshal(object); err == nil {
+				body = merged
+			}
+		}
+	}
+	w.ResponseWriter.Write(body)
+}
+
+// bindJSON binds the JSON body of the request like ShouldBindJSON, handling the fields
+// unknown to obj in the mode of the route.
+func bindJSON(c *gin.Context, obj interface{}) error {
+	return bindChecked(c, obj, binding.JSON)
+}
+
+// bindBody binds the body of the request like ShouldBind, handling the fields of JSON bodies
+// unknown to obj in the mode of the route.
+func bindBody(c *gin.Context, obj interface{}) error {
+	return bindChecked(c, obj, binding.Default(c.Request.Method, c.ContentType()))
+}
+
+func bindChecked(c *gin.Context, obj interface{}, b binding.Binding) error {
+	mode := c.GetString(entity.UnknownFieldsModeKey)
+	if mode == "" {
+		mode = entity.UnknownFieldsWarn
+	}
+	if b != binding.JSON || mode == entity.UnknownFieldsIgnore || c.Request.Body == nil {
+		return c.ShouldBindWith(obj, b)
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	warnings := unknownJSONFields(value, reflect.TypeOf(obj), "")
+	if len(warnings) == 0 {
+		return nil
+	}
+	if mode == entity.UnknownFieldsReject {
+		fields := make([]*entity.FieldError, len(warnings))
+		for i, w := range warnings {
+			fields[i] = &entity.FieldError{
+				Code:    w.Code,
+				Field:   w.Field,
+				Message: w.Message,
+			}
+		}
+		return &entity.InvalidFieldsError{Fields: fields}
+	}
+	for _, w := range warnings {
+		log.Printf("WARN: %s %s: %s", c.Request.Method, c.FullPath(), w.Message)
+	}
+	if previous, ok := c.Get(entity.PayloadWarningsKey); ok {
+		warnings = append(previous.([]*entity.PayloadWarning), warnings...)
+	}
+	c.Set(entity.PayloadWarningsKey, warnings)
+	return nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// jsonFieldNames returns the fields of a struct by JSON name, those of the embedded structs
+// included, as encoding/json decodes them.
+func jsonFieldNames(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				jsonFieldNames(ft, fields)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+}
+
+// unknownJSONFields returns the warnings of the fields of a decoded JSON value which the type
+// it was bound to does not know. The types decoding themselves are not inspected.
+func unknownJSONFields(value interface{}, t reflect.Type, path string) []*entity.PayloadWarning {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) ||
+		reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+	var warnings []*entity.PayloadWarning
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields := map[string]reflect.Type{}
+		jsonFieldNames(t, fields)
+		for key, v := range object {
+			ft, ok := fields[key]
+			if !ok {
+				// encoding/json matches the names case-insensitively
+				for name, typ := range fields {
+					if strings.EqualFold(name, key) {
+						ft, ok = typ, true
+						break
+					}
+				}
+			}
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			if !ok {
+				w := &entity.PayloadWarning{
+					Code:    entity.PayloadWarningUnknownField,
+					Field:   field,
+					Message: fmt.Sprintf("unknown field %q", field),
+				}
+				if s := closestFieldName(key, fields); s != "" {
+					w.Suggestion = s
+					w.Message += fmt.Sprintf(", did you mean %q?", s)
+				}
+				warnings = append(warnings, w)
+				continue
+			}
+			warnings = append(warnings, unknownJSONFields(v, ft, field)...)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, v := range items {
+			warnings = append(warnings, unknownJSONFields(v, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for key, v := range object {
+			warnings = append(warnings, unknownJSONFields(v, t.Elem(), path+"."+key)...)
+		}
+	}
+	return warnings
+}
+
+// closestFieldName returns the known field the unknown one is likely a typo of, at most two
+// edits away, or "".
+func closestFieldName(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		d := editDistance(strings.ToLower(key), strings.ToLower(name))
+		if d < bestDistance || d == bestDistance && best != "" && name < best {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance of two strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,111 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewWatcher(
+	uc *usecase.Watcher,
+) *Watcher {
+	return &Watcher{
+		uc: uc,
+	}
+}
+
+type Watcher struct {
+	uc *usecase.Watcher
+}
+
+func watcherError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+type listWatchersParams struct {
+	TargetType *string `form:"target_type"`
+	Target     *string `form:"target"`
+	User       *string `form:"user"`
+}
+
+// List lists the watchers of a target, or the targets watched by a user.
+func (h *Watcher) List(c *gin.Context) {
+	var p listWatchersParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListWatchersParams{
+		Project:    c.Param("project"),
+		TargetType: p.TargetType,
+		Target:     p.Target,
+		User:       p.User,
+	}
+	entities, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		watcherError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"watchers": entities})
+}
+
+type createWatcherParams struct {
+	TargetType  string  `json:"target_type" binding:"required"`
+	Target      string  `json:"target" binding:"required"`
+	User        string  `json:"user" binding:"required"`
+	MailAddress *string `json:"mail_address"`
+	CreatedBy   *string `json:"created_by"`
+}
+
+// Post makes a user watch an asset or shot. Watching it again updates the mail address.
+func (h *Watcher) Post(c *gin.Context) {
+	var p createWatcherParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.CreateWatcherParams{
+		Project:     c.Param("project"),
+		TargetType:  p.TargetType,
+		Target:      p.Target,
+		User:        p.User,
+		MailAddress: p.MailAddress,
+		CreatedBy:   p.CreatedBy,
+	}
+	e, err := h.uc.Create(c.Request.Context(), params)
+	if err != nil {
+		watcherError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *Watcher) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.DeleteWatcherParams{
+		Project:    c.Param("project"),
+		ID:         int32(id),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		watcherError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewWorkCalendar(
+	uc *usecase.WorkCalendar,
+) *WorkCalendar {
+	return &WorkCalendar{
+		uc: uc,
+	}
+}
+
+type WorkCalendar struct {
+	uc *usecase.WorkCalendar
+}
+
+func workCalendarError(c *gin.Context, err error) {
+	if errors.Is(err, entity.ErrBadRequest) {
+		badRequest(c, err)
+		return
+	}
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		notFound(c, err)
+		return
+	}
+	internalServerError(c, err)
+}
+
+func (h *WorkCalendar) List(c *gin.Context) {
+	entities, err := h.uc.List(c.Request.Context())
+	if err != nil {
+		workCalendarError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, gin.H{"calendars": entities})
+}
+
+func (h *WorkCalendar) Get(c *gin.Context) {
+	params := &entity.GetWorkCalendarParams{
+		Studio: c.Param("studio"),
+	}
+	e, err := h.uc.Get(c.Request.Context(), params)
+	if err != nil {
+		workCalendarError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+type updateWorkCalendarParams struct {
+	TimeZone string              `json:"time_zone" binding:"required"`
+	Weekends []int               `json:"weekends"`
+	Holidays []string            `json:"holidays"`
+	Shifts   []*entity.WorkShift `json:"shifts" binding:"required"`
+}
+
+// Update creates or replaces the work calendar of a studio.
+func (h *WorkCalendar) Update(c *gin.Context) {
+	var p updateWorkCalendarParams
+	if err := bindJSON(c, &p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.UpdateWorkCalendarParams{
+		Studio:     c.Param("studio"),
+		TimeZone:   p.TimeZone,
+		Weekends:   p.Weekends,
+		Holidays:   p.Holidays,
+		Shifts:     p.Shifts,
+		ModifiedBy: nil,
+	}
+	e, err := h.uc.Update(c.Request.Context(), params)
+	if err != nil {
+		workCalendarError(c, err)
+		return
+	}
+	c.PureJSON(http.StatusOK, e)
+}
+
+func (h *WorkCalendar) Delete(c *gin.Context) {
+	params := &entity.DeleteWorkCalendarParams{
+		Studio:     c.Param("studio"),
+		ModifiedBy: nil,
+	}
+	if err := h.uc.Delete(c.Request.Context(), params); err != nil {
+		workCalendarError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
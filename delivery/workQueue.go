@@ -0,0 +1,60 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewWorkQueue(
+	uc *usecase.WorkQueue,
+) *WorkQueue {
+	return &WorkQueue{
+		uc: uc,
+	}
+}
+
+type WorkQueue struct {
+	uc *usecase.WorkQueue
+}
+
+type listWorkItemsParams struct {
+	PerPage *int    `form:"per_page"`
+	Page    *int    `form:"page"`
+	Project *string `form:"project"`
+	Kind    *string `form:"kind"`
+}
+
+// List lists the work queue of the authenticated user across the projects,
+// the most urgent first.
+func (h *WorkQueue) List(c *gin.Context) {
+	var p listWorkItemsParams
+	if err := c.ShouldBindQuery(&p); err != nil {
+		badRequest(c, err)
+		return
+	}
+	params := &entity.ListWorkItemsParams{
+		User:    requestUser(c),
+		Project: p.Project,
+		Kind:    p.Kind,
+		BaseListParams: &entity.BaseListParams{
+			PerPage: p.PerPage,
+			Page:    p.Page,
+		},
+	}
+	entities, total, err := h.uc.List(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrBadRequest) {
+			badRequest(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+	res := libs.CreateListResponse("items", entities, c.Request, params, total)
+	c.PureJSON(http.StatusOK, res)
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+type ActivityType string
+
+const (
+	ActivityReviewSubmitted     ActivityType = "reviewSubmitted"
+	ActivityReviewStatusChanged ActivityType = "reviewStatusChanged"
+	ActivityPublished           ActivityType = "published"
+	ActivityDirectoryCreated    ActivityType = "directoryCreated"
+	ActivityDirectoryDeleted    ActivityType = "directoryDeleted"
+	ActivitySettingChanged      ActivityType = "settingChanged"
+)
+
+var ActivityTypes = []ActivityType{
+	ActivityReviewSubmitted,
+	ActivityReviewStatusChanged,
+	ActivityPublished,
+	ActivityDirectoryCreated,
+	ActivityDirectoryDeleted,
+	ActivitySettingChanged,
+}
+
+// Activity is an entry of the activity feed of a project.
+//
+// Target depends on the type: the take path of reviews (root/group/relation/phase/take), the
+// revision path of publishes, the path of directories and the key of settings. Detail holds
+// the new status, the publish operation and event, or the section of the setting.
+type Activity struct {
+	Type          ActivityType `json:"type"`
+	OccurredAtUTC time.Time    `json:"occurred_at_utc"`
+	Actor         string       `json:"actor"`
+	Computer      string       `json:"computer"`
+	Target        string       `json:"target"`
+	Detail        string       `json:"detail"`
+	SourceID      int32        `json:"source_id"`
+}
+
+type ListActivitiesParams struct {
+	Project string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	After   time.Time      `binding:"required"`
+	Types   []ActivityType `binding:"omitempty,dive,oneof=reviewSubmitted reviewStatusChanged published directoryCreated directoryDeleted settingChanged"`
+	Actor   *string        `binding:"omitempty,min=1,max=100"`
+	*BaseListParams
+}
@@ -0,0 +1,44 @@
+package entity
+
+// AnonymizeParams configure the scrubbing of the personal data of databases cloned from
+// production for demo and training environments. The same Salt always gives the same fakes,
+// so that MySQL and MongoDB, or databases anonymized separately, still agree with each other.
+type AnonymizeParams struct {
+	Salt      string `json:"-" binding:"min=16"`
+	BatchSize int    `json:"batch_size" binding:"min=1,max=10000"`
+	// SkipMongo leaves the documents of MongoDB untouched.
+	SkipMongo bool `json:"skip_mongo"`
+	// DryRun counts the rows and documents to be rewritten without writing them.
+	DryRun bool `json:"dry_run"`
+}
+
+// DefaultAnonymizeParams returns the parameters but the salt, which has no default.
+func DefaultAnonymizeParams() *AnonymizeParams {
+	return &AnonymizeParams{
+		BatchSize: 500,
+	}
+}
+
+// AnonymizeColumn is a column of personal data. Kind is the kind of data of the column, and
+// is empty for the JSON columns rewritten by the keys of their values only.
+type AnonymizeColumn struct {
+	Name string
+	Kind string
+	JSON bool
+}
+
+// AnonymizeTable is a table having columns of personal data, whose rows are identified by
+// an integer ID.
+type AnonymizeTable struct {
+	Name    string
+	Columns []*AnonymizeColumn
+}
+
+type AnonymizeResult struct {
+	// Users and Computers are the numbers of distinct names found, also scrubbed from paths.
+	Users     int              `json:"users"`
+	Computers int              `json:"computers"`
+	Rows      map[string]int64 `json:"rows"`
+	Documents map[string]int64 `json:"documents"`
+	DryRun    bool             `json:"dry_run"`
+}
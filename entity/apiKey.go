@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyHeader is the header the service accounts of pipeline scripts authenticate with, in
+// place of the tokens of the users.
+const APIKeyHeader = "X-Api-Key"
+
+// APIKeyPrefix starts the API keys, which tells them from tokens in logs and secret scanners.
+const APIKeyPrefix = "ppk_"
+
+// APIKeyContextKey holds the ID of the API key a request was authenticated with.
+const APIKeyContextKey = "apiKey"
+
+// APIKeyRoute is a route an API key may call. Method is "*" for any method, and Path is a
+// route pattern as registered, e.g. "/api/projects/:project/reviews", or a prefix of route
+// patterns ending with "*".
+type APIKeyRoute struct {
+	Method string `json:"method" binding:"oneof=* GET POST PUT PATCH DELETE"`
+	Path   string `json:"path" binding:"min=5,max=200,startswith=/api/"`
+}
+
+// Matches reports whether the key may call the route pattern path with method.
+func (r *APIKeyRoute) Matches(method, path string) bool {
+	if r.Method != "*" && r.Method != method {
+		return false
+	}
+	if strings.HasSuffix(r.Path, "*") {
+		return strings.HasPrefix(path, strings.TrimSuffix(r.Path, "*"))
+	}
+	return r.Path == path
+}
+
+// APIKey authenticates a service account as its studio, restricted to its routes and, when
+// Projects is set, to the routes of those projects. The key itself is only known when it is
+// created or rotated, then only its hash is stored.
+type APIKey struct {
+	Name          string         `json:"name"`
+	Studio        string         `json:"studio"`
+	Projects      []string       `json:"projects"`
+	Routes        []*APIKeyRoute `json:"routes"`
+	Prefix        string         `json:"prefix"`
+	Key           string         `json:"key,omitempty"`
+	ExpiresAtUTC  *time.Time     `json:"expires_at_utc"`
+	LastUsedAtUTC *time.Time     `json:"last_used_at_utc"`
+	RotatedAtUTC  *time.Time     `json:"rotated_at_utc"`
+	RevokedAtUTC  *time.Time     `json:"revoked_at_utc"`
+	CreatedAtUTC  time.Time      `json:"created_at_utc"`
+	ModifiedAtUTC time.Time      `json:"modified_at_utc"`
+	ModifiedBy    string         `json:"modified_by"`
+	CreatedBy     string         `json:"created_by"`
+	ID            int32          `json:"id"`
+}
+
+// ListAPIKeysParams lists the API keys of a studio or of all of them, the revoked keys
+// included when Revoked is true.
+type ListAPIKeysParams struct {
+	Studio  *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Revoked bool
+}
+
+type GetAPIKeyParams struct {
+	ID int32 `binding:"min=1"`
+}
+
+type CreateAPIKeyParams struct {
+	Name         string         `binding:"min=1,max=100"`
+	Studio       string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Projects     []string       `binding:"dive,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Routes       []*APIKeyRoute `binding:"min=1,max=100,dive,required"`
+	ExpiresAtUTC *time.Time
+	CreatedBy    string `binding:"max=100"`
+}
+
+// RotateAPIKeyParams replaces the key of an API key, the previous key being rejected at once.
+type RotateAPIKeyParams struct {
+	ID         int32  `binding:"min=1"`
+	ModifiedBy string `binding:"max=100"`
+}
+
+type RevokeAPIKeyParams struct {
+	ID         int32  `binding:"min=1"`
+	ModifiedBy string `binding:"max=100"`
+}
+
+// AuthenticateAPIKeyParams asks whether the key may call the route, with the project of its
+// :project parameter if any.
+type AuthenticateAPIKeyParams struct {
+	Key     string `binding:"required,max=200"`
+	Method  string `binding:"required"`
+	Path    string
+	Project string
+}
@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"time"
+)
+
+// ArchiveFormatVersion is the version of the layout of the archive bundles. The import reads
+// the bundles of this version and the previous ones.
+const ArchiveFormatVersion = 1
+
+type ArchiveStatus string
+
+const (
+	ArchiveQueued    ArchiveStatus = "queued"
+	ArchiveRunning   ArchiveStatus = "running"
+	ArchiveCompleted ArchiveStatus = "completed"
+	ArchiveFailed    ArchiveStatus = "failed"
+	// ArchiveExpired is a completed archive whose bundle was removed.
+	ArchiveExpired ArchiveStatus = "expired"
+)
+
+// ArchiveJob exports a whole project at its wrap into a bundle for cold storage: the rows of
+// its MySQL tables, its MongoDB documents, its Neo4j subgraph and the manifest of the files
+// its records refer to. Done of Total sections were exported so far, Step being the section
+// in progress.
+type ArchiveJob struct {
+	Project        string        `json:"project"`
+	Status         ArchiveStatus `json:"status"`
+	Step           string        `json:"step"`
+	Total          int64         `json:"total"`
+	Done           int64         `json:"done"`
+	Size           int64         `json:"size"`
+	Error          *string       `json:"error"`
+	StartedAtUTC   *time.Time    `json:"started_at_utc"`
+	CompletedAtUTC *time.Time    `json:"completed_at_utc"`
+	ExpiresAtUTC   *time.Time    `json:"expires_at_utc"`
+	DownloadURL    string        `json:"download_url,omitempty"`
+	CreatedAtUTC   time.Time     `json:"created_at_utc"`
+	ModifiedAtUTC  time.Time     `json:"modified_at_utc"`
+	CreatedBy      string        `json:"created_by"`
+	ID             int32         `json:"id"`
+}
+
+type ListArchiveJobsParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type GetArchiveJobParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID      int32  `binding:"required"`
+}
+
+type CreateArchiveJobParams struct {
+	Project   string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	CreatedBy string `binding:"min=1,max=100"`
+}
+
+// ArchiveManifest is the first entry of a bundle, describing its sections. The files of the
+// attachments are not in the bundle and must be archived from the storage with it.
+type ArchiveManifest struct {
+	FormatVersion int                  `json:"format_version"`
+	Project       string               `json:"project"`
+	Tables        []*ArchiveTable      `json:"tables"`
+	Collections   []*ArchiveCollection `json:"collections"`
+	// Graph is nil when the DataDependency API was not available.
+	Graph *ArchiveGraph `json:"graph"`
+	// AttachmentsFile lists the ArchiveAttachment, one per line.
+	AttachmentsFile string    `json:"attachments_file"`
+	Attachments     int64     `json:"attachments"`
+	CreatedAtUTC    time.Time `json:"created_at_utc"`
+	CreatedBy       string    `json:"created_by"`
+}
+
+// ArchiveTable is the SQL dump of the rows of a project in a MySQL table, one INSERT
+// statement per line.
+type ArchiveTable struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+}
+
+// ArchiveCollection is the dump of the documents of a project in a MongoDB collection, one
+// canonical Extended JSON document per line.
+type ArchiveCollection struct {
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	Documents int64  `json:"documents"`
+}
+
+// ArchiveGraph is the dump of the subgraph of a project, one JSON node or relationship per
+// line. The relationships to the nodes of other projects are kept for reference but are not
+// restored, as those nodes cannot be told apart in another instance.
+type ArchiveGraph struct {
+	NodesFile         string `json:"nodes_file"`
+	RelationshipsFile string `json:"relationships_file"`
+	Nodes             int64  `json:"nodes"`
+	Relationships     int64  `json:"relationships"`
+}
+
+// Kinds of the attachments of the archives.
+const (
+	ArchiveAttachmentTake      = "take"
+	ArchiveAttachmentFile      = "file"
+	ArchiveAttachmentTranscode = "transcode"
+)
+
+// ArchiveAttachment is a file of the storage a record of the project refers to. Size and Hash
+// are only known for the hashed files.
+type ArchiveAttachment struct {
+	Kind         string  `json:"kind"`
+	ReviewInfoID int32   `json:"review_info_id"`
+	Path         string  `json:"path"`
+	Size         *uint64 `json:"size,omitempty"`
+	Hash         *string `json:"hash,omitempty"`
+}
+
+// ArchiveImportParams restore a bundle into an instance whose tables are migrated and which
+// does not have the project yet, typically a cold-storage instance.
+type ArchiveImportParams struct {
+	File string `binding:"min=1"`
+	// SkipMongo and SkipGraph leave the documents and the subgraph out of the restore.
+	SkipMongo bool
+	SkipGraph bool
+	// DryRun checks the bundle and the target without writing.
+	DryRun bool
+}
+
+type ArchiveImportResult struct {
+	Project       string           `json:"project"`
+	FormatVersion int              `json:"format_version"`
+	Rows          map[string]int64 `json:"rows"`
+	Documents     map[string]int64 `json:"documents"`
+	Nodes         int64            `json:"nodes"`
+	Relationships int64            `json:"relationships"`
+	// SkippedRelationships lead to the nodes of other projects.
+	SkippedRelationships int64 `json:"skipped_relationships"`
+	DryRun               bool  `json:"dry_run"`
+}
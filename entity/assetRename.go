@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// AssetRenameChange counts the records of a table moved from the old name of an asset to the
+// new one. Merged counts the records dropped because the new name has them already.
+type AssetRenameChange struct {
+	Table  string `json:"table"`
+	Moved  int64  `json:"moved"`
+	Merged int64  `json:"merged"`
+}
+
+// AssetRename moves the history of an asset from the group From to the group To of a root.
+// Merge tells whether To had reviews already. The rename of a dry run is rolled back, so it
+// has no ID.
+type AssetRename struct {
+	Project      string               `json:"project"`
+	Root         string               `json:"root"`
+	From         string               `json:"from"`
+	To           string               `json:"to"`
+	DryRun       bool                 `json:"dry_run"`
+	Merge        bool                 `json:"merge"`
+	Changes      []*AssetRenameChange `json:"changes"`
+	Studio       string               `json:"studio"`
+	CreatedAtUTC time.Time            `json:"created_at_utc"`
+	CreatedBy    string               `json:"created_by"`
+	ID           int32                `json:"id"`
+}
+
+type ListAssetRenamesParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset   *string
+}
+
+type RenameAssetParams struct {
+	Project   string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Root      string `binding:"min=1,max=30"`
+	From      string `binding:"min=1,max=255"`
+	To        string `binding:"min=1,max=255,nefield=From"`
+	DryRun    bool
+	Studio    string `binding:"max=30"`
+	CreatedBy string `binding:"max=100"`
+}
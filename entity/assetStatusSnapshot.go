@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Formats of the pivot diff.
+const (
+	PivotDiffFormatJSON = "json"
+	PivotDiffFormatCSV  = "csv"
+)
+
+// PivotPhaseChange is the change of a phase of an asset between two snapshots. The From fields
+// are nil for the phases which had no review in the earlier snapshot.
+type PivotPhaseChange struct {
+	Phase              string  `json:"phase"`
+	FromApprovalStatus *string `json:"from_approval_status"`
+	ToApprovalStatus   string  `json:"to_approval_status"`
+	FromWorkStatus     *string `json:"from_work_status"`
+	ToWorkStatus       string  `json:"to_work_status"`
+	FromTake           *string `json:"from_take"`
+	ToTake             string  `json:"to_take"`
+	Regression         bool    `json:"regression"`
+}
+
+type PivotDiffAsset struct {
+	Group    string              `json:"group"`
+	Relation string              `json:"relation"`
+	Phases   []*PivotPhaseChange `json:"phases"`
+}
+
+// PivotDiff compares the phase statuses of the assets of a project between the nightly
+// snapshots of From and To. Changed lists the assets of both snapshots with changed phases,
+// Added the assets only in the later one, and Regressions the phases of Changed which went
+// from approved to retake.
+type PivotDiff struct {
+	Project     string            `json:"project"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Changed     []*PivotDiffAsset `json:"changed"`
+	Added       []*PivotDiffAsset `json:"added"`
+	Regressions []*PivotDiffAsset `json:"regressions"`
+}
+
+// IsPivotRegression tells whether a phase going from one approval status to another is a
+// regression.
+func IsPivotRegression(from, to string) bool {
+	return strings.EqualFold(from, "approved") && strings.EqualFold(to, "retake")
+}
+
+// GetPivotDiffParams compares the latest snapshots taken on or before From and To, in UTC.
+type GetPivotDiffParams struct {
+	Project string    `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	From    time.Time `binding:"required"`
+	To      time.Time `binding:"required,gtefield=From"`
+	Format  string    `binding:"oneof=json csv"`
+}
@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type StudioAuthParams struct {
+	Project    string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	AuthHeader string `binding:"required"`
+}
+
+type ProjectAccessParams struct {
+	Studio  string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit,required"`
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit,required"`
+}
+
+type LoginParams struct {
+	Studio   string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit,required"`
+	Password string `binding:"min=8,max=100,alphanum,required"`
+}
+
+// OIDCStateCookie keeps the state of a login with the OpenID Connect provider in the browser
+// of the user until the callback.
+const OIDCStateCookie = "oidc_state"
+
+// OIDCCallbackParams are the parameters the OpenID Connect provider returns the user with.
+type OIDCCallbackParams struct {
+	Code        string `binding:"required"`
+	State       string `binding:"required"`
+	StateCookie string `binding:"required"`
+}
+
+// OIDCIdentity is a user authenticated by the OpenID Connect provider.
+type OIDCIdentity struct {
+	Subject string
+	Email   string
+}
+
+// TokenCurrent can be used in place of the ID of the token presented in the request.
+const TokenCurrent = "current"
+
+// TokenInfo describes the claims of an access token. User is the user authenticated by the
+// OpenID Connect provider, empty for the tokens of a studio password.
+type TokenInfo struct {
+	ID           string    `json:"id"`
+	Studio       string    `json:"studio"`
+	User         string    `json:"user,omitempty"`
+	IssuedAtUTC  time.Time `json:"issued_at_utc"`
+	ExpiresAtUTC time.Time `json:"expires_at_utc"`
+}
+
+// GetTokenPermissionsParams lists the permissions of the token of AuthHeader, or of the API
+// key APIKeyID the request was authenticated with.
+type GetTokenPermissionsParams struct {
+	ID         string `binding:"required,max=64"`
+	AuthHeader string `binding:"required_without=APIKeyID"`
+	APIKeyID   *int32
+}
+
+// TokenScope is what the routes permitted to a token or an API key are derived from: the
+// projects of its studio, within the projects of the API key, and the permissions of its user
+// in the projects enforcing roles. The projects missing from Enforced do not enforce roles.
+type TokenScope struct {
+	Token    *TokenInfo
+	APIKey   *APIKey
+	Studio   string
+	User     string
+	Projects []string
+	Enforced map[string][]Permission
+}
+
+// RoutePermission is a route the token is permitted to call. When Projects or Studios is set,
+// the route is only permitted with one of them as its :project or :studio parameter.
+// Permission is the permission of the user the route requires. When Fields is set, it is only
+// required to set those fields of the body, which can then only be set on FieldProjects.
+type RoutePermission struct {
+	Method        string     `json:"method"`
+	Path          string     `json:"path"`
+	Permission    Permission `json:"permission,omitempty"`
+	Projects      []string   `json:"projects,omitempty"`
+	Studios       []string   `json:"studios,omitempty"`
+	Fields        []string   `json:"fields,omitempty"`
+	FieldProjects []string   `json:"field_projects,omitempty"`
+}
+
+type TokenPermissions struct {
+	Token  *TokenInfo         `json:"token,omitempty"`
+	APIKey *APIKey            `json:"api_key,omitempty"`
+	Admin  bool               `json:"admin"`
+	Routes []*RoutePermission `json:"routes"`
+}
+
+var (
+	// ErrTokenExpired is returned when a token has expired, clock skew taken into account.
+	ErrTokenExpired = fmt.Errorf("%w: token has expired", ErrUnauthorized)
+
+	// ErrTokenReplayed is returned when a query token has already been used, or is used
+	// outside of its replay window.
+	ErrTokenReplayed = fmt.Errorf("%w: token has already been used", ErrUnauthorized)
+)
+
+// Error codes returned with authentication failures, so that clients can tell when to prompt
+// the user to log in again.
+const (
+	TokenErrorInvalid  = "token_invalid"
+	TokenErrorExpired  = "token_expired"
+	TokenErrorReplayed = "token_replayed"
+	// TokenErrorOIDC is returned to the login page when the login with the OpenID Connect
+	// provider failed.
+	TokenErrorOIDC = "oidc_failed"
+)
+
+func TokenErrorCode(err error) string {
+	if errors.Is(err, ErrTokenReplayed) {
+		return TokenErrorReplayed
+	}
+	if errors.Is(err, ErrTokenExpired) {
+		return TokenErrorExpired
+	}
+	return TokenErrorInvalid
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BasePath is the path the service is served at behind a reverse proxy, e.g. "/central30",
+// empty when it is served at the root. It is set at startup from PPI_BASE_PATH.
+var BasePath string
+
+// ParseBasePath returns the base path of a setting, starting with a slash and ending without
+// one, or empty for the root.
+func ParseBasePath(s string) (string, error) {
+	s = strings.Trim(strings.TrimSpace(s), "/")
+	if s == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(s, "?#\\ ") || strings.Contains(s, "//") {
+		return "", fmt.Errorf("invalid base path: %q", s)
+	}
+	return "/" + s, nil
+}
+
+// PublicPath returns the path clients reach the path p of the service at, p being a path at
+// the root like "/api/projects".
+func PublicPath(p string) string {
+	return BasePath + p
+}
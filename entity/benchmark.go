@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// BenchmarkScenario is a request measured by the load test mode.
+type BenchmarkScenario struct {
+	Name string
+	// Path is the path of the request, relative to the API base URL, with its query.
+	Path string
+}
+
+// DefaultBenchmarkScenarios returns the asset pivot and CSV code paths of a project: the list
+// and grouped views of the pivot, sorted by name and by a phase column, filtered by status,
+// and the tracker CSV generation.
+func DefaultBenchmarkScenarios(project string) []*BenchmarkScenario {
+	pivot := fmt.Sprintf("/projects/%s/reviews/assets/pivot", url.PathEscape(project))
+	return []*BenchmarkScenario{
+		{Name: "pivotList", Path: pivot + "?view=list&per_page=50"},
+		{Name: "pivotListLastPage", Path: pivot + "?view=list&per_page=50&page=10"},
+		{Name: "pivotListPhaseSort", Path: pivot + "?view=list&per_page=50&sort=bld_work&dir=DESC"},
+		{Name: "pivotListStatusFilter", Path: pivot + "?view=list&per_page=50&approval_status=check"},
+		{Name: "pivotGrouped", Path: pivot + "?view=grouped&per_page=50"},
+		{
+			Name: "generateCsv",
+			Path: fmt.Sprintf("/projects/%s/assets/generateCsv", url.PathEscape(project)),
+		},
+	}
+}
+
+type BenchmarkParams struct {
+	// BaseURL is the base URL of the API of a server running against a seeded database,
+	// e.g. "http://localhost:8080/api".
+	BaseURL     string `binding:"url"`
+	Project     string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Iterations  int    `binding:"min=1,max=10000"`
+	Concurrency int    `binding:"min=1,max=64"`
+	// Warmup is the number of requests of each scenario sent before measuring.
+	Warmup int `binding:"min=0,max=100"`
+	// Scenarios are the names of the scenarios to run; all the scenarios run when empty.
+	Scenarios []string
+}
+
+type BenchmarkStats struct {
+	Name     string `json:"name"`
+	Requests int    `json:"requests"`
+	Errors   int    `json:"errors"`
+	// Latencies are in milliseconds.
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+	// Throughput is the number of requests completed per second.
+	Throughput float64 `json:"throughput"`
+}
+
+type BenchmarkReport struct {
+	StartedAtUTC time.Time         `json:"started_at_utc"`
+	BaseURL      string            `json:"base_url"`
+	Project      string            `json:"project"`
+	Iterations   int               `json:"iterations"`
+	Concurrency  int               `json:"concurrency"`
+	Stats        []*BenchmarkStats `json:"stats"`
+}
+
+// BenchmarkRegression is a scenario slower, or failing more, than in the baseline.
+type BenchmarkRegression struct {
+	Name     string  `json:"name"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+func (r *BenchmarkRegression) String() string {
+	return fmt.Sprintf("%s: %s %.1f -> %.1f", r.Name, r.Metric, r.Baseline, r.Current)
+}
+
+// benchmarkNoiseMs is the latency difference under which a slowdown is considered noise,
+// however large its ratio.
+const benchmarkNoiseMs = 5
+
+// Compare returns the regressions of the report against a baseline report. A scenario
+// regresses when it has errors, or when its p50 or p95 latency grows by more than the given
+// tolerance, e.g. 0.2 for 20%. The scenarios missing from the baseline are not compared.
+func (r *BenchmarkReport) Compare(
+	baseline *BenchmarkReport,
+	tolerance float64,
+) []*BenchmarkRegression {
+	base := map[string]*BenchmarkStats{}
+	for _, s := range baseline.Stats {
+		base[s.Name] = s
+	}
+	var regressions []*BenchmarkRegression
+	for _, s := range r.Stats {
+		if s.Errors != 0 {
+			b := 0
+			if bs, ok := base[s.Name]; ok {
+				b = bs.Errors
+			}
+			regressions = append(regressions, &BenchmarkRegression{
+				Name:     s.Name,
+				Metric:   "errors",
+				Baseline: float64(b),
+				Current:  float64(s.Errors),
+			})
+		}
+		b, ok := base[s.Name]
+		if !ok {
+			continue
+		}
+		for _, m := range []struct {
+			metric            string
+			baseline, current float64
+		}{
+			{"p50_ms", b.P50Ms, s.P50Ms},
+			{"p95_ms", b.P95Ms, s.P95Ms},
+		} {
+			if m.current > m.baseline*(1+tolerance) && m.current-m.baseline > benchmarkNoiseMs {
+				regressions = append(regressions, &BenchmarkRegression{
+					Name:     s.Name,
+					Metric:   m.metric,
+					Baseline: m.baseline,
+					Current:  m.current,
+				})
+			}
+		}
+	}
+	return regressions
+}
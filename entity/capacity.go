@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// Load levels of the API reported by its capacity.
+const (
+	CapacityLevelOK        = "ok"
+	CapacityLevelBusy      = "busy"
+	CapacityLevelSaturated = "saturated"
+)
+
+// CapacityDBPool is the state of the connection pool of the MySQL database. Saturation is the
+// ratio of the connections in use to the maximum, 0 when the pool is not limited.
+type CapacityDBPool struct {
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	MaxOpenConnections int     `json:"max_open_connections"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationMS     int64   `json:"wait_duration_ms"`
+	Saturation         float64 `json:"saturation"`
+}
+
+// Capacity is the current load of the API, for the clients throttling themselves. Load is the
+// highest of the in-flight and the database pool ratios, and RetryAfterSeconds is the advised
+// delay before sending more work, 0 while the API is not busy.
+type Capacity struct {
+	InFlight          int64          `json:"in_flight"`
+	MaxInFlight       int64          `json:"max_in_flight"`
+	DBPool            CapacityDBPool `json:"db_pool"`
+	Load              float64        `json:"load"`
+	Level             string         `json:"level"`
+	RetryAfterSeconds int            `json:"retry_after_seconds"`
+	CheckedAtUTC      time.Time      `json:"checked_at_utc"`
+}
@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+// ClientCompatStatus tells a desktop tool whether its version may still be used.
+type ClientCompatStatus string
+
+const (
+	// ClientCompatSupported versions may be used as they are.
+	ClientCompatSupported ClientCompatStatus = "supported"
+	// ClientCompatDeprecated versions still work, but the users should be told to update.
+	ClientCompatDeprecated ClientCompatStatus = "deprecated"
+	// ClientCompatBlocked versions must not be used anymore.
+	ClientCompatBlocked ClientCompatStatus = "blocked"
+)
+
+// ClientCompatRule is the row of a desktop tool in the compatibility matrix. The versions
+// older than MinVersion are blocked, and those older than DeprecatedBelow are deprecated.
+type ClientCompatRule struct {
+	Client          string    `json:"client"`
+	MinVersion      string    `json:"min_version"`
+	DeprecatedBelow *string   `json:"deprecated_below"`
+	LatestVersion   *string   `json:"latest_version"`
+	ChangelogURL    *string   `json:"changelog_url"`
+	Message         string    `json:"message"`
+	CreatedAtUTC    time.Time `json:"created_at_utc"`
+	ModifiedAtUTC   time.Time `json:"modified_at_utc"`
+	ModifiedBy      string    `json:"modified_by"`
+	CreatedBy       string    `json:"created_by"`
+}
+
+// ClientCompat is the compatibility of a version of a desktop tool.
+type ClientCompat struct {
+	Client          string             `json:"client"`
+	Version         string             `json:"version"`
+	Status          ClientCompatStatus `json:"status"`
+	MinVersion      string             `json:"min_version"`
+	DeprecatedBelow *string            `json:"deprecated_below"`
+	LatestVersion   *string            `json:"latest_version"`
+	ChangelogURL    *string            `json:"changelog_url"`
+	Message         string             `json:"message"`
+}
+
+type GetClientCompatParams struct {
+	Client  string `binding:"min=1,max=50"`
+	Version string `binding:"min=1,max=50"`
+}
+
+// UpdateClientCompatRuleParams creates the row of a desktop tool or replaces it.
+type UpdateClientCompatRuleParams struct {
+	Client          string  `binding:"min=1,max=50"`
+	MinVersion      string  `binding:"min=1,max=50"`
+	DeprecatedBelow *string `binding:"omitempty,min=1,max=50"`
+	LatestVersion   *string `binding:"omitempty,min=1,max=50"`
+	ChangelogURL    *string `binding:"omitempty,url,max=500"`
+	Message         string  `binding:"max=1000"`
+	ModifiedBy      string  `binding:"max=100"`
+}
+
+type DeleteClientCompatRuleParams struct {
+	Client string `binding:"min=1,max=50"`
+}
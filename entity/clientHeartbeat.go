@@ -0,0 +1,79 @@
+package entity
+
+import "time"
+
+const (
+	// DefaultConnectedClientsWithin is how recently a client must have sent a heartbeat to be
+	// listed as connected, unless the request tells otherwise.
+	DefaultConnectedClientsWithin = 5 * time.Minute
+	// MaxConnectedClientsWithin bounds the duration of the live view.
+	MaxConnectedClientsWithin = 24 * time.Hour
+	// MaxClientAdoptionDays bounds the range of the adoption reports.
+	MaxClientAdoptionDays = 366
+)
+
+// ClientHeartbeat is the latest heartbeat of a desktop tool or DCC plugin on a host, per
+// credential. The tokens of the studios are renewed on every request, so the heartbeats sent
+// with them are told apart by their studio, APIKeyID being 0, and those sent with an API key
+// by its ID.
+type ClientHeartbeat struct {
+	Studio         string    `json:"studio"`
+	APIKeyID       int32     `json:"api_key_id"`
+	Client         string    `json:"client"`
+	Version        string    `json:"version"`
+	Host           string    `json:"host"`
+	Beats          int64     `json:"beats"`
+	FirstSeenAtUTC time.Time `json:"first_seen_at_utc"`
+	LastSeenAtUTC  time.Time `json:"last_seen_at_utc"`
+	ID             int32     `json:"id"`
+}
+
+// ClientVersionCount counts the hosts and studios running a version of a client.
+type ClientVersionCount struct {
+	Client  string `json:"client"`
+	Version string `json:"version"`
+	Hosts   int    `json:"hosts"`
+	Studios int    `json:"studios"`
+}
+
+// ConnectedClients is the live view of the clients which sent a heartbeat lately.
+type ConnectedClients struct {
+	Clients  []*ClientHeartbeat    `json:"clients"`
+	Versions []*ClientVersionCount `json:"versions"`
+	SinceUTC time.Time             `json:"since_utc"`
+}
+
+// ClientAdoption counts the hosts and studios which ran a version of a client on a day, in UTC.
+type ClientAdoption struct {
+	Date    string `json:"date"`
+	Client  string `json:"client"`
+	Version string `json:"version"`
+	Hosts   int64  `json:"hosts"`
+	Studios int64  `json:"studios"`
+	Beats   int64  `json:"beats"`
+}
+
+type RecordClientHeartbeatParams struct {
+	Studio   string `binding:"min=1,max=30"`
+	APIKeyID int32  `binding:"min=0"`
+	Client   string `binding:"min=1,max=50"`
+	Version  string `binding:"min=1,max=50"`
+	Host     string `binding:"min=1,max=255"`
+}
+
+// ListConnectedClientsParams lists the clients which sent a heartbeat within the duration,
+// DefaultConnectedClientsWithin when it is 0, and at most MaxConnectedClientsWithin.
+type ListConnectedClientsParams struct {
+	Studio *string `binding:"omitempty,min=1,max=30"`
+	Client *string `binding:"omitempty,min=1,max=50"`
+	Within time.Duration
+}
+
+// ListClientAdoptionParams reports the adoption of the versions of the clients per day from
+// From to To, both included, the last 30 days when they are not given.
+type ListClientAdoptionParams struct {
+	Studio *string `binding:"omitempty,min=1,max=30"`
+	Client *string `binding:"omitempty,min=1,max=50"`
+	From   *time.Time
+	To     *time.Time
+}
@@ -0,0 +1,66 @@
+package entity
+
+import "time"
+
+// Kinds of data transfers to external studios reported for compliance.
+const (
+	TransferPublish            = "publish"
+	TransferDirectoryDeletion  = "directoryDeletion"
+	TransferAttachmentDownload = "attachmentDownload"
+)
+
+// Formats of the compliance report.
+const (
+	ComplianceFormatJSON = "json"
+	ComplianceFormatCSV  = "csv"
+	ComplianceFormatPDF  = "pdf"
+)
+
+// Transfer is data shared with an external studio. Target is the revision path of publishes,
+// the path of directories and the attachment ID of comment attachments. Detail holds the
+// origin studio of publishes, the status of directories and the comment ID of attachments.
+type Transfer struct {
+	Kind          string    `json:"kind"`
+	Studio        string    `json:"studio"`
+	OccurredAtUTC time.Time `json:"occurred_at_utc"`
+	Actor         string    `json:"actor"`
+	Target        string    `json:"target"`
+	Detail        string    `json:"detail"`
+}
+
+// StudioTransfers counts the transfers to an external studio per kind.
+type StudioTransfers struct {
+	Studio              string `json:"studio"`
+	Publishes           int    `json:"publishes"`
+	DirectoryDeletions  int    `json:"directory_deletions"`
+	AttachmentDownloads int    `json:"attachment_downloads"`
+}
+
+type ComplianceReport struct {
+	Project        string             `json:"project"`
+	From           string             `json:"from"`
+	To             string             `json:"to"`
+	Studios        []*StudioTransfers `json:"studios"`
+	Transfers      []*Transfer        `json:"transfers"`
+	GeneratedAtUTC time.Time          `json:"generated_at_utc"`
+}
+
+// GetComplianceReportParams reports the transfers from From to To included, in UTC.
+type GetComplianceReportParams struct {
+	Project string    `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Studio  *string   `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	From    time.Time `binding:"required"`
+	To      time.Time `binding:"required,gtefield=From"`
+	Format  string    `binding:"oneof=json csv pdf"`
+}
+
+// RecordTransferParams records a transfer which is not tracked by any other table, i.e. the
+// download of a comment attachment.
+type RecordTransferParams struct {
+	Project   string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Studio    string `binding:"min=1,max=30"`
+	Kind      string `binding:"oneof=attachmentDownload"`
+	Target    string `binding:"min=1,max=255"`
+	Detail    string `binding:"max=255"`
+	CreatedBy string `binding:"max=100"`
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsistencyTokenHeader carries the consistency token returned by write requests. Sending it
+// back on reads guarantees that they see the write, even when served by a read replica.
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+const KeyConsistencyToken contextKey = "consistencyToken"
+
+// ConsistencyToken identifies the state of the primary after a write: its executed GTID set,
+// or the time of the write when GTIDs are not available.
+type ConsistencyToken struct {
+	GTIDSet   string
+	WrittenAt time.Time
+}
+
+func (t *ConsistencyToken) String() string {
+	if t.GTIDSet != "" {
+		return "g1." + base64.RawURLEncoding.EncodeToString([]byte(t.GTIDSet))
+	}
+	return "t1." + strconv.FormatInt(t.WrittenAt.UnixMilli(), 10)
+}
+
+func ParseConsistencyToken(s string) (*ConsistencyToken, error) {
+	version, value, ok := strings.Cut(s, ".")
+	if ok {
+		switch version {
+		case "g1":
+			gtidSet, err := base64.RawURLEncoding.DecodeString(value)
+			if err == nil && len(gtidSet) > 0 {
+				return &ConsistencyToken{GTIDSet: string(gtidSet)}, nil
+			}
+		case "t1":
+			ms, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				return &ConsistencyToken{WrittenAt: time.UnixMilli(ms).UTC()}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: invalid consistency token %q", ErrBadRequest, s)
+}
@@ -0,0 +1,193 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Types of custom fields.
+const (
+	CustomFieldString = "string"
+	CustomFieldNumber = "number"
+	CustomFieldEnum   = "enum"
+	CustomFieldDate   = "date"
+)
+
+// Targets of custom fields. Review fields are stored on each review info, while asset fields
+// are shared by all reviews of an asset (group_1 and relation).
+const (
+	CustomFieldTargetReview = "review"
+	CustomFieldTargetAsset  = "asset"
+)
+
+// CustomFieldDateLayout is the format of the values of date fields.
+const CustomFieldDateLayout = "2006-01-02"
+
+// MetadataFilterPrefix is the prefix of the query parameters filtering by custom fields, as in
+// `?meta.difficulty=hard`.
+const MetadataFilterPrefix = "meta."
+
+type CustomFieldDefinition struct {
+	Project       string    `json:"project"`
+	Target        string    `json:"target"`
+	Key           string    `json:"key"`
+	DisplayName   string    `json:"display_name"`
+	Type          string    `json:"type"`
+	EnumValues    []string  `json:"enum_values"`
+	Required      bool      `json:"required"`
+	CreatedAtUTC  time.Time `json:"created_at_utc"`
+	ModifiedAtUTC time.Time `json:"modified_at_utc"`
+	ModifiedBy    string    `json:"modified_by"`
+	CreatedBy     string    `json:"created_by"`
+	ID            int32     `json:"id"`
+}
+
+type ListCustomFieldDefinitionsParams struct {
+	Project string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Target  *string `binding:"omitempty,oneof=review asset"`
+}
+
+type CreateCustomFieldDefinitionParams struct {
+	Project     string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Target      string   `binding:"oneof=review asset"`
+	Key         string   `binding:"min=1,max=50,alphanumunderscore"`
+	DisplayName string   `binding:"min=1,max=100"`
+	Type        string   `binding:"oneof=string number enum date"`
+	EnumValues  []string `binding:"required_if=Type enum,max=100,dive,min=1,max=100"`
+	Required    bool
+	CreatedBy   *string `binding:"omitempty,min=1,max=100"`
+}
+
+type UpdateCustomFieldDefinitionParams struct {
+	Project     string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID          int32    `binding:"required"`
+	DisplayName *string  `binding:"omitempty,min=1,max=100"`
+	EnumValues  []string `binding:"omitempty,max=100,dive,min=1,max=100"`
+	Required    *bool
+	ModifiedBy  *string `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteCustomFieldDefinitionParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+type AssetMetadata struct {
+	Project       string     `json:"project"`
+	Asset         string     `json:"asset"`
+	Relation      string     `json:"relation"`
+	Metadata      JSONObject `json:"metadata"`
+	ModifiedAtUTC time.Time  `json:"modified_at_utc"`
+	ModifiedBy    string     `json:"modified_by"`
+}
+
+type GetAssetMetadataParams struct {
+	Project  string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset    string `binding:"min=1,max=255"`
+	Relation string `binding:"min=1,max=100"`
+}
+
+// UpdateAssetMetadataParams merges Metadata into the stored values. Keys with a null value are
+// removed.
+type UpdateAssetMetadataParams struct {
+	Project    string     `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset      string     `binding:"min=1,max=255"`
+	Relation   string     `binding:"min=1,max=100"`
+	Metadata   JSONObject `binding:"required"`
+	ModifiedBy *string    `binding:"omitempty,min=1,max=100"`
+}
+
+// MergeCustomMetadata applies the changes to the current values, removing keys set to null,
+// and validates the result against the field definitions of the target. Numbers are stored as
+// float64 and dates as strings in CustomFieldDateLayout.
+func MergeCustomMetadata(
+	defs []*CustomFieldDefinition,
+	current JSONObject,
+	changes JSONObject,
+) (JSONObject, error) {
+	byKey := make(map[string]*CustomFieldDefinition, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+	}
+
+	merged := JSONObject{}
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range changes {
+		d, ok := byKey[k]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown custom field %q", ErrBadRequest, k)
+		}
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		normalized, err := d.normalize(v)
+		if err != nil {
+			return nil, err
+		}
+		merged[k] = normalized
+	}
+	for _, d := range defs {
+		if _, ok := merged[d.Key]; d.Required && !ok {
+			return nil, fmt.Errorf("%w: custom field %q is required", ErrBadRequest, d.Key)
+		}
+	}
+	return merged, nil
+}
+
+func (d *CustomFieldDefinition) normalize(v interface{}) (interface{}, error) {
+	invalid := fmt.Errorf(
+		"%w: invalid %s value for custom field %q: %v", ErrBadRequest, d.Type, d.Key, v,
+	)
+	switch d.Type {
+	case CustomFieldNumber:
+		n, ok := v.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, invalid
+		}
+		return n, nil
+	case CustomFieldDate:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid
+		}
+		if _, err := time.Parse(CustomFieldDateLayout, s); err != nil {
+			return nil, invalid
+		}
+		return s, nil
+	case CustomFieldEnum:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid
+		}
+		for _, ev := range d.EnumValues {
+			if s == ev {
+				return s, nil
+			}
+		}
+		return nil, invalid
+	default:
+		s, ok := v.(string)
+		if !ok || len(s) > 1000 {
+			return nil, invalid
+		}
+		return s, nil
+	}
+}
+
+// FormatCustomValue formats a stored value for CSV exports.
+func FormatCustomValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return fmt.Sprint(value)
+	case string:
+		return value
+	}
+	return fmt.Sprint(v)
+}
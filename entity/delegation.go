@@ -0,0 +1,60 @@
+package entity
+
+import "time"
+
+type DelegationStatus string
+
+const (
+	// DelegationActive lets the delegate approve on behalf of the delegator within the window
+	// of the delegation, and is scheduled until it starts.
+	DelegationActive DelegationStatus = "active"
+	// DelegationExpired is set once the window of the delegation has ended.
+	DelegationExpired DelegationStatus = "expired"
+	DelegationRevoked DelegationStatus = "revoked"
+)
+
+// Delegation lets a supervisor on leave have the delegate approve reviews on their behalf,
+// from StartsAtUTC until EndsAtUTC, in Project or in all projects when nil.
+type Delegation struct {
+	Delegator    string           `json:"delegator"`
+	Delegate     string           `json:"delegate"`
+	Project      *string          `json:"project"`
+	StartsAtUTC  time.Time        `json:"starts_at_utc"`
+	EndsAtUTC    time.Time        `json:"ends_at_utc"`
+	Reason       string           `json:"reason"`
+	Status       DelegationStatus `json:"status"`
+	RevokedAtUTC *time.Time       `json:"revoked_at_utc"`
+	RevokedBy    *string          `json:"revoked_by"`
+	CreatedAtUTC time.Time        `json:"created_at_utc"`
+	CreatedBy    string           `json:"created_by"`
+	ID           int32            `json:"id"`
+}
+
+// ListDelegationsParams filters delegations. Project keeps the delegations applying to the
+// project, including the ones of all projects.
+type ListDelegationsParams struct {
+	Delegator *string           `binding:"omitempty,min=1,max=100"`
+	Delegate  *string           `binding:"omitempty,min=1,max=100"`
+	Project   *string           `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Status    *DelegationStatus `binding:"omitempty,oneof=active expired revoked"`
+	*BaseListParams
+}
+
+type GetDelegationParams struct {
+	ID int32 `binding:"min=1"`
+}
+
+type CreateDelegationParams struct {
+	Delegator   string    `binding:"min=1,max=100"`
+	Delegate    string    `binding:"min=1,max=100,nefield=Delegator"`
+	Project     *string   `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	StartsAtUTC time.Time `binding:"required"`
+	EndsAtUTC   time.Time `binding:"required,gtfield=StartsAtUTC"`
+	Reason      string    `binding:"max=4000"`
+	CreatedBy   string    `binding:"min=1,max=100"`
+}
+
+type RevokeDelegationParams struct {
+	ID        int32  `binding:"min=1"`
+	RevokedBy string `binding:"min=1,max=100"`
+}
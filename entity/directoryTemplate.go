@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// Tokens replaced in the path patterns of directory templates. A pattern containing
+// DirectoryTokenPhase is expanded once per phase.
+const (
+	DirectoryTokenProject  = "{project}"
+	DirectoryTokenAsset    = "{asset}"
+	DirectoryTokenRelation = "{relation}"
+	DirectoryTokenPhase    = "{phase}"
+)
+
+// DirectoryTemplate is a set of path patterns stamped as directories when an asset is
+// scaffolded, e.g. "assets/{asset}/{relation}/{phase}/work".
+type DirectoryTemplate struct {
+	Project       string    `json:"project"`
+	Name          string    `json:"name"`
+	Patterns      []string  `json:"patterns"`
+	CreatedAtUTC  time.Time `json:"created_at_utc"`
+	ModifiedAtUTC time.Time `json:"modified_at_utc"`
+	ModifiedBy    string    `json:"modified_by"`
+	CreatedBy     string    `json:"created_by"`
+	ID            int32     `json:"id"`
+}
+
+type ListDirectoryTemplatesParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type CreateDirectoryTemplateParams struct {
+	Project   string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Name      string   `binding:"min=1,max=50"`
+	Patterns  []string `binding:"min=1,max=100,dive,min=1,max=1000"`
+	CreatedBy *string  `binding:"omitempty,min=1,max=100"`
+}
+
+type UpdateDirectoryTemplateParams struct {
+	Project    string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32    `binding:"required"`
+	Name       *string  `binding:"omitempty,min=1,max=50"`
+	Patterns   []string `binding:"omitempty,min=1,max=100,dive,min=1,max=1000"`
+	ModifiedBy *string  `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteDirectoryTemplateParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// ScaffoldAssetParams stamps the directories of the templates for an asset. All the templates
+// of the project are applied when TemplateIDs is empty, and the phases of the project's asset
+// phase template are used when Phases is empty.
+type ScaffoldAssetParams struct {
+	Project     string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset       string   `binding:"min=1,max=255,excludesall=/"`
+	Relation    *string  `binding:"omitempty,min=1,max=255,excludesall=/"`
+	Phases      []string `binding:"max=50,dive,min=1,max=30,excludesall=/"`
+	TemplateIDs []int32  `binding:"max=50"`
+	DryRun      bool
+	CreatedBy   *string `binding:"omitempty,min=1,max=100"`
+}
+
+// ScaffoldResult lists the directories created by a scaffold, or which would be created by a
+// dry run, and the ones which already existed.
+type ScaffoldResult struct {
+	DryRun   bool     `json:"dry_run"`
+	Created  []string `json:"created"`
+	Existing []string `json:"existing"`
+}
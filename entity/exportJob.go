@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type ExportStatus string
+
+const (
+	ExportQueued    ExportStatus = "queued"
+	ExportRunning   ExportStatus = "running"
+	ExportCompleted ExportStatus = "completed"
+	ExportFailed    ExportStatus = "failed"
+	// ExportExpired is a completed export whose document was removed.
+	ExportExpired ExportStatus = "expired"
+)
+
+// Kinds of documents generated by export jobs.
+const (
+	// ExportKindAssets is the assets tracker CSV of the generateCsv API.
+	ExportKindAssets = "assets"
+	// ExportKindPivot is the export of the asset pivot view.
+	ExportKindPivot = "pivot"
+)
+
+// ExportJob generates a large document in the background, which is downloaded once
+// completed until it expires. Query is the pivot query of the pivot exports.
+type ExportJob struct {
+	Project        string          `json:"project"`
+	Kind           string          `json:"kind"`
+	Format         string          `json:"format"`
+	Query          json.RawMessage `json:"query,omitempty"`
+	Status         ExportStatus    `json:"status"`
+	Size           int64           `json:"size"`
+	Error          *string         `json:"error"`
+	StartedAtUTC   *time.Time      `json:"started_at_utc"`
+	CompletedAtUTC *time.Time      `json:"completed_at_utc"`
+	ExpiresAtUTC   *time.Time      `json:"expires_at_utc"`
+	DownloadURL    string          `json:"download_url,omitempty"`
+	CreatedAtUTC   time.Time       `json:"created_at_utc"`
+	ModifiedAtUTC  time.Time       `json:"modified_at_utc"`
+	ModifiedBy     string          `json:"modified_by"`
+	CreatedBy      string          `json:"created_by"`
+	ID             int32           `json:"id"`
+}
+
+type ListExportJobsParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type GetExportJobParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID      int32  `binding:"required"`
+}
+
+type CreateExportJobParams struct {
+	Project   string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Kind      string  `binding:"oneof=assets pivot"`
+	Format    string  `binding:"oneof=csv xlsx"`
+	CreatedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// ExportRenderer writes the document of an export job.
+type ExportRenderer interface {
+	RenderExport(ctx context.Context, job *ExportJob, w io.Writer) error
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Dependency is an external service whose outages can be simulated.
+type Dependency string
+
+const (
+	DependencyMongo Dependency = "mongo"
+	DependencyNeo4j Dependency = "neo4j"
+)
+
+// Fault simulates a degraded dependency: every call to it is delayed by the latency, and a
+// share of the calls, given by the error rate, fails with ErrBadGateway.
+type Fault struct {
+	Dependency Dependency `json:"dependency"`
+	LatencyMs  int        `json:"latency_ms"`
+	ErrorRate  float64    `json:"error_rate"`
+	// ExpiresAtUTC is nil for the faults injected until they are cleared.
+	ExpiresAtUTC *time.Time `json:"expires_at_utc"`
+	CreatedAtUTC time.Time  `json:"created_at_utc"`
+}
+
+type SetFaultParams struct {
+	Dependency Dependency `binding:"oneof=mongo neo4j"`
+	LatencyMs  int        `binding:"min=0,max=60000"`
+	ErrorRate  float64    `binding:"min=0,max=1"`
+	// DurationSeconds bounds the fault, so that a forgotten one does not last.
+	DurationSeconds *int `binding:"omitempty,min=1,max=86400"`
+}
+
+type ClearFaultParams struct {
+	Dependency Dependency `binding:"oneof=mongo neo4j"`
+}
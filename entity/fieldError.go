@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"strings"
+)
+
+// ErrorDocsURL is the page documenting the error codes, set by PPI_ERROR_DOCS_URL. The error
+// responses link to the section of their code on it, e.g. "https://wiki/central30/errors#max".
+var ErrorDocsURL string
+
+// FieldError reports a field of a request which failed the request, like PayloadWarning. Code
+// is the failed rule, like "required" or "max", and Field the path of the field, like
+// "comments[0].txt".
+type FieldError struct {
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+	Docs    string `json:"docs,omitempty"`
+}
+
+// InvalidFieldsError is the ErrBadRequest of a request with invalid fields.
+type InvalidFieldsError struct {
+	Fields []*FieldError
+}
+
+func (e *InvalidFieldsError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+	return ErrBadRequest.Error() + ": " + strings.Join(messages, ", ")
+}
+
+func (e *InvalidFieldsError) Unwrap() error {
+	return ErrBadRequest
+}
@@ -0,0 +1,90 @@
+package entity
+
+import "time"
+
+// FileHash registers the SHA-256 content hash of a file of the AllFiles manifests of a review,
+// so that the places a file already exists at can be found by its content.
+type FileHash struct {
+	Project      string    `json:"project"`
+	Hash         string    `json:"sha256"`
+	Path         string    `json:"path"`
+	Size         uint64    `json:"size"`
+	ReviewInfoID int32     `json:"review_info_id"`
+	CreatedAtUTC time.Time `json:"created_at_utc"`
+	CreatedBy    string    `json:"created_by"`
+	ID           int32     `json:"id"`
+}
+
+type FileHashEntry struct {
+	Path string `json:"path" binding:"min=1,max=1000"`
+	Hash string `json:"sha256" binding:"len=64,hexadecimal,lowercase"`
+}
+
+type RegisterFileHashesParams struct {
+	Project      string           `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ReviewInfoID int32            `binding:"required"`
+	Files        []*FileHashEntry `binding:"min=1,max=10000,dive"`
+	CreatedBy    *string          `binding:"omitempty,min=1,max=100"`
+}
+
+type ListFilesByHashParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Hash    string `binding:"len=64,hexadecimal,lowercase"`
+}
+
+// UploadManifestEntry is a file a client is about to upload, with its content hash. Size is
+// compared with the registered size of the content when given.
+type UploadManifestEntry struct {
+	Path string  `json:"path" binding:"min=1,max=1000"`
+	Hash string  `json:"sha256" binding:"len=64,hexadecimal,lowercase"`
+	Size *uint64 `json:"size"`
+}
+
+type NegotiateUploadParams struct {
+	Project string                 `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Files   []*UploadManifestEntry `binding:"min=1,max=10000,dive"`
+}
+
+// ReusedFile is a file of an upload manifest whose content the project already has at
+// Source, from where it is copied instead of transferred.
+type ReusedFile struct {
+	Path   string    `json:"path"`
+	Hash   string    `json:"sha256"`
+	Size   uint64    `json:"size"`
+	Source *FileHash `json:"source"`
+}
+
+// UploadNegotiation splits an upload manifest into the files to transfer and the files to
+// reuse, in the order of the manifest.
+type UploadNegotiation struct {
+	Transfer     []*UploadManifestEntry `json:"transfer"`
+	Reuse        []*ReusedFile          `json:"reuse"`
+	TransferSize uint64                 `json:"transfer_size"`
+	ReusedSize   uint64                 `json:"reused_size"`
+}
+
+type GetDuplicateReportParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Limit   int    `binding:"min=1,max=1000"`
+}
+
+// DuplicateFileGroup is the set of distinct paths holding the same content. Savings is the
+// size which deduplicating them to a single copy would free.
+type DuplicateFileGroup struct {
+	Hash    string `json:"sha256"`
+	Size    uint64 `json:"size"`
+	Copies  int64  `json:"copies"`
+	Savings uint64 `json:"savings"`
+}
+
+// DuplicateReport quantifies the duplicated volume of the registered files of a project.
+// Files are counted once per distinct path, and Groups holds the largest savings first.
+type DuplicateReport struct {
+	TotalFiles     int64                 `json:"total_files"`
+	TotalSize      uint64                `json:"total_size"`
+	UniqueFiles    int64                 `json:"unique_files"`
+	UniqueSize     uint64                `json:"unique_size"`
+	DuplicateFiles int64                 `json:"duplicate_files"`
+	DuplicateSize  uint64                `json:"duplicate_size"`
+	Groups         []*DuplicateFileGroup `json:"groups"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "errors"
+
+// ErrDataDependencyUnavailable is returned for the dependencies when the DataDependency graph
+// is not configured.
+var ErrDataDependencyUnavailable = errors.New("DataDependency is not available")
+
+// GetAssetPivotParams selects the pivot row of an asset, as for its asset card.
+type GetAssetPivotParams struct {
+	Project  string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Root     string `binding:"min=1,max=30"`
+	Asset    string `binding:"min=1,max=255"`
+	Relation string `binding:"min=1,max=100"`
+}
+
+// RevisionDependency is an upstream content of the contents of a revision in the
+// DataDependency graph.
+type RevisionDependency struct {
+	Root      string `json:"root"`
+	Group     string `json:"group"`
+	Relation  string `json:"relation"`
+	Phase     string `json:"phase"`
+	Component string `json:"component"`
+	Revision  string `json:"revision"`
+	FileName  string `json:"file_name"`
+}
@@ -0,0 +1,10 @@
+package groupCategory
+
+// AssignParams assigns groups to a category at once, like the assets selected in the pivot.
+// The groups already assigned to the category are kept as they are.
+type AssignParams struct {
+	Project    string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32    `binding:"required"`
+	Groups     []string `binding:"min=1,max=1000,dive,min=1,max=255"`
+	ModifiedBy *string  `binding:"omitempty,min=1,max=100"`
+}
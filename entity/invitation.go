@@ -0,0 +1,69 @@
+package entity
+
+import "time"
+
+// InvitationStatus is the state of an invitation, expired once its ExpiresAtUTC has passed
+// before it was redeemed.
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationRedeemed InvitationStatus = "redeemed"
+	InvitationRevoked  InvitationStatus = "revoked"
+	InvitationExpired  InvitationStatus = "expired"
+)
+
+// Invitation grants a role in a project to the user redeeming its code, e.g. a freelancer
+// after their first login, without a DBA assigning the role. When Invitee is set, only that
+// user may redeem it. The code is only known when the invitation is created, then only its
+// hash is stored.
+type Invitation struct {
+	Project                string           `json:"project"`
+	Role                   string           `json:"role"`
+	Invitee                *string          `json:"invitee"`
+	Code                   string           `json:"code,omitempty"`
+	Status                 InvitationStatus `json:"status"`
+	ExpiresAtUTC           time.Time        `json:"expires_at_utc"`
+	MembershipExpiresAtUTC *time.Time       `json:"membership_expires_at_utc"`
+	RedeemedBy             *string          `json:"redeemed_by"`
+	RedeemedStudio         *string          `json:"redeemed_studio"`
+	RedeemedAtUTC          *time.Time       `json:"redeemed_at_utc"`
+	UserRoleID             *int32           `json:"user_role_id"`
+	RevokedAtUTC           *time.Time       `json:"revoked_at_utc"`
+	CreatedAtUTC           time.Time        `json:"created_at_utc"`
+	CreatedBy              string           `json:"created_by"`
+	ID                     int32            `json:"id"`
+}
+
+type ListInvitationsParams struct {
+	Project *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Status  *string `binding:"omitempty,oneof=pending redeemed revoked expired"`
+	*BaseListParams
+}
+
+type GetInvitationParams struct {
+	ID int32 `binding:"min=1"`
+}
+
+// CreateInvitationParams creates an invitation which may be redeemed until ExpiresAtUTC. The
+// role assigned on redemption ends at MembershipExpiresAtUTC when set.
+type CreateInvitationParams struct {
+	Project                string     `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Role                   string     `binding:"min=1,max=30,alphanumunderscore,lowercase"`
+	Invitee                *string    `binding:"omitempty,min=1,max=100"`
+	ExpiresAtUTC           time.Time  `binding:"required"`
+	MembershipExpiresAtUTC *time.Time `binding:"omitempty,gtfield=ExpiresAtUTC"`
+	CreatedBy              string     `binding:"max=100"`
+}
+
+// RedeemInvitationParams redeem the code of an invitation as the authenticated user, logged
+// in to Studio.
+type RedeemInvitationParams struct {
+	Code   string `binding:"required,max=100"`
+	Studio string `binding:"max=30"`
+	User   string `binding:"min=1,max=100"`
+}
+
+type RevokeInvitationParams struct {
+	ID int32 `binding:"min=1"`
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// Cache namespaces, which admins invalidate after fixing the data of the database by hand.
+const (
+	// CacheNamespacePivot is the cache of the asset pivot, the latest submission counts and
+	// the sequence rollups.
+	CacheNamespacePivot = "pivot"
+	// CacheNamespaceReports is the cache of the submission heatmaps.
+	CacheNamespaceReports    = "reports"
+	CacheNamespaceSettings   = "settings"
+	CacheNamespaceCategories = "categories"
+)
+
+// CacheInvalidator drops the cached data of a project, or of all the projects when project is
+// empty.
+type CacheInvalidator func(ctx context.Context, project string)
+
+type InvalidateCacheParams struct {
+	Namespace string  `binding:"oneof=pivot reports settings categories"`
+	Project   *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+// CacheInvalidation reports the invalidation of a cache namespace, of a project or of all of
+// them when Project is nil. The caches of the other instances of the API expire after their
+// TTL.
+type CacheInvalidation struct {
+	Namespace        string    `json:"namespace"`
+	Project          *string   `json:"project"`
+	InvalidatedAtUTC time.Time `json:"invalidated_at_utc"`
+}
+
+type ReindexStatus string
+
+const (
+	ReindexQueued    ReindexStatus = "queued"
+	ReindexRunning   ReindexStatus = "running"
+	ReindexCompleted ReindexStatus = "completed"
+	ReindexFailed    ReindexStatus = "failed"
+)
+
+// ReindexKindPivot rebuilds the latest reviews the asset pivot reads.
+const ReindexKindPivot = "pivot"
+
+// ReindexJob rebuilds the materialized data of a project in the background. Done of Total
+// assets and shots were rebuilt so far.
+type ReindexJob struct {
+	Project        string        `json:"project"`
+	Kind           string        `json:"kind"`
+	Status         ReindexStatus `json:"status"`
+	Total          int64         `json:"total"`
+	Done           int64         `json:"done"`
+	Rows           int64         `json:"rows"`
+	Error          *string       `json:"error"`
+	StartedAtUTC   *time.Time    `json:"started_at_utc"`
+	CompletedAtUTC *time.Time    `json:"completed_at_utc"`
+	CreatedAtUTC   time.Time     `json:"created_at_utc"`
+	ModifiedAtUTC  time.Time     `json:"modified_at_utc"`
+	CreatedBy      string        `json:"created_by"`
+	ID             int32         `json:"id"`
+}
+
+type ListReindexJobsParams struct {
+	Project *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type GetReindexJobParams struct {
+	ID int32 `binding:"required"`
+}
+
+type CreateReindexJobParams struct {
+	Project   string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Kind      string `binding:"oneof=pivot"`
+	CreatedBy string `binding:"min=1,max=100"`
+}
@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// Kinds of media encrypted at rest.
+const (
+	MediaKindThumbnail  = "thumbnail"
+	MediaKindAttachment = "attachment"
+)
+
+// MediaKey is a version of the key encrypting the media of a project at rest. The media of
+// the projects with a key are encrypted with the active version, while the retired versions
+// are kept to decrypt the media encrypted before a rotation.
+type MediaKey struct {
+	Project      string     `json:"project"`
+	Version      int32      `json:"version"`
+	Active       bool       `json:"active"`
+	RetiredAtUTC *time.Time `json:"retired_at_utc"`
+	CreatedAtUTC time.Time  `json:"created_at_utc"`
+	CreatedBy    string     `json:"created_by"`
+	ID           int32      `json:"id"`
+}
+
+// MediaAccessLog is an access to encrypted media, which was decrypted for the studio.
+type MediaAccessLog struct {
+	Project       string    `json:"project"`
+	Kind          string    `json:"kind"`
+	Path          string    `json:"path"`
+	KeyVersion    int32     `json:"key_version"`
+	Studio        string    `json:"studio"`
+	AccessedAtUTC time.Time `json:"accessed_at_utc"`
+	ID            int32     `json:"id"`
+}
+
+type ListMediaKeysParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+// RotateMediaKeyParams creates a new active key of the project, which enables the encryption
+// of its media on the first rotation.
+type RotateMediaKeyParams struct {
+	Project   string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	CreatedBy string `binding:"max=100"`
+}
+
+// EncryptThumbnailsParams encrypts the thumbnails of the project which are not encrypted with
+// its active key yet.
+type EncryptThumbnailsParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+// EncryptThumbnailsResult counts the thumbnails encrypted with the active key, including
+// those re-encrypted from a retired key, and those which already were.
+type EncryptThumbnailsResult struct {
+	KeyVersion int32    `json:"key_version"`
+	Encrypted  int      `json:"encrypted"`
+	Skipped    int      `json:"skipped"`
+	Failed     int      `json:"failed"`
+	Errors     []string `json:"errors"`
+}
+
+// ListMediaAccessLogsParams lists the accesses from From to To included, in UTC.
+type ListMediaAccessLogsParams struct {
+	Project string    `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	From    time.Time `binding:"required"`
+	To      time.Time `binding:"required,gtefield=From"`
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrNotificationSkipped is returned by senders when a notification is intentionally not sent,
+// so that it is not retried.
+var ErrNotificationSkipped = errors.New("notification skipped")
+
+type NotificationOutboxKind string
+
+const (
+	PublishNotificationKind      NotificationOutboxKind = "publish"
+	ReviewStatusNotificationKind NotificationOutboxKind = "reviewStatus"
+	SLABreachNotificationKind    NotificationOutboxKind = "slaBreach"
+	// PropagationOverdueNotificationKind alerts publishes not synced to other studios in time.
+	PropagationOverdueNotificationKind NotificationOutboxKind = "propagationOverdue"
+	// SettingChangesetNotificationKind is the change event of a published or rolled back
+	// changeset of pipeline settings.
+	SettingChangesetNotificationKind NotificationOutboxKind = "settingChangeset"
+	// WatchNotificationKind is an event of a review sent to the watchers of its asset or shot.
+	WatchNotificationKind NotificationOutboxKind = "watch"
+	// WebhookNotificationKind is an event posted to a webhook of the project.
+	WebhookNotificationKind NotificationOutboxKind = "webhook"
+	// StatusChatNotificationKind is an approval status transition of an asset posted to the
+	// Slack or MS Teams channels of the project.
+	StatusChatNotificationKind NotificationOutboxKind = "statusChat"
+)
+
+type NotificationOutboxStatus string
+
+const (
+	NotificationOutboxPending NotificationOutboxStatus = "pending"
+	NotificationOutboxSent    NotificationOutboxStatus = "sent"
+	// NotificationOutboxDead marks entries that failed too many times (dead letters).
+	NotificationOutboxDead NotificationOutboxStatus = "dead"
+)
+
+type NotificationOutboxEntry struct {
+	Kind             NotificationOutboxKind   `json:"kind"`
+	Project          string                   `json:"project"`
+	Payload          json.RawMessage          `json:"payload"`
+	Status           NotificationOutboxStatus `json:"status"`
+	Attempts         uint32                   `json:"attempts"`
+	NextAttemptAtUTC time.Time                `json:"next_attempt_at_utc"`
+	LastError        string                   `json:"last_error"`
+
+	CreatedAtUTC  time.Time `json:"created_at_utc"`
+	ModifiedAtUTC time.Time `json:"modified_at_utc"`
+	ID            int32     `json:"id"`
+}
+
+// ListDeadLettersParams filters the dead letters of the outbox, the last failed first.
+type ListDeadLettersParams struct {
+	Project *string                 `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Kind    *NotificationOutboxKind `binding:"omitempty,min=1,max=30"`
+	*BaseListParams
+}
+
+type GetDeadLetterParams struct {
+	ID int32 `binding:"min=1"`
+}
+
+// RetryDeadLettersParams selects the dead letters to send again: the ones of IDs, or all the
+// ones of Project and Kind when IDs is empty.
+type RetryDeadLettersParams struct {
+	IDs     []int32                 `binding:"max=1000,dive,min=1"`
+	Project *string                 `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Kind    *NotificationOutboxKind `binding:"omitempty,min=1,max=30"`
+}
+
+// PurgeDeadLettersParams deletes the dead letters of Project and Kind which failed for the
+// last time before Before.
+type PurgeDeadLettersParams struct {
+	Before  time.Time               `binding:"required"`
+	Project *string                 `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Kind    *NotificationOutboxKind `binding:"omitempty,min=1,max=30"`
+}
+
+// DeadLetterBatch is the number of dead letters retried or purged at once.
+type DeadLetterBatch struct {
+	Count int64 `json:"count"`
+}
+
+// DeadLetterStats is the depth of the dead letter queue, per kind, and when its oldest entry
+// failed for the last time.
+type DeadLetterStats struct {
+	Depth       int64                            `json:"depth"`
+	ByKind      map[NotificationOutboxKind]int64 `json:"by_kind"`
+	OldestAtUTC *time.Time                       `json:"oldest_at_utc"`
+}
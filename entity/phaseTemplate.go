@@ -0,0 +1,24 @@
+package entity
+
+// PhaseTemplateKeyFormat is the key of the Preference pipeline setting holding the ordered
+// phases of a root. It is usually set per project, but may be inherited from the studio or
+// common sections. The asset pivot has a column per phase of the template.
+const PhaseTemplateKeyFormat = "/ppip/roots/%s/phaseTemplate"
+
+// DefaultPhaseTemplates are the phases of the roots without a phase template.
+var DefaultPhaseTemplates = map[string][]string{
+	"assets": {"mdl", "rig", "bld", "dsn", "ldv"},
+}
+
+type PhaseTemplate struct {
+	Project string   `json:"project"`
+	Root    string   `json:"root"`
+	Phases  []string `json:"phases"`
+	// Default is true when no template is set, Phases then being the default phases.
+	Default bool `json:"default"`
+}
+
+type GetPhaseTemplateParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Root    string `binding:"min=1,max=30"`
+}
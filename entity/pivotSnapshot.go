@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ErrShareLinkExpired is returned for share links past their expiry. The snapshot is kept for
+// its creator, so it wraps ErrRecordNotFound for the public.
+var ErrShareLinkExpired = fmt.Errorf("%w: share link has expired", ErrRecordNotFound)
+
+// PivotSnapshot is an asset pivot result frozen to be shared by a public link. Rows are the
+// pivot rows, or the groups of rows for the group view, as returned by the pivot API when the
+// snapshot was taken, and Filters the query they were selected with.
+type PivotSnapshot struct {
+	Project         string              `json:"project"`
+	Root            string              `json:"root"`
+	View            string              `json:"view"`
+	Filters         map[string][]string `json:"filters"`
+	Phases          []string            `json:"phases"`
+	Rows            json.RawMessage     `json:"rows"`
+	Total           int64               `json:"total"`
+	ExpiresAtUTC    time.Time           `json:"expires_at_utc"`
+	Views           int64               `json:"views"`
+	LastViewedAtUTC *time.Time          `json:"last_viewed_at_utc"`
+	CreatedAtUTC    time.Time           `json:"created_at_utc"`
+	CreatedBy       string              `json:"created_by"`
+	ID              int32               `json:"id"`
+
+	// URL is the signed public URL of the snapshot. It is only returned on creation.
+	URL string `json:"url,omitempty"`
+}
+
+type CreatePivotSnapshotParams struct {
+	Project        string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Root           string `binding:"min=1,max=30"`
+	View           string `binding:"oneof=list group"`
+	Filters        map[string][]string
+	ExpiresInHours int     `binding:"min=1,max=720"`
+	CreatedBy      *string `binding:"omitempty,min=1,max=100"`
+}
+
+// GetSharedPivotSnapshotParams gets a snapshot by its public link, counting the view.
+type GetSharedPivotSnapshotParams struct {
+	ID        int32  `binding:"required"`
+	Expires   int64  `binding:"required"`
+	Signature string `binding:"required"`
+}
+
+type DeletePivotSnapshotParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+// PivotView is a named preset of the assets pivot: the query parameters filtering it, its
+// sort and the phase columns shown, in order. Views are private to their owner, the name
+// authenticated by the access token of the request.
+type PivotView struct {
+	Project       string              `json:"project"`
+	Owner         string              `json:"owner"`
+	Name          string              `json:"name"`
+	Root          string              `json:"root"`
+	Filters       map[string][]string `json:"filters"`
+	Sort          string              `json:"sort"`
+	Dir           string              `json:"dir"`
+	Columns       []string            `json:"columns"`
+	CreatedAtUTC  time.Time           `json:"created_at_utc"`
+	ModifiedAtUTC time.Time           `json:"modified_at_utc"`
+	ID            int32               `json:"id"`
+}
+
+type ListPivotViewsParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Owner   string `binding:"min=1,max=100"`
+}
+
+type GetPivotViewParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Owner   string `binding:"min=1,max=100"`
+	ID      int32  `binding:"required"`
+}
+
+// CreatePivotViewParams saves a view. Columns lists the phases shown, all of them when it is
+// empty.
+type CreatePivotViewParams struct {
+	Project string              `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Owner   string              `binding:"min=1,max=100"`
+	Name    string              `binding:"min=1,max=100"`
+	Root    string              `binding:"min=1,max=30"`
+	Filters map[string][]string `binding:"max=50,dive,keys,min=1,max=100,endkeys,max=100,dive,max=255"`
+	Sort    string              `binding:"min=1,max=50"`
+	Dir     string              `binding:"oneof=ASC DESC"`
+	Columns []string            `binding:"max=100,unique,dive,min=1,max=30"`
+}
+
+type UpdatePivotViewParams struct {
+	Project string              `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Owner   string              `binding:"min=1,max=100"`
+	ID      int32               `binding:"required"`
+	Name    *string             `binding:"omitempty,min=1,max=100"`
+	Root    *string             `binding:"omitempty,min=1,max=30"`
+	Filters map[string][]string `binding:"omitempty,max=50,dive,keys,min=1,max=100,endkeys,max=100,dive,max=255"`
+	Sort    *string             `binding:"omitempty,min=1,max=50"`
+	Dir     *string             `binding:"omitempty,oneof=ASC DESC"`
+	Columns []string            `binding:"omitempty,max=100,unique,dive,min=1,max=30"`
+}
+
+type DeletePivotViewParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Owner   string `binding:"min=1,max=100"`
+	ID      int32  `binding:"required"`
+}
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"time"
+)
+
+type ProjectBootstrapStatus string
+
+const (
+	ProjectBootstrapQueued    ProjectBootstrapStatus = "queued"
+	ProjectBootstrapRunning   ProjectBootstrapStatus = "running"
+	ProjectBootstrapCompleted ProjectBootstrapStatus = "completed"
+	ProjectBootstrapFailed    ProjectBootstrapStatus = "failed"
+)
+
+// Steps of the project bootstraps, run in this order.
+const (
+	ProjectBootstrapStepProject     = "project"
+	ProjectBootstrapStepSettings    = "settings"
+	ProjectBootstrapStepDirectories = "directories"
+	ProjectBootstrapStepCategories  = "categories"
+)
+
+// ProjectBootstrapJob creates a project and the scaffolding selected from its template
+// project: the values of its project settings, its directory templates and its group
+// categories. Done of Total steps were run so far, Step being the step in progress.
+type ProjectBootstrapJob struct {
+	Project        string                 `json:"project"`
+	Template       *string                `json:"template"`
+	Settings       bool                   `json:"settings"`
+	Directories    bool                   `json:"directories"`
+	Categories     bool                   `json:"categories"`
+	Status         ProjectBootstrapStatus `json:"status"`
+	Step           string                 `json:"step"`
+	Total          int64                  `json:"total"`
+	Done           int64                  `json:"done"`
+	Result         ProjectBootstrapResult `json:"result"`
+	Error          *string                `json:"error"`
+	StartedAtUTC   *time.Time             `json:"started_at_utc"`
+	CompletedAtUTC *time.Time             `json:"completed_at_utc"`
+	CreatedAtUTC   time.Time              `json:"created_at_utc"`
+	ModifiedAtUTC  time.Time              `json:"modified_at_utc"`
+	CreatedBy      string                 `json:"created_by"`
+	ID             int32                  `json:"id"`
+}
+
+// ProjectBootstrapResult counts what the steps of a bootstrap copied from the template.
+type ProjectBootstrapResult struct {
+	Settings    int `json:"settings"`
+	Directories int `json:"directories"`
+	Categories  int `json:"categories"`
+	Groups      int `json:"groups"`
+}
+
+// Steps returns the steps of the job, the creation of the project first.
+func (e *ProjectBootstrapJob) Steps() []string {
+	steps := []string{ProjectBootstrapStepProject}
+	if e.Settings {
+		steps = append(steps, ProjectBootstrapStepSettings)
+	}
+	if e.Directories {
+		steps = append(steps, ProjectBootstrapStepDirectories)
+	}
+	if e.Categories {
+		steps = append(steps, ProjectBootstrapStepCategories)
+	}
+	return steps
+}
+
+type GetProjectBootstrapJobParams struct {
+	ID int32 `binding:"required"`
+}
+
+// CreateProjectBootstrapJobParams select the scaffolding copied from Template, which is
+// required when any is selected.
+type CreateProjectBootstrapJobParams struct {
+	Project     string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Template    *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Settings    bool
+	Directories bool
+	Categories  bool
+	CreatedBy   string `binding:"min=1,max=100"`
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when the daily quota of a project operation is used up.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Headers reporting the quota of the operation of a request.
+const (
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	QuotaResetHeader     = "X-Quota-Reset"
+)
+
+// QuotaOperation is an expensive operation whose daily usage can be limited per project.
+type QuotaOperation string
+
+const (
+	QuotaCSVExport  QuotaOperation = "csvExport"
+	QuotaTranscode  QuotaOperation = "transcode"
+	QuotaBulkImport QuotaOperation = "bulkImport"
+)
+
+var QuotaOperations = []QuotaOperation{
+	QuotaCSVExport,
+	QuotaTranscode,
+	QuotaBulkImport,
+}
+
+func ParseQuotaOperation(s string) (QuotaOperation, bool) {
+	for _, op := range QuotaOperations {
+		if string(op) == s {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// ProjectQuota limits the number of requests of the operation per project and UTC day.
+// Operations without quota are unlimited.
+type ProjectQuota struct {
+	Project       string         `json:"project"`
+	Operation     QuotaOperation `json:"operation"`
+	DailyLimit    int32          `json:"daily_limit"`
+	Used          int32          `json:"used"`
+	CreatedAtUTC  time.Time      `json:"created_at_utc"`
+	ModifiedAtUTC time.Time      `json:"modified_at_utc"`
+	ModifiedBy    string         `json:"modified_by"`
+	CreatedBy     string         `json:"created_by"`
+	ID            int32          `json:"id"`
+}
+
+// QuotaUsage is the usage of the quota of an operation on the current UTC day.
+type QuotaUsage struct {
+	Limit      int32
+	Used       int32
+	ResetAtUTC time.Time
+}
+
+func (u *QuotaUsage) Remaining() int32 {
+	if u.Used >= u.Limit {
+		return 0
+	}
+	return u.Limit - u.Used
+}
+
+type ListProjectQuotasParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type UpdateProjectQuotaParams struct {
+	Project    string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Operation  QuotaOperation `binding:"required"`
+	DailyLimit int32          `binding:"min=0,max=1000000"`
+	ModifiedBy *string        `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteProjectQuotaParams struct {
+	Project    string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Operation  QuotaOperation `binding:"required"`
+	ModifiedBy *string        `binding:"omitempty,min=1,max=100"`
+}
+
+type ConsumeProjectQuotaParams struct {
+	Project   string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Operation QuotaOperation `binding:"required"`
+}
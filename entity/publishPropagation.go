@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+type PropagationStatus string
+
+const (
+	PropagationPending PropagationStatus = "pending"
+	PropagationArrived PropagationStatus = "arrived"
+	PropagationFailed  PropagationStatus = "failed"
+	// PropagationOverdue is reported for pending studios past the propagation deadline. It is
+	// never stored.
+	PropagationOverdue PropagationStatus = "overdue"
+)
+
+// StudioPropagation is the sync status of the files of a publish transaction in a studio
+// other than the one they were published from.
+type StudioPropagation struct {
+	Studio        string            `json:"studio"`
+	Status        PropagationStatus `json:"status"`
+	ArrivedAtUTC  *time.Time        `json:"arrived_at_utc"`
+	Message       string            `json:"message"`
+	ModifiedAtUTC *time.Time        `json:"modified_at_utc"`
+}
+
+type PublishPropagation struct {
+	Project        string               `json:"project"`
+	LogID          string               `json:"log_id"`
+	OriginStudio   string               `json:"origin_studio"`
+	RevisionPath   string               `json:"revision_path"`
+	PublishedAtUTC time.Time            `json:"published_at_utc"`
+	DeadlineAtUTC  time.Time            `json:"deadline_at_utc"`
+	Studios        []*StudioPropagation `json:"studios"`
+}
+
+type GetPublishPropagationParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	LogID   string `binding:"min=1,max=36"`
+}
+
+// ReportPublishPropagationParams is sent by the sync client of a studio when the files of a
+// publish transaction arrived, or failed to.
+type ReportPublishPropagationParams struct {
+	Project      string            `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	LogID        string            `binding:"min=1,max=36"`
+	Studio       string            `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Status       PropagationStatus `binding:"oneof=arrived failed"`
+	ArrivedAtUTC *time.Time        ``
+	Message      string            `binding:"max=1000"`
+	CreatedBy    *string           `binding:"omitempty,min=1,max=100"`
+}
+
+// OverduePropagation is a publish transaction not synced to a studio by the deadline.
+type OverduePropagation struct {
+	LogID          string
+	OriginStudio   string
+	Studio         string
+	RevisionPath   string
+	PublishedAtUTC time.Time
+}
+
+// PropagationOverdueNotification is posted to the chat of the project when the checker
+// detects overdue propagations.
+type PropagationOverdueNotification struct {
+	Project      string
+	Deadline     time.Duration
+	Propagations []*OverduePropagation
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// RateLimit is a token bucket refilled with Rate requests per second up to Burst requests.
+// A zero Rate disables the limit.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// RateLimitParams identify the client of a request. Token identifies its validated
+// credentials and IP its client IP; the buckets of those given are taken.
+type RateLimitParams struct {
+	Token string
+	IP    string
+	Write bool
+}
+
+// RateLimitDecision tells whether a request is allowed, with the tightest of its limits and
+// the requests remaining in it. RetryAfter is the wait before a rejected request is allowed.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
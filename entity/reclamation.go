@@ -0,0 +1,84 @@
+package entity
+
+import "time"
+
+type ReclamationStatus string
+
+const (
+	// ReclamationRequested waits for the approval of a supervisor.
+	ReclamationRequested ReclamationStatus = "requested"
+	// ReclamationApproved waits for the storage team to delete the takes of the manifest.
+	ReclamationApproved ReclamationStatus = "approved"
+	ReclamationRejected ReclamationStatus = "rejected"
+	// ReclamationCompleted records the bytes freed by the deletion.
+	ReclamationCompleted ReclamationStatus = "completed"
+)
+
+// ReclamationTake is a take to delete, as it was when the reclamation was requested.
+type ReclamationTake struct {
+	ReviewInfoID int32  `json:"review_info_id"`
+	Root         string `json:"root"`
+	Group1       string `json:"group_1"`
+	Relation     string `json:"relation"`
+	Phase        string `json:"phase"`
+	Take         string `json:"take"`
+	TakePath     string `json:"take_path"`
+}
+
+// Reclamation requests the deletion of old takes to free disk space. A supervisor other than
+// the requester approves or rejects it, then the storage team deletes the takes of its
+// manifest and completes it with the freed bytes.
+type Reclamation struct {
+	Project        string             `json:"project"`
+	Status         ReclamationStatus  `json:"status"`
+	Reason         string             `json:"reason"`
+	Takes          []*ReclamationTake `json:"takes,omitempty"`
+	ReviewedBy     *string            `json:"reviewed_by"`
+	ReviewedAtUTC  *time.Time         `json:"reviewed_at_utc"`
+	ReviewComment  *string            `json:"review_comment"`
+	CompletedBy    *string            `json:"completed_by"`
+	CompletedAtUTC *time.Time         `json:"completed_at_utc"`
+	FreedBytes     *int64             `json:"freed_bytes"`
+	CreatedAtUTC   time.Time          `json:"created_at_utc"`
+	ModifiedAtUTC  time.Time          `json:"modified_at_utc"`
+	ModifiedBy     string             `json:"modified_by"`
+	CreatedBy      string             `json:"created_by"`
+	ID             int32              `json:"id"`
+}
+
+type ListReclamationsParams struct {
+	Project string             `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Status  *ReclamationStatus `binding:"omitempty,oneof=requested approved rejected completed"`
+	*BaseListParams
+}
+
+type GetReclamationParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID      int32  `binding:"min=1"`
+}
+
+// CreateReclamationParams requests the deletion of the takes of the review infos of the
+// project. A take may only be in one pending reclamation at once.
+type CreateReclamationParams struct {
+	Project       string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ReviewInfoIDs []int32 `binding:"min=1,max=500,dive,min=1"`
+	Reason        string  `binding:"min=1,max=4000"`
+	CreatedBy     string  `binding:"min=1,max=100"`
+}
+
+// ReviewReclamationParams approves or rejects a requested reclamation.
+type ReviewReclamationParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"min=1"`
+	Approve    bool    ``
+	Comment    *string `binding:"omitempty,max=4000"`
+	ReviewedBy string  `binding:"min=1,max=100"`
+}
+
+// CompleteReclamationParams confirms the deletion of the takes of an approved reclamation.
+type CompleteReclamationParams struct {
+	Project     string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID          int32  `binding:"min=1"`
+	FreedBytes  *int64 `binding:"required,min=0"`
+	CompletedBy string `binding:"min=1,max=100"`
+}
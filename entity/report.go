@@ -0,0 +1,97 @@
+package entity
+
+import "time"
+
+// Reviewers are not assigned explicitly: the reviewer of a pending review is the last one who
+// set an approval status on a previous take of the same asset or shot and phase, and reviewers
+// of a phase are the ones who set approval statuses in it over the report period.
+
+// ReviewerActivity is the approval activity of a reviewer in a phase over the report period.
+type ReviewerActivity struct {
+	Reviewer              string
+	Phase                 string
+	ReviewedReviews       int
+	AverageLatencySeconds float64
+}
+
+// PendingReview is a review waiting for its first approval status. Reviewer is empty when no
+// take of the same asset or shot and phase has been reviewed yet.
+type PendingReview struct {
+	ReviewInfoID   int32
+	Root           string
+	Group          string
+	Relation       string
+	Phase          string
+	SubmittedAtUTC time.Time
+	Reviewer       string
+}
+
+type ReviewerLoad struct {
+	Reviewer            string   `json:"reviewer"`
+	Phase               string   `json:"phase"`
+	PendingReviews      int      `json:"pending_reviews"`
+	ReviewedReviews     int      `json:"reviewed_reviews"`
+	AverageLatencyHours *float64 `json:"average_latency_hours"`
+}
+
+// ReviewerLoadSuggestion suggests to move pending reviews from a reviewer, or from no one when
+// From is empty, to another reviewer of the phase.
+type ReviewerLoadSuggestion struct {
+	Phase         string  `json:"phase"`
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	ReviewInfoIDs []int32 `json:"review_info_ids"`
+}
+
+type ReviewerLoadReport struct {
+	Project           string                    `json:"project"`
+	SinceUTC          time.Time                 `json:"since_utc"`
+	Loads             []*ReviewerLoad           `json:"loads"`
+	UnassignedReviews int                       `json:"unassigned_reviews"`
+	Suggestions       []*ReviewerLoadSuggestion `json:"suggestions"`
+}
+
+type GetReviewerLoadParams struct {
+	Project string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Root    *string `binding:"omitempty,min=1,max=30"`
+	Phase   *string `binding:"omitempty,min=1,max=30"`
+	Days    int     `binding:"min=1,max=365"`
+}
+
+// Periods submissions are counted by in the submission heatmap.
+const (
+	HeatmapGroupByDay   = "day"
+	HeatmapGroupByWeek  = "week"
+	HeatmapGroupByMonth = "month"
+)
+
+// SubmissionCount is the number of reviews submitted in a root and phase during the period
+// starting on Date. Weeks start on Monday.
+type SubmissionCount struct {
+	Date  string `json:"date"`
+	Root  string `json:"root"`
+	Phase string `json:"phase"`
+	Count int    `json:"count"`
+}
+
+// SubmissionHeatmap is served from a cache; DataAsOf is when its counts were read from the
+// database.
+type SubmissionHeatmap struct {
+	Project        string             `json:"project"`
+	From           string             `json:"from"`
+	To             string             `json:"to"`
+	GroupBy        string             `json:"group_by"`
+	Counts         []*SubmissionCount `json:"counts"`
+	GeneratedAtUTC time.Time          `json:"generated_at_utc"`
+	DataAsOf       time.Time          `json:"data_as_of"`
+}
+
+// GetSubmissionHeatmapParams counts the submissions from From to To included, in UTC. A
+// cached heatmap older than MaxStaleness is counted again.
+type GetSubmissionHeatmapParams struct {
+	Project      string    `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	From         time.Time `binding:"required"`
+	To           time.Time `binding:"required,gtefield=From"`
+	GroupBy      string    `binding:"oneof=day week month"`
+	MaxStaleness *time.Duration
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// PendingReviewStatuses are the approval statuses of the reviews waiting for a supervisor,
+// compiled into the digests.
+var PendingReviewStatuses = []string{"check", "dirReview"}
+
+// PendingReview is the latest review of a phase of an asset which has been waiting for a
+// supervisor since PendingSinceUTC.
+type PendingReview struct {
+	Project         string    `json:"project"`
+	ReviewInfoID    int32     `json:"review_info_id"`
+	Group           string    `json:"group"`
+	Relation        string    `json:"relation"`
+	Phase           string    `json:"phase"`
+	Take            string    `json:"take"`
+	ApprovalStatus  string    `json:"approval_status"`
+	PendingSinceUTC time.Time `json:"pending_since_utc"`
+}
+
+// SupervisorDigest lists the pending reviews of the phases a supervisor leads, i.e. whose
+// review SLA lists their mail address, over all the projects.
+type SupervisorDigest struct {
+	MailAddress string           `json:"mail_address"`
+	Reviews     []*PendingReview `json:"reviews"`
+}
+
+// RunReviewDigestsParams compiles the digests of the reviews pending for longer than
+// PendingHours, and sends them unless DryRun.
+type RunReviewDigestsParams struct {
+	PendingHours int32 `binding:"min=1,max=8760"`
+	DryRun       bool
+}
+
+type ReviewDigestFailure struct {
+	MailAddress string `json:"mail_address"`
+	Error       string `json:"error"`
+}
+
+// ReviewDigestRun is the result of a run of the digests. Digests is only returned by dry runs.
+type ReviewDigestRun struct {
+	PendingHours int32                  `json:"pending_hours"`
+	Supervisors  int                    `json:"supervisors"`
+	Reviews      int                    `json:"reviews"`
+	Sent         int                    `json:"sent"`
+	Failures     []*ReviewDigestFailure `json:"failures"`
+	Digests      []*SupervisorDigest    `json:"digests,omitempty"`
+	RanAtUTC     time.Time              `json:"ran_at_utc"`
+}
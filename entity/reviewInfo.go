@@ -0,0 +1,573 @@
+/* ──────────────────────────────────────────────────────────────────────────
+	Module Name:
+    	entity/reviewInfo.go
+
+	Module Description:
+		Entity definitions and parameter structures for review information management.
+
+	Details:
+
+	Update and Modification History:
+	* - 29-10-2025 - SanjayK PSI - Initial creation sorting pagination implementation.
+	* - 20-11-2025 - SanjayK PSI - Fixed typo in filter property names handling.
+	* - 15-10-2026 - Added review intent (wip/publish/final) and per-project intent exclusions.
+	* - 15-10-2026 - Added ULID lookup to GetReviewParams.
+	* - 15-10-2026 - Added approval gating on upstream dependencies per project and phase.
+	* - 15-10-2026 - Added custom metadata to review information.
+	* - 15-10-2026 - Added tags to review information and tag filters.
+	* - 15-10-2026 - Added corrections of submitted fields with an audit log.
+	* - 15-10-2026 - Added the rebuild state of the materialized latest reviews.
+	* - 15-10-2026 - Added the per phase rollup of the shots of a sequence.
+	* - 15-10-2026 - Added the selection of the reviews of a take.
+	* - 15-10-2026 - Added reviews created before their AllFiles manifest.
+	* - 15-10-2026 - Added the link of reviews to the publish transaction of their take.
+	* - 15-10-2026 - Added the summary of a publish transaction linked to reviews.
+	* - 15-10-2026 - Added the restore, listing and purge of deleted reviews.
+	* - 15-10-2026 - Added the version of reviews and the preconditions of their updates.
+
+	Functions:
+	* - AssetPivot: Represents pivot information for assets including work and approval statuses.
+	* - LatestSubmissionRow: Represents a row containing latest submission information.
+	* - ReviewIntentSetting: Represents the intents hidden by default in a project's listings.
+	* - ReviewApprovalGate: Represents the approval gate mode of a project's phase.
+	* - UpstreamReview: Represents the approval state of an upstream dependency.
+	* - ReviewInfoAuditLog: Represents a correction of a submitted field of a review.
+	* - ReviewLatestState: Represents the last rebuild of a project's materialized latest reviews.
+	* - SequenceRollup: Represents the per phase statuses of the shots of a sequence.
+	* - PublishTransactionSummary: Represents a publish transaction reviews are linked to.
+	* - PurgeReviewInfosResult: Represents the deleted reviews of a project removed for good.
+	* - StaleReviewInfoError: Represents an update rejected as the review changed since.
+	────────────────────────────────────────────────────────────────────────── */
+
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/libs"
+)
+
+type Contents []*libs.Content
+
+func (c *Contents) MarshalJSON() ([]byte, error) {
+	if c == nil || len(*c) == 0 {
+		return []byte("[]"), nil
+	}
+	var contents []*libs.Content = *c
+	return json.Marshal(contents)
+}
+
+type Files []*libs.File
+
+func (a *Files) MarshalJSON() ([]byte, error) {
+	if a == nil || len(*a) == 0 {
+		return []byte("[]"), nil
+	}
+	var files []*libs.File = *a
+	return json.Marshal(files)
+}
+
+// Review intents tell whether a submission is meant for approval tracking.
+const (
+	ReviewIntentWIP     = "wip"
+	ReviewIntentPublish = "publish"
+	ReviewIntentFinal   = "final"
+)
+
+// DefaultExcludedReviewIntents are hidden from listings of projects without an intent setting.
+var DefaultExcludedReviewIntents = []string{ReviewIntentWIP}
+
+// ApprovalStatusApproved is the approval status required of upstream dependencies.
+const ApprovalStatusApproved = "approved"
+
+// Approval gate modes decide what happens when a review is approved while the contents it
+// depends on are not approved yet.
+const (
+	ApprovalGateOff   = "off"
+	ApprovalGateWarn  = "warn"
+	ApprovalGateBlock = "block"
+)
+
+// ApprovalGateAllPhases is the phase of a gate which applies to every phase of the project
+// without a gate of its own.
+const ApprovalGateAllPhases = "*"
+
+type Components []string
+
+func (c *Components) MarshalJSON() ([]byte, error) {
+	if c == nil || len(*c) == 0 {
+		return []byte("[]"), nil
+	}
+	var components []string = *c
+	return json.Marshal(components)
+}
+
+// Specification change: https://jira.ppi.co.jp/browse/POTOO-2406
+// Specification change: https://jira.ppi.co.jp/browse/POTOO-2594
+// Specification change: https://jira.ppi.co.jp/browse/POTOO-2666
+type ReviewInfo struct {
+	TaskID                     string              `json:"task_id"`
+	SubtaskID                  string              `json:"subtask_id"`
+	Studio                     string              `json:"studio"`
+	Project                    string              `json:"project"`
+	ProjectPath                string              `json:"project_path"`
+	ReviewComments             []*libs.CommentInfo `json:"review_comments"`
+	Path                       string              `json:"path"` // TODO: Remove the "Path" property after the tool migration is complete
+	TakePath                   string              `json:"take_path"`
+	Root                       string              `json:"root"`
+	Groups                     []string            `json:"groups"`
+	Relation                   string              `json:"relation"`
+	Phase                      string              `json:"phase"`
+	Component                  string              `json:"component"`
+	Take                       string              `json:"take"`
+	Intent                     string              `json:"intent"`
+	ApprovalStatus             string              `json:"approval_status"`
+	ApprovalStatusUpdatedUser  string              `json:"approval_status_updated_user"`
+	ApprovalStatusUpdatedAtUtc time.Time           `json:"approval_status_updated_at_utc"`
+	WorkStatus                 string              `json:"work_status"`
+	WorkStatusUpdatedUser      string              `json:"work_status_updated_user"`
+	WorkStatusUpdatedAtUtc     time.Time           `json:"work_status_updated_at_utc"`
+	ReviewTarget               Contents            `json:"review_target"`
+	ReviewData                 Contents            `json:"review_data"`
+	OutputContents             Contents            `json:"output_contents"`
+	SubmittedAtUtc             time.Time           `json:"submitted_at_utc"`
+	SubmittedComputer          string              `json:"submitted_computer"`
+	SubmittedOS                string              `json:"submitted_os"`
+	SubmittedOSVersion         string              `json:"submitted_os_version"`
+	SubmittedUser              string              `json:"submitted_user"`
+	ExecutedAtUtc              time.Time           `json:"executed_at_utc"`
+	ExecutedComputer           string              `json:"executed_computer"`
+	ExecutedOS                 string              `json:"executed_os"`
+	ExecutedOSVersion          string              `json:"executed_os_version"`
+	ExecutedUser               string              `json:"executed_user"`
+	AllFiles                   Files               `json:"all_files"`
+	NumAllFiles                uint32              `json:"num_all_files"`
+	SizeAllFiles               uint64              `json:"size_all_files"`
+	TargetComponents           Components          `json:"target_components"`
+	// ManifestPending is set until the AllFiles manifest of the review is uploaded, which is
+	// due at ManifestDueAtUTC. The review is deleted when it is not uploaded by then.
+	ManifestPending  bool       `json:"manifest_pending"`
+	ManifestDueAtUTC *time.Time `json:"manifest_due_at_utc,omitempty"`
+	Metadata         JSONObject `json:"metadata"`
+	Tags             []string   `json:"tags"`
+	// PublishTransactionID is the log ID of the publish transaction of the take of the review.
+	PublishTransactionID *string `json:"publish_transaction_id"`
+
+	Duration                    *int32  `json:"duration,omitempty"`
+	DurationTimeline            *string `json:"duration_timeline,omitempty"`
+	ExportShotsVersions         *bool   `json:"export_shots_versions,omitempty"`
+	ExportShotsVersionsRevision *string `json:"export_shots_versions_revision,omitempty"`
+	ExportShotsVersionsPath     *string `json:"export_shots_version_path,omitempty"`
+
+	CreatedAtUTC  time.Time `json:"created_at_utc"`
+	ModifiedAtUTC time.Time `json:"modified_at_utc"`
+	Deleted       *int32    `json:"deleted,omitempty"`
+	ModifiedBy    string    `json:"modified_by"`
+	CreatedBy     string    `json:"created_by"`
+	ID            int32     `json:"id"`
+	UID           *string   `json:"uid,omitempty"`
+	// Version is incremented by each change of the review, for the preconditions of updates.
+	Version uint32 `json:"version"`
+
+	// ApprovalWarnings is only set in the response of an update which approved the review
+	// while upstream dependencies were not approved.
+	ApprovalWarnings []*UpstreamReview `json:"approval_warnings,omitempty"`
+}
+
+type ListReviewInfoParams struct {
+	Project       string     `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Studio        *string    `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	TaskID        *string    `binding:"omitempty,uuid"`
+	SubtaskID     *string    `binding:"omitempty,uuid"`
+	Root          *string    `binding:"omitempty,min=1,max=30"`
+	Group         []string   `binding:"max=5,dive,max=100"`
+	Relation      []string   `binding:"omitempty,dive,max=100"`
+	Phase         []string   `binding:"omitempty,dive,max=100"`
+	Component     *string    `binding:"omitempty,min=1,max=100"`
+	Take          *string    `binding:"omitempty,len=30"`
+	Intent        []string   `binding:"omitempty,dive,oneof=wip publish final"`
+	Tags          []string   `binding:"omitempty,max=20,dive,min=1,max=50"`
+	ModifiedSince *time.Time ``
+	// SubmittedFrom, SubmittedTo, ModifiedFrom and ModifiedTo filter by submission and
+	// modification time, with inclusive lower and exclusive upper bounds.
+	SubmittedFrom *time.Time ``
+	SubmittedTo   *time.Time ``
+	ModifiedFrom  *time.Time ``
+	ModifiedTo    *time.Time ``
+	// GroupPrefix filters by a prefix of the group path, such as "seqA/" or "seqA/shot01".
+	GroupPrefix *string `binding:"omitempty,min=1,max=505,excludes=//"`
+	// Metadata filters by custom field values, keyed by field key.
+	Metadata map[string]string `binding:"omitempty,dive,keys,min=1,max=50,alphanumunderscore,endkeys,max=1000"`
+	*BaseListParams
+}
+
+func (ListReviewInfoParams) DefaultPerPage() int {
+	return 50
+}
+
+// GetReviewParams selects a record either by its numeric ID or by its ULID.
+type GetReviewParams struct {
+	Project string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID      int32   `binding:"required_without=UID"`
+	UID     *string `binding:"omitempty,len=26,alphanum,uppercase"`
+}
+
+type CreateReviewInfoParams struct {
+	Project   string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	CreatedBy *string `binding:"omitempty,min=1,max=100"`
+
+	TaskID                    string              `binding:"uuid"`
+	SubtaskID                 string              `binding:"uuid"`
+	Studio                    string              `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ProjectPath               string              ``
+	ReviewComments            []*libs.CommentInfo `binding:"required"`
+	TakePath                  string              `binding:"required"`
+	Root                      string              `binding:"min=1"`
+	Groups                    []string            `binding:"min=1,max=5,dive,max=100"`
+	Relation                  string              `binding:"min=1"`
+	Phase                     string              `binding:"min=1"`
+	Component                 string              `binding:"min=1"`
+	Take                      string              `binding:"len=30"`
+	Intent                    *string             `binding:"omitempty,oneof=wip publish final"`
+	ApprovalStatus            string              `binding:"min=1"`
+	ApprovalStatusUpdatedUser string              `binding:"min=1"`
+	WorkStatus                string              `binding:"min=1"`
+	WorkStatusUpdatedUser     string              `binding:"min=1"`
+	ReviewTarget              []*libs.Content     `binding:"required"`
+	ReviewData                []*libs.Content     `binding:"required"`
+	OutputContents            []*libs.Content     ``
+	SubmittedAtUtc            time.Time           `binding:"required"`
+	SubmittedComputer         string              `binding:"min=1,max=30"`
+	SubmittedOS               string              `binding:"len=3"`
+	SubmittedOSVersion        string              `binding:"min=1,max=1000"`
+	SubmittedUser             string              `binding:"min=1,max=100"`
+	ExecutedAtUtc             time.Time           `binding:"required"`
+	ExecutedComputer          string              `binding:"min=1,max=30"`
+	ExecutedOS                string              `binding:"len=3"`
+	ExecutedOSVersion         string              `binding:"min=1,max=1000"`
+	ExecutedUser              string              `binding:"min=1,max=100"`
+	AllFiles                  []*libs.File        ``
+	NumAllFiles               uint32              ``
+	SizeAllFiles              uint64              ``
+	TargetComponents          []string            ``
+	Metadata                  JSONObject          ``
+	// ManifestPending creates the review before its AllFiles manifest, which is uploaded later
+	// with UpdateReviewManifestParams. AllFiles, NumAllFiles and SizeAllFiles must be empty.
+	ManifestPending bool
+	// PublishTransactionID links the review to a publish transaction of the project by its
+	// log ID, which must exist.
+	PublishTransactionID *string `binding:"omitempty,min=1,max=36"`
+
+	Duration                    *int32
+	DurationTimeline            *string
+	ExportShotsVersions         *bool
+	ExportShotsVersionsRevision *string
+	ExportShotsVersionsPath     *string
+}
+
+// UpdateReviewManifestParams uploads the AllFiles manifest of a review created with a pending
+// manifest, from which its number and size of files are set.
+type UpdateReviewManifestParams struct {
+	Project    string       `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32        `binding:"required"`
+	AllFiles   []*libs.File `binding:"required"`
+	ModifiedBy *string      `binding:"omitempty,min=1,max=100"`
+}
+
+type UpdateReviewInfoParams struct {
+	ApprovalStatus            *string ``
+	ApprovalStatusUpdatedUser *string `binding:"omitempty,min=1,max=100"`
+	WorkStatus                *string ``
+	WorkStatusUpdatedUser     *string `binding:"omitempty,min=1,max=100"`
+	Project                   string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID                        int32   `binding:"required"`
+	ModifiedBy                *string `binding:"omitempty,min=1,max=100"`
+
+	// Metadata is merged into the current custom field values; null values remove a field.
+	Metadata JSONObject
+
+	// TakePath, Duration and ReviewTarget correct the submission. They are restricted to admin
+	// studios and each change is recorded in the audit log of the review, with Studio.
+	TakePath     *string         `binding:"omitempty,min=1,max=1000"`
+	Duration     *int32          `binding:"omitempty,min=0"`
+	ReviewTarget []*libs.Content ``
+	Studio       string          ``
+
+	// Version and UnmodifiedSince are the preconditions of the update, which is rejected with a
+	// StaleReviewInfoError when the review has changed since.
+	Version         *uint32    ``
+	UnmodifiedSince *time.Time ``
+}
+
+// StaleReviewInfoError is the ErrConflict of an update whose precondition no longer holds,
+// with the current review.
+type StaleReviewInfoError struct {
+	Current *ReviewInfo
+}
+
+func (e *StaleReviewInfoError) Error() string {
+	return fmt.Sprintf(
+		"%s: review info with ID %d was modified at %s by %q (version %d)",
+		ErrConflict, e.Current.ID, e.Current.ModifiedAtUTC.Format(time.RFC3339Nano),
+		e.Current.ModifiedBy, e.Current.Version,
+	)
+}
+
+func (e *StaleReviewInfoError) Unwrap() error {
+	return ErrConflict
+}
+
+// HasCorrections tells whether the update corrects fields of the submission.
+func (p *UpdateReviewInfoParams) HasCorrections() bool {
+	return p.TakePath != nil || p.Duration != nil || p.ReviewTarget != nil
+}
+
+// ReviewInfoAuditLog records the before and after values of a corrected field of a review.
+type ReviewInfoAuditLog struct {
+	Project      string      `json:"project"`
+	ReviewInfoID int32       `json:"review_info_id"`
+	Field        string      `json:"field"`
+	Before       interface{} `json:"before"`
+	After        interface{} `json:"after"`
+	Studio       string      `json:"studio"`
+	CreatedAtUTC time.Time   `json:"created_at_utc"`
+	CreatedBy    string      `json:"created_by"`
+	ID           int32       `json:"id"`
+}
+
+type ListReviewInfoAuditLogsParams struct {
+	Project      string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ReviewInfoID int32  `binding:"required"`
+}
+
+// ReviewLatestState tells when the latest reviews of a project were last rebuilt from the
+// reviews, and how many rows were materialized.
+type ReviewLatestState struct {
+	Project      string    `json:"project"`
+	Rows         int64     `json:"rows"`
+	RebuiltAtUTC time.Time `json:"rebuilt_at_utc"`
+	RebuiltBy    string    `json:"rebuilt_by"`
+}
+
+type RebuildReviewLatestParams struct {
+	Project   string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	RebuiltBy string `binding:"max=100"`
+}
+
+type DeleteReviewInfoParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// RestoreReviewInfoParams undoes the deletion of a review.
+type RestoreReviewInfoParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// ListDeletedReviewInfosParams lists the deleted reviews of a project, deleted before
+// DeletedBefore when it is set, the latest deleted first.
+type ListDeletedReviewInfosParams struct {
+	Project       string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	DeletedBefore *time.Time
+	*BaseListParams
+}
+
+// DefaultReviewPurgeRetentionDays is how long the deleted reviews are kept by default.
+const DefaultReviewPurgeRetentionDays = 90
+
+// PurgeReviewInfosParams removes for good the reviews of a project deleted more than
+// RetentionDays days ago. A dry run only counts them.
+type PurgeReviewInfosParams struct {
+	Project       string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	RetentionDays int32  `binding:"min=1,max=3650"`
+	DryRun        bool
+	PurgedBy      string `binding:"max=100"`
+}
+
+// PurgeReviewInfosResult is the number of the reviews removed for good, or which would be by a
+// dry run, with their status logs, audit logs, file hashes, transcodes and SLA breaches.
+type PurgeReviewInfosResult struct {
+	Project       string    `json:"project"`
+	DeletedBefore time.Time `json:"deleted_before"`
+	Purged        int64     `json:"purged"`
+	DryRun        bool      `json:"dry_run"`
+}
+
+type Asset struct {
+	Name     string `json:"name"`
+	Relation string `json:"relation"`
+}
+
+type AssetListParams struct {
+	Project string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Studio  *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	*BaseListParams
+}
+
+type AssetReviewInfoListParams struct {
+	Project  string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Studio   *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset    string  `binding:"omitempty,min=1,alphanumunderscore,startsnotwithdigit"`
+	Relation string  `binding:"min=1,max=100,startsnotwithdot"`
+}
+
+// TakeReviewInfoListParams selects the reviews of a take of a phase of an asset, one per
+// submitted component.
+type TakeReviewInfoListParams struct {
+	Project  string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset    string `binding:"min=1,alphanumunderscore,startsnotwithdigit"`
+	Relation string `binding:"min=1,max=100,startsnotwithdot"`
+	Phase    string `binding:"min=1,max=20"`
+	Take     string `binding:"min=1,max=30"`
+}
+
+// PublishTransactionReviewInfoListParams selects the reviews linked to a publish transaction,
+// the latest first.
+type PublishTransactionReviewInfoListParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	LogID   string `binding:"min=1,max=36"`
+	*BaseListParams
+}
+
+// PublishTransactionSummary is the publish transaction of a log ID, as linked to reviews.
+type PublishTransactionSummary struct {
+	LogID        string    `json:"log_id"`
+	Project      string    `json:"project"`
+	Studio       string    `json:"studio"`
+	RevisionPath string    `json:"revision_path"`
+	Operation    string    `json:"operation"`
+	Event        string    `json:"event"`
+	User         *string   `json:"user"`
+	Computer     *string   `json:"computer"`
+	CreatedAtUTC time.Time `json:"created_at_utc"`
+	CreatedBy    *string   `json:"created_by"`
+}
+
+type GetPublishTransactionSummaryParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	LogID   string `binding:"min=1,max=36"`
+}
+
+type ShotReviewInfoListParams struct {
+	Project  string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Studio   *string  `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Groups   []string `binding:"min=1,max=5,dive,max=100"`
+	Relation string   `binding:"min=1,max=100,startsnotwithdot"`
+}
+
+type ReviewIntentSetting struct {
+	Project         string    `json:"project"`
+	ExcludedIntents []string  `json:"excluded_intents"`
+	ModifiedAtUTC   time.Time `json:"modified_at_utc"`
+	ModifiedBy      string    `json:"modified_by"`
+}
+
+type GetReviewIntentSettingParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type UpdateReviewIntentSettingParams struct {
+	Project         string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ExcludedIntents []string `binding:"max=3,dive,oneof=wip publish final"`
+	ModifiedBy      *string  `binding:"omitempty,min=1,max=100"`
+}
+
+type ReviewApprovalGate struct {
+	Project       string    `json:"project"`
+	Phase         string    `json:"phase"`
+	Mode          string    `json:"mode"`
+	ModifiedAtUTC time.Time `json:"modified_at_utc"`
+	ModifiedBy    string    `json:"modified_by"`
+}
+
+type ListReviewApprovalGatesParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type UpdateReviewApprovalGateParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Phase      string  `binding:"min=1,max=30"`
+	Mode       string  `binding:"oneof=off warn block"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// UpstreamReview is a content the review depends on, with the approval status of its latest
+// review. ReviewInfoID is nil when the content has never been reviewed.
+type UpstreamReview struct {
+	Root           string `json:"root"`
+	Group          string `json:"group"`
+	Relation       string `json:"relation"`
+	Phase          string `json:"phase"`
+	ApprovalStatus string `json:"approval_status"`
+	ReviewInfoID   *int32 `json:"review_info_id"`
+}
+
+// ApprovalGateResult is the outcome of checking the upstream dependencies of an approval.
+type ApprovalGateResult struct {
+	Mode       string            `json:"mode"`
+	Unapproved []*UpstreamReview `json:"unapproved"`
+}
+
+// ApprovalGateError is the ErrConflict of an approval blocked by the gate of its phase, with
+// the unapproved upstream dependencies.
+type ApprovalGateError struct {
+	ID         int32
+	Unapproved []*UpstreamReview
+}
+
+func (e *ApprovalGateError) Error() string {
+	return fmt.Sprintf(
+		"%s: %d upstream dependencies of review info with ID %d are not approved",
+		ErrConflict, len(e.Unapproved), e.ID,
+	)
+}
+
+func (e *ApprovalGateError) Unwrap() error {
+	return ErrConflict
+}
+
+// Formats of the sequence rollup.
+const (
+	SequenceRollupFormatJSON = "json"
+	SequenceRollupFormatCSV  = "csv"
+)
+
+// PhaseRollup counts the shots of a sequence per status of their latest review of a phase.
+// Submitted is the number of shots having a review of the phase, and the percentages are of
+// all the shots of the sequence.
+type PhaseRollup struct {
+	Phase            string         `json:"phase"`
+	Submitted        int            `json:"submitted"`
+	Approved         int            `json:"approved"`
+	SubmittedPercent float64        `json:"submitted_percent"`
+	ApprovedPercent  float64        `json:"approved_percent"`
+	ApprovalStatuses map[string]int `json:"approval_statuses"`
+	WorkStatuses     map[string]int `json:"work_statuses"`
+}
+
+// SequenceRollup is the status of the shots of a sequence, group_2 of the shots root, per
+// phase. A shot is a group_1, group_3 and relation of the sequence having a review. DataAsOf
+// is when the counts were read from the database.
+type SequenceRollup struct {
+	Project  string         `json:"project"`
+	Episode  *string        `json:"episode,omitempty"`
+	Sequence string         `json:"sequence"`
+	Shots    int            `json:"shots"`
+	Phases   []*PhaseRollup `json:"phases"`
+	DataAsOf time.Time      `json:"data_as_of"`
+}
+
+// GetSequenceRollupParams selects the shots of the sequence, in the episode (group_1) when
+// given. A cached rollup older than MaxStaleness is counted again.
+type GetSequenceRollupParams struct {
+	Project      string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Episode      *string        `binding:"omitempty,min=1,max=100"`
+	Sequence     string         `binding:"min=1,max=100"`
+	Format       string         `binding:"oneof=json csv" json:"-"`
+	MaxStaleness *time.Duration `json:"-"`
+}
@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+// SLA states reported per phase in the asset pivot.
+const (
+	SLAStateOK       = "ok"
+	SLAStateBreached = "breached"
+)
+
+// ReviewSLA requires supervisor feedback, i.e. any review status log, within FeedbackWithinHours
+// of the submission of a review of the phase. Only reviews submitted after the SLA was created
+// are evaluated.
+type ReviewSLA struct {
+	Project             string    `json:"project"`
+	Phase               string    `json:"phase"`
+	FeedbackWithinHours int32     `json:"feedback_within_hours"`
+	MailAddresses       []string  `json:"mail_addresses"`
+	CreatedAtUTC        time.Time `json:"created_at_utc"`
+	ModifiedAtUTC       time.Time `json:"modified_at_utc"`
+	ModifiedBy          string    `json:"modified_by"`
+	CreatedBy           string    `json:"created_by"`
+	ID                  int32     `json:"id"`
+}
+
+type ListReviewSLAsParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type UpdateReviewSLAParams struct {
+	Project             string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Phase               string   `binding:"min=1,max=30"`
+	FeedbackWithinHours int32    `binding:"min=1,max=8760"`
+	MailAddresses       []string `binding:"max=50,dive,email"`
+	ModifiedBy          *string  `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteReviewSLAParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Phase      string  `binding:"min=1,max=30"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// ReviewSLABreach is recorded by the SLA evaluator when a review did not get feedback in time.
+// ResolvedAtUTC is set once the review gets its first feedback.
+type ReviewSLABreach struct {
+	Project        string     `json:"project"`
+	ReviewInfoID   int32      `json:"review_info_id"`
+	Root           string     `json:"root"`
+	Group          string     `json:"group"`
+	Relation       string     `json:"relation"`
+	Phase          string     `json:"phase"`
+	Take           string     `json:"take"`
+	SubmittedAtUTC time.Time  `json:"submitted_at_utc"`
+	DueAtUTC       time.Time  `json:"due_at_utc"`
+	DetectedAtUTC  time.Time  `json:"detected_at_utc"`
+	ResolvedAtUTC  *time.Time `json:"resolved_at_utc"`
+	ID             int32      `json:"id"`
+}
+
+type ListReviewSLABreachesParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Phase      *string `binding:"omitempty,min=1,max=30"`
+	Unresolved bool
+	*BaseListParams
+}
+
+// SLABreachNotification is sent to the leads of a phase when the evaluator detects breaches.
+type SLABreachNotification struct {
+	Project       string
+	Phase         string
+	MailAddresses []string
+	Breaches      []*ReviewSLABreach
+}
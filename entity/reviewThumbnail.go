@@ -0,0 +1,75 @@
+package entity
+
+import "time"
+
+type GetAssetThumbnailParams struct {
+	Project  string `binding:"required,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset    string `binding:"required,min=1,max=100,alphanumunderscore"`
+	Relation string `binding:"required,min=1,max=30,alphanumunderscore"`
+}
+
+type GetShotThumbnailParams struct {
+	Project  string `binding:"required,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Group1   string `binding:"required,min=1,max=100,alphanumunderscore"`
+	Group2   string `binding:"required,min=1,max=100,alphanumunderscore"`
+	Group3   string `binding:"required,min=1,max=100,alphanumunderscore"`
+	Relation string `binding:"required,min=1,max=30,alphanumunderscore"`
+}
+
+// AssetThumbnailKey identifies one asset/relation pair in a batch thumbnail lookup.
+type AssetThumbnailKey struct {
+	Asset    string `json:"asset" binding:"required,min=1,max=100,alphanumunderscore"`
+	Relation string `json:"relation" binding:"required,min=1,max=30,alphanumunderscore"`
+}
+
+// BatchGetAssetThumbnailsParams is used by the pivot page to resolve the thumbnails of all
+// visible assets in a single request.
+type BatchGetAssetThumbnailsParams struct {
+	Project string              `binding:"required,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Keys    []AssetThumbnailKey `binding:"required,min=1,max=100,dive"`
+	Inline  bool
+	// Studio is recorded in the accesses to the encrypted thumbnails.
+	Studio string
+}
+
+// AssetThumbnail is one entry of a batch thumbnail response. URL is empty when the asset has
+// no thumbnail yet. Preview holds a base64 data URI of small thumbnails when inline previews
+// were requested. Pinned tells whether the thumbnail was pinned.
+type AssetThumbnail struct {
+	Asset    string  `json:"asset"`
+	Relation string  `json:"relation"`
+	URL      string  `json:"url"`
+	Preview  *string `json:"preview,omitempty"`
+	Pinned   bool    `json:"pinned,omitempty"`
+}
+
+// ThumbnailPin pins the thumbnail file Name of the revision Revision of a phase as the
+// representative image of an asset, in place of the latest thumbnail. Revision is the name of
+// the thumbnail directory of a take, like 20240515.s001r0002.
+type ThumbnailPin struct {
+	Project     string    `json:"project"`
+	Asset       string    `json:"asset"`
+	Relation    string    `json:"relation"`
+	Phase       string    `json:"phase"`
+	Revision    string    `json:"revision"`
+	Name        string    `json:"name"`
+	PinnedAtUTC time.Time `json:"pinned_at_utc"`
+	PinnedBy    string    `json:"pinned_by"`
+	ID          int32     `json:"id"`
+}
+
+type PinAssetThumbnailParams struct {
+	Project  string `binding:"required,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset    string `binding:"required,min=1,max=100,alphanumunderscore"`
+	Relation string `binding:"required,min=1,max=30,alphanumunderscore"`
+	Phase    string `binding:"oneof=mdl rig bld dsn ldv"`
+	Revision string `binding:"min=1,max=50"`
+	Name     string `binding:"oneof=thumbnail_s.png thumbnail_m.png thumbnail_l.png animated.gif"`
+	PinnedBy string `binding:"min=1,max=100"`
+}
+
+type UnpinAssetThumbnailParams struct {
+	Project  string `binding:"required,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset    string `binding:"required,min=1,max=100,alphanumunderscore"`
+	Relation string `binding:"required,min=1,max=30,alphanumunderscore"`
+}
@@ -0,0 +1,69 @@
+package entity
+
+import "time"
+
+type TranscodeStatus string
+
+const (
+	TranscodeQueued    TranscodeStatus = "queued"
+	TranscodeRunning   TranscodeStatus = "running"
+	TranscodeCompleted TranscodeStatus = "completed"
+	TranscodeFailed    TranscodeStatus = "failed"
+)
+
+// Done tells whether the transcode will not change anymore.
+func (s TranscodeStatus) Done() bool {
+	return s == TranscodeCompleted || s == TranscodeFailed
+}
+
+// ReviewTranscode generates a proxy movie of an image sequence of the review data, which is
+// registered as review data of the review once completed. Paths are relative to the project
+// like the paths of the review data.
+type ReviewTranscode struct {
+	Project        string          `json:"project"`
+	ReviewInfoID   int32           `json:"review_info_id"`
+	Source         string          `json:"source"`
+	Frames         []string        `json:"-"`
+	Output         string          `json:"output"`
+	Status         TranscodeStatus `json:"status"`
+	Backend        string          `json:"backend"`
+	JobID          *string         `json:"job_id"`
+	Error          *string         `json:"error"`
+	SubmittedAtUTC *time.Time      `json:"submitted_at_utc"`
+	CompletedAtUTC *time.Time      `json:"completed_at_utc"`
+	CreatedAtUTC   time.Time       `json:"created_at_utc"`
+	ModifiedAtUTC  time.Time       `json:"modified_at_utc"`
+	ModifiedBy     string          `json:"modified_by"`
+	CreatedBy      string          `json:"created_by"`
+	ID             int32           `json:"id"`
+}
+
+type ListReviewTranscodesParams struct {
+	Project      string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ReviewInfoID int32  `binding:"required"`
+}
+
+// CreateReviewTranscodeParams requests the transcode of the image sequence Source of the
+// review data, or of its first image sequence when Source is not given.
+type CreateReviewTranscodeParams struct {
+	Project      string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ReviewInfoID int32   `binding:"required"`
+	Source       *string `binding:"omitempty,min=1,max=1000"`
+	CreatedBy    *string `binding:"omitempty,min=1,max=100"`
+}
+
+// TranscodeJob is the job submitted to a transcoder backend. The frames are ordered.
+type TranscodeJob struct {
+	ID      int32    `json:"id"`
+	Project string   `json:"project"`
+	Frames  []string `json:"frames"`
+	Output  string   `json:"output"`
+}
+
+// TranscodeJobState is the state of a job reported by a transcoder backend. Size is the size
+// of the output once completed.
+type TranscodeJobState struct {
+	Status TranscodeStatus `json:"status"`
+	Error  string          `json:"error"`
+	Size   uint64          `json:"size"`
+}
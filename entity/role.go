@@ -0,0 +1,120 @@
+package entity
+
+import "time"
+
+// UserHeader may name the user acting through a token or an API key. It is not trusted: a
+// request whose header is not the authenticated user is rejected.
+const UserHeader = "X-User"
+
+// UserContextKey is the key of the gin context the authenticated user is set at, from the
+// claims of the token or the name of the API key. The routes requiring a permission check
+// the roles of this user.
+const UserContextKey = "user"
+
+type Permission string
+
+const (
+	PermissionReviewRead    Permission = "review:read"
+	PermissionReviewSubmit  Permission = "review:submit"
+	PermissionReviewApprove Permission = "review:approve"
+	PermissionProjectManage Permission = "project:manage"
+)
+
+// Permissions are all the permissions roles may grant.
+var Permissions = []Permission{
+	PermissionReviewRead,
+	PermissionReviewSubmit,
+	PermissionReviewApprove,
+	PermissionProjectManage,
+}
+
+// The built-in roles, which are created with their default permissions and cannot be deleted.
+const (
+	RoleAdmin      = "admin"
+	RoleSupervisor = "supervisor"
+	RoleArtist     = "artist"
+	RoleViewer     = "viewer"
+)
+
+// BuiltInRoles are the default permissions of the built-in roles.
+var BuiltInRoles = map[string][]Permission{
+	RoleAdmin:      Permissions,
+	RoleSupervisor: {PermissionReviewRead, PermissionReviewSubmit, PermissionReviewApprove},
+	RoleArtist:     {PermissionReviewRead, PermissionReviewSubmit},
+	RoleViewer:     {PermissionReviewRead},
+}
+
+// PermissionRequirement is the permission a route requires of the authenticated user. When
+// Fields is set, only the requests whose JSON body sets any of them require it.
+type PermissionRequirement struct {
+	Permission Permission
+	Fields     []string
+}
+
+// Role is a set of permissions granted to the users it is assigned to in a project.
+type Role struct {
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Permissions   []Permission `json:"permissions"`
+	BuiltIn       bool         `json:"built_in"`
+	CreatedAtUTC  time.Time    `json:"created_at_utc"`
+	ModifiedAtUTC time.Time    `json:"modified_at_utc"`
+	ModifiedBy    string       `json:"modified_by"`
+	CreatedBy     string       `json:"created_by"`
+}
+
+// UserRole assigns a role to a user in a project.
+type UserRole struct {
+	Project      string     `json:"project"`
+	User         string     `json:"user"`
+	Role         string     `json:"role"`
+	ExpiresAtUTC *time.Time `json:"expires_at_utc"`
+	CreatedAtUTC time.Time  `json:"created_at_utc"`
+	CreatedBy    string     `json:"created_by"`
+	ID           int32      `json:"id"`
+}
+
+type GetRoleParams struct {
+	Name string `binding:"min=1,max=30,alphanumunderscore,lowercase"`
+}
+
+// UpdateRoleParams creates the role or replaces its description and permissions.
+type UpdateRoleParams struct {
+	Name        string       `binding:"min=1,max=30,alphanumunderscore,lowercase"`
+	Description string       `binding:"max=4000"`
+	Permissions []Permission `binding:"dive,oneof=review:read review:submit review:approve project:manage"`
+	ModifiedBy  string       `binding:"min=1,max=100"`
+}
+
+type DeleteRoleParams struct {
+	Name string `binding:"min=1,max=30,alphanumunderscore,lowercase"`
+}
+
+type ListUserRolesParams struct {
+	Role    *string `binding:"omitempty,min=1,max=30,alphanumunderscore,lowercase"`
+	Project *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	User    *string `binding:"omitempty,min=1,max=100"`
+	*BaseListParams
+}
+
+type CreateUserRoleParams struct {
+	Role    string `binding:"min=1,max=30,alphanumunderscore,lowercase"`
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	User    string `binding:"min=1,max=100"`
+	// ExpiresAtUTC ends the assignment when set.
+	ExpiresAtUTC *time.Time
+	CreatedBy    string `binding:"min=1,max=100"`
+}
+
+type DeleteUserRoleParams struct {
+	Role string `binding:"min=1,max=30,alphanumunderscore,lowercase"`
+	ID   int32  `binding:"min=1"`
+}
+
+// CheckPermissionParams asks whether the roles of the user in the project grant the
+// permission.
+type CheckPermissionParams struct {
+	Project    string     `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	User       string     `binding:"max=100"`
+	Permission Permission `binding:"required"`
+}
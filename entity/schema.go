@@ -0,0 +1,78 @@
+package entity
+
+// JSONSchemaDraft is the JSON Schema version of the generated schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Keys of the Preference pipeline settings defining the review statuses, as in
+// "/ppip/reviews/approvalStatus/statuses/approved/displayName".
+const (
+	ReviewStatusKeyPrefixFormat = "/ppip/reviews/%s/statuses/"
+	ReviewStatusKeySuffix       = "/displayName"
+)
+
+// RequestSchema is the subset of JSON Schema used to describe request bodies.
+type RequestSchema struct {
+	Schema               string                    `json:"$schema,omitempty"`
+	Title                string                    `json:"title,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	Items                *RequestSchema            `json:"items,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	Properties           map[string]*RequestSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *bool                     `json:"additionalProperties,omitempty"`
+}
+
+type GetReviewInfoSchemaParams struct {
+	Project *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+// ReviewInfoSchemaExtensions are the parts of the review info schema defined by the pipeline
+// settings and the custom fields of a project. The statuses are empty when none is defined.
+type ReviewInfoSchemaExtensions struct {
+	CustomFields     []*CustomFieldDefinition
+	ApprovalStatuses []string
+	WorkStatuses     []string
+}
+
+// MetadataSchema describes the custom metadata accepted for the field definitions, following
+// MergeCustomMetadata.
+func MetadataSchema(defs []*CustomFieldDefinition) *RequestSchema {
+	additional := false
+	maxLength := 1000
+	s := &RequestSchema{
+		Type:                 "object",
+		Properties:           map[string]*RequestSchema{},
+		AdditionalProperties: &additional,
+	}
+	for _, d := range defs {
+		p := &RequestSchema{
+			Title: d.DisplayName,
+		}
+		switch d.Type {
+		case CustomFieldNumber:
+			p.Type = "number"
+		case CustomFieldDate:
+			p.Type = "string"
+			p.Format = "date"
+		case CustomFieldEnum:
+			p.Type = "string"
+			p.Enum = d.EnumValues
+		default:
+			p.Type = "string"
+			p.MaxLength = &maxLength
+		}
+		s.Properties[d.Key] = p
+		if d.Required {
+			s.Required = append(s.Required, d.Key)
+		}
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package entity
+
+// SeedParams configure the synthetic data generated for load testing. The same parameters
+// with the same Seed always generate the same data.
+type SeedParams struct {
+	// Prefix of the generated project names, which are the prefix followed by a number.
+	Prefix           string `json:"prefix" binding:"min=1,max=20,alphanum,lowercase,startsnotwithdigit"`
+	Projects         int    `json:"projects" binding:"min=1,max=20"`
+	AssetsPerProject int    `json:"assets_per_project" binding:"min=1,max=10000"`
+	// ReviewsPerPhase is the average number of submissions of an asset in a phase it reached.
+	ReviewsPerPhase      int `json:"reviews_per_phase" binding:"min=1,max=20"`
+	CategoriesPerProject int `json:"categories_per_project" binding:"min=0,max=200"`
+	// DependencyRatio is the share of the built assets depending on other assets.
+	DependencyRatio float64 `json:"dependency_ratio" binding:"min=0,max=1"`
+	// Days is the period, ending now, over which the submissions are spread.
+	Days   int    `json:"days" binding:"min=1,max=3650"`
+	Studio string `json:"studio" binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Seed   int64  `json:"seed"`
+	// Reset deletes the previously seeded data of the generated projects first.
+	Reset     bool    `json:"reset"`
+	CreatedBy *string `json:"-" binding:"omitempty,min=1,max=100"`
+}
+
+// DefaultSeedParams returns the parameters of a small but representative data set.
+func DefaultSeedParams() *SeedParams {
+	return &SeedParams{
+		Prefix:               "seed",
+		Projects:             1,
+		AssetsPerProject:     500,
+		ReviewsPerPhase:      3,
+		CategoriesPerProject: 10,
+		DependencyRatio:      0.3,
+		Days:                 180,
+		Studio:               "ppi",
+		Seed:                 1,
+	}
+}
+
+// SeedContent identifies a generated review content in the data dependency graph.
+type SeedContent struct {
+	Project   string
+	Root      string
+	Group     string
+	Relation  string
+	Phase     string
+	Component string
+	Take      string
+	FileName  string
+}
+
+// SeedDependency is a generated dependency of a content on an upstream content.
+type SeedDependency struct {
+	Content  *SeedContent
+	Upstream *SeedContent
+}
+
+type SeedResult struct {
+	Seed         int64    `json:"seed"`
+	Projects     []string `json:"projects"`
+	Categories   int      `json:"categories"`
+	Groups       int      `json:"groups"`
+	Assets       int      `json:"assets"`
+	Reviews      int      `json:"reviews"`
+	Dependencies int      `json:"dependencies"`
+	// DependenciesSkipped is true when the dependencies were generated but not stored,
+	// the data dependency graph not being configured.
+	DependenciesSkipped bool `json:"dependencies_skipped"`
+}
@@ -0,0 +1,193 @@
+package entity
+
+import "time"
+
+type SettingChangesetStatus string
+
+const (
+	SettingChangesetDraft      SettingChangesetStatus = "draft"
+	SettingChangesetScheduled  SettingChangesetStatus = "scheduled"
+	SettingChangesetPublished  SettingChangesetStatus = "published"
+	SettingChangesetRolledBack SettingChangesetStatus = "rolledBack"
+)
+
+type SettingChangeOperation string
+
+const (
+	SettingChangeSet    SettingChangeOperation = "set"
+	SettingChangeDelete SettingChangeOperation = "delete"
+)
+
+// SettingChange is a pending edit of a config or preference value. PreviousValue and
+// PreviousExists hold the live value replaced by the publish, to be restored by a rollback.
+type SettingChange struct {
+	ID             int32                  `json:"id"`
+	Group          PipelineSettingGroup   `json:"group"`
+	Key            string                 `json:"key"`
+	Operation      SettingChangeOperation `json:"operation"`
+	Value          interface{}            `json:"value"`
+	PreviousValue  interface{}            `json:"previous_value"`
+	PreviousExists bool                   `json:"previous_exists"`
+	Encrypted      bool                   `json:"encrypted"`
+	CreatedBy      *string                `json:"created_by"`
+	CreatedAtUTC   *time.Time             `json:"created_at_utc"`
+	ModifiedBy     *string                `json:"modified_by"`
+	ModifiedAtUTC  *time.Time             `json:"modified_at_utc"`
+}
+
+// Mask hides the values of the change when its property is encrypted.
+func (c *SettingChange) Mask() {
+	if !c.Encrypted {
+		return
+	}
+	if c.Value != nil {
+		c.Value = MaskedSettingValue
+	}
+	if c.PreviousValue != nil {
+		c.PreviousValue = MaskedSettingValue
+	}
+}
+
+// SettingChangeset groups the edits of the values of a section, which go live together when
+// it is published.
+type SettingChangeset struct {
+	ID              int32                  `json:"id"`
+	Section         string                 `json:"section"`
+	Entry           string                 `json:"entry"`
+	Title           string                 `json:"title"`
+	Description     string                 `json:"description"`
+	Status          SettingChangesetStatus `json:"status"`
+	PublishAtUTC    *time.Time             `json:"publish_at_utc"`
+	PublishedAtUTC  *time.Time             `json:"published_at_utc"`
+	PublishedBy     *string                `json:"published_by"`
+	RolledBackAtUTC *time.Time             `json:"rolled_back_at_utc"`
+	RolledBackBy    *string                `json:"rolled_back_by"`
+	Changes         []*SettingChange       `json:"changes,omitempty"`
+	CreatedBy       *string                `json:"created_by"`
+	CreatedAtUTC    *time.Time             `json:"created_at_utc"`
+	ModifiedBy      *string                `json:"modified_by"`
+	ModifiedAtUTC   *time.Time             `json:"modified_at_utc"`
+}
+
+// SettingChangeDiff compares a change with the live value it would replace.
+type SettingChangeDiff struct {
+	Group         PipelineSettingGroup   `json:"group"`
+	Key           string                 `json:"key"`
+	Operation     SettingChangeOperation `json:"operation"`
+	Current       interface{}            `json:"current"`
+	CurrentExists bool                   `json:"current_exists"`
+	Proposed      interface{}            `json:"proposed"`
+	Changed       bool                   `json:"changed"`
+	Encrypted     bool                   `json:"encrypted"`
+}
+
+// SettingChangesetSection is the section whose values are edited by a changeset. Exactly one
+// of the names is set.
+type SettingChangesetSection struct {
+	Common  *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Studio  *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Project *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+func (s *SettingChangesetSection) Section() PipelineSettingSection {
+	if s.Project != nil && *s.Project != "" {
+		return ProjectSection
+	}
+	if s.Studio != nil && *s.Studio != "" {
+		return StudioSection
+	}
+	if s.Common != nil && *s.Common != "" {
+		return CommonSection
+	}
+	return 0
+}
+
+func (s *SettingChangesetSection) Name() string {
+	switch s.Section() {
+	case ProjectSection:
+		return *s.Project
+	case StudioSection:
+		return *s.Studio
+	case CommonSection:
+		return *s.Common
+	}
+	return ""
+}
+
+type ListSettingChangesetsParams struct {
+	SettingChangesetSection
+	Status *SettingChangesetStatus `binding:"omitempty,oneof=draft scheduled published rolledBack"`
+	*BaseListParams
+}
+
+type GetSettingChangesetParams struct {
+	SettingChangesetSection
+	ID int32 `binding:"min=1"`
+}
+
+type CreateSettingChangesetParams struct {
+	SettingChangesetSection
+	Title       string  `binding:"min=1,max=255"`
+	Description string  `binding:"max=4000"`
+	CreatedBy   *string `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteSettingChangesetParams struct {
+	SettingChangesetSection
+	ID         int32   `binding:"min=1"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// PutSettingChangeParams adds the change of the key to a draft, replacing the previous change
+// of the same key.
+type PutSettingChangeParams struct {
+	SettingChangesetSection
+	ChangesetID int32                  `binding:"min=1"`
+	Group       PipelineSettingGroup   `binding:"oneof=1 3"`
+	Key         string                 `binding:"min=1,max=255"`
+	Operation   SettingChangeOperation `binding:"oneof=set delete"`
+	Value       interface{}            `binding:"required_if=Operation set"`
+	CreatedBy   *string                `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteSettingChangeParams struct {
+	SettingChangesetSection
+	ChangesetID int32   `binding:"min=1"`
+	ChangeID    int32   `binding:"min=1"`
+	ModifiedBy  *string `binding:"omitempty,min=1,max=100"`
+}
+
+// PublishSettingChangesetParams publishes a draft now, or schedules it when PublishAtUTC is in
+// the future.
+type PublishSettingChangesetParams struct {
+	SettingChangesetSection
+	ID           int32      `binding:"min=1"`
+	PublishAtUTC *time.Time ``
+	PublishedBy  *string    `binding:"omitempty,min=1,max=100"`
+}
+
+type RollbackSettingChangesetParams struct {
+	SettingChangesetSection
+	ID           int32   `binding:"min=1"`
+	RolledBackBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// SettingChangesetNotification is the change event emitted once per publish or rollback of a
+// changeset.
+type SettingChangesetNotification struct {
+	ChangesetID int32
+	Title       string
+	Section     string
+	Entry       string
+	Status      SettingChangesetStatus
+	Actor       string
+	Changes     []*SettingChangeSummary
+}
+
+// SettingChangeSummary names a change in the event. Group is its name, since
+// PipelineSettingGroup is not decoded from JSON.
+type SettingChangeSummary struct {
+	Group     string
+	Key       string
+	Operation SettingChangeOperation
+}
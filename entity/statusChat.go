@@ -0,0 +1,68 @@
+package entity
+
+import "strings"
+
+// StatusChatChannelsKey is the key of the config pipeline setting of a project listing its
+// status chat channels, e.g.
+//
+//	[{"provider": "slack", "webhook": "https://hooks.slack.com/...", "phases": ["mdl", "rig"]},
+//	 {"provider": "teams", "webhook": "https://example.webhook.office.com/...",
+//	  "statuses": ["retake"]}]
+const StatusChatChannelsKey = "statusChatChannels"
+
+type StatusChatProvider string
+
+const (
+	StatusChatSlack StatusChatProvider = "slack"
+	StatusChatTeams StatusChatProvider = "teams"
+)
+
+// DefaultStatusChatStatuses are the approval statuses posted by the channels which set no
+// Statuses.
+var DefaultStatusChatStatuses = []string{"approved", "retake"}
+
+// StatusChatChannel is a Slack or MS Teams incoming webhook to which the approval status
+// transitions of the assets of a project are posted. It receives the transitions of all the
+// phases unless Phases is set.
+type StatusChatChannel struct {
+	Provider StatusChatProvider `json:"provider"`
+	Webhook  string             `json:"webhook"`
+	Phases   []string           `json:"phases"`
+	Statuses []string           `json:"statuses"`
+}
+
+// Receives tells whether the channel is notified of a transition of the phase to the status.
+func (c *StatusChatChannel) Receives(phase, status string) bool {
+	statuses := c.Statuses
+	if len(statuses) == 0 {
+		statuses = DefaultStatusChatStatuses
+	}
+	if !containsFold(statuses, status) {
+		return false
+	}
+	return len(c.Phases) == 0 || containsFold(c.Phases, phase)
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChatNotification is an approval status transition of a review of an asset, posted to
+// the status chat channels of its project and phase.
+type StatusChatNotification struct {
+	Project                string
+	Root                   string
+	Groups                 []string
+	Relation               string
+	Phase                  string
+	Take                   string
+	ReviewInfoID           int32
+	PreviousApprovalStatus string
+	ApprovalStatus         string
+	UpdatedUser            string
+}
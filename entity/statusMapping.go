@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// Kinds of the statuses of the reviews.
+const (
+	StatusKindApproval = "approval"
+	StatusKindWork     = "work"
+)
+
+// StatusMapping normalizes the legacy status LegacyValue of a kind, e.g. "OK", to Value of
+// the current vocabulary, e.g. "approved", for the reviews of a project. The legacy values
+// are matched case insensitively. The asset pivot, the latest submissions, the sequence
+// rollup, the upstream approvals and the pending review report read the statuses through the
+// mappings, so that the filters and counts do not miss the old reviews. A backfill rewrites
+// the stored statuses once the mappings are settled.
+type StatusMapping struct {
+	Project       string    `json:"project"`
+	Kind          string    `json:"kind"`
+	LegacyValue   string    `json:"legacy_value"`
+	Value         string    `json:"value"`
+	CreatedAtUTC  time.Time `json:"created_at_utc"`
+	ModifiedAtUTC time.Time `json:"modified_at_utc"`
+	ModifiedBy    string    `json:"modified_by"`
+	CreatedBy     string    `json:"created_by"`
+	ID            int32     `json:"id"`
+}
+
+type ListStatusMappingsParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type UpdateStatusMappingParams struct {
+	Project     string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Kind        string  `binding:"oneof=approval work"`
+	LegacyValue string  `binding:"min=1,max=20"`
+	Value       string  `binding:"min=1,max=20"`
+	ModifiedBy  *string `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteStatusMappingParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"min=1"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// BackfillStatusMappingsParams rewrites the legacy statuses stored in the reviews of a
+// project to their mapped values, or only counts them when DryRun is true.
+type BackfillStatusMappingsParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	DryRun  bool
+}
+
+// StatusBackfillResult is the number of reviews whose approval and work statuses were
+// rewritten by a backfill, or would be by a dry run.
+type StatusBackfillResult struct {
+	Project          string `json:"project"`
+	DryRun           bool   `json:"dry_run"`
+	ApprovalStatuses int64  `json:"approval_statuses"`
+	WorkStatuses     int64  `json:"work_statuses"`
+}
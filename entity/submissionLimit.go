@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSubmissionRateLimited is returned when an asset is submitted more often than the
+// submission limit of its project allows.
+var ErrSubmissionRateLimited = errors.New("submission rate limit exceeded")
+
+// SubmissionRateLimitError is the ErrSubmissionRateLimited of a submission, with the wait
+// before the asset may be submitted again.
+type SubmissionRateLimitError struct {
+	Project        string
+	Root           string
+	Groups         []string
+	Relation       string
+	MaxSubmissions int32
+	WindowMinutes  int32
+	RetryAfter     time.Duration
+}
+
+func (e *SubmissionRateLimitError) Error() string {
+	return fmt.Sprintf(
+		"%s: %v %s of %s/%s was submitted %d times in the last %d minutes",
+		ErrSubmissionRateLimited, e.Groups, e.Relation, e.Project, e.Root,
+		e.MaxSubmissions, e.WindowMinutes,
+	)
+}
+
+func (e *SubmissionRateLimitError) Unwrap() error {
+	return ErrSubmissionRateLimited
+}
+
+// SubmissionLimit limits the reviews submitted per asset of a project, of all its phases and
+// components, to MaxSubmissions per WindowMinutes. Projects without limit are unlimited.
+type SubmissionLimit struct {
+	Project        string                     `json:"project"`
+	MaxSubmissions int32                      `json:"max_submissions"`
+	WindowMinutes  int32                      `json:"window_minutes"`
+	Overrides      []*SubmissionLimitOverride `json:"overrides"`
+	CreatedAtUTC   time.Time                  `json:"created_at_utc"`
+	ModifiedAtUTC  time.Time                  `json:"modified_at_utc"`
+	ModifiedBy     string                     `json:"modified_by"`
+	CreatedBy      string                     `json:"created_by"`
+	ID             int32                      `json:"id"`
+}
+
+// SubmissionLimitOverride lifts the submission limit of a project until ExpiresAtUTC, e.g.
+// for a bulk migration, on every asset or only on the assets of the first group Asset.
+type SubmissionLimitOverride struct {
+	Project      string    `json:"project"`
+	Asset        *string   `json:"asset"`
+	Reason       string    `json:"reason"`
+	ExpiresAtUTC time.Time `json:"expires_at_utc"`
+	CreatedAtUTC time.Time `json:"created_at_utc"`
+	CreatedBy    string    `json:"created_by"`
+	ID           int32     `json:"id"`
+}
+
+type GetSubmissionLimitParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type UpdateSubmissionLimitParams struct {
+	Project        string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	MaxSubmissions int32   `binding:"min=1,max=100000"`
+	WindowMinutes  int32   `binding:"min=1,max=10080"`
+	ModifiedBy     *string `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteSubmissionLimitParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+type CreateSubmissionLimitOverrideParams struct {
+	Project      string    `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset        *string   `binding:"omitempty,min=1,max=255"`
+	Reason       string    `binding:"min=1,max=1000"`
+	ExpiresAtUTC time.Time `binding:"required"`
+	CreatedBy    *string   `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteSubmissionLimitOverrideParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"min=1"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// CheckSubmissionParams is the asset of a review about to be submitted.
+type CheckSubmissionParams struct {
+	Project  string
+	Root     string
+	Groups   []string
+	Relation string
+}
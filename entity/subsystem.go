@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Optional subsystems, without which the API starts degraded.
+const (
+	SubsystemDataDependency   = "dataDependency"
+	SubsystemDataSyncClient   = "dataSyncClient"
+	SubsystemNotificationChat = "notificationChat"
+	SubsystemNotificationMail = "notificationMail"
+)
+
+// States of the optional subsystems.
+const (
+	SubsystemUp   = "up"
+	SubsystemDown = "down"
+	// SubsystemDisabled is not configured or could not be set up at startup, and is not probed
+	// again until the API restarts.
+	SubsystemDisabled = "disabled"
+)
+
+var ErrSubsystemUnavailable = errors.New("subsystem unavailable")
+
+// Subsystem is the state of an optional subsystem as of its latest probe. Error is the failure
+// of the probe, or the reason the subsystem is disabled.
+type Subsystem struct {
+	Name         string     `json:"name"`
+	State        string     `json:"state"`
+	Error        *string    `json:"error"`
+	SinceUTC     time.Time  `json:"since_utc"`
+	CheckedAtUTC *time.Time `json:"checked_at_utc"`
+}
@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// Health statuses of the dependencies in a support bundle.
+const (
+	DependencyStatusOK       = "ok"
+	DependencyStatusDown     = "down"
+	DependencyStatusDisabled = "disabled"
+)
+
+// SupportLogEntry is an error logged by the API.
+type SupportLogEntry struct {
+	LoggedAtUTC time.Time `json:"logged_at_utc"`
+	Message     string    `json:"message"`
+}
+
+// SlowQuery is a MySQL query which took longer than the slow query threshold, or failed when
+// Error is set.
+type SlowQuery struct {
+	StartedAtUTC time.Time `json:"started_at_utc"`
+	DurationMS   int64     `json:"duration_ms"`
+	Rows         int64     `json:"rows"`
+	SQL          string    `json:"sql"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// DependencyHealth is the result of a ping of a dependency of the API.
+type DependencyHealth struct {
+	Dependency string `json:"dependency"`
+	Status     string `json:"status"`
+	LatencyMS  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SupportBundle gathers what the support team needs to investigate a bug report. The logs
+// and the queries are kept in memory by the instance which generated the bundle, since
+// BufferedSinceUTC. Secrets of the configuration are redacted, from the logs and the queries
+// too.
+type SupportBundle struct {
+	Project          *string             `json:"project"`
+	WindowSeconds    int                 `json:"window_seconds"`
+	BufferedSinceUTC time.Time           `json:"buffered_since_utc"`
+	Errors           []*SupportLogEntry  `json:"errors"`
+	SlowQueries      []*SlowQuery        `json:"slow_queries"`
+	Config           map[string]string   `json:"config"`
+	Dependencies     []*DependencyHealth `json:"dependencies"`
+	GeneratedAtUTC   time.Time           `json:"generated_at_utc"`
+	GeneratedBy      string              `json:"generated_by"`
+}
+
+// CreateSupportBundleParams bundles the last WindowSeconds, only the logs and the queries
+// mentioning Project when it is given.
+type CreateSupportBundleParams struct {
+	Project       *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	WindowSeconds int     `binding:"min=60,max=86400"`
+	CreatedBy     string  `binding:"max=100"`
+}
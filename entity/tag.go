@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+// Types of tagged targets. Targets are identified by the review ID for reviews, and by the
+// groups and relation joined by "/" for assets ("<asset>/<relation>") and shots
+// ("<group_1>/<group_2>/<group_3>/<relation>").
+const (
+	TagTargetAsset  = "asset"
+	TagTargetShot   = "shot"
+	TagTargetReview = "review"
+)
+
+type Tag struct {
+	Project       string    `json:"project"`
+	Name          string    `json:"name"`
+	NumTargets    int       `json:"num_targets"`
+	CreatedAtUTC  time.Time `json:"created_at_utc"`
+	ModifiedAtUTC time.Time `json:"modified_at_utc"`
+	ModifiedBy    string    `json:"modified_by"`
+	CreatedBy     string    `json:"created_by"`
+	ID            int32     `json:"id"`
+}
+
+// ListTagsParams lists the tags of a project by name. Query narrows it down to the tags
+// starting with it for autocompletion.
+type ListTagsParams struct {
+	Project string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Query   *string `binding:"omitempty,max=50"`
+	*BaseListParams
+}
+
+type CreateTagParams struct {
+	Project   string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Name      string  `binding:"min=1,max=50,excludesall=0x2C"`
+	CreatedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+type UpdateTagParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	Name       string  `binding:"min=1,max=50,excludesall=0x2C"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteTagParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// TagTarget is a tagged asset, shot or review with its tag names.
+type TagTarget struct {
+	Project    string   `json:"project"`
+	TargetType string   `json:"target_type"`
+	Target     string   `json:"target"`
+	Tags       []string `json:"tags"`
+}
+
+type GetTagTargetParams struct {
+	Project    string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	TargetType string `binding:"oneof=asset shot review"`
+	Target     string `binding:"min=1,max=500"`
+}
+
+// UpdateTagTargetParams attaches or detaches tags. Attaching creates the tags which do not
+// exist in the project yet.
+type UpdateTagTargetParams struct {
+	Project    string   `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	TargetType string   `binding:"oneof=asset shot review"`
+	Target     string   `binding:"min=1,max=500"`
+	Tags       []string `binding:"min=1,max=50,dive,min=1,max=50,excludesall=0x2C"`
+	ModifiedBy *string  `binding:"omitempty,min=1,max=100"`
+}
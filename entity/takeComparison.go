@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/libs"
+)
+
+type GetContactSheetParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Before  int32  `binding:"required"`
+	After   int32  `binding:"required"`
+	Frames  int    `binding:"min=1,max=8"`
+	Width   int    `binding:"min=64,max=1024"`
+}
+
+// ContactSheetTake holds the stills of a take in frame order, as paths relative to the
+// project directory, and the frames matched for the comparison.
+type ContactSheetTake struct {
+	ReviewInfoID int32
+	Stills       []string
+	Frames       []string
+}
+
+// ContactSheet is a before/after contact sheet: the matched frames of the earlier take on the
+// top row and those of the later take below. Key identifies it by the pair of takes.
+type ContactSheet struct {
+	Key  string
+	Path string
+}
+
+type CompareTakesParams struct {
+	Project  string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Asset    string `binding:"min=1,alphanumunderscore,startsnotwithdigit"`
+	Relation string `binding:"min=1,max=100,startsnotwithdot"`
+	Phase    string `binding:"min=1,max=20"`
+	From     string `binding:"min=1,max=30"`
+	To       string `binding:"min=1,max=30,nefield=From"`
+}
+
+// Changes of the files and statuses of a take comparison.
+const (
+	TakeChangeAdded    = "added"
+	TakeChangeRemoved  = "removed"
+	TakeChangeModified = "modified"
+)
+
+// ComparedTake is a take of a comparison: its reviews, one per submitted component, merged, and
+// the publish operations of its revision.
+type ComparedTake struct {
+	Take           string              `json:"take"`
+	ReviewInfoIDs  []int32             `json:"review_info_ids"`
+	Components     []string            `json:"components"`
+	ApprovalStatus string              `json:"approval_status"`
+	WorkStatus     string              `json:"work_status"`
+	SubmittedAtUTC time.Time           `json:"submitted_at_utc"`
+	SubmittedUser  string              `json:"submitted_user"`
+	NumFiles       int                 `json:"num_files"`
+	SizeFiles      uint64              `json:"size_files"`
+	Comments       []*libs.CommentInfo `json:"comments"`
+	StatusLogs     []*ReviewStatusLog  `json:"status_logs"`
+	Publishes      []*DocumentInfo     `json:"publishes"`
+}
+
+// TakeFileChange is a file added, removed or resized between two takes. Files are matched by
+// their path within the take, with the take name in it replaced by {take}.
+type TakeFileChange struct {
+	Path     string  `json:"path"`
+	Change   string  `json:"change"`
+	FromSize *uint64 `json:"from_size"`
+	ToSize   *uint64 `json:"to_size"`
+}
+
+// TakeStatusChange is a status of a take differing in the other one.
+type TakeStatusChange struct {
+	StatusType string `json:"status_type"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+// TakeComparison is the diff of two takes of a phase of an asset, from the earlier to the
+// later one as given.
+type TakeComparison struct {
+	Project           string              `json:"project"`
+	Asset             string              `json:"asset"`
+	Relation          string              `json:"relation"`
+	Phase             string              `json:"phase"`
+	From              *ComparedTake       `json:"from"`
+	To                *ComparedTake       `json:"to"`
+	Files             []*TakeFileChange   `json:"files"`
+	SizeDelta         int64               `json:"size_delta"`
+	AddedComponents   []string            `json:"added_components"`
+	RemovedComponents []string            `json:"removed_components"`
+	StatusChanges     []*TakeStatusChange `json:"status_changes"`
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DebugTimingHeader is sent by admins to opt in to the timing breakdown of a request, which
+// is returned in the header of the same name, e.g.
+//
+//	X-Debug-Timing: count;dur=12.4, keys;dur=30.1, phases;dur=8.0, stitch;dur=0.3, serialize;dur=2.2
+//
+// The durations are in milliseconds, following the syntax of Server-Timing.
+const DebugTimingHeader = "X-Debug-Timing"
+
+const KeyTimings contextKey = "timings"
+
+// Stages of the timing breakdown of the heavy handlers.
+const (
+	TimingCount     = "count"
+	TimingKeys      = "keys"
+	TimingPhases    = "phases"
+	TimingStitch    = "stitch"
+	TimingSerialize = "serialize"
+)
+
+// Timings collects the durations of the stages of a request. The durations of a stage run
+// several times are summed, and the stages are reported in the order they first ran.
+type Timings struct {
+	mu        sync.Mutex
+	stages    []string
+	durations map[string]time.Duration
+}
+
+func NewTimings() *Timings {
+	return &Timings{
+		durations: map[string]time.Duration{},
+	}
+}
+
+// Add adds d to the duration of the stage.
+func (t *Timings) Add(stage string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.durations[stage]; !ok {
+		t.stages = append(t.stages, stage)
+	}
+	t.durations[stage] += d
+}
+
+func (t *Timings) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parts := make([]string, len(t.stages))
+	for i, stage := range t.stages {
+		ms := float64(t.durations[stage].Microseconds()) / 1000
+		parts[i] = fmt.Sprintf("%s;dur=%.1f", stage, ms)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WithTimings returns a context collecting the timings of the stages run with it.
+func WithTimings(ctx context.Context, t *Timings) context.Context {
+	return context.WithValue(ctx, KeyTimings, t)
+}
+
+// StartTiming starts the timer of a stage and returns the function stopping it. It does
+// nothing unless the timings of the request are collected.
+func StartTiming(ctx context.Context, stage string) func() {
+	t, ok := ctx.Value(KeyTimings).(*Timings)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		t.Add(stage, time.Since(start))
+	}
+}
@@ -0,0 +1,32 @@
+package entity
+
+// Modes of the handling of the JSON fields of request bodies unknown to their route, such as
+// the typo "aproval_status", which were silently dropped before.
+const (
+	// UnknownFieldsIgnore drops the unknown fields silently.
+	UnknownFieldsIgnore = "ignore"
+	// UnknownFieldsWarn logs the unknown fields and returns them in the `warnings` of the
+	// response.
+	UnknownFieldsWarn = "warn"
+	// UnknownFieldsReject rejects the requests with unknown fields as bad requests.
+	UnknownFieldsReject = "reject"
+)
+
+// Context keys of the unknown fields mode of the route and of the warnings of the request.
+const (
+	UnknownFieldsModeKey = "unknownFieldsMode"
+	PayloadWarningsKey   = "payloadWarnings"
+)
+
+// PayloadWarningUnknownField is the code of the warnings of unknown fields.
+const PayloadWarningUnknownField = "unknown_field"
+
+// PayloadWarning reports a mistake in a request body which did not fail the request. Field is
+// the path of the field, like "comments[0].txt", and Suggestion the known field it may be a
+// typo of.
+type PayloadWarning struct {
+	Code       string `json:"code"`
+	Field      string `json:"field"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Message    string `json:"message"`
+}
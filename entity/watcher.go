@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Reasons of watching. Submitters watch the assets and shots they submit reviews of, and
+// assignees those whose reviews they change the status of, as reviews are not assigned
+// explicitly.
+const (
+	WatchReasonManual    = "manual"
+	WatchReasonSubmitter = "submitter"
+	WatchReasonAssignee  = "assignee"
+)
+
+// Watcher subscribes a user to the events of an asset or shot, identified like tag targets.
+// Notifications are sent to MailAddress, or to the user at the mail domain of the studio when
+// it is not set.
+type Watcher struct {
+	Project      string    `json:"project"`
+	TargetType   string    `json:"target_type"`
+	Target       string    `json:"target"`
+	User         string    `json:"user"`
+	MailAddress  *string   `json:"mail_address"`
+	Reason       string    `json:"reason"`
+	CreatedAtUTC time.Time `json:"created_at_utc"`
+	CreatedBy    string    `json:"created_by"`
+	ID           int32     `json:"id"`
+}
+
+// ListWatchersParams lists the watchers of a target, or the watched targets of a user.
+type ListWatchersParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	TargetType *string `binding:"omitempty,oneof=asset shot"`
+	Target     *string `binding:"omitempty,min=1,max=500"`
+	User       *string `binding:"omitempty,min=1,max=100"`
+}
+
+type CreateWatcherParams struct {
+	Project     string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	TargetType  string  `binding:"oneof=asset shot"`
+	Target      string  `binding:"min=1,max=500"`
+	User        string  `binding:"min=1,max=100"`
+	MailAddress *string `binding:"omitempty,max=254,email"`
+	CreatedBy   *string `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteWatcherParams struct {
+	Project    string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID         int32   `binding:"required"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// WatchTargetOfReview returns the asset or shot target the review belongs to, and false for
+// other roots.
+func WatchTargetOfReview(review *ReviewInfo) (string, string, bool) {
+	switch review.Root {
+	case "assets":
+		if len(review.Groups) < 1 {
+			return "", "", false
+		}
+		return TagTargetAsset, review.Groups[0] + "/" + review.Relation, true
+	case "shots":
+		if len(review.Groups) < 3 {
+			return "", "", false
+		}
+		return TagTargetShot, strings.Join(
+			append(review.Groups[:3:3], review.Relation), "/",
+		), true
+	}
+	return "", "", false
+}
+
+// WatchNotification is an event of a review sent to the watchers of its asset or shot.
+type WatchNotification struct {
+	Project        string
+	TargetType     string
+	Target         string
+	Event          ActivityType
+	Actor          string
+	ReviewInfoID   int32
+	Phase          string
+	Take           string
+	ApprovalStatus string
+	WorkStatus     string
+	MailAddresses  []string
+}
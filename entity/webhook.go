@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent is a pipeline event posted to the webhooks subscribed to it.
+type WebhookEvent string
+
+const (
+	WebhookReviewCreated         WebhookEvent = "review.created"
+	WebhookReviewApprovalChanged WebhookEvent = "review.approval_changed"
+	WebhookPublishCompleted      WebhookEvent = "publish.completed"
+)
+
+// The headers of the posts to webhooks. The signature is the hex HMAC-SHA256 of the timestamp,
+// a dot and the body, prefixed with WebhookSignaturePrefix.
+const (
+	WebhookEventHeader     = "X-PPI-Event"
+	WebhookDeliveryHeader  = "X-PPI-Delivery"
+	WebhookTimestampHeader = "X-PPI-Timestamp"
+	WebhookSignatureHeader = "X-PPI-Signature"
+	WebhookSignaturePrefix = "sha256="
+)
+
+// Webhook posts the events of a project to an external URL, e.g. a tracker or a chat bot.
+// The bodies are signed with the HMAC-SHA256 of Secret, which is only returned when the
+// webhook is created.
+type Webhook struct {
+	Project       string         `json:"project"`
+	URL           string         `json:"url"`
+	Events        []WebhookEvent `json:"events"`
+	Active        bool           `json:"active"`
+	Secret        string         `json:"secret,omitempty"`
+	CreatedAtUTC  time.Time      `json:"created_at_utc"`
+	ModifiedAtUTC time.Time      `json:"modified_at_utc"`
+	ModifiedBy    string         `json:"modified_by"`
+	CreatedBy     string         `json:"created_by"`
+	ID            int32          `json:"id"`
+}
+
+// WebhookNotification is the payload of an outbox entry posting an event to a webhook. Each
+// webhook subscribed to the event gets its own entry, so that they are retried separately.
+type WebhookNotification struct {
+	WebhookID     int32           `json:"webhook_id"`
+	Project       string          `json:"project"`
+	Event         WebhookEvent    `json:"event"`
+	DeliveryID    string          `json:"delivery_id"`
+	OccurredAtUTC time.Time       `json:"occurred_at_utc"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// WebhookApprovalChange is the data of the review.approval_changed event.
+type WebhookApprovalChange struct {
+	PreviousApprovalStatus string      `json:"previous_approval_status"`
+	Review                 *ReviewInfo `json:"review"`
+}
+
+// WebhookDelivery is an attempt to post an event to a webhook. A failed delivery is retried
+// with the backoff of the outbox.
+type WebhookDelivery struct {
+	WebhookID      int32        `json:"webhook_id"`
+	DeliveryID     string       `json:"delivery_id"`
+	Event          WebhookEvent `json:"event"`
+	Attempt        uint32       `json:"attempt"`
+	StatusCode     *int         `json:"status_code"`
+	Error          string       `json:"error"`
+	DurationMS     int64        `json:"duration_ms"`
+	DeliveredAtUTC time.Time    `json:"delivered_at_utc"`
+	ID             int32        `json:"id"`
+}
+
+type ListWebhooksParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type GetWebhookParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID      int32  `binding:"min=1"`
+}
+
+type CreateWebhookParams struct {
+	Project   string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	URL       string         `binding:"required,max=500,url"`
+	Events    []WebhookEvent `binding:"required,min=1,dive,oneof=review.created review.approval_changed publish.completed"`
+	Active    *bool
+	CreatedBy string `binding:"max=100"`
+}
+
+// UpdateWebhookParams changes the given fields of a webhook. RotateSecret replaces its
+// secret, which is then returned once more.
+type UpdateWebhookParams struct {
+	Project      string         `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID           int32          `binding:"min=1"`
+	URL          *string        `binding:"omitempty,max=500,url"`
+	Events       []WebhookEvent `binding:"omitempty,min=1,dive,oneof=review.created review.approval_changed publish.completed"`
+	Active       *bool
+	RotateSecret bool
+	ModifiedBy   string `binding:"max=100"`
+}
+
+type DeleteWebhookParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID      int32  `binding:"min=1"`
+}
+
+// ListWebhookDeliveriesParams lists the deliveries of a webhook, the latest first.
+type ListWebhookDeliveriesParams struct {
+	Project string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ID      int32  `binding:"min=1"`
+	Failed  *bool
+	*BaseListParams
+}
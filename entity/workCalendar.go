@@ -0,0 +1,180 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// workCalendarMaxDays bounds the days a business clock walks through, so that a calendar
+// without working time cannot loop forever.
+const workCalendarMaxDays = 5 * 366
+
+// WorkShift is a daily working period in the time zone of the calendar. A shift whose end is
+// not after its start ends on the next day, e.g. 22:00-06:00, or 00:00-00:00 for a whole day.
+type WorkShift struct {
+	Start string `json:"start" binding:"datetime=15:04"`
+	End   string `json:"end" binding:"datetime=15:04"`
+}
+
+// WorkCalendar is the working time of a studio. Weekends are days of the week, Sunday being
+// 0, and holidays are dates, both in the time zone of the calendar. Cycle times and SLAs of
+// reviews of the studio count only the working time.
+type WorkCalendar struct {
+	Studio        string       `json:"studio"`
+	TimeZone      string       `json:"time_zone"`
+	Weekends      []int        `json:"weekends"`
+	Holidays      []string     `json:"holidays"`
+	Shifts        []*WorkShift `json:"shifts"`
+	CreatedAtUTC  time.Time    `json:"created_at_utc"`
+	ModifiedAtUTC time.Time    `json:"modified_at_utc"`
+	ModifiedBy    string       `json:"modified_by"`
+	CreatedBy     string       `json:"created_by"`
+	ID            int32        `json:"id"`
+}
+
+type GetWorkCalendarParams struct {
+	Studio string `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+}
+
+type UpdateWorkCalendarParams struct {
+	Studio     string       `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	TimeZone   string       `binding:"timezone"`
+	Weekends   []int        `binding:"max=6,unique,dive,min=0,max=6"`
+	Holidays   []string     `binding:"max=1000,unique,dive,datetime=2006-01-02"`
+	Shifts     []*WorkShift `binding:"min=1,max=10,dive,required"`
+	ModifiedBy *string      `binding:"omitempty,min=1,max=100"`
+}
+
+type DeleteWorkCalendarParams struct {
+	Studio     string  `binding:"min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	ModifiedBy *string `binding:"omitempty,min=1,max=100"`
+}
+
+// BusinessClock measures durations in the working time of a work calendar. A nil
+// BusinessClock counts every hour, for studios without a calendar.
+type BusinessClock struct {
+	loc      *time.Location
+	weekends [7]bool
+	holidays map[string]bool
+	// shifts are the start and end of each shift in minutes from midnight.
+	shifts [][2]int
+}
+
+func NewBusinessClock(c *WorkCalendar) (*BusinessClock, error) {
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid time zone %q", ErrBadRequest, c.TimeZone)
+	}
+	bc := &BusinessClock{
+		loc:      loc,
+		holidays: make(map[string]bool, len(c.Holidays)),
+	}
+	for _, d := range c.Weekends {
+		if d < 0 || d > 6 {
+			return nil, fmt.Errorf("%w: invalid weekend %d", ErrBadRequest, d)
+		}
+		bc.weekends[d] = true
+	}
+	for _, h := range c.Holidays {
+		bc.holidays[h] = true
+	}
+	for _, s := range c.Shifts {
+		start, err := time.Parse("15:04", s.Start)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid shift start %q", ErrBadRequest, s.Start)
+		}
+		end, err := time.Parse("15:04", s.End)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid shift end %q", ErrBadRequest, s.End)
+		}
+		from := start.Hour()*60 + start.Minute()
+		to := end.Hour()*60 + end.Minute()
+		if to <= from {
+			to += 24 * 60
+		}
+		bc.shifts = append(bc.shifts, [2]int{from, to})
+	}
+	sort.Slice(bc.shifts, func(i, j int) bool {
+		return bc.shifts[i][0] < bc.shifts[j][0]
+	})
+	return bc, nil
+}
+
+// working returns the working periods of day, in the time zone of the calendar.
+// Shifts are attributed to the day they start on.
+func (bc *BusinessClock) working(day time.Time) [][2]time.Time {
+	if bc.weekends[day.Weekday()] || bc.holidays[day.Format("2006-01-02")] {
+		return nil
+	}
+	y, m, d := day.Date()
+	periods := make([][2]time.Time, len(bc.shifts))
+	for i, s := range bc.shifts {
+		periods[i] = [2]time.Time{
+			time.Date(y, m, d, 0, s[0], 0, 0, bc.loc),
+			time.Date(y, m, d, 0, s[1], 0, 0, bc.loc),
+		}
+	}
+	return periods
+}
+
+// Duration returns the working time between from and to.
+func (bc *BusinessClock) Duration(from, to time.Time) time.Duration {
+	if bc == nil {
+		return to.Sub(from)
+	}
+	if !to.After(from) {
+		return 0
+	}
+	// Start on the previous day for the shifts ending after midnight.
+	y, m, d := from.In(bc.loc).Date()
+	day := time.Date(y, m, d-1, 0, 0, 0, 0, bc.loc)
+	var total time.Duration
+	for i := 0; i < workCalendarMaxDays && day.Before(to); i++ {
+		for _, p := range bc.working(day) {
+			start, end := p[0], p[1]
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		y, m, d := day.Date()
+		day = time.Date(y, m, d+1, 0, 0, 0, 0, bc.loc)
+	}
+	return total
+}
+
+// Add returns the time when d of working time has elapsed since from. It falls back to the
+// wall clock when the calendar has no working time in the next years.
+func (bc *BusinessClock) Add(from time.Time, d time.Duration) time.Time {
+	if bc == nil {
+		return from.Add(d)
+	}
+	remaining := d
+	y, m, dd := from.In(bc.loc).Date()
+	day := time.Date(y, m, dd-1, 0, 0, 0, 0, bc.loc)
+	for i := 0; i < workCalendarMaxDays; i++ {
+		for _, p := range bc.working(day) {
+			start, end := p[0], p[1]
+			if start.Before(from) {
+				start = from
+			}
+			if !end.After(start) {
+				continue
+			}
+			length := end.Sub(start)
+			if remaining <= length {
+				return start.Add(remaining).UTC()
+			}
+			remaining -= length
+		}
+		y, m, dd := day.Date()
+		day = time.Date(y, m, dd+1, 0, 0, 0, 0, bc.loc)
+	}
+	return from.Add(d).UTC()
+}
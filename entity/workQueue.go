@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+// Kinds of the items of the work queue of a user, from the most to the least urgent.
+const (
+	// WorkItemManifestPending is a review of the user whose AllFiles manifest is due.
+	WorkItemManifestPending = "manifest_pending"
+	// WorkItemRetake is a phase of an asset or shot the user submitted reviews of, whose latest
+	// review was sent back to retake.
+	WorkItemRetake = "retake"
+	// WorkItemAssigned is an asset or shot the user watches as its submitter or assignee,
+	// without a retake or a manifest due.
+	WorkItemAssigned = "assigned"
+)
+
+// RetakeApprovalStatus is the approval status of the reviews sent back to their artists.
+const RetakeApprovalStatus = "retake"
+
+// WorkItem is an asset or shot awaiting the work of a user. Phase, Take, the statuses and
+// ReviewInfoID are nil for the assigned targets, which are not tied to a review. SinceUTC is
+// when the item started waiting for the user.
+type WorkItem struct {
+	Kind           string     `json:"kind"`
+	Project        string     `json:"project"`
+	TargetType     string     `json:"target_type"`
+	Target         string     `json:"target"`
+	Phase          *string    `json:"phase"`
+	Take           *string    `json:"take"`
+	ApprovalStatus *string    `json:"approval_status"`
+	WorkStatus     *string    `json:"work_status"`
+	ReviewInfoID   *int32     `json:"review_info_id"`
+	DueAtUTC       *time.Time `json:"due_at_utc"`
+	Overdue        bool       `json:"overdue"`
+	SinceUTC       time.Time  `json:"since_utc"`
+}
+
+// ListWorkItemsParams lists the work queue of a user across the projects, or in Project.
+type ListWorkItemsParams struct {
+	User    string  `binding:"min=1,max=100"`
+	Project *string `binding:"omitempty,min=1,max=30,alphanum,lowercase,startsnotwithdigit"`
+	Kind    *string `binding:"omitempty,oneof=manifest_pending retake assigned"`
+	*BaseListParams
+}
@@ -12,13 +12,15 @@ import (
 	"github.com/PolygonPictures/central30-web/front/repository/model"
 	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GroupCategory struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache QueryCache
 }
 
-func NewGroupCategory(db *gorm.DB) (*GroupCategory, error) {
+func NewGroupCategory(db *gorm.DB, cache QueryCache) (*GroupCategory, error) {
 	var m model.GroupCategory
 	if err := db.AutoMigrate(&m, &model.GroupCategoryGroup{}); err != nil {
 		return nil, err
@@ -27,7 +29,8 @@ func NewGroupCategory(db *gorm.DB) (*GroupCategory, error) {
 		return nil, err
 	}
 	return &GroupCategory{
-		db: db,
+		db:    db,
+		cache: cache,
 	}, nil
 }
 
@@ -76,7 +79,7 @@ func (r *GroupCategory) listStandard(
 		return nil, 0, err
 	}
 
-	stmt = orderBy(stmt, ob)
+	stmt = orderBy(stmt, ob).Order("`id` asc")
 	stmt = limitOffset(stmt, baseListParams)
 
 	var models []*model.GroupCategory
@@ -114,7 +117,7 @@ func (r *GroupCategory) listSince(
 		return nil, 0, err
 	}
 
-	stmt = orderBy(stmt, ob)
+	stmt = orderBy(stmt, ob).Order("`id` asc")
 	stmt = limitOffset(stmt, baseListParams)
 
 	var entities []groupCategory.Entity
@@ -191,6 +194,70 @@ func (r *GroupCategory) Create(
 	return m.Entity(false), nil
 }
 
+// Copy copies the categories of a project and their groups to another project. It returns
+// the numbers of the copied categories and groups.
+func (r *GroupCategory) Copy(
+	tx *gorm.DB,
+	from string,
+	to string,
+	createdBy string,
+) (int, int, error) {
+	var models []*model.GroupCategory
+	if err := tx.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", from,
+	).Preload(
+		"Groups", "`deleted` = ?", 0,
+	).Order("`id` asc").Find(&models).Error; err != nil {
+		return 0, 0, err
+	}
+	if len(models) == 0 {
+		return 0, 0, nil
+	}
+	now := time.Now().UTC()
+	var groups int
+	categories := make([]*model.GroupCategory, len(models))
+	for i, m := range models {
+		c := &model.GroupCategory{
+			Project:       to,
+			Root:          m.Root,
+			Path:          m.Path,
+			Depth:         m.Depth,
+			CreatedBy:     createdBy,
+			CreatedAtUTC:  now,
+			ModifiedBy:    createdBy,
+			ModifiedAtUTC: now,
+		}
+		for _, g := range m.Groups {
+			c.Groups = append(c.Groups, &model.GroupCategoryGroup{
+				Path:          g.Path,
+				Project:       to,
+				CreatedBy:     createdBy,
+				CreatedAtUTC:  now,
+				ModifiedBy:    createdBy,
+				ModifiedAtUTC: now,
+			})
+		}
+		groups += len(c.Groups)
+		categories[i] = c
+	}
+	if err := tx.Create(&categories).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return 0, 0, fmt.Errorf(
+				"%w: the categories of project %q already exist", entity.ErrBadRequest, to,
+			)
+		}
+		return 0, 0, err
+	}
+
+	if r.cache != nil {
+		r.cache.Invalidate(tx.Statement.Context, to)
+	}
+	return len(categories), groups, nil
+}
+
 func (r *GroupCategory) Update(
 	tx *gorm.DB,
 	params *groupCategory.UpdateParams,
@@ -282,6 +349,76 @@ func (r *GroupCategory) Update(
 	return cm.Entity(false), nil
 }
 
+// Assign upserts the groups of a category, so that the groups already assigned are only
+// marked as modified, and drops the cached pivots of the project, which are grouped by the
+// categories.
+func (r *GroupCategory) Assign(
+	tx *gorm.DB,
+	params *groupCategory.AssignParams,
+) (*groupCategory.CategoryEntity, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+
+	var cm *model.GroupCategory
+	result := tx.Model(cm).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"modified_at_utc": now,
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf(
+			"%w: category with ID %d not found", entity.ErrRecordNotFound, params.ID,
+		)
+	}
+
+	seen := map[string]bool{}
+	var groups []*model.GroupCategoryGroup
+	for _, path := range params.Groups {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		groups = append(groups, model.NewGroupCategoryGroup(&groupCategory.CreateGroupParams{
+			GroupCategoryID: params.ID,
+			Path:            path,
+			Project:         params.Project,
+			CreatedBy:       &modifiedBy,
+		}))
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"modified_at_utc": now,
+			"modified_by":     modifiedBy,
+		}),
+	}).Create(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	if r.cache != nil {
+		r.cache.Invalidate(tx.Statement.Context, params.Project)
+	}
+
+	cm, err := r.get(tx, &groupCategory.GetParams{
+		Project: params.Project,
+		ID:      params.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cm.Entity(false), nil
+}
+
 func (r *GroupCategory) Delete(
 	tx *gorm.DB,
 	params *groupCategory.DeleteParams,
@@ -3,18 +3,26 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/bigquery"
 	"cloud.google.com/go/logging/logadmin"
+	"github.com/PolygonPictures/central30-web/front/config"
 	"github.com/PolygonPictures/central30-web/front/database"
 	"github.com/PolygonPictures/central30-web/front/entity"
 	"github.com/PolygonPictures/central30-web/front/license"
@@ -40,28 +48,41 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	"gorm.io/plugin/dbresolver"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 const (
-	defaultProjectID = "ppi-gcp-pj001"
 	datasetLocation  = "asia-northeast1"
 	connectTimeout   = 60 * time.Second
 	readTimeout      = 60 * time.Second
 	writeTimeout     = 60 * time.Second
-)
+	seedTimeout      = 60 * 30 * time.Second
+	anonymizeTimeout = 60 * 60 * 6 * time.Second
+	archiveTimeout   = 60 * 60 * 2 * time.Second
+	bootstrapTimeout = 60 * 10 * time.Second
+	importTimeout    = 60 * 60 * 12 * time.Second
 
-// Neo4jConfig holds the configuration details required to connect to a Neo4j database.
-type Neo4jConfig struct {
-	// URI is the connection string for the Neo4j database.
-	URI string
-	// Username is the username used for authentication.
-	Username string
-	// Password is the password used for authentication.
-	Password string
-}
+	subsystemProbeTimeout = 10 * time.Second
+
+	// tracingServiceName is the service of the traces unless OTEL_SERVICE_NAME is set.
+	tracingServiceName = "central30-front"
+)
 
 // getDataset returns a dataset that matches the datasetID.
 // If not found, a new dataset with that ID will be created.
@@ -85,91 +106,375 @@ func getDataset(client *bigquery.Client, datasetID string) (*bigquery.Dataset, e
 	return datasetRef, nil
 }
 
-func getGCPProjectID() string {
-	projectID := os.Getenv("PPI_PROJECT_ID")
-	if projectID == "" {
-		projectID = defaultProjectID
+// mySQLTimeZoneParams are the DSN parameters reading and writing datetimes in the time zone of
+// the database, PPI_MYSQL_TIME_ZONE, and setting it as the time zone of the sessions.
+func mySQLTimeZoneParams(val url.Values) url.Values {
+	val.Add("loc", repository.DBLocation.String())
+	val.Add("time_zone", "'"+repository.SessionTimeZone(repository.DBLocation)+"'")
+	return val
+}
+
+// gormDSN is the DSN of the database on host, the primary or a replica.
+func gormDSN(c *config.MySQL, host string) string {
+	return fmt.Sprintf(
+		"%s:%s@(%s:%s)/%s?charset=utf8mb4&parseTime=True&%s",
+		c.User,
+		c.Password,
+		host,
+		c.Port,
+		c.Name,
+		mySQLTimeZoneParams(url.Values{}).Encode(),
+	)
+}
+
+// openGorm opens the database with the queries traced as spans of the context of their
+// statements, without their values.
+func openGorm(c *config.MySQL) (*gorm.DB, error) {
+	db, err := gorm.Open(
+		mysql.Open(gormDSN(c, c.Host)),
+		&gorm.Config{
+			SkipDefaultTransaction: true,
+			NamingStrategy: schema.NamingStrategy{
+				TablePrefix:   "t_",
+				SingularTable: true,
+			},
+			DisableForeignKeyConstraintWhenMigrating: true,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.NewPlugin(
+		tracing.WithoutMetrics(),
+		tracing.WithoutQueryVariables(),
+	)); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// registerReplicas routes the reads of db to the read replicas in PPI_MYSQL_REPLICA_HOSTS, a
+// comma-separated list of hosts sharing the user, password and port of the primary. It tells
+// whether any replica is registered.
+func registerReplicas(db *gorm.DB, c *config.MySQL) (bool, error) {
+	var replicas []gorm.Dialector
+	for _, host := range c.ReplicaHosts {
+		replicas = append(replicas, mysql.Open(gormDSN(c, host)))
+	}
+	if len(replicas) == 0 {
+		return false, nil
 	}
-	return projectID
+	return true, db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}))
 }
 
-func bqConfigs() (string, string) {
-	publishLogDatasetID := os.Getenv("PPI_PUBLISH_LOG_DATASET_ID")
-	return getGCPProjectID(), publishLogDatasetID
+// runSeed is the "seed" subcommand, generating load testing data into the database of the
+// server, whose tables must already be migrated, e.g.
+//
+//	front seed -projects 3 -assets 5000 -reviews 4 -seed 42 -reset
+func runSeed(ctx context.Context, cfg *config.Config, args []string) {
+	if !cfg.SeedEnabled {
+		log.Fatal("The seed subcommand requires PPI_SEED_ENABLED=true.")
+	}
+	params := entity.DefaultSeedParams()
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	fs.StringVar(&params.Prefix, "prefix", params.Prefix, "prefix of the project names")
+	fs.IntVar(&params.Projects, "projects", params.Projects, "number of projects")
+	fs.IntVar(&params.AssetsPerProject, "assets", params.AssetsPerProject, "assets per project")
+	fs.IntVar(&params.ReviewsPerPhase, "reviews", params.ReviewsPerPhase, "average reviews per phase")
+	fs.IntVar(
+		&params.CategoriesPerProject, "categories", params.CategoriesPerProject,
+		"categories per project",
+	)
+	fs.Float64Var(
+		&params.DependencyRatio, "dependencies", params.DependencyRatio,
+		"share of built assets with dependencies",
+	)
+	fs.IntVar(&params.Days, "days", params.Days, "days over which the reviews are spread")
+	fs.StringVar(&params.Studio, "studio", params.Studio, "studio of the reviews")
+	fs.Int64Var(&params.Seed, "seed", params.Seed, "random seed")
+	fs.BoolVar(&params.Reset, "reset", params.Reset, "delete the previously seeded projects first")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if err := cfg.Require(config.NeedMySQL); err != nil {
+		log.Fatal(err)
+	}
+
+	gormDB, err := openGorm(&cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var dataDepRepo *repository.DataDepRepository
+	neo4jDriver, err := newNeo4jDriverWithContext(ctx, cfg.Neo4j)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if neo4jDriver != nil {
+		defer (*neo4jDriver).Close(ctx)
+		dataDepRepo = repository.NewDataDepRepository(*neo4jDriver, gormDB)
+	}
+
+	uc := usecase.NewSeed(repository.NewSeed(gormDB), dataDepRepo, seedTimeout)
+	result, err := uc.Seed(ctx, delivery.NewBackgroundLogger("seed"), params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("INFO: seeded %s", b)
 }
 
-func mySQLConfigs() (string, string, string, string, string) {
-	dbUser := os.Getenv("PPI_MYSQL_USER")
-	dbPass := os.Getenv("PPI_MYSQL_PASSWORD")
-	dbHost := os.Getenv("PPI_MYSQL_HOST")
-	dbPort := os.Getenv("PPI_MYSQL_PORT")
-	dbName := "central30"
-	return dbUser, dbPass, dbHost, dbPort, dbName
+// runBench is the "bench" subcommand, the load test mode measuring the asset pivot and CSV code
+// paths of a server running against seeded data. With -baseline, it acts as a perf gate:
+// the regressions against the baseline report are logged and the exit status is 1, e.g.
+//
+//	front bench -project seed01 -out current.json -baseline baseline.json -tolerance 0.2
+func runBench(ctx context.Context, args []string) {
+	params := &entity.BenchmarkParams{}
+	var scenarios, baselinePath, outPath string
+	var tolerance float64
+	var timeout time.Duration
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.StringVar(&params.BaseURL, "url", "http://localhost:8080/api", "base URL of the API")
+	fs.StringVar(&params.Project, "project", "seed01", "project to measure")
+	fs.IntVar(&params.Iterations, "iterations", 50, "requests per scenario")
+	fs.IntVar(&params.Concurrency, "concurrency", 4, "concurrent clients")
+	fs.IntVar(&params.Warmup, "warmup", 2, "unmeasured requests per scenario")
+	fs.StringVar(&scenarios, "scenarios", "", "comma-separated scenarios, all when empty")
+	fs.StringVar(&baselinePath, "baseline", "", "baseline report to compare with")
+	fs.Float64Var(&tolerance, "tolerance", 0.2, "allowed latency growth, e.g. 0.2 for 20%")
+	fs.StringVar(&outPath, "out", "", "file to write the report to")
+	fs.DurationVar(&timeout, "timeout", 15*time.Minute, "timeout of a request")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	for _, s := range strings.Split(scenarios, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			params.Scenarios = append(params.Scenarios, s)
+		}
+	}
+
+	uc := usecase.NewBenchmark(repository.NewBenchmark(timeout))
+	report, err := uc.Run(ctx, delivery.NewBackgroundLogger("bench"), params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if outPath != "" {
+		if err := os.WriteFile(outPath, b, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		log.Printf("INFO: benchmark report:\n%s", b)
+	}
+
+	if baselinePath == "" {
+		return
+	}
+	b, err = os.ReadFile(baselinePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var baseline entity.BenchmarkReport
+	if err := json.Unmarshal(b, &baseline); err != nil {
+		log.Fatalf("Invalid baseline %s: %v", baselinePath, err)
+	}
+	regressions := report.Compare(&baseline, tolerance)
+	if len(regressions) == 0 {
+		log.Printf("INFO: no regression against %s.", baselinePath)
+		return
+	}
+	for _, r := range regressions {
+		log.Printf("ERROR: regression: %s", r)
+	}
+	os.Exit(1)
 }
 
-func mongoConfigs() (string, string, string, string, string) {
-	dbUser := os.Getenv("PPI_MONGODB_USER")
-	dbPass := os.Getenv("PPI_MONGODB_PASSWORD")
-	dbHost := os.Getenv("PPI_MONGODB_HOST")
-	dbPort := os.Getenv("PPI_MONGODB_PORT")
-	dbName := "central30"
-	return dbUser, dbPass, dbHost, dbPort, dbName
+// runAnonymize is the "anonymize" subcommand, scrubbing the user names, computers, comments and
+// paths of the databases cloned from production for demo and training environments, e.g.
+//
+//	front anonymize -salt "$(cat salt.txt)" -dry-run
+func runAnonymize(ctx context.Context, cfg *config.Config, args []string) {
+	if !cfg.AnonymizeEnabled {
+		log.Fatal("The anonymize subcommand requires PPI_ANONYMIZE_ENABLED=true.")
+	}
+	params := entity.DefaultAnonymizeParams()
+	fs := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	fs.StringVar(&params.Salt, "salt", "", "secret of the fakes, at least 16 characters")
+	fs.IntVar(&params.BatchSize, "batch", params.BatchSize, "rows per transaction")
+	fs.BoolVar(&params.SkipMongo, "skip-mongo", params.SkipMongo, "leave MongoDB untouched")
+	fs.BoolVar(&params.DryRun, "dry-run", params.DryRun, "count the changes without writing")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	needs := []config.Need{config.NeedMySQL}
+	if !params.SkipMongo {
+		needs = append(needs, config.NeedMongo)
+	}
+	if err := cfg.Require(needs...); err != nil {
+		log.Fatal(err)
+	}
+
+	gormDB, err := openGorm(&cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var mongoDB *mongo.Database
+	if !params.SkipMongo {
+		mongoDB, err = openMongo(&cfg.Mongo)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer mongoDB.Client().Disconnect(ctx)
+	}
+
+	uc := usecase.NewAnonymize(repository.NewAnonymizer(gormDB, mongoDB), anonymizeTimeout)
+	result, err := uc.Anonymize(ctx, delivery.NewBackgroundLogger("anonymize"), params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("INFO: anonymized %s", b)
 }
 
-// NewNeo4jConfig creates a new Neo4jConfig instance by reading the necessary configuration values
-// from environment variables.
+// runArchiveImport is the "archive-import" subcommand, restoring the bundle of a project
+// archived by the Archive API into the databases of the server, typically a cold-storage
+// instance whose tables are migrated and whose IDs do not overlap those of the bundle, e.g.
 //
-// Required environment variables:
-//   - NEO4J_URI: the URI of the Neo4j database
-//   - NEO4J_USER: the username for the Neo4j database
-//   - NEO4J_PASSWORD: the password for the Neo4j database
-func NewNeo4jConfig() *Neo4jConfig {
-	var config Neo4jConfig
-	uri := os.Getenv("NEO4J_URI")
-	if uri == "" {
-		return nil
+//	front archive-import -file archive_pj01_12.tar.gz -dry-run
+func runArchiveImport(ctx context.Context, cfg *config.Config, args []string) {
+	params := &entity.ArchiveImportParams{}
+	fs := flag.NewFlagSet("archive-import", flag.ExitOnError)
+	fs.StringVar(&params.File, "file", "", "bundle to restore")
+	fs.BoolVar(&params.SkipMongo, "skip-mongo", false, "leave the documents out")
+	fs.BoolVar(&params.SkipGraph, "skip-graph", false, "leave the Neo4j subgraph out")
+	fs.BoolVar(&params.DryRun, "dry-run", false, "check the bundle and the target without writing")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
 	}
-	config.URI = uri
-	username := os.Getenv("NEO4J_USER")
-	if username == "" {
-		return nil
+	needs := []config.Need{config.NeedMySQL}
+	if !params.SkipMongo {
+		needs = append(needs, config.NeedMongo)
 	}
-	config.Username = username
-	password := os.Getenv("NEO4J_PASSWORD")
-	if password == "" {
-		return nil
+	if err := cfg.Require(needs...); err != nil {
+		log.Fatal(err)
+	}
+
+	gormDB, err := openGorm(&cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var mongoDB *mongo.Database
+	if !params.SkipMongo {
+		mongoDB, err = openMongo(&cfg.Mongo)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer mongoDB.Client().Disconnect(ctx)
+	}
+	var graphDriver neo4j.DriverWithContext
+	if !params.SkipGraph {
+		neo4jDriver, err := newNeo4jDriverWithContext(ctx, cfg.Neo4j)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if neo4jDriver != nil {
+			defer (*neo4jDriver).Close(ctx)
+			graphDriver = *neo4jDriver
+		}
+	}
+
+	uc := usecase.NewArchiveImport(
+		repository.NewArchiver(gormDB, mongoDB, graphDriver),
+		importTimeout,
+	)
+	result, err := uc.Import(ctx, delivery.NewBackgroundLogger("archive-import"), params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("INFO: imported %s", b)
+}
+
+type uidBackfiller interface {
+	BackfillUIDs(db *gorm.DB, batchSize int) (int64, error)
+}
+
+// backfillUIDs assigns ULIDs to the records created before the ULID mode was enabled, one
+// batch at a time so that the tables are not locked for long.
+func backfillUIDs(db *gorm.DB, backfillers ...uidBackfiller) {
+	const batchSize = 1000
+	for _, b := range backfillers {
+		var total int64
+		for {
+			n, err := b.BackfillUIDs(db, batchSize)
+			total += n
+			if err != nil {
+				log.Printf("ERROR: failed to backfill UIDs: %v", err)
+				break
+			}
+			if n < batchSize {
+				break
+			}
+		}
+		log.Printf("INFO: %d UIDs backfilled.", total)
+	}
+}
+
+// backfillTakeNumbers sets the take numbers of the review information records created before
+// they were stored, so that they sort by take.
+func backfillTakeNumbers(db *gorm.DB, repo *repository.ReviewInfo) {
+	const batchSize = 1000
+	n, err := repo.BackfillTakeNumbers(db, batchSize)
+	if err != nil {
+		log.Printf("ERROR: failed to backfill take numbers: %v", err)
 	}
-	config.Password = password
-	return &config
+	log.Printf("INFO: %d take numbers backfilled.", n)
 }
 
 func openMySQLByDSN(dsn string) (*sql.DB, error) {
 	val := url.Values{}
 	val.Add("charset", "utf8mb4")
 	val.Add("parseTime", "1")
-	return sql.Open("mysql", fmt.Sprintf("%s?%s", dsn, val.Encode()))
+	return sql.Open("mysql", fmt.Sprintf("%s?%s", dsn, mySQLTimeZoneParams(val).Encode()))
 }
 
-func openMySQL(dbUser, dbPass, dbHost, dbPort, dbName string) (*sql.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", dbUser, dbPass, dbHost, dbPort, dbName)
+func openMySQL(c *config.MySQL) (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", c.User, c.Password, c.Host, c.Port, c.Name)
 	return openMySQLByDSN(dsn)
 }
 
-func openMongo(dbUser, dbPass, dbHost, dbPort, dbName string) (*mongo.Database, error) {
+func openMongo(c *config.Mongo) (*mongo.Database, error) {
 	val := url.Values{}
 	val.Add("connect", "direct")
-	if dbUser != "" {
-		val.Add("authSource", dbName)
+	if c.User != "" {
+		val.Add("authSource", c.Name)
 	}
 
 	var conn string
-	if dbUser != "" && dbPass != "" {
-		conn += fmt.Sprintf("%s:%s@", dbUser, dbPass)
+	if c.User != "" && c.Password != "" {
+		conn += fmt.Sprintf("%s:%s@", c.User, c.Password)
 	}
-	conn += fmt.Sprintf("%s:%s", dbHost, dbPort)
+	conn += fmt.Sprintf("%s:%s", c.Host, c.Port)
 
 	url := fmt.Sprintf("mongodb://%s/?%s", conn, val.Encode())
-	client, err := mongo.NewClient(options.Client().ApplyURI(url))
+	client, err := mongo.NewClient(
+		options.Client().ApplyURI(url).SetMonitor(otelmongo.NewMonitor()),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -181,12 +486,61 @@ func openMongo(dbUser, dbPass, dbHost, dbPort, dbName string) (*mongo.Database,
 		return nil, err
 	}
 
-	return client.Database(dbName), nil
+	return client.Database(c.Name), nil
 }
 
+// openBigQuery opens the BigQuery client used by the publish logs. Its reads are retried on
+// transient failures, see repository.RetryTransport, and traced.
 func openBigQuery(projectID string) (*bigquery.Client, error) {
 	ctx := context.Background()
-	return bigquery.NewClient(ctx, projectID)
+	transport, err := htransport.NewTransport(
+		ctx, http.DefaultTransport, option.WithScopes(bigquery.Scope),
+	)
+	if err != nil {
+		return nil, err
+	}
+	retrying, err := repository.NewRetryTransportFromEnv(transport, "bigquery", "BIGQUERY")
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Transport: otelhttp.NewTransport(retrying)}
+	return bigquery.NewClient(ctx, projectID, option.WithHTTPClient(httpClient))
+}
+
+// setupTracing exports the traces of the requests and of the queries of the databases over
+// OTLP when OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set. The
+// exporter, the sampler and the resource follow the standard OTEL_* variables, e.g.
+// OTEL_TRACES_SAMPLER=parentbased_traceidratio. The trace context of the callers is
+// propagated in any case. It returns the function flushing the traces.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" &&
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(
+		ctx,
+		resource.WithAttributes(attribute.String("service.name", tracingServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	log.Println("Tracing is exported over OTLP.")
+	return provider.Shutdown, nil
 }
 
 func openCloudLogging(projectID string) (*logadmin.Client, error) {
@@ -194,36 +548,57 @@ func openCloudLogging(projectID string) (*logadmin.Client, error) {
 	return logadmin.NewClient(ctx, projectID)
 }
 
+// probeCloudLogging checks that the log entries of the project can be read with the client.
+func probeCloudLogging(client *logadmin.Client) usecase.SubsystemProbe {
+	return func(ctx context.Context) error {
+		it := client.Entries(
+			ctx,
+			logadmin.NewestFirst(),
+			logadmin.Filter(fmt.Sprintf(
+				"timestamp >= %q", time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+			)),
+		)
+		it.PageInfo().MaxSize = 1
+		if _, err := it.Next(); err != nil && err != iterator.Done {
+			return err
+		}
+		return nil
+	}
+}
+
 func methodNotAllowedHandler(c *gin.Context) {
 	c.AbortWithStatus(http.StatusMethodNotAllowed)
 }
 
-// newNeo4jDriverWithContext initializes and returns a new Neo4j driver with a context. If it can
-// get the Neo4j configuration, it will try to establish a connection to the Neo4j database,
-// otherwise it will return nil. However, if it fails to connect to the database, it will log
-// an error and exit the program.
-func newNeo4jDriverWithContext(ctx context.Context) *neo4j.DriverWithContext {
-	neo4jConfig := NewNeo4jConfig()
+// newNeo4jDriverWithContext initializes and returns a new Neo4j driver with a context. If Neo4j
+// is configured, it will try to establish a connection to the Neo4j database, otherwise it
+// will return nil. If the driver cannot be created, it returns the error without a driver. If
+// the connection cannot be verified, it returns the driver with the error, so that the caller
+// may start without Neo4j and verify the connection again later.
+func newNeo4jDriverWithContext(
+	ctx context.Context,
+	neo4jConfig *config.Neo4j,
+) (*neo4j.DriverWithContext, error) {
 	if neo4jConfig == nil {
 		log.Println(
 			"No environment variables were provided to authenticate with Neo4j. " +
 				"Skip registering DataDependency API.",
 		)
-		return nil
+		return nil, nil
 	}
 
 	authToken := neo4j.BasicAuth(neo4jConfig.Username, neo4jConfig.Password, "")
 	neo4jDriver, err := neo4j.NewDriverWithContext(neo4jConfig.URI, authToken)
 	if err != nil {
-		log.Fatalf("Failed to create Neo4j driver: %v", err)
+		return nil, fmt.Errorf("failed to create Neo4j driver: %w", err)
 	}
 	err = neo4jDriver.VerifyConnectivity(ctx)
 	if err != nil {
-		log.Fatalf("Neo4j verification failed: %v", err)
+		return &neo4jDriver, fmt.Errorf("Neo4j verification failed: %w", err)
 	}
 
 	log.Println("Neo4j connection established.")
-	return &neo4jDriver
+	return &neo4jDriver, nil
 }
 
 // registerDataDepHandlers registers all the HTTP route handlers related to data dependency
@@ -310,15 +685,6 @@ func registerDataDepHandlers(router *gin.RouterGroup, dataDepUsecase *usecase.Da
 var defaultRoot = "assets"
 var defaultPerPage = 15
 
-var allowedPhases = map[string]struct{}{
-	"mdl":  {},
-	"rig":  {},
-	"bld":  {},
-	"dsn":  {},
-	"ldv":  {},
-	"none": {},
-}
-
 // -------------------------------------------------------
 // INT PARSING HELPERS
 // -------------------------------------------------------
@@ -371,40 +737,11 @@ func normalizeSortKey(key string) string {
 	case "submitted", "submitted_at", "submitted_at_utc":
 		return "submitted_at_utc"
 
-	case "mdl_work":
-		return "mdl_work"
-	case "rig_work":
-		return "rig_work"
-	case "bld_work":
-		return "bld_work"
-	case "dsn_work":
-		return "dsn_work"
-	case "ldv_work":
-		return "ldv_work"
-
-	case "mdl_appr":
-		return "mdl_appr"
-	case "rig_appr":
-		return "rig_appr"
-	case "bld_appr":
-		return "bld_appr"
-	case "dsn_appr":
-		return "dsn_appr"
-	case "ldv_appr":
-		return "ldv_appr"
-
-	case "mdl_submitted":
-		return "mdl_submitted"
-	case "rig_submitted":
-		return "rig_submitted"
-	case "bld_submitted":
-		return "bld_submitted"
-	case "dsn_submitted":
-		return "dsn_submitted"
-	case "ldv_submitted":
-		return "ldv_submitted"
-
 	default:
+		// <phase>_work, <phase>_appr and <phase>_submitted of any phase of the templates
+		if repository.IsPivotPhaseSortKey(key) {
+			return key
+		}
 		return "group1_only"
 	}
 }
@@ -436,6 +773,41 @@ func parseStatusParam(c *gin.Context, key string) []string {
 	return out
 }
 
+// pivotQueryParams parses the root, sorting and filters of an asset pivot query, as the
+// pivot API does. The paging and the phase columns are left to the caller. It responds with
+// 400 and returns false when a filter is invalid.
+func pivotQueryParams(c *gin.Context) (repository.ListAssetsPivotParams, bool) {
+	officialOnly, _ := strconv.ParseBool(c.DefaultQuery("official_only", "false"))
+	phaseApprovalStatuses, phaseWorkStatuses := delivery.PhaseStatusFilters(c.Request.URL.Query())
+	params := repository.ListAssetsPivotParams{
+		Project:               strings.TrimSpace(c.Param("project")),
+		Root:                  c.DefaultQuery("root", defaultRoot),
+		OrderKey:              normalizeSortKey(c.DefaultQuery("sort", "group_1")),
+		Direction:             normalizeDir(c.DefaultQuery("dir", "ASC")),
+		AssetNameKey:          strings.TrimSpace(c.Query("name")),
+		ApprovalStatuses:      parseStatusParam(c, "approval_status"),
+		WorkStatuses:          parseStatusParam(c, "work_status"),
+		PhaseApprovalStatuses: phaseApprovalStatuses,
+		PhaseWorkStatuses:     phaseWorkStatuses,
+		OfficialOnly:          officialOnly,
+		Intents:               parseStatusParam(c, "intent"),
+		Metadata:              delivery.MetadataFilters(c.Request.URL.Query()),
+		Tags:                  delivery.TagFilters(c),
+		PhaseValue:            strings.ToLower(strings.TrimSpace(c.Query("phase_value"))),
+	}
+	if err := delivery.PivotTimeFilters(c, &params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return params, false
+	}
+	sortKeys, err := delivery.PivotSort(c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return params, false
+	}
+	params.Sort = sortKeys
+	return params, true
+}
+
 // -------------------------------------------------------
 // PAGINATION LINK HEADER (RFC 5988)
 // -------------------------------------------------------
@@ -472,57 +844,109 @@ func paginationLinks(baseURL string, page, perPage, total int) string {
 func main() {
 	ctx := context.Background()
 
-	projectID, publishLogDatasetID := bqConfigs()
-	client, err := openBigQuery(projectID)
+	// The settings are read from the environment, which overrides those of the -config file,
+	// and are all checked before anything starts, so that a typo fails with every problem.
+	fs := flag.NewFlagSet("front", flag.ExitOnError)
+	configFile := fs.String(
+		"config", os.Getenv("PPI_CONFIG_FILE"), "file of KEY=VALUE settings, e.g. front.env",
+	)
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+	args := fs.Args()
+	if err := config.ExportFile(*configFile); err != nil {
+		log.Fatal(err)
+	}
+	cfg, err := config.Load(os.LookupEnv)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	cloudLoggingClient, err := openCloudLogging(getGCPProjectID())
+	// The datetimes of the database are UTC unless configured otherwise, for all connections.
+	repository.DBLocation = cfg.MySQL.TimeZone
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "seed":
+			binding.Validator = new(defaultValidator)
+			runSeed(ctx, cfg, args[1:])
+			return
+		case "bench":
+			binding.Validator = new(defaultValidator)
+			runBench(ctx, args[1:])
+			return
+		case "anonymize":
+			binding.Validator = new(defaultValidator)
+			runAnonymize(ctx, cfg, args[1:])
+			return
+		case "archive-import":
+			binding.Validator = new(defaultValidator)
+			runArchiveImport(ctx, cfg, args[1:])
+			return
+		}
+	}
+	if err := cfg.Require(config.NeedMySQL, config.NeedMongo); err != nil {
+		log.Fatal(err)
+	}
+
+	// The recent errors and slow queries are kept for the support bundles.
+	supportLogRepository := repository.NewSupportLog()
+	log.SetOutput(io.MultiWriter(os.Stderr, supportLogRepository))
+
+	shutdownTracing, err := setupTracing(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer cloudLoggingClient.Close()
+	defer shutdownTracing(context.Background())
 
-	dbUser, dbPass, dbHost, dbPort, dbName := mySQLConfigs()
-	myDB, err := openMySQL(dbUser, dbPass, dbHost, dbPort, dbName)
+	client, err := openBigQuery(cfg.GCP.ProjectID)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	gormDB, err := gorm.Open(
-		mysql.Open(
-			fmt.Sprintf(
-				"%s:%s@(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-				dbUser,
-				dbPass,
-				dbHost,
-				dbPort,
-				dbName,
-			),
-		),
-		&gorm.Config{
-			SkipDefaultTransaction: true,
-			NamingStrategy: schema.NamingStrategy{
-				TablePrefix:   "t_",
-				SingularTable: true,
-			},
-			DisableForeignKeyConstraintWhenMigrating: true,
-		},
-	)
+	// Cloud Logging is only used by the DataSyncClient API, which is disabled without it.
+	cloudLoggingClient, cloudLoggingErr := openCloudLogging(cfg.GCP.ProjectID)
+	if cloudLoggingErr != nil {
+		log.Printf("WARNING: Could not open Cloud Logging. %s", cloudLoggingErr)
+	} else {
+		defer cloudLoggingClient.Close()
+	}
+
+	myDB, err := openMySQL(&cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gormDB, err := openGorm(&cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gormDB.Logger = supportLogRepository.GormLogger(gormDB.Logger)
+	replicated, err := registerReplicas(gormDB, &cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	consistencyRepository, err := repository.NewConsistency(gormDB, replicated)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	dbUser, dbPass, dbHost, dbPort, dbName = mongoConfigs()
-	mongoDB, err := openMongo(dbUser, dbPass, dbHost, dbPort, dbName)
+	mongoDB, err := openMongo(&cfg.Mongo)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	binding.Validator = new(defaultValidator)
+	entity.BasePath = cfg.Server.BasePath
+	// the error responses link to the sections of their codes on this page
+	entity.ErrorDocsURL = cfg.Server.ErrorDocsURL
 	router := gin.New()
 	router.UseRawPath = true
+	// the client IPs, which the rate limits are keyed on, are only read from the headers of
+	// the proxies in front of the server
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal(err)
+	}
 
 	// Recovery middleware recovers from any panics and writes a 500 if there was one.
 	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
@@ -533,6 +957,10 @@ func main() {
 		c.AbortWithStatus(http.StatusInternalServerError)
 	}))
 
+	// Each request is the root span of the queries of its handlers, or a child of the span of
+	// the caller sending a traceparent header.
+	router.Use(otelgin.Middleware(tracingServiceName))
+
 	router.Use(gin.Logger())
 
 	// https://github.com/gin-gonic/gin/issues/1044
@@ -558,11 +986,22 @@ func main() {
 	router.Use(static.Serve("/project/settings/publish-notification", localFile))
 	router.Use(static.Serve("/login", localFile))
 
+	// Optional subsystems
+	// - The API starts without the subsystems which are not configured or not reachable, and
+	//   probes them every PPI_SUBSYSTEM_PROBE_INTERVAL, 60s by default. Their routes respond
+	//   503 while they are not up, and their states are reported by /health.
+	subsystemUsecase := usecase.NewSubsystems(subsystemProbeTimeout)
+	subsystemDelivery := delivery.NewSubsystems(
+		subsystemUsecase, cfg.Server.SubsystemProbeInterval,
+	)
+
 	// https://jira.ppi.co.jp/browse/POTOO-1402
 	healthCheck := func(c *gin.Context) {
 		if err := myDB.Ping(); err != nil {
 			c.Status(http.StatusInternalServerError)
-			log.Printf("ERROR: Could not connect to MySQL database %s. %s", dbName, err.Error())
+			log.Printf(
+				"ERROR: Could not connect to MySQL database %s. %s", cfg.MySQL.Name, err.Error(),
+			)
 			return
 		}
 
@@ -570,23 +1009,66 @@ func main() {
 		defer cancel()
 		if err := mongoDB.Client().Ping(ctx, readpref.Primary()); err != nil {
 			c.Status(http.StatusInternalServerError)
-			log.Printf("ERROR: Could not connect to MongoDB database %s. %s", dbName, err.Error())
+			log.Printf(
+				"ERROR: Could not connect to MongoDB database %s. %s", cfg.Mongo.Name, err.Error(),
+			)
 			return
 		}
 
-		c.String(http.StatusOK, "ok")
+		c.PureJSON(http.StatusOK, subsystemDelivery.Health())
 	}
 	router.GET("/health", healthCheck)
 	router.GET("/ready", healthCheck)
 
+	apiVersioning := delivery.NewAPIVersioning(cfg.Server.APIDeprecations)
+	responseEncoding := delivery.NewResponseEncoding(cfg.Server.JSONEncodings)
+
+	consistencyDelivery := delivery.NewConsistency(
+		usecase.NewConsistency(consistencyRepository, readTimeout),
+	)
+
+	capacityRepository, err := repository.NewCapacity(gormDB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	capacityDelivery := delivery.NewCapacity(usecase.NewCapacity(capacityRepository))
+
+	rateLimiter, err := repository.NewRateLimiterFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	rateLimitDelivery := delivery.NewRateLimit(usecase.NewRateLimit(rateLimiter))
+
+	unknownFieldsDelivery, err := delivery.NewUnknownFieldsFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	apiRouter := router.Group("/api")
+	apiRouter.Use(rateLimitDelivery.Limit)
+	apiRouter.Use(unknownFieldsDelivery.Track)
+	apiRouter.Use(capacityDelivery.Track)
+	apiRouter.Use(apiVersioning.Negotiate)
+	apiRouter.Use(responseEncoding.Encode)
+	apiRouter.Use(consistencyDelivery.Track)
 	{
 		myRepo := database.NewMySQLRepository(myDB)
 		mongoRepo := database.NewMongoRepository(mongoDB)
 		cs := service.NewCentralService(myRepo, mongoRepo)
-		neo4jDriver := newNeo4jDriverWithContext(ctx)
+		neo4jDriver, err := newNeo4jDriverWithContext(ctx, cfg.Neo4j)
 		if neo4jDriver != nil {
 			defer (*neo4jDriver).Close(ctx)
+			if err != nil {
+				log.Printf("WARNING: %s", err)
+			}
+			subsystemUsecase.Register(
+				entity.SubsystemDataDependency, (*neo4jDriver).VerifyConnectivity,
+			)
+		} else {
+			if err != nil {
+				log.Printf("WARNING: %s. Skip registering DataDependency API.", err)
+			}
+			subsystemUsecase.Disable(entity.SubsystemDataDependency, err)
 		}
 
 		// MARK: Repositories
@@ -607,6 +1089,18 @@ func main() {
 			dataDepRepo = repository.NewDataDepRepository(*neo4jDriver, gormDB)
 		}
 
+		// Faults simulated on Mongo and Neo4j, to check that the API degrades gracefully. The
+		// injection must never be enabled in production.
+		var faultInjector *repository.FaultInjector
+		var docRepo entity.DocumentRepository = mongoRepo
+		if cfg.FaultInjectionEnabled {
+			faultInjector = repository.NewFaultInjector()
+			docRepo = repository.NewFaultyDocumentRepository(mongoRepo, faultInjector)
+			if dataDepRepo != nil {
+				dataDepRepo.SetFaultInjector(faultInjector)
+			}
+		}
+
 		// MARK: Usecases (Services)
 
 		dataDepUsecase := usecase.NewDataDepUsecase(
@@ -647,29 +1141,161 @@ func main() {
 		if err != nil {
 			log.Fatalln(err)
 		}
-		authUsecase := usecase.NewAuth(authRepository, readTimeout, writeTimeout)
-		authDelivery := delivery.NewAuth(authUsecase)
-		router.Use(authDelivery.ParseQueryToken)
-		apiRouter.Use(authDelivery.ParseHeaderToken)
-		apiRouter.Use(authDelivery.CheckAccessPermission)
-		apiRouter.Use(authDelivery.CreateNewToken)
-		apiRouter.GET("/auth/parser")
-		apiRouter.POST("/auth/login", authDelivery.Login)
-
-		// Notification Middleware
-
-		notificationRepository, err := repository.NewNotification(gormDB, pipelineSettingRepository)
+		oidcRepository, err := repository.NewOIDC()
 		if err != nil {
 			log.Fatalln(err)
 		}
-		notificationUsecase := usecase.NewNotification(
-			notificationRepository,
-			readTimeout,
-			writeTimeout,
-		)
-		notificationDelivery := delivery.NewNotification(notificationUsecase)
+		apiKeyRepository, err := repository.NewAPIKey(gormDB, studioInfoRepository)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		// the routes requiring a permission are registered through the role delivery, whose
+		// route table also lists the permissions of tokens
+		roleRepository, err := repository.NewRole(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		roleDelivery := delivery.NewRole(
+			usecase.NewRole(
+				roleRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		authUsecase := usecase.NewAuth(
+			authRepository,
+			oidcRepository,
+			roleRepository,
+			apiKeyRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		authDelivery := delivery.NewAuth(authUsecase, roleDelivery, router.Routes)
+		apiKeyDelivery := delivery.NewAPIKey(
+			usecase.NewAPIKey(apiKeyRepository, readTimeout, writeTimeout),
+		)
+		router.Use(authDelivery.ParseQueryToken)
+		apiRouter.Use(apiKeyDelivery.Authenticate)
+		apiRouter.Use(authDelivery.ParseHeaderToken)
+		apiRouter.Use(rateLimitDelivery.LimitCredentials)
+		apiRouter.Use(authDelivery.CheckAccessPermission)
+		apiRouter.Use(authDelivery.CreateNewToken)
+		// the timing breakdown is restricted to admins, once the studio is known
+		apiRouter.Use(delivery.DebugTiming)
+		apiRouter.GET("/auth/parser")
+		apiRouter.POST("/auth/login", authDelivery.Login)
+		apiRouter.GET("/auth/oidc/login", authDelivery.OIDCLogin)
+		apiRouter.GET("/auth/oidc/callback", authDelivery.OIDCCallback)
+		apiRouter.GET("/auth/tokens/:id/permissions", authDelivery.GetTokenPermissions)
+
+		// API Key API
+		//
+		// Note: The API keys of the service accounts are sent in the X-Api-Key header in place
+		//       of a token, and are restricted to the routes and projects of their scope.
+
+		apiRouter.GET("/admin/apikeys", apiKeyDelivery.List)
+		apiRouter.POST("/admin/apikeys", apiKeyDelivery.Post)
+		apiRouter.GET("/admin/apikeys/:id", apiKeyDelivery.Get)
+		apiRouter.POST("/admin/apikeys/:id/rotate", apiKeyDelivery.Rotate)
+		apiRouter.DELETE("/admin/apikeys/:id", apiKeyDelivery.Delete)
+
+		// Client Compatibility API
+		//
+		// Note: The desktop tools check their version before logging in, so GET /api/compat
+		//       does not require a token.
+
+		clientCompatRepository, err := repository.NewClientCompat(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		clientCompatDelivery := delivery.NewClientCompat(
+			usecase.NewClientCompat(clientCompatRepository, readTimeout, writeTimeout),
+		)
+		apiRouter.GET("/compat", clientCompatDelivery.Get)
+		apiRouter.GET("/admin/compat", clientCompatDelivery.ListRules)
+		apiRouter.PUT("/admin/compat/:client", clientCompatDelivery.PutRule)
+		apiRouter.DELETE("/admin/compat/:client", clientCompatDelivery.DeleteRule)
+
+		// Client Heartbeat API
+		//
+		// Note: The desktop tools and DCC plugins send a heartbeat every few minutes while
+		//       running, from which the connected clients and the adoption of their versions
+		//       are reported to admins.
+
+		clientHeartbeatRepository, err := repository.NewClientHeartbeat(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		clientHeartbeatDelivery := delivery.NewClientHeartbeat(
+			usecase.NewClientHeartbeat(clientHeartbeatRepository, readTimeout, writeTimeout),
+		)
+		apiRouter.POST("/clients/heartbeat", clientHeartbeatDelivery.Post)
+		apiRouter.GET("/admin/clients", clientHeartbeatDelivery.List)
+		apiRouter.GET("/admin/clients/adoption", clientHeartbeatDelivery.Adoption)
+
+		// Notification Middleware
+
+		notificationRepository, err := repository.NewNotification(gormDB, pipelineSettingRepository)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if notificationRepository.ChatConfigured() {
+			subsystemUsecase.Register(
+				entity.SubsystemNotificationChat, notificationRepository.ProbeChat,
+			)
+		} else {
+			subsystemUsecase.Disable(entity.SubsystemNotificationChat, nil)
+		}
+		subsystemUsecase.Register(entity.SubsystemNotificationMail, notificationRepository.ProbeMail)
+		notificationOutboxRepository, err := repository.NewNotificationOutbox(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		webhookRepository, err := repository.NewWebhook(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		notificationUsecase := usecase.NewNotification(
+			notificationRepository,
+			notificationOutboxRepository,
+			webhookRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		go notificationUsecase.RunOutboxDispatcher(
+			context.Background(),
+			delivery.NewBackgroundLogger("notificationOutbox"),
+			30*time.Second,
+		)
+		notificationDelivery := delivery.NewNotification(notificationUsecase)
 		apiRouter.Use(notificationDelivery.SendNotification)
 
+		// Dead Letter API
+		// - Notifications of the outbox which failed too many times, restricted to admins.
+		apiRouter.GET("/notifications/deadLetters", notificationDelivery.ListDeadLetters)
+		apiRouter.GET("/notifications/deadLetters/stats", notificationDelivery.GetDeadLetterStats)
+		apiRouter.GET("/notifications/deadLetters/:id", notificationDelivery.GetDeadLetter)
+		apiRouter.POST("/notifications/deadLetters/retry", notificationDelivery.RetryDeadLetters)
+		apiRouter.POST(
+			"/notifications/deadLetters/:id/retry", notificationDelivery.RetryDeadLetter,
+		)
+		apiRouter.DELETE("/notifications/deadLetters", notificationDelivery.PurgeDeadLetters)
+
+		// API Version Usage API
+
+		apiRouter.GET("/apiVersions/usage", apiVersioning.ListUsage)
+
+		// Metrics API
+		// - http_retries: retries of the external services, e.g. bigquery.retried
+		// - notification_dead_letters: depth of the dead letter queue of the notifications
+		apiRouter.GET("/metrics", gin.WrapH(expvar.Handler()))
+
+		// Capacity API
+		// - Load and backoff advice for the clients throttling themselves, e.g. the farm
+		//   publisher; the in-flight requests are also exported as http_in_flight.
+		apiRouter.GET("/capacity", capacityDelivery.Get)
+
 		// License API
 
 		apiRouter.POST("/licenses", license.PostLicense)
@@ -703,13 +1329,26 @@ func main() {
 		apiRouter.DELETE("/studios/:studio", studioInfoDelivery.Delete)
 
 		// DataSyncClient API
-		dataSyncClientRepository := repository.NewDataSyncClient(
-			repository.ConnectedCloudLoggingFinder{Client: cloudLoggingClient},
-			getGCPProjectID(),
-		)
-		dataSyncClientUseCase := usecase.NewDataSyncClient(dataSyncClientRepository, readTimeout)
-		dataSyncClientDelivery := delivery.NewDataSyncClient(dataSyncClientUseCase)
-		apiRouter.GET("/projects/:project/studios/:studio/dataSyncClient/status", dataSyncClientDelivery.GetStatus)
+		//
+		// Note: The DataSyncClient API is only available when Cloud Logging could be opened.
+		if cloudLoggingErr == nil {
+			dataSyncClientRepository := repository.NewDataSyncClient(
+				repository.ConnectedCloudLoggingFinder{Client: cloudLoggingClient},
+				cfg.GCP.ProjectID,
+			)
+			dataSyncClientUseCase := usecase.NewDataSyncClient(dataSyncClientRepository, readTimeout)
+			dataSyncClientDelivery := delivery.NewDataSyncClient(dataSyncClientUseCase)
+			subsystemUsecase.Register(
+				entity.SubsystemDataSyncClient, probeCloudLogging(cloudLoggingClient),
+			)
+			apiRouter.GET(
+				"/projects/:project/studios/:studio/dataSyncClient/status",
+				subsystemDelivery.Require(entity.SubsystemDataSyncClient),
+				dataSyncClientDelivery.GetStatus,
+			)
+		} else {
+			subsystemUsecase.Disable(entity.SubsystemDataSyncClient, cloudLoggingErr)
+		}
 
 		// Dierctory API
 
@@ -753,31 +1392,228 @@ func main() {
 			studioDirectoryDelivery.Delete,
 		)
 
+		// Role API
+		apiRouter.GET("/admin/roles", roleDelivery.List)
+		apiRouter.GET("/admin/roles/:role", roleDelivery.Get)
+		apiRouter.PUT("/admin/roles/:role", roleDelivery.Put)
+		apiRouter.DELETE("/admin/roles/:role", roleDelivery.Delete)
+		apiRouter.GET("/admin/roles/:role/users", roleDelivery.ListUsers)
+		apiRouter.POST("/admin/roles/:role/users", roleDelivery.PostUser)
+		apiRouter.DELETE("/admin/roles/:role/users/:id", roleDelivery.DeleteUser)
+
+		// Invitation API
+		//
+		// Note: The invitations are managed by admins, and redeemed by any logged in user.
+
+		invitationRepository, err := repository.NewInvitation(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		invitationDelivery := delivery.NewInvitation(
+			usecase.NewInvitation(
+				invitationRepository,
+				roleRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/invitations", invitationDelivery.List)
+		apiRouter.POST("/invitations", invitationDelivery.Post)
+		apiRouter.POST("/invitations/redeem", invitationDelivery.Redeem)
+		apiRouter.GET("/invitations/:id", invitationDelivery.Get)
+		apiRouter.DELETE("/invitations/:id", invitationDelivery.Delete)
+
+		// Webhook API
+		// - Events of the project posted to the registered URLs through the outbox, signed
+		//   with the secret of each webhook: review.created, review.approval_changed and
+		//   publish.completed.
+
+		roleDelivery.Handle(
+			apiRouter, http.MethodGet, "/projects/:project/webhooks",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			notificationDelivery.ListWebhooks,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPost, "/projects/:project/webhooks",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			notificationDelivery.PostWebhook,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodGet, "/projects/:project/webhooks/:id",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			notificationDelivery.GetWebhook,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPut, "/projects/:project/webhooks/:id",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			notificationDelivery.UpdateWebhook,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodDelete, "/projects/:project/webhooks/:id",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			notificationDelivery.DeleteWebhook,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodGet, "/projects/:project/webhooks/:id/deliveries",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			notificationDelivery.ListWebhookDeliveries,
+		)
+
+		// Project Quota API
+		projectQuotaRepository, err := repository.NewProjectQuota(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		projectQuotaDelivery := delivery.NewProjectQuota(
+			usecase.NewProjectQuota(
+				projectQuotaRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/projects/:project/quotas", projectQuotaDelivery.List)
+		apiRouter.PUT("/projects/:project/quotas/:operation", projectQuotaDelivery.Update)
+		apiRouter.DELETE("/projects/:project/quotas/:operation", projectQuotaDelivery.Delete)
+
+		// Submission Limit API
+		submissionLimitRepository, err := repository.NewSubmissionLimit(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		submissionLimitDelivery := delivery.NewSubmissionLimit(
+			usecase.NewSubmissionLimit(
+				submissionLimitRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/projects/:project/submission-limit", submissionLimitDelivery.Get)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPut, "/projects/:project/submission-limit",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			submissionLimitDelivery.Update,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodDelete, "/projects/:project/submission-limit",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			submissionLimitDelivery.Delete,
+		)
+		apiRouter.POST(
+			"/projects/:project/submission-limit/overrides",
+			submissionLimitDelivery.CreateOverride,
+		)
+		apiRouter.DELETE(
+			"/projects/:project/submission-limit/overrides/:id",
+			submissionLimitDelivery.DeleteOverride,
+		)
+
 		// Review API
 
-		reviewInfoRepository, err := repository.NewReviewInfo(gormDB)
+		idGenerator := repository.NewIDGenerator(cfg.Server.IDMode)
+
+		customFieldRepository, err := repository.NewCustomField(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		watcherRepository, err := repository.NewWatcher(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		queryCache, err := repository.NewQueryCacheFromEnv()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reviewInfoRepository, err := repository.NewReviewInfo(gormDB, idGenerator, queryCache)
 		if err != nil {
 			log.Fatalln(err)
 		}
+		go backfillTakeNumbers(gormDB, reviewInfoRepository)
 		reviewInfoUsecase := usecase.NewReviewInfo(
 			reviewInfoRepository,
 			projectInfoRepository,
 			studioInfoRepository,
-			mongoRepo,
+			docRepo,
+			dataDepRepo,
+			customFieldRepository,
+			watcherRepository,
+			notificationOutboxRepository,
+			submissionLimitRepository,
 			readTimeout,
 			writeTimeout,
 		)
+		go reviewInfoUsecase.RunManifestExpiry(
+			context.Background(),
+			delivery.NewBackgroundLogger("review-manifest"),
+			time.Minute,
+		)
 		reviewInfoDelivery := delivery.NewReviewInfo(
 			reviewInfoUsecase,
 		)
-		apiRouter.GET("/projects/:project/reviews", reviewInfoDelivery.List)
-		apiRouter.GET("/projects/:project/reviews/:id", reviewInfoDelivery.Get)
-		apiRouter.POST("/projects/:project/reviews", reviewInfoDelivery.Post)
-		apiRouter.PATCH("/projects/:project/reviews/:id", reviewInfoDelivery.Update)
+		roleDelivery.Handle(
+			apiRouter, http.MethodGet, "/projects/:project/reviews",
+			&entity.PermissionRequirement{Permission: entity.PermissionReviewRead},
+			reviewInfoDelivery.List,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodGet, "/projects/:project/reviews/:id",
+			&entity.PermissionRequirement{Permission: entity.PermissionReviewRead},
+			reviewInfoDelivery.Get,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPost, "/projects/:project/reviews",
+			&entity.PermissionRequirement{Permission: entity.PermissionReviewSubmit},
+			reviewInfoDelivery.Post,
+		)
+		// only supervisors may change the approval status
+		roleDelivery.Handle(
+			apiRouter, http.MethodPatch, "/projects/:project/reviews/:id",
+			&entity.PermissionRequirement{
+				Permission: entity.PermissionReviewApprove,
+				Fields:     []string{"approval_status"},
+			},
+			reviewInfoDelivery.Update,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPut, "/projects/:project/reviews/:id/manifest",
+			&entity.PermissionRequirement{Permission: entity.PermissionReviewSubmit},
+			reviewInfoDelivery.PutManifest,
+		)
 		apiRouter.DELETE("/projects/:project/reviews/:id", reviewInfoDelivery.Delete)
-		apiRouter.GET("/projects/:project/reviews/assets", reviewInfoDelivery.ListAssets)
+		// the deleted reviews can be restored until an admin purges them
+		apiRouter.GET("/projects/:project/reviews/deleted", reviewInfoDelivery.ListDeleted)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPost, "/projects/:project/reviews/:id/restore",
+			&entity.PermissionRequirement{Permission: entity.PermissionReviewSubmit},
+			reviewInfoDelivery.Restore,
+		)
+		apiRouter.POST("/projects/:project/reviews/purge", reviewInfoDelivery.Purge)
+		apiRouter.GET("/projects/:project/reviews/:id/auditLogs", reviewInfoDelivery.ListAuditLogs)
 		apiRouter.GET(
+			"/projects/:project/sequences/:sequence/rollup", reviewInfoDelivery.GetSequenceRollup,
+		)
+		apiRouter.GET("/projects/:project/reviewIntentSetting", reviewInfoDelivery.GetIntentSetting)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPut, "/projects/:project/reviewIntentSetting",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			reviewInfoDelivery.UpdateIntentSetting,
+		)
+		apiRouter.GET("/projects/:project/reviewApprovalGates", reviewInfoDelivery.ListApprovalGates)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPut, "/projects/:project/reviewApprovalGates/:phase",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			reviewInfoDelivery.UpdateApprovalGate,
+		)
+		apiRouter.POST("/projects/:project/reviewLatest\\:rebuild", reviewInfoDelivery.RebuildLatest)
+		apiRouter.GET("/projects/:project/reviews/assets", reviewInfoDelivery.ListAssets)
+		roleDelivery.Handle(
+			apiRouter, http.MethodGet,
 			"/projects/:project/assets/:asset/relations/:relation/reviewInfos",
+			&entity.PermissionRequirement{Permission: entity.PermissionReviewRead},
 			reviewInfoDelivery.ListAssetReviewInfos,
 		)
 		// Assets Pivot API - returns latest review info per asset
@@ -786,6 +1622,362 @@ func main() {
 		// Shots ReviewInfo API
 		apiRouter.GET("/projects/:project/shots/reviewInfos", reviewInfoDelivery.ListShotReviewInfos)
 
+		// Status Mapping API
+		statusMappingRepository, err := repository.NewStatusMapping(gormDB, queryCache)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		statusMappingDelivery := delivery.NewStatusMapping(
+			usecase.NewStatusMapping(
+				statusMappingRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/projects/:project/status-mappings", statusMappingDelivery.List)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPut, "/projects/:project/status-mappings",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			statusMappingDelivery.Update,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodDelete, "/projects/:project/status-mappings/:id",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			statusMappingDelivery.Delete,
+		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodPost, "/projects/:project/status-mappings/backfill",
+			&entity.PermissionRequirement{Permission: entity.PermissionProjectManage},
+			statusMappingDelivery.Backfill,
+		)
+
+		// Watcher API
+		watcherDelivery := delivery.NewWatcher(
+			usecase.NewWatcher(
+				watcherRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/projects/:project/watchers", watcherDelivery.List)
+		apiRouter.POST("/projects/:project/watchers", watcherDelivery.Post)
+		apiRouter.DELETE("/projects/:project/watchers/:id", watcherDelivery.Delete)
+
+		// Work Queue API
+		workQueueDelivery := delivery.NewWorkQueue(
+			usecase.NewWorkQueue(
+				repository.NewWorkQueue(gormDB),
+				watcherRepository,
+				readTimeout,
+			),
+		)
+		apiRouter.GET("/users/me/work", workQueueDelivery.List)
+
+		// Review Transcode API
+		transcoder, err := repository.NewTranscoder(cfg.Server.TranscodeBackend)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reviewTranscodeRepository, err := repository.NewReviewTranscode(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reviewTranscodeUsecase := usecase.NewReviewTranscode(
+			reviewTranscodeRepository,
+			reviewInfoRepository,
+			projectInfoRepository,
+			transcoder,
+			readTimeout,
+			writeTimeout,
+		)
+		go reviewTranscodeUsecase.RunWorker(
+			context.Background(),
+			delivery.NewBackgroundLogger("reviewTranscode"),
+			30*time.Second,
+		)
+		reviewTranscodeDelivery := delivery.NewReviewTranscode(reviewTranscodeUsecase)
+		apiRouter.GET("/projects/:project/reviews/:id/transcode", reviewTranscodeDelivery.List)
+		apiRouter.POST(
+			"/projects/:project/reviews/:id/transcode",
+			projectQuotaDelivery.Enforce(entity.QuotaTranscode),
+			reviewTranscodeDelivery.Post,
+		)
+
+		// File Hash API
+		fileHashRepository, err := repository.NewFileHash(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fileHashDelivery := delivery.NewFileHash(
+			usecase.NewFileHash(
+				fileHashRepository,
+				reviewInfoRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.POST("/projects/:project/reviews/:id/fileHashes", fileHashDelivery.Register)
+		apiRouter.GET("/projects/:project/files/byHash/:sha", fileHashDelivery.ListByHash)
+		apiRouter.GET("/projects/:project/files/duplicates", fileHashDelivery.DuplicateReport)
+		apiRouter.POST("/projects/:project/files/negotiate", fileHashDelivery.NegotiateUpload)
+
+		// Take Comparison API
+		contactSheetRepository, err := repository.NewContactSheet()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		// also read by the take comparison, before the Review Status Log API
+		reviewStatusLogRepository, err := repository.NewReviewStatusLog(gormDB, idGenerator)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		takeComparisonDelivery := delivery.NewTakeComparison(
+			usecase.NewTakeComparison(
+				contactSheetRepository,
+				reviewInfoRepository,
+				projectInfoRepository,
+				reviewStatusLogRepository,
+				docRepo,
+				readTimeout,
+			),
+		)
+		apiRouter.GET(
+			"/projects/:project/reviewContactSheet",
+			takeComparisonDelivery.GetContactSheet,
+		)
+		apiRouter.GET(
+			"/projects/:project/assets/:asset/relations/:relation/phases/:phase/takes/compare",
+			takeComparisonDelivery.CompareTakes,
+		)
+
+		// Reclamation API
+		reclamationRepository, err := repository.NewReclamation(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reclamationDelivery := delivery.NewReclamation(usecase.NewReclamation(
+			reclamationRepository,
+			projectInfoRepository,
+			readTimeout,
+			writeTimeout,
+		))
+		apiRouter.GET("/projects/:project/reclamations", reclamationDelivery.List)
+		apiRouter.POST("/projects/:project/reclamations", reclamationDelivery.Post)
+		apiRouter.GET("/projects/:project/reclamations/:id", reclamationDelivery.Get)
+		apiRouter.GET("/projects/:project/reclamations/:id/manifest", reclamationDelivery.Manifest)
+		apiRouter.POST(
+			"/projects/:project/reclamations/:id/review",
+			reclamationDelivery.Review,
+		)
+		apiRouter.POST(
+			"/projects/:project/reclamations/:id/complete",
+			reclamationDelivery.Complete,
+		)
+
+		// Work Calendar API
+		workCalendarRepository, err := repository.NewWorkCalendar(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		workCalendarUsecase := usecase.NewWorkCalendar(
+			workCalendarRepository,
+			studioInfoRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		workCalendarDelivery := delivery.NewWorkCalendar(workCalendarUsecase)
+		apiRouter.GET("/workCalendars", workCalendarDelivery.List)
+		apiRouter.GET("/studios/:studio/workCalendar", workCalendarDelivery.Get)
+		apiRouter.PUT("/studios/:studio/workCalendar", workCalendarDelivery.Update)
+		apiRouter.DELETE("/studios/:studio/workCalendar", workCalendarDelivery.Delete)
+
+		// Review SLA API
+		reviewSLARepository, err := repository.NewReviewSLA(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reviewSLAUsecase := usecase.NewReviewSLA(
+			reviewSLARepository,
+			projectInfoRepository,
+			notificationOutboxRepository,
+			workCalendarRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		go reviewSLAUsecase.RunEvaluator(
+			context.Background(),
+			delivery.NewBackgroundLogger("reviewSLA"),
+			5*time.Minute,
+		)
+		reviewSLADelivery := delivery.NewReviewSLA(reviewSLAUsecase)
+		apiRouter.GET("/projects/:project/reviewSLAs", reviewSLADelivery.List)
+		apiRouter.PUT("/projects/:project/reviewSLAs/:phase", reviewSLADelivery.Update)
+		apiRouter.DELETE("/projects/:project/reviewSLAs/:phase", reviewSLADelivery.Delete)
+		apiRouter.GET("/projects/:project/reviewSLABreaches", reviewSLADelivery.ListBreaches)
+
+		// Review Digest API
+		reviewDigestRepository, err := repository.NewReviewDigestFromEnv(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reviewDigestUsecase := usecase.NewReviewDigest(
+			reviewDigestRepository,
+			reviewSLARepository,
+			readTimeout,
+			writeTimeout,
+		)
+		if interval := reviewDigestUsecase.Interval(); interval > 0 {
+			go reviewDigestUsecase.RunScheduler(
+				context.Background(),
+				delivery.NewBackgroundLogger("reviewDigest"),
+				interval,
+			)
+		}
+		reviewDigestDelivery := delivery.NewReviewDigest(reviewDigestUsecase)
+		apiRouter.POST("/internal/digests/run", reviewDigestDelivery.Run)
+
+		// Report API
+		reportUsecase := usecase.NewReport(
+			repository.NewReport(gormDB),
+			projectInfoRepository,
+			workCalendarRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		reportDelivery := delivery.NewReport(reportUsecase)
+		apiRouter.GET("/projects/:project/reports/reviewerLoad", reportDelivery.GetReviewerLoad)
+		apiRouter.GET(
+			"/projects/:project/reports/submissionHeatmap", reportDelivery.GetSubmissionHeatmap,
+		)
+
+		// Compliance Report API
+		complianceRepository, err := repository.NewCompliance(gormDB, projectStudioMapRepository)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		complianceDelivery := delivery.NewCompliance(
+			usecase.NewCompliance(
+				complianceRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/projects/:project/reports/compliance", complianceDelivery.GetReport)
+
+		// Activity API
+		activityUsecase := usecase.NewActivity(
+			repository.NewActivity(gormDB),
+			projectInfoRepository,
+			readTimeout,
+		)
+		activityDelivery := delivery.NewActivity(activityUsecase)
+		apiRouter.GET("/projects/:project/activity", activityDelivery.List)
+
+		// Custom Field API
+		customFieldUsecase := usecase.NewCustomField(
+			customFieldRepository,
+			projectInfoRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		customFieldDelivery := delivery.NewCustomField(customFieldUsecase)
+		apiRouter.GET("/projects/:project/customFields", customFieldDelivery.List)
+		apiRouter.POST("/projects/:project/customFields", customFieldDelivery.Post)
+		apiRouter.PATCH("/projects/:project/customFields/:id", customFieldDelivery.Update)
+		apiRouter.DELETE("/projects/:project/customFields/:id", customFieldDelivery.Delete)
+		apiRouter.GET(
+			"/projects/:project/assets/:asset/relations/:relation/metadata",
+			customFieldDelivery.GetAssetMetadata,
+		)
+		apiRouter.PATCH(
+			"/projects/:project/assets/:asset/relations/:relation/metadata",
+			customFieldDelivery.UpdateAssetMetadata,
+		)
+
+		// Schema API
+		schemaUsecase := usecase.NewSchema(
+			repository.NewSchema(gormDB),
+			projectInfoRepository,
+			customFieldRepository,
+			readTimeout,
+		)
+		schemaDelivery := delivery.NewSchema(schemaUsecase)
+		apiRouter.GET("/schemas/reviewInfo", schemaDelivery.GetReviewInfo)
+
+		// Tag API
+		tagRepository, err := repository.NewTag(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		tagUsecase := usecase.NewTag(
+			tagRepository,
+			projectInfoRepository,
+			reviewInfoRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		tagDelivery := delivery.NewTag(tagUsecase)
+		apiRouter.GET("/projects/:project/tags", tagDelivery.List)
+		apiRouter.POST("/projects/:project/tags", tagDelivery.Post)
+		apiRouter.PATCH("/projects/:project/tags/:id", tagDelivery.Update)
+		apiRouter.DELETE("/projects/:project/tags/:id", tagDelivery.Delete)
+		apiRouter.GET("/projects/:project/tagTargets", tagDelivery.GetTarget)
+		apiRouter.POST("/projects/:project/tagTargets", tagDelivery.Attach)
+		apiRouter.DELETE("/projects/:project/tagTargets", tagDelivery.Detach)
+
+		// Asset Rename API
+		assetRenameRepository, err := repository.NewAssetRename(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		assetRenameDelivery := delivery.NewAssetRename(
+			usecase.NewAssetRename(
+				assetRenameRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/projects/:project/assetRenames", assetRenameDelivery.List)
+		apiRouter.POST("/projects/:project/assets\\:rename", assetRenameDelivery.Rename)
+
+		// Phase Template API
+		phaseTemplateRepository := repository.NewPhaseTemplate(gormDB, pipelineSettingRepository)
+		phaseTemplateUsecase := usecase.NewPhaseTemplate(
+			phaseTemplateRepository,
+			projectInfoRepository,
+			readTimeout,
+		)
+		phaseTemplateDelivery := delivery.NewPhaseTemplate(phaseTemplateUsecase)
+		apiRouter.GET("/projects/:project/phaseTemplate", phaseTemplateDelivery.Get)
+
+		// Directory Template API
+		directoryTemplateRepository, err := repository.NewDirectoryTemplate(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		directoryTemplateUsecase := usecase.NewDirectoryTemplate(
+			directoryTemplateRepository,
+			projectInfoRepository,
+			phaseTemplateRepository,
+			directoryUsecase,
+			readTimeout,
+			writeTimeout,
+		)
+		directoryTemplateDelivery := delivery.NewDirectoryTemplate(directoryTemplateUsecase)
+		apiRouter.GET("/projects/:project/directoryTemplates", directoryTemplateDelivery.List)
+		apiRouter.POST("/projects/:project/directoryTemplates", directoryTemplateDelivery.Post)
+		apiRouter.PATCH("/projects/:project/directoryTemplates/:id", directoryTemplateDelivery.Update)
+		apiRouter.DELETE("/projects/:project/directoryTemplates/:id", directoryTemplateDelivery.Delete)
+		apiRouter.POST(
+			"/projects/:project/assets/:asset/scaffold",
+			directoryTemplateDelivery.ScaffoldAsset,
+		)
+
 		/* ========================================================
 		   Assets Pivot API (Expanded Implementation)
 			router.GET("/api/projects/:project/reviews/assets/pivot", func(c *gin.Context) {
@@ -799,23 +1991,16 @@ func main() {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "project is required in the path"})
 				return
 			}
+			maxStaleness, err := delivery.MaxStaleness(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 
 			root := c.DefaultQuery("root", defaultRoot)
 
-			// ---- Phase Validation ----
+			// ---- Phase, validated against the phase template below ----
 			phaseParam := strings.TrimSpace(c.Query("phase"))
-			if phaseParam != "" {
-				lp := strings.ToLower(phaseParam)
-				if lp != "none" {
-					if _, ok := allowedPhases[lp]; !ok {
-						c.JSON(http.StatusBadRequest, gin.H{
-							"error":          "invalid phase",
-							"allowed_phases": []string{"mdl", "rig", "bld", "dsn", "ldv", "none"},
-						})
-						return
-					}
-				}
-			}
 
 			// ---- Pagination ----
 			page := mustAtoi(c.DefaultQuery("page", "1"))
@@ -823,12 +2008,20 @@ func main() {
 			perPage := clampPerPage(mustAtoi(c.DefaultQuery("per_page", fmt.Sprint(defaultPerPage))))
 			limit := perPage
 			offset := (page - 1) * perPage
+			// The cursor of a list view page continues after it, in place of page.
+			cursor := strings.TrimSpace(c.Query("cursor"))
 
 			// ---- Sorting ----
 			sortParam := c.DefaultQuery("sort", "group_1")
 			dirParam := c.DefaultQuery("dir", "ASC")
 			orderKey := normalizeSortKey(sortParam)
 			dir := normalizeDir(dirParam)
+			// a multi-key sort, e.g. mdl_appr:desc,group_1:asc, overrides sort and dir
+			sortKeys, err := delivery.PivotSort(sortParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 
 			// ---- View Mode ----
 			viewParam := strings.ToLower(strings.TrimSpace(c.DefaultQuery("view", "list")))
@@ -838,59 +2031,126 @@ func main() {
 			assetNameKey := strings.TrimSpace(c.Query("name"))
 			approvalStatuses := parseStatusParam(c, "approval_status")
 			workStatuses := parseStatusParam(c, "work_status")
-
-			// ---- Preferred Phase Logic ----
-			preferredPhase := phaseParam
-			if orderKey == "group1_only" || orderKey == "relation_only" || orderKey == "group_rel_submitted" {
-				preferredPhase = "none"
-			}
-			if preferredPhase == "" {
-				preferredPhase = "none"
+			phaseApprovalStatuses, phaseWorkStatuses := delivery.PhaseStatusFilters(
+				c.Request.URL.Query(),
+			)
+			officialOnly, _ := strconv.ParseBool(c.DefaultQuery("official_only", "false"))
+			intents := parseStatusParam(c, "intent")
+			metadata := delivery.MetadataFilters(c.Request.URL.Query())
+			tags := delivery.TagFilters(c)
+			// latest_approved reads the phase columns from the latest approved reviews
+			phaseValue := strings.ToLower(strings.TrimSpace(c.Query("phase_value")))
+			var timeFilters repository.ListAssetsPivotParams
+			if err := delivery.PivotTimeFilters(c, &timeFilters); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
 			}
 
 			ctx, cancel := context.WithTimeout(c.Request.Context(), 7*time.Second)
 			defer cancel()
 
+			// ---- Phase columns, ordered by the project's phase template ----
+			phaseTemplate, err := phaseTemplateRepository.Get(
+				reviewInfoRepository.WithContext(ctx),
+				&entity.GetPhaseTemplateParams{Project: project, Root: root},
+			)
+			if err != nil {
+				log.Printf("[pivot-submissions] phase template error for project %q: %v", project, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+				return
+			}
+			var phases []string
+			if !phaseTemplate.Default {
+				phases = phaseTemplate.Phases
+			}
+			// roots without any template have the pivot columns of the assets root
+			allowedPhases := phaseTemplate.Phases
+			if len(allowedPhases) == 0 {
+				allowedPhases = entity.DefaultPhaseTemplates[defaultRoot]
+			}
+			if lp := strings.ToLower(phaseParam); lp != "" && lp != "none" &&
+				!slices.Contains(allowedPhases, lp) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":          "invalid phase",
+					"allowed_phases": append(slices.Clone(allowedPhases), "none"),
+				})
+				return
+			}
+
 			// ---------------------------------------------------------------
 			// CASE 1: LIST VIEW - keep current DB pagination behavior
 			// ---------------------------------------------------------------
 			if !isGroupedView {
-				assets, total, err := reviewInfoRepository.ListAssetsPivot(
-					ctx,
-					project, root,
-					preferredPhase,
-					orderKey,
-					dir,
-					limit, offset,
-					assetNameKey,
-					approvalStatuses,
-					workStatuses,
+				result, err := reviewInfoRepository.ListAssetsPivot(
+					reviewInfoRepository.WithContext(ctx),
+					repository.ListAssetsPivotParams{
+						Project:               project,
+						Root:                  root,
+						View:                  "list",
+						Page:                  page,
+						PerPage:               perPage,
+						OrderKey:              orderKey,
+						Direction:             dir,
+						Sort:                  sortKeys,
+						AssetNameKey:          assetNameKey,
+						ApprovalStatuses:      approvalStatuses,
+						WorkStatuses:          workStatuses,
+						PhaseApprovalStatuses: phaseApprovalStatuses,
+						PhaseWorkStatuses:     phaseWorkStatuses,
+						SubmittedFrom:         timeFilters.SubmittedFrom,
+						SubmittedTo:           timeFilters.SubmittedTo,
+						ModifiedFrom:          timeFilters.ModifiedFrom,
+						ModifiedTo:            timeFilters.ModifiedTo,
+						OfficialOnly:          officialOnly,
+						Intents:               intents,
+						Metadata:              metadata,
+						Tags:                  tags,
+						Phases:                phases,
+						Cursor:                cursor,
+						PhaseValue:            phaseValue,
+						MaxStaleness:          maxStaleness,
+					},
 				)
+				if errors.Is(err, entity.ErrBadRequest) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
 				if err != nil {
 					log.Printf("[pivot-submissions] query error for project %q: %v", project, err)
 					c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
 					return
 				}
+				assets, total := result.Assets, result.Total
 
-				c.Header("Cache-Control", "public, max-age=15")
-				baseURL := fmt.Sprintf("/api/projects/%s/reviews/assets/pivot", project)
+				delivery.CacheControl(c, 15*time.Second, maxStaleness)
+				baseURL := entity.PublicPath(fmt.Sprintf("/api/projects/%s/reviews/assets/pivot", project))
 				if links := paginationLinks(baseURL, page, perPage, int(total)); links != "" {
-					c.Header("Link", links)
+					c.Writer.Header().Add("Link", links)
 				}
 
 				resp := gin.H{
-					"assets":    assets,
-					"total":     total,
-					"page":      page,
-					"per_page":  perPage,
-					"sort":      sortParam,
-					"dir":       strings.ToLower(dir),
-					"project":   project,
-					"root":      root,
-					"has_next":  offset+limit < int(total),
-					"has_prev":  page > 1,
-					"page_last": (int(total) + perPage - 1) / perPage,
-					"view":      viewParam,
+					"assets":               assets,
+					"total":                total,
+					"page":                 page,
+					"per_page":             perPage,
+					"sort":                 sortParam,
+					"dir":                  strings.ToLower(dir),
+					"project":              project,
+					"root":                 root,
+					"has_next":             offset+limit < int(total),
+					"has_prev":             page > 1,
+					"page_last":            (int(total) + perPage - 1) / perPage,
+					"view":                 viewParam,
+					"phases":               phaseTemplate.Phases,
+					"data_as_of":           result.DataAsOf,
+					"categories_available": result.CategoriesAvailable,
+				}
+				if result.NextCursor != "" {
+					resp["next_cursor"] = result.NextCursor
+				}
+				if cursor != "" {
+					resp["has_next"] = result.HasNext
+					resp["has_prev"] = result.HasPrev
 				}
 				if phaseParam != "" {
 					resp["phase"] = phaseParam
@@ -904,93 +2164,98 @@ func main() {
 				if len(workStatuses) > 0 {
 					resp["work_status"] = workStatuses
 				}
+				if len(phaseApprovalStatuses) > 0 {
+					resp["phase_approval_status"] = phaseApprovalStatuses
+				}
+				if len(phaseWorkStatuses) > 0 {
+					resp["phase_work_status"] = phaseWorkStatuses
+				}
+				if officialOnly {
+					resp["official_only"] = true
+				}
+				if len(intents) > 0 {
+					resp["intent"] = intents
+				}
+				if phaseValue != "" {
+					resp["phase_value"] = phaseValue
+				}
 
+				stopSerialize := entity.StartTiming(c.Request.Context(), entity.TimingSerialize)
 				c.IndentedJSON(http.StatusOK, resp)
+				stopSerialize()
 				return
 			}
 
 			// ---------------------------------------------------------------
-			// CASE 2: GROUPED VIEW - group first, then paginate
+			// CASE 2: GROUPED VIEW - grouped and paginated in SQL
 			// ---------------------------------------------------------------
-
-			// 1) Fetch ALL matching assets (no pagination here).
-			//    We still let the repo compute "total" for us.
-			//    Use a very large limit and offset=0,
-			//    or create a dedicated "ListAllAssetsPivot" if you prefer.
-			allLimit := 1000000
-			assetsAll, total, err := reviewInfoRepository.ListAssetsPivot(
-				ctx,
-				project, root,
-				preferredPhase,
-				"group1_only", // base: stable order by name
-				"ASC",
-				allLimit, 0,
-				assetNameKey,
-				approvalStatuses,
-				workStatuses,
+			resultPage, err := reviewInfoRepository.ListAssetsPivotGroups(
+				reviewInfoRepository.WithContext(ctx),
+				repository.ListAssetsPivotParams{
+					Project:               project,
+					Root:                  root,
+					View:                  "group",
+					Page:                  page,
+					PerPage:               perPage,
+					OrderKey:              "group1_only", // base: stable order by name
+					Direction:             "ASC",
+					AssetNameKey:          assetNameKey,
+					ApprovalStatuses:      approvalStatuses,
+					WorkStatuses:          workStatuses,
+					PhaseApprovalStatuses: phaseApprovalStatuses,
+					PhaseWorkStatuses:     phaseWorkStatuses,
+					SubmittedFrom:         timeFilters.SubmittedFrom,
+					SubmittedTo:           timeFilters.SubmittedTo,
+					ModifiedFrom:          timeFilters.ModifiedFrom,
+					ModifiedTo:            timeFilters.ModifiedTo,
+					OfficialOnly:          officialOnly,
+					Intents:               intents,
+					Metadata:              metadata,
+					Tags:                  tags,
+					Phases:                phases,
+					PhaseValue:            phaseValue,
+					MaxStaleness:          maxStaleness,
+				},
 			)
+			if errors.Is(err, entity.ErrBadRequest) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			if err != nil {
 				log.Printf("[pivot-submissions] query error (group view) for project %q: %v", project, err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
 				return
 			}
-
-			// 2) Group ALL assets by top_group_node
-			dirUpper := strings.ToUpper(dir)
-			if dirUpper != "ASC" && dirUpper != "DESC" {
-				dirUpper = "ASC"
-			}
-			groupedAll := repository.GroupAndSortByTopNode(
-				assetsAll,
-				repository.SortDirection(dirUpper),
-			)
-
-			// 3) Flatten groups in that order → flat slice in group order
-			flat := make([]repository.AssetPivot, 0, len(assetsAll))
-			for _, g := range groupedAll {
-				flat = append(flat, g.Items...)
-			}
-
-			// 4) Apply pagination on the flat, grouped-ordered slice
-			totalAssets := len(flat)
-			start := offset
-			if start > totalAssets {
-				start = totalAssets
-			}
-			end := start + limit
-			if end > totalAssets {
-				end = totalAssets
-			}
-			pageSlice := flat[start:end]
-
-			// 5) Re-group only the current page slice
-			pageGroups := repository.GroupAndSortByTopNode(
-				pageSlice,
-				repository.SortDirection(dirUpper),
-			)
+			total := resultPage.Total
 
 			// ---- Headers ----
-			c.Header("Cache-Control", "public, max-age=15")
-			baseURL := fmt.Sprintf("/api/projects/%s/reviews/assets/pivot", project)
+			delivery.CacheControl(c, 15*time.Second, maxStaleness)
+			baseURL := entity.PublicPath(fmt.Sprintf("/api/projects/%s/reviews/assets/pivot", project))
 			if links := paginationLinks(baseURL, page, perPage, int(total)); links != "" {
-				c.Header("Link", links)
+				c.Writer.Header().Add("Link", links)
 			}
 
 			// ---- Response ----
 			resp := gin.H{
-				"assets":    pageSlice, // optional: keep flat slice for debugging / UI
-				"groups":    pageGroups,
-				"total":     total, // total number of matching assets
-				"page":      page,
-				"per_page":  perPage,
-				"sort":      sortParam,
-				"dir":       strings.ToLower(dir),
-				"project":   project,
-				"root":      root,
-				"has_next":  offset+limit < int(totalAssets),
-				"has_prev":  page > 1,
-				"page_last": (int(totalAssets) + perPage - 1) / perPage,
-				"view":      viewParam,
+				"groups":               resultPage.Groups,
+				"total":                total, // total number of matching assets
+				"page":                 page,
+				"per_page":             perPage,
+				"sort":                 sortParam,
+				"dir":                  strings.ToLower(dir),
+				"project":              project,
+				"root":                 root,
+				"has_next":             offset+limit < int(total),
+				"has_prev":             page > 1,
+				"page_last":            (int(total) + perPage - 1) / perPage,
+				"view":                 viewParam,
+				"phases":               phaseTemplate.Phases,
+				"data_as_of":           resultPage.DataAsOf,
+				"categories_available": resultPage.CategoriesAvailable,
+			}
+			// The flat slice duplicates the groups and is only kept for API version 1 clients.
+			if delivery.RequestAPIVersion(c) < delivery.APIVersion2 {
+				resp["assets"] = resultPage.Assets
 			}
 
 			if phaseParam != "" {
@@ -1005,23 +2270,197 @@ func main() {
 			if len(workStatuses) > 0 {
 				resp["work_status"] = workStatuses
 			}
+			if len(phaseApprovalStatuses) > 0 {
+				resp["phase_approval_status"] = phaseApprovalStatuses
+			}
+			if len(phaseWorkStatuses) > 0 {
+				resp["phase_work_status"] = phaseWorkStatuses
+			}
+			if officialOnly {
+				resp["official_only"] = true
+			}
+			if len(intents) > 0 {
+				resp["intent"] = intents
+			}
+			if phaseValue != "" {
+				resp["phase_value"] = phaseValue
+			}
 
+			stopSerialize := entity.StartTiming(c.Request.Context(), entity.TimingSerialize)
 			c.IndentedJSON(http.StatusOK, resp)
+			stopSerialize()
+		})
+
+		// Counts per phase and status of the assets matching the pivot filters.
+		apiRouter.GET("/projects/:project/reviews/assets/pivot/summary", func(c *gin.Context) {
+			params, ok := pivotQueryParams(c)
+			if !ok {
+				return
+			}
+			if params.Project == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "project is required in the path"})
+				return
+			}
+			maxStaleness, err := delivery.MaxStaleness(c)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request.Context(), 7*time.Second)
+			defer cancel()
+			dataAsOf := time.Now().UTC()
+
+			phaseTemplate, err := phaseTemplateRepository.Get(
+				reviewInfoRepository.WithContext(ctx),
+				&entity.GetPhaseTemplateParams{Project: params.Project, Root: params.Root},
+			)
+			if err != nil {
+				log.Printf("[pivot-summary] phase template error for project %q: %v", params.Project, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+				return
+			}
+			if !phaseTemplate.Default {
+				params.Phases = phaseTemplate.Phases
+			}
+
+			summary, err := reviewInfoRepository.SummarizeAssetsPivot(
+				reviewInfoRepository.WithContext(ctx),
+				params,
+			)
+			if errors.Is(err, entity.ErrBadRequest) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				log.Printf("[pivot-summary] query error for project %q: %v", params.Project, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+				return
+			}
+
+			delivery.CacheControl(c, 15*time.Second, maxStaleness)
+			stopSerialize := entity.StartTiming(c.Request.Context(), entity.TimingSerialize)
+			c.IndentedJSON(http.StatusOK, gin.H{
+				"project":    params.Project,
+				"root":       params.Root,
+				"total":      summary.Total,
+				"phases":     summary.Phases,
+				"data_as_of": dataAsOf,
+			})
+			stopSerialize()
+		})
+
+		// Pivot Snapshot API
+		pivotSnapshotRepository, err := repository.NewPivotSnapshot(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		pivotSnapshotUsecase := usecase.NewPivotSnapshot(
+			pivotSnapshotRepository,
+			reviewInfoRepository,
+			phaseTemplateRepository,
+			projectInfoRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		pivotSnapshotDelivery := delivery.NewPivotSnapshot(pivotSnapshotUsecase)
+		apiRouter.POST("/projects/:project/reviews/assets/pivot/share", func(c *gin.Context) {
+			view := "list"
+			switch strings.ToLower(strings.TrimSpace(c.DefaultQuery("view", "list"))) {
+			case "group", "grouped", "category":
+				view = "group"
+			}
+			params, ok := pivotQueryParams(c)
+			if !ok {
+				return
+			}
+			pivotSnapshotDelivery.Post(c, params, view)
 		})
+		apiRouter.DELETE(
+			"/projects/:project/reviews/assets/pivot/share/:id",
+			pivotSnapshotDelivery.Delete,
+		)
+		// The share links are public, outside of the token authentication of apiRouter.
+		router.GET("/api/public/pivotSnapshots/:id", pivotSnapshotDelivery.GetShared)
+
+		// Pivot Diff API
+		assetStatusSnapshotRepository, err := repository.NewAssetStatusSnapshot(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		assetStatusSnapshotUsecase := usecase.NewAssetStatusSnapshot(
+			assetStatusSnapshotRepository,
+			projectInfoRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		go assetStatusSnapshotUsecase.RunSnapshotter(
+			context.Background(),
+			delivery.NewBackgroundLogger("assetStatusSnapshot"),
+			time.Hour,
+		)
+		assetStatusSnapshotDelivery := delivery.NewAssetStatusSnapshot(assetStatusSnapshotUsecase)
+		apiRouter.GET(
+			"/projects/:project/reviews/assets/pivot/diff",
+			assetStatusSnapshotDelivery.Diff,
+		)
+
+		// GraphQL API
+		graphQLUsecase := usecase.NewGraphQL(
+			reviewInfoUsecase,
+			phaseTemplateRepository,
+			authUsecase,
+			readTimeout,
+		)
+		graphQLDelivery, err := delivery.NewGraphQL(graphQLUsecase, roleDelivery)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		apiRouter.POST("/graphql", graphQLDelivery.Query)
+
+		// OpenAPI
+		openAPIDelivery := delivery.NewOpenAPI(router.Routes)
+		apiRouter.GET("/openapi.json", openAPIDelivery.Spec)
+		apiRouter.GET("/docs", openAPIDelivery.SwaggerUI)
+
+		// Pivot View API
+		pivotViewRepository, err := repository.NewPivotView(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		pivotViewDelivery := delivery.NewPivotView(
+			usecase.NewPivotView(
+				pivotViewRepository,
+				projectInfoRepository,
+				readTimeout,
+				writeTimeout,
+			),
+		)
+		apiRouter.GET("/projects/:project/pivotViews", pivotViewDelivery.List)
+		apiRouter.GET("/projects/:project/pivotViews/:id", pivotViewDelivery.Get)
+		apiRouter.POST("/projects/:project/pivotViews", pivotViewDelivery.Post)
+		apiRouter.PATCH("/projects/:project/pivotViews/:id", pivotViewDelivery.Patch)
+		apiRouter.DELETE("/projects/:project/pivotViews/:id", pivotViewDelivery.Delete)
 
 		/* ========================================================
 		   Additional APIs
 		======================================================= */
 
 		// Review Status Log API
-		reviewStatusLogRepository, err := repository.NewReviewStatusLog(gormDB)
+		if cfg.Server.IDMode == repository.IDModeULID {
+			go backfillUIDs(gormDB, reviewInfoRepository, reviewStatusLogRepository)
+		}
+		delegationRepository, err := repository.NewDelegation(gormDB)
 		if err != nil {
 			log.Fatalln(err)
 		}
 		reviewStatusLogUsecase := usecase.NewReviewStatusLog(
 			reviewStatusLogRepository,
+			reviewInfoRepository,
 			projectInfoRepository,
 			studioInfoRepository,
+			notificationOutboxRepository,
+			delegationRepository,
 			readTimeout,
 			writeTimeout,
 		)
@@ -1043,19 +2482,79 @@ func main() {
 		apiRouter.POST("/projects/:project/reviewStatusLogs", reviewStatusLogDelivery.Post)
 		apiRouter.POST("/projects/:project/reviewStatusLogs2", reviewStatusLogDelivery.Post2)
 
+		// Delegation API
+		delegationUsecase := usecase.NewDelegation(
+			delegationRepository,
+			projectInfoRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		go delegationUsecase.RunExpiry(
+			context.Background(),
+			delivery.NewBackgroundLogger("delegation"),
+			time.Minute,
+		)
+		delegationDelivery := delivery.NewDelegation(delegationUsecase)
+		apiRouter.GET("/delegations", delegationDelivery.List)
+		apiRouter.GET("/delegations/:id", delegationDelivery.Get)
+		apiRouter.POST("/delegations", delegationDelivery.Post)
+		apiRouter.POST("/delegations/:id/revoke", delegationDelivery.Revoke)
+
 		// Review Thumbnail API
 
+		mediaKeyRepository, err := repository.NewMediaKey(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		thumbnailPinRepository, err := repository.NewThumbnailPin(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
 		reviewThumbnailRepository := repository.NewReviewThumbnail(cs)
-		reviewThumbnailUsecase := usecase.NewReviewThumbnail(reviewThumbnailRepository)
+		reviewThumbnailUsecase := usecase.NewReviewThumbnail(
+			reviewThumbnailRepository,
+			mediaKeyRepository,
+			thumbnailPinRepository,
+			readTimeout,
+			writeTimeout,
+		)
 		reviewThumbnailDelivery := delivery.NewReviewThumbnail(reviewThumbnailUsecase)
 		apiRouter.GET(
 			"/projects/:project/assets/:asset/relations/:relation/reviewthumbnail",
 			reviewThumbnailDelivery.GetAssetThumbnail,
 		)
+		apiRouter.PUT(
+			"/projects/:project/assets/:asset/relations/:relation/reviewthumbnail/pin",
+			reviewThumbnailDelivery.PinAssetThumbnail,
+		)
+		apiRouter.DELETE(
+			"/projects/:project/assets/:asset/relations/:relation/reviewthumbnail/pin",
+			reviewThumbnailDelivery.UnpinAssetThumbnail,
+		)
 		apiRouter.GET(
 			"/projects/:project/shots/reviewthumbnail",
 			reviewThumbnailDelivery.GetShotThumbnail,
 		)
+		apiRouter.POST(
+			"/projects/:project/reviewthumbnails\\:batch",
+			reviewThumbnailDelivery.BatchGetAssetThumbnails,
+		)
+
+		// Media Key API
+		mediaKeyDelivery := delivery.NewMediaKey(usecase.NewMediaKey(
+			mediaKeyRepository,
+			reviewThumbnailRepository,
+			projectInfoRepository,
+			readTimeout,
+			writeTimeout,
+		))
+		apiRouter.GET("/projects/:project/mediaKeys", mediaKeyDelivery.List)
+		apiRouter.POST("/projects/:project/mediaKeys\\:rotate", mediaKeyDelivery.Rotate)
+		apiRouter.POST(
+			"/projects/:project/mediaKeys\\:encryptThumbnails",
+			mediaKeyDelivery.EncryptThumbnails,
+		)
+		apiRouter.GET("/projects/:project/mediaAccessLogs", mediaKeyDelivery.ListAccessLogs)
 
 		// Collection API
 		// - Comment API
@@ -1075,6 +2574,7 @@ func main() {
 			func(c *gin.Context) {
 				if c.Param("collection") == "comment" {
 					attachmentDelivery.Get(c)
+					complianceDelivery.RecordAttachmentDownload(c)
 				} else {
 					c.AbortWithStatus(http.StatusNotFound)
 				}
@@ -1110,7 +2610,7 @@ func main() {
 		projectRepository := project.NewRepository(myDB)
 		projectService := project.NewService(projectRepository)
 
-		dataset, err := getDataset(client, publishLogDatasetID)
+		dataset, err := getDataset(client, cfg.GCP.PublishLogDatasetID)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -1148,9 +2648,44 @@ func main() {
 			"/projects/:project/publishTransactionInfos/:logID",
 			publishTransactionInfoDelivery.Get,
 		)
+		roleDelivery.Handle(
+			apiRouter, http.MethodGet, "/projects/:project/publishTransactionInfos/:logID/reviews",
+			&entity.PermissionRequirement{Permission: entity.PermissionReviewRead},
+			reviewInfoDelivery.ListPublishTransactionReviewInfos,
+		)
 		apiRouter.PATCH("/projects/:project/publishTransactionInfos/:logID", methodNotAllowedHandler)
 		apiRouter.DELETE("/projects/:project/publishTransactionInfos/:logID", methodNotAllowedHandler)
 
+		// Publish Propagation API
+		publishPropagationRepository, err := repository.NewPublishPropagation(
+			gormDB,
+			projectStudioMapRepository,
+		)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		publishPropagationUsecase := usecase.NewPublishPropagation(
+			publishPropagationRepository,
+			projectInfoRepository,
+			notificationOutboxRepository,
+			readTimeout,
+			writeTimeout,
+		)
+		go publishPropagationUsecase.RunOverdueChecker(
+			context.Background(),
+			delivery.NewBackgroundLogger("publishPropagation"),
+			5*time.Minute,
+		)
+		publishPropagationDelivery := delivery.NewPublishPropagation(publishPropagationUsecase)
+		apiRouter.GET(
+			"/projects/:project/publishTransactionInfos/:logID/propagation",
+			publishPropagationDelivery.Get,
+		)
+		apiRouter.POST(
+			"/projects/:project/publishTransactionInfos/:logID/propagation",
+			publishPropagationDelivery.Report,
+		)
+
 		// PipelineParameter API
 
 		pipelineParameterRouter := apiRouter.Group("/pipelineParameter")
@@ -1220,7 +2755,7 @@ func main() {
 
 		// GroupCategory API
 
-		groupCategoryRepository, err := repository.NewGroupCategory(gormDB)
+		groupCategoryRepository, err := repository.NewGroupCategory(gormDB, queryCache)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -1246,6 +2781,9 @@ func main() {
 		apiRouter.DELETE(
 			"/projects/:project/groupCategories/:id", groupCategoryDelivery.Delete,
 		)
+		apiRouter.POST(
+			"/projects/:project/groupCategories/:id/assign", groupCategoryDelivery.Assign,
+		)
 
 		// OfficialRevision API
 		officialRevisionRepository, err := repository.NewOfficialRevision(gormDB)
@@ -1255,7 +2793,7 @@ func main() {
 		officialRevisionUsecase := usecase.NewOfficialRevision(
 			officialRevisionRepository,
 			projectInfoRepository,
-			mongoRepo,
+			docRepo,
 			readTimeout,
 			writeTimeout,
 		)
@@ -1569,6 +3107,58 @@ func main() {
 					}
 				},
 			)
+			pipelineSettingRouter.POST(
+				"/:group/composite/values\\:batchGet",
+				func(c *gin.Context) {
+					if c.Param("group") == entity.Preference.String() {
+						pipelineSettingDelivery.BatchGetCompositeValues(c)
+					} else {
+						c.AbortWithStatus(http.StatusNotFound)
+					}
+				},
+			)
+
+			// Changeset (draft / publish of values)
+
+			settingChangesetRepository, err := repository.NewSettingChangeset(
+				gormDB,
+				pipelineSettingRepository,
+			)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			settingChangesetUsecase := usecase.NewSettingChangeset(
+				settingChangesetRepository,
+				projectInfoRepository,
+				studioInfoRepository,
+				notificationOutboxRepository,
+				readTimeout,
+				writeTimeout,
+			)
+			go settingChangesetUsecase.RunScheduler(
+				context.Background(),
+				delivery.NewBackgroundLogger("settingChangeset"),
+				time.Minute,
+			)
+			settingChangesetDelivery := delivery.NewSettingChangeset(settingChangesetUsecase)
+			for _, section := range []string{
+				"/changesets/commons/:common",
+				"/changesets/studios/:studio",
+				"/changesets/projects/:project",
+			} {
+				pipelineSettingRouter.GET(section, settingChangesetDelivery.List)
+				pipelineSettingRouter.POST(section, settingChangesetDelivery.Post)
+				pipelineSettingRouter.GET(section+"/:id", settingChangesetDelivery.Get)
+				pipelineSettingRouter.DELETE(section+"/:id", settingChangesetDelivery.Delete)
+				pipelineSettingRouter.GET(section+"/:id/diff", settingChangesetDelivery.Diff)
+				pipelineSettingRouter.PUT(section+"/:id/changes", settingChangesetDelivery.PutChange)
+				pipelineSettingRouter.DELETE(
+					section+"/:id/changes/:changeID",
+					settingChangesetDelivery.DeleteChange,
+				)
+				pipelineSettingRouter.POST(section+"/:id/publish", settingChangesetDelivery.Publish)
+				pipelineSettingRouter.POST(section+"/:id/rollback", settingChangesetDelivery.Rollback)
+			}
 		}
 
 		// Legacy PipelineSettings API (Readonly)
@@ -1576,7 +3166,7 @@ func main() {
 		settingRouter0 := apiRouter.Group("/setting")
 		{
 			db0 := myDB
-			connection0 := os.Getenv("PPI_DEV_LEGACY_DB") // DB for local development environment
+			connection0 := cfg.MySQL.DevLegacyDSN // DB for local development environment
 			if connection0 != "" {
 				var err error
 				db0, err = openMySQLByDSN(connection0)
@@ -1684,9 +3274,17 @@ func main() {
 		//       environment variables are provided.
 
 		if dataDepRepo != nil {
-			registerDataDepHandlers(apiRouter, dataDepUsecase)
+			registerDataDepHandlers(
+				apiRouter.Group("", subsystemDelivery.Require(entity.SubsystemDataDependency)),
+				dataDepUsecase,
+			)
 		}
 
+		// probe the optional subsystems before serving, then in the background
+		subsystemLogger := delivery.NewBackgroundLogger("subsystems")
+		subsystemUsecase.Probe(ctx, subsystemLogger)
+		go subsystemUsecase.Run(context.Background(), subsystemLogger, cfg.Server.SubsystemProbeInterval)
+
 		// Generate CSV API
 		generateCsvTimeout := 60 * 15 * time.Second
 		generateCsvRepository := repository.NewGenerateCsv(gormDB)
@@ -1695,21 +3293,219 @@ func main() {
 			reviewInfoRepository,
 			groupCategoryRepository,
 			publishOperationInfoRepository,
-			mongoRepo,
+			docRepo,
+			customFieldRepository,
+			tagRepository,
+			phaseTemplateRepository,
 			generateCsvTimeout,
 		)
 		generateCsvDelivery := delivery.NewGenerateCsv(generateCsvUsecase)
-		apiRouter.GET("/projects/:project/assets/generateCsv", generateCsvDelivery.GenerateAssetsCsv)
+		apiRouter.GET(
+			"/projects/:project/assets/generateCsv",
+			projectQuotaDelivery.Enforce(entity.QuotaCSVExport),
+			generateCsvDelivery.GenerateAssetsCsv,
+		)
+		// Download of the whole asset pivot view, with the filters and sort of the pivot API.
+		apiRouter.GET(
+			"/projects/:project/reviews/assets/pivot/export",
+			projectQuotaDelivery.Enforce(entity.QuotaCSVExport),
+			func(c *gin.Context) {
+				params, ok := pivotQueryParams(c)
+				if !ok {
+					return
+				}
+				params.View = c.DefaultQuery("view", "list")
+				generateCsvDelivery.ExportAssetsPivot(c, params)
+			},
+		)
+
+		// Export Job API
+		//
+		// Note: The exports are generated in the background by the export workers of the
+		//       instances, for the documents taking longer than a request.
+
+		exportJobRepository, err := repository.NewExportJob(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		exportJobUsecase := usecase.NewExportJob(
+			exportJobRepository,
+			projectInfoRepository,
+			generateCsvDelivery,
+			2*generateCsvTimeout,
+			readTimeout,
+			writeTimeout,
+		)
+		for i := 0; i < exportJobUsecase.Workers(); i++ {
+			go exportJobUsecase.RunWorker(
+				context.Background(),
+				delivery.NewBackgroundLogger("exportJob"),
+				10*time.Second,
+			)
+		}
+		exportJobDelivery := delivery.NewExportJob(exportJobUsecase)
+		apiRouter.GET("/projects/:project/exports", exportJobDelivery.List)
+		apiRouter.POST(
+			"/projects/:project/exports",
+			projectQuotaDelivery.Enforce(entity.QuotaCSVExport),
+			func(c *gin.Context) {
+				query, ok := pivotQueryParams(c)
+				if !ok {
+					return
+				}
+				query.View = c.DefaultQuery("view", "list")
+				exportJobDelivery.Post(c, query)
+			},
+		)
+		apiRouter.GET("/projects/:project/exports/:id", exportJobDelivery.Get)
+		apiRouter.GET("/projects/:project/exports/:id/download", exportJobDelivery.Download)
+
+		// Maintenance API
+		//
+		// Note: The caches are dropped in this instance only, those of the other instances
+		//       expire after their TTL.
+
+		reindexJobRepository, err := repository.NewReindexJob(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		cacheInvalidators := map[string]entity.CacheInvalidator{
+			entity.CacheNamespaceReports: reportUsecase.InvalidateHeatmaps,
+		}
+		if queryCache != nil {
+			cacheInvalidators[entity.CacheNamespacePivot] = queryCache.Invalidate
+		}
+		maintenanceUsecase := usecase.NewMaintenance(
+			reindexJobRepository,
+			projectInfoRepository,
+			cacheInvalidators,
+			readTimeout,
+			writeTimeout,
+		)
+		go maintenanceUsecase.RunReindexWorker(
+			context.Background(),
+			delivery.NewBackgroundLogger("reindexJob"),
+			10*time.Second,
+		)
+		maintenanceDelivery := delivery.NewMaintenance(maintenanceUsecase)
+		apiRouter.DELETE("/admin/caches/:namespace", maintenanceDelivery.InvalidateCache)
+		apiRouter.GET("/admin/reindexJobs", maintenanceDelivery.ListReindexJobs)
+		apiRouter.POST("/admin/reindexJobs", maintenanceDelivery.PostReindexJob)
+		apiRouter.GET("/admin/reindexJobs/:id", maintenanceDelivery.GetReindexJob)
+
+		// Seed API
+		//
+		// Note: The Seed API generates load testing data and is only available when
+		//       PPI_SEED_ENABLED=true, which must never be set in production.
+
+		if cfg.SeedEnabled {
+			seedUsecase := usecase.NewSeed(repository.NewSeed(gormDB), dataDepRepo, seedTimeout)
+			seedDelivery := delivery.NewSeed(seedUsecase)
+			apiRouter.POST("/admin/seed", seedDelivery.Post)
+		}
+
+		// Fault Injection API
+		//
+		// Note: The Fault Injection API is only available when PPI_FAULT_INJECTION_ENABLED=true.
+
+		if faultInjector != nil {
+			faultDelivery := delivery.NewFault(usecase.NewFault(faultInjector))
+			apiRouter.GET("/admin/faults", faultDelivery.List)
+			apiRouter.PUT("/admin/faults/:dependency", faultDelivery.Put)
+			apiRouter.DELETE("/admin/faults/:dependency", faultDelivery.Delete)
+		}
+
+		// Support Bundle API
+		var supportNeo4jDriver neo4j.DriverWithContext
+		if neo4jDriver != nil {
+			supportNeo4jDriver = *neo4jDriver
+		}
+		supportBundleDelivery := delivery.NewSupportBundle(usecase.NewSupportBundle(
+			repository.NewSupportBundle(gormDB, mongoDB, supportNeo4jDriver),
+			supportLogRepository,
+		))
+		apiRouter.POST("/admin/supportBundle", supportBundleDelivery.Create)
+
+		// Archive API
+		//
+		// Note: The archives of whole projects are exported in the background by the archive
+		//       worker of each instance, into PPI_ARCHIVE_DIR, and restored elsewhere with the
+		//       archive-import subcommand.
+
+		archiveJobRepository, err := repository.NewArchiveJob(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		archiveUsecase := usecase.NewArchive(
+			archiveJobRepository,
+			repository.NewArchiver(gormDB, mongoDB, supportNeo4jDriver),
+			projectInfoRepository,
+			archiveTimeout,
+			readTimeout,
+			writeTimeout,
+		)
+		go archiveUsecase.RunWorker(
+			context.Background(),
+			delivery.NewBackgroundLogger("archive"),
+			30*time.Second,
+		)
+		archiveDelivery := delivery.NewArchive(archiveUsecase)
+		apiRouter.GET("/projects/:project/archives", archiveDelivery.List)
+		apiRouter.POST("/projects/:project/archives", archiveDelivery.Post)
+		apiRouter.GET("/projects/:project/archives/:id", archiveDelivery.Get)
+		apiRouter.GET("/projects/:project/archives/:id/download", archiveDelivery.Download)
+
+		// Project Bootstrap API
+		//
+		// Note: The projects are created with their scaffolding in the background by the
+		//       bootstrap worker of each instance.
+
+		projectBootstrapJobRepository, err := repository.NewProjectBootstrapJob(gormDB)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		projectBootstrapUsecase := usecase.NewProjectBootstrap(
+			projectBootstrapJobRepository,
+			projectInfoRepository,
+			pipelineSettingRepository,
+			directoryTemplateRepository,
+			groupCategoryRepository,
+			bootstrapTimeout,
+			readTimeout,
+			writeTimeout,
+		)
+		go projectBootstrapUsecase.RunWorker(
+			context.Background(),
+			delivery.NewBackgroundLogger("bootstrap"),
+			10*time.Second,
+		)
+		projectBootstrapDelivery := delivery.NewProjectBootstrap(projectBootstrapUsecase)
+		apiRouter.POST("/projects\\:bootstrap", projectBootstrapDelivery.Post)
+		apiRouter.GET("/projectBootstraps/:id", projectBootstrapDelivery.Get)
 	}
 
 	s := &http.Server{
 		Addr:           ":4000",
-		Handler:        router,
+		Handler:        delivery.StripBasePath(delivery.RewriteVersionedPath(router)),
 		ReadTimeout:    readTimeout,
 		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20,
 	}
 
+	// gRPC API
+	//
+	// Note: The gRPC API of the pipeline clients serves its calls with the handler of s, on
+	// PPI_GRPC_ADDRESS or :4001.
+	grpcListener, err := net.Listen("tcp", cfg.Server.GRPCAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		if err := delivery.NewGRPCServer(s.Handler).Serve(grpcListener); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
 	if err := s.ListenAndServe(); err != nil {
 		log.Fatal(err)
 	}
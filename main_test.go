@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/PolygonPictures/central30-web/front/config"
+	"github.com/PolygonPictures/central30-web/front/repository"
+)
+
+func TestGormDSNTimeZone(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		loc          *time.Location
+		wantLoc      string
+		wantTimeZone string
+	}{
+		{loc: time.UTC, wantLoc: "UTC", wantTimeZone: "'+00:00'"},
+		{loc: la, wantLoc: "America/Los_Angeles", wantTimeZone: "'America/Los_Angeles'"},
+	}
+	prev := repository.DBLocation
+	t.Cleanup(func() { repository.DBLocation = prev })
+	c := &config.MySQL{User: "user", Password: "pass", Host: "primary", Port: "3306", Name: "central"}
+	for _, tt := range tests {
+		repository.DBLocation = tt.loc
+		dsn := gormDSN(c, "replica")
+		prefix := "user:pass@(replica:3306)/central?"
+		if !strings.HasPrefix(dsn, prefix) {
+			t.Fatalf("gormDSN() = %q, want the prefix %q", dsn, prefix)
+		}
+		val, err := url.ParseQuery(strings.TrimPrefix(dsn, prefix))
+		if err != nil {
+			t.Fatalf("gormDSN() = %q: %v", dsn, err)
+		}
+		if got := val.Get("loc"); got != tt.wantLoc {
+			t.Errorf("loc of %v = %q, want %q", tt.loc, got, tt.wantLoc)
+		}
+		if got := val.Get("time_zone"); got != tt.wantTimeZone {
+			t.Errorf("time_zone of %v = %q, want %q", tt.loc, got, tt.wantTimeZone)
+		}
+		if got := val.Get("parseTime"); got != "True" {
+			t.Errorf("parseTime of %v = %q, want %q", tt.loc, got, "True")
+		}
+		// The driver loads loc with time.LoadLocation, which must give back the zone.
+		loaded, err := time.LoadLocation(val.Get("loc"))
+		if err != nil {
+			t.Fatalf("LoadLocation(%q): %v", val.Get("loc"), err)
+		}
+		if loaded.String() != tt.loc.String() {
+			t.Errorf("loc of %v loads as %v", tt.loc, loaded)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// Package pipelinepb is the generated code of the gRPC API of the pipeline clients.
+package pipelinepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pipeline.proto
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// Activity builds the activity feed of a project from the tables recording the changes:
+// reviews, review status logs, publish transactions, directories and pipeline settings.
+type Activity struct {
+	db *gorm.DB
+}
+
+func NewActivity(db *gorm.DB) *Activity {
+	return &Activity{
+		db: db,
+	}
+}
+
+func (r *Activity) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// activitySources returns, per activity type, the query selecting the activities of the
+// project with the columns of entity.Activity.
+func activitySources(
+	db *gorm.DB,
+	project string,
+) map[entity.ActivityType][]*gorm.DB {
+	takePath := "CONCAT_WS('/', ri.root, ri.group_1, ri.relation, ri.phase, ri.take)"
+	settings := func(table string, section string) *gorm.DB {
+		return db.Table(table).Select(
+			"? AS type, modified_at_utc AS occurred_at_utc, "+
+				"COALESCE(modified_by, '') AS actor, '' AS computer, "+
+				"`key` AS target, ? AS detail, id AS source_id",
+			entity.ActivitySettingChanged, section,
+		).Where(
+			"section_type = ?", "project",
+		).Where(
+			"section_name = ?", project,
+		)
+	}
+	return map[entity.ActivityType][]*gorm.DB{
+		entity.ActivityReviewSubmitted: {
+			db.Table("t_review_info AS ri").Select(
+				"? AS type, ri.submitted_at_utc AS occurred_at_utc, "+
+					"ri.submitted_user AS actor, ri.submitted_computer AS computer, "+
+					takePath+" AS target, ri.intent AS detail, ri.id AS source_id",
+				entity.ActivityReviewSubmitted,
+			).Where(
+				"ri.deleted = ?", 0,
+			).Where(
+				"ri.project = ?", project,
+			),
+		},
+		entity.ActivityReviewStatusChanged: {
+			db.Table("t_review_status_log AS l").Select(
+				"? AS type, l.created_at_utc AS occurred_at_utc, "+
+					"l.created_by AS actor, '' AS computer, "+
+					takePath+" AS target, CONCAT(l.status_type, ':', l.status) AS detail, "+
+					"l.id AS source_id",
+				entity.ActivityReviewStatusChanged,
+			).Joins(
+				"INNER JOIN t_review_info AS ri ON ri.id = l.review_info_id",
+			).Where(
+				"l.project = ?", project,
+			),
+		},
+		entity.ActivityPublished: {
+			db.Table("t_publish_transaction_info").Select(
+				"? AS type, created_at_utc AS occurred_at_utc, "+
+					"COALESCE(`user`, created_by) AS actor, COALESCE(computer, '') AS computer, "+
+					"revision_path AS target, CONCAT(operation, ':', event) AS detail, "+
+					"id AS source_id",
+				entity.ActivityPublished,
+			).Where(
+				"deleted = ?", 0,
+			).Where(
+				"project = ?", project,
+			),
+		},
+		entity.ActivityDirectoryCreated: {
+			db.Table("t_directory").Select(
+				"? AS type, created_at_utc AS occurred_at_utc, "+
+					"COALESCE(created_by, '') AS actor, '' AS computer, "+
+					"path AS target, '' AS detail, id AS source_id",
+				entity.ActivityDirectoryCreated,
+			).Where(
+				"project = ?", project,
+			).Where(
+				"created_at_utc IS NOT NULL",
+			),
+		},
+		entity.ActivityDirectoryDeleted: {
+			db.Table("t_directory").Select(
+				"? AS type, modified_at_utc AS occurred_at_utc, "+
+					"COALESCE(modified_by, '') AS actor, '' AS computer, "+
+					"path AS target, status AS detail, id AS source_id",
+				entity.ActivityDirectoryDeleted,
+			).Where(
+				"project = ?", project,
+			).Where(
+				"deleted <> ?", 0,
+			).Where(
+				"modified_at_utc IS NOT NULL",
+			),
+		},
+		entity.ActivitySettingChanged: {
+			settings("t_pipeline_setting_config", "config"),
+			settings("t_pipeline_setting_preference", "preference"),
+			settings("t_pipeline_setting_environment", "environment"),
+		},
+	}
+}
+
+// List returns the activities which occurred after the given time, newest first, and their
+// total number.
+func (r *Activity) List(
+	db *gorm.DB,
+	params *entity.ListActivitiesParams,
+) ([]*entity.Activity, int, error) {
+	types := params.Types
+	if len(types) == 0 {
+		types = entity.ActivityTypes
+	}
+	sources := activitySources(db, params.Project)
+	var union string
+	var queries []interface{}
+	for _, t := range types {
+		for _, q := range sources[t] {
+			if union != "" {
+				union += " UNION ALL "
+			}
+			union += "(?)"
+			queries = append(queries, q)
+		}
+	}
+
+	stmt := db.Table("(?) AS a", db.Raw(union, queries...)).Where(
+		"a.occurred_at_utc > ?", params.After,
+	)
+	if params.Actor != nil {
+		stmt = stmt.Where("a.actor = ?", *params.Actor)
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("Activity.List: %w", err)
+	}
+
+	var entities []*entity.Activity
+	if err := limitOffset(
+		stmt.Order("a.occurred_at_utc DESC, a.type ASC, a.source_id DESC"),
+		params.BaseListParams,
+	).Scan(&entities).Error; err != nil {
+		return nil, 0, fmt.Errorf("Activity.List: %w", err)
+	}
+	return entities, int(total), nil
+}
@@ -0,0 +1,553 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+// Kinds of personal data, telling how their values are faked.
+const (
+	anonymizeUser     = "user"
+	anonymizeComputer = "computer"
+	anonymizeComment  = "comment"
+	anonymizePath     = "path"
+	anonymizeMail     = "mail"
+)
+
+// anonymizeMailDomain is the domain of the fake mail addresses, reserved for examples.
+const anonymizeMailDomain = "example.com"
+
+// anonymizeMinNameLength is the length below which the names are not replaced in paths, as
+// they could match unrelated directories.
+const anonymizeMinNameLength = 3
+
+var anonymizeFirstNames = []string{
+	"aiko", "ben", "chloe", "daisuke", "emma", "felix", "grace", "haruto", "isla", "jun",
+	"kate", "leo", "mio", "noah", "olivia", "pablo", "quinn", "rin", "sam", "yuki",
+}
+
+var anonymizeLastNames = []string{
+	"abe", "baker", "clark", "doi", "evans", "fujii", "green", "hara", "ito", "jones",
+	"kato", "lee", "mori", "nakata", "ono", "parker", "reed", "sato", "turner", "ueda",
+}
+
+var anonymizeWords = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed",
+	"do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna",
+	"aliqua", "enim", "ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco",
+	"laboris", "nisi", "aliquip",
+}
+
+var anonymizeStringTypes = map[string]bool{
+	"char": true, "varchar": true, "tinytext": true, "text": true, "mediumtext": true,
+	"longtext": true,
+}
+
+var anonymizeIntegerTypes = map[string]bool{
+	"tinyint": true, "smallint": true, "mediumint": true, "int": true, "bigint": true,
+}
+
+// anonymizeKindOfKey tells the kind of personal data of a column or a JSON key by its name,
+// e.g. submitted_user, modified_by or take_path, and returns an empty string for other keys.
+func anonymizeKindOfKey(key string) string {
+	k := strings.ReplaceAll(strings.ToLower(key), "_", "")
+	switch {
+	case k == "mail" || strings.HasSuffix(k, "email") ||
+		strings.HasSuffix(k, "mailaddress") || strings.HasSuffix(k, "mailaddresses"):
+		return anonymizeMail
+	case strings.HasSuffix(k, "user") || strings.HasSuffix(k, "username") ||
+		strings.HasSuffix(k, "users") || strings.HasSuffix(k, "edby") ||
+		k == "actor" || k == "assignee":
+		return anonymizeUser
+	case strings.HasSuffix(k, "computer") || strings.HasSuffix(k, "hostname"):
+		return anonymizeComputer
+	case strings.Contains(k, "comment"):
+		return anonymizeComment
+	case strings.HasSuffix(k, "path") || strings.HasSuffix(k, "paths"):
+		return anonymizePath
+	}
+	return ""
+}
+
+// anonymizeChildKind returns the kind of the value of the key in an object of the given kind.
+// The kind of an object is only passed on to the keys of its text, so that the dates or
+// languages of comments are kept.
+func anonymizeChildKind(key string, kind string) string {
+	if k := anonymizeKindOfKey(key); k != "" {
+		return k
+	}
+	if kind == anonymizeComment {
+		switch strings.ToLower(key) {
+		case "text", "body", "message", "content":
+			return anonymizeComment
+		}
+	}
+	return ""
+}
+
+// AnonymizeFaker fakes personal data deterministically, a value of a kind always giving the
+// same fake for a salt, so that the references between tables and documents are kept. The
+// user and computer names it knows of are also replaced in the paths.
+type AnonymizeFaker struct {
+	salt      []byte
+	users     map[string]bool
+	computers map[string]bool
+}
+
+func (f *AnonymizeFaker) Users() int {
+	return len(f.users)
+}
+
+func (f *AnonymizeFaker) Computers() int {
+	return len(f.computers)
+}
+
+func (f *AnonymizeFaker) sum(kind string, value string) []byte {
+	mac := hmac.New(sha256.New, f.salt)
+	mac.Write([]byte(kind + ":" + value))
+	return mac.Sum(nil)
+}
+
+func (f *AnonymizeFaker) user(name string) string {
+	h := f.sum(anonymizeUser, name)
+	return fmt.Sprintf(
+		"%s.%s%s",
+		anonymizeFirstNames[int(h[0])%len(anonymizeFirstNames)],
+		anonymizeLastNames[int(h[1])%len(anonymizeLastNames)],
+		hex.EncodeToString(h[2:4]),
+	)
+}
+
+func (f *AnonymizeFaker) computer(name string) string {
+	return "ws-" + hex.EncodeToString(f.sum(anonymizeComputer, name)[:4])
+}
+
+// mail fakes the local part of the address as a user name, as they usually are.
+func (f *AnonymizeFaker) mail(address string) string {
+	local := address
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		local = address[:i]
+	}
+	return f.user(local) + "@" + anonymizeMailDomain
+}
+
+// comment replaces the text with as many placeholder words.
+func (f *AnonymizeFaker) comment(text string) string {
+	n := len(strings.Fields(text))
+	if n == 0 {
+		return text
+	}
+	h := f.sum(anonymizeComment, text)
+	words := make([]string, n)
+	for i := range words {
+		if i > 0 && i%len(h) == 0 {
+			h = f.sum(anonymizeComment, string(h))
+		}
+		words[i] = anonymizeWords[int(h[i%len(h)])%len(anonymizeWords)]
+	}
+	return strings.Join(words, " ")
+}
+
+// path replaces the segments of the path naming known users or computers, such as home
+// directories and the hosts of UNC paths, and keeps the rest of the path.
+func (f *AnonymizeFaker) path(p string) string {
+	var b strings.Builder
+	start := 0
+	flush := func(end int) {
+		segment := p[start:end]
+		switch {
+		case f.users[segment]:
+			b.WriteString(f.user(segment))
+		case f.computers[segment]:
+			b.WriteString(f.computer(segment))
+		default:
+			b.WriteString(segment)
+		}
+	}
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' || p[i] == '\\' {
+			flush(i)
+			b.WriteByte(p[i])
+			start = i + 1
+		}
+	}
+	flush(len(p))
+	return b.String()
+}
+
+func (f *AnonymizeFaker) fake(kind string, value string) string {
+	if value == "" {
+		return value
+	}
+	switch kind {
+	case anonymizeUser:
+		return f.user(value)
+	case anonymizeComputer:
+		return f.computer(value)
+	case anonymizeComment:
+		return f.comment(value)
+	case anonymizePath:
+		return f.path(value)
+	case anonymizeMail:
+		return f.mail(value)
+	}
+	return value
+}
+
+// fakeValue fakes the strings of a decoded JSON or BSON value in place, by the kind of their
+// key, and reports whether anything changed. Arrays pass the kind of their key on.
+func (f *AnonymizeFaker) fakeValue(v interface{}, kind string) (interface{}, bool) {
+	switch x := v.(type) {
+	case string:
+		s := f.fake(kind, x)
+		return s, s != x
+	case map[string]interface{}:
+		return x, f.fakeMap(x, kind)
+	case bson.M:
+		return x, f.fakeMap(x, kind)
+	case bson.D:
+		changed := false
+		for i, e := range x {
+			if nv, ok := f.fakeValue(e.Value, anonymizeChildKind(e.Key, kind)); ok {
+				x[i].Value = nv
+				changed = true
+			}
+		}
+		return x, changed
+	case []interface{}:
+		return x, f.fakeSlice(x, kind)
+	case bson.A:
+		return x, f.fakeSlice(x, kind)
+	}
+	return v, false
+}
+
+func (f *AnonymizeFaker) fakeMap(m map[string]interface{}, kind string) bool {
+	changed := false
+	for k, e := range m {
+		if nv, ok := f.fakeValue(e, anonymizeChildKind(k, kind)); ok {
+			m[k] = nv
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (f *AnonymizeFaker) fakeSlice(s []interface{}, kind string) bool {
+	changed := false
+	for i, e := range s {
+		if nv, ok := f.fakeValue(e, kind); ok {
+			s[i] = nv
+			changed = true
+		}
+	}
+	return changed
+}
+
+// fakeColumn fakes the value of a column, the JSON ones by the keys of their values.
+func (f *AnonymizeFaker) fakeColumn(
+	c *entity.AnonymizeColumn,
+	value string,
+) (string, bool, error) {
+	if !c.JSON {
+		s := f.fake(c.Kind, value)
+		return s, s != value, nil
+	}
+	d := json.NewDecoder(strings.NewReader(value))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return "", false, err
+	}
+	v, changed := f.fakeValue(v, c.Kind)
+	if !changed {
+		return value, false, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
+
+// Anonymizer rewrites the personal data of the MySQL and MongoDB databases in place. The
+// columns and keys of personal data are found by their names, so that the tables and
+// documents added later are covered without changes.
+type Anonymizer struct {
+	db      *gorm.DB
+	mongoDB *mongo.Database
+}
+
+// NewAnonymizer returns the anonymizer of the databases. mongoDB may be nil, the documents
+// then being left untouched.
+func NewAnonymizer(db *gorm.DB, mongoDB *mongo.Database) *Anonymizer {
+	return &Anonymizer{
+		db:      db,
+		mongoDB: mongoDB,
+	}
+}
+
+func (r *Anonymizer) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Anonymizer) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+type anonymizeSchemaColumn struct {
+	TableName  string
+	ColumnName string
+	DataType   string
+	Extra      string
+}
+
+// Tables returns the tables of the database having columns of personal data, or JSON columns
+// which may hold some. The generated columns are left to MySQL, and the tables without an
+// integer ID cannot be walked through and are skipped.
+func (r *Anonymizer) Tables(db *gorm.DB) ([]*entity.AnonymizeTable, error) {
+	var columns []*anonymizeSchemaColumn
+	if err := db.Raw(
+		"SELECT c.`TABLE_NAME` AS `table_name`, c.`COLUMN_NAME` AS `column_name`, " +
+			"c.`DATA_TYPE` AS `data_type`, c.`EXTRA` AS `extra` " +
+			"FROM `information_schema`.`COLUMNS` AS c " +
+			"JOIN `information_schema`.`TABLES` AS t " +
+			"ON t.`TABLE_SCHEMA` = c.`TABLE_SCHEMA` AND t.`TABLE_NAME` = c.`TABLE_NAME` " +
+			"WHERE c.`TABLE_SCHEMA` = DATABASE() AND t.`TABLE_TYPE` = 'BASE TABLE' " +
+			"ORDER BY c.`TABLE_NAME`, c.`ORDINAL_POSITION`",
+	).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+	byName := map[string]*entity.AnonymizeTable{}
+	hasID := map[string]bool{}
+	var names []string
+	for _, c := range columns {
+		if strings.Contains(strings.ToUpper(c.Extra), "GENERATED") {
+			continue
+		}
+		dataType := strings.ToLower(c.DataType)
+		if c.ColumnName == "id" {
+			hasID[c.TableName] = anonymizeIntegerTypes[dataType]
+			continue
+		}
+		kind := anonymizeKindOfKey(c.ColumnName)
+		isJSON := dataType == "json"
+		if !isJSON && (kind == "" || !anonymizeStringTypes[dataType]) {
+			continue
+		}
+		t, ok := byName[c.TableName]
+		if !ok {
+			t = &entity.AnonymizeTable{Name: c.TableName}
+			byName[c.TableName] = t
+			names = append(names, c.TableName)
+		}
+		t.Columns = append(t.Columns, &entity.AnonymizeColumn{
+			Name: c.ColumnName,
+			Kind: kind,
+			JSON: isJSON,
+		})
+	}
+	tables := make([]*entity.AnonymizeTable, 0, len(names))
+	for _, name := range names {
+		if hasID[name] {
+			tables = append(tables, byName[name])
+		}
+	}
+	return tables, nil
+}
+
+// Faker returns the faker of the salt, knowing of the user and computer names of the columns
+// of the tables.
+func (r *Anonymizer) Faker(
+	db *gorm.DB,
+	tables []*entity.AnonymizeTable,
+	salt string,
+) (*AnonymizeFaker, error) {
+	f := &AnonymizeFaker{
+		salt:      []byte(salt),
+		users:     map[string]bool{},
+		computers: map[string]bool{},
+	}
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			var names map[string]bool
+			switch {
+			case c.JSON:
+				continue
+			case c.Kind == anonymizeUser:
+				names = f.users
+			case c.Kind == anonymizeComputer:
+				names = f.computers
+			default:
+				continue
+			}
+			var values []string
+			if err := db.Table(t.Name).Distinct("`"+c.Name+"`").Where(
+				"`"+c.Name+"` IS NOT NULL",
+			).Pluck(c.Name, &values).Error; err != nil {
+				return nil, err
+			}
+			for _, v := range values {
+				if len(v) >= anonymizeMinNameLength {
+					names[v] = true
+				}
+			}
+		}
+	}
+	return f, nil
+}
+
+type anonymizeRowChange struct {
+	id      int64
+	updates map[string]interface{}
+}
+
+// AnonymizeRows rewrites up to limit rows of the table with IDs above afterID. It returns the
+// last ID read, the number of rows read and the number of rows changed, which are not written
+// for a dry run.
+func (r *Anonymizer) AnonymizeRows(
+	tx *gorm.DB,
+	f *AnonymizeFaker,
+	table *entity.AnonymizeTable,
+	afterID int64,
+	limit int,
+	dryRun bool,
+) (int64, int, int64, error) {
+	selects := make([]string, 0, len(table.Columns)+1)
+	selects = append(selects, "`id`")
+	for _, c := range table.Columns {
+		selects = append(selects, "`"+c.Name+"`")
+	}
+	rows, err := tx.Table(table.Name).Select(strings.Join(selects, ", ")).Where(
+		"`id` > ?", afterID,
+	).Order("`id` asc").Limit(limit).Rows()
+	if err != nil {
+		return afterID, 0, 0, err
+	}
+	defer rows.Close()
+
+	lastID := afterID
+	read := 0
+	var changes []*anonymizeRowChange
+	for rows.Next() {
+		var id int64
+		values := make([]sql.NullString, len(table.Columns))
+		dest := make([]interface{}, len(values)+1)
+		dest[0] = &id
+		for i := range values {
+			dest[i+1] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return lastID, read, 0, err
+		}
+		lastID = id
+		read++
+		updates := map[string]interface{}{}
+		for i, c := range table.Columns {
+			if !values[i].Valid {
+				continue
+			}
+			s, changed, err := f.fakeColumn(c, values[i].String)
+			if err != nil {
+				return lastID, read, 0, fmt.Errorf(
+					"%s.%s of ID %d: %w", table.Name, c.Name, id, err,
+				)
+			}
+			if changed {
+				updates[c.Name] = s
+			}
+		}
+		if len(updates) > 0 {
+			changes = append(changes, &anonymizeRowChange{id: id, updates: updates})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return lastID, read, 0, err
+	}
+	// the connection is busy until the rows are closed
+	rows.Close()
+
+	if dryRun {
+		return lastID, read, int64(len(changes)), nil
+	}
+	for _, c := range changes {
+		if err := tx.Table(table.Name).Where(
+			"`id` = ?", c.id,
+		).Updates(c.updates).Error; err != nil {
+			return lastID, read, 0, err
+		}
+	}
+	return lastID, read, int64(len(changes)), nil
+}
+
+// Collections returns the collections of MongoDB but the system ones, and none when MongoDB
+// is not given.
+func (r *Anonymizer) Collections(ctx context.Context) ([]string, error) {
+	if r.mongoDB == nil {
+		return nil, nil
+	}
+	names, err := r.mongoDB.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	collections := make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.HasPrefix(name, "system.") {
+			collections = append(collections, name)
+		}
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
+// AnonymizeDocuments rewrites the documents of the collection, and returns the number of
+// documents changed, which are not written for a dry run.
+func (r *Anonymizer) AnonymizeDocuments(
+	ctx context.Context,
+	f *AnonymizeFaker,
+	collection string,
+	dryRun bool,
+) (int64, error) {
+	col := r.mongoDB.Collection(collection)
+	cursor, err := col.Find(ctx, bson.D{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var changed int64
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return changed, err
+		}
+		if _, ok := f.fakeValue(doc, ""); !ok {
+			continue
+		}
+		changed++
+		if dryRun {
+			continue
+		}
+		if _, err := col.ReplaceOne(ctx, bson.D{{"_id", doc["_id"]}}, doc); err != nil {
+			return changed, err
+		}
+	}
+	return changed, cursor.Err()
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// apiKeyTouchInterval limits how often the last use of an API key is recorded, so that the
+// requests of busy scripts do not write it every time.
+const apiKeyTouchInterval = time.Minute
+
+// APIKey stores the API keys of the service accounts. Only the SHA-256 hashes of the keys are
+// stored, the keys being found by their prefix.
+type APIKey struct {
+	db      *gorm.DB
+	stuRepo *StudioInfo
+}
+
+func NewAPIKey(db *gorm.DB, stuRepo *StudioInfo) (*APIKey, error) {
+	if err := db.AutoMigrate(&model.APIKey{}); err != nil {
+		return nil, err
+	}
+	return &APIKey{
+		db:      db,
+		stuRepo: stuRepo,
+	}, nil
+}
+
+func (r *APIKey) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *APIKey) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// newAPIKey generates a key, made of APIKeyPrefix, its prefix and its secret, and its hash.
+func newAPIKey(prefix string) (string, string, string, error) {
+	if prefix == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", "", "", err
+		}
+		prefix = hex.EncodeToString(b)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	key := entity.APIKeyPrefix + prefix + "_" + hex.EncodeToString(secret)
+	return key, prefix, hashAPIKey(key), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *APIKey) List(db *gorm.DB, params *entity.ListAPIKeysParams) ([]*entity.APIKey, error) {
+	stmt := db.Model(&model.APIKey{})
+	if params.Studio != nil {
+		stmt = stmt.Where("`studio` = ?", *params.Studio)
+	}
+	if !params.Revoked {
+		stmt = stmt.Where("`revoked_at_utc` IS NULL")
+	}
+	var models []*model.APIKey
+	if err := stmt.Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.APIKey, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *APIKey) get(db *gorm.DB, id int32) (*model.APIKey, error) {
+	var m model.APIKey
+	if err := db.Where("`id` = ?", id).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: API key with ID %d", entity.ErrRecordNotFound, id)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *APIKey) Get(db *gorm.DB, params *entity.GetAPIKeyParams) (*entity.APIKey, error) {
+	m, err := r.get(db, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Create creates an API key of an existing studio and returns it with its key.
+func (r *APIKey) Create(tx *gorm.DB, params *entity.CreateAPIKeyParams) (*entity.APIKey, error) {
+	if _, err := r.stuRepo.Get(tx, &entity.GetStudioInfoParams{
+		KeyName: params.Studio,
+	}); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: studio %q does not exist", entity.ErrBadRequest, params.Studio)
+		}
+		return nil, err
+	}
+	for _, route := range params.Routes {
+		if i := strings.Index(route.Path, "*"); i != -1 && i != len(route.Path)-1 {
+			return nil, fmt.Errorf(
+				"%w: route %q may only end with *", entity.ErrBadRequest, route.Path,
+			)
+		}
+	}
+	key, prefix, hash, err := newAPIKey("")
+	if err != nil {
+		return nil, err
+	}
+	m := model.NewAPIKey(params, prefix, hash)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	e.Key = key
+	return e, nil
+}
+
+// Rotate replaces the key of an API key, keeping its prefix, and returns it with its new key.
+func (r *APIKey) Rotate(tx *gorm.DB, params *entity.RotateAPIKeyParams) (*entity.APIKey, error) {
+	m, err := r.get(tx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if m.RevokedAtUTC != nil {
+		return nil, fmt.Errorf("%w: API key with ID %d was revoked", entity.ErrBadRequest, m.ID)
+	}
+	key, _, hash, err := newAPIKey(m.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	m.Hash = hash
+	m.RotatedAtUTC = &now
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = params.ModifiedBy
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	e.Key = key
+	return e, nil
+}
+
+// Revoke rejects the key of an API key from then on. Revoking a revoked key does nothing.
+func (r *APIKey) Revoke(tx *gorm.DB, params *entity.RevokeAPIKeyParams) (*entity.APIKey, error) {
+	m, err := r.get(tx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if m.RevokedAtUTC != nil {
+		return m.Entity(), nil
+	}
+	now := time.Now().UTC()
+	m.RevokedAtUTC = &now
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = params.ModifiedBy
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Authenticate returns the API key of a request, with ErrUnauthorized when the key is unknown,
+// revoked or expired, and with ErrForbidden when its routes or projects do not cover the
+// request.
+func (r *APIKey) Authenticate(
+	db *gorm.DB,
+	params *entity.AuthenticateAPIKeyParams,
+) (*entity.APIKey, error) {
+	invalid := fmt.Errorf("%w: invalid API key", entity.ErrUnauthorized)
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(params.Key, entity.APIKeyPrefix), "_")
+	if !ok || !strings.HasPrefix(params.Key, entity.APIKeyPrefix) {
+		return nil, invalid
+	}
+	var m model.APIKey
+	if err := db.Where("`prefix` = ?", prefix).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(params.Key)), []byte(m.Hash)) != 1 {
+		return nil, invalid
+	}
+	now := time.Now().UTC()
+	if m.RevokedAtUTC != nil {
+		return nil, fmt.Errorf("%w: API key was revoked", entity.ErrUnauthorized)
+	}
+	if m.ExpiresAtUTC != nil && !now.Before(*m.ExpiresAtUTC) {
+		return nil, fmt.Errorf("%w: API key has expired", entity.ErrUnauthorized)
+	}
+
+	permitted := false
+	for _, route := range m.Routes {
+		if route.Matches(params.Method, params.Path) {
+			permitted = true
+			break
+		}
+	}
+	if !permitted {
+		return nil, fmt.Errorf(
+			"%w: API key %q may not call %s %s",
+			entity.ErrForbidden, m.Name, params.Method, params.Path,
+		)
+	}
+	if params.Project != "" && len(m.Projects) != 0 {
+		permitted = false
+		for _, p := range m.Projects {
+			if p == params.Project {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			return nil, fmt.Errorf(
+				"%w: API key %q may not access project %s",
+				entity.ErrForbidden, m.Name, params.Project,
+			)
+		}
+	}
+
+	if m.LastUsedAtUTC == nil || now.Sub(*m.LastUsedAtUTC) >= apiKeyTouchInterval {
+		if err := db.Model(&model.APIKey{}).Where(
+			"`id` = ?", m.ID,
+		).Update("last_used_at_utc", now).Error; err != nil {
+			return nil, err
+		}
+		m.LastUsedAtUTC = &now
+	}
+	return m.Entity(), nil
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// archiveJobListLimit limits the number of archive jobs listed per project.
+const archiveJobListLimit = 100
+
+// ArchiveJob stores the archive jobs and their bundles. The bundles are files of the directory
+// PPI_ARCHIVE_DIR, a temporary directory by default, which must be shared by the instances of
+// the API for them to be downloaded from any instance, and large enough for whole projects.
+type ArchiveJob struct {
+	db  *gorm.DB
+	dir string
+}
+
+func NewArchiveJob(db *gorm.DB) (*ArchiveJob, error) {
+	if err := db.AutoMigrate(&model.ArchiveJob{}); err != nil {
+		return nil, err
+	}
+	dir := os.Getenv("PPI_ARCHIVE_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "ppi-archives")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &ArchiveJob{
+		db:  db,
+		dir: dir,
+	}, nil
+}
+
+func (r *ArchiveJob) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ArchiveJob) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// List returns the latest archive jobs of a project.
+func (r *ArchiveJob) List(
+	db *gorm.DB,
+	params *entity.ListArchiveJobsParams,
+) ([]*entity.ArchiveJob, error) {
+	var models []*model.ArchiveJob
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Order("`id` desc").Limit(archiveJobListLimit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ArchiveJob, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *ArchiveJob) Get(
+	db *gorm.DB,
+	params *entity.GetArchiveJobParams,
+) (*entity.ArchiveJob, error) {
+	var m model.ArchiveJob
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: archive job with ID %d", entity.ErrRecordNotFound, params.ID)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Create queues an archive job, unless one of the project is already queued or running.
+func (r *ArchiveJob) Create(
+	tx *gorm.DB,
+	params *entity.CreateArchiveJobParams,
+) (*entity.ArchiveJob, error) {
+	var count int64
+	if err := tx.Model(&model.ArchiveJob{}).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`status` IN ?", []string{string(entity.ArchiveQueued), string(entity.ArchiveRunning)},
+	).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count != 0 {
+		return nil, fmt.Errorf(
+			"%w: an archive of project %s is already in progress", entity.ErrConflict, params.Project,
+		)
+	}
+	m := model.NewArchiveJob(params)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Claim starts the oldest queued archive job and returns it, or nil when no job is queued.
+// A job claimed by another worker meanwhile is skipped.
+func (r *ArchiveJob) Claim(db *gorm.DB) (*entity.ArchiveJob, error) {
+	for {
+		var m model.ArchiveJob
+		if err := db.Where(
+			"`status` = ?", string(entity.ArchiveQueued),
+		).Order("`id` asc").Take(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		now := time.Now().UTC()
+		result := db.Model(&model.ArchiveJob{}).Where(
+			"`id` = ?", m.ID,
+		).Where(
+			"`status` = ?", string(entity.ArchiveQueued),
+		).Updates(map[string]interface{}{
+			"status":          string(entity.ArchiveRunning),
+			"started_at_utc":  now,
+			"modified_at_utc": now,
+		})
+		if err := result.Error; err != nil {
+			return nil, err
+		}
+		if result.RowsAffected != 0 {
+			m.Status = string(entity.ArchiveRunning)
+			m.StartedAtUTC = &now
+			m.ModifiedAtUTC = now
+			return m.Entity(), nil
+		}
+	}
+}
+
+// Progress records the section a running archive job is exporting, done of total.
+func (r *ArchiveJob) Progress(db *gorm.DB, id int32, step string, total, done int64) error {
+	return db.Model(&model.ArchiveJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ArchiveRunning),
+	).Updates(map[string]interface{}{
+		"step":            step,
+		"total":           total,
+		"done":            done,
+		"modified_at_utc": time.Now().UTC(),
+	}).Error
+}
+
+// Complete records the bundle of a running archive job, kept until expiresAt.
+func (r *ArchiveJob) Complete(db *gorm.DB, id int32, size int64, expiresAt time.Time) error {
+	now := time.Now().UTC()
+	return db.Model(&model.ArchiveJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ArchiveRunning),
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ArchiveCompleted),
+		"step":             "",
+		"done":             gorm.Expr("`total`"),
+		"size":             size,
+		"completed_at_utc": now,
+		"expires_at_utc":   expiresAt,
+		"modified_at_utc":  now,
+	}).Error
+}
+
+// Fail fails a running archive job.
+func (r *ArchiveJob) Fail(db *gorm.DB, id int32, errMessage string) error {
+	now := time.Now().UTC()
+	return db.Model(&model.ArchiveJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ArchiveRunning),
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ArchiveFailed),
+		"error":            errMessage,
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	}).Error
+}
+
+// FailStale fails the archive jobs which made no progress since the given time, whose worker
+// was stopped before finishing them.
+func (r *ArchiveJob) FailStale(db *gorm.DB, before time.Time) (int64, error) {
+	now := time.Now().UTC()
+	result := db.Model(&model.ArchiveJob{}).Where(
+		"`status` = ?", string(entity.ArchiveRunning),
+	).Where(
+		"`modified_at_utc` < ?", before,
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ArchiveFailed),
+		"error":            "the archive was interrupted",
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// Expire removes the bundles of the completed archive jobs which expired before now.
+func (r *ArchiveJob) Expire(db *gorm.DB, now time.Time) (int, error) {
+	var models []*model.ArchiveJob
+	if err := db.Where(
+		"`status` = ?", string(entity.ArchiveCompleted),
+	).Where(
+		"`expires_at_utc` < ?", now,
+	).Find(&models).Error; err != nil {
+		return 0, err
+	}
+	for _, m := range models {
+		if err := os.Remove(r.path(m.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, err
+		}
+		if err := db.Model(&model.ArchiveJob{}).Where(
+			"`id` = ?", m.ID,
+		).Where(
+			"`status` = ?", string(entity.ArchiveCompleted),
+		).Updates(map[string]interface{}{
+			"status":          string(entity.ArchiveExpired),
+			"modified_at_utc": time.Now().UTC(),
+		}).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(models), nil
+}
+
+func (r *ArchiveJob) path(id int32) string {
+	return filepath.Join(r.dir, fmt.Sprintf("%d.tar.gz", id))
+}
+
+// WorkDir creates the directory the sections of an archive job are dumped into before they are
+// bundled, which the caller must remove.
+func (r *ArchiveJob) WorkDir(e *entity.ArchiveJob) (string, error) {
+	return os.MkdirTemp(r.dir, fmt.Sprintf("%d-*.work", e.ID))
+}
+
+// Store writes the bundle of an archive job with write and returns its size. The bundle only
+// replaces the previous one once fully written.
+func (r *ArchiveJob) Store(e *entity.ArchiveJob, write func(w io.Writer) error) (int64, error) {
+	f, err := os.CreateTemp(r.dir, fmt.Sprintf("%d-*.tmp", e.ID))
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	if err := write(f); err != nil {
+		f.Close()
+		return 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(f.Name(), r.path(e.ID)); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Open opens the bundle of a completed archive job.
+func (r *ArchiveJob) Open(e *entity.ArchiveJob) (*os.File, error) {
+	f, err := os.Open(r.path(e.ID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(
+			"%w: bundle of archive job with ID %d", entity.ErrRecordNotFound, e.ID,
+		)
+	}
+	return f, err
+}
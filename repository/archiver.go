@@ -0,0 +1,1027 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+const (
+	// archiveRowsPerInsert is the number of rows per INSERT statement of the SQL dumps.
+	archiveRowsPerInsert = 100
+	// archiveBatchSize is the number of documents, nodes or relationships written at once by
+	// the restore.
+	archiveBatchSize = 500
+	// archiveProjectTable is the only archived table keyed by key_name instead of project.
+	archiveProjectTable = "t_project_info"
+	// archiveImportLabel marks the nodes being restored until their relationships are, so
+	// that they are found by the element IDs of the archived instance.
+	archiveImportLabel = "ArchiveImport"
+)
+
+// archiveSkippedTables are the tables of the jobs, whose files do not follow the archives.
+var archiveSkippedTables = map[string]bool{
+	"t_archive_job": true,
+	"t_export_job":  true,
+}
+
+// archiveBinaryTypes are the types of the columns dumped as hexadecimal literals.
+var archiveBinaryTypes = map[string]bool{
+	"BINARY": true, "VARBINARY": true, "TINYBLOB": true, "BLOB": true, "MEDIUMBLOB": true,
+	"LONGBLOB": true, "BIT": true,
+}
+
+// archiveGraphRelationships are the relationships of the hierarchy of a project in Neo4j.
+const archiveGraphRelationships = "HAS_ROOT|HAS_GROUP|HAS_RELATION|HAS_PHASE_DIRECTORY|" +
+	"HAS_COMPONENT_DIRECTORY|HAS_REVISION|HAS_CONTENT|HAS_CONTENT_FILE"
+
+// Archiver dumps the data of a project from the MySQL, MongoDB and Neo4j databases into the
+// sections of an archive, and restores them. The tables and collections are found by their
+// project keys, so that those added later are archived without changes.
+type Archiver struct {
+	db      *gorm.DB
+	mongoDB *mongo.Database
+	neo4j   neo4j.DriverWithContext
+}
+
+// NewArchiver returns the archiver of the databases. mongoDB and neo4jDriver may be nil, the
+// documents or the subgraph then being left out.
+func NewArchiver(
+	db *gorm.DB,
+	mongoDB *mongo.Database,
+	neo4jDriver neo4j.DriverWithContext,
+) *Archiver {
+	return &Archiver{
+		db:      db,
+		mongoDB: mongoDB,
+		neo4j:   neo4jDriver,
+	}
+}
+
+func (r *Archiver) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Archiver) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// HasGraph tells whether the subgraphs are archived.
+func (r *Archiver) HasGraph() bool {
+	return r.neo4j != nil
+}
+
+// archiveKeyColumn returns the column of the project of an archived table.
+func archiveKeyColumn(table string) string {
+	if table == archiveProjectTable {
+		return "key_name"
+	}
+	return "project"
+}
+
+type archiveSchemaColumn struct {
+	TableName  string
+	ColumnName string
+	Extra      string
+}
+
+// schemaColumns returns the columns of the base tables of the database but the generated ones,
+// by table.
+func (r *Archiver) schemaColumns(db *gorm.DB) (map[string][]string, []string, error) {
+	var columns []*archiveSchemaColumn
+	if err := db.Raw(
+		"SELECT c.`TABLE_NAME` AS `table_name`, c.`COLUMN_NAME` AS `column_name`, " +
+			"c.`EXTRA` AS `extra` " +
+			"FROM `information_schema`.`COLUMNS` AS c " +
+			"JOIN `information_schema`.`TABLES` AS t " +
+			"ON t.`TABLE_SCHEMA` = c.`TABLE_SCHEMA` AND t.`TABLE_NAME` = c.`TABLE_NAME` " +
+			"WHERE c.`TABLE_SCHEMA` = DATABASE() AND t.`TABLE_TYPE` = 'BASE TABLE' " +
+			"ORDER BY c.`TABLE_NAME`, c.`ORDINAL_POSITION`",
+	).Scan(&columns).Error; err != nil {
+		return nil, nil, err
+	}
+	byTable := map[string][]string{}
+	var names []string
+	for _, c := range columns {
+		if strings.Contains(strings.ToUpper(c.Extra), "GENERATED") {
+			continue
+		}
+		if _, ok := byTable[c.TableName]; !ok {
+			names = append(names, c.TableName)
+		}
+		byTable[c.TableName] = append(byTable[c.TableName], c.ColumnName)
+	}
+	return byTable, names, nil
+}
+
+// Tables returns the tables having rows of projects, with the columns to dump.
+func (r *Archiver) Tables(db *gorm.DB) ([]*entity.ArchiveTable, error) {
+	byTable, names, err := r.schemaColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*entity.ArchiveTable
+	for _, name := range names {
+		if archiveSkippedTables[name] {
+			continue
+		}
+		columns := byTable[name]
+		key := archiveKeyColumn(name)
+		hasKey := false
+		for _, c := range columns {
+			if c == key {
+				hasKey = true
+				break
+			}
+		}
+		if hasKey {
+			tables = append(tables, &entity.ArchiveTable{
+				Name:    name,
+				Columns: columns,
+			})
+		}
+	}
+	return tables, nil
+}
+
+// CheckTables checks that the tables of an archive exist in the database with their columns,
+// and have no row of the project.
+func (r *Archiver) CheckTables(db *gorm.DB, project string, tables []*entity.ArchiveTable) error {
+	byTable, _, err := r.schemaColumns(db)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		columns, ok := byTable[t.Name]
+		if !ok {
+			return fmt.Errorf("%w: table %s does not exist", entity.ErrBadRequest, t.Name)
+		}
+		has := make(map[string]bool, len(columns))
+		for _, c := range columns {
+			has[c] = true
+		}
+		for _, c := range t.Columns {
+			if !has[c] {
+				return fmt.Errorf(
+					"%w: column %s.%s does not exist", entity.ErrBadRequest, t.Name, c,
+				)
+			}
+		}
+		var count int64
+		if err := db.Table(t.Name).Where(
+			"`"+archiveKeyColumn(t.Name)+"` = ?", project,
+		).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 0 {
+			return fmt.Errorf(
+				"%w: table %s already has %d rows of project %s",
+				entity.ErrConflict, t.Name, count, project,
+			)
+		}
+	}
+	return nil
+}
+
+// archiveQuote quotes a string as a MySQL literal.
+func archiveQuote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\x1a':
+			b.WriteString(`\Z`)
+		case '\\', '\'', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// archiveLiteral returns the MySQL literal of a value scanned from a column.
+func archiveLiteral(v interface{}, binary bool) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if binary {
+			if len(v) == 0 {
+				return "''"
+			}
+			return "X'" + hex.EncodeToString(v) + "'"
+		}
+		return archiveQuote(string(v))
+	case string:
+		return archiveQuote(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return archiveQuote(dbTime(v))
+	}
+	return archiveQuote(fmt.Sprint(v))
+}
+
+// DumpTable writes the rows of the project in the table as INSERT statements, one per line,
+// and returns the number of rows.
+func (r *Archiver) DumpTable(
+	db *gorm.DB,
+	table *entity.ArchiveTable,
+	project string,
+	w io.Writer,
+) (int64, error) {
+	quoted := make([]string, len(table.Columns))
+	for i, c := range table.Columns {
+		quoted[i] = "`" + c + "`"
+	}
+	columns := strings.Join(quoted, ", ")
+	rows, err := db.Table(table.Name).Select(columns).Where(
+		"`"+archiveKeyColumn(table.Name)+"` = ?", project,
+	).Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	binary := make([]bool, len(types))
+	for i, t := range types {
+		binary[i] = archiveBinaryTypes[strings.ToUpper(t.DatabaseTypeName())]
+	}
+	prefix := "INSERT INTO `" + table.Name + "` (" + columns + ") VALUES "
+
+	bw := bufio.NewWriter(w)
+	var count int64
+	inStatement := 0
+	values := make([]interface{}, len(table.Columns))
+	dest := make([]interface{}, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	literals := make([]string, len(values))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, err
+		}
+		for i, v := range values {
+			literals[i] = archiveLiteral(v, binary[i])
+		}
+		if inStatement == 0 {
+			bw.WriteString(prefix)
+		} else {
+			bw.WriteString(", ")
+		}
+		bw.WriteString("(" + strings.Join(literals, ", ") + ")")
+		count++
+		inStatement++
+		if inStatement == archiveRowsPerInsert {
+			bw.WriteString(";\n")
+			inStatement = 0
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	if inStatement != 0 {
+		bw.WriteString(";\n")
+	}
+	return count, bw.Flush()
+}
+
+// RestoreTable runs the INSERT statements of a dump, one per line, and returns the number of
+// statements. The foreign keys are not checked, as the tables are restored one by one.
+func (r *Archiver) RestoreTable(tx *gorm.DB, rd io.Reader) (int64, error) {
+	if err := tx.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+		return 0, err
+	}
+	count, err := r.restoreStatements(tx, rd)
+	// the setting of the session outlives the transaction
+	if resetErr := tx.Exec("SET FOREIGN_KEY_CHECKS = 1").Error; err == nil {
+		err = resetErr
+	}
+	return count, err
+}
+
+func (r *Archiver) restoreStatements(tx *gorm.DB, rd io.Reader) (int64, error) {
+	br := bufio.NewReader(rd)
+	var count int64
+	for {
+		line, err := br.ReadString('\n')
+		if stmt := strings.TrimSpace(line); stmt != "" {
+			if !strings.HasPrefix(stmt, "INSERT INTO ") {
+				return count, fmt.Errorf("%w: unexpected statement in dump", entity.ErrBadRequest)
+			}
+			if err := tx.Exec(stmt).Error; err != nil {
+				return count, err
+			}
+			count++
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Attachments writes the files of the storage the records of the project refer to, one JSON
+// entity.ArchiveAttachment per line, and returns their number.
+func (r *Archiver) Attachments(db *gorm.DB, project string, w io.Writer) (int64, error) {
+	enc := json.NewEncoder(w)
+	var count int64
+	write := func(a *entity.ArchiveAttachment) error {
+		count++
+		return enc.Encode(a)
+	}
+
+	rows, err := db.Model(&model.ReviewInfo{}).Select("`id`, `take_path`").Where(
+		"`project` = ?", project,
+	).Where(
+		"`take_path` <> ''",
+	).Order("`id` asc").Rows()
+	if err != nil {
+		return count, err
+	}
+	for rows.Next() {
+		a := &entity.ArchiveAttachment{Kind: entity.ArchiveAttachmentTake}
+		if err := rows.Scan(&a.ReviewInfoID, &a.Path); err != nil {
+			rows.Close()
+			return count, err
+		}
+		if err := write(a); err != nil {
+			rows.Close()
+			return count, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return count, err
+	}
+	rows.Close()
+
+	var hashes []*model.FileHash
+	if err := db.Where(
+		"`project` = ?", project,
+	).Order("`id` asc").Find(&hashes).Error; err != nil {
+		return count, err
+	}
+	for _, m := range hashes {
+		size, hash := m.Size, m.Hash
+		if err := write(&entity.ArchiveAttachment{
+			Kind:         entity.ArchiveAttachmentFile,
+			ReviewInfoID: m.ReviewInfoID,
+			Path:         m.Path,
+			Size:         &size,
+			Hash:         &hash,
+		}); err != nil {
+			return count, err
+		}
+	}
+
+	var transcodes []*model.ReviewTranscode
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`output` <> ''",
+	).Order("`review_info_id` asc").Find(&transcodes).Error; err != nil {
+		return count, err
+	}
+	for _, m := range transcodes {
+		if err := write(&entity.ArchiveAttachment{
+			Kind:         entity.ArchiveAttachmentTranscode,
+			ReviewInfoID: m.ReviewInfoID,
+			Path:         m.Output,
+		}); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Collections returns the collections of MongoDB but the system ones, and none when MongoDB
+// is not given.
+func (r *Archiver) Collections(ctx context.Context) ([]string, error) {
+	if r.mongoDB == nil {
+		return nil, nil
+	}
+	names, err := r.mongoDB.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	collections := make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.HasPrefix(name, "system.") {
+			collections = append(collections, name)
+		}
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
+func archiveDocumentFilter(project string) bson.D {
+	return bson.D{{"_central.project", project}}
+}
+
+// DumpCollection writes the documents of the project in the collection as canonical Extended
+// JSON, one per line, and returns their number.
+func (r *Archiver) DumpCollection(
+	ctx context.Context,
+	collection string,
+	project string,
+	w io.Writer,
+) (int64, error) {
+	cursor, err := r.mongoDB.Collection(collection).Find(ctx, archiveDocumentFilter(project))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	bw := bufio.NewWriter(w)
+	var count int64
+	for cursor.Next(ctx) {
+		b, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return count, err
+		}
+		bw.Write(b)
+		bw.WriteByte('\n')
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// CheckCollection checks that the collection has no document of the project.
+func (r *Archiver) CheckCollection(ctx context.Context, collection string, project string) error {
+	count, err := r.mongoDB.Collection(collection).CountDocuments(
+		ctx, archiveDocumentFilter(project),
+	)
+	if err != nil {
+		return err
+	}
+	if count != 0 {
+		return fmt.Errorf(
+			"%w: collection %s already has %d documents of project %s",
+			entity.ErrConflict, collection, count, project,
+		)
+	}
+	return nil
+}
+
+// RestoreCollection inserts the documents of a dump, one per line, and returns their number.
+func (r *Archiver) RestoreCollection(
+	ctx context.Context,
+	collection string,
+	rd io.Reader,
+) (int64, error) {
+	col := r.mongoDB.Collection(collection)
+	br := bufio.NewReader(rd)
+	var count int64
+	var batch []interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := col.InsertMany(ctx, batch); err != nil {
+			return err
+		}
+		count += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) != 0 {
+			var doc bson.D
+			if err := bson.UnmarshalExtJSON(line, true, &doc); err != nil {
+				return count, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+			}
+			batch = append(batch, doc)
+			if len(batch) == archiveBatchSize {
+				if err := flush(); err != nil {
+					return count, err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, flush()
+}
+
+// archiveGraphValue is a property of a node or a relationship, tagged with its type so that
+// the temporal values are restored as such.
+type archiveGraphValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type archiveGraphDuration struct {
+	Months  int64 `json:"months"`
+	Days    int64 `json:"days"`
+	Seconds int64 `json:"seconds"`
+	Nanos   int   `json:"nanos"`
+}
+
+type archiveGraphNode struct {
+	ID         string                        `json:"id"`
+	Labels     []string                      `json:"labels"`
+	Properties map[string]*archiveGraphValue `json:"properties"`
+}
+
+type archiveGraphRelationship struct {
+	ID         string                        `json:"id"`
+	Type       string                        `json:"type"`
+	Start      string                        `json:"start"`
+	End        string                        `json:"end"`
+	Properties map[string]*archiveGraphValue `json:"properties"`
+}
+
+// archiveTimeLayout keeps the offset and the nanoseconds of the temporal values.
+const archiveTimeLayout = time.RFC3339Nano
+
+func encodeArchiveGraphValue(v interface{}) (*archiveGraphValue, error) {
+	var typ string
+	var value interface{}
+	switch v := v.(type) {
+	case nil:
+		typ, value = "null", nil
+	case bool:
+		typ, value = "bool", v
+	case int64:
+		typ, value = "int", v
+	case float64:
+		typ, value = "float", v
+	case string:
+		typ, value = "string", v
+	case []byte:
+		typ, value = "bytes", v
+	case []interface{}:
+		items := make([]*archiveGraphValue, len(v))
+		for i, item := range v {
+			var err error
+			if items[i], err = encodeArchiveGraphValue(item); err != nil {
+				return nil, err
+			}
+		}
+		typ, value = "list", items
+	case time.Time:
+		typ, value = "datetime", v.Format(archiveTimeLayout)
+	case neo4j.Date:
+		typ, value = "date", time.Time(v).Format(time.DateOnly)
+	case neo4j.LocalDateTime:
+		typ, value = "localdatetime", time.Time(v).Format(archiveTimeLayout)
+	case neo4j.LocalTime:
+		typ, value = "localtime", time.Time(v).Format(archiveTimeLayout)
+	case neo4j.Time:
+		typ, value = "time", time.Time(v).Format(archiveTimeLayout)
+	case neo4j.Duration:
+		typ, value = "duration", &archiveGraphDuration{
+			Months:  v.Months,
+			Days:    v.Days,
+			Seconds: v.Seconds,
+			Nanos:   v.Nanos,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported property of type %T", v)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &archiveGraphValue{Type: typ, Value: b}, nil
+}
+
+func decodeArchiveGraphValue(v *archiveGraphValue) (interface{}, error) {
+	parseTime := func() (time.Time, error) {
+		var s string
+		if err := json.Unmarshal(v.Value, &s); err != nil {
+			return time.Time{}, err
+		}
+		return time.Parse(archiveTimeLayout, s)
+	}
+	switch v.Type {
+	case "null":
+		return nil, nil
+	case "bool":
+		var b bool
+		err := json.Unmarshal(v.Value, &b)
+		return b, err
+	case "int":
+		var n int64
+		err := json.Unmarshal(v.Value, &n)
+		return n, err
+	case "float":
+		var f float64
+		err := json.Unmarshal(v.Value, &f)
+		return f, err
+	case "string":
+		var s string
+		err := json.Unmarshal(v.Value, &s)
+		return s, err
+	case "bytes":
+		var b []byte
+		err := json.Unmarshal(v.Value, &b)
+		return b, err
+	case "list":
+		var items []*archiveGraphValue
+		if err := json.Unmarshal(v.Value, &items); err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if list[i], err = decodeArchiveGraphValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case "datetime":
+		return parseTime()
+	case "date":
+		var s string
+		if err := json.Unmarshal(v.Value, &s); err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.DateOnly, s)
+		return neo4j.Date(t), err
+	case "localdatetime":
+		t, err := parseTime()
+		return neo4j.LocalDateTime(t), err
+	case "localtime":
+		t, err := parseTime()
+		return neo4j.LocalTime(t), err
+	case "time":
+		t, err := parseTime()
+		return neo4j.Time(t), err
+	case "duration":
+		var d archiveGraphDuration
+		if err := json.Unmarshal(v.Value, &d); err != nil {
+			return nil, err
+		}
+		return neo4j.Duration{Months: d.Months, Days: d.Days, Seconds: d.Seconds, Nanos: d.Nanos}, nil
+	}
+	return nil, fmt.Errorf("unsupported property of type %q", v.Type)
+}
+
+func encodeArchiveGraphProperties(props map[string]interface{}) (map[string]*archiveGraphValue, error) {
+	encoded := make(map[string]*archiveGraphValue, len(props))
+	for k, v := range props {
+		e, err := encodeArchiveGraphValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		encoded[k] = e
+	}
+	return encoded, nil
+}
+
+func decodeArchiveGraphProperties(props map[string]*archiveGraphValue) (map[string]interface{}, error) {
+	decoded := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		d, err := decodeArchiveGraphValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", entity.ErrBadRequest, k, err)
+		}
+		decoded[k] = d
+	}
+	return decoded, nil
+}
+
+// archiveGraphNodesQuery matches the project and the nodes of its hierarchy as n.
+const archiveGraphNodesQuery = `
+MATCH (pj:Project {keyName: $project})
+OPTIONAL MATCH (pj)-[:` + archiveGraphRelationships + `*]->(c)
+WITH pj, collect(DISTINCT c) AS cs
+UNWIND [pj] + cs AS n
+WITH DISTINCT n
+`
+
+func (r *Archiver) readGraph(
+	ctx context.Context,
+	query string,
+	params map[string]interface{},
+	fn func(rec *neo4j.Record) error,
+) (err error) {
+	ctx, span := startNeo4jSpan(ctx, query)
+	defer func() { endSpan(span, err) }()
+	session := r.neo4j.NewSession(ctx, neo4j.SessionConfig{
+		AccessMode:   neo4j.AccessModeRead,
+		DatabaseName: "neo4j",
+	})
+	defer session.Close(ctx)
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		return err
+	}
+	for result.Next(ctx) {
+		if err := fn(result.Record()); err != nil {
+			return err
+		}
+	}
+	return result.Err()
+}
+
+// DumpGraph writes the nodes of the hierarchy of the project and their outgoing relationships,
+// one JSON object per line, and returns their numbers.
+func (r *Archiver) DumpGraph(
+	ctx context.Context,
+	project string,
+	nodes io.Writer,
+	relationships io.Writer,
+) (int64, int64, error) {
+	params := map[string]interface{}{"project": project}
+
+	var nodeCount int64
+	enc := json.NewEncoder(nodes)
+	if err := r.readGraph(ctx, archiveGraphNodesQuery+`
+RETURN elementId(n) AS id, labels(n) AS labels, properties(n) AS properties
+`, params, func(rec *neo4j.Record) error {
+		id, _ := rec.Get("id")
+		labels, _ := rec.Get("labels")
+		props, _ := rec.Get("properties")
+		n := &archiveGraphNode{ID: id.(string)}
+		for _, l := range labels.([]interface{}) {
+			n.Labels = append(n.Labels, l.(string))
+		}
+		var err error
+		if n.Properties, err = encodeArchiveGraphProperties(props.(map[string]interface{})); err != nil {
+			return fmt.Errorf("node %s: %w", n.ID, err)
+		}
+		nodeCount++
+		return enc.Encode(n)
+	}); err != nil {
+		return nodeCount, 0, err
+	}
+
+	var relCount int64
+	enc = json.NewEncoder(relationships)
+	if err := r.readGraph(ctx, archiveGraphNodesQuery+`
+MATCH (n)-[rel]->(m)
+RETURN elementId(rel) AS id, type(rel) AS type, elementId(n) AS start, elementId(m) AS end,
+	properties(rel) AS properties
+`, params, func(rec *neo4j.Record) error {
+		id, _ := rec.Get("id")
+		typ, _ := rec.Get("type")
+		start, _ := rec.Get("start")
+		end, _ := rec.Get("end")
+		props, _ := rec.Get("properties")
+		rel := &archiveGraphRelationship{
+			ID:    id.(string),
+			Type:  typ.(string),
+			Start: start.(string),
+			End:   end.(string),
+		}
+		var err error
+		if rel.Properties, err = encodeArchiveGraphProperties(props.(map[string]interface{})); err != nil {
+			return fmt.Errorf("relationship %s: %w", rel.ID, err)
+		}
+		relCount++
+		return enc.Encode(rel)
+	}); err != nil {
+		return nodeCount, relCount, err
+	}
+	return nodeCount, relCount, nil
+}
+
+// CheckGraph checks that the graph has no project of the key.
+func (r *Archiver) CheckGraph(ctx context.Context, project string) error {
+	query := "MATCH (pj:Project {keyName: $project}) RETURN count(pj) AS count"
+	ctx, span := startNeo4jSpan(ctx, query)
+	result, err := neo4j.ExecuteQuery(
+		ctx,
+		r.neo4j,
+		query,
+		map[string]interface{}{"project": project},
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase("neo4j"),
+	)
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	if len(result.Records) != 0 {
+		if count, _ := result.Records[0].Get("count"); count.(int64) != 0 {
+			return fmt.Errorf("%w: the graph already has project %s", entity.ErrConflict, project)
+		}
+	}
+	return nil
+}
+
+func (r *Archiver) writeGraph(
+	ctx context.Context,
+	query string,
+	params map[string]interface{},
+) (*neo4j.EagerResult, error) {
+	ctx, span := startNeo4jSpan(ctx, query)
+	result, err := neo4j.ExecuteQuery(
+		ctx,
+		r.neo4j,
+		query,
+		params,
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase("neo4j"),
+	)
+	endSpan(span, err)
+	return result, err
+}
+
+// archiveCypherName quotes a label or a relationship type.
+func archiveCypherName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// RestoreGraph creates the nodes and relationships of a dump. The relationships to the nodes
+// which are not in the dump are skipped. It returns the numbers of the nodes, relationships
+// and skipped relationships.
+func (r *Archiver) RestoreGraph(
+	ctx context.Context,
+	nodes io.Reader,
+	relationships io.Reader,
+) (int64, int64, int64, error) {
+	if _, err := r.writeGraph(ctx, fmt.Sprintf(
+		"CREATE INDEX archive_import_id IF NOT EXISTS FOR (n:%s) ON (n.archiveId)",
+		archiveImportLabel,
+	), nil); err != nil {
+		return 0, 0, 0, err
+	}
+
+	// the nodes are created by set of labels, which cannot be parameters
+	ids := map[string]bool{}
+	byLabels := map[string][]map[string]interface{}{}
+	var nodeCount int64
+	flushNodes := func(key string) error {
+		batch := byLabels[key]
+		if len(batch) == 0 {
+			return nil
+		}
+		labels := archiveImportLabel
+		if key != "" {
+			for _, l := range strings.Split(key, "\x00") {
+				labels += ":" + archiveCypherName(l)
+			}
+		}
+		if _, err := r.writeGraph(ctx, fmt.Sprintf(
+			"UNWIND $nodes AS n CREATE (x:%s {archiveId: n.id}) SET x += n.properties",
+			labels,
+		), map[string]interface{}{"nodes": batch}); err != nil {
+			return err
+		}
+		nodeCount += int64(len(batch))
+		byLabels[key] = nil
+		return nil
+	}
+	dec := json.NewDecoder(nodes)
+	for {
+		var n archiveGraphNode
+		if err := dec.Decode(&n); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nodeCount, 0, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+		}
+		props, err := decodeArchiveGraphProperties(n.Properties)
+		if err != nil {
+			return nodeCount, 0, 0, err
+		}
+		ids[n.ID] = true
+		sort.Strings(n.Labels)
+		key := strings.Join(n.Labels, "\x00")
+		byLabels[key] = append(byLabels[key], map[string]interface{}{
+			"id":         n.ID,
+			"properties": props,
+		})
+		if len(byLabels[key]) == archiveBatchSize {
+			if err := flushNodes(key); err != nil {
+				return nodeCount, 0, 0, err
+			}
+		}
+	}
+	for key := range byLabels {
+		if err := flushNodes(key); err != nil {
+			return nodeCount, 0, 0, err
+		}
+	}
+
+	byType := map[string][]map[string]interface{}{}
+	var relCount, skipped int64
+	flushRelationships := func(typ string) error {
+		batch := byType[typ]
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := r.writeGraph(ctx, fmt.Sprintf(
+			"UNWIND $relationships AS r "+
+				"MATCH (a:%[1]s {archiveId: r.start}), (b:%[1]s {archiveId: r.end}) "+
+				"CREATE (a)-[x:%[2]s]->(b) SET x += r.properties",
+			archiveImportLabel, archiveCypherName(typ),
+		), map[string]interface{}{"relationships": batch}); err != nil {
+			return err
+		}
+		relCount += int64(len(batch))
+		byType[typ] = nil
+		return nil
+	}
+	dec = json.NewDecoder(relationships)
+	for {
+		var rel archiveGraphRelationship
+		if err := dec.Decode(&rel); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nodeCount, relCount, skipped, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+		}
+		if !ids[rel.Start] || !ids[rel.End] {
+			skipped++
+			continue
+		}
+		props, err := decodeArchiveGraphProperties(rel.Properties)
+		if err != nil {
+			return nodeCount, relCount, skipped, err
+		}
+		byType[rel.Type] = append(byType[rel.Type], map[string]interface{}{
+			"start":      rel.Start,
+			"end":        rel.End,
+			"properties": props,
+		})
+		if len(byType[rel.Type]) == archiveBatchSize {
+			if err := flushRelationships(rel.Type); err != nil {
+				return nodeCount, relCount, skipped, err
+			}
+		}
+	}
+	for typ := range byType {
+		if err := flushRelationships(typ); err != nil {
+			return nodeCount, relCount, skipped, err
+		}
+	}
+
+	// the restored nodes are unmarked once all are linked
+	for {
+		result, err := r.writeGraph(ctx, fmt.Sprintf(
+			"MATCH (n:%[1]s) WITH n LIMIT %[2]d REMOVE n:%[1]s, n.archiveId RETURN count(n) AS count",
+			archiveImportLabel, archiveBatchSize,
+		), nil)
+		if err != nil {
+			return nodeCount, relCount, skipped, err
+		}
+		if len(result.Records) == 0 {
+			break
+		}
+		if count, _ := result.Records[0].Get("count"); count.(int64) == 0 {
+			break
+		}
+	}
+	return nodeCount, relCount, skipped, nil
+}
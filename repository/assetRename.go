@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// AssetRename moves the history of renamed assets from their old group_1 to the new one.
+type AssetRename struct {
+	db *gorm.DB
+}
+
+func NewAssetRename(db *gorm.DB) (*AssetRename, error) {
+	if err := db.AutoMigrate(&model.AssetRenameLog{}); err != nil {
+		return nil, err
+	}
+	return &AssetRename{
+		db: db,
+	}, nil
+}
+
+func (r *AssetRename) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *AssetRename) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// List lists the renames of a project, latest first. Asset keeps the renames from or to it.
+func (r *AssetRename) List(
+	db *gorm.DB,
+	params *entity.ListAssetRenamesParams,
+) ([]*entity.AssetRename, error) {
+	stmt := db.Where("`project` = ?", params.Project)
+	if params.Asset != nil {
+		stmt = stmt.Where("(`from` = ? OR `to` = ?)", *params.Asset, *params.Asset)
+	}
+	var models []*model.AssetRenameLog
+	if err := stmt.Order("`id` desc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.AssetRename, len(models))
+	for i, m := range models {
+		e, err := m.Entity()
+		if err != nil {
+			return nil, err
+		}
+		entities[i] = e
+	}
+	return entities, nil
+}
+
+// Rename moves the reviews and the category mappings and official revisions of the asset
+// From to To. Status logs refer to the reviews by ID, so they follow the reviews and are only
+// counted. Mappings of From to categories which have To already are deleted. It must be
+// called in a transaction, which the caller rolls back for a dry run.
+func (r *AssetRename) Rename(
+	tx *gorm.DB,
+	params *entity.RenameAssetParams,
+) (*entity.AssetRename, error) {
+	var ids []int32
+	if err := tx.Table("t_review_info").Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`root` = ?", params.Root,
+	).Where(
+		"`group_1` = ?", params.From,
+	).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf(
+			"%w: asset %q not found in %q", entity.ErrRecordNotFound, params.From, params.Root,
+		)
+	}
+
+	var existing int64
+	if err := tx.Table("t_review_info").Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`root` = ?", params.Root,
+	).Where(
+		"`group_1` = ?", params.To,
+	).Where(
+		"`deleted` = ?", 0,
+	).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	e := &entity.AssetRename{
+		Project:      params.Project,
+		Root:         params.Root,
+		From:         params.From,
+		To:           params.To,
+		DryRun:       params.DryRun,
+		Merge:        existing != 0,
+		Studio:       params.Studio,
+		CreatedAtUTC: now,
+		CreatedBy:    params.CreatedBy,
+	}
+
+	// group_1 is generated from the first of the groups.
+	result := tx.Table("t_review_info").Where("`id` IN ?", ids).Updates(
+		map[string]interface{}{
+			"groups":  gorm.Expr("JSON_SET(`groups`, '$[0]', ?)", params.To),
+			"version": gorm.Expr("`version` + 1"),
+		},
+	)
+	if result.Error != nil {
+		return nil, renameConflict(result.Error, "reviews", params.To)
+	}
+	e.Changes = append(e.Changes, &entity.AssetRenameChange{
+		Table: "t_review_info",
+		Moved: result.RowsAffected,
+	})
+	if _, err := refreshReviewLatest(
+		tx, params.Project, params.Root, []string{params.From, params.To},
+	); err != nil {
+		return nil, err
+	}
+
+	var logs int64
+	if err := tx.Table("t_review_status_log").Where(
+		"`review_info_id` IN ?", ids,
+	).Count(&logs).Error; err != nil {
+		return nil, err
+	}
+	e.Changes = append(e.Changes, &entity.AssetRenameChange{
+		Table: "t_review_status_log",
+		Moved: logs,
+	})
+
+	categories := tx.Table("t_group_category").Select("id").Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`root` = ?", params.Root,
+	).Where(
+		"`deleted` = ?", 0,
+	)
+	var mapped []int32
+	if err := tx.Table("t_group_category_group").Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`path` = ?", params.To,
+	).Where(
+		"`deleted` = ?", 0,
+	).Pluck("group_category_id", &mapped).Error; err != nil {
+		return nil, err
+	}
+	mappings := func() *gorm.DB {
+		return tx.Table("t_group_category_group").Where(
+			"`project` = ?", params.Project,
+		).Where(
+			"`path` = ?", params.From,
+		).Where(
+			"`deleted` = ?", 0,
+		).Where(
+			"`group_category_id` IN (?)", categories,
+		)
+	}
+	change := &entity.AssetRenameChange{Table: "t_group_category_group"}
+	if len(mapped) != 0 {
+		result = mappings().Where("`group_category_id` IN ?", mapped).Updates(
+			map[string]interface{}{
+				"deleted":         gorm.Expr("id"),
+				"modified_by":     params.CreatedBy,
+				"modified_at_utc": now,
+			},
+		)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		change.Merged = result.RowsAffected
+	}
+	result = mappings().Updates(map[string]interface{}{
+		"path":            params.To,
+		"modified_by":     params.CreatedBy,
+		"modified_at_utc": now,
+	})
+	if result.Error != nil {
+		return nil, renameConflict(result.Error, "category mappings", params.To)
+	}
+	change.Moved = result.RowsAffected
+	e.Changes = append(e.Changes, change)
+
+	result = tx.Table("t_official_revision").Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`root` = ?", params.Root,
+	).Where(
+		"`group` = ?", params.From,
+	).Update("group", params.To)
+	if result.Error != nil {
+		return nil, renameConflict(result.Error, "official revisions", params.To)
+	}
+	e.Changes = append(e.Changes, &entity.AssetRenameChange{
+		Table: "t_official_revision",
+		Moved: result.RowsAffected,
+	})
+	return e, nil
+}
+
+func renameConflict(err error, records string, to string) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return fmt.Errorf("%w: %s of %q are already exists", entity.ErrBadRequest, records, to)
+	}
+	return err
+}
+
+// CreateLog records the rename in the audit log and sets its ID.
+func (r *AssetRename) CreateLog(tx *gorm.DB, e *entity.AssetRename) error {
+	m, err := model.NewAssetRenameLog(e)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(m).Error; err != nil {
+		return err
+	}
+	e.ID = m.ID
+	return nil
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+const snapshotDateLayout = "2006-01-02"
+
+// AssetStatusSnapshot records the latest review of every phase of the assets of the projects
+// once a day, and compares the snapshots of two dates.
+type AssetStatusSnapshot struct {
+	db *gorm.DB
+}
+
+func NewAssetStatusSnapshot(db *gorm.DB) (*AssetStatusSnapshot, error) {
+	if err := db.AutoMigrate(&model.AssetStatusSnapshot{}); err != nil {
+		return nil, err
+	}
+	return &AssetStatusSnapshot{
+		db: db,
+	}, nil
+}
+
+func (r *AssetStatusSnapshot) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *AssetStatusSnapshot) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// ListUnsnapshotted returns the projects with assets which have no snapshot of the date.
+func (r *AssetStatusSnapshot) ListUnsnapshotted(db *gorm.DB, date time.Time) ([]string, error) {
+	var projects []string
+	if err := db.Table("t_review_info").Where(
+		"`root` = ?", "assets",
+	).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` NOT IN (?)", db.Model(&model.AssetStatusSnapshot{}).Select("project").Where(
+			"`snapshot_date` = ?", date.Format(snapshotDateLayout),
+		),
+	).Distinct().Order("`project` asc").Pluck("project", &projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// Take replaces the snapshot of the date of a project with the latest review of every phase
+// of its assets, and returns the number of rows written.
+func (r *AssetStatusSnapshot) Take(tx *gorm.DB, project string, date time.Time) (int64, error) {
+	day := date.Format(snapshotDateLayout)
+	if err := tx.Where(
+		"`project` = ?", project,
+	).Where(
+		"`snapshot_date` = ?", day,
+	).Delete(&model.AssetStatusSnapshot{}).Error; err != nil {
+		return 0, err
+	}
+	ranked := tx.Table("t_review_info").Select(
+		"id, group_1, relation, phase, take, approval_status, work_status, "+
+			"ROW_NUMBER() OVER ("+
+			"PARTITION BY group_1, relation, phase "+
+			"ORDER BY modified_at_utc DESC, id DESC"+
+			") AS rn",
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`root` = ?", "assets",
+	).Where(
+		"`deleted` = ?", 0,
+	).Where("`group_1` IS NOT NULL")
+	result := tx.Exec(
+		"INSERT INTO `t_asset_status_snapshot` (project, snapshot_date, group_1, relation, "+
+			"phase, take, approval_status, work_status, review_info_id, created_at_utc) ?",
+		tx.Table("(?) AS r", ranked).Select(
+			"?, ?, group_1, relation, phase, take, approval_status, work_status, id, ?",
+			project, day, time.Now().UTC(),
+		).Where("rn = ?", 1),
+	)
+	return result.RowsAffected, result.Error
+}
+
+// snapshotDate returns the date of the latest snapshot of the project taken on or before the
+// date.
+func (r *AssetStatusSnapshot) snapshotDate(
+	db *gorm.DB,
+	project string,
+	date time.Time,
+) (string, error) {
+	var m model.AssetStatusSnapshot
+	if err := db.Select("snapshot_date").Where(
+		"`project` = ?", project,
+	).Where(
+		"`snapshot_date` <= ?", date.Format(snapshotDateLayout),
+	).Order("`snapshot_date` desc").Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf(
+				"%w: asset status snapshot of %s on or before %s",
+				entity.ErrRecordNotFound, project, date.Format(snapshotDateLayout),
+			)
+		}
+		return "", err
+	}
+	return m.SnapshotDate.Format(snapshotDateLayout), nil
+}
+
+func (r *AssetStatusSnapshot) list(
+	db *gorm.DB,
+	project string,
+	day string,
+) ([]*model.AssetStatusSnapshot, error) {
+	var models []*model.AssetStatusSnapshot
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`snapshot_date` = ?", day,
+	).Order(
+		"`group_1` asc",
+	).Order(
+		"`relation` asc",
+	).Order(
+		"`phase` asc",
+	).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// Diff compares the latest snapshots of the project taken on or before params.From and
+// params.To. The phases only in the earlier snapshot, whose reviews were deleted since, are
+// not reported.
+func (r *AssetStatusSnapshot) Diff(
+	db *gorm.DB,
+	params *entity.GetPivotDiffParams,
+) (*entity.PivotDiff, error) {
+	from, err := r.snapshotDate(db, params.Project, params.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := r.snapshotDate(db, params.Project, params.To)
+	if err != nil {
+		return nil, err
+	}
+	fromModels, err := r.list(db, params.Project, from)
+	if err != nil {
+		return nil, err
+	}
+	toModels, err := r.list(db, params.Project, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type assetKey struct{ group, relation string }
+	before := map[assetKey]map[string]*model.AssetStatusSnapshot{}
+	for _, m := range fromModels {
+		k := assetKey{m.Group1, m.Relation}
+		if before[k] == nil {
+			before[k] = map[string]*model.AssetStatusSnapshot{}
+		}
+		before[k][m.Phase] = m
+	}
+
+	diff := &entity.PivotDiff{
+		Project:     params.Project,
+		From:        from,
+		To:          to,
+		Changed:     []*entity.PivotDiffAsset{},
+		Added:       []*entity.PivotDiffAsset{},
+		Regressions: []*entity.PivotDiffAsset{},
+	}
+	var asset, regressed *entity.PivotDiffAsset
+	var current assetKey
+	flush := func() {
+		if asset == nil {
+			return
+		}
+		if _, ok := before[current]; !ok {
+			diff.Added = append(diff.Added, asset)
+		} else if len(asset.Phases) != 0 {
+			diff.Changed = append(diff.Changed, asset)
+		}
+		if regressed != nil {
+			diff.Regressions = append(diff.Regressions, regressed)
+		}
+		asset, regressed = nil, nil
+	}
+	for _, m := range toModels {
+		k := assetKey{m.Group1, m.Relation}
+		if asset == nil || k != current {
+			flush()
+			current = k
+			asset = &entity.PivotDiffAsset{
+				Group:    m.Group1,
+				Relation: m.Relation,
+				Phases:   []*entity.PivotPhaseChange{},
+			}
+		}
+		change := &entity.PivotPhaseChange{
+			Phase:            m.Phase,
+			ToApprovalStatus: m.ApprovalStatus,
+			ToWorkStatus:     m.WorkStatus,
+			ToTake:           m.Take,
+		}
+		if prev, ok := before[k][m.Phase]; ok {
+			if prev.ApprovalStatus == m.ApprovalStatus && prev.WorkStatus == m.WorkStatus {
+				continue
+			}
+			change.FromApprovalStatus = &prev.ApprovalStatus
+			change.FromWorkStatus = &prev.WorkStatus
+			change.FromTake = &prev.Take
+			change.Regression = entity.IsPivotRegression(prev.ApprovalStatus, m.ApprovalStatus)
+		}
+		asset.Phases = append(asset.Phases, change)
+		if change.Regression {
+			if regressed == nil {
+				regressed = &entity.PivotDiffAsset{Group: m.Group1, Relation: m.Relation}
+			}
+			regressed.Phases = append(regressed.Phases, change)
+		}
+	}
+	flush()
+	return diff, nil
+}
@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang-jwt/jwt"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTokenClockSkew         = 30 * time.Second
+	defaultQueryTokenReplayWindow = 5 * time.Minute
+)
+
+func durationFromEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	return d, nil
+}
+
+func loadPrivateKey(stringkey string) (*rsa.PrivateKey, error) {
+	content := []byte(stringkey)
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block containing private key")
+	}
+	if block.Type != entity.KeytypePrivate {
+		return nil, fmt.Errorf("invalid private key type: %s", block.Type)
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func loadPublicKey(stringkey string) (interface{}, error) {
+	content := []byte(stringkey)
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block containing public key")
+	}
+	if block.Type != entity.KeytypePublic {
+		return nil, fmt.Errorf("invalid public key type: %s", block.Type)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+type Auth struct {
+	db              *gorm.DB
+	ps              *ProjectStudioMap
+	signingKey      *rsa.PrivateKey
+	verificationKey interface{}
+	stuRepo         *StudioInfo
+
+	// clockSkew is the tolerance applied to the time based claims of tokens.
+	clockSkew time.Duration
+	// replayWindow is how long after being issued a token may be used once as a query token.
+	replayWindow time.Duration
+}
+
+func NewAuth(db *gorm.DB, ps *ProjectStudioMap, stuRepo *StudioInfo) (*Auth, error) {
+	db.AutoMigrate(&model.StudioAuth{})
+	if err := db.AutoMigrate(&model.UsedToken{}); err != nil {
+		return nil, err
+	}
+	signingKeyStr := os.Getenv("PPI_KEY_PRIVATE")
+	verificationKeyStr := os.Getenv("PPI_KEY_PUBLIC")
+	if signingKeyStr == "" || verificationKeyStr == "" {
+		return nil, errors.New("key not found")
+	}
+	signingKey, err := loadPrivateKey(strings.Replace(signingKeyStr, `\n`, "\n", -1))
+	if err != nil {
+		return nil, err
+	}
+	verificationKey, err := loadPublicKey(strings.Replace(verificationKeyStr, `\n`, "\n", -1))
+	if err != nil {
+		return nil, err
+	}
+	clockSkew, err := durationFromEnv("PPI_TOKEN_CLOCK_SKEW", defaultTokenClockSkew)
+	if err != nil {
+		return nil, err
+	}
+	replayWindow, err := durationFromEnv(
+		"PPI_QUERY_TOKEN_REPLAY_WINDOW", defaultQueryTokenReplayWindow,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Auth{
+		db:              db,
+		ps:              ps,
+		signingKey:      signingKey,
+		verificationKey: verificationKey,
+		stuRepo:         stuRepo,
+		clockSkew:       clockSkew,
+		replayWindow:    replayWindow,
+	}, nil
+}
+
+func (r *Auth) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// Entry is the studio the token is issued to, with the user authenticated by the OpenID
+// Connect provider. User is omitted from the tokens of a studio password.
+type Entry struct {
+	Name string
+	User string `json:",omitempty"`
+}
+
+type CustomClaims struct {
+	*jwt.StandardClaims
+	Section entity.Section
+	Entry   Entry
+}
+
+func (r *Auth) ParseToken(db *gorm.DB, tokenStr string) (*entity.TokenInfo, error) {
+	claims, err := r.parse(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	name := claims.Entry.Name
+	if name == "" || r.checkForStudio(r.db, name) != nil {
+		return nil, entity.ErrUnauthorized
+	}
+	return claims.tokenInfo(), nil
+}
+
+// ParseQueryToken parses a token passed in the query parameter. Since such tokens may leak
+// through access logs and browser history, each of them is only accepted once, within the
+// replay window following its issue.
+func (r *Auth) ParseQueryToken(db *gorm.DB, tokenStr string) (*entity.TokenInfo, error) {
+	claims, err := r.parse(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Id == "" || claims.IssuedAt == 0 {
+		return nil, entity.ErrUnauthorized
+	}
+	now := time.Now()
+	windowEnd := time.Unix(claims.IssuedAt, 0).Add(r.replayWindow + r.clockSkew)
+	if now.After(windowEnd) {
+		return nil, fmt.Errorf(
+			"%w: query token was issued more than %s ago", entity.ErrTokenReplayed, r.replayWindow,
+		)
+	}
+	name := claims.Entry.Name
+	if name == "" || r.checkForStudio(db, name) != nil {
+		return nil, entity.ErrUnauthorized
+	}
+
+	// Tokens out of their replay window are rejected above, so they need not be kept.
+	if err := db.Where(
+		"`expires_at_utc` < ?", now.UTC(),
+	).Delete(&model.UsedToken{}).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Create(model.NewUsedToken(claims.Id, name, windowEnd)).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, entity.ErrTokenReplayed
+		}
+		return nil, err
+	}
+	return claims.tokenInfo(), nil
+}
+
+// GetToken returns the claims of the token.
+func (r *Auth) GetToken(db *gorm.DB, tokenStr string) (*entity.TokenInfo, error) {
+	claims, err := r.parse(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	name := claims.Entry.Name
+	if name == "" || r.checkForStudio(db, name) != nil {
+		return nil, entity.ErrUnauthorized
+	}
+	return claims.tokenInfo(), nil
+}
+
+func (c *CustomClaims) tokenInfo() *entity.TokenInfo {
+	return &entity.TokenInfo{
+		ID:           c.Id,
+		Studio:       c.Entry.Name,
+		User:         c.Entry.User,
+		IssuedAtUTC:  time.Unix(c.IssuedAt, 0).UTC(),
+		ExpiresAtUTC: time.Unix(c.ExpiresAt, 0).UTC(),
+	}
+}
+
+// ListStudioProjects returns the projects the studio has access to.
+func (r *Auth) ListStudioProjects(db *gorm.DB, studio string) ([]string, error) {
+	mappings, _, err := r.ps.List(db, &entity.ListProjectStudioMapParams{Studio: &studio})
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]string, len(mappings))
+	for i, mapping := range mappings {
+		projects[i] = mapping.Project
+	}
+	return projects, nil
+}
+
+func (r *Auth) CheckProjectAccess(db *gorm.DB, params *entity.ProjectAccessParams) error {
+	db.Model(&model.ProjectStudioMap{})
+	mappings, _, err := r.ps.List(db, &entity.ListProjectStudioMapParams{Studio: &params.Studio})
+	if err != nil {
+		return err
+	}
+	for _, mapping := range mappings {
+		if params.Project == mapping.Project {
+			return nil
+		}
+	}
+	return entity.ErrForbidden
+}
+
+// CreateNewToken issues a token of the studio entry, acting as the authenticated user when user
+// is not empty.
+func (r *Auth) CreateNewToken(entry string, user string) (string, error) {
+	t := jwt.New(jwt.GetSigningMethod(entity.Sign))
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	t.Claims = &CustomClaims{
+		&jwt.StandardClaims{
+			Id:        jti,
+			ExpiresAt: time.Now().Add(time.Hour * 1).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		entity.StudioAuth,
+		Entry{Name: entry, User: user},
+	}
+	signedStr, err := t.SignedString(r.signingKey)
+	if err != nil {
+		return "", err
+	}
+	return signedStr, nil
+}
+
+func (r *Auth) Login(db *gorm.DB, params *entity.LoginParams) (string, error) {
+	info, err := queryLoginInfo(db, params.Studio)
+	if err != nil {
+		return "", err
+	}
+	sha256 := sha256.Sum256([]byte(params.Password + info.Salt))
+	hash := hex.EncodeToString(sha256[:])
+	if info.Password != hash {
+		return "", entity.ErrUnauthorized
+	}
+	return params.Studio, nil
+}
+
+// CheckStudio returns ErrUnauthorized unless the studio a user logged in to with the OpenID
+// Connect provider exists.
+func (r *Auth) CheckStudio(db *gorm.DB, studio string) error {
+	if err := r.checkForStudio(db, studio); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return fmt.Errorf("%w: studio %q does not exist", entity.ErrUnauthorized, studio)
+		}
+		return err
+	}
+	return nil
+}
+
+func queryLoginInfo(db *gorm.DB, studio string) (*model.StudioAuth, error) {
+	var m model.StudioAuth
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`studio` = ?", studio,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parse verifies the signature of the token and validates its time based claims, tolerating
+// the configured clock skew between the issuer and this server.
+func (r *Auth) parse(tokenStr string) (*CustomClaims, error) {
+	claims := &CustomClaims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	if _, err := parser.ParseWithClaims(
+		tokenStr,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, entity.ErrUnauthorized
+			}
+			return r.verificationKey, nil
+		},
+	); err != nil {
+		return nil, entity.ErrUnauthorized
+	}
+	if claims.StandardClaims == nil {
+		return nil, entity.ErrUnauthorized
+	}
+	now := time.Now()
+	if !claims.VerifyExpiresAt(now.Add(-r.clockSkew).Unix(), true) {
+		return nil, entity.ErrTokenExpired
+	}
+	if !claims.VerifyIssuedAt(now.Add(r.clockSkew).Unix(), false) ||
+		!claims.VerifyNotBefore(now.Add(r.clockSkew).Unix(), false) {
+		return nil, fmt.Errorf("%w: token is not valid yet", entity.ErrUnauthorized)
+	}
+	return claims, nil
+}
+
+func (r *Auth) checkForStudio(db *gorm.DB, studio string) error {
+	db.Model(&model.StudioInfo{})
+	_, err := r.stuRepo.Get(db, &entity.GetStudioInfoParams{
+		KeyName: studio,
+	})
+	return err
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	testClockSkew    = 30 * time.Second
+	testReplayWindow = 5 * time.Minute
+)
+
+// newTestAuth returns an Auth signing and verifying tokens with a key of its own.
+func newTestAuth(tb testing.TB) *Auth {
+	tb.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &Auth{
+		signingKey:      key,
+		verificationKey: &key.PublicKey,
+		clockSkew:       testClockSkew,
+		replayWindow:    testReplayWindow,
+	}
+}
+
+func signTestToken(tb testing.TB, r *Auth, claims *jwt.StandardClaims, studio string) string {
+	tb.Helper()
+	t := jwt.New(jwt.GetSigningMethod(entity.Sign))
+	t.Claims = &CustomClaims{claims, entity.StudioAuth, Entry{Name: studio}}
+	s, err := t.SignedString(r.signingKey)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return s
+}
+
+func TestParseClockSkew(t *testing.T) {
+	r := newTestAuth(t)
+	now := time.Now()
+	margin := 5 * time.Second
+	tests := []struct {
+		desc    string
+		claims  *jwt.StandardClaims
+		wantErr error
+	}{
+		{
+			desc: "expired just within the clock skew",
+			claims: &jwt.StandardClaims{
+				ExpiresAt: now.Add(-testClockSkew + margin).Unix(),
+			},
+		},
+		{
+			desc: "expired just outside the clock skew",
+			claims: &jwt.StandardClaims{
+				ExpiresAt: now.Add(-testClockSkew - margin).Unix(),
+			},
+			wantErr: entity.ErrTokenExpired,
+		},
+		{
+			desc: "issued just within the clock skew",
+			claims: &jwt.StandardClaims{
+				ExpiresAt: now.Add(time.Hour).Unix(),
+				IssuedAt:  now.Add(testClockSkew - margin).Unix(),
+			},
+		},
+		{
+			desc: "issued just outside the clock skew",
+			claims: &jwt.StandardClaims{
+				ExpiresAt: now.Add(time.Hour).Unix(),
+				IssuedAt:  now.Add(testClockSkew + margin).Unix(),
+			},
+			wantErr: entity.ErrUnauthorized,
+		},
+		{
+			desc: "valid just within the clock skew",
+			claims: &jwt.StandardClaims{
+				ExpiresAt: now.Add(time.Hour).Unix(),
+				NotBefore: now.Add(testClockSkew - margin).Unix(),
+			},
+		},
+		{
+			desc: "valid just outside the clock skew",
+			claims: &jwt.StandardClaims{
+				ExpiresAt: now.Add(time.Hour).Unix(),
+				NotBefore: now.Add(testClockSkew + margin).Unix(),
+			},
+			wantErr: entity.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := r.parse(signTestToken(t, r, tt.claims, "studioa"))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("parse: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parse: %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrTokenExpired) != errors.Is(tt.wantErr, entity.ErrTokenExpired) {
+				t.Errorf("parse: %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestParseQueryTokenClaims checks the query tokens rejected before they are recorded, which
+// therefore need no database.
+func TestParseQueryTokenClaims(t *testing.T) {
+	r := newTestAuth(t)
+	now := time.Now()
+	tests := []struct {
+		desc     string
+		claims   *jwt.StandardClaims
+		wantCode string
+	}{
+		{
+			desc: "without a jti",
+			claims: &jwt.StandardClaims{
+				ExpiresAt: now.Add(time.Hour).Unix(),
+				IssuedAt:  now.Unix(),
+			},
+			wantCode: entity.TokenErrorInvalid,
+		},
+		{
+			desc: "without an iat",
+			claims: &jwt.StandardClaims{
+				Id:        "0123456789abcdef",
+				ExpiresAt: now.Add(time.Hour).Unix(),
+			},
+			wantCode: entity.TokenErrorInvalid,
+		},
+		{
+			desc: "issued before the replay window",
+			claims: &jwt.StandardClaims{
+				Id:        "0123456789abcdef",
+				ExpiresAt: now.Add(time.Hour).Unix(),
+				IssuedAt:  now.Add(-testReplayWindow - testClockSkew - time.Minute).Unix(),
+			},
+			wantCode: entity.TokenErrorReplayed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := r.ParseQueryToken(nil, signTestToken(t, r, tt.claims, "studioa"))
+			if !errors.Is(err, entity.ErrUnauthorized) {
+				t.Fatalf("ParseQueryToken: %v, want %v", err, entity.ErrUnauthorized)
+			}
+			if code := entity.TokenErrorCode(err); code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// testStudioEnv names a studio of the test database, for the tests requiring one.
+const testStudioEnv = "PPI_TEST_STUDIO"
+
+func TestParseQueryTokenReplay(t *testing.T) {
+	db := openTestDB(t)
+	studio := os.Getenv(testStudioEnv)
+	if studio == "" {
+		t.Skipf("%s is not set", testStudioEnv)
+	}
+	if err := db.AutoMigrate(&model.UsedToken{}); err != nil {
+		t.Fatal(err)
+	}
+	stuRepo, err := NewStudioInfo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestAuth(t)
+	r.db = db
+	r.stuRepo = stuRepo
+
+	jti, err := newTokenID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Where("`jti` = ?", jti).Delete(&model.UsedToken{})
+	})
+	now := time.Now()
+	token := signTestToken(t, r, &jwt.StandardClaims{
+		Id:        jti,
+		ExpiresAt: now.Add(time.Hour).Unix(),
+		IssuedAt:  now.Unix(),
+	}, studio)
+
+	info, err := r.ParseQueryToken(db, token)
+	if err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if info.ID != jti || info.Studio != studio {
+		t.Errorf("token = %s of %s, want %s of %s", info.ID, info.Studio, jti, studio)
+	}
+	_, err = r.ParseQueryToken(db, token)
+	if !errors.Is(err, entity.ErrTokenReplayed) {
+		t.Fatalf("replay: %v, want %v", err, entity.ErrTokenReplayed)
+	}
+	if code := entity.TokenErrorCode(err); code != entity.TokenErrorReplayed {
+		t.Errorf("code = %q, want %q", code, entity.TokenErrorReplayed)
+	}
+}
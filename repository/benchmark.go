@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Benchmark sends the requests of the load test mode.
+type Benchmark struct {
+	client *http.Client
+}
+
+func NewBenchmark(timeout time.Duration) *Benchmark {
+	return &Benchmark{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Request sends a GET request and returns the time until its body is fully read.
+func (r *Benchmark) Request(ctx context.Context, baseURL, path string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+path, nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start)
+	if resp.StatusCode != http.StatusOK {
+		return elapsed, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return elapsed, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// seedBenchmarkProject stores a project of entity.DefaultSeedParams, the data set of the bench
+// mode of the server, with its latest reviews rebuilt as they are maintained on write.
+func seedBenchmarkProject(b *testing.B) (*gorm.DB, *ReviewInfo, string) {
+	b.Helper()
+	db := openTestDB(b)
+	r, err := NewReviewInfo(db, nil, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := NewGroupCategory(db, nil); err != nil {
+		b.Fatal(err)
+	}
+	project := testProject(b, db)
+	params := entity.DefaultSeedParams()
+	if _, _, err := NewSeed(db).Create(
+		db, rand.New(rand.NewSource(params.Seed)), project, params,
+	); err != nil {
+		b.Fatal(err)
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := r.RebuildLatest(tx, &entity.RebuildReviewLatestParams{Project: project})
+		return err
+	}); err != nil {
+		b.Fatal(err)
+	}
+	return db, r, project
+}
+
+func BenchmarkListAssetsPivot(b *testing.B) {
+	db, r, project := seedBenchmarkProject(b)
+	for _, bm := range []struct {
+		name string
+		p    ListAssetsPivotParams
+	}{
+		{"first page", ListAssetsPivotParams{Page: 1}},
+		{"last page", ListAssetsPivotParams{Page: 10}},
+		{"phase sort", ListAssetsPivotParams{Sort: []PivotSortKey{{Key: "bld_work", Desc: true}}}},
+		{"status filter", ListAssetsPivotParams{ApprovalStatuses: []string{"check"}}},
+	} {
+		p := bm.p
+		p.Project, p.Root, p.View, p.PerPage = project, seedRoot, "list", 50
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := r.ListAssetsPivot(db, p); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkListAssetsPivotGrouped(b *testing.B) {
+	db, r, project := seedBenchmarkProject(b)
+	p := ListAssetsPivotParams{
+		Project: project,
+		Root:    seedRoot,
+		View:    "grouped",
+		PerPage: 50,
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.ListAssetsPivot(db, p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGenerateCsv measures the reads of the CSV exports: the pivot read a thousand rows
+// at a time as the pivot export does, and the queries of the tracker CSV.
+func BenchmarkGenerateCsv(b *testing.B) {
+	db, r, project := seedBenchmarkProject(b)
+	gc := NewGenerateCsv(db)
+
+	b.Run("pivot export", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p := ListAssetsPivotParams{
+				Project: project,
+				Root:    seedRoot,
+				View:    "list",
+				PerPage: 1000,
+			}
+			for {
+				result, err := r.ListAssetsPivot(db, p)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.NextCursor == "" {
+					break
+				}
+				p.Cursor = result.NextCursor
+			}
+		}
+	})
+	b.Run("tracker", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := gc.ListLatestAssetsReviews(db, project); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := gc.ListAssetsGroupCategory(db, project); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := gc.ListAllBldReviews(db, project); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+const defaultCapacityMaxInFlight = 200
+
+// Capacity reads the state of the database connection pool of the API.
+type Capacity struct {
+	db          *gorm.DB
+	maxInFlight int64
+}
+
+// NewCapacity returns the capacity repository. The number of requests the API serves at once
+// before it is saturated is set by PPI_CAPACITY_MAX_IN_FLIGHT.
+func NewCapacity(db *gorm.DB) (*Capacity, error) {
+	maxInFlight := int64(defaultCapacityMaxInFlight)
+	if v := os.Getenv("PPI_CAPACITY_MAX_IN_FLIGHT"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid PPI_CAPACITY_MAX_IN_FLIGHT: %q", v)
+		}
+		maxInFlight = n
+	}
+	return &Capacity{
+		db:          db,
+		maxInFlight: maxInFlight,
+	}, nil
+}
+
+func (r *Capacity) MaxInFlight() int64 {
+	return r.maxInFlight
+}
+
+// DBPool returns the statistics of the connection pool.
+func (r *Capacity) DBPool() (*entity.CapacityDBPool, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	stats := sqlDB.Stats()
+	pool := &entity.CapacityDBPool{
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		MaxOpenConnections: stats.MaxOpenConnections,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMS:     stats.WaitDuration.Milliseconds(),
+	}
+	if stats.MaxOpenConnections > 0 {
+		pool.Saturation = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	}
+	return pool, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// ClientCompat stores the compatibility matrix of the desktop tools.
+type ClientCompat struct {
+	db *gorm.DB
+}
+
+func NewClientCompat(db *gorm.DB) (*ClientCompat, error) {
+	if err := db.AutoMigrate(&model.ClientCompatRule{}); err != nil {
+		return nil, err
+	}
+	return &ClientCompat{
+		db: db,
+	}, nil
+}
+
+func (r *ClientCompat) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ClientCompat) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *ClientCompat) List(db *gorm.DB) ([]*entity.ClientCompatRule, error) {
+	var models []*model.ClientCompatRule
+	if err := db.Order("`client` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ClientCompatRule, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *ClientCompat) get(db *gorm.DB, client string) (*model.ClientCompatRule, error) {
+	var m model.ClientCompatRule
+	if err := db.Where("`client` = ?", client).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: client %q", entity.ErrRecordNotFound, client)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *ClientCompat) Get(db *gorm.DB, client string) (*entity.ClientCompatRule, error) {
+	m, err := r.get(db, client)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *ClientCompat) Update(
+	tx *gorm.DB,
+	params *entity.UpdateClientCompatRuleParams,
+) (*entity.ClientCompatRule, error) {
+	now := time.Now().UTC()
+	m, err := r.get(tx, params.Client)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		m = &model.ClientCompatRule{
+			Client:       params.Client,
+			CreatedAtUTC: now,
+			CreatedBy:    params.ModifiedBy,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	m.MinVersion = params.MinVersion
+	m.DeprecatedBelow = params.DeprecatedBelow
+	m.LatestVersion = params.LatestVersion
+	m.ChangelogURL = params.ChangelogURL
+	m.Message = params.Message
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = params.ModifiedBy
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *ClientCompat) Delete(tx *gorm.DB, params *entity.DeleteClientCompatRuleParams) error {
+	m, err := r.get(tx, params.Client)
+	if err != nil {
+		return err
+	}
+	return tx.Delete(m).Error
+}
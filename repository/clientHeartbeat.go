@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// clientHeartbeatListLimit limits the number of connected clients listed.
+const clientHeartbeatListLimit = 5000
+
+// ClientHeartbeat stores the heartbeats of the desktop tools and DCC plugins, the latest one
+// per credential and host, and their count per day for the adoption reports.
+type ClientHeartbeat struct {
+	db *gorm.DB
+}
+
+func NewClientHeartbeat(db *gorm.DB) (*ClientHeartbeat, error) {
+	if err := db.AutoMigrate(&model.ClientHeartbeat{}, &model.ClientUsage{}); err != nil {
+		return nil, err
+	}
+	return &ClientHeartbeat{
+		db: db,
+	}, nil
+}
+
+func (r *ClientHeartbeat) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ClientHeartbeat) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// Record upserts the latest heartbeat of the client on the host and counts it in the usage of
+// the day, so that a heartbeat costs two statements whatever the number of its predecessors.
+func (r *ClientHeartbeat) Record(
+	tx *gorm.DB,
+	params *entity.RecordClientHeartbeatParams,
+) error {
+	now := time.Now().UTC()
+	if err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"version":          params.Version,
+			"beats":            gorm.Expr("`beats` + 1"),
+			"last_seen_at_utc": now,
+		}),
+	}).Create(model.NewClientHeartbeat(params, now)).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"beats": gorm.Expr("`beats` + 1"),
+		}),
+	}).Create(&model.ClientUsage{
+		Day:     now.Truncate(24 * time.Hour),
+		Client:  params.Client,
+		Version: params.Version,
+		Studio:  params.Studio,
+		Host:    params.Host,
+		Beats:   1,
+	}).Error
+}
+
+// ListConnected returns the latest heartbeats sent since the given time, the latest first.
+func (r *ClientHeartbeat) ListConnected(
+	db *gorm.DB,
+	params *entity.ListConnectedClientsParams,
+	since time.Time,
+) ([]*entity.ClientHeartbeat, error) {
+	stmt := db.Where("`last_seen_at_utc` >= ?", since)
+	if params.Studio != nil {
+		stmt = stmt.Where("`studio` = ?", *params.Studio)
+	}
+	if params.Client != nil {
+		stmt = stmt.Where("`client` = ?", *params.Client)
+	}
+	var models []*model.ClientHeartbeat
+	if err := stmt.Order(
+		"`last_seen_at_utc` desc",
+	).Limit(clientHeartbeatListLimit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ClientHeartbeat, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// Adoption counts the hosts and studios which ran each version of the clients per day from
+// one day to another, both included.
+func (r *ClientHeartbeat) Adoption(
+	db *gorm.DB,
+	params *entity.ListClientAdoptionParams,
+	from, to time.Time,
+) ([]*entity.ClientAdoption, error) {
+	stmt := db.Model(&model.ClientUsage{}).Select(
+		"DATE_FORMAT(`day`, '%Y-%m-%d') AS `date`, `client`, `version`, "+
+			"COUNT(DISTINCT `host`) AS `hosts`, COUNT(DISTINCT `studio`) AS `studios`, "+
+			"SUM(`beats`) AS `beats`",
+	).Where(
+		"`day` BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if params.Studio != nil {
+		stmt = stmt.Where("`studio` = ?", *params.Studio)
+	}
+	if params.Client != nil {
+		stmt = stmt.Where("`client` = ?", *params.Client)
+	}
+	var entities []*entity.ClientAdoption
+	if err := stmt.Group(
+		"`day`, `client`, `version`",
+	).Order(
+		"`day` asc, `client` asc, `version` asc",
+	).Scan(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
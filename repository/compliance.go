@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// Compliance reports the data shared with the external studios of a project: the publishes
+// propagated to them, the directory deletions synced to them and the comment attachments
+// they downloaded.
+type Compliance struct {
+	db       *gorm.DB
+	ps       *ProjectStudioMap
+	internal map[string]bool
+}
+
+// NewCompliance returns the compliance repository. The studios of the company, which are not
+// reported, are set by PPI_INTERNAL_STUDIOS as a comma separated list.
+func NewCompliance(db *gorm.DB, ps *ProjectStudioMap) (*Compliance, error) {
+	if err := db.AutoMigrate(&model.TransferAuditLog{}); err != nil {
+		return nil, err
+	}
+	studios := os.Getenv("PPI_INTERNAL_STUDIOS")
+	if studios == "" {
+		studios = "ppi,ppidev"
+	}
+	internal := map[string]bool{}
+	for _, s := range strings.Split(studios, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			internal[s] = true
+		}
+	}
+	return &Compliance{
+		db:       db,
+		ps:       ps,
+		internal: internal,
+	}, nil
+}
+
+func (r *Compliance) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// ExternalStudios returns the studios of the project which are not internal, sorted.
+func (r *Compliance) ExternalStudios(db *gorm.DB, project string) ([]string, error) {
+	mappings, _, err := r.ps.List(db, &entity.ListProjectStudioMapParams{
+		Project: &project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var studios []string
+	for _, m := range mappings {
+		if !r.internal[m.Studio] {
+			studios = append(studios, m.Studio)
+		}
+	}
+	sort.Strings(studios)
+	return studios, nil
+}
+
+// RecordTransfer records a transfer to a studio in the audit log. Transfers to internal
+// studios are not recorded.
+func (r *Compliance) RecordTransfer(tx *gorm.DB, params *entity.RecordTransferParams) error {
+	if r.internal[params.Studio] {
+		return nil
+	}
+	return tx.Create(model.NewTransferAuditLog(params)).Error
+}
+
+// ListTransfers returns the transfers to the given studios over the period of the report,
+// oldest first. Directory deletions are synced to every studio of the project, so each one is
+// reported once per studio.
+func (r *Compliance) ListTransfers(
+	db *gorm.DB,
+	params *entity.GetComplianceReportParams,
+	studios []string,
+) ([]*entity.Transfer, error) {
+	if len(studios) == 0 {
+		return []*entity.Transfer{}, nil
+	}
+	to := params.To.AddDate(0, 0, 1)
+
+	var publishes []*entity.Transfer
+	if err := db.Table("t_publish_propagation AS p").Select(
+		"? AS kind, p.studio, COALESCE(p.arrived_at_utc, p.modified_at_utc) AS occurred_at_utc, "+
+			"COALESCE(t.`user`, t.created_by, '') AS actor, t.revision_path AS target, "+
+			"t.studio AS detail",
+		entity.TransferPublish,
+	).Joins(
+		"INNER JOIN t_publish_transaction_info AS t "+
+			"ON t.project = p.project AND t.log_id = p.log_id",
+	).Where(
+		"t.deleted = ?", 0,
+	).Where(
+		"t.operation = ?", "publish",
+	).Where(
+		"t.event = ?", "completed",
+	).Where(
+		"p.project = ?", params.Project,
+	).Where(
+		"p.studio IN ?", studios,
+	).Where(
+		"p.status = ?", entity.PropagationArrived,
+	).Where(
+		"COALESCE(p.arrived_at_utc, p.modified_at_utc) >= ?", params.From,
+	).Where(
+		"COALESCE(p.arrived_at_utc, p.modified_at_utc) < ?", to,
+	).Scan(&publishes).Error; err != nil {
+		return nil, fmt.Errorf("ListTransfers: %w", err)
+	}
+
+	var deletions []*entity.Transfer
+	if err := db.Table("t_directory").Select(
+		"? AS kind, modified_at_utc AS occurred_at_utc, "+
+			"COALESCE(modified_by, '') AS actor, path AS target, status AS detail",
+		entity.TransferDirectoryDeletion,
+	).Where(
+		"project = ?", params.Project,
+	).Where(
+		"deleted <> ?", 0,
+	).Where(
+		"modified_at_utc >= ?", params.From,
+	).Where(
+		"modified_at_utc < ?", to,
+	).Scan(&deletions).Error; err != nil {
+		return nil, fmt.Errorf("ListTransfers: %w", err)
+	}
+
+	var downloads []*entity.Transfer
+	if err := db.Model(&model.TransferAuditLog{}).Select(
+		"kind, studio, created_at_utc AS occurred_at_utc, created_by AS actor, target, detail",
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`studio` IN ?", studios,
+	).Where(
+		"`created_at_utc` >= ?", params.From,
+	).Where(
+		"`created_at_utc` < ?", to,
+	).Scan(&downloads).Error; err != nil {
+		return nil, fmt.Errorf("ListTransfers: %w", err)
+	}
+
+	transfers := append(publishes, downloads...)
+	for _, d := range deletions {
+		for _, s := range studios {
+			t := *d
+			t.Studio = s
+			transfers = append(transfers, &t)
+		}
+	}
+	sort.SliceStable(transfers, func(i, j int) bool {
+		if !transfers[i].OccurredAtUTC.Equal(transfers[j].OccurredAtUTC) {
+			return transfers[i].OccurredAtUTC.Before(transfers[j].OccurredAtUTC)
+		}
+		if transfers[i].Studio != transfers[j].Studio {
+			return transfers[i].Studio < transfers[j].Studio
+		}
+		return transfers[i].Kind < transfers[j].Kind
+	})
+	return transfers, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// Consistency provides read-after-write consistency when reads are served by replicas. Writes
+// return a token of the state of the primary, and reads carrying it in their context either
+// wait for the replica to apply it, or are routed to the primary.
+type Consistency struct {
+	primary     gorm.ConnPool
+	replicated  bool
+	waitTimeout time.Duration
+	window      time.Duration
+}
+
+// NewConsistency routes the reads of db according to the tokens of their context. replicated
+// tells whether read replicas are registered to db, otherwise every read already goes to the
+// primary and the tokens are ignored.
+func NewConsistency(db *gorm.DB, replicated bool) (*Consistency, error) {
+	waitTimeout, err := durationFromEnv("PPI_REPLICA_WAIT_TIMEOUT", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	window, err := durationFromEnv("PPI_CONSISTENCY_WINDOW", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	r := &Consistency{
+		primary:     db.ConnPool,
+		replicated:  replicated,
+		waitTimeout: waitTimeout,
+		window:      window,
+	}
+	if !replicated {
+		return r, nil
+	}
+	// runs once the resolver has picked the connection pool of the statement
+	if err := db.Callback().Query().After("gorm:db_resolver").Before("gorm:query").Register(
+		"ppi:consistency", r.route,
+	); err != nil {
+		return nil, err
+	}
+	if err := db.Callback().Row().After("gorm:db_resolver").Before("gorm:row").Register(
+		"ppi:consistency", r.route,
+	); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Token returns the token of the writes committed so far on the primary.
+func (r *Consistency) Token(ctx context.Context) *entity.ConsistencyToken {
+	token := &entity.ConsistencyToken{WrittenAt: time.Now().UTC()}
+	if !r.replicated {
+		return token
+	}
+	var gtidSet string
+	if err := r.primary.QueryRowContext(
+		ctx, "SELECT @@GLOBAL.gtid_executed",
+	).Scan(&gtidSet); err == nil {
+		token.GTIDSet = gtidSet
+	}
+	return token
+}
+
+func (r *Consistency) route(db *gorm.DB) {
+	token, ok := db.Statement.Context.Value(entity.KeyConsistencyToken).(*entity.ConsistencyToken)
+	if !ok || db.Statement.ConnPool == r.primary {
+		return
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		// transactions run on the primary
+		return
+	}
+	if token.GTIDSet != "" {
+		if r.waitForReplica(db.Statement.Context, db.Statement.ConnPool, token.GTIDSet) {
+			return
+		}
+	} else if time.Since(token.WrittenAt) > r.window {
+		return
+	}
+	db.Statement.ConnPool = r.primary
+}
+
+// waitForReplica waits until the replica has applied the GTID set, and reports whether it did
+// within the timeout.
+func (r *Consistency) waitForReplica(
+	ctx context.Context,
+	replica gorm.ConnPool,
+	gtidSet string,
+) bool {
+	var timedOut int
+	if err := replica.QueryRowContext(
+		ctx, "SELECT WAIT_FOR_EXECUTED_GTID_SET(?, ?)", gtidSet, r.waitTimeout.Seconds(),
+	).Scan(&timedOut); err != nil {
+		return false
+	}
+	return timedOut == 0
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// contactSheetGap is the space between the cells of a contact sheet, in pixels.
+const contactSheetGap = 8
+
+var contactSheetBackground = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+
+// ContactSheet composes the before/after contact sheets of takes from their stills under
+// PPI_CONTACT_SHEET_PROJECTS_ROOT, and caches them as PNG files under
+// PPI_CONTACT_SHEET_CACHE_DIR.
+type ContactSheet struct {
+	root     string
+	cacheDir string
+}
+
+func NewContactSheet() (*ContactSheet, error) {
+	cs := &ContactSheet{
+		root:     os.Getenv("PPI_CONTACT_SHEET_PROJECTS_ROOT"),
+		cacheDir: os.Getenv("PPI_CONTACT_SHEET_CACHE_DIR"),
+	}
+	if cs.root == "" {
+		cs.root = "/mnt/ppip30-data01/datasync30/projects"
+	}
+	if cs.cacheDir == "" {
+		cs.cacheDir = filepath.Join(os.TempDir(), "contactsheets")
+	}
+	if err := os.MkdirAll(cs.cacheDir, 0o775); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// takeHash identifies the content of a take by its stills.
+func (cs *ContactSheet) takeHash(project string, take *entity.ContactSheetTake) (string, error) {
+	h := sha256.New()
+	for _, still := range take.Stills {
+		f, err := os.Stat(filepath.Join(cs.root, project, filepath.FromSlash(still)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", still, f.Size(), f.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get returns the contact sheet of the takes, composing it unless it is cached already.
+func (cs *ContactSheet) Get(
+	params *entity.GetContactSheetParams,
+	before *entity.ContactSheetTake,
+	after *entity.ContactSheetTake,
+) (*entity.ContactSheet, error) {
+	beforeHash, err := cs.takeHash(params.Project, before)
+	if err != nil {
+		return nil, err
+	}
+	afterHash, err := cs.takeHash(params.Project, after)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf(
+		"%s:%s:%d:%d", beforeHash, afterHash, params.Frames, params.Width,
+	)))
+	sheet := &entity.ContactSheet{
+		Key: hex.EncodeToString(sum[:]),
+	}
+	sheet.Path = filepath.Join(cs.cacheDir, sheet.Key+".png")
+	if f, err := os.Stat(sheet.Path); err == nil && f.Mode().IsRegular() {
+		return sheet, nil
+	}
+
+	img, err := cs.compose(params, before, after)
+	if err != nil {
+		return nil, err
+	}
+	// written aside first so that concurrent requests never serve an incomplete sheet
+	tmp, err := os.CreateTemp(cs.cacheDir, sheet.Key+"-*.part")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if err := png.Encode(tmp, img); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), sheet.Path); err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+func (cs *ContactSheet) decode(project, still string) (image.Image, error) {
+	f, err := os.Open(filepath.Join(cs.root, project, filepath.FromSlash(still)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode %q: %s", entity.ErrBadRequest, still, err)
+	}
+	return img, nil
+}
+
+// compose lays out the frames of each take on a row. The cells have the aspect ratio of the
+// first frame of the earlier take, and the frames are fitted into them.
+func (cs *ContactSheet) compose(
+	params *entity.GetContactSheetParams,
+	before *entity.ContactSheetTake,
+	after *entity.ContactSheetTake,
+) (image.Image, error) {
+	rows := make([][]image.Image, 2)
+	for i, take := range []*entity.ContactSheetTake{before, after} {
+		for _, frame := range take.Frames {
+			img, err := cs.decode(params.Project, frame)
+			if err != nil {
+				return nil, err
+			}
+			rows[i] = append(rows[i], img)
+		}
+	}
+
+	first := rows[0][0].Bounds()
+	cellWidth := params.Width
+	cellHeight := cellWidth * first.Dy() / first.Dx()
+	if cellHeight < 1 {
+		cellHeight = 1
+	}
+	columns := max(len(rows[0]), len(rows[1]))
+	sheet := image.NewRGBA(image.Rect(
+		0,
+		0,
+		columns*cellWidth+(columns+1)*contactSheetGap,
+		len(rows)*cellHeight+(len(rows)+1)*contactSheetGap,
+	))
+	draw.Draw(
+		sheet, sheet.Bounds(), &image.Uniform{C: contactSheetBackground}, image.Point{}, draw.Src,
+	)
+	for r, row := range rows {
+		for c, img := range row {
+			x := contactSheetGap + c*(cellWidth+contactSheetGap)
+			y := contactSheetGap + r*(cellHeight+contactSheetGap)
+			drawFitted(sheet, image.Rect(x, y, x+cellWidth, y+cellHeight), img)
+		}
+	}
+	return sheet, nil
+}
+
+// drawFitted scales src into the center of cell, keeping its aspect ratio. Nearest neighbor
+// sampling is enough for review stills.
+func drawFitted(dst *image.RGBA, cell image.Rectangle, src image.Image) {
+	sb := src.Bounds()
+	if sb.Empty() {
+		return
+	}
+	w, h := cell.Dx(), sb.Dy()*cell.Dx()/sb.Dx()
+	if h > cell.Dy() {
+		w, h = sb.Dx()*cell.Dy()/sb.Dy(), cell.Dy()
+	}
+	if w < 1 || h < 1 {
+		return
+	}
+	offset := cell.Min.Add(image.Pt((cell.Dx()-w)/2, (cell.Dy()-h)/2))
+	for y := 0; y < h; y++ {
+		sy := sb.Min.Y + y*sb.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := sb.Min.X + x*sb.Dx()/w
+			dst.Set(offset.X+x, offset.Y+y, src.At(sx, sy))
+		}
+	}
+}
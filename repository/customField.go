@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+type CustomField struct {
+	db *gorm.DB
+}
+
+func NewCustomField(db *gorm.DB) (*CustomField, error) {
+	if err := db.AutoMigrate(
+		&model.CustomFieldDefinition{}, &model.AssetMetadata{},
+	); err != nil {
+		return nil, err
+	}
+	return &CustomField{
+		db: db,
+	}, nil
+}
+
+func (r *CustomField) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *CustomField) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *CustomField) List(
+	db *gorm.DB,
+	params *entity.ListCustomFieldDefinitionsParams,
+) ([]*entity.CustomFieldDefinition, error) {
+	stmt := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	)
+	if params.Target != nil {
+		stmt = stmt.Where("`target` = ?", *params.Target)
+	}
+	var models []*model.CustomFieldDefinition
+	if err := stmt.Order("`target` asc").Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.CustomFieldDefinition, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// ListDefinitions returns the field definitions of the target used to validate values.
+func (r *CustomField) ListDefinitions(
+	db *gorm.DB,
+	project string,
+	target string,
+) ([]*entity.CustomFieldDefinition, error) {
+	return r.List(db, &entity.ListCustomFieldDefinitionsParams{
+		Project: project,
+		Target:  &target,
+	})
+}
+
+func (r *CustomField) get(
+	db *gorm.DB,
+	project string,
+	id int32,
+) (*model.CustomFieldDefinition, error) {
+	var m model.CustomFieldDefinition
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: custom field with ID %d not found", entity.ErrRecordNotFound, id,
+			)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *CustomField) Create(
+	tx *gorm.DB,
+	params *entity.CreateCustomFieldDefinitionParams,
+) (*entity.CustomFieldDefinition, error) {
+	m := model.NewCustomFieldDefinition(params)
+	if err := tx.Create(m).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, fmt.Errorf(
+				"%w: custom field with key %q is already exists",
+				entity.ErrBadRequest, params.Key,
+			)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *CustomField) Update(
+	tx *gorm.DB,
+	params *entity.UpdateCustomFieldDefinitionParams,
+) (*entity.CustomFieldDefinition, error) {
+	m, err := r.get(tx, params.Project, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if params.DisplayName != nil {
+		m.DisplayName = *params.DisplayName
+	}
+	if params.EnumValues != nil {
+		if m.Type != entity.CustomFieldEnum {
+			return nil, fmt.Errorf(
+				"%w: custom field %q is not an enum", entity.ErrBadRequest, m.Key,
+			)
+		}
+		m.EnumValues = params.EnumValues
+	}
+	if params.Required != nil {
+		m.Required = *params.Required
+	}
+	m.ModifiedAtUTC = time.Now().UTC()
+	if params.ModifiedBy != nil {
+		m.ModifiedBy = *params.ModifiedBy
+	}
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *CustomField) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteCustomFieldDefinitionParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.CustomFieldDefinition
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: custom field with ID %d not found", entity.ErrRecordNotFound, params.ID,
+		)
+	}
+	return nil
+}
+
+// GetAssetMetadata returns the asset's values, which are empty when none have been set yet.
+func (r *CustomField) GetAssetMetadata(
+	db *gorm.DB,
+	params *entity.GetAssetMetadataParams,
+) (*entity.AssetMetadata, error) {
+	var m model.AssetMetadata
+	err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`asset` = ?", params.Asset,
+	).Where(
+		"`relation` = ?", params.Relation,
+	).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &entity.AssetMetadata{
+			Project:  params.Project,
+			Asset:    params.Asset,
+			Relation: params.Relation,
+			Metadata: entity.JSONObject{},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// UpdateAssetMetadata stores the already validated values of the asset.
+func (r *CustomField) UpdateAssetMetadata(
+	tx *gorm.DB,
+	project string,
+	asset string,
+	relation string,
+	metadata entity.JSONObject,
+	modifiedBy string,
+) (*entity.AssetMetadata, error) {
+	now := time.Now().UTC()
+	var m model.AssetMetadata
+	err := tx.Where(
+		"`project` = ?", project,
+	).Where(
+		"`asset` = ?", asset,
+	).Where(
+		"`relation` = ?", relation,
+	).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.AssetMetadata{
+			Project:       project,
+			Asset:         asset,
+			Relation:      relation,
+			Metadata:      model.GormJSONObject(metadata),
+			CreatedAtUTC:  now,
+			ModifiedAtUTC: now,
+			ModifiedBy:    modifiedBy,
+			CreatedBy:     modifiedBy,
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return nil, err
+		}
+		return m.Entity(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.Metadata = model.GormJSONObject(metadata)
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	if err := tx.Save(&m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// ListAssetMetadata returns the values of every asset of the project keyed by
+// "<asset>/<relation>".
+func (r *CustomField) ListAssetMetadata(
+	db *gorm.DB,
+	project string,
+) (map[string]entity.JSONObject, error) {
+	var models []*model.AssetMetadata
+	if err := db.Where("`project` = ?", project).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]entity.JSONObject, len(models))
+	for _, m := range models {
+		metadata[AssetMetadataKey(m.Asset, m.Relation)] = entity.JSONObject(m.Metadata)
+	}
+	return metadata, nil
+}
+
+func AssetMetadataKey(asset, relation string) string {
+	return asset + "/" + relation
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSNEnv is the DSN of the MySQL database the database tests run against, one migrated by
+// the server. The tests are skipped when it is not set.
+const testDSNEnv = "PPI_TEST_MYSQL_DSN"
+
+// openTestDB opens the test database, skipping tb when there is none.
+func openTestDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		tb.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		tb.Fatalf("open %s: %v", testDSNEnv, err)
+	}
+	return db
+}
+
+// testProject returns the name of a project of its own for tb, whose seeded data are purged
+// when tb finishes.
+func testProject(tb testing.TB, db *gorm.DB) string {
+	tb.Helper()
+	project := fmt.Sprintf("test%d", time.Now().UnixNano()%1e12)
+	tb.Cleanup(func() {
+		if err := NewSeed(db).Purge(db, []string{project}); err != nil {
+			tb.Errorf("purge %s: %v", project, err)
+		}
+	})
+	return project
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// Delegation stores the delegations of approvals of supervisors on leave.
+type Delegation struct {
+	db *gorm.DB
+}
+
+func NewDelegation(db *gorm.DB) (*Delegation, error) {
+	if err := db.AutoMigrate(&model.Delegation{}); err != nil {
+		return nil, err
+	}
+	return &Delegation{
+		db: db,
+	}, nil
+}
+
+func (r *Delegation) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Delegation) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *Delegation) take(db *gorm.DB, id int32) (*model.Delegation, error) {
+	var m model.Delegation
+	if err := db.Where("`id` = ?", id).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: delegation with ID %d", entity.ErrRecordNotFound, id)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// List returns the delegations, latest first. The active delegations which have ended are
+// listed as expired.
+func (r *Delegation) List(
+	db *gorm.DB,
+	params *entity.ListDelegationsParams,
+) ([]*entity.Delegation, uint, error) {
+	stmt := db.Model(&model.Delegation{})
+	if params.Delegator != nil {
+		stmt = stmt.Where("`delegator` = ?", *params.Delegator)
+	}
+	if params.Delegate != nil {
+		stmt = stmt.Where("`delegate` = ?", *params.Delegate)
+	}
+	if params.Project != nil {
+		stmt = stmt.Where("(`project` IS NULL OR `project` = ?)", *params.Project)
+	}
+	if params.Status != nil {
+		now := time.Now().UTC()
+		switch *params.Status {
+		case entity.DelegationActive:
+			stmt = stmt.Where(
+				"`status` = ?", string(entity.DelegationActive),
+			).Where(
+				"`ends_at_utc` > ?", now,
+			)
+		case entity.DelegationExpired:
+			stmt = stmt.Where(
+				"(`status` = ? OR (`status` = ? AND `ends_at_utc` <= ?))",
+				string(entity.DelegationExpired), string(entity.DelegationActive), now,
+			)
+		default:
+			stmt = stmt.Where("`status` = ?", string(*params.Status))
+		}
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.Delegation
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.Delegation, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, uint(total), nil
+}
+
+func (r *Delegation) Get(
+	db *gorm.DB,
+	params *entity.GetDelegationParams,
+) (*entity.Delegation, error) {
+	m, err := r.take(db, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *Delegation) Create(
+	tx *gorm.DB,
+	params *entity.CreateDelegationParams,
+) (*entity.Delegation, error) {
+	m := model.NewDelegation(params)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Revoke ends an active delegation before its window does. A delegation which has ended
+// already cannot be revoked.
+func (r *Delegation) Revoke(
+	tx *gorm.DB,
+	params *entity.RevokeDelegationParams,
+) (*entity.Delegation, error) {
+	now := time.Now().UTC()
+	result := tx.Model(&model.Delegation{}).Where(
+		"`id` = ?", params.ID,
+	).Where(
+		"`status` = ?", string(entity.DelegationActive),
+	).Where(
+		"`ends_at_utc` > ?", now,
+	).Updates(map[string]interface{}{
+		"status":         string(entity.DelegationRevoked),
+		"revoked_at_utc": now,
+		"revoked_by":     params.RevokedBy,
+	})
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+	m, err := r.take(tx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf(
+			"%w: delegation with ID %d is %s", entity.ErrBadRequest, params.ID, e.Status,
+		)
+	}
+	return e, nil
+}
+
+// FindActive returns the delegation letting the delegate act on behalf of the delegator in
+// the project at the time, or nil. The latest one is returned when several apply.
+func (r *Delegation) FindActive(
+	db *gorm.DB,
+	project, delegator, delegate string,
+	at time.Time,
+) (*entity.Delegation, error) {
+	var m model.Delegation
+	if err := db.Where(
+		"`delegator` = ?", delegator,
+	).Where(
+		"`delegate` = ?", delegate,
+	).Where(
+		"(`project` IS NULL OR `project` = ?)", project,
+	).Where(
+		"`status` = ?", string(entity.DelegationActive),
+	).Where(
+		"`starts_at_utc` <= ?", at,
+	).Where(
+		"`ends_at_utc` > ?", at,
+	).Order("`id` desc").Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// ExpireEnded marks the active delegations whose window has ended as expired, and returns
+// their number.
+func (r *Delegation) ExpireEnded(tx *gorm.DB, now time.Time) (int64, error) {
+	result := tx.Model(&model.Delegation{}).Where(
+		"`status` = ?", string(entity.DelegationActive),
+	).Where(
+		"`ends_at_utc` <= ?", now,
+	).Update("status", string(entity.DelegationExpired))
+	return result.RowsAffected, result.Error
+}
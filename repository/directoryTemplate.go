@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+type DirectoryTemplate struct {
+	db *gorm.DB
+}
+
+func NewDirectoryTemplate(db *gorm.DB) (*DirectoryTemplate, error) {
+	if err := db.AutoMigrate(&model.DirectoryTemplate{}); err != nil {
+		return nil, err
+	}
+	return &DirectoryTemplate{
+		db: db,
+	}, nil
+}
+
+func (r *DirectoryTemplate) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *DirectoryTemplate) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// List returns the templates of the project. When ids are given, only these templates are
+// returned, and all of them must exist.
+func (r *DirectoryTemplate) List(
+	db *gorm.DB,
+	project string,
+	ids []int32,
+) ([]*entity.DirectoryTemplate, error) {
+	stmt := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	)
+	if len(ids) > 0 {
+		stmt = stmt.Where("`id` IN ?", ids)
+	}
+	var models []*model.DirectoryTemplate
+	if err := stmt.Order("`name` asc").Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	found := make(map[int32]bool, len(models))
+	entities := make([]*entity.DirectoryTemplate, len(models))
+	for i, m := range models {
+		found[m.ID] = true
+		entities[i] = m.Entity()
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf(
+				"%w: directory template with ID %d not found", entity.ErrRecordNotFound, id,
+			)
+		}
+	}
+	return entities, nil
+}
+
+func (r *DirectoryTemplate) get(
+	db *gorm.DB,
+	project string,
+	id int32,
+) (*model.DirectoryTemplate, error) {
+	var m model.DirectoryTemplate
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: directory template with ID %d not found", entity.ErrRecordNotFound, id,
+			)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *DirectoryTemplate) Create(
+	tx *gorm.DB,
+	params *entity.CreateDirectoryTemplateParams,
+) (*entity.DirectoryTemplate, error) {
+	m := model.NewDirectoryTemplate(params)
+	if err := tx.Create(m).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, fmt.Errorf(
+				"%w: directory template %q is already exists", entity.ErrBadRequest, params.Name,
+			)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Copy copies the templates of a project to another project and returns their number.
+func (r *DirectoryTemplate) Copy(
+	tx *gorm.DB,
+	from string,
+	to string,
+	createdBy string,
+) (int, error) {
+	var models []*model.DirectoryTemplate
+	if err := tx.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", from,
+	).Order("`id` asc").Find(&models).Error; err != nil {
+		return 0, err
+	}
+	for _, m := range models {
+		if _, err := r.Create(tx, &entity.CreateDirectoryTemplateParams{
+			Project:   to,
+			Name:      m.Name,
+			Patterns:  m.Patterns,
+			CreatedBy: &createdBy,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(models), nil
+}
+
+func (r *DirectoryTemplate) Update(
+	tx *gorm.DB,
+	params *entity.UpdateDirectoryTemplateParams,
+) (*entity.DirectoryTemplate, error) {
+	m, err := r.get(tx, params.Project, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if params.Name != nil {
+		m.Name = *params.Name
+	}
+	if params.Patterns != nil {
+		m.Patterns = params.Patterns
+	}
+	m.ModifiedAtUTC = time.Now().UTC()
+	if params.ModifiedBy != nil {
+		m.ModifiedBy = *params.ModifiedBy
+	}
+	if err := tx.Save(m).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, fmt.Errorf(
+				"%w: directory template %q is already exists", entity.ErrBadRequest, m.Name,
+			)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *DirectoryTemplate) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteDirectoryTemplateParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.DirectoryTemplate
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: directory template with ID %d not found", entity.ErrRecordNotFound, params.ID,
+		)
+	}
+	return nil
+}
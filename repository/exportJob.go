@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// exportJobListLimit limits the number of export jobs listed per project.
+	exportJobListLimit = 100
+	// defaultExportWorkers is the number of export workers per instance by default.
+	defaultExportWorkers = 2
+)
+
+// ExportJob stores the export jobs and their documents. The documents are files of the
+// directory PPI_EXPORT_DIR, a temporary directory by default, which must be shared by the
+// instances of the API for them to be downloaded from any instance. PPI_EXPORT_WORKERS sets
+// the number of jobs an instance runs at once.
+type ExportJob struct {
+	db      *gorm.DB
+	dir     string
+	workers int
+}
+
+func NewExportJob(db *gorm.DB) (*ExportJob, error) {
+	if err := db.AutoMigrate(&model.ExportJob{}); err != nil {
+		return nil, err
+	}
+	dir := os.Getenv("PPI_EXPORT_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "ppi-exports")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	workers := defaultExportWorkers
+	if v := os.Getenv("PPI_EXPORT_WORKERS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid PPI_EXPORT_WORKERS: %q", v)
+		}
+		workers = n
+	}
+	return &ExportJob{
+		db:      db,
+		dir:     dir,
+		workers: workers,
+	}, nil
+}
+
+func (r *ExportJob) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ExportJob) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *ExportJob) Workers() int {
+	return r.workers
+}
+
+// List returns the latest export jobs of a project.
+func (r *ExportJob) List(
+	db *gorm.DB,
+	params *entity.ListExportJobsParams,
+) ([]*entity.ExportJob, error) {
+	var models []*model.ExportJob
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Order("`id` desc").Limit(exportJobListLimit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ExportJob, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *ExportJob) Get(
+	db *gorm.DB,
+	params *entity.GetExportJobParams,
+) (*entity.ExportJob, error) {
+	var m model.ExportJob
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: export job with ID %d", entity.ErrRecordNotFound, params.ID)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *ExportJob) Create(
+	tx *gorm.DB,
+	params *entity.CreateExportJobParams,
+	query []byte,
+) (*entity.ExportJob, error) {
+	m := model.NewExportJob(params, query)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Claim starts the oldest queued export job and returns it, or nil when no job is queued.
+// A job claimed by another worker meanwhile is skipped.
+func (r *ExportJob) Claim(db *gorm.DB) (*entity.ExportJob, error) {
+	for {
+		var m model.ExportJob
+		if err := db.Where(
+			"`status` = ?", string(entity.ExportQueued),
+		).Order("`id` asc").Take(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		now := time.Now().UTC()
+		result := db.Model(&model.ExportJob{}).Where(
+			"`id` = ?", m.ID,
+		).Where(
+			"`status` = ?", string(entity.ExportQueued),
+		).Updates(map[string]interface{}{
+			"status":          string(entity.ExportRunning),
+			"started_at_utc":  now,
+			"modified_at_utc": now,
+		})
+		if err := result.Error; err != nil {
+			return nil, err
+		}
+		if result.RowsAffected != 0 {
+			m.Status = string(entity.ExportRunning)
+			m.StartedAtUTC = &now
+			m.ModifiedAtUTC = now
+			return m.Entity(), nil
+		}
+	}
+}
+
+// Complete records the document of a running export job, kept until expiresAt.
+func (r *ExportJob) Complete(db *gorm.DB, id int32, size int64, expiresAt time.Time) error {
+	now := time.Now().UTC()
+	return db.Model(&model.ExportJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ExportRunning),
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ExportCompleted),
+		"size":             size,
+		"completed_at_utc": now,
+		"expires_at_utc":   expiresAt,
+		"modified_at_utc":  now,
+	}).Error
+}
+
+// Fail fails a running export job.
+func (r *ExportJob) Fail(db *gorm.DB, id int32, errMessage string) error {
+	now := time.Now().UTC()
+	return db.Model(&model.ExportJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ExportRunning),
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ExportFailed),
+		"error":            errMessage,
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	}).Error
+}
+
+// FailStale fails the export jobs started before the given time, whose worker was stopped
+// before finishing them.
+func (r *ExportJob) FailStale(db *gorm.DB, before time.Time) (int64, error) {
+	now := time.Now().UTC()
+	result := db.Model(&model.ExportJob{}).Where(
+		"`status` = ?", string(entity.ExportRunning),
+	).Where(
+		"`started_at_utc` < ?", before,
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ExportFailed),
+		"error":            "the export was interrupted",
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// Expire removes the documents of the completed export jobs which expired before now.
+func (r *ExportJob) Expire(db *gorm.DB, now time.Time) (int, error) {
+	var models []*model.ExportJob
+	if err := db.Where(
+		"`status` = ?", string(entity.ExportCompleted),
+	).Where(
+		"`expires_at_utc` < ?", now,
+	).Find(&models).Error; err != nil {
+		return 0, err
+	}
+	for _, m := range models {
+		if err := os.Remove(r.path(m.ID, m.Format)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, err
+		}
+		if err := db.Model(&model.ExportJob{}).Where(
+			"`id` = ?", m.ID,
+		).Where(
+			"`status` = ?", string(entity.ExportCompleted),
+		).Updates(map[string]interface{}{
+			"status":          string(entity.ExportExpired),
+			"modified_at_utc": time.Now().UTC(),
+		}).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(models), nil
+}
+
+func (r *ExportJob) path(id int32, format string) string {
+	return filepath.Join(r.dir, fmt.Sprintf("%d.%s", id, format))
+}
+
+// Store writes the document of an export job with write and returns its size. The document
+// only replaces the previous one once fully written.
+func (r *ExportJob) Store(e *entity.ExportJob, write func(w io.Writer) error) (int64, error) {
+	f, err := os.CreateTemp(r.dir, fmt.Sprintf("%d-*.tmp", e.ID))
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	if err := write(f); err != nil {
+		f.Close()
+		return 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(f.Name(), r.path(e.ID, e.Format)); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Open opens the document of a completed export job.
+func (r *ExportJob) Open(e *entity.ExportJob) (*os.File, error) {
+	f, err := os.Open(r.path(e.ID, e.Format))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(
+			"%w: document of export job with ID %d", entity.ErrRecordNotFound, e.ID,
+		)
+	}
+	return f, err
+}
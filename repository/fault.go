@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// FaultInjector holds the faults simulated on the dependencies. The faults are kept in memory
+// and only affect the instance they were set on. A nil FaultInjector injects nothing.
+type FaultInjector struct {
+	mu     sync.Mutex
+	faults map[entity.Dependency]*entity.Fault
+}
+
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{
+		faults: map[entity.Dependency]*entity.Fault{},
+	}
+}
+
+func (r *FaultInjector) List() []*entity.Fault {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	faults := []*entity.Fault{}
+	for dep := range r.faults {
+		if f := r.get(dep); f != nil {
+			faults = append(faults, f)
+		}
+	}
+	sort.Slice(faults, func(i, j int) bool {
+		return faults[i].Dependency < faults[j].Dependency
+	})
+	return faults
+}
+
+func (r *FaultInjector) Set(params *entity.SetFaultParams) *entity.Fault {
+	now := time.Now().UTC()
+	f := &entity.Fault{
+		Dependency:   params.Dependency,
+		LatencyMs:    params.LatencyMs,
+		ErrorRate:    params.ErrorRate,
+		CreatedAtUTC: now,
+	}
+	if params.DurationSeconds != nil {
+		expiresAt := now.Add(time.Duration(*params.DurationSeconds) * time.Second)
+		f.ExpiresAtUTC = &expiresAt
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.faults[params.Dependency] = f
+	return f
+}
+
+func (r *FaultInjector) Clear(dep entity.Dependency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.get(dep) == nil {
+		return fmt.Errorf("%w: no fault on %s", entity.ErrRecordNotFound, dep)
+	}
+	delete(r.faults, dep)
+	return nil
+}
+
+// get returns the fault of the dependency, dropping it once expired. r.mu must be held.
+func (r *FaultInjector) get(dep entity.Dependency) *entity.Fault {
+	f, ok := r.faults[dep]
+	if !ok {
+		return nil
+	}
+	if f.ExpiresAtUTC != nil && time.Now().After(*f.ExpiresAtUTC) {
+		delete(r.faults, dep)
+		return nil
+	}
+	return f
+}
+
+// Inject applies the fault of the dependency, if any, to a call about to be made: it waits
+// for the latency and may return an error wrapping entity.ErrBadGateway, in which case the
+// call must not be made.
+func (r *FaultInjector) Inject(ctx context.Context, dep entity.Dependency) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	f := r.get(dep)
+	r.mu.Unlock()
+	if f == nil {
+		return nil
+	}
+	if f.LatencyMs > 0 {
+		select {
+		case <-time.After(time.Duration(f.LatencyMs) * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if rand.Float64() < f.ErrorRate {
+		return fmt.Errorf("%w: %s is unavailable (injected fault)", entity.ErrBadGateway, dep)
+	}
+	return nil
+}
+
+// FaultyDocumentRepository injects the faults of Mongo into a document repository.
+type FaultyDocumentRepository struct {
+	entity.DocumentRepository
+	faults *FaultInjector
+}
+
+func NewFaultyDocumentRepository(
+	repo entity.DocumentRepository,
+	faults *FaultInjector,
+) *FaultyDocumentRepository {
+	return &FaultyDocumentRepository{
+		DocumentRepository: repo,
+		faults:             faults,
+	}
+}
+
+func (r *FaultyDocumentRepository) CreateDocument(
+	ctx context.Context,
+	project string,
+	collection string,
+	params map[string]interface{},
+) (*entity.DocumentInfo, error) {
+	if err := r.faults.Inject(ctx, entity.DependencyMongo); err != nil {
+		return nil, err
+	}
+	return r.DocumentRepository.CreateDocument(ctx, project, collection, params)
+}
+
+func (r *FaultyDocumentRepository) GetDocumentByID(
+	ctx context.Context,
+	project string,
+	collection string,
+	id string,
+) (*entity.DocumentInfo, error) {
+	if err := r.faults.Inject(ctx, entity.DependencyMongo); err != nil {
+		return nil, err
+	}
+	return r.DocumentRepository.GetDocumentByID(ctx, project, collection, id)
+}
+
+func (r *FaultyDocumentRepository) GetDocumentsByFields(
+	ctx context.Context,
+	project string,
+	collection string,
+	param *entity.QueryDocumentsParam,
+) ([]*entity.DocumentInfo, int, error) {
+	if err := r.faults.Inject(ctx, entity.DependencyMongo); err != nil {
+		return nil, 0, err
+	}
+	return r.DocumentRepository.GetDocumentsByFields(ctx, project, collection, param)
+}
+
+func (r *FaultyDocumentRepository) GetDocumentsByOfficialRevisionFilters(
+	ctx context.Context,
+	project string,
+	collection string,
+	param *entity.QueryDocumentsParam,
+	fields *map[string]interface{},
+) ([]*entity.DocumentInfo, int, error) {
+	if err := r.faults.Inject(ctx, entity.DependencyMongo); err != nil {
+		return nil, 0, err
+	}
+	return r.DocumentRepository.GetDocumentsByOfficialRevisionFilters(
+		ctx, project, collection, param, fields,
+	)
+}
+
+func (r *FaultyDocumentRepository) UpdateDocument(
+	ctx context.Context,
+	project string,
+	collection string,
+	id string,
+	params map[string]interface{},
+) (*entity.DocumentInfo, error) {
+	if err := r.faults.Inject(ctx, entity.DependencyMongo); err != nil {
+		return nil, err
+	}
+	return r.DocumentRepository.UpdateDocument(ctx, project, collection, id, params)
+}
+
+func (r *FaultyDocumentRepository) DeleteDocument(
+	ctx context.Context,
+	project string,
+	collection string,
+	id string,
+) error {
+	if err := r.faults.Inject(ctx, entity.DependencyMongo); err != nil {
+		return err
+	}
+	return r.DocumentRepository.DeleteDocument(ctx, project, collection, id)
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// fileHashBatchSize limits the number of rows inserted per statement.
+const fileHashBatchSize = 500
+
+// fileHashLookupLimit limits the number of registrations returned per content hash.
+const fileHashLookupLimit = 1000
+
+type FileHash struct {
+	db *gorm.DB
+}
+
+func NewFileHash(db *gorm.DB) (*FileHash, error) {
+	if err := db.AutoMigrate(&model.FileHash{}); err != nil {
+		return nil, err
+	}
+	return &FileHash{
+		db: db,
+	}, nil
+}
+
+func (r *FileHash) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *FileHash) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// Register records the hashes of the files of a review. sizes holds the size of each file of
+// the AllFiles manifests of the review by path. Files registered again replace their hash.
+func (r *FileHash) Register(
+	tx *gorm.DB,
+	params *entity.RegisterFileHashesParams,
+	sizes map[string]uint64,
+) ([]*entity.FileHash, error) {
+	var existing []*model.FileHash
+	if err := tx.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`review_info_id` = ?", params.ReviewInfoID,
+	).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*model.FileHash, len(existing))
+	for _, m := range existing {
+		byPath[m.Path] = m
+	}
+
+	now := time.Now().UTC()
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	var created []*model.FileHash
+	var updated []*model.FileHash
+	for _, f := range params.Files {
+		if m, ok := byPath[f.Path]; ok {
+			if m.Hash == f.Hash {
+				continue
+			}
+			m.Hash = f.Hash
+			m.Size = sizes[f.Path]
+			updated = append(updated, m)
+			continue
+		}
+		m := &model.FileHash{
+			Project:      params.Project,
+			Hash:         f.Hash,
+			Path:         f.Path,
+			Size:         sizes[f.Path],
+			ReviewInfoID: params.ReviewInfoID,
+			CreatedAtUTC: now,
+			CreatedBy:    createdBy,
+		}
+		byPath[f.Path] = m
+		created = append(created, m)
+	}
+
+	if len(created) != 0 {
+		if err := tx.CreateInBatches(created, fileHashBatchSize).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range updated {
+		if err := tx.Model(m).Updates(map[string]interface{}{
+			"hash": m.Hash,
+			"size": m.Size,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	entities := make([]*entity.FileHash, 0, len(created)+len(updated))
+	for _, m := range append(created, updated...) {
+		entities = append(entities, m.Entity())
+	}
+	return entities, nil
+}
+
+// ListByHash returns where the content with the hash is registered, by path.
+func (r *FileHash) ListByHash(
+	db *gorm.DB,
+	params *entity.ListFilesByHashParams,
+) ([]*entity.FileHash, error) {
+	stmt := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`hash` = ?", params.Hash,
+	).Limit(fileHashLookupLimit)
+	var models []*model.FileHash
+	if err := stmt.Order("`path` asc").Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.FileHash, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// FindSources returns a registered file of each of the content hashes which is still kept,
+// by hash. Files of deleted reviews and of takes in approved or completed reclamations are
+// not kept. The earliest registration of a content is returned.
+func (r *FileHash) FindSources(
+	db *gorm.DB,
+	project string,
+	hashes []string,
+) (map[string]*entity.FileHash, error) {
+	reclaimed := db.Table("t_reclamation_take AS t").Select("1").Joins(
+		"INNER JOIN t_reclamation AS rc ON rc.id = t.reclamation_id",
+	).Where(
+		"t.review_info_id = h.review_info_id",
+	).Where(
+		"rc.status IN ?", []string{
+			string(entity.ReclamationApproved), string(entity.ReclamationCompleted),
+		},
+	)
+	sources := make(map[string]*entity.FileHash, len(hashes))
+	for start := 0; start < len(hashes); start += fileHashBatchSize {
+		end := min(start+fileHashBatchSize, len(hashes))
+		earliest := db.Table("t_file_hash AS h").Select("MIN(h.id)").Joins(
+			"INNER JOIN t_review_info AS ri ON ri.id = h.review_info_id",
+		).Where(
+			"h.project = ?", project,
+		).Where(
+			"h.hash IN ?", hashes[start:end],
+		).Where(
+			"ri.deleted = ?", 0,
+		).Where(
+			"NOT EXISTS (?)", reclaimed,
+		).Group("h.hash")
+		var models []*model.FileHash
+		if err := db.Where("`id` IN (?)", earliest).Find(&models).Error; err != nil {
+			return nil, err
+		}
+		for _, m := range models {
+			sources[m.Hash] = m.Entity()
+		}
+	}
+	return sources, nil
+}
+
+// DuplicateReport aggregates the registered files of the project by content. A path
+// registered by several reviews is a single copy.
+func (r *FileHash) DuplicateReport(
+	db *gorm.DB,
+	params *entity.GetDuplicateReportParams,
+) (*entity.DuplicateReport, error) {
+	contents := db.Model(&model.FileHash{}).Select(
+		"`hash`, MAX(`size`) AS `size`, COUNT(DISTINCT `path`) AS `copies`",
+	).Where(
+		"`project` = ?", params.Project,
+	).Group("`hash`")
+
+	var totals struct {
+		TotalFiles  int64
+		TotalSize   uint64
+		UniqueFiles int64
+		UniqueSize  uint64
+	}
+	if err := db.Table("(?) AS `c`", contents).Select(
+		"COALESCE(SUM(`copies`), 0) AS `total_files`, " +
+			"COALESCE(SUM(`copies` * `size`), 0) AS `total_size`, " +
+			"COUNT(*) AS `unique_files`, " +
+			"COALESCE(SUM(`size`), 0) AS `unique_size`",
+	).Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+
+	stmt := db.Table("(?) AS `c`", contents).Select(
+		"`hash`, `size`, `copies`, (`copies` - 1) * `size` AS `savings`",
+	).Where(
+		"`copies` > ?", 1,
+	).Limit(params.Limit)
+	var groups []*entity.DuplicateFileGroup
+	if err := stmt.Order("`savings` desc").Order("`hash` asc").Scan(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	return &entity.DuplicateReport{
+		TotalFiles:     totals.TotalFiles,
+		TotalSize:      totals.TotalSize,
+		UniqueFiles:    totals.UniqueFiles,
+		UniqueSize:     totals.UniqueSize,
+		DuplicateFiles: totals.TotalFiles - totals.UniqueFiles,
+		DuplicateSize:  totals.TotalSize - totals.UniqueSize,
+		Groups:         groups,
+	}, nil
+}
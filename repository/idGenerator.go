@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IDMode selects how the identifiers of newly created records are generated.
+//
+// Records always keep their auto-increment `id` primary key, which is still referenced by
+// soft deletion (`deleted` = `id`) and by other tables such as t_review_status_log. String IDs
+// are stored next to it in a nullable, unique `uid` column, so switching modes is done in
+// steps without downtime:
+//
+//  1. Deploy with PPI_ID_MODE=serial (default). The `uid` column is migrated but left empty.
+//  2. Switch to PPI_ID_MODE=ulid. New records get a ULID and existing ones are backfilled by
+//     BackfillUIDs. Both kinds of IDs are accepted by Get (dual read).
+//  3. Once every client addresses records by `uid`, the numeric ID can be hidden from the API.
+type IDMode string
+
+const (
+	IDModeSerial IDMode = "serial"
+	IDModeULID   IDMode = "ulid"
+)
+
+func ParseIDMode(s string) (IDMode, error) {
+	switch IDMode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", IDModeSerial:
+		return IDModeSerial, nil
+	case IDModeULID:
+		return IDModeULID, nil
+	}
+	return "", fmt.Errorf("unknown ID mode %q", s)
+}
+
+// IDGenerator generates string IDs for newly created records.
+type IDGenerator interface {
+	NewID() string
+}
+
+// NewIDGenerator returns the generator of the given mode, or nil when records only use their
+// auto-increment ID.
+func NewIDGenerator(mode IDMode) IDGenerator {
+	if mode == IDModeULID {
+		return &ulidGenerator{}
+	}
+	return nil
+}
+
+// crockford is the Crockford's base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator generates ULIDs: a 48-bit millisecond timestamp followed by 80 random bits,
+// encoded as 26 characters. IDs generated in the same millisecond are kept sortable by
+// incrementing the random part of the previous one.
+type ulidGenerator struct {
+	mu       sync.Mutex
+	lastMS   uint64
+	lastRand [10]byte
+}
+
+func (g *ulidGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(time.Now().UTC().UnixMilli())
+	if ms == g.lastMS {
+		for i := len(g.lastRand) - 1; i >= 0; i-- {
+			g.lastRand[i]++
+			if g.lastRand[i] != 0 {
+				break
+			}
+		}
+	} else {
+		if _, err := rand.Read(g.lastRand[:]); err != nil {
+			panic(fmt.Errorf("failed to read random bytes: %w", err))
+		}
+		g.lastMS = ms
+	}
+
+	var b [16]byte
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(b[:6], ts[2:])
+	copy(b[6:], g.lastRand[:])
+	return encodeULID(b)
+}
+
+func encodeULID(b [16]byte) string {
+	// 128 bits are encoded as 26 characters of 5 bits each; the first character only
+	// carries the 3 most significant bits.
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// backfillUIDs assigns IDs to at most batchSize records of the model's table that do not have
+// one yet and returns the number of updated records.
+func backfillUIDs(db *gorm.DB, m interface{}, gen IDGenerator, batchSize int) (int64, error) {
+	if gen == nil {
+		return 0, nil
+	}
+	var ids []int32
+	if err := db.Model(m).Where(
+		"`uid` IS NULL",
+	).Order("`id` asc").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	var updated int64
+	for _, id := range ids {
+		result := db.Model(m).Where(
+			"`id` = ?", id,
+		).Where(
+			"`uid` IS NULL",
+		).UpdateColumn("uid", gen.NewID())
+		if err := result.Error; err != nil {
+			return updated, err
+		}
+		updated += result.RowsAffected
+	}
+	return updated, nil
+}
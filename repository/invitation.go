@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Invitation stores the invitations to projects. Only the SHA-256 hashes of their codes are
+// stored.
+type Invitation struct {
+	db *gorm.DB
+}
+
+func NewInvitation(db *gorm.DB) (*Invitation, error) {
+	if err := db.AutoMigrate(&model.Invitation{}); err != nil {
+		return nil, err
+	}
+	return &Invitation{
+		db: db,
+	}, nil
+}
+
+func (r *Invitation) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Invitation) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func hashInvitationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// List returns the invitations, latest first.
+func (r *Invitation) List(
+	db *gorm.DB,
+	params *entity.ListInvitationsParams,
+) ([]*entity.Invitation, uint, error) {
+	stmt := db.Model(&model.Invitation{})
+	if params.Project != nil {
+		stmt = stmt.Where("`project` = ?", *params.Project)
+	}
+	if params.Status != nil {
+		now := time.Now().UTC()
+		switch entity.InvitationStatus(*params.Status) {
+		case entity.InvitationPending:
+			stmt = stmt.Where(
+				"`revoked_at_utc` IS NULL AND `redeemed_at_utc` IS NULL AND `expires_at_utc` > ?", now,
+			)
+		case entity.InvitationExpired:
+			stmt = stmt.Where(
+				"`revoked_at_utc` IS NULL AND `redeemed_at_utc` IS NULL AND `expires_at_utc` <= ?", now,
+			)
+		case entity.InvitationRedeemed:
+			stmt = stmt.Where("`redeemed_at_utc` IS NOT NULL")
+		case entity.InvitationRevoked:
+			stmt = stmt.Where("`revoked_at_utc` IS NOT NULL")
+		}
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.Invitation
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.Invitation, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, uint(total), nil
+}
+
+func (r *Invitation) get(db *gorm.DB, id int32) (*model.Invitation, error) {
+	var m model.Invitation
+	if err := db.Where("`id` = ?", id).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: invitation with ID %d", entity.ErrRecordNotFound, id)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *Invitation) Get(db *gorm.DB, params *entity.GetInvitationParams) (*entity.Invitation, error) {
+	m, err := r.get(db, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Create creates an invitation and returns it with its code.
+func (r *Invitation) Create(
+	tx *gorm.DB,
+	params *entity.CreateInvitationParams,
+) (*entity.Invitation, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	code := hex.EncodeToString(b)
+	m := model.NewInvitation(params, hashInvitationCode(code))
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	e.Code = code
+	return e, nil
+}
+
+// GetForRedemption returns the invitation of a code, locked until the end of the transaction
+// so that it is redeemed once.
+func (r *Invitation) GetForRedemption(tx *gorm.DB, code string) (*entity.Invitation, error) {
+	var m model.Invitation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(
+		"`hash` = ?", hashInvitationCode(code),
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: invalid invitation code", entity.ErrRecordNotFound)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Redeemed records the redemption of an invitation by a user, assigned its role.
+func (r *Invitation) Redeemed(
+	tx *gorm.DB,
+	id int32,
+	params *entity.RedeemInvitationParams,
+	userRole *entity.UserRole,
+) (*entity.Invitation, error) {
+	m, err := r.get(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	m.RedeemedBy = &params.User
+	m.RedeemedStudio = &params.Studio
+	m.RedeemedAtUTC = &now
+	m.UserRoleID = &userRole.ID
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Revoke revokes a pending invitation. Revoking a revoked invitation does nothing.
+func (r *Invitation) Revoke(
+	tx *gorm.DB,
+	params *entity.RevokeInvitationParams,
+) (*entity.Invitation, error) {
+	m, err := r.get(tx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch m.Status(time.Now().UTC()) {
+	case entity.InvitationRevoked:
+		return m.Entity(), nil
+	case entity.InvitationRedeemed:
+		return nil, fmt.Errorf(
+			"%w: invitation with ID %d was already redeemed, remove its role assignment instead",
+			entity.ErrBadRequest, m.ID,
+		)
+	}
+	now := time.Now().UTC()
+	m.RevokedAtUTC = &now
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
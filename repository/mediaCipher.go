@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Layout of the media encrypted at rest: a header of the magic, the key version and a nonce
+// prefix, then chunks sealed with AES-GCM. Every chunk but the last one holds
+// mediaChunkSize bytes, so that a truncated file is detected by its missing last chunk.
+const (
+	mediaMagic       = "PPIMEDIA"
+	mediaFormatV1    = 1
+	mediaNoncePrefix = 8
+	mediaHeaderSize  = len(mediaMagic) + 1 + 4 + mediaNoncePrefix
+	mediaChunkSize   = 64 * 1024
+)
+
+var errMediaTruncated = errors.New("encrypted media is truncated")
+
+func newMediaAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// mediaKeyVersion returns the version of the key of encrypted media from its first bytes,
+// or 0 for plaintext media.
+func mediaKeyVersion(head []byte) int32 {
+	if len(head) < mediaHeaderSize || !bytes.HasPrefix(head, []byte(mediaMagic)) ||
+		head[len(mediaMagic)] != mediaFormatV1 {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(head[len(mediaMagic)+1:]))
+}
+
+// mediaChunkAAD binds a chunk to the header, and so to the key version, to the project, so
+// that encrypted media cannot be moved to another project, and to whether it is the last.
+func mediaChunkAAD(header []byte, project string, last bool) []byte {
+	aad := append(append([]byte{}, header...), project...)
+	if last {
+		return append(aad, 1)
+	}
+	return append(aad, 0)
+}
+
+func mediaNonce(header []byte, n uint32) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, header[mediaHeaderSize-mediaNoncePrefix:])
+	binary.BigEndian.PutUint32(nonce[mediaNoncePrefix:], n)
+	return nonce
+}
+
+// mediaWriter encrypts the media written to it. It must be closed to write the last chunk.
+type mediaWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	project string
+	header  []byte
+	buf     []byte
+	n       uint32
+}
+
+func newMediaWriter(
+	w io.Writer,
+	aead cipher.AEAD,
+	project string,
+	version int32,
+) (*mediaWriter, error) {
+	header := make([]byte, mediaHeaderSize)
+	copy(header, mediaMagic)
+	header[len(mediaMagic)] = mediaFormatV1
+	binary.BigEndian.PutUint32(header[len(mediaMagic)+1:], uint32(version))
+	if _, err := io.ReadFull(rand.Reader, header[mediaHeaderSize-mediaNoncePrefix:]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &mediaWriter{
+		w:       w,
+		aead:    aead,
+		project: project,
+		header:  header,
+		buf:     make([]byte, 0, mediaChunkSize),
+	}, nil
+}
+
+func (mw *mediaWriter) seal(last bool) error {
+	sealed := mw.aead.Seal(
+		nil, mediaNonce(mw.header, mw.n), mw.buf, mediaChunkAAD(mw.header, mw.project, last),
+	)
+	mw.n++
+	mw.buf = mw.buf[:0]
+	_, err := mw.w.Write(sealed)
+	return err
+}
+
+func (mw *mediaWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := copy(mw.buf[len(mw.buf):cap(mw.buf)], p)
+		mw.buf = mw.buf[:len(mw.buf)+n]
+		p = p[n:]
+		written += n
+		if len(mw.buf) == mediaChunkSize {
+			if err := mw.seal(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close writes the last chunk, which is shorter than the others and may be empty. It does not
+// close the underlying writer.
+func (mw *mediaWriter) Close() error {
+	return mw.seal(true)
+}
+
+// mediaReader decrypts encrypted media, checking each chunk before returning it.
+type mediaReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	project string
+	header  []byte
+	chunk   []byte
+	plain   []byte
+	n       uint32
+	done    bool
+}
+
+func newMediaReader(r io.Reader, aead cipher.AEAD, project string, header []byte) *mediaReader {
+	return &mediaReader{
+		r:       r,
+		aead:    aead,
+		project: project,
+		header:  header,
+		chunk:   make([]byte, mediaChunkSize+aead.Overhead()),
+	}
+}
+
+func (mr *mediaReader) Read(p []byte) (int, error) {
+	for len(mr.plain) == 0 {
+		if mr.done {
+			return 0, io.EOF
+		}
+		n, err := io.ReadFull(mr.r, mr.chunk)
+		last := false
+		switch {
+		case errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+			if n < mr.aead.Overhead() {
+				return 0, errMediaTruncated
+			}
+			last = true
+		case err != nil:
+			return 0, err
+		}
+		plain, err := mr.aead.Open(
+			mr.chunk[:0], mediaNonce(mr.header, mr.n), mr.chunk[:n],
+			mediaChunkAAD(mr.header, mr.project, last),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt media: %w", err)
+		}
+		mr.n++
+		mr.plain = plain
+		mr.done = last
+	}
+	n := copy(p, mr.plain)
+	mr.plain = mr.plain[n:]
+	return n, nil
+}
+
+// peekMediaHeader returns a reader of the whole media and its header when it is encrypted, or
+// nil for plaintext media.
+func peekMediaHeader(r io.Reader) (*bufio.Reader, []byte, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(mediaHeaderSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	if mediaKeyVersion(head) == 0 {
+		return br, nil, nil
+	}
+	return br, append([]byte{}, head...), nil
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// mediaAccessLogLimit limits the number of media accesses listed at once.
+const mediaAccessLogLimit = 10000
+
+var errMediaKeyNotConfigured = errors.New("encryption of media is not configured")
+
+type mediaKeyID struct {
+	project string
+	version int32
+}
+
+// MediaKey stores the keys encrypting the media of the sensitive projects at rest. Each key is
+// a random data key wrapped by Cloud KMS with the key PPI_MEDIA_KMS_KEY, which is unwrapped
+// once per instance and kept in memory. Projects without a key keep their media in plaintext.
+type MediaKey struct {
+	db      *gorm.DB
+	keyName string
+	client  *kms.KeyManagementClient
+
+	mu    sync.Mutex
+	aeads map[mediaKeyID]cipher.AEAD
+}
+
+func NewMediaKey(db *gorm.DB) (*MediaKey, error) {
+	if err := db.AutoMigrate(&model.MediaKey{}, &model.MediaAccessLog{}); err != nil {
+		return nil, err
+	}
+	r := &MediaKey{
+		db:      db,
+		keyName: os.Getenv("PPI_MEDIA_KMS_KEY"),
+		aeads:   make(map[mediaKeyID]cipher.AEAD),
+	}
+	if r.keyName == "" {
+		return r, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.client = client
+	return r, nil
+}
+
+func (r *MediaKey) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *MediaKey) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// Enabled reports whether media can be encrypted, PPI_MEDIA_KMS_KEY being set.
+func (r *MediaKey) Enabled() bool {
+	return r.client != nil
+}
+
+func (r *MediaKey) List(
+	db *gorm.DB,
+	params *entity.ListMediaKeysParams,
+) ([]*entity.MediaKey, error) {
+	var models []*model.MediaKey
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Order("`version` desc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.MediaKey, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// ActiveVersion returns the version of the active key of a project, or 0 when its media are
+// not encrypted.
+func (r *MediaKey) ActiveVersion(db *gorm.DB, project string) (int32, error) {
+	var m model.MediaKey
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`active` = ?", true,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return m.Version, nil
+}
+
+// Rotate creates a new active key of a project and retires the previous one, which is kept
+// to decrypt the media it encrypted.
+func (r *MediaKey) Rotate(
+	tx *gorm.DB,
+	params *entity.RotateMediaKeyParams,
+) (*entity.MediaKey, error) {
+	if !r.Enabled() {
+		return nil, fmt.Errorf("%w: %s", entity.ErrBadRequest, errMediaKeyNotConfigured)
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Encrypt(tx.Statement.Context, &kmspb.EncryptRequest{
+		Name:      r.keyName,
+		Plaintext: key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap media key: %w", err)
+	}
+
+	var version int32
+	if err := tx.Model(&model.MediaKey{}).Where(
+		"`project` = ?", params.Project,
+	).Select("COALESCE(MAX(`version`), 0)").Scan(&version).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := tx.Model(&model.MediaKey{}).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`active` = ?", true,
+	).Updates(map[string]interface{}{
+		"active":         false,
+		"retired_at_utc": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	m := &model.MediaKey{
+		Project:      params.Project,
+		Version:      version + 1,
+		WrappedKey:   resp.Ciphertext,
+		Active:       true,
+		CreatedAtUTC: now,
+		CreatedBy:    params.CreatedBy,
+	}
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// aead returns the cipher of a version of the key of a project, unwrapping it on first use.
+func (r *MediaKey) aead(db *gorm.DB, project string, version int32) (cipher.AEAD, error) {
+	id := mediaKeyID{project: project, version: version}
+	r.mu.Lock()
+	aead, ok := r.aeads[id]
+	r.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+	if !r.Enabled() {
+		return nil, errMediaKeyNotConfigured
+	}
+	var m model.MediaKey
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`version` = ?", version,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: media key of %s with version %d", entity.ErrRecordNotFound, project, version,
+			)
+		}
+		return nil, err
+	}
+	resp, err := r.client.Decrypt(db.Statement.Context, &kmspb.DecryptRequest{
+		Name:       r.keyName,
+		Ciphertext: m.WrappedKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap media key: %w", err)
+	}
+	aead, err = newMediaAEAD(resp.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.aeads[id] = aead
+	r.mu.Unlock()
+	return aead, nil
+}
+
+// Encrypt returns a writer encrypting media of a project to w with its active key of the
+// given version. The writer must be closed to complete the media.
+func (r *MediaKey) Encrypt(
+	db *gorm.DB,
+	project string,
+	version int32,
+	w io.Writer,
+) (io.WriteCloser, error) {
+	aead, err := r.aead(db, project, version)
+	if err != nil {
+		return nil, err
+	}
+	return newMediaWriter(w, aead, project, version)
+}
+
+// KeyVersion returns the version of the key media read from rd were encrypted with, or 0 for
+// plaintext media.
+func (r *MediaKey) KeyVersion(rd io.Reader) (int32, error) {
+	head := make([]byte, mediaHeaderSize)
+	n, err := io.ReadFull(rd, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	return mediaKeyVersion(head[:n]), nil
+}
+
+// Decrypt returns a reader of the plaintext of media of a project read from rd and the
+// version of the key it was encrypted with. Plaintext media are read as is, with version 0.
+func (r *MediaKey) Decrypt(
+	db *gorm.DB,
+	project string,
+	rd io.Reader,
+) (io.Reader, int32, error) {
+	br, header, err := peekMediaHeader(rd)
+	if err != nil {
+		return nil, 0, err
+	}
+	if header == nil {
+		return br, 0, nil
+	}
+	version := mediaKeyVersion(header)
+	aead, err := r.aead(db, project, version)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := br.Discard(mediaHeaderSize); err != nil {
+		return nil, 0, err
+	}
+	return newMediaReader(br, aead, project, header), version, nil
+}
+
+// RecordAccess records that encrypted media were decrypted for a studio.
+func (r *MediaKey) RecordAccess(db *gorm.DB, logs []*entity.MediaAccessLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	models := make([]*model.MediaAccessLog, len(logs))
+	for i, e := range logs {
+		models[i] = &model.MediaAccessLog{
+			Project:       e.Project,
+			Kind:          e.Kind,
+			Path:          e.Path,
+			KeyVersion:    e.KeyVersion,
+			Studio:        e.Studio,
+			AccessedAtUTC: e.AccessedAtUTC,
+		}
+	}
+	return db.Create(models).Error
+}
+
+// ListAccessLogs returns the latest accesses to the encrypted media of a project between the
+// days From and To.
+func (r *MediaKey) ListAccessLogs(
+	db *gorm.DB,
+	params *entity.ListMediaAccessLogsParams,
+) ([]*entity.MediaAccessLog, error) {
+	var models []*model.MediaAccessLog
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`accessed_at_utc` >= ?", params.From,
+	).Where(
+		"`accessed_at_utc` < ?", params.To.AddDate(0, 0, 1),
+	).Order("`id` desc").Limit(mediaAccessLogLimit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.MediaAccessLog, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type APIKeyProjects []string
+
+func (APIKeyProjects) GormDataType() string {
+	return "json"
+}
+
+func (p APIKeyProjects) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *APIKeyProjects) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan APIKeyProjects: %v", value)
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+type APIKeyRoutes []*entity.APIKeyRoute
+
+func (APIKeyRoutes) GormDataType() string {
+	return "json"
+}
+
+func (r APIKeyRoutes) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *APIKeyRoutes) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan APIKeyRoutes: %v", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+type APIKey struct {
+	Name          string         `gorm:"size:100;not null"`
+	Studio        string         `gorm:"size:30;not null;index:ix_api_key_1"`
+	Projects      APIKeyProjects `gorm:"not null"`
+	Routes        APIKeyRoutes   `gorm:"not null"`
+	Prefix        string         `gorm:"size:20;not null;uniqueIndex:uix_api_key_1"`
+	Hash          string         `gorm:"size:64;not null"`
+	ExpiresAtUTC  *time.Time     `gorm:"type:datetime(6)"`
+	LastUsedAtUTC *time.Time     `gorm:"type:datetime(6)"`
+	RotatedAtUTC  *time.Time     `gorm:"type:datetime(6)"`
+	RevokedAtUTC  *time.Time     `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewAPIKey(params *entity.CreateAPIKeyParams, prefix, hash string) *APIKey {
+	now := time.Now().UTC()
+	projects := params.Projects
+	if projects == nil {
+		projects = []string{}
+	}
+	return &APIKey{
+		Name:          params.Name,
+		Studio:        params.Studio,
+		Projects:      projects,
+		Routes:        params.Routes,
+		Prefix:        prefix,
+		Hash:          hash,
+		ExpiresAtUTC:  params.ExpiresAtUTC,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    params.CreatedBy,
+		CreatedBy:     params.CreatedBy,
+	}
+}
+
+func (m *APIKey) Entity() *entity.APIKey {
+	return &entity.APIKey{
+		Name:          m.Name,
+		Studio:        m.Studio,
+		Projects:      m.Projects,
+		Routes:        m.Routes,
+		Prefix:        m.Prefix,
+		ExpiresAtUTC:  m.ExpiresAtUTC,
+		LastUsedAtUTC: m.LastUsedAtUTC,
+		RotatedAtUTC:  m.RotatedAtUTC,
+		RevokedAtUTC:  m.RevokedAtUTC,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+}
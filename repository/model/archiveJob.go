@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ArchiveJob struct {
+	Project        string     `gorm:"size:30;not null;index:ix_archive_job_1"`
+	Status         string     `gorm:"size:20;not null;index:ix_archive_job_2"`
+	Step           string     `gorm:"size:255;not null;default:''"`
+	Total          int64      `gorm:"not null;default:0"`
+	Done           int64      `gorm:"not null;default:0"`
+	Size           int64      `gorm:"not null;default:0"`
+	Error          *string    `gorm:"type:text"`
+	StartedAtUTC   *time.Time `gorm:"type:datetime(6)"`
+	CompletedAtUTC *time.Time `gorm:"type:datetime(6)"`
+	ExpiresAtUTC   *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewArchiveJob(params *entity.CreateArchiveJobParams) *ArchiveJob {
+	now := time.Now().UTC()
+	return &ArchiveJob{
+		Project:       params.Project,
+		Status:        string(entity.ArchiveQueued),
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		CreatedBy:     params.CreatedBy,
+	}
+}
+
+func (m *ArchiveJob) Entity() *entity.ArchiveJob {
+	return &entity.ArchiveJob{
+		Project:        m.Project,
+		Status:         entity.ArchiveStatus(m.Status),
+		Step:           m.Step,
+		Total:          m.Total,
+		Done:           m.Done,
+		Size:           m.Size,
+		Error:          m.Error,
+		StartedAtUTC:   m.StartedAtUTC,
+		CompletedAtUTC: m.CompletedAtUTC,
+		ExpiresAtUTC:   m.ExpiresAtUTC,
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+		CreatedBy:      m.CreatedBy,
+		ID:             m.ID,
+	}
+}
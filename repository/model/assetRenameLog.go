@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// AssetRenameLog is the audit log of the asset renames. Changes holds the counts per table.
+type AssetRenameLog struct {
+	Project string `gorm:"size:30;not null;index:ix_asset_rename_log_1,priority:1"`
+	Root    string `gorm:"size:30;not null"`
+	From    string `gorm:"size:255;not null"`
+	To      string `gorm:"size:255;not null"`
+	Merge   bool   `gorm:"not null"`
+	Changes JSON   `gorm:"not null"`
+	Studio  string `gorm:"size:30;not null"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null;index:ix_asset_rename_log_1,priority:2"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewAssetRenameLog(e *entity.AssetRename) (*AssetRenameLog, error) {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return nil, err
+	}
+	return &AssetRenameLog{
+		Project:      e.Project,
+		Root:         e.Root,
+		From:         e.From,
+		To:           e.To,
+		Merge:        e.Merge,
+		Changes:      changes,
+		Studio:       e.Studio,
+		CreatedAtUTC: e.CreatedAtUTC,
+		CreatedBy:    e.CreatedBy,
+	}, nil
+}
+
+func (m *AssetRenameLog) Entity() (*entity.AssetRename, error) {
+	e := &entity.AssetRename{
+		Project:      m.Project,
+		Root:         m.Root,
+		From:         m.From,
+		To:           m.To,
+		Merge:        m.Merge,
+		Studio:       m.Studio,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}
+	if err := json.Unmarshal(m.Changes, &e.Changes); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
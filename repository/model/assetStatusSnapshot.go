@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// AssetStatusSnapshot is the latest review of a phase of an asset on SnapshotDate, recorded
+// nightly for the pivot diff.
+type AssetStatusSnapshot struct {
+	Project        string    `gorm:"size:30;not null;uniqueIndex:uix_asset_status_snapshot_1,priority:1"`
+	SnapshotDate   time.Time `gorm:"type:date;not null;uniqueIndex:uix_asset_status_snapshot_1,priority:2"`
+	Group1         string    `gorm:"column:group_1;size:255;not null;uniqueIndex:uix_asset_status_snapshot_1,priority:3"`
+	Relation       string    `gorm:"size:100;not null;uniqueIndex:uix_asset_status_snapshot_1,priority:4"`
+	Phase          string    `gorm:"size:100;not null;uniqueIndex:uix_asset_status_snapshot_1,priority:5"`
+	Take           string    `gorm:"size:30;not null"`
+	ApprovalStatus string    `gorm:"size:20;not null"`
+	WorkStatus     string    `gorm:"size:20;not null"`
+	ReviewInfoID   int32     `gorm:"not null"`
+	CreatedAtUTC   time.Time `gorm:"type:datetime(6) not null"`
+	ID             int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ClientCompatRule struct {
+	Client          string  `gorm:"size:50;not null;uniqueIndex:uix_client_compat_rule_1"`
+	MinVersion      string  `gorm:"size:50;not null"`
+	DeprecatedBelow *string `gorm:"size:50"`
+	LatestVersion   *string `gorm:"size:50"`
+	ChangelogURL    *string `gorm:"size:500"`
+	Message         string  `gorm:"type:text;not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ClientCompatRule) Entity() *entity.ClientCompatRule {
+	return &entity.ClientCompatRule{
+		Client:          m.Client,
+		MinVersion:      m.MinVersion,
+		DeprecatedBelow: m.DeprecatedBelow,
+		LatestVersion:   m.LatestVersion,
+		ChangelogURL:    m.ChangelogURL,
+		Message:         m.Message,
+		CreatedAtUTC:    m.CreatedAtUTC,
+		ModifiedAtUTC:   m.ModifiedAtUTC,
+		ModifiedBy:      m.ModifiedBy,
+		CreatedBy:       m.CreatedBy,
+	}
+}
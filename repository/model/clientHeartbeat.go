@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ClientHeartbeat struct {
+	Studio         string    `gorm:"size:30;not null;uniqueIndex:uix_client_heartbeat_1,priority:1"`
+	APIKeyID       int32     `gorm:"not null;default:0;uniqueIndex:uix_client_heartbeat_1,priority:2"`
+	Client         string    `gorm:"size:50;not null;uniqueIndex:uix_client_heartbeat_1,priority:3"`
+	Host           string    `gorm:"size:255;not null;uniqueIndex:uix_client_heartbeat_1,priority:4"`
+	Version        string    `gorm:"size:50;not null"`
+	Beats          int64     `gorm:"not null;default:0"`
+	FirstSeenAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	LastSeenAtUTC  time.Time `gorm:"type:datetime(6) not null;index:ix_client_heartbeat_1"`
+	ID             int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewClientHeartbeat(
+	params *entity.RecordClientHeartbeatParams,
+	now time.Time,
+) *ClientHeartbeat {
+	return &ClientHeartbeat{
+		Studio:         params.Studio,
+		APIKeyID:       params.APIKeyID,
+		Client:         params.Client,
+		Host:           params.Host,
+		Version:        params.Version,
+		Beats:          1,
+		FirstSeenAtUTC: now,
+		LastSeenAtUTC:  now,
+	}
+}
+
+func (m *ClientHeartbeat) Entity() *entity.ClientHeartbeat {
+	return &entity.ClientHeartbeat{
+		Studio:         m.Studio,
+		APIKeyID:       m.APIKeyID,
+		Client:         m.Client,
+		Version:        m.Version,
+		Host:           m.Host,
+		Beats:          m.Beats,
+		FirstSeenAtUTC: m.FirstSeenAtUTC,
+		LastSeenAtUTC:  m.LastSeenAtUTC,
+		ID:             m.ID,
+	}
+}
+
+// ClientUsage counts the heartbeats of a version of a client on a host per day, in UTC, from
+// which the adoption of the versions is reported.
+type ClientUsage struct {
+	Day     time.Time `gorm:"type:date;not null;uniqueIndex:uix_client_usage_1,priority:1"`
+	Client  string    `gorm:"size:50;not null;uniqueIndex:uix_client_usage_1,priority:2"`
+	Version string    `gorm:"size:50;not null;uniqueIndex:uix_client_usage_1,priority:3"`
+	Studio  string    `gorm:"size:30;not null;uniqueIndex:uix_client_usage_1,priority:4"`
+	Host    string    `gorm:"size:255;not null;uniqueIndex:uix_client_usage_1,priority:5"`
+	Beats   int64     `gorm:"not null;default:0"`
+	ID      int64     `gorm:"primaryKey;autoIncrement;not null"`
+}
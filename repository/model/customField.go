@@ -0,0 +1,122 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type EnumValues []string
+
+func (EnumValues) GormDataType() string {
+	return "json"
+}
+
+func (v EnumValues) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+func (v *EnumValues) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan EnumValues: %v", value)
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+type CustomFieldDefinition struct {
+	Project     string `gorm:"size:30;not null;uniqueIndex:uix_custom_field_definition_1,priority:1"`
+	Target      string `gorm:"size:10;not null;uniqueIndex:uix_custom_field_definition_1,priority:2"`
+	Key         string `gorm:"size:50;not null;uniqueIndex:uix_custom_field_definition_1,priority:3"`
+	DisplayName string `gorm:"size:100;not null"`
+	Type        string `gorm:"size:10;not null"`
+	EnumValues  EnumValues
+	Required    bool `gorm:"not null;default:false"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;default:0;uniqueIndex:uix_custom_field_definition_1,priority:4"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewCustomFieldDefinition(
+	p *entity.CreateCustomFieldDefinitionParams,
+) *CustomFieldDefinition {
+	now := time.Now().UTC()
+	var createdBy string
+	if p.CreatedBy != nil {
+		createdBy = *p.CreatedBy
+	}
+	var enumValues EnumValues
+	if p.Type == entity.CustomFieldEnum {
+		enumValues = p.EnumValues
+	}
+	return &CustomFieldDefinition{
+		Project:     p.Project,
+		Target:      p.Target,
+		Key:         p.Key,
+		DisplayName: p.DisplayName,
+		Type:        p.Type,
+		EnumValues:  enumValues,
+		Required:    p.Required,
+
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+}
+
+func (m *CustomFieldDefinition) Entity() *entity.CustomFieldDefinition {
+	enumValues := []string(m.EnumValues)
+	if enumValues == nil {
+		enumValues = []string{}
+	}
+	return &entity.CustomFieldDefinition{
+		Project:       m.Project,
+		Target:        m.Target,
+		Key:           m.Key,
+		DisplayName:   m.DisplayName,
+		Type:          m.Type,
+		EnumValues:    enumValues,
+		Required:      m.Required,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+}
+
+type AssetMetadata struct {
+	Project  string `gorm:"size:30;not null;uniqueIndex:uix_asset_metadata_1,priority:1"`
+	Asset    string `gorm:"size:255;not null;uniqueIndex:uix_asset_metadata_1,priority:2"`
+	Relation string `gorm:"size:100;not null;uniqueIndex:uix_asset_metadata_1,priority:3"`
+	Metadata GormJSONObject
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *AssetMetadata) Entity() *entity.AssetMetadata {
+	metadata := entity.JSONObject(m.Metadata)
+	if metadata == nil {
+		metadata = entity.JSONObject{}
+	}
+	return &entity.AssetMetadata{
+		Project:       m.Project,
+		Asset:         m.Asset,
+		Relation:      m.Relation,
+		Metadata:      metadata,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+	}
+}
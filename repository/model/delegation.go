@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type Delegation struct {
+	Delegator    string     `gorm:"size:100;not null;index:ix_delegation_1"`
+	Delegate     string     `gorm:"size:100;not null;index:ix_delegation_1"`
+	Project      *string    `gorm:"size:30"`
+	StartsAtUTC  time.Time  `gorm:"type:datetime(6) not null"`
+	EndsAtUTC    time.Time  `gorm:"type:datetime(6) not null;index:ix_delegation_2"`
+	Reason       string     `gorm:"type:text;not null"`
+	Status       string     `gorm:"size:20;not null;index:ix_delegation_2"`
+	RevokedAtUTC *time.Time `gorm:"type:datetime(6)"`
+	RevokedBy    *string    `gorm:"size:100"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewDelegation(params *entity.CreateDelegationParams) *Delegation {
+	return &Delegation{
+		Delegator:    params.Delegator,
+		Delegate:     params.Delegate,
+		Project:      params.Project,
+		StartsAtUTC:  params.StartsAtUTC.UTC(),
+		EndsAtUTC:    params.EndsAtUTC.UTC(),
+		Reason:       params.Reason,
+		Status:       string(entity.DelegationActive),
+		CreatedAtUTC: time.Now().UTC(),
+		CreatedBy:    params.CreatedBy,
+	}
+}
+
+// Entity returns the delegation as expired once it has ended, even before it is marked so.
+func (m *Delegation) Entity() *entity.Delegation {
+	status := entity.DelegationStatus(m.Status)
+	if status == entity.DelegationActive && !time.Now().UTC().Before(m.EndsAtUTC) {
+		status = entity.DelegationExpired
+	}
+	return &entity.Delegation{
+		Delegator:    m.Delegator,
+		Delegate:     m.Delegate,
+		Project:      m.Project,
+		StartsAtUTC:  m.StartsAtUTC,
+		EndsAtUTC:    m.EndsAtUTC,
+		Reason:       m.Reason,
+		Status:       status,
+		RevokedAtUTC: m.RevokedAtUTC,
+		RevokedBy:    m.RevokedBy,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type PathPatterns []string
+
+func (PathPatterns) GormDataType() string {
+	return "json"
+}
+
+func (p PathPatterns) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *PathPatterns) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan PathPatterns: %v", value)
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+type DirectoryTemplate struct {
+	Project  string       `gorm:"size:30;not null;uniqueIndex:uix_directory_template_1,priority:1"`
+	Name     string       `gorm:"size:50;not null;uniqueIndex:uix_directory_template_1,priority:2"`
+	Patterns PathPatterns `gorm:"not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;default:0;uniqueIndex:uix_directory_template_1,priority:3"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewDirectoryTemplate(params *entity.CreateDirectoryTemplateParams) *DirectoryTemplate {
+	now := time.Now().UTC()
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	return &DirectoryTemplate{
+		Project:       params.Project,
+		Name:          params.Name,
+		Patterns:      params.Patterns,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+}
+
+func (m *DirectoryTemplate) Entity() *entity.DirectoryTemplate {
+	patterns := []string(m.Patterns)
+	if patterns == nil {
+		patterns = []string{}
+	}
+	return &entity.DirectoryTemplate{
+		Project:       m.Project,
+		Name:          m.Name,
+		Patterns:      patterns,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ExportJob struct {
+	Project        string `gorm:"size:30;not null;index:ix_export_job_1"`
+	Kind           string `gorm:"size:20;not null"`
+	Format         string `gorm:"size:10;not null"`
+	Query          JSON
+	Status         string     `gorm:"size:20;not null;index:ix_export_job_2"`
+	Size           int64      `gorm:"not null;default:0"`
+	Error          *string    `gorm:"type:text"`
+	StartedAtUTC   *time.Time `gorm:"type:datetime(6)"`
+	CompletedAtUTC *time.Time `gorm:"type:datetime(6)"`
+	ExpiresAtUTC   *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewExportJob(params *entity.CreateExportJobParams, query []byte) *ExportJob {
+	now := time.Now().UTC()
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	return &ExportJob{
+		Project:       params.Project,
+		Kind:          params.Kind,
+		Format:        params.Format,
+		Query:         JSON(query),
+		Status:        string(entity.ExportQueued),
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+}
+
+func (m *ExportJob) Entity() *entity.ExportJob {
+	return &entity.ExportJob{
+		Project:        m.Project,
+		Kind:           m.Kind,
+		Format:         m.Format,
+		Query:          json.RawMessage(m.Query),
+		Status:         entity.ExportStatus(m.Status),
+		Size:           m.Size,
+		Error:          m.Error,
+		StartedAtUTC:   m.StartedAtUTC,
+		CompletedAtUTC: m.CompletedAtUTC,
+		ExpiresAtUTC:   m.ExpiresAtUTC,
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+		ModifiedBy:     m.ModifiedBy,
+		CreatedBy:      m.CreatedBy,
+		ID:             m.ID,
+	}
+}
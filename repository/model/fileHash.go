@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type FileHash struct {
+	Project      string `gorm:"size:30;not null;index:ix_file_hash_1,priority:1;index:ix_file_hash_2,priority:1"`
+	Hash         string `gorm:"type:char(64);not null;index:ix_file_hash_1,priority:2"`
+	Path         string `gorm:"size:1000;not null"`
+	Size         uint64 `gorm:"not null;default:0"`
+	ReviewInfoID int32  `gorm:"not null;index:ix_file_hash_2,priority:2"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *FileHash) Entity() *entity.FileHash {
+	return &entity.FileHash{
+		Project:      m.Project,
+		Hash:         m.Hash,
+		Path:         m.Path,
+		Size:         m.Size,
+		ReviewInfoID: m.ReviewInfoID,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type Invitation struct {
+	Project                string     `gorm:"size:30;not null;index:ix_invitation_1"`
+	Role                   string     `gorm:"size:30;not null"`
+	Invitee                *string    `gorm:"size:100"`
+	Hash                   string     `gorm:"size:64;not null;uniqueIndex:uix_invitation_1"`
+	ExpiresAtUTC           time.Time  `gorm:"type:datetime(6) not null"`
+	MembershipExpiresAtUTC *time.Time `gorm:"type:datetime(6)"`
+	RedeemedBy             *string    `gorm:"size:100"`
+	RedeemedStudio         *string    `gorm:"size:30"`
+	RedeemedAtUTC          *time.Time `gorm:"type:datetime(6)"`
+	UserRoleID             *int32
+	RevokedAtUTC           *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewInvitation(params *entity.CreateInvitationParams, hash string) *Invitation {
+	return &Invitation{
+		Project:                params.Project,
+		Role:                   params.Role,
+		Invitee:                params.Invitee,
+		Hash:                   hash,
+		ExpiresAtUTC:           params.ExpiresAtUTC.UTC(),
+		MembershipExpiresAtUTC: params.MembershipExpiresAtUTC,
+		CreatedAtUTC:           time.Now().UTC(),
+		CreatedBy:              params.CreatedBy,
+	}
+}
+
+// Status returns the state of the invitation at the given time.
+func (m *Invitation) Status(now time.Time) entity.InvitationStatus {
+	switch {
+	case m.RevokedAtUTC != nil:
+		return entity.InvitationRevoked
+	case m.RedeemedAtUTC != nil:
+		return entity.InvitationRedeemed
+	case !now.Before(m.ExpiresAtUTC):
+		return entity.InvitationExpired
+	}
+	return entity.InvitationPending
+}
+
+func (m *Invitation) Entity() *entity.Invitation {
+	return &entity.Invitation{
+		Project:                m.Project,
+		Role:                   m.Role,
+		Invitee:                m.Invitee,
+		Status:                 m.Status(time.Now().UTC()),
+		ExpiresAtUTC:           m.ExpiresAtUTC,
+		MembershipExpiresAtUTC: m.MembershipExpiresAtUTC,
+		RedeemedBy:             m.RedeemedBy,
+		RedeemedStudio:         m.RedeemedStudio,
+		RedeemedAtUTC:          m.RedeemedAtUTC,
+		UserRoleID:             m.UserRoleID,
+		RevokedAtUTC:           m.RevokedAtUTC,
+		CreatedAtUTC:           m.CreatedAtUTC,
+		CreatedBy:              m.CreatedBy,
+		ID:                     m.ID,
+	}
+}
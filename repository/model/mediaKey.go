@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// MediaKey is a data key of a project wrapped by Cloud KMS.
+type MediaKey struct {
+	Project      string     `gorm:"size:30;not null;uniqueIndex:ix_media_key_1,priority:1"`
+	Version      int32      `gorm:"not null;uniqueIndex:ix_media_key_1,priority:2"`
+	WrappedKey   []byte     `gorm:"type:blob;not null"`
+	Active       bool       `gorm:"not null;default:false"`
+	RetiredAtUTC *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *MediaKey) Entity() *entity.MediaKey {
+	return &entity.MediaKey{
+		Project:      m.Project,
+		Version:      m.Version,
+		Active:       m.Active,
+		RetiredAtUTC: m.RetiredAtUTC,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}
+}
+
+type MediaAccessLog struct {
+	Project       string    `gorm:"size:30;not null;index:ix_media_access_log_1,priority:1"`
+	Kind          string    `gorm:"size:20;not null"`
+	Path          string    `gorm:"size:1000;not null"`
+	KeyVersion    int32     `gorm:"not null"`
+	Studio        string    `gorm:"size:30;not null"`
+	AccessedAtUTC time.Time `gorm:"type:datetime(6) not null;index:ix_media_access_log_1,priority:2"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *MediaAccessLog) Entity() *entity.MediaAccessLog {
+	return &entity.MediaAccessLog{
+		Project:       m.Project,
+		Kind:          m.Kind,
+		Path:          m.Path,
+		KeyVersion:    m.KeyVersion,
+		Studio:        m.Studio,
+		AccessedAtUTC: m.AccessedAtUTC,
+		ID:            m.ID,
+	}
+}
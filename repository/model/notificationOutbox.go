@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type NotificationOutbox struct {
+	Kind             string     `gorm:"size:30;not null"`
+	Project          string     `gorm:"size:30;not null"`
+	Payload          JSON       `gorm:"not null"`
+	Status           string     `gorm:"size:10;not null;index:ix_notification_outbox_1"`
+	Attempts         uint32     `gorm:"not null;default:0"`
+	NextAttemptAtUTC time.Time  `gorm:"type:datetime(6);not null;index:ix_notification_outbox_1"`
+	LockedUntilUTC   *time.Time `gorm:"type:datetime(6)"`
+	LockToken        *string    `gorm:"size:36;index:ix_notification_outbox_2"`
+	LastError        string     `gorm:"type:text"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewNotificationOutbox(
+	kind entity.NotificationOutboxKind,
+	project string,
+	payload []byte,
+) *NotificationOutbox {
+	now := time.Now().UTC()
+	return &NotificationOutbox{
+		Kind:             string(kind),
+		Project:          project,
+		Payload:          JSON(payload),
+		Status:           string(entity.NotificationOutboxPending),
+		NextAttemptAtUTC: now,
+		CreatedAtUTC:     now,
+		ModifiedAtUTC:    now,
+	}
+}
+
+func (m *NotificationOutbox) Entity() *entity.NotificationOutboxEntry {
+	return &entity.NotificationOutboxEntry{
+		Kind:             entity.NotificationOutboxKind(m.Kind),
+		Project:          m.Project,
+		Payload:          json.RawMessage(m.Payload),
+		Status:           entity.NotificationOutboxStatus(m.Status),
+		Attempts:         m.Attempts,
+		NextAttemptAtUTC: m.NextAttemptAtUTC,
+		LastError:        m.LastError,
+		CreatedAtUTC:     m.CreatedAtUTC,
+		ModifiedAtUTC:    m.ModifiedAtUTC,
+		ID:               m.ID,
+	}
+}
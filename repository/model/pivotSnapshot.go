@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// PivotSnapshot is soft deleted when its share link is revoked.
+type PivotSnapshot struct {
+	Project         string     `gorm:"size:30;not null;index:ix_pivot_snapshot_1,priority:1"`
+	Root            string     `gorm:"size:30;not null"`
+	View            string     `gorm:"size:10;not null"`
+	Filters         JSON       `gorm:"not null"`
+	Phases          JSON       `gorm:"not null"`
+	Rows            JSON       `gorm:"not null"`
+	Total           int64      `gorm:"not null"`
+	ExpiresAtUTC    time.Time  `gorm:"type:datetime(6);not null"`
+	Views           int64      `gorm:"not null;default:0"`
+	LastViewedAtUTC *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;default:0;index:ix_pivot_snapshot_1,priority:2"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *PivotSnapshot) Entity() (*entity.PivotSnapshot, error) {
+	var filters map[string][]string
+	if err := json.Unmarshal(m.Filters, &filters); err != nil {
+		return nil, err
+	}
+	var phases []string
+	if err := json.Unmarshal(m.Phases, &phases); err != nil {
+		return nil, err
+	}
+	return &entity.PivotSnapshot{
+		Project:         m.Project,
+		Root:            m.Root,
+		View:            m.View,
+		Filters:         filters,
+		Phases:          phases,
+		Rows:            json.RawMessage(m.Rows),
+		Total:           m.Total,
+		ExpiresAtUTC:    m.ExpiresAtUTC,
+		Views:           m.Views,
+		LastViewedAtUTC: m.LastViewedAtUTC,
+		CreatedAtUTC:    m.CreatedAtUTC,
+		CreatedBy:       m.CreatedBy,
+		ID:              m.ID,
+	}, nil
+}
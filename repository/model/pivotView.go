@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type PivotView struct {
+	Project string `gorm:"size:30;not null;uniqueIndex:uix_pivot_view_1,priority:1"`
+	Owner   string `gorm:"size:100;not null;uniqueIndex:uix_pivot_view_1,priority:2"`
+	Name    string `gorm:"size:100;not null;uniqueIndex:uix_pivot_view_1,priority:3"`
+	Root    string `gorm:"size:30;not null"`
+	Filters JSON   `gorm:"not null"`
+	Sort    string `gorm:"size:50;not null"`
+	Dir     string `gorm:"size:4;not null"`
+	Columns JSON   `gorm:"not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;default:0;uniqueIndex:uix_pivot_view_1,priority:4"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *PivotView) Entity() (*entity.PivotView, error) {
+	e := &entity.PivotView{
+		Project:       m.Project,
+		Owner:         m.Owner,
+		Name:          m.Name,
+		Root:          m.Root,
+		Sort:          m.Sort,
+		Dir:           m.Dir,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ID:            m.ID,
+	}
+	if err := json.Unmarshal(m.Filters, &e.Filters); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(m.Columns, &e.Columns); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ProjectBootstrapJob struct {
+	Project            string     `gorm:"size:30;not null;index:ix_project_bootstrap_job_1"`
+	Template           *string    `gorm:"size:30"`
+	Settings           bool       `gorm:"not null;default:false"`
+	Directories        bool       `gorm:"not null;default:false"`
+	Categories         bool       `gorm:"not null;default:false"`
+	Status             string     `gorm:"size:20;not null;index:ix_project_bootstrap_job_2"`
+	Step               string     `gorm:"size:20;not null;default:''"`
+	Total              int64      `gorm:"not null;default:0"`
+	Done               int64      `gorm:"not null;default:0"`
+	SettingsCopied     int        `gorm:"not null;default:0"`
+	DirectoriesCopied  int        `gorm:"not null;default:0"`
+	CategoriesImported int        `gorm:"not null;default:0"`
+	GroupsImported     int        `gorm:"not null;default:0"`
+	Error              *string    `gorm:"type:text"`
+	StartedAtUTC       *time.Time `gorm:"type:datetime(6)"`
+	CompletedAtUTC     *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewProjectBootstrapJob(params *entity.CreateProjectBootstrapJobParams) *ProjectBootstrapJob {
+	now := time.Now().UTC()
+	return &ProjectBootstrapJob{
+		Project:       params.Project,
+		Template:      params.Template,
+		Settings:      params.Settings,
+		Directories:   params.Directories,
+		Categories:    params.Categories,
+		Status:        string(entity.ProjectBootstrapQueued),
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		CreatedBy:     params.CreatedBy,
+	}
+}
+
+func (m *ProjectBootstrapJob) Entity() *entity.ProjectBootstrapJob {
+	return &entity.ProjectBootstrapJob{
+		Project:     m.Project,
+		Template:    m.Template,
+		Settings:    m.Settings,
+		Directories: m.Directories,
+		Categories:  m.Categories,
+		Status:      entity.ProjectBootstrapStatus(m.Status),
+		Step:        m.Step,
+		Total:       m.Total,
+		Done:        m.Done,
+		Result: entity.ProjectBootstrapResult{
+			Settings:    m.SettingsCopied,
+			Directories: m.DirectoriesCopied,
+			Categories:  m.CategoriesImported,
+			Groups:      m.GroupsImported,
+		},
+		Error:          m.Error,
+		StartedAtUTC:   m.StartedAtUTC,
+		CompletedAtUTC: m.CompletedAtUTC,
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+		CreatedBy:      m.CreatedBy,
+		ID:             m.ID,
+	}
+}
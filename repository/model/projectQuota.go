@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ProjectQuota struct {
+	Project    string `gorm:"size:30;not null;uniqueIndex:uix_project_quota_1,priority:1"`
+	Operation  string `gorm:"size:30;not null;uniqueIndex:uix_project_quota_1,priority:2"`
+	DailyLimit int32  `gorm:"not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;uniqueIndex:uix_project_quota_1,priority:3"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ProjectQuota) Entity() *entity.ProjectQuota {
+	return &entity.ProjectQuota{
+		Project:       m.Project,
+		Operation:     entity.QuotaOperation(m.Operation),
+		DailyLimit:    m.DailyLimit,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+}
+
+// ProjectQuotaUsage counts the requests of an operation of a project on a UTC day, formatted
+// as 2006-01-02.
+type ProjectQuotaUsage struct {
+	Project   string `gorm:"size:30;not null;uniqueIndex:uix_project_quota_usage_1,priority:1"`
+	Operation string `gorm:"size:30;not null;uniqueIndex:uix_project_quota_usage_1,priority:2"`
+	Day       string `gorm:"size:10;not null;uniqueIndex:uix_project_quota_usage_1,priority:3"`
+	Used      int32  `gorm:"not null;default:0"`
+	ID        int32  `gorm:"primaryKey;autoIncrement;not null"`
+}
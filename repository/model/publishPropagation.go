@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// PublishPropagation records the sync status of a publish transaction in a studio. Rows of
+// pending propagations are only created when they are reported overdue, so that each one is
+// alerted once.
+type PublishPropagation struct {
+	Project      string     `gorm:"size:30;not null;uniqueIndex:uix_publish_propagation_1"`
+	LogID        string     `gorm:"size:36;not null;uniqueIndex:uix_publish_propagation_1"`
+	Studio       string     `gorm:"size:30;not null;uniqueIndex:uix_publish_propagation_1"`
+	Status       string     `gorm:"size:10;not null"`
+	ArrivedAtUTC *time.Time `gorm:"type:datetime(6)"`
+	Message      string     `gorm:"type:text"`
+	AlertedAtUTC *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *PublishPropagation) Entity() *entity.StudioPropagation {
+	modifiedAt := m.ModifiedAtUTC
+	return &entity.StudioPropagation{
+		Studio:        m.Studio,
+		Status:        entity.PropagationStatus(m.Status),
+		ArrivedAtUTC:  m.ArrivedAtUTC,
+		Message:       m.Message,
+		ModifiedAtUTC: &modifiedAt,
+	}
+}
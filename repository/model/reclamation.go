@@ -0,0 +1,85 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type Reclamation struct {
+	Project        string     `gorm:"size:30;not null;index:ix_reclamation_1"`
+	Status         string     `gorm:"size:20;not null;index:ix_reclamation_1"`
+	Reason         string     `gorm:"type:text;not null"`
+	ReviewedBy     *string    `gorm:"size:100"`
+	ReviewedAtUTC  *time.Time `gorm:"type:datetime(6)"`
+	ReviewComment  *string    `gorm:"type:text"`
+	CompletedBy    *string    `gorm:"size:100"`
+	CompletedAtUTC *time.Time `gorm:"type:datetime(6)"`
+	FreedBytes     *int64
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewReclamation(params *entity.CreateReclamationParams) *Reclamation {
+	now := time.Now().UTC()
+	return &Reclamation{
+		Project:       params.Project,
+		Status:        string(entity.ReclamationRequested),
+		Reason:        params.Reason,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    params.CreatedBy,
+		CreatedBy:     params.CreatedBy,
+	}
+}
+
+func (m *Reclamation) Entity() *entity.Reclamation {
+	return &entity.Reclamation{
+		Project:        m.Project,
+		Status:         entity.ReclamationStatus(m.Status),
+		Reason:         m.Reason,
+		ReviewedBy:     m.ReviewedBy,
+		ReviewedAtUTC:  m.ReviewedAtUTC,
+		ReviewComment:  m.ReviewComment,
+		CompletedBy:    m.CompletedBy,
+		CompletedAtUTC: m.CompletedAtUTC,
+		FreedBytes:     m.FreedBytes,
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+		ModifiedBy:     m.ModifiedBy,
+		CreatedBy:      m.CreatedBy,
+		ID:             m.ID,
+	}
+}
+
+// ReclamationTake copies the take of a review info, so that the manifest is not changed by
+// later corrections of the review info. Pending tells whether the reclamation is requested or
+// approved, so that a take is only in one pending reclamation.
+type ReclamationTake struct {
+	ReclamationID int32  `gorm:"not null;index:ix_reclamation_take_1"`
+	ReviewInfoID  int32  `gorm:"not null;index:ix_reclamation_take_2"`
+	Pending       bool   `gorm:"not null;index:ix_reclamation_take_2"`
+	Root          string `gorm:"size:30;not null"`
+	Group1        string `gorm:"column:group_1;size:255;not null"`
+	Relation      string `gorm:"size:100;not null"`
+	Phase         string `gorm:"size:100;not null"`
+	Take          string `gorm:"size:30;not null"`
+	TakePath      string `gorm:"size:1000;not null"`
+	ID            int32  `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ReclamationTake) Entity() *entity.ReclamationTake {
+	return &entity.ReclamationTake{
+		ReviewInfoID: m.ReviewInfoID,
+		Root:         m.Root,
+		Group1:       m.Group1,
+		Relation:     m.Relation,
+		Phase:        m.Phase,
+		Take:         m.Take,
+		TakePath:     m.TakePath,
+	}
+}
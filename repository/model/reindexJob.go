@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ReindexJob struct {
+	Project        string     `gorm:"size:30;not null;index:ix_reindex_job_1"`
+	Kind           string     `gorm:"size:20;not null"`
+	Status         string     `gorm:"size:20;not null;index:ix_reindex_job_2"`
+	Total          int64      `gorm:"not null;default:0"`
+	Done           int64      `gorm:"not null;default:0"`
+	Rows           int64      `gorm:"not null;default:0"`
+	Error          *string    `gorm:"type:text"`
+	StartedAtUTC   *time.Time `gorm:"type:datetime(6)"`
+	CompletedAtUTC *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewReindexJob(params *entity.CreateReindexJobParams) *ReindexJob {
+	now := time.Now().UTC()
+	return &ReindexJob{
+		Project:       params.Project,
+		Kind:          params.Kind,
+		Status:        string(entity.ReindexQueued),
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		CreatedBy:     params.CreatedBy,
+	}
+}
+
+func (m *ReindexJob) Entity() *entity.ReindexJob {
+	return &entity.ReindexJob{
+		Project:        m.Project,
+		Kind:           m.Kind,
+		Status:         entity.ReindexStatus(m.Status),
+		Total:          m.Total,
+		Done:           m.Done,
+		Rows:           m.Rows,
+		Error:          m.Error,
+		StartedAtUTC:   m.StartedAtUTC,
+		CompletedAtUTC: m.CompletedAtUTC,
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+		CreatedBy:      m.CreatedBy,
+		ID:             m.ID,
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ReviewApprovalGate struct {
+	Project string `gorm:"size:30;not null;uniqueIndex:uix_review_approval_gate_1,priority:1"`
+	Phase   string `gorm:"size:30;not null;uniqueIndex:uix_review_approval_gate_1,priority:2"`
+	Mode    string `gorm:"size:10;not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ReviewApprovalGate) Entity() *entity.ReviewApprovalGate {
+	return &entity.ReviewApprovalGate{
+		Project:       m.Project,
+		Phase:         m.Phase,
+		Mode:          m.Mode,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+	}
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// ReviewInfoAuditLog holds the values as JSON text.
+type ReviewInfoAuditLog struct {
+	Project      string `gorm:"size:30;not null;index:ix_review_info_audit_log_1,priority:1"`
+	ReviewInfoID int32  `gorm:"not null;index:ix_review_info_audit_log_1,priority:2"`
+	Field        string `gorm:"size:50;not null"`
+	Before       string `gorm:"type:text;not null"`
+	After        string `gorm:"type:text;not null"`
+	Studio       string `gorm:"size:30;not null"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewReviewInfoAuditLog(
+	params *entity.UpdateReviewInfoParams,
+	field string,
+	before interface{},
+	after interface{},
+) (*ReviewInfoAuditLog, error) {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	var createdBy string
+	if params.ModifiedBy != nil {
+		createdBy = *params.ModifiedBy
+	}
+	return &ReviewInfoAuditLog{
+		Project:      params.Project,
+		ReviewInfoID: params.ID,
+		Field:        field,
+		Before:       string(beforeJSON),
+		After:        string(afterJSON),
+		Studio:       params.Studio,
+		CreatedAtUTC: time.Now().UTC(),
+		CreatedBy:    createdBy,
+	}, nil
+}
+
+func (m *ReviewInfoAuditLog) Entity() (*entity.ReviewInfoAuditLog, error) {
+	var before, after interface{}
+	if err := json.Unmarshal([]byte(m.Before), &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(m.After), &after); err != nil {
+		return nil, err
+	}
+	return &entity.ReviewInfoAuditLog{
+		Project:      m.Project,
+		ReviewInfoID: m.ReviewInfoID,
+		Field:        m.Field,
+		Before:       before,
+		After:        after,
+		Studio:       m.Studio,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}, nil
+}
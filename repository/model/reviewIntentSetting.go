@@ -0,0 +1,52 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type Intents []string
+
+func (Intents) GormDataType() string {
+	return "json"
+}
+
+func (i Intents) Value() (driver.Value, error) {
+	return json.Marshal(i)
+}
+
+func (i *Intents) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan Intents: %v", value)
+	}
+	return json.Unmarshal(bytes, i)
+}
+
+type ReviewIntentSetting struct {
+	Project         string  `gorm:"size:30;not null;uniqueIndex:uix_review_intent_setting_1"`
+	ExcludedIntents Intents `gorm:"not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ReviewIntentSetting) Entity() *entity.ReviewIntentSetting {
+	intents := []string(m.ExcludedIntents)
+	if intents == nil {
+		intents = []string{}
+	}
+	return &entity.ReviewIntentSetting{
+		Project:         m.Project,
+		ExcludedIntents: intents,
+		ModifiedAtUTC:   m.ModifiedAtUTC,
+		ModifiedBy:      m.ModifiedBy,
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// ReviewLatest is a copy of the latest review of an asset or shot per relation, phase and
+// intent, maintained on write by the ReviewInfo repository. ID is the ID of the review, and
+// PhaseLatest marks the latest review of the phase over all the intents.
+type ReviewLatest struct {
+	Project           string    `gorm:"size:30;not null;uniqueIndex:uix_review_latest_1,priority:1;index:ix_review_latest_1,priority:1"`
+	Root              string    `gorm:"size:30;not null;uniqueIndex:uix_review_latest_1,priority:2;index:ix_review_latest_1,priority:2"`
+	Group1            string    `gorm:"column:group_1;size:255;not null;uniqueIndex:uix_review_latest_1,priority:3"`
+	Relation          string    `gorm:"size:100;not null;uniqueIndex:uix_review_latest_1,priority:4"`
+	Phase             string    `gorm:"size:100;not null;uniqueIndex:uix_review_latest_1,priority:5"`
+	Intent            string    `gorm:"size:10;not null;uniqueIndex:uix_review_latest_1,priority:6"`
+	PhaseLatest       bool      `gorm:"not null;index:ix_review_latest_1,priority:3"`
+	Take              string    `gorm:"size:30;not null"`
+	TakeNumber        *uint32   ``
+	ApprovalStatus    string    `gorm:"size:20;not null"`
+	WorkStatus        string    `gorm:"size:20;not null"`
+	SubmittedAtUTC    time.Time `gorm:"column:submitted_at_utc;type:datetime(6) not null"`
+	ModifiedAtUTC     time.Time `gorm:"column:modified_at_utc;type:datetime(6) not null"`
+	LeafGroupName     *string   `gorm:"size:255"`
+	GroupCategoryPath *string   `gorm:"size:1000"`
+	TopGroupNode      *string   `gorm:"size:255"`
+	// PublishTransactionID is the log ID of the publish transaction linked to the review.
+	PublishTransactionID *string `gorm:"size:36"`
+	ID                   int32   `gorm:"primaryKey;autoIncrement:false;not null"`
+}
+
+// ReviewLatestState records when the t_review_latest rows of a project were last rebuilt.
+// Listings only read them once they were.
+type ReviewLatestState struct {
+	Project      string    `gorm:"size:30;not null;uniqueIndex:uix_review_latest_state_1"`
+	Rows         int64     `gorm:"not null"`
+	RebuiltAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	RebuiltBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ReviewLatestState) Entity() *entity.ReviewLatestState {
+	return &entity.ReviewLatestState{
+		Project:      m.Project,
+		Rows:         m.Rows,
+		RebuiltAtUTC: m.RebuiltAtUTC,
+		RebuiltBy:    m.RebuiltBy,
+	}
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type MailAddresses []string
+
+func (MailAddresses) GormDataType() string {
+	return "json"
+}
+
+func (a MailAddresses) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+func (a *MailAddresses) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan MailAddresses: %v", value)
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+type ReviewSLA struct {
+	Project             string        `gorm:"size:30;not null;uniqueIndex:uix_review_sla_1,priority:1"`
+	Phase               string        `gorm:"size:30;not null;uniqueIndex:uix_review_sla_1,priority:2"`
+	FeedbackWithinHours int32         `gorm:"not null"`
+	MailAddresses       MailAddresses `gorm:"not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;uniqueIndex:uix_review_sla_1,priority:3"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ReviewSLA) Entity() *entity.ReviewSLA {
+	addresses := []string(m.MailAddresses)
+	if addresses == nil {
+		addresses = []string{}
+	}
+	return &entity.ReviewSLA{
+		Project:             m.Project,
+		Phase:               m.Phase,
+		FeedbackWithinHours: m.FeedbackWithinHours,
+		MailAddresses:       addresses,
+		CreatedAtUTC:        m.CreatedAtUTC,
+		ModifiedAtUTC:       m.ModifiedAtUTC,
+		ModifiedBy:          m.ModifiedBy,
+		CreatedBy:           m.CreatedBy,
+		ID:                  m.ID,
+	}
+}
+
+type ReviewSLABreach struct {
+	Project        string     `gorm:"size:30;not null;index:ix_review_sla_breach_1"`
+	ReviewInfoID   int32      `gorm:"not null;uniqueIndex:uix_review_sla_breach_1"`
+	SLAID          int32      `gorm:"column:sla_id;not null"`
+	Root           string     `gorm:"size:30;not null"`
+	Group          string     `gorm:"size:255;not null"`
+	Relation       string     `gorm:"size:100;not null"`
+	Phase          string     `gorm:"size:100;not null;index:ix_review_sla_breach_1"`
+	Take           string     `gorm:"size:30;not null"`
+	SubmittedAtUTC time.Time  `gorm:"type:datetime(6) not null"`
+	DueAtUTC       time.Time  `gorm:"type:datetime(6) not null"`
+	DetectedAtUTC  time.Time  `gorm:"type:datetime(6) not null;index:ix_review_sla_breach_1"`
+	ResolvedAtUTC  *time.Time `gorm:"type:datetime(6)"`
+	ID             int32      `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ReviewSLABreach) Entity() *entity.ReviewSLABreach {
+	return &entity.ReviewSLABreach{
+		Project:        m.Project,
+		ReviewInfoID:   m.ReviewInfoID,
+		Root:           m.Root,
+		Group:          m.Group,
+		Relation:       m.Relation,
+		Phase:          m.Phase,
+		Take:           m.Take,
+		SubmittedAtUTC: m.SubmittedAtUTC,
+		DueAtUTC:       m.DueAtUTC,
+		DetectedAtUTC:  m.DetectedAtUTC,
+		ResolvedAtUTC:  m.ResolvedAtUTC,
+		ID:             m.ID,
+	}
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type TranscodeFrames []string
+
+func (TranscodeFrames) GormDataType() string {
+	return "json"
+}
+
+func (f TranscodeFrames) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+func (f *TranscodeFrames) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan TranscodeFrames: %v", value)
+	}
+	return json.Unmarshal(bytes, f)
+}
+
+type ReviewTranscode struct {
+	Project        string          `gorm:"size:30;not null;index:ix_review_transcode_1,priority:1"`
+	ReviewInfoID   int32           `gorm:"not null;index:ix_review_transcode_1,priority:2"`
+	Source         string          `gorm:"size:1000;not null"`
+	Frames         TranscodeFrames `gorm:"not null"`
+	Output         string          `gorm:"size:1000;not null"`
+	Status         string          `gorm:"size:20;not null;index:ix_review_transcode_2"`
+	Backend        string          `gorm:"size:20;not null"`
+	JobID          *string         `gorm:"size:255"`
+	Error          *string         `gorm:"type:text"`
+	SubmittedAtUTC *time.Time      `gorm:"type:datetime(6)"`
+	CompletedAtUTC *time.Time      `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewReviewTranscode(
+	params *entity.CreateReviewTranscodeParams,
+	source string,
+	frames []string,
+	output string,
+	backend string,
+) *ReviewTranscode {
+	now := time.Now().UTC()
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	return &ReviewTranscode{
+		Project:       params.Project,
+		ReviewInfoID:  params.ReviewInfoID,
+		Source:        source,
+		Frames:        TranscodeFrames(frames),
+		Output:        output,
+		Status:        string(entity.TranscodeQueued),
+		Backend:       backend,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+}
+
+func (m *ReviewTranscode) Entity() *entity.ReviewTranscode {
+	return &entity.ReviewTranscode{
+		Project:        m.Project,
+		ReviewInfoID:   m.ReviewInfoID,
+		Source:         m.Source,
+		Frames:         []string(m.Frames),
+		Output:         m.Output,
+		Status:         entity.TranscodeStatus(m.Status),
+		Backend:        m.Backend,
+		JobID:          m.JobID,
+		Error:          m.Error,
+		SubmittedAtUTC: m.SubmittedAtUTC,
+		CompletedAtUTC: m.CompletedAtUTC,
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+		ModifiedBy:     m.ModifiedBy,
+		CreatedBy:      m.CreatedBy,
+		ID:             m.ID,
+	}
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type Permissions []string
+
+func (Permissions) GormDataType() string {
+	return "json"
+}
+
+func (p Permissions) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *Permissions) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan Permissions: %v", value)
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+type Role struct {
+	Name        string      `gorm:"size:30;not null;uniqueIndex:uix_role_1"`
+	Description string      `gorm:"type:text;not null"`
+	Permissions Permissions `gorm:"not null"`
+	BuiltIn     bool        `gorm:"not null;default:false"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *Role) Entity() *entity.Role {
+	permissions := make([]entity.Permission, len(m.Permissions))
+	for i, p := range m.Permissions {
+		permissions[i] = entity.Permission(p)
+	}
+	return &entity.Role{
+		Name:          m.Name,
+		Description:   m.Description,
+		Permissions:   permissions,
+		BuiltIn:       m.BuiltIn,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+	}
+}
+
+type UserRole struct {
+	Project string `gorm:"size:30;not null;uniqueIndex:uix_user_role_1"`
+	User    string `gorm:"size:100;not null;uniqueIndex:uix_user_role_1"`
+	Role    string `gorm:"size:30;not null;uniqueIndex:uix_user_role_1;index:ix_user_role_1"`
+	// ExpiresAtUTC ends the assignment, e.g. of a freelancer, when set.
+	ExpiresAtUTC *time.Time `gorm:"type:datetime(6)"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewUserRole(params *entity.CreateUserRoleParams) *UserRole {
+	return &UserRole{
+		Project:      params.Project,
+		User:         params.User,
+		Role:         params.Role,
+		ExpiresAtUTC: params.ExpiresAtUTC,
+		CreatedAtUTC: time.Now().UTC(),
+		CreatedBy:    params.CreatedBy,
+	}
+}
+
+func (m *UserRole) Entity() *entity.UserRole {
+	return &entity.UserRole{
+		Project:      m.Project,
+		User:         m.User,
+		Role:         m.Role,
+		ExpiresAtUTC: m.ExpiresAtUTC,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type SettingChangeset struct {
+	SectionType     string     `gorm:"size:20;not null;index:ix_setting_changeset_1"`
+	SectionName     string     `gorm:"size:50;not null;index:ix_setting_changeset_1"`
+	Title           string     `gorm:"size:255;not null"`
+	Description     string     `gorm:"type:text"`
+	Status          string     `gorm:"size:20;not null;index:ix_setting_changeset_2"`
+	PublishAtUTC    *time.Time `gorm:"type:datetime(6);index:ix_setting_changeset_2"`
+	PublishedAtUTC  *time.Time `gorm:"type:datetime(6)"`
+	PublishedBy     *string    `gorm:"size:100"`
+	RolledBackAtUTC *time.Time `gorm:"type:datetime(6)"`
+	RolledBackBy    *string    `gorm:"size:100"`
+
+	CreatedAtUTC  *time.Time `gorm:"type:datetime(6)"`
+	ModifiedAtUTC *time.Time `gorm:"type:datetime(6)"`
+	ModifiedBy    *string    `gorm:"size:100"`
+	CreatedBy     *string    `gorm:"size:100"`
+	Deleted       int32      `gorm:"not null;default:0;index:ix_setting_changeset_1"`
+	ID            int32      `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewSettingChangeset(params *entity.CreateSettingChangesetParams) *SettingChangeset {
+	now := time.Now().UTC()
+	return &SettingChangeset{
+		SectionType:   params.Section().String(),
+		SectionName:   params.Name(),
+		Title:         params.Title,
+		Description:   params.Description,
+		Status:        string(entity.SettingChangesetDraft),
+		CreatedAtUTC:  &now,
+		ModifiedAtUTC: &now,
+		ModifiedBy:    params.CreatedBy,
+		CreatedBy:     params.CreatedBy,
+	}
+}
+
+func (m *SettingChangeset) Entity() *entity.SettingChangeset {
+	return &entity.SettingChangeset{
+		ID:              m.ID,
+		Section:         m.SectionType,
+		Entry:           m.SectionName,
+		Title:           m.Title,
+		Description:     m.Description,
+		Status:          entity.SettingChangesetStatus(m.Status),
+		PublishAtUTC:    m.PublishAtUTC,
+		PublishedAtUTC:  m.PublishedAtUTC,
+		PublishedBy:     m.PublishedBy,
+		RolledBackAtUTC: m.RolledBackAtUTC,
+		RolledBackBy:    m.RolledBackBy,
+		CreatedBy:       m.CreatedBy,
+		CreatedAtUTC:    m.CreatedAtUTC,
+		ModifiedBy:      m.ModifiedBy,
+		ModifiedAtUTC:   m.ModifiedAtUTC,
+	}
+}
+
+// SettingChange holds the values as JSON text, sealed like the live values when the property
+// is encrypted.
+type SettingChange struct {
+	ChangesetID    int32   `gorm:"not null;uniqueIndex:uix_setting_change_1"`
+	Group          string  `gorm:"size:20;not null;uniqueIndex:uix_setting_change_1"`
+	Key            string  `gorm:"size:255;not null;uniqueIndex:uix_setting_change_1"`
+	Operation      string  `gorm:"size:10;not null"`
+	Value          *string `gorm:"type:text"`
+	PreviousValue  *string `gorm:"type:text"`
+	PreviousExists bool    `gorm:"not null;default:false"`
+
+	CreatedAtUTC  *time.Time `gorm:"type:datetime(6)"`
+	ModifiedAtUTC *time.Time `gorm:"type:datetime(6)"`
+	ModifiedBy    *string    `gorm:"size:100"`
+	CreatedBy     *string    `gorm:"size:100"`
+	ID            int32      `gorm:"primaryKey;autoIncrement;not null"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type StatusMapping struct {
+	Project     string `gorm:"size:30;not null;uniqueIndex:uix_status_mapping_1,priority:1"`
+	Kind        string `gorm:"size:20;not null;uniqueIndex:uix_status_mapping_1,priority:2"`
+	LegacyValue string `gorm:"size:20;not null;uniqueIndex:uix_status_mapping_1,priority:3"`
+	Value       string `gorm:"size:20;not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;uniqueIndex:uix_status_mapping_1,priority:4"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *StatusMapping) Entity() *entity.StatusMapping {
+	return &entity.StatusMapping{
+		Project:       m.Project,
+		Kind:          m.Kind,
+		LegacyValue:   m.LegacyValue,
+		Value:         m.Value,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+}
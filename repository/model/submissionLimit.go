@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type SubmissionLimit struct {
+	Project        string `gorm:"size:30;not null;uniqueIndex:uix_submission_limit_1,priority:1"`
+	MaxSubmissions int32  `gorm:"not null"`
+	WindowMinutes  int32  `gorm:"not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;uniqueIndex:uix_submission_limit_1,priority:2"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *SubmissionLimit) Entity() *entity.SubmissionLimit {
+	return &entity.SubmissionLimit{
+		Project:        m.Project,
+		MaxSubmissions: m.MaxSubmissions,
+		WindowMinutes:  m.WindowMinutes,
+		Overrides:      []*entity.SubmissionLimitOverride{},
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+		ModifiedBy:     m.ModifiedBy,
+		CreatedBy:      m.CreatedBy,
+		ID:             m.ID,
+	}
+}
+
+// SubmissionLimitOverride lifts the submission limit of the project, or of the assets of
+// the first group Asset when it is set, until ExpiresAtUTC.
+type SubmissionLimitOverride struct {
+	Project      string    `gorm:"size:30;not null;index:ix_submission_limit_override_1,priority:1"`
+	Asset        *string   `gorm:"size:255"`
+	Reason       string    `gorm:"size:1000;not null"`
+	ExpiresAtUTC time.Time `gorm:"type:datetime(6) not null;index:ix_submission_limit_override_1,priority:3"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;index:ix_submission_limit_override_1,priority:2"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *SubmissionLimitOverride) Entity() *entity.SubmissionLimitOverride {
+	return &entity.SubmissionLimitOverride{
+		Project:      m.Project,
+		Asset:        m.Asset,
+		Reason:       m.Reason,
+		ExpiresAtUTC: m.ExpiresAtUTC,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type Tag struct {
+	Project string `gorm:"size:30;not null;uniqueIndex:uix_tag_1,priority:1"`
+	Name    string `gorm:"size:50;not null;uniqueIndex:uix_tag_1,priority:2"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;default:0;uniqueIndex:uix_tag_1,priority:3"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewTag(project, name, createdBy string) *Tag {
+	now := time.Now().UTC()
+	return &Tag{
+		Project:       project,
+		Name:          name,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+}
+
+func (m *Tag) Entity(numTargets int) *entity.Tag {
+	return &entity.Tag{
+		Project:       m.Project,
+		Name:          m.Name,
+		NumTargets:    numTargets,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+}
+
+// TagAssignment attaches a tag to an asset, shot or review. Assignments are deleted on detach.
+type TagAssignment struct {
+	Project    string `gorm:"size:30;not null;index:ix_tag_assignment_1,priority:1"`
+	TagID      int32  `gorm:"not null;uniqueIndex:uix_tag_assignment_1,priority:1"`
+	TargetType string `gorm:"size:10;not null;uniqueIndex:uix_tag_assignment_1,priority:2;index:ix_tag_assignment_1,priority:2"`
+	Target     string `gorm:"size:500;not null;uniqueIndex:uix_tag_assignment_1,priority:3;index:ix_tag_assignment_1,priority:3"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
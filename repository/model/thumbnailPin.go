@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type ThumbnailPin struct {
+	Project     string    `gorm:"size:30;not null;uniqueIndex:ix_thumbnail_pin_1,priority:1"`
+	Asset       string    `gorm:"size:100;not null;uniqueIndex:ix_thumbnail_pin_1,priority:2"`
+	Relation    string    `gorm:"size:30;not null;uniqueIndex:ix_thumbnail_pin_1,priority:3"`
+	Phase       string    `gorm:"size:20;not null"`
+	Revision    string    `gorm:"size:50;not null"`
+	Name        string    `gorm:"size:30;not null"`
+	PinnedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	PinnedBy    string    `gorm:"size:100;not null"`
+	ID          int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *ThumbnailPin) Entity() *entity.ThumbnailPin {
+	return &entity.ThumbnailPin{
+		Project:     m.Project,
+		Asset:       m.Asset,
+		Relation:    m.Relation,
+		Phase:       m.Phase,
+		Revision:    m.Revision,
+		Name:        m.Name,
+		PinnedAtUTC: m.PinnedAtUTC,
+		PinnedBy:    m.PinnedBy,
+		ID:          m.ID,
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// TransferAuditLog records the transfers to studios which are not tracked by other tables.
+type TransferAuditLog struct {
+	Project string `gorm:"size:30;not null;index:ix_transfer_audit_log_1,priority:1"`
+	Studio  string `gorm:"size:30;not null"`
+	Kind    string `gorm:"size:30;not null"`
+	Target  string `gorm:"size:255;not null"`
+	Detail  string `gorm:"size:255;not null"`
+
+	CreatedAtUTC time.Time `gorm:"type:datetime(6) not null;index:ix_transfer_audit_log_1,priority:2"`
+	CreatedBy    string    `gorm:"size:100;not null"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewTransferAuditLog(params *entity.RecordTransferParams) *TransferAuditLog {
+	return &TransferAuditLog{
+		Project:      params.Project,
+		Studio:       params.Studio,
+		Kind:         params.Kind,
+		Target:       params.Target,
+		Detail:       params.Detail,
+		CreatedAtUTC: time.Now().UTC(),
+		CreatedBy:    params.CreatedBy,
+	}
+}
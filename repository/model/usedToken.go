@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// UsedToken records the ID (jti) of a token consumed through the query parameter, so that the
+// same token cannot be replayed. Rows are purged once the token is out of its replay window.
+type UsedToken struct {
+	JTI    string `gorm:"column:jti;size:64;not null;uniqueIndex:uix_used_token_1"`
+	Studio string `gorm:"size:30;not null"`
+
+	UsedAtUTC    time.Time `gorm:"type:datetime(6) not null"`
+	ExpiresAtUTC time.Time `gorm:"type:datetime(6) not null;index:ix_used_token_1"`
+	ID           int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewUsedToken(jti, studio string, expiresAt time.Time) *UsedToken {
+	return &UsedToken{
+		JTI:          jti,
+		Studio:       studio,
+		UsedAtUTC:    time.Now().UTC(),
+		ExpiresAtUTC: expiresAt.UTC(),
+	}
+}
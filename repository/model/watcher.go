@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// Watcher is soft deleted on unwatch, so that the user is not made to watch the target again
+// automatically.
+type Watcher struct {
+	Project     string  `gorm:"size:30;not null;uniqueIndex:uix_watcher_1,priority:1;index:ix_watcher_1,priority:1"`
+	TargetType  string  `gorm:"size:10;not null;uniqueIndex:uix_watcher_1,priority:2"`
+	Target      string  `gorm:"size:500;not null;uniqueIndex:uix_watcher_1,priority:3"`
+	User        string  `gorm:"size:100;not null;uniqueIndex:uix_watcher_1,priority:4;index:ix_watcher_1,priority:2"`
+	MailAddress *string `gorm:"size:254"`
+	Reason      string  `gorm:"size:20;not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;default:0;uniqueIndex:uix_watcher_1,priority:5"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *Watcher) Entity() *entity.Watcher {
+	return &entity.Watcher{
+		Project:      m.Project,
+		TargetType:   m.TargetType,
+		Target:       m.Target,
+		User:         m.User,
+		MailAddress:  m.MailAddress,
+		Reason:       m.Reason,
+		CreatedAtUTC: m.CreatedAtUTC,
+		CreatedBy:    m.CreatedBy,
+		ID:           m.ID,
+	}
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type WebhookEvents []entity.WebhookEvent
+
+func (WebhookEvents) GormDataType() string {
+	return "json"
+}
+
+func (e WebhookEvents) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+func (e *WebhookEvents) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan WebhookEvents: %v", value)
+	}
+	return json.Unmarshal(bytes, e)
+}
+
+// Has tells whether the webhook subscribes to the event.
+func (e WebhookEvents) Has(event entity.WebhookEvent) bool {
+	for _, v := range e {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
+
+type Webhook struct {
+	Project string        `gorm:"size:30;not null;index:ix_webhook_1"`
+	URL     string        `gorm:"size:500;not null"`
+	Events  WebhookEvents `gorm:"not null"`
+	Active  bool          `gorm:"not null"`
+	// Secret is kept in plain text since the bodies are signed with it.
+	Secret string `gorm:"size:64;not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func NewWebhook(params *entity.CreateWebhookParams, secret string) *Webhook {
+	now := time.Now().UTC()
+	active := true
+	if params.Active != nil {
+		active = *params.Active
+	}
+	return &Webhook{
+		Project:       params.Project,
+		URL:           params.URL,
+		Events:        params.Events,
+		Active:        active,
+		Secret:        secret,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    params.CreatedBy,
+		CreatedBy:     params.CreatedBy,
+	}
+}
+
+// Entity returns the webhook without its secret.
+func (m *Webhook) Entity() *entity.Webhook {
+	return &entity.Webhook{
+		Project:       m.Project,
+		URL:           m.URL,
+		Events:        m.Events,
+		Active:        m.Active,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+}
+
+type WebhookDelivery struct {
+	WebhookID      int32     `gorm:"not null;index:ix_webhook_delivery_1"`
+	DeliveryID     string    `gorm:"size:36;not null"`
+	Event          string    `gorm:"size:50;not null"`
+	Attempt        uint32    `gorm:"not null"`
+	StatusCode     *int      `gorm:""`
+	Error          string    `gorm:"type:text"`
+	DurationMS     int64     `gorm:"not null"`
+	DeliveredAtUTC time.Time `gorm:"type:datetime(6);not null"`
+	ID             int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *WebhookDelivery) Entity() *entity.WebhookDelivery {
+	return &entity.WebhookDelivery{
+		WebhookID:      m.WebhookID,
+		DeliveryID:     m.DeliveryID,
+		Event:          entity.WebhookEvent(m.Event),
+		Attempt:        m.Attempt,
+		StatusCode:     m.StatusCode,
+		Error:          m.Error,
+		DurationMS:     m.DurationMS,
+		DeliveredAtUTC: m.DeliveredAtUTC,
+		ID:             m.ID,
+	}
+}
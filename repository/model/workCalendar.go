@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+type WorkCalendar struct {
+	Studio   string `gorm:"size:30;not null;uniqueIndex:uix_work_calendar_1,priority:1"`
+	TimeZone string `gorm:"size:64;not null"`
+	Weekends JSON   `gorm:"not null"`
+	Holidays JSON   `gorm:"not null"`
+	Shifts   JSON   `gorm:"not null"`
+
+	CreatedAtUTC  time.Time `gorm:"type:datetime(6) not null"`
+	ModifiedAtUTC time.Time `gorm:"type:datetime(6) not null"`
+	Deleted       int32     `gorm:"not null;uniqueIndex:uix_work_calendar_1,priority:2"`
+	ModifiedBy    string    `gorm:"size:100;not null"`
+	CreatedBy     string    `gorm:"size:100;not null"`
+	ID            int32     `gorm:"primaryKey;autoIncrement;not null"`
+}
+
+func (m *WorkCalendar) Entity() (*entity.WorkCalendar, error) {
+	e := &entity.WorkCalendar{
+		Studio:        m.Studio,
+		TimeZone:      m.TimeZone,
+		CreatedAtUTC:  m.CreatedAtUTC,
+		ModifiedAtUTC: m.ModifiedAtUTC,
+		ModifiedBy:    m.ModifiedBy,
+		CreatedBy:     m.CreatedBy,
+		ID:            m.ID,
+	}
+	if err := json.Unmarshal(m.Weekends, &e.Weekends); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(m.Holidays, &e.Holidays); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(m.Shifts, &e.Shifts); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,1030 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	notif "github.com/PolygonPictures/central30-web/front/repository/notification"
+	"google.golang.org/api/chat/v1"
+	"gorm.io/gorm"
+)
+
+type Notification struct {
+	db              *gorm.DB
+	settingRepo     *PipelineSetting
+	projectWebhooks string
+	systemWebhook   string
+}
+
+func NewNotification(
+	db *gorm.DB,
+	settingRepo *PipelineSetting,
+) (*Notification, error) {
+	projectWebhooks := os.Getenv("PPIP30_GOOGLECHAT_WEBHOOK_PROJECT")
+	if projectWebhooks == "" {
+		slog.Warn("projectWebhook is not set")
+	}
+	systemWebhook := os.Getenv("PPIP30_GOOGLECHAT_WEBHOOK_SYSTEM")
+	if systemWebhook == "" {
+		slog.Warn("systemWebhook is not set")
+	}
+	return &Notification{
+		db:              db,
+		settingRepo:     settingRepo,
+		projectWebhooks: projectWebhooks,
+		systemWebhook:   systemWebhook,
+	}, nil
+}
+
+func (r *Notification) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Notification) SendPublishNotification(
+	db *gorm.DB,
+	lgr entity.Logger,
+	e *entity.PublishTransactionInfoNotification,
+) error {
+	issendemailinfo, ok := e.AdditionalInfo["is_send_email"]
+	// success without sending email
+	if !ok {
+		return fmt.Errorf("%w: [EmailSender] flag `is_send_email` is not found", entity.ErrNotificationSkipped)
+	}
+
+	issendemail, ok := issendemailinfo.(bool)
+	if !ok {
+		return errors.New("[EmailSender] failed to convert Flag `is_send_email` into bool")
+	}
+	if !issendemail {
+		return fmt.Errorf("%w: [EmailSender] flag `is_send_email` is false", entity.ErrNotificationSkipped)
+	}
+
+	// success without sending email
+	if e.Operation == "download" ||
+		e.Operation == "upload" && e.Event == "completed" ||
+		e.Event == "start" ||
+		*e.Component == "_org" ||
+		*e.Component == "_tmb" {
+		return fmt.Errorf("%w: [EmailSender] untargetted Operation/Event/Component", entity.ErrNotificationSkipped)
+	}
+
+	componentType := "components"
+	if *e.Component == "_raw" {
+		componentType = "preComponents"
+	}
+	if *e.Component == "_review" {
+		componentType = "postComponents"
+	}
+
+	// send email on publishing as notification
+	shortpath := strings.Join(strings.Split(e.RevisionPath, "/")[2:], "/")
+	user := e.User
+	computer := e.Computer
+	toolname := e.ToolName
+	toolversion := e.ToolVersion
+	publishedtime := e.PublishedTime
+	displaynamekey := "displayName"
+	studio := e.Studio
+	componentkey := fmt.Sprintf(
+		"/ppip/roots/%s/phases/%s/%s/%s/emailAddress",
+		*e.Root,
+		*e.Phase,
+		componentType,
+		*e.Component,
+	)
+	setting, err := r.getPipelineSettingValue(
+		db,
+		entity.Preference,
+		nil,
+		nil,
+		&e.Project,
+		componentkey,
+	)
+	if setting == nil {
+		return fmt.Errorf(
+			"[EmailSender] email address is not set to pipeline setting: %s",
+			componentkey,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("[EmailSender] error in fetching email pipeliine setting: %e", err)
+	}
+	studiosetting, err := r.getPipelineSettingValue(
+		db,
+		entity.Config,
+		nil,
+		&studio,
+		nil,
+		displaynamekey,
+	)
+	if err != nil {
+		lgr.Warn("[EmailSender] error in finding studio setting: " + err.Error())
+	}
+	if studiosetting == nil {
+		msg := fmt.Sprintf(
+			"[EmailSender] failed to fetch studio pipeline setting: %s",
+			displaynamekey,
+		)
+		lgr.Warn(msg)
+	} else {
+		studiodispname, ok := studiosetting.(string)
+		if !ok {
+			msg := fmt.Sprintf(
+				"[EmailSender] failed to convert pipeline setting into string: %s\n",
+				displaynamekey,
+			)
+			lgr.Warn(msg)
+		} else {
+			studio = studiodispname
+		}
+	}
+	project := e.Project
+	projectsetting, err := r.getPipelineSettingValue(
+		db,
+		entity.Config,
+		nil,
+		nil,
+		&project,
+		displaynamekey,
+	)
+	if err != nil {
+		lgr.Warn("[EmailSender] error in finding project setting: " + err.Error())
+	}
+	if projectsetting == nil {
+		msg := fmt.Sprintf(
+			"[EmailSender] failed to fetch project pipeline setting: %s",
+			displaynamekey,
+		)
+		lgr.Warn(msg)
+	} else {
+		projectdispname, ok := projectsetting.(string)
+		if !ok {
+			msg := fmt.Sprintf(
+				"[EmailSender] failed to convert pipeline setting into string: %s\n",
+				displaynamekey,
+			)
+			lgr.Warn(msg)
+		} else {
+			project = projectdispname
+		}
+	}
+	operation := e.Operation
+	if *e.Component == "_raw" {
+		operation = "pre-publish"
+	}
+	subject := fmt.Sprintf(
+		"[%s][%s] %s %s: %s",
+		project,
+		studio,
+		operation,
+		e.Event,
+		shortpath,
+	)
+	bodydata := entity.PublishNotificationTemplateData{
+		StudioDisplayName:  studio,
+		StudioKeyName:      e.Studio,
+		ProjectDisplayName: project,
+		ProjectKeyName:     e.Project,
+		RevisionPath:       shortpath,
+		TaskID:             e.TaskID,
+		SubTaskID:          e.SubtaskID,
+		User:               user,
+		Computer:           computer,
+		ToolName:           toolname,
+		ToolVersion:        toolversion,
+	}
+	if e.PublishedTime != nil {
+		strdate := fmt.Sprint(publishedtime)
+		timezonekey := "timezone"
+		setting, err := r.getPipelineSettingValue(
+			db,
+			entity.Config,
+			nil,
+			&e.Studio,
+			nil,
+			timezonekey,
+		)
+		if err != nil || setting == nil {
+			lgr.Info("[EmailSender] timezone is not found: " + err.Error())
+		} else {
+			timezone := setting.(string)
+			loc, err := time.LoadLocation(timezone)
+			if err != nil {
+				lgr.Warn("[EmailSender] error in loading location by timezone: " + err.Error())
+			} else {
+				strdate = fmt.Sprint(publishedtime.In(loc))
+			}
+		}
+		bodydata.PublishedTime = &strdate
+	}
+	toaddr := setting.([]string)
+	serveraddr := "10.1.10.5:25"
+	entry := "default"
+	rawConfig, err := r.getPipelineSettingValue(
+		db,
+		entity.Config,
+		&entry,
+		nil,
+		nil,
+		"mailServerAddress",
+	)
+	if err == nil && rawConfig != nil {
+		if strConfig, ok := rawConfig.(string); ok {
+			serveraddr = strConfig
+		}
+	}
+	sendername := os.Getenv("PPI_EMAIL_SENDER_NAME")
+	if sendername == "" {
+		sendername = "noreply@ppi.co.jp"
+	}
+	senderaddr := os.Getenv("PPI_EMAIL_SENDER_ADDRESS")
+	if senderaddr == "" {
+		senderaddr = "noreply@ppi.co.jp"
+	}
+	sender := mail.Address{
+		Name:    sendername,
+		Address: senderaddr,
+	}
+	if e.Event == "failed" {
+		bodydata.StackTrace = e.StackTrace
+		// Currently hard coding, gonna fetch from default later
+		toaddr = append(toaddr, "pipeline30@ppi.co.jp")
+	}
+	t, err := template.ParseFiles("template/publishEmail.html")
+	if err != nil {
+		return errors.New("[EmailSender] failed to parse html template")
+	}
+	var bodybytes bytes.Buffer
+	if err := t.Execute(&bodybytes, bodydata); err != nil {
+		return errors.New("[EmailSender] failed to apply a parsed template")
+	}
+
+	if err := notif.CheckPublishEmailDomainRestriction(toaddr, e.Project); err != nil {
+		return fmt.Errorf("[EmailSender] failed to send publish notification: %s", err.Error())
+	}
+
+	maildata := buildEmail(&entity.EmailData{
+		Sender:  sender.Address,
+		To:      toaddr,
+		Subject: subject,
+		Body:    bodybytes.String(),
+	})
+	// the email is sent synchronously so that the outbox dispatcher can retry on failure
+	if err := r.sendEmail(&entity.EmailSenderInfo{
+		Server:  serveraddr,
+		Subject: subject,
+		Sender:  &sender,
+		To:      toaddr,
+		Cc:      nil,
+		Message: maildata,
+	}); err != nil {
+		return fmt.Errorf("[EmailSender] failed to send publish notification: %w", err)
+	}
+	message := chat.Message{
+		Cards: []*chat.Card{
+			{
+				Header: &chat.CardHeader{
+					Title:    fmt.Sprintf("%s: %s", operation, e.Event),
+					Subtitle: fmt.Sprintf("%s (%s)", studio, e.Studio),
+				},
+				Sections: []*chat.Section{
+					{
+						Widgets: []*chat.WidgetMarkup{
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Revision Path",
+									Content:          e.RevisionPath,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Task ID",
+									Content:          e.TaskID,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Subtask ID",
+									Content:          e.SubtaskID,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "User",
+									Content:          *user,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Computer",
+									Content:          *computer,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Tool Name",
+									Content:          *toolname,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Tool Version",
+									Content:          *toolversion,
+									ContentMultiline: true,
+								},
+							},
+							{
+								Buttons: []*chat.Button{
+									{
+										TextButton: &chat.TextButton{
+											Text: "Button to Do Something",
+											OnClick: &chat.OnClick{
+												OpenLink: &chat.OpenLink{
+													Url: "https://pkg.go.dev/google.golang.org/api@v0.33.0/chat/v1#OnClick",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	go r.sendChatMessage(&entity.ChatMessageSenderInfo{
+		Webhook: r.projectWebhook(e.Project),
+		Message: &message,
+	})
+	return nil
+}
+
+// projectWebhook returns the chat webhook of the project, set in PPIP30_GOOGLECHAT_WEBHOOK_PROJECT
+// as comma-separated <project>@<webhook URL> pairs.
+func (r *Notification) projectWebhook(project string) string {
+	for _, projectWebhook := range strings.Split(r.projectWebhooks, ",") {
+		webhookInfo := strings.Split(projectWebhook, "@")
+		if webhookInfo[0] == project && len(webhookInfo) > 1 {
+			return webhookInfo[1]
+		}
+	}
+	return ""
+}
+
+// SendPropagationOverdueNotification posts the overdue propagations to the chat of the project.
+func (r *Notification) SendPropagationOverdueNotification(
+	e *entity.PropagationOverdueNotification,
+) error {
+	webhookURL := r.projectWebhook(e.Project)
+	if webhookURL == "" {
+		return fmt.Errorf(
+			"%w: no chat webhook is set for project %s", entity.ErrNotificationSkipped, e.Project,
+		)
+	}
+	var widgets []*chat.WidgetMarkup
+	for _, p := range e.Propagations {
+		widgets = append(widgets, &chat.WidgetMarkup{
+			KeyValue: &chat.KeyValue{
+				TopLabel: fmt.Sprintf(
+					"%s -> %s (published %s)",
+					p.OriginStudio, p.Studio, p.PublishedAtUTC.Format(time.RFC3339),
+				),
+				Content:          p.RevisionPath,
+				ContentMultiline: true,
+			},
+		})
+	}
+	message := &chat.Message{
+		Cards: []*chat.Card{
+			{
+				Header: &chat.CardHeader{
+					Title: fmt.Sprintf(
+						"[%s] %d publishes not synced within %s",
+						e.Project, len(e.Propagations), e.Deadline,
+					),
+				},
+				Sections: []*chat.Section{
+					{
+						Widgets: widgets,
+					},
+				},
+			},
+		},
+	}
+	go r.sendChatMessage(&entity.ChatMessageSenderInfo{
+		Webhook: webhookURL,
+		Message: message,
+	})
+	return nil
+}
+
+// SendSettingChangesetNotification posts the published or rolled back changeset to the chat of
+// the project. Changesets of common and studio sections are not posted.
+func (r *Notification) SendSettingChangesetNotification(
+	e *entity.SettingChangesetNotification,
+) error {
+	if e.Section != entity.ProjectSection.String() {
+		return fmt.Errorf(
+			"%w: changeset %d is not of a project", entity.ErrNotificationSkipped, e.ChangesetID,
+		)
+	}
+	webhookURL := r.projectWebhook(e.Entry)
+	if webhookURL == "" {
+		return fmt.Errorf(
+			"%w: no chat webhook is set for project %s", entity.ErrNotificationSkipped, e.Entry,
+		)
+	}
+	var widgets []*chat.WidgetMarkup
+	for _, c := range e.Changes {
+		widgets = append(widgets, &chat.WidgetMarkup{
+			KeyValue: &chat.KeyValue{
+				TopLabel: fmt.Sprintf("%s (%s)", c.Group, c.Operation),
+				Content:  c.Key,
+			},
+		})
+	}
+	message := &chat.Message{
+		Cards: []*chat.Card{
+			{
+				Header: &chat.CardHeader{
+					Title: fmt.Sprintf(
+						"[%s] settings %s by %s: %s",
+						e.Entry, e.Status, e.Actor, e.Title,
+					),
+				},
+				Sections: []*chat.Section{
+					{
+						Widgets: widgets,
+					},
+				},
+			},
+		},
+	}
+	go r.sendChatMessage(&entity.ChatMessageSenderInfo{
+		Webhook: webhookURL,
+		Message: message,
+	})
+	return nil
+}
+
+func (r *Notification) SendReviewStatusNotification(
+	db *gorm.DB,
+	e *entity.ReviewStatusLogNotification,
+) error {
+	projectDisplayName := e.Project
+	displayNameKey := "displayName"
+	rawConfig, err := r.getPipelineSettingValue(
+		db,
+		entity.Config,
+		nil,
+		nil,
+		&e.Project,
+		displayNameKey,
+	)
+	if err != nil {
+		log.Println("[EmailSender] Error in finding project setting: " + err.Error())
+	}
+	if rawConfig == nil {
+		log.Printf(
+			"[EmailSender] Failed to fetch project pipeline setting: %s",
+			displayNameKey,
+		)
+	} else {
+		config, ok := rawConfig.(string)
+		if !ok {
+			log.Printf(
+				"[EmailSender] Failed to convert pipeline setting into string: %s\n",
+				displayNameKey,
+			)
+		} else {
+			projectDisplayName = config
+		}
+	}
+	toEmails := e.MailAddresses
+	ccEmails := e.MailCCAddresses
+	subject := fmt.Sprintf("[%s (%s)] %s", projectDisplayName, e.Project, e.MailSubject)
+	metaParams := &entity.MetaParams{
+		Studio:        e.Studio,
+		Project:       fmt.Sprintf("%s (%s)", projectDisplayName, e.Project),
+		UpdateID:      e.UpdateID,
+		ModifiedAtUTC: e.ModifiedAtUTC,
+		ModifiedBy:    e.ModifiedBy,
+		TakeList:      e.TakeList,
+		RelationList:  e.RelationList,
+		PhaseList:     e.PhaseList,
+		EmailToList:   toEmails,
+		EmailCcList:   ccEmails,
+	}
+	langs := make([]string, 0, len(*e.LangSet))
+	for lang := range *e.LangSet {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	t, err := template.ParseFiles("template/reviewEmail2.html")
+	if err != nil {
+		return fmt.Errorf("[EmailSender] failed to parse html template: %w", err)
+	}
+	var bodybytes bytes.Buffer
+	bodydata := entity.ReviewEmailTemplateData{
+		Message: e.MailComment,
+		Items:   e.TableParamsList,
+		Langs:   langs,
+	}
+	if err := t.Execute(&bodybytes, bodydata); err != nil {
+		return fmt.Errorf("[EmailSender] failed to apply a parsed template: %w", err)
+	}
+	serveraddr := "10.1.10.5:25"
+	entry := "default"
+	rawConfig, err = r.getPipelineSettingValue(
+		db,
+		entity.Config,
+		&entry,
+		nil,
+		nil,
+		"mailServerAddress",
+	)
+	if err == nil && rawConfig != nil {
+		if strConfig, ok := rawConfig.(string); ok {
+			serveraddr = strConfig
+		}
+	}
+	sendername := os.Getenv("PPI_EMAIL_SENDER_NAME")
+	if sendername == "" {
+		sendername = "noreply@ppi.co.jp"
+	}
+	senderaddr := os.Getenv("PPI_EMAIL_SENDER_ADDRESS")
+	if senderaddr == "" {
+		senderaddr = "noreply@ppi.co.jp"
+	}
+	maildata := buildEmailWithMetadata(&entity.EmailDataWithMeta{
+		Sender:     senderaddr,
+		To:         toEmails,
+		Cc:         ccEmails,
+		Subject:    subject,
+		Body:       bodybytes.String(),
+		MetaParams: metaParams,
+	})
+	sender := mail.Address{
+		Name:    sendername,
+		Address: senderaddr,
+	}
+	if err := r.sendEmail(&entity.EmailSenderInfo{
+		Server:  serveraddr,
+		Subject: subject,
+		Sender:  &sender,
+		To:      toEmails,
+		Cc:      ccEmails,
+		Message: maildata,
+	}); err != nil {
+		return fmt.Errorf("[EmailSender] failed to send review status notification: %w", err)
+	}
+	return nil
+}
+
+// SendSLABreachNotification sends the breaches detected by the SLA evaluator to the leads of
+// the phase.
+func (r *Notification) SendSLABreachNotification(
+	db *gorm.DB,
+	e *entity.SLABreachNotification,
+) error {
+	if len(e.MailAddresses) == 0 {
+		return fmt.Errorf(
+			"%w: no lead is set for SLA of phase %s", entity.ErrNotificationSkipped, e.Phase,
+		)
+	}
+	subject := fmt.Sprintf(
+		"[%s] %d reviews of %s exceeded the feedback SLA", e.Project, len(e.Breaches), e.Phase,
+	)
+	t, err := template.ParseFiles("template/slaBreachEmail.html")
+	if err != nil {
+		return fmt.Errorf("[EmailSender] failed to parse html template: %w", err)
+	}
+	var bodybytes bytes.Buffer
+	if err := t.Execute(&bodybytes, e); err != nil {
+		return fmt.Errorf("[EmailSender] failed to apply a parsed template: %w", err)
+	}
+	serveraddr := "10.1.10.5:25"
+	entry := "default"
+	rawConfig, err := r.getPipelineSettingValue(
+		db,
+		entity.Config,
+		&entry,
+		nil,
+		nil,
+		"mailServerAddress",
+	)
+	if err == nil && rawConfig != nil {
+		if strConfig, ok := rawConfig.(string); ok {
+			serveraddr = strConfig
+		}
+	}
+	sendername := os.Getenv("PPI_EMAIL_SENDER_NAME")
+	if sendername == "" {
+		sendername = "noreply@ppi.co.jp"
+	}
+	senderaddr := os.Getenv("PPI_EMAIL_SENDER_ADDRESS")
+	if senderaddr == "" {
+		senderaddr = "noreply@ppi.co.jp"
+	}
+	sender := mail.Address{
+		Name:    sendername,
+		Address: senderaddr,
+	}
+	maildata := buildEmail(&entity.EmailData{
+		Sender:  sender.Address,
+		To:      e.MailAddresses,
+		Subject: subject,
+		Body:    bodybytes.String(),
+	})
+	if err := r.sendEmail(&entity.EmailSenderInfo{
+		Server:  serveraddr,
+		Subject: subject,
+		Sender:  &sender,
+		To:      e.MailAddresses,
+		Cc:      nil,
+		Message: maildata,
+	}); err != nil {
+		return fmt.Errorf("[EmailSender] failed to send SLA breach notification: %w", err)
+	}
+	return nil
+}
+
+// SendWatchNotification sends an event of a review to the watchers of its asset or shot.
+func (r *Notification) SendWatchNotification(
+	db *gorm.DB,
+	e *entity.WatchNotification,
+) error {
+	if len(e.MailAddresses) == 0 {
+		return fmt.Errorf(
+			"%w: no watcher of %s %s to notify", entity.ErrNotificationSkipped, e.TargetType, e.Target,
+		)
+	}
+	action := "submitted"
+	if e.Event == entity.ActivityReviewStatusChanged {
+		action = "updated"
+	}
+	subject := fmt.Sprintf(
+		"[%s] %s %s take %s was %s by %s", e.Project, e.Target, e.Phase, e.Take, action, e.Actor,
+	)
+	t, err := template.ParseFiles("template/watchEmail.html")
+	if err != nil {
+		return fmt.Errorf("[EmailSender] failed to parse html template: %w", err)
+	}
+	var bodybytes bytes.Buffer
+	if err := t.Execute(&bodybytes, e); err != nil {
+		return fmt.Errorf("[EmailSender] failed to apply a parsed template: %w", err)
+	}
+	serveraddr := "10.1.10.5:25"
+	entry := "default"
+	rawConfig, err := r.getPipelineSettingValue(
+		db,
+		entity.Config,
+		&entry,
+		nil,
+		nil,
+		"mailServerAddress",
+	)
+	if err == nil && rawConfig != nil {
+		if strConfig, ok := rawConfig.(string); ok {
+			serveraddr = strConfig
+		}
+	}
+	sendername := os.Getenv("PPI_EMAIL_SENDER_NAME")
+	if sendername == "" {
+		sendername = "noreply@ppi.co.jp"
+	}
+	senderaddr := os.Getenv("PPI_EMAIL_SENDER_ADDRESS")
+	if senderaddr == "" {
+		senderaddr = "noreply@ppi.co.jp"
+	}
+	sender := mail.Address{
+		Name:    sendername,
+		Address: senderaddr,
+	}
+	// watchers are not told about each other
+	maildata := buildEmail(&entity.EmailData{
+		Sender:  sender.Address,
+		To:      []string{sender.Address},
+		Subject: subject,
+		Body:    bodybytes.String(),
+	})
+	if err := r.sendEmail(&entity.EmailSenderInfo{
+		Server:  serveraddr,
+		Subject: subject,
+		Sender:  &sender,
+		To:      e.MailAddresses,
+		Cc:      nil,
+		Message: maildata,
+	}); err != nil {
+		return fmt.Errorf("[EmailSender] failed to send watch notification: %w", err)
+	}
+	return nil
+}
+
+func (r *Notification) SendApiProcessFailure(err *entity.ApiProcessError) {
+	message := chat.Message{
+		Cards: []*chat.Card{
+			{
+				Header: &chat.CardHeader{
+					Title:    err.Title,
+					Subtitle: err.Studio,
+				},
+				Sections: []*chat.Section{
+					{
+						Widgets: []*chat.WidgetMarkup{
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         err.InfoLabel,
+									Content:          err.InfoContent,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Error",
+									Content:          err.Error.Error(),
+									ContentMultiline: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	go r.sendChatMessage(&entity.ChatMessageSenderInfo{
+		Webhook: r.systemWebhook,
+		Message: &message,
+	})
+}
+
+func (r *Notification) SendGeneralFailure(err error) {
+	message := errorMessageTemplate("", err.Error())
+
+	go r.sendChatMessage(&entity.ChatMessageSenderInfo{
+		Webhook: r.systemWebhook,
+		Message: message,
+	})
+}
+
+func (r *Notification) getPipelineSettingValue(
+	db *gorm.DB,
+	group entity.PipelineSettingGroup,
+	common *string,
+	studio *string,
+	project *string,
+	key string,
+) (interface{}, error) {
+	getPipelineSettingValueParams := &entity.GetPipelineSettingValueParams{
+		Group:     group,
+		Common:    common,
+		Studio:    studio,
+		Project:   project,
+		Key:       key,
+		Composite: true,
+	}
+	prefval, err := r.settingRepo.GetValue(db, getPipelineSettingValueParams)
+	if err != nil {
+		return nil, err
+	}
+	return prefval.Value, nil
+}
+
+// sendEmail makes a single delivery attempt. Retries are left to the notification outbox.
+// ChatConfigured tells whether a chat webhook is set.
+func (r *Notification) ChatConfigured() bool {
+	return r.systemWebhook != "" || r.projectWebhooks != ""
+}
+
+// ProbeChat checks that the host of the chat webhooks accepts connections, without posting.
+func (r *Notification) ProbeChat(ctx context.Context) error {
+	webhook := r.systemWebhook
+	if webhook == "" {
+		webhookInfo := strings.Split(strings.Split(r.projectWebhooks, ",")[0], "@")
+		if len(webhookInfo) > 1 {
+			webhook = webhookInfo[1]
+		}
+	}
+	u, err := url.Parse(webhook)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("invalid chat webhook: %q", webhook)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "443")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// ProbeMail checks that the mail server of the notifications greets, without sending.
+func (r *Notification) ProbeMail(ctx context.Context) error {
+	serveraddr := "10.1.10.5:25"
+	entry := "default"
+	rawConfig, err := r.getPipelineSettingValue(
+		r.WithContext(ctx),
+		entity.Config,
+		&entry,
+		nil,
+		nil,
+		"mailServerAddress",
+	)
+	if err == nil && rawConfig != nil {
+		if strConfig, ok := rawConfig.(string); ok {
+			serveraddr = strConfig
+		}
+	}
+	host, _, err := net.SplitHostPort(serveraddr)
+	if err != nil {
+		return fmt.Errorf("invalid mail server address: %q", serveraddr)
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", serveraddr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	mc, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	return mc.Quit()
+}
+
+func (r *Notification) sendEmail(info *entity.EmailSenderInfo) error {
+	mc, err := smtp.Dial(info.Server)
+	if err != nil {
+		return err
+	}
+	defer mc.Close()
+	if err = mc.Mail(info.Sender.String()); err != nil {
+		return err
+	}
+	for _, addr := range info.To {
+		if err = mc.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	for _, ccaddr := range info.Cc {
+		if err = mc.Rcpt(ccaddr); err != nil {
+			return err
+		}
+	}
+	w, err := mc.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(info.Message); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return mc.Quit()
+}
+
+func (r *Notification) sendChatMessage(info *entity.ChatMessageSenderInfo) {
+	payload, err := json.Marshal(info.Message)
+	if err != nil {
+		log.Println("[ChatSender] failed to marshal chat message: " + err.Error())
+		return
+	}
+	resp, err := http.Post(info.Webhook, "application/json; charset=UTF-8", bytes.NewReader(payload))
+	if err != nil {
+		log.Println("[ChatSender] failed to post chat message: " + err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[ChatSender] chat webhook responded with %s", resp.Status)
+	}
+}
+
+func buildEmail(data *entity.EmailData) []byte {
+	var buf bytes.Buffer
+	boundary := "my-boundary-779"
+	// mail header
+	buf.WriteString(fmt.Sprintf("From: %s\n", data.Sender))
+	buf.WriteString(fmt.Sprintf("To: %s\n", strings.Join(data.To, ";")))
+	if data.Cc != nil {
+		buf.WriteString(fmt.Sprintf("Cc: %s\n", strings.Join(data.Cc, ";")))
+	}
+	buf.WriteString(fmt.Sprintf("Subject: %s\n", data.Subject))
+	buf.WriteString("MIME-Version: 1.0\n")
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\n", boundary))
+	buf.WriteString(fmt.Sprintf("\n--%s\n", boundary))
+	// mail body
+	buf.WriteString("Content-Type: text/html; charset=\"utf-8\"\n")
+	buf.WriteString(fmt.Sprintf("\n%s", data.Body))
+	return buf.Bytes()
+}
+
+func buildEmailWithMetadata(data *entity.EmailDataWithMeta) []byte {
+	var buf bytes.Buffer
+	studio := data.MetaParams.Studio
+	project := data.MetaParams.Project
+	t := time.Now().UTC()
+	fileName := fmt.Sprintf("ppiReview_%s_%s_%s.json", studio, project, t.Format("20060102-150405"))
+	buf.WriteString(fmt.Sprintf("From: %s\n", data.Sender))
+	buf.WriteString(fmt.Sprintf("To: %s\n", strings.Join(data.To, ";")))
+	buf.WriteString(fmt.Sprintf("Cc: %s\n", strings.Join(data.Cc, ";")))
+	buf.WriteString(fmt.Sprintf("Subject: %s\n", data.Subject))
+	boundary := "my-boundary-779"
+	buf.WriteString("MIME-Version: 1.0\n")
+	buf.WriteString(
+		fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\n", boundary),
+	)
+	buf.WriteString(fmt.Sprintf("\n--%s\n", boundary))
+	buf.WriteString("Content-Type: text/html; charset=\"utf-8\"\n")
+	buf.WriteString(fmt.Sprintf("\n%s", data.Body))
+	buf.WriteString(fmt.Sprintf("\n--%s\n", boundary))
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\n")
+	buf.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=%s\n", fileName))
+	buf.WriteString(fmt.Sprintf("Content-ID: <%s>\n\n", fileName))
+	metaparamjson, err := json.Marshal(data.MetaParams)
+	if err != nil {
+		log.Println("[EmailSender] failed to marshal mail metadata: " + err.Error())
+		return buf.Bytes()
+	}
+	var temp bytes.Buffer
+	err = json.Indent(&temp, metaparamjson, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	metaparamjson = temp.Bytes()
+	b := make([]byte, base64.StdEncoding.EncodedLen(len(metaparamjson)))
+	base64.StdEncoding.Encode(b, metaparamjson)
+	buf.Write(b)
+	buf.WriteString(fmt.Sprintf("\n--%s", boundary))
+	buf.WriteString("--")
+	return buf.Bytes()
+}
+
+func errorMessageTemplate(subject string, message string) *chat.Message {
+	return &chat.Message{
+		Cards: []*chat.Card{
+			{
+				Header: &chat.CardHeader{
+					Title: "failure in sending email",
+				},
+				Sections: []*chat.Section{
+					{
+						Widgets: []*chat.WidgetMarkup{
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Email Subject",
+									Content:          subject,
+									ContentMultiline: true,
+								},
+							},
+							{
+								KeyValue: &chat.KeyValue{
+									TopLabel:         "Error",
+									Content:          message,
+									ContentMultiline: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationOutbox stores notifications to be sent by the background dispatcher. Entries are
+// written with the same transaction as the mutation that triggers them, so a notification is
+// never lost nor sent for a rolled back change.
+type NotificationOutbox struct {
+	db *gorm.DB
+}
+
+func NewNotificationOutbox(db *gorm.DB) (*NotificationOutbox, error) {
+	if err := db.AutoMigrate(&model.NotificationOutbox{}); err != nil {
+		return nil, err
+	}
+	return &NotificationOutbox{
+		db: db,
+	}, nil
+}
+
+func (r *NotificationOutbox) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *NotificationOutbox) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *NotificationOutbox) Enqueue(
+	tx *gorm.DB,
+	kind entity.NotificationOutboxKind,
+	project string,
+	payload interface{},
+) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(model.NewNotificationOutbox(kind, project, b)).Error
+}
+
+// EnqueueWebhookEvent queues an event of the project for each active webhook subscribed to
+// it, so that a failing webhook is retried without posting the event to the others again.
+func (r *NotificationOutbox) EnqueueWebhookEvent(
+	tx *gorm.DB,
+	project string,
+	event entity.WebhookEvent,
+	data interface{},
+) error {
+	var webhooks []*model.Webhook
+	if err := tx.Where(
+		"`project` = ?", project,
+	).Where(
+		"`active` = ?", true,
+	).Find(&webhooks).Error; err != nil {
+		return err
+	}
+	var b []byte
+	now := time.Now().UTC()
+	for _, w := range webhooks {
+		if !w.Events.Has(event) {
+			continue
+		}
+		if b == nil {
+			var err error
+			if b, err = json.Marshal(data); err != nil {
+				return err
+			}
+		}
+		if err := r.Enqueue(tx, entity.WebhookNotificationKind, project, &entity.WebhookNotification{
+			WebhookID:     w.ID,
+			Project:       project,
+			Event:         event,
+			DeliveryID:    uuid.NewString(),
+			OccurredAtUTC: now,
+			Data:          b,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnqueuePublishNotification queues the notifications of a publish: the publish mail and chat
+// of the project, and the publish.completed event of the webhooks, with the transaction tx.
+func (r *NotificationOutbox) EnqueuePublishNotification(
+	tx *gorm.DB,
+	info *entity.PublishTransactionInfoNotification,
+) error {
+	if err := r.Enqueue(tx, entity.PublishNotificationKind, info.Project, info); err != nil {
+		return err
+	}
+	return r.EnqueueWebhookEvent(tx, info.Project, entity.WebhookPublishCompleted, info)
+}
+
+// EnqueueApprovalChange queues the notifications of a change of the approval status of a
+// review: the review.approval_changed event of the webhooks, and the transition posted to the
+// status chat channels when the review is of an asset.
+func (r *NotificationOutbox) EnqueueApprovalChange(
+	tx *gorm.DB,
+	previous string,
+	review *entity.ReviewInfo,
+) error {
+	if err := r.EnqueueWebhookEvent(
+		tx, review.Project, entity.WebhookReviewApprovalChanged, &entity.WebhookApprovalChange{
+			PreviousApprovalStatus: previous,
+			Review:                 review,
+		},
+	); err != nil {
+		return err
+	}
+	if review.Root != "assets" {
+		return nil
+	}
+	return r.Enqueue(
+		tx, entity.StatusChatNotificationKind, review.Project, &entity.StatusChatNotification{
+			Project:                review.Project,
+			Root:                   review.Root,
+			Groups:                 review.Groups,
+			Relation:               review.Relation,
+			Phase:                  review.Phase,
+			Take:                   review.Take,
+			ReviewInfoID:           review.ID,
+			PreviousApprovalStatus: previous,
+			ApprovalStatus:         review.ApprovalStatus,
+			UpdatedUser:            review.ApprovalStatusUpdatedUser,
+		},
+	)
+}
+
+// Claim locks at most limit pending entries that are due for the given lease and returns them.
+// Entries locked by a dispatcher that died are claimed again once their lease has expired.
+func (r *NotificationOutbox) Claim(
+	db *gorm.DB,
+	limit int,
+	lease time.Duration,
+) ([]*entity.NotificationOutboxEntry, error) {
+	now := time.Now().UTC()
+	claimable := func(stmt *gorm.DB) *gorm.DB {
+		return stmt.Where(
+			"`status` = ?", entity.NotificationOutboxPending,
+		).Where(
+			"`next_attempt_at_utc` <= ?", now,
+		).Where(
+			"(`locked_until_utc` IS NULL OR `locked_until_utc` < ?)", now,
+		)
+	}
+
+	var ids []int32
+	if err := claimable(db.Model(&model.NotificationOutbox{})).Order(
+		"`id` asc",
+	).Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	token := uuid.NewString()
+	if err := claimable(db.Model(&model.NotificationOutbox{})).Where(
+		"`id` IN ?", ids,
+	).Updates(map[string]interface{}{
+		"lock_token":       token,
+		"locked_until_utc": now.Add(lease),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	var models []*model.NotificationOutbox
+	if err := db.Where("`lock_token` = ?", token).Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.NotificationOutboxEntry, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *NotificationOutbox) MarkSent(db *gorm.DB, id int32) error {
+	return db.Model(&model.NotificationOutbox{}).Where(
+		"`id` = ?", id,
+	).Updates(map[string]interface{}{
+		"status":           entity.NotificationOutboxSent,
+		"attempts":         gorm.Expr("`attempts` + 1"),
+		"lock_token":       nil,
+		"locked_until_utc": nil,
+		"modified_at_utc":  time.Now().UTC(),
+	}).Error
+}
+
+// MarkFailed records a failed attempt. The entry is retried at nextAttempt, or moved to the
+// dead letters when nextAttempt is nil.
+func (r *NotificationOutbox) MarkFailed(
+	db *gorm.DB,
+	id int32,
+	cause error,
+	nextAttempt *time.Time,
+) error {
+	values := map[string]interface{}{
+		"attempts":         gorm.Expr("`attempts` + 1"),
+		"last_error":       cause.Error(),
+		"lock_token":       nil,
+		"locked_until_utc": nil,
+		"modified_at_utc":  time.Now().UTC(),
+	}
+	if nextAttempt != nil {
+		values["next_attempt_at_utc"] = *nextAttempt
+	} else {
+		values["status"] = entity.NotificationOutboxDead
+	}
+	return db.Model(&model.NotificationOutbox{}).Where("`id` = ?", id).Updates(values).Error
+}
+
+// whereDeadLetters keeps the dead letters of the project and kind, when given.
+func whereDeadLetters(
+	stmt *gorm.DB,
+	project *string,
+	kind *entity.NotificationOutboxKind,
+) *gorm.DB {
+	stmt = stmt.Where("`status` = ?", entity.NotificationOutboxDead)
+	if project != nil {
+		stmt = stmt.Where("`project` = ?", *project)
+	}
+	if kind != nil {
+		stmt = stmt.Where("`kind` = ?", string(*kind))
+	}
+	return stmt
+}
+
+func (r *NotificationOutbox) ListDeadLetters(
+	db *gorm.DB,
+	params *entity.ListDeadLettersParams,
+) ([]*entity.NotificationOutboxEntry, uint, error) {
+	stmt := whereDeadLetters(db.Model(&model.NotificationOutbox{}), params.Project, params.Kind)
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.NotificationOutbox
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`modified_at_utc` desc",
+	).Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.NotificationOutboxEntry, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, uint(total), nil
+}
+
+func (r *NotificationOutbox) GetDeadLetter(
+	db *gorm.DB,
+	params *entity.GetDeadLetterParams,
+) (*entity.NotificationOutboxEntry, error) {
+	var m model.NotificationOutbox
+	if err := db.Where("`id` = ?", params.ID).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: notification with ID %d", entity.ErrRecordNotFound, params.ID,
+			)
+		}
+		return nil, err
+	}
+	if m.Status != string(entity.NotificationOutboxDead) {
+		return nil, fmt.Errorf(
+			"%w: notification with ID %d is %s", entity.ErrRecordNotFound, params.ID, m.Status,
+		)
+	}
+	return m.Entity(), nil
+}
+
+// RetryDeadLetters moves the dead letters back to the pending entries, due now, with their
+// attempts reset. Their last error is kept until they are attempted again.
+func (r *NotificationOutbox) RetryDeadLetters(
+	tx *gorm.DB,
+	params *entity.RetryDeadLettersParams,
+) (int64, error) {
+	stmt := whereDeadLetters(tx.Model(&model.NotificationOutbox{}), params.Project, params.Kind)
+	if len(params.IDs) > 0 {
+		stmt = stmt.Where("`id` IN ?", params.IDs)
+	}
+	now := time.Now().UTC()
+	result := stmt.Updates(map[string]interface{}{
+		"status":              entity.NotificationOutboxPending,
+		"attempts":            0,
+		"next_attempt_at_utc": now,
+		"modified_at_utc":     now,
+	})
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationOutbox) PurgeDeadLetters(
+	tx *gorm.DB,
+	params *entity.PurgeDeadLettersParams,
+) (int64, error) {
+	result := whereDeadLetters(tx, params.Project, params.Kind).Where(
+		"`modified_at_utc` < ?", params.Before,
+	).Delete(&model.NotificationOutbox{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationOutbox) DeadLetterStats(db *gorm.DB) (*entity.DeadLetterStats, error) {
+	var rows []struct {
+		Kind        string
+		Count       int64
+		OldestAtUtc time.Time
+	}
+	if err := whereDeadLetters(db.Model(&model.NotificationOutbox{}), nil, nil).Select(
+		"`kind`, COUNT(*) AS count, MIN(`modified_at_utc`) AS oldest_at_utc",
+	).Group("`kind`").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	stats := &entity.DeadLetterStats{
+		ByKind: map[entity.NotificationOutboxKind]int64{},
+	}
+	for _, row := range rows {
+		stats.Depth += row.Count
+		stats.ByKind[entity.NotificationOutboxKind(row.Kind)] = row.Count
+		if stats.OldestAtUTC == nil || row.OldestAtUtc.Before(*stats.OldestAtUTC) {
+			oldest := row.OldestAtUtc
+			stats.OldestAtUTC = &oldest
+		}
+	}
+	return stats, nil
+}
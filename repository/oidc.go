@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/golang-jwt/jwt"
+)
+
+// oidcStateLifetime is how long the user has to log in with the provider.
+const oidcStateLifetime = 10 * time.Minute
+
+// oidcProvider is the part of the discovery document of the provider used by the login.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcStateClaims struct {
+	*jwt.StandardClaims
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	ReturnTo string `json:"return_to"`
+}
+
+// OIDC logs users in with an OpenID Connect provider, e.g. Google Workspace or Azure AD, with
+// the authorization code flow. It is configured by PPI_OIDC_ISSUER, PPI_OIDC_CLIENT_ID,
+// PPI_OIDC_CLIENT_SECRET and PPI_OIDC_REDIRECT_URL, and PPI_OIDC_STUDIO_DOMAINS maps the
+// domains of the email addresses of the users to their studios, e.g. "ppi.co.jp=ppi". The
+// login is disabled when PPI_OIDC_ISSUER is not set.
+type OIDC struct {
+	issuer        string
+	clientID      string
+	clientSecret  string
+	redirectURL   string
+	scopes        []string
+	studioDomains map[string]string
+	client        *http.Client
+
+	mu       sync.Mutex
+	provider *oidcProvider
+	keys     map[string]*rsa.PublicKey
+}
+
+func NewOIDC() (*OIDC, error) {
+	r := &OIDC{
+		issuer:        strings.TrimSuffix(os.Getenv("PPI_OIDC_ISSUER"), "/"),
+		clientID:      os.Getenv("PPI_OIDC_CLIENT_ID"),
+		clientSecret:  os.Getenv("PPI_OIDC_CLIENT_SECRET"),
+		redirectURL:   os.Getenv("PPI_OIDC_REDIRECT_URL"),
+		scopes:        []string{"openid", "email", "profile"},
+		studioDomains: make(map[string]string),
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+	if r.issuer == "" {
+		return r, nil
+	}
+	if r.clientID == "" || r.clientSecret == "" || r.redirectURL == "" {
+		return nil, errors.New(
+			"PPI_OIDC_CLIENT_ID, PPI_OIDC_CLIENT_SECRET and PPI_OIDC_REDIRECT_URL are required " +
+				"with PPI_OIDC_ISSUER",
+		)
+	}
+	if v := os.Getenv("PPI_OIDC_SCOPES"); v != "" {
+		r.scopes = strings.Fields(v)
+	}
+	for _, pair := range strings.Split(os.Getenv("PPI_OIDC_STUDIO_DOMAINS"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		domain, studio, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(domain) == "" || strings.TrimSpace(studio) == "" {
+			return nil, fmt.Errorf("invalid PPI_OIDC_STUDIO_DOMAINS: %q", pair)
+		}
+		r.studioDomains[strings.ToLower(strings.TrimSpace(domain))] = strings.TrimSpace(studio)
+	}
+	return r, nil
+}
+
+// Enabled reports whether users can log in with the provider, PPI_OIDC_ISSUER being set.
+func (r *OIDC) Enabled() bool {
+	return r.issuer != ""
+}
+
+// AuthURL returns the URL of the provider the user logs in at, and the state to keep in the
+// browser of the user until the callback, which returns them to returnTo.
+func (r *OIDC) AuthURL(ctx context.Context, returnTo string) (string, string, error) {
+	provider, err := r.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	state, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, &oidcStateClaims{
+		StandardClaims: &jwt.StandardClaims{
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(oidcStateLifetime).Unix(),
+		},
+		State:    state,
+		Nonce:    nonce,
+		ReturnTo: returnTo,
+	})
+	cookie, err := t.SignedString([]byte(r.clientSecret))
+	if err != nil {
+		return "", "", err
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", r.clientID)
+	q.Set("redirect_uri", r.redirectURL)
+	q.Set("scope", strings.Join(r.scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	sep := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return provider.AuthorizationEndpoint + sep + q.Encode(), cookie, nil
+}
+
+// Callback exchanges the authorization code returned by the provider for the ID token of the
+// user, and returns the user with the path to return them to. The state cookie of the params
+// is the state returned by AuthURL, which must be the one the provider returned the code with.
+func (r *OIDC) Callback(
+	ctx context.Context,
+	params *entity.OIDCCallbackParams,
+) (*entity.OIDCIdentity, string, error) {
+	state := &oidcStateClaims{}
+	if _, err := jwt.ParseWithClaims(
+		params.StateCookie,
+		state,
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, entity.ErrUnauthorized
+			}
+			return []byte(r.clientSecret), nil
+		},
+	); err != nil || state.StandardClaims == nil {
+		return nil, "", fmt.Errorf("%w: invalid or expired login state", entity.ErrUnauthorized)
+	}
+	if state.State != params.State {
+		return nil, "", fmt.Errorf("%w: login state does not match", entity.ErrUnauthorized)
+	}
+	idToken, err := r.exchange(ctx, params.Code)
+	if err != nil {
+		return nil, "", err
+	}
+	identity, err := r.verify(ctx, idToken, state.Nonce)
+	if err != nil {
+		return nil, "", err
+	}
+	return identity, state.ReturnTo, nil
+}
+
+// Studio returns the studio of the user, from the domain of their email address.
+func (r *OIDC) Studio(identity *entity.OIDCIdentity) (string, error) {
+	_, domain, ok := strings.Cut(identity.Email, "@")
+	if ok {
+		if studio, ok := r.studioDomains[strings.ToLower(domain)]; ok {
+			return studio, nil
+		}
+	}
+	return "", fmt.Errorf(
+		"%w: %q does not belong to the domain of any studio", entity.ErrForbidden, identity.Email,
+	)
+}
+
+// discover returns the discovery document of the provider, which is read once per instance.
+func (r *OIDC) discover(ctx context.Context) (*oidcProvider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.provider != nil {
+		return r.provider, nil
+	}
+	var p oidcProvider
+	if err := r.get(ctx, r.issuer+"/.well-known/openid-configuration", &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(p.Issuer, "/") != r.issuer {
+		return nil, fmt.Errorf("issuer %q of the provider is not %q", p.Issuer, r.issuer)
+	}
+	r.provider = &p
+	return r.provider, nil
+}
+
+func (r *OIDC) get(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	return r.do(req, v)
+}
+
+func (r *OIDC) do(req *http.Request, v interface{}) error {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OIDC provider returned %s for %s", resp.Status, req.URL.Path)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// exchange returns the ID token the authorization code is exchanged for.
+func (r *OIDC) exchange(ctx context.Context, code string) (string, error) {
+	provider, err := r.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", r.redirectURL)
+	form.Set("client_id", r.clientID)
+	form.Set("client_secret", r.clientSecret)
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var res struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := r.do(req, &res); err != nil {
+		return "", fmt.Errorf("%w: %s", entity.ErrUnauthorized, err)
+	}
+	if res.IDToken == "" {
+		return "", fmt.Errorf("%w: no ID token was returned", entity.ErrUnauthorized)
+	}
+	return res.IDToken, nil
+}
+
+// verify verifies the signature and the claims of the ID token, and returns its user.
+func (r *OIDC) verify(
+	ctx context.Context,
+	idToken string,
+	nonce string,
+) (*entity.OIDCIdentity, error) {
+	provider, err := r.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(
+		idToken,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, entity.ErrUnauthorized
+			}
+			kid, _ := token.Header["kid"].(string)
+			return r.key(ctx, kid)
+		},
+	); err != nil {
+		return nil, fmt.Errorf("%w: invalid ID token: %s", entity.ErrUnauthorized, err)
+	}
+	if !claims.VerifyIssuer(provider.Issuer, true) || !claims.VerifyAudience(r.clientID, true) {
+		return nil, fmt.Errorf("%w: ID token was not issued to this client", entity.ErrUnauthorized)
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, fmt.Errorf("%w: ID token nonce does not match", entity.ErrUnauthorized)
+	}
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return nil, fmt.Errorf("%w: email address is not verified", entity.ErrUnauthorized)
+	}
+	identity := &entity.OIDCIdentity{}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	if identity.Email == "" {
+		// Azure AD only has the email address of work accounts as their user name
+		identity.Email, _ = claims["preferred_username"].(string)
+	}
+	if identity.Subject == "" || identity.Email == "" {
+		return nil, fmt.Errorf("%w: ID token has no subject or email", entity.ErrUnauthorized)
+	}
+	return identity, nil
+}
+
+// key returns the signing key of the provider with the ID. The keys are read again when it is
+// unknown, since providers rotate their keys.
+func (r *OIDC) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	r.mu.Lock()
+	key, ok := r.keys[kid]
+	r.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	provider, err := r.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := r.get(ctx, provider.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	r.mu.Lock()
+	r.keys = keys
+	r.mu.Unlock()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", entity.ErrUnauthorized, kid)
+}
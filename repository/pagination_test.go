@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/entity/groupCategory"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// paginationRecords is the size of the data sets paginated, not a multiple of
+// paginationPerPage so that the last page is partial.
+const (
+	paginationRecords = 23
+	paginationPerPage = 4
+)
+
+// collectPages reads the pages from the first one until an empty page and fails when a key
+// is returned twice or when the keys read are not the total.
+func collectPages(t *testing.T, total int, page func(page int) ([]string, error)) {
+	t.Helper()
+	seen := make(map[string]int)
+	for p := 1; p <= total+1; p++ {
+		keys, err := page(p)
+		if err != nil {
+			t.Fatalf("page %d: %v", p, err)
+		}
+		if len(keys) == 0 {
+			break
+		}
+		for _, k := range keys {
+			if prev, ok := seen[k]; ok {
+				t.Fatalf("%s returned on page %d and again on page %d", k, prev, p)
+			}
+			seen[k] = p
+		}
+	}
+	if len(seen) != total {
+		t.Fatalf("read %d records over the pages, want %d", len(seen), total)
+	}
+}
+
+func perPage(n int) *entity.BaseListParams {
+	return &entity.BaseListParams{PerPage: &n}
+}
+
+func atPage(base *entity.BaseListParams, page int) *entity.BaseListParams {
+	p := *base
+	p.Page = &page
+	return &p
+}
+
+// seedTiedReviews stores a review per asset of the project, all submitted and modified at the
+// same time so that only the tiebreakers order them.
+func seedTiedReviews(t *testing.T, db *gorm.DB, project string) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	reviews := make([]*model.ReviewInfo, paginationRecords)
+	for i := range reviews {
+		// two groups, so that the assets tie on the first tiebreaker as well
+		group := seedAssetTypes[i%2].group
+		relation := fmt.Sprintf("%s%04d", group, i+1)
+		take := fmt.Sprintf("%s_%07d", at.Format("20060102T150405.000000"), i)
+		reviews[i] = seedReview(
+			rng, project, "ppi", group, relation, "mdl", take, relation+"_mdl.usd",
+			"check", "inProgress", "artist01", at,
+		)
+	}
+	if err := db.Create(reviews).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReviewInfoListPagesWithoutDuplicates(t *testing.T) {
+	db := openTestDB(t)
+	r, err := NewReviewInfo(db, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := testProject(t, db)
+	seedTiedReviews(t, db, project)
+
+	for _, order := range []string{"`submitted_at_utc` desc", "`phase` asc", reviewInfoTiebreaker} {
+		t.Run(order, func(t *testing.T) {
+			base := perPage(paginationPerPage)
+			collectPages(t, paginationRecords, func(page int) ([]string, error) {
+				entities, _, err := r.List(db, &entity.ListReviewInfoParams{
+					Project:        project,
+					OrderBy:        &order,
+					BaseListParams: atPage(base, page),
+				})
+				keys := make([]string, len(entities))
+				for i, e := range entities {
+					keys[i] = fmt.Sprint(e.ID)
+				}
+				return keys, err
+			})
+		})
+	}
+}
+
+func TestAssetsPivotPagesWithoutDuplicates(t *testing.T) {
+	db := openTestDB(t)
+	r, err := NewReviewInfo(db, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := testProject(t, db)
+	seedTiedReviews(t, db, project)
+
+	pivotKeys := func(res *ListAssetsPivotResult) []string {
+		keys := make([]string, len(res.Assets))
+		for i, a := range res.Assets {
+			keys[i] = a.Group1 + "/" + a.Relation
+		}
+		return keys
+	}
+	for _, dir := range []string{"ASC", "DESC"} {
+		params := ListAssetsPivotParams{
+			Project:   project,
+			Root:      seedRoot,
+			View:      "list",
+			PerPage:   paginationPerPage,
+			OrderKey:  "submitted",
+			Direction: dir,
+		}
+		t.Run("page "+dir, func(t *testing.T) {
+			collectPages(t, paginationRecords, func(page int) ([]string, error) {
+				p := params
+				p.Page = page
+				res, err := r.ListAssetsPivot(db, p)
+				if err != nil {
+					return nil, err
+				}
+				return pivotKeys(res), nil
+			})
+		})
+		t.Run("cursor "+dir, func(t *testing.T) {
+			cursor := ""
+			collectPages(t, paginationRecords, func(page int) ([]string, error) {
+				if page > 1 && cursor == "" {
+					return nil, nil
+				}
+				p := params
+				p.Cursor = cursor
+				res, err := r.ListAssetsPivot(db, p)
+				if err != nil {
+					return nil, err
+				}
+				cursor = res.NextCursor
+				return pivotKeys(res), nil
+			})
+		})
+	}
+}
+
+func TestGroupCategoryListPagesWithoutDuplicates(t *testing.T) {
+	db := openTestDB(t)
+	r, err := NewGroupCategory(db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := testProject(t, db)
+	// the categories are all of the same root and created at the same time
+	categories := seedCategories(
+		rand.New(rand.NewSource(1)), project, paginationRecords, "tester", time.Now().UTC(),
+	)
+	if err := db.Create(categories).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	base := perPage(paginationPerPage)
+	collectPages(t, paginationRecords, func(page int) ([]string, error) {
+		entities, _, err := r.List(db, &groupCategory.ListParams{
+			Project:        project,
+			OrderBy:        []string{"root"},
+			SubParams:      groupCategory.ListStandardSubParams{},
+			BaseListParams: atPage(base, page),
+		})
+		keys := make([]string, len(entities))
+		for i, e := range entities {
+			b, err := json.Marshal(e)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = string(b)
+		}
+		return keys, err
+	})
+}
+
+func TestTagListPagesWithoutDuplicates(t *testing.T) {
+	db := openTestDB(t)
+	r, err := NewTag(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := testProject(t, db)
+	t.Cleanup(func() {
+		db.Where("`project` = ?", project).Delete(&model.Tag{})
+	})
+	for i := 0; i < paginationRecords; i++ {
+		name := fmt.Sprintf("tag%02d", i)
+		if err := db.Create(model.NewTag(project, name, "tester")).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base := perPage(paginationPerPage)
+	collectPages(t, paginationRecords, func(page int) ([]string, error) {
+		tags, _, err := r.List(db, &entity.ListTagsParams{
+			Project:        project,
+			BaseListParams: atPage(base, page),
+		})
+		keys := make([]string, len(tags))
+		for i, tag := range tags {
+			keys[i] = fmt.Sprint(tag.ID)
+		}
+		return keys, err
+	})
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// PhaseTemplate reads the ordered phases of project roots from the pipeline settings.
+type PhaseTemplate struct {
+	db          *gorm.DB
+	settingRepo *PipelineSetting
+}
+
+func NewPhaseTemplate(db *gorm.DB, settingRepo *PipelineSetting) *PhaseTemplate {
+	return &PhaseTemplate{
+		db:          db,
+		settingRepo: settingRepo,
+	}
+}
+
+func (r *PhaseTemplate) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *PhaseTemplate) Get(
+	db *gorm.DB,
+	params *entity.GetPhaseTemplateParams,
+) (*entity.PhaseTemplate, error) {
+	value, err := r.settingRepo.GetValue(db, &entity.GetPipelineSettingValueParams{
+		Group:     entity.Preference,
+		Project:   &params.Project,
+		Key:       fmt.Sprintf(entity.PhaseTemplateKeyFormat, params.Root),
+		Composite: true,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return &entity.PhaseTemplate{
+				Project: params.Project,
+				Root:    params.Root,
+				Phases:  slices.Clone(entity.DefaultPhaseTemplates[params.Root]),
+				Default: true,
+			}, nil
+		}
+		return nil, err
+	}
+
+	values, ok := value.Value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(
+			"phase template of root %q must be an array of phases", params.Root,
+		)
+	}
+	phases := make([]string, 0, len(values))
+	for _, v := range values {
+		phase, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf(
+				"phase template of root %q must be an array of phases", params.Root,
+			)
+		}
+		phase = strings.ToLower(strings.TrimSpace(phase))
+		if phase != "" && !slices.Contains(phases, phase) {
+			phases = append(phases, phase)
+		}
+	}
+	return &entity.PhaseTemplate{
+		Project: params.Project,
+		Root:    params.Root,
+		Phases:  phases,
+	}, nil
+}
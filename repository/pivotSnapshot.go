@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// PivotSnapshot stores the asset pivot results shared by public links. The links are signed
+// with HMAC-SHA256 keyed by PPI_SHARE_LINK_SECRET, and sharing is disabled when it is not set.
+type PivotSnapshot struct {
+	db     *gorm.DB
+	secret []byte
+}
+
+func NewPivotSnapshot(db *gorm.DB) (*PivotSnapshot, error) {
+	if err := db.AutoMigrate(&model.PivotSnapshot{}); err != nil {
+		return nil, err
+	}
+	return &PivotSnapshot{
+		db:     db,
+		secret: []byte(os.Getenv("PPI_SHARE_LINK_SECRET")),
+	}, nil
+}
+
+func (r *PivotSnapshot) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *PivotSnapshot) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// Enabled reports whether share links can be signed.
+func (r *PivotSnapshot) Enabled() bool {
+	return len(r.secret) > 0
+}
+
+// Sign returns the signature of the link to the snapshot expiring at the given Unix time.
+func (r *PivotSnapshot) Sign(id int32, expires int64) string {
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write([]byte(strconv.Itoa(int(id)) + "." + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature is the one of the link to the snapshot.
+func (r *PivotSnapshot) Verify(id int32, expires int64, signature string) bool {
+	if !r.Enabled() {
+		return false
+	}
+	return hmac.Equal([]byte(r.Sign(id, expires)), []byte(signature))
+}
+
+func (r *PivotSnapshot) Create(
+	tx *gorm.DB,
+	params *entity.CreatePivotSnapshotParams,
+	phases []string,
+	rows []byte,
+	total int64,
+) (*entity.PivotSnapshot, error) {
+	filters, err := json.Marshal(params.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if phases == nil {
+		phases = []string{}
+	}
+	phasesJSON, err := json.Marshal(phases)
+	if err != nil {
+		return nil, err
+	}
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	now := time.Now().UTC()
+	expires := now.Add(time.Duration(params.ExpiresInHours) * time.Hour)
+	m := &model.PivotSnapshot{
+		Project:       params.Project,
+		Root:          params.Root,
+		View:          params.View,
+		Filters:       model.JSON(filters),
+		Phases:        model.JSON(phasesJSON),
+		Rows:          model.JSON(rows),
+		Total:         total,
+		ExpiresAtUTC:  expires.Truncate(time.Second),
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity()
+}
+
+// View returns the snapshot of a public link and counts the view. Revoked snapshots are not
+// found, and expired ones return ErrShareLinkExpired.
+func (r *PivotSnapshot) View(tx *gorm.DB, id int32) (*entity.PivotSnapshot, error) {
+	var m model.PivotSnapshot
+	if err := tx.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: pivot snapshot with ID %d", entity.ErrRecordNotFound, id)
+		}
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if !now.Before(m.ExpiresAtUTC) {
+		return nil, fmt.Errorf("%w: pivot snapshot with ID %d", entity.ErrShareLinkExpired, id)
+	}
+	if err := tx.Model(&m).Updates(map[string]interface{}{
+		"views":              gorm.Expr("`views` + 1"),
+		"last_viewed_at_utc": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	m.Views++
+	m.LastViewedAtUTC = &now
+	return m.Entity()
+}
+
+func (r *PivotSnapshot) Delete(
+	tx *gorm.DB,
+	params *entity.DeletePivotSnapshotParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.PivotSnapshot
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: pivot snapshot with ID %d", entity.ErrRecordNotFound, params.ID)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+type PivotView struct {
+	db *gorm.DB
+}
+
+func NewPivotView(db *gorm.DB) (*PivotView, error) {
+	if err := db.AutoMigrate(&model.PivotView{}); err != nil {
+		return nil, err
+	}
+	return &PivotView{
+		db: db,
+	}, nil
+}
+
+func (r *PivotView) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *PivotView) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *PivotView) List(
+	db *gorm.DB,
+	params *entity.ListPivotViewsParams,
+) ([]*entity.PivotView, error) {
+	var models []*model.PivotView
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`owner` = ?", params.Owner,
+	).Order("`name` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.PivotView, len(models))
+	for i, m := range models {
+		e, err := m.Entity()
+		if err != nil {
+			return nil, err
+		}
+		entities[i] = e
+	}
+	return entities, nil
+}
+
+func (r *PivotView) get(
+	db *gorm.DB,
+	project string,
+	owner string,
+	id int32,
+) (*model.PivotView, error) {
+	var m model.PivotView
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`owner` = ?", owner,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: pivot view with ID %d not found", entity.ErrRecordNotFound, id,
+			)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *PivotView) Get(
+	db *gorm.DB,
+	params *entity.GetPivotViewParams,
+) (*entity.PivotView, error) {
+	m, err := r.get(db, params.Project, params.Owner, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity()
+}
+
+func pivotViewJSON(filters map[string][]string, columns []string) (model.JSON, model.JSON, error) {
+	if filters == nil {
+		filters = map[string][]string{}
+	}
+	if columns == nil {
+		columns = []string{}
+	}
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	columnsJSON, err := json.Marshal(columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filtersJSON, columnsJSON, nil
+}
+
+func duplicatedPivotView(err error, name string) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return fmt.Errorf("%w: pivot view %q is already exists", entity.ErrBadRequest, name)
+	}
+	return err
+}
+
+func (r *PivotView) Create(
+	tx *gorm.DB,
+	params *entity.CreatePivotViewParams,
+) (*entity.PivotView, error) {
+	filters, columns, err := pivotViewJSON(params.Filters, params.Columns)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	m := &model.PivotView{
+		Project:       params.Project,
+		Owner:         params.Owner,
+		Name:          params.Name,
+		Root:          params.Root,
+		Filters:       filters,
+		Sort:          params.Sort,
+		Dir:           params.Dir,
+		Columns:       columns,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+	}
+	if err := tx.Create(m).Error; err != nil {
+		return nil, duplicatedPivotView(err, params.Name)
+	}
+	return m.Entity()
+}
+
+func (r *PivotView) Update(
+	tx *gorm.DB,
+	params *entity.UpdatePivotViewParams,
+) (*entity.PivotView, error) {
+	m, err := r.get(tx, params.Project, params.Owner, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if params.Name != nil {
+		m.Name = *params.Name
+	}
+	if params.Root != nil {
+		m.Root = *params.Root
+	}
+	if params.Sort != nil {
+		m.Sort = *params.Sort
+	}
+	if params.Dir != nil {
+		m.Dir = *params.Dir
+	}
+	if params.Filters != nil || params.Columns != nil {
+		e, err := m.Entity()
+		if err != nil {
+			return nil, err
+		}
+		if params.Filters != nil {
+			e.Filters = params.Filters
+		}
+		if params.Columns != nil {
+			e.Columns = params.Columns
+		}
+		if m.Filters, m.Columns, err = pivotViewJSON(e.Filters, e.Columns); err != nil {
+			return nil, err
+		}
+	}
+	m.ModifiedAtUTC = time.Now().UTC()
+	if err := tx.Save(m).Error; err != nil {
+		return nil, duplicatedPivotView(err, m.Name)
+	}
+	return m.Entity()
+}
+
+func (r *PivotView) Delete(
+	tx *gorm.DB,
+	params *entity.DeletePivotViewParams,
+) error {
+	var m *model.PivotView
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`owner` = ?", params.Owner,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: pivot view with ID %d not found", entity.ErrRecordNotFound, params.ID,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// ProjectBootstrapJob stores the bootstrap jobs of the projects.
+type ProjectBootstrapJob struct {
+	db *gorm.DB
+}
+
+func NewProjectBootstrapJob(db *gorm.DB) (*ProjectBootstrapJob, error) {
+	if err := db.AutoMigrate(&model.ProjectBootstrapJob{}); err != nil {
+		return nil, err
+	}
+	return &ProjectBootstrapJob{
+		db: db,
+	}, nil
+}
+
+func (r *ProjectBootstrapJob) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ProjectBootstrapJob) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *ProjectBootstrapJob) Get(
+	db *gorm.DB,
+	params *entity.GetProjectBootstrapJobParams,
+) (*entity.ProjectBootstrapJob, error) {
+	var m model.ProjectBootstrapJob
+	if err := db.Where("`id` = ?", params.ID).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: project bootstrap job with ID %d", entity.ErrRecordNotFound, params.ID,
+			)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Create queues a bootstrap job, unless one of the project is already queued or running.
+func (r *ProjectBootstrapJob) Create(
+	tx *gorm.DB,
+	params *entity.CreateProjectBootstrapJobParams,
+) (*entity.ProjectBootstrapJob, error) {
+	var count int64
+	if err := tx.Model(&model.ProjectBootstrapJob{}).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`status` IN ?",
+		[]string{string(entity.ProjectBootstrapQueued), string(entity.ProjectBootstrapRunning)},
+	).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count != 0 {
+		return nil, fmt.Errorf(
+			"%w: a bootstrap of project %s is already in progress",
+			entity.ErrConflict, params.Project,
+		)
+	}
+	m := model.NewProjectBootstrapJob(params)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Claim starts the oldest queued bootstrap job and returns it, or nil when no job is queued.
+// A job claimed by another worker meanwhile is skipped.
+func (r *ProjectBootstrapJob) Claim(db *gorm.DB) (*entity.ProjectBootstrapJob, error) {
+	for {
+		var m model.ProjectBootstrapJob
+		if err := db.Where(
+			"`status` = ?", string(entity.ProjectBootstrapQueued),
+		).Order("`id` asc").Take(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		now := time.Now().UTC()
+		result := db.Model(&model.ProjectBootstrapJob{}).Where(
+			"`id` = ?", m.ID,
+		).Where(
+			"`status` = ?", string(entity.ProjectBootstrapQueued),
+		).Updates(map[string]interface{}{
+			"status":          string(entity.ProjectBootstrapRunning),
+			"started_at_utc":  now,
+			"modified_at_utc": now,
+		})
+		if err := result.Error; err != nil {
+			return nil, err
+		}
+		if result.RowsAffected != 0 {
+			m.Status = string(entity.ProjectBootstrapRunning)
+			m.StartedAtUTC = &now
+			m.ModifiedAtUTC = now
+			return m.Entity(), nil
+		}
+	}
+}
+
+func projectBootstrapResultColumns(result *entity.ProjectBootstrapResult) map[string]interface{} {
+	return map[string]interface{}{
+		"settings_copied":     result.Settings,
+		"directories_copied":  result.Directories,
+		"categories_imported": result.Categories,
+		"groups_imported":     result.Groups,
+	}
+}
+
+// Progress records the step a running bootstrap job starts, done of total, and what its
+// previous steps copied.
+func (r *ProjectBootstrapJob) Progress(
+	db *gorm.DB,
+	id int32,
+	step string,
+	total, done int64,
+	result *entity.ProjectBootstrapResult,
+) error {
+	columns := projectBootstrapResultColumns(result)
+	columns["step"] = step
+	columns["total"] = total
+	columns["done"] = done
+	columns["modified_at_utc"] = time.Now().UTC()
+	return db.Model(&model.ProjectBootstrapJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ProjectBootstrapRunning),
+	).Updates(columns).Error
+}
+
+// Complete records what a running bootstrap job copied.
+func (r *ProjectBootstrapJob) Complete(
+	db *gorm.DB,
+	id int32,
+	result *entity.ProjectBootstrapResult,
+) error {
+	now := time.Now().UTC()
+	columns := projectBootstrapResultColumns(result)
+	columns["status"] = string(entity.ProjectBootstrapCompleted)
+	columns["step"] = ""
+	columns["done"] = gorm.Expr("`total`")
+	columns["completed_at_utc"] = now
+	columns["modified_at_utc"] = now
+	return db.Model(&model.ProjectBootstrapJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ProjectBootstrapRunning),
+	).Updates(columns).Error
+}
+
+// Fail fails a running bootstrap job. The step it failed at is kept, but not the counts of
+// its previous steps, which were rolled back with it.
+func (r *ProjectBootstrapJob) Fail(db *gorm.DB, id int32, errMessage string) error {
+	now := time.Now().UTC()
+	columns := projectBootstrapResultColumns(&entity.ProjectBootstrapResult{})
+	columns["status"] = string(entity.ProjectBootstrapFailed)
+	columns["error"] = errMessage
+	columns["completed_at_utc"] = now
+	columns["modified_at_utc"] = now
+	return db.Model(&model.ProjectBootstrapJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ProjectBootstrapRunning),
+	).Updates(columns).Error
+}
+
+// FailStale fails the bootstrap jobs which made no progress since the given time, whose worker
+// was stopped before finishing them.
+func (r *ProjectBootstrapJob) FailStale(db *gorm.DB, before time.Time) (int64, error) {
+	now := time.Now().UTC()
+	result := db.Model(&model.ProjectBootstrapJob{}).Where(
+		"`status` = ?", string(entity.ProjectBootstrapRunning),
+	).Where(
+		"`modified_at_utc` < ?", before,
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ProjectBootstrapFailed),
+		"error":            "the bootstrap was interrupted",
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// CreateProject creates the project of a bootstrap job.
+func (r *ProjectBootstrapJob) CreateProject(
+	tx *gorm.DB,
+	project string,
+	createdBy string,
+) error {
+	now := time.Now().UTC()
+	if err := tx.Create(&model.ProjectInfo{
+		KeyName:       project,
+		CreatedAtUTC:  &now,
+		ModifiedAtUTC: &now,
+		ModifiedBy:    &createdBy,
+		CreatedBy:     &createdBy,
+	}).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return fmt.Errorf("%w: project %q is already exists", entity.ErrConflict, project)
+		}
+		return err
+	}
+	return nil
+}
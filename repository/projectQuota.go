@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProjectQuota struct {
+	db *gorm.DB
+}
+
+func NewProjectQuota(db *gorm.DB) (*ProjectQuota, error) {
+	if err := db.AutoMigrate(&model.ProjectQuota{}, &model.ProjectQuotaUsage{}); err != nil {
+		return nil, err
+	}
+	return &ProjectQuota{
+		db: db,
+	}, nil
+}
+
+func (r *ProjectQuota) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ProjectQuota) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// quotaDay returns the UTC day of the usage counters at now, and the time they are reset.
+func quotaDay(now time.Time) (string, time.Time) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return day.Format("2006-01-02"), day.AddDate(0, 0, 1)
+}
+
+// List returns the quotas of the project with their usage of the current day.
+func (r *ProjectQuota) List(
+	db *gorm.DB,
+	params *entity.ListProjectQuotasParams,
+) ([]*entity.ProjectQuota, error) {
+	var models []*model.ProjectQuota
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Order("`operation` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	day, _ := quotaDay(time.Now())
+	var usages []*model.ProjectQuotaUsage
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`day` = ?", day,
+	).Find(&usages).Error; err != nil {
+		return nil, err
+	}
+	used := map[string]int32{}
+	for _, u := range usages {
+		used[u.Operation] = u.Used
+	}
+
+	entities := make([]*entity.ProjectQuota, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+		entities[i].Used = used[m.Operation]
+	}
+	return entities, nil
+}
+
+func (r *ProjectQuota) getQuota(
+	db *gorm.DB,
+	project string,
+	operation entity.QuotaOperation,
+) (*model.ProjectQuota, error) {
+	var m model.ProjectQuota
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`operation` = ?", string(operation),
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *ProjectQuota) Update(
+	tx *gorm.DB,
+	params *entity.UpdateProjectQuotaParams,
+) (*entity.ProjectQuota, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	m, err := r.getQuota(tx, params.Project, params.Operation)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = &model.ProjectQuota{
+			Project:       params.Project,
+			Operation:     string(params.Operation),
+			DailyLimit:    params.DailyLimit,
+			CreatedAtUTC:  now,
+			ModifiedAtUTC: now,
+			ModifiedBy:    modifiedBy,
+			CreatedBy:     modifiedBy,
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return nil, err
+		}
+		return m.Entity(), nil
+	}
+	m.DailyLimit = params.DailyLimit
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	return m.Entity(), tx.Save(m).Error
+}
+
+func (r *ProjectQuota) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteProjectQuotaParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.ProjectQuota
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`operation` = ?", string(params.Operation),
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: quota of operation %q not found", entity.ErrRecordNotFound, params.Operation,
+		)
+	}
+	return nil
+}
+
+// Consume counts a request of the operation against the quota of the project. It returns nil
+// when the operation has no quota, and ErrQuotaExceeded with the usage when it is used up.
+func (r *ProjectQuota) Consume(
+	tx *gorm.DB,
+	params *entity.ConsumeProjectQuotaParams,
+) (*entity.QuotaUsage, error) {
+	quota, err := r.getQuota(tx, params.Project, params.Operation)
+	if err != nil || quota == nil {
+		return nil, err
+	}
+	day, resetAt := quotaDay(time.Now())
+	usage := &model.ProjectQuotaUsage{
+		Project:   params.Project,
+		Operation: string(params.Operation),
+		Day:       day,
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(usage).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(
+		"`project` = ?", usage.Project,
+	).Where(
+		"`operation` = ?", usage.Operation,
+	).Where(
+		"`day` = ?", usage.Day,
+	).Take(usage).Error; err != nil {
+		return nil, err
+	}
+
+	e := &entity.QuotaUsage{
+		Limit:      quota.DailyLimit,
+		Used:       usage.Used,
+		ResetAtUTC: resetAt,
+	}
+	if usage.Used >= quota.DailyLimit {
+		return e, fmt.Errorf(
+			"%w: daily quota of %d %s requests of project %q is used up",
+			entity.ErrQuotaExceeded, quota.DailyLimit, params.Operation, params.Project,
+		)
+	}
+	if err := tx.Model(usage).Update("used", gorm.Expr("`used` + 1")).Error; err != nil {
+		return nil, err
+	}
+	e.Used++
+	return e, nil
+}
+
+// Release gives back a request counted by Consume with the usage, e.g. when it failed.
+func (r *ProjectQuota) Release(
+	tx *gorm.DB,
+	params *entity.ConsumeProjectQuotaParams,
+	usage *entity.QuotaUsage,
+) error {
+	day, _ := quotaDay(usage.ResetAtUTC.AddDate(0, 0, -1))
+	return tx.Model(&model.ProjectQuotaUsage{}).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`operation` = ?", string(params.Operation),
+	).Where(
+		"`day` = ?", day,
+	).Where(
+		"`used` > ?", 0,
+	).Update("used", gorm.Expr("`used` - 1")).Error
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// propagationLookback bounds the publish transactions checked for overdue propagations, so
+// that enabling the checker does not alert on the whole history.
+const propagationLookback = 7 * 24 * time.Hour
+
+// PublishPropagation tracks the sync of published files to the other studios of the project.
+// Only completed publishes are propagated.
+type PublishPropagation struct {
+	db       *gorm.DB
+	ps       *ProjectStudioMap
+	deadline time.Duration
+}
+
+func NewPublishPropagation(
+	db *gorm.DB,
+	ps *ProjectStudioMap,
+) (*PublishPropagation, error) {
+	deadline, err := durationFromEnv("PPI_PROPAGATION_DEADLINE", 2*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&model.PublishPropagation{}); err != nil {
+		return nil, err
+	}
+	return &PublishPropagation{
+		db:       db,
+		ps:       ps,
+		deadline: deadline,
+	}, nil
+}
+
+func (r *PublishPropagation) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *PublishPropagation) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// Deadline is the time after the publish by which the files must have arrived in every
+// studio, set by PPI_PROPAGATION_DEADLINE.
+func (r *PublishPropagation) Deadline() time.Duration {
+	return r.deadline
+}
+
+type propagatedTransaction struct {
+	LogID        string
+	Studio       string
+	RevisionPath string
+	CreatedAtUTC time.Time
+}
+
+func (r *PublishPropagation) whereTransaction(stmt *gorm.DB) *gorm.DB {
+	return stmt.Where(
+		"deleted = ?", 0,
+	).Where(
+		"operation = ?", "publish",
+	).Where(
+		"event = ?", "completed",
+	)
+}
+
+// Get returns the propagation of the publish transaction to every studio of the project
+// other than the one it was published from.
+func (r *PublishPropagation) Get(
+	db *gorm.DB,
+	params *entity.GetPublishPropagationParams,
+) (*entity.PublishPropagation, error) {
+	var t propagatedTransaction
+	if err := r.whereTransaction(db.Table("t_publish_transaction_info")).Select(
+		"log_id, studio, revision_path, created_at_utc",
+	).Where(
+		"project = ?", params.Project,
+	).Where(
+		"log_id = ?", params.LogID,
+	).Take(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: completed publish transaction with log ID %q not found",
+				entity.ErrRecordNotFound, params.LogID,
+			)
+		}
+		return nil, err
+	}
+
+	mappings, _, err := r.ps.List(db, &entity.ListProjectStudioMapParams{
+		Project: &params.Project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var models []*model.PublishPropagation
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`log_id` = ?", params.LogID,
+	).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	reported := make(map[string]*model.PublishPropagation, len(models))
+	for _, m := range models {
+		reported[m.Studio] = m
+	}
+
+	deadline := t.CreatedAtUTC.Add(r.deadline)
+	overdue := time.Now().UTC().After(deadline)
+	e := &entity.PublishPropagation{
+		Project:        params.Project,
+		LogID:          t.LogID,
+		OriginStudio:   t.Studio,
+		RevisionPath:   t.RevisionPath,
+		PublishedAtUTC: t.CreatedAtUTC,
+		DeadlineAtUTC:  deadline,
+		Studios:        []*entity.StudioPropagation{},
+	}
+	for _, mapping := range mappings {
+		if mapping.Studio == t.Studio {
+			continue
+		}
+		s := &entity.StudioPropagation{
+			Studio: mapping.Studio,
+			Status: entity.PropagationPending,
+		}
+		if m, ok := reported[mapping.Studio]; ok {
+			s = m.Entity()
+		}
+		if s.Status == entity.PropagationPending && overdue {
+			s.Status = entity.PropagationOverdue
+		}
+		e.Studios = append(e.Studios, s)
+	}
+	return e, nil
+}
+
+// Report records the status reported by the sync client of a studio.
+func (r *PublishPropagation) Report(
+	tx *gorm.DB,
+	params *entity.ReportPublishPropagationParams,
+) (*entity.StudioPropagation, error) {
+	var count int64
+	if err := r.whereTransaction(tx.Table("t_publish_transaction_info")).Where(
+		"project = ?", params.Project,
+	).Where(
+		"log_id = ?", params.LogID,
+	).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf(
+			"%w: completed publish transaction with log ID %q not found",
+			entity.ErrRecordNotFound, params.LogID,
+		)
+	}
+
+	now := time.Now().UTC()
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	arrivedAt := params.ArrivedAtUTC
+	if arrivedAt == nil && params.Status == entity.PropagationArrived {
+		arrivedAt = &now
+	}
+
+	var m model.PublishPropagation
+	err := tx.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`log_id` = ?", params.LogID,
+	).Where(
+		"`studio` = ?", params.Studio,
+	).Take(&m).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.PublishPropagation{
+			Project:      params.Project,
+			LogID:        params.LogID,
+			Studio:       params.Studio,
+			CreatedAtUTC: now,
+			CreatedBy:    createdBy,
+		}
+	}
+	m.Status = string(params.Status)
+	m.ArrivedAtUTC = arrivedAt
+	m.Message = params.Message
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = createdBy
+	if err := tx.Save(&m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// DetectOverdue records the propagations past the deadline which were neither reported nor
+// alerted yet, and returns them per project.
+func (r *PublishPropagation) DetectOverdue(
+	tx *gorm.DB,
+	now time.Time,
+) (map[string][]*entity.OverduePropagation, error) {
+	type row struct {
+		Project string
+		entity.OverduePropagation
+	}
+	var rows []*row
+	if err := tx.Table("t_publish_transaction_info AS t").Select(
+		"t.project, t.log_id, t.studio AS origin_studio, m.studio, t.revision_path, "+
+			"t.created_at_utc AS published_at_utc",
+	).Joins(
+		"INNER JOIN t_project_studio_map AS m ON m.project = t.project "+
+			"AND m.deleted = 0 AND m.studio <> t.studio",
+	).Joins(
+		"LEFT JOIN t_publish_propagation AS p ON p.project = t.project "+
+			"AND p.log_id = t.log_id AND p.studio = m.studio",
+	).Where(
+		"t.deleted = ?", 0,
+	).Where(
+		"t.operation = ?", "publish",
+	).Where(
+		"t.event = ?", "completed",
+	).Where(
+		"t.created_at_utc BETWEEN ? AND ?", now.Add(-propagationLookback), now.Add(-r.deadline),
+	).Where(
+		"p.id IS NULL",
+	).Order("t.project, t.created_at_utc, m.studio").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("DetectOverdue: %w", err)
+	}
+
+	overdue := map[string][]*entity.OverduePropagation{}
+	for _, row := range rows {
+		if err := tx.Create(&model.PublishPropagation{
+			Project:       row.Project,
+			LogID:         row.LogID,
+			Studio:        row.Studio,
+			Status:        string(entity.PropagationPending),
+			AlertedAtUTC:  &now,
+			CreatedAtUTC:  now,
+			ModifiedAtUTC: now,
+		}).Error; err != nil {
+			return nil, err
+		}
+		p := row.OverduePropagation
+		overdue[row.Project] = append(overdue[row.Project], &p)
+	}
+	return overdue, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultQueryCacheTTL matches the max-age of the pivot responses.
+	defaultQueryCacheTTL = 15 * time.Second
+	// defaultQueryCacheEntries is the number of results cached per instance by default.
+	defaultQueryCacheEntries = 1000
+	// maxQueryCacheValueSize keeps the results of the largest queries, like the grouped pivot
+	// of a whole project, out of the cache.
+	maxQueryCacheValueSize = 8 << 20
+)
+
+// QueryCache caches the encoded results of heavy queries per project. The results of a
+// project are dropped at once by Invalidate when its data change, and the others expire after
+// a TTL, which bounds the staleness of the results invalidated by another instance.
+type QueryCache interface {
+	// Get returns a cached result and when it was cached.
+	Get(ctx context.Context, project, key string) ([]byte, time.Time, bool)
+	Set(ctx context.Context, project, key string, value []byte)
+	// Invalidate drops the results of a project, or of all the projects when it is empty.
+	Invalidate(ctx context.Context, project string)
+}
+
+// NewQueryCacheFromEnv returns the in-process cache configured by PPI_QUERY_CACHE_TTL, a
+// duration, and PPI_QUERY_CACHE_ENTRIES, the number of cached results. It returns nil, which
+// disables the cache, when the TTL is 0.
+func NewQueryCacheFromEnv() (QueryCache, error) {
+	ttl := defaultQueryCacheTTL
+	if v := os.Getenv("PPI_QUERY_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid PPI_QUERY_CACHE_TTL: %q", v)
+		}
+		ttl = d
+	}
+	entries := defaultQueryCacheEntries
+	if v := os.Getenv("PPI_QUERY_CACHE_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid PPI_QUERY_CACHE_ENTRIES: %q", v)
+		}
+		entries = n
+	}
+	if ttl == 0 {
+		return nil, nil
+	}
+	return NewMemoryQueryCache(ttl, entries), nil
+}
+
+type queryCacheEntry struct {
+	value    []byte
+	cachedAt time.Time
+}
+
+// MemoryQueryCache is a QueryCache of the instance. When full, it drops the expired results,
+// then the oldest ones.
+type MemoryQueryCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]map[string]*queryCacheEntry
+	size    int
+}
+
+func NewMemoryQueryCache(ttl time.Duration, maxEntries int) *MemoryQueryCache {
+	return &MemoryQueryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]map[string]*queryCacheEntry{},
+	}
+}
+
+func (c *MemoryQueryCache) Get(
+	ctx context.Context,
+	project string,
+	key string,
+) ([]byte, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[project][key]
+	if !ok || time.Since(e.cachedAt) >= c.ttl {
+		return nil, time.Time{}, false
+	}
+	return e.value, e.cachedAt, true
+}
+
+func (c *MemoryQueryCache) Set(ctx context.Context, project, key string, value []byte) {
+	if len(value) > maxQueryCacheValueSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[project][key]; !ok {
+		if c.size >= c.maxEntries {
+			c.evict()
+		}
+		c.size++
+	}
+	projectEntries, ok := c.entries[project]
+	if !ok {
+		projectEntries = map[string]*queryCacheEntry{}
+		c.entries[project] = projectEntries
+	}
+	projectEntries[key] = &queryCacheEntry{value: value, cachedAt: time.Now().UTC()}
+}
+
+// evict drops the expired entries, or the oldest one when none expired.
+func (c *MemoryQueryCache) evict() {
+	var oldestProject, oldestKey string
+	var oldest time.Time
+	for project, projectEntries := range c.entries {
+		for key, e := range projectEntries {
+			if time.Since(e.cachedAt) >= c.ttl {
+				delete(projectEntries, key)
+				c.size--
+				continue
+			}
+			if oldest.IsZero() || e.cachedAt.Before(oldest) {
+				oldestProject, oldestKey, oldest = project, key, e.cachedAt
+			}
+		}
+		if len(projectEntries) == 0 {
+			delete(c.entries, project)
+		}
+	}
+	if c.size >= c.maxEntries && !oldest.IsZero() {
+		delete(c.entries[oldestProject], oldestKey)
+		c.size--
+	}
+}
+
+func (c *MemoryQueryCache) Invalidate(ctx context.Context, project string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if project == "" {
+		c.entries = map[string]map[string]*queryCacheEntry{}
+		c.size = 0
+		return
+	}
+	c.size -= len(c.entries[project])
+	delete(c.entries, project)
+}
+
+// queryCacheKey identifies the result of a query by its name and its parameters.
+func queryCacheKey(name string, params interface{}) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return name + ":" + hex.EncodeToString(sum[:]), nil
+}
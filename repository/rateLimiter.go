@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// rateLimitSweepInterval is how often the buckets refilled to their burst are dropped.
+const rateLimitSweepInterval = 5 * time.Minute
+
+// Default limits of the clients. The limits per IP are looser, since the users of a studio
+// share the IP of its proxy.
+var (
+	defaultTokenReadLimit  = entity.RateLimit{Rate: 20, Burst: 100}
+	defaultTokenWriteLimit = entity.RateLimit{Rate: 5, Burst: 20}
+	defaultIPReadLimit     = entity.RateLimit{Rate: 100, Burst: 500}
+	defaultIPWriteLimit    = entity.RateLimit{Rate: 20, Burst: 100}
+)
+
+type rateLimitBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimiter keeps the token buckets of the clients of the instance, per token and per IP
+// with distinct limits for the reads and the writes.
+type RateLimiter struct {
+	tokenRead  entity.RateLimit
+	tokenWrite entity.RateLimit
+	ipRead     entity.RateLimit
+	ipWrite    entity.RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+// parseRateLimit parses a limit like "20:100", a rate per second and a burst, or "0" to
+// disable it.
+func parseRateLimit(key string, defaultValue entity.RateLimit) (entity.RateLimit, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue, nil
+	}
+	if v == "0" {
+		return entity.RateLimit{}, nil
+	}
+	rate, burst, ok := strings.Cut(v, ":")
+	r, err := strconv.ParseFloat(rate, 64)
+	if err != nil || r < 0 || !ok {
+		return entity.RateLimit{}, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	b, err := strconv.Atoi(burst)
+	if err != nil || b < 1 {
+		return entity.RateLimit{}, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	return entity.RateLimit{Rate: r, Burst: b}, nil
+}
+
+// NewRateLimiterFromEnv returns the rate limiter configured by PPI_RATE_LIMIT_TOKEN_READ,
+// PPI_RATE_LIMIT_TOKEN_WRITE, PPI_RATE_LIMIT_IP_READ and PPI_RATE_LIMIT_IP_WRITE.
+func NewRateLimiterFromEnv() (*RateLimiter, error) {
+	r := &RateLimiter{
+		buckets:   map[string]*rateLimitBucket{},
+		lastSweep: time.Now(),
+	}
+	var err error
+	if r.tokenRead, err = parseRateLimit(
+		"PPI_RATE_LIMIT_TOKEN_READ", defaultTokenReadLimit,
+	); err != nil {
+		return nil, err
+	}
+	if r.tokenWrite, err = parseRateLimit(
+		"PPI_RATE_LIMIT_TOKEN_WRITE", defaultTokenWriteLimit,
+	); err != nil {
+		return nil, err
+	}
+	if r.ipRead, err = parseRateLimit("PPI_RATE_LIMIT_IP_READ", defaultIPReadLimit); err != nil {
+		return nil, err
+	}
+	if r.ipWrite, err = parseRateLimit("PPI_RATE_LIMIT_IP_WRITE", defaultIPWriteLimit); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// refill returns the bucket of the key refilled until now.
+func (r *RateLimiter) refill(key string, limit entity.RateLimit, now time.Time) *rateLimitBucket {
+	b, ok := r.buckets[key]
+	if !ok {
+		b = &rateLimitBucket{tokens: float64(limit.Burst), updated: now}
+		r.buckets[key] = b
+		return b
+	}
+	b.tokens = math.Min(
+		float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate,
+	)
+	b.updated = now
+	return b
+}
+
+// sweep drops the buckets which would be full by now, as if they were never used.
+func (r *RateLimiter) sweep(now time.Time) {
+	for key, b := range r.buckets {
+		limit := r.limitOf(key)
+		if limit.Rate == 0 ||
+			b.tokens+now.Sub(b.updated).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(r.buckets, key)
+		}
+	}
+	r.lastSweep = now
+}
+
+// limitOf returns the limit of a bucket from the prefix of its key.
+func (r *RateLimiter) limitOf(key string) entity.RateLimit {
+	switch {
+	case strings.HasPrefix(key, "tr:"):
+		return r.tokenRead
+	case strings.HasPrefix(key, "tw:"):
+		return r.tokenWrite
+	case strings.HasPrefix(key, "ir:"):
+		return r.ipRead
+	}
+	return r.ipWrite
+}
+
+// Allow takes a request from the buckets of the token and of the IP of the client, unless
+// either is empty.
+func (r *RateLimiter) Allow(params *entity.RateLimitParams) *entity.RateLimitDecision {
+	tokenKey, ipKey := "tr:", "ir:"
+	if params.Write {
+		tokenKey, ipKey = "tw:", "iw:"
+	}
+	type check struct {
+		key   string
+		limit entity.RateLimit
+	}
+	var checks []check
+	if limit := r.limitOf(tokenKey); params.Token != "" && limit.Rate > 0 {
+		checks = append(checks, check{tokenKey + params.Token, limit})
+	}
+	if limit := r.limitOf(ipKey); params.IP != "" && limit.Rate > 0 {
+		checks = append(checks, check{ipKey + params.IP, limit})
+	}
+	decision := &entity.RateLimitDecision{Allowed: true}
+	if len(checks) == 0 {
+		return decision
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.Sub(r.lastSweep) >= rateLimitSweepInterval {
+		r.sweep(now)
+	}
+	buckets := make([]*rateLimitBucket, len(checks))
+	decision.Remaining = math.MaxInt
+	for i, c := range checks {
+		b := r.refill(c.key, c.limit, now)
+		buckets[i] = b
+		if b.tokens < 1 {
+			decision.Allowed = false
+			wait := time.Duration((1 - b.tokens) / c.limit.Rate * float64(time.Second))
+			if wait > decision.RetryAfter {
+				decision.RetryAfter = wait
+			}
+		}
+		if remaining := int(b.tokens); remaining <= decision.Remaining {
+			decision.Remaining = remaining
+			decision.Limit = c.limit.Burst
+		}
+	}
+	if !decision.Allowed {
+		return decision
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	decision.Remaining--
+	return decision
+}
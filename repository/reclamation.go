@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reclamation stores the requests to delete old takes to free disk space, and the takes of
+// each of them.
+type Reclamation struct {
+	db *gorm.DB
+}
+
+func NewReclamation(db *gorm.DB) (*Reclamation, error) {
+	if err := db.AutoMigrate(
+		&model.Reclamation{},
+		&model.ReclamationTake{},
+	); err != nil {
+		return nil, err
+	}
+	return &Reclamation{
+		db: db,
+	}, nil
+}
+
+func (r *Reclamation) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Reclamation) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *Reclamation) take(db *gorm.DB, project string, id int32) (*model.Reclamation, error) {
+	var m model.Reclamation
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: reclamation with ID %d", entity.ErrRecordNotFound, id)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// transit moves the reclamation from the status from with values. The update locks the row
+// until the end of the transaction, so only the first of concurrent transitions succeeds.
+func (r *Reclamation) transit(
+	tx *gorm.DB,
+	project string,
+	id int32,
+	from entity.ReclamationStatus,
+	values map[string]interface{},
+) (*entity.Reclamation, error) {
+	result := tx.Model(&model.Reclamation{}).Where(
+		"`project` = ?", project,
+	).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(from),
+	).Updates(values)
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+	m, err := r.take(tx, project, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf(
+			"%w: reclamation with ID %d is %s", entity.ErrBadRequest, id, m.Status,
+		)
+	}
+	if m.Status != string(entity.ReclamationApproved) {
+		if err := tx.Model(&model.ReclamationTake{}).Where(
+			"`reclamation_id` = ?", id,
+		).Update("pending", false).Error; err != nil {
+			return nil, err
+		}
+	}
+	return r.withTakes(tx, m)
+}
+
+func (r *Reclamation) withTakes(db *gorm.DB, m *model.Reclamation) (*entity.Reclamation, error) {
+	var takes []*model.ReclamationTake
+	if err := db.Where(
+		"`reclamation_id` = ?", m.ID,
+	).Order("`id` asc").Find(&takes).Error; err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	e.Takes = make([]*entity.ReclamationTake, len(takes))
+	for i, t := range takes {
+		e.Takes[i] = t.Entity()
+	}
+	return e, nil
+}
+
+// List returns the reclamations of a project, latest first, without their takes.
+func (r *Reclamation) List(
+	db *gorm.DB,
+	params *entity.ListReclamationsParams,
+) ([]*entity.Reclamation, uint, error) {
+	stmt := db.Model(&model.Reclamation{}).Where("`project` = ?", params.Project)
+	if params.Status != nil {
+		stmt = stmt.Where("`status` = ?", string(*params.Status))
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.Reclamation
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.Reclamation, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, uint(total), nil
+}
+
+func (r *Reclamation) Get(
+	db *gorm.DB,
+	params *entity.GetReclamationParams,
+) (*entity.Reclamation, error) {
+	m, err := r.take(db, params.Project, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.withTakes(db, m)
+}
+
+// Create requests the deletion of the takes of review infos. The review infos are locked
+// until the end of the transaction, so that concurrent requests of a take are serialized and
+// only the first one succeeds.
+func (r *Reclamation) Create(
+	tx *gorm.DB,
+	params *entity.CreateReclamationParams,
+) (*entity.Reclamation, error) {
+	ids := make([]int32, 0, len(params.ReviewInfoIDs))
+	seen := make(map[int32]bool, len(params.ReviewInfoIDs))
+	for _, id := range params.ReviewInfoIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	var reviews []*model.ReviewInfo
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`id` IN ?", ids,
+	).Order("`id` asc").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	if len(reviews) != len(ids) {
+		for _, m := range reviews {
+			delete(seen, m.ID)
+		}
+		missing := make([]int32, 0, len(seen))
+		for _, id := range ids {
+			if seen[id] {
+				missing = append(missing, id)
+			}
+		}
+		return nil, fmt.Errorf(
+			"%w: review infos with IDs %v not found", entity.ErrBadRequest, missing,
+		)
+	}
+
+	var pending []int32
+	if err := tx.Model(&model.ReclamationTake{}).Where(
+		"`review_info_id` IN ?", ids,
+	).Where(
+		"`pending` = ?", true,
+	).Distinct().Pluck("review_info_id", &pending).Error; err != nil {
+		return nil, err
+	}
+	if len(pending) != 0 {
+		return nil, fmt.Errorf(
+			"%w: takes of review infos with IDs %v are already in a pending reclamation",
+			entity.ErrConflict, pending,
+		)
+	}
+
+	m := model.NewReclamation(params)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	takes := make([]*model.ReclamationTake, len(reviews))
+	for i, ri := range reviews {
+		takes[i] = &model.ReclamationTake{
+			ReclamationID: m.ID,
+			ReviewInfoID:  ri.ID,
+			Pending:       true,
+			Root:          ri.Root,
+			Group1:        ri.Group1,
+			Relation:      ri.Relation,
+			Phase:         ri.Phase,
+			Take:          ri.Take,
+			TakePath:      ri.TakePath,
+		}
+	}
+	if err := tx.Create(takes).Error; err != nil {
+		return nil, err
+	}
+	return r.withTakes(tx, m)
+}
+
+// Review approves or rejects a requested reclamation. A reclamation cannot be reviewed by its
+// requester.
+func (r *Reclamation) Review(
+	tx *gorm.DB,
+	params *entity.ReviewReclamationParams,
+) (*entity.Reclamation, error) {
+	m, err := r.take(tx, params.Project, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if m.CreatedBy == params.ReviewedBy {
+		return nil, fmt.Errorf(
+			"%w: reclamation with ID %d cannot be reviewed by its requester",
+			entity.ErrForbidden, params.ID,
+		)
+	}
+	status := entity.ReclamationRejected
+	if params.Approve {
+		status = entity.ReclamationApproved
+	}
+	now := time.Now().UTC()
+	return r.transit(tx, params.Project, params.ID, entity.ReclamationRequested,
+		map[string]interface{}{
+			"status":          string(status),
+			"reviewed_by":     params.ReviewedBy,
+			"reviewed_at_utc": now,
+			"review_comment":  params.Comment,
+			"modified_at_utc": now,
+			"modified_by":     params.ReviewedBy,
+		},
+	)
+}
+
+// Complete records the bytes freed by the deletion of the takes of an approved reclamation.
+func (r *Reclamation) Complete(
+	tx *gorm.DB,
+	params *entity.CompleteReclamationParams,
+) (*entity.Reclamation, error) {
+	now := time.Now().UTC()
+	return r.transit(tx, params.Project, params.ID, entity.ReclamationApproved,
+		map[string]interface{}{
+			"status":           string(entity.ReclamationCompleted),
+			"completed_by":     params.CompletedBy,
+			"completed_at_utc": now,
+			"freed_bytes":      *params.FreedBytes,
+			"modified_at_utc":  now,
+			"modified_by":      params.CompletedBy,
+		},
+	)
+}
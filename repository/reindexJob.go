@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// reindexJobListLimit limits the number of reindex jobs listed.
+const reindexJobListLimit = 100
+
+// ReindexJob stores the jobs rebuilding the materialized data of projects, and rebuilds the
+// latest reviews of the assets and shots of a project a batch at a time for them.
+type ReindexJob struct {
+	db *gorm.DB
+}
+
+func NewReindexJob(db *gorm.DB) (*ReindexJob, error) {
+	if err := db.AutoMigrate(&model.ReindexJob{}); err != nil {
+		return nil, err
+	}
+	return &ReindexJob{
+		db: db,
+	}, nil
+}
+
+func (r *ReindexJob) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ReindexJob) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// List returns the latest reindex jobs, of a project when given.
+func (r *ReindexJob) List(
+	db *gorm.DB,
+	params *entity.ListReindexJobsParams,
+) ([]*entity.ReindexJob, error) {
+	stmt := db.Model(&model.ReindexJob{})
+	if params.Project != nil {
+		stmt = stmt.Where("`project` = ?", *params.Project)
+	}
+	var models []*model.ReindexJob
+	if err := stmt.Order(
+		"`id` desc",
+	).Limit(reindexJobListLimit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ReindexJob, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *ReindexJob) Get(
+	db *gorm.DB,
+	params *entity.GetReindexJobParams,
+) (*entity.ReindexJob, error) {
+	var m model.ReindexJob
+	if err := db.Where("`id` = ?", params.ID).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: reindex job with ID %d", entity.ErrRecordNotFound, params.ID)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Create queues a reindex job, unless one of the same kind is queued or running for the
+// project.
+func (r *ReindexJob) Create(
+	tx *gorm.DB,
+	params *entity.CreateReindexJobParams,
+) (*entity.ReindexJob, error) {
+	var pending int64
+	if err := tx.Model(&model.ReindexJob{}).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`kind` = ?", params.Kind,
+	).Where(
+		"`status` IN ?", []string{string(entity.ReindexQueued), string(entity.ReindexRunning)},
+	).Count(&pending).Error; err != nil {
+		return nil, err
+	}
+	if pending != 0 {
+		return nil, fmt.Errorf(
+			"%w: a %s reindex of %s is already queued or running",
+			entity.ErrBadRequest, params.Kind, params.Project,
+		)
+	}
+	m := model.NewReindexJob(params)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Claim starts the oldest queued reindex job and returns it, or nil when no job is queued.
+// A job claimed by another worker meanwhile is skipped.
+func (r *ReindexJob) Claim(db *gorm.DB) (*entity.ReindexJob, error) {
+	for {
+		var m model.ReindexJob
+		if err := db.Where(
+			"`status` = ?", string(entity.ReindexQueued),
+		).Order("`id` asc").Take(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		now := time.Now().UTC()
+		result := db.Model(&model.ReindexJob{}).Where(
+			"`id` = ?", m.ID,
+		).Where(
+			"`status` = ?", string(entity.ReindexQueued),
+		).Updates(map[string]interface{}{
+			"status":          string(entity.ReindexRunning),
+			"started_at_utc":  now,
+			"modified_at_utc": now,
+		})
+		if err := result.Error; err != nil {
+			return nil, err
+		}
+		if result.RowsAffected != 0 {
+			m.Status = string(entity.ReindexRunning)
+			m.StartedAtUTC = &now
+			m.ModifiedAtUTC = now
+			return m.Entity(), nil
+		}
+	}
+}
+
+// Progress records the progress of a running reindex job.
+func (r *ReindexJob) Progress(db *gorm.DB, id int32, total, done, rows int64) error {
+	return db.Model(&model.ReindexJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ReindexRunning),
+	).Updates(map[string]interface{}{
+		"total":           total,
+		"done":            done,
+		"rows":            rows,
+		"modified_at_utc": time.Now().UTC(),
+	}).Error
+}
+
+// Complete completes a running reindex job.
+func (r *ReindexJob) Complete(db *gorm.DB, id int32) error {
+	now := time.Now().UTC()
+	return db.Model(&model.ReindexJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ReindexRunning),
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ReindexCompleted),
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	}).Error
+}
+
+// Fail fails a running reindex job.
+func (r *ReindexJob) Fail(db *gorm.DB, id int32, errMessage string) error {
+	now := time.Now().UTC()
+	return db.Model(&model.ReindexJob{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.ReindexRunning),
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ReindexFailed),
+		"error":            errMessage,
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	}).Error
+}
+
+// FailStale fails the running reindex jobs without any progress since the given time, whose
+// worker was stopped before finishing them.
+func (r *ReindexJob) FailStale(db *gorm.DB, before time.Time) (int64, error) {
+	now := time.Now().UTC()
+	result := db.Model(&model.ReindexJob{}).Where(
+		"`status` = ?", string(entity.ReindexRunning),
+	).Where(
+		"`modified_at_utc` < ?", before,
+	).Updates(map[string]interface{}{
+		"status":           string(entity.ReindexFailed),
+		"error":            "the reindex was interrupted",
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// ReviewLatestGroup is an asset or shot of the latest reviews of a project.
+type ReviewLatestGroup struct {
+	Root   string
+	Group1 string `gorm:"column:group_1"`
+}
+
+// ListLatestGroups returns the assets and shots of a project having reviews or latest
+// reviews, so that the latest reviews of those without reviews anymore are removed too.
+func (r *ReindexJob) ListLatestGroups(db *gorm.DB, project string) ([]ReviewLatestGroup, error) {
+	var groups []ReviewLatestGroup
+	if err := db.Raw(
+		"SELECT root, group_1 FROM (? UNION ?) AS g ORDER BY root, group_1",
+		db.Model(&model.ReviewInfo{}).Select("root, group_1").Where(
+			"`project` = ?", project,
+		).Where(
+			"`deleted` = ?", 0,
+		).Where("`group_1` IS NOT NULL"),
+		db.Model(&model.ReviewLatest{}).Select("root, group_1").Where("`project` = ?", project),
+	).Scan(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// RefreshLatestGroups rebuilds the latest reviews of assets or shots of a root of a project,
+// and returns the number of rows written.
+func (r *ReindexJob) RefreshLatestGroups(
+	tx *gorm.DB,
+	project string,
+	root string,
+	groups []string,
+) (int64, error) {
+	return refreshReviewLatest(tx, project, root, groups)
+}
+
+// SaveLatestState records the rebuild of the latest reviews of a project, so that the asset
+// pivot and the latest submission counts read them from then on.
+func (r *ReindexJob) SaveLatestState(
+	tx *gorm.DB,
+	project string,
+	rows int64,
+	rebuiltBy string,
+) (*entity.ReviewLatestState, error) {
+	return saveReviewLatestState(tx, project, rows, rebuiltBy)
+}
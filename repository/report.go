@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// Report aggregates review infos and their status logs for project reports.
+type Report struct {
+	db *gorm.DB
+}
+
+func NewReport(db *gorm.DB) *Report {
+	return &Report{
+		db: db,
+	}
+}
+
+func (r *Report) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// ListReviewerActivities returns, per reviewer and phase, the number of reviews the reviewer
+// set an approval status on since the given time, and the average time between the submission
+// of these reviews and the first approval status set by the reviewer. Latencies count the
+// working time of the business clock of the review's studio, or every hour when the studio
+// has none.
+func (r *Report) ListReviewerActivities(
+	db *gorm.DB,
+	params *entity.GetReviewerLoadParams,
+	since time.Time,
+	clocks map[string]*entity.BusinessClock,
+) ([]*entity.ReviewerActivity, error) {
+	firstLogs := db.Table("t_review_status_log").Select(
+		"review_info_id, created_by, MIN(created_at_utc) AS first_at_utc",
+	).Where(
+		"project = ?", params.Project,
+	).Where(
+		"status_type = ?", entity.ReviewStatusTypeApproval,
+	).Where(
+		"created_at_utc >= ?", since,
+	).Group("review_info_id, created_by")
+
+	stmt := db.Table("t_review_info AS ri").Select(
+		"f.created_by AS reviewer, ri.phase, ri.studio, ri.submitted_at_utc, f.first_at_utc",
+	).Joins(
+		"INNER JOIN (?) AS f ON f.review_info_id = ri.id", firstLogs,
+	).Where(
+		"ri.deleted = ?", 0,
+	).Where(
+		"ri.project = ?", params.Project,
+	)
+	stmt = whereReportTarget(stmt, params)
+
+	type response struct {
+		Reviewer       string
+		Phase          string
+		Studio         string
+		SubmittedAtUtc time.Time
+		FirstAtUtc     time.Time
+	}
+	var responses []response
+	if err := stmt.Order(
+		"ri.phase, f.created_by",
+	).Scan(&responses).Error; err != nil {
+		return nil, fmt.Errorf("ListReviewerActivities: %w", err)
+	}
+
+	var entities []*entity.ReviewerActivity
+	var latencies float64
+	for _, res := range responses {
+		last := len(entities) - 1
+		if last < 0 || entities[last].Phase != res.Phase ||
+			entities[last].Reviewer != res.Reviewer {
+			if last >= 0 {
+				entities[last].AverageLatencySeconds = latencies /
+					float64(entities[last].ReviewedReviews)
+			}
+			entities = append(entities, &entity.ReviewerActivity{
+				Reviewer: res.Reviewer,
+				Phase:    res.Phase,
+			})
+			last++
+			latencies = 0
+		}
+		entities[last].ReviewedReviews++
+		if d := clocks[res.Studio].Duration(res.SubmittedAtUtc, res.FirstAtUtc); d > 0 {
+			latencies += d.Seconds()
+		}
+	}
+	if last := len(entities) - 1; last >= 0 {
+		entities[last].AverageLatencySeconds = latencies / float64(entities[last].ReviewedReviews)
+	}
+	return entities, nil
+}
+
+// ListPendingReviews returns the reviews which did not get any approval status yet, oldest
+// first, with the reviewer who last reviewed the same asset or shot and phase. The reviews
+// approved with a legacy status mapped to approved are not pending.
+func (r *Report) ListPendingReviews(
+	db *gorm.DB,
+	params *entity.GetReviewerLoadParams,
+) ([]*entity.PendingReview, error) {
+	vocab, err := loadStatusVocabulary(db, params.Project)
+	if err != nil {
+		return nil, fmt.Errorf("ListPendingReviews: %w", err)
+	}
+	lastReviewer := db.Table("t_review_status_log AS l").Select(
+		"l.created_by",
+	).Joins(
+		"INNER JOIN t_review_info AS p ON p.id = l.review_info_id",
+	).Where(
+		"p.project = ri.project AND p.root = ri.root AND p.group_1 = ri.group_1 "+
+			"AND p.relation = ri.relation AND p.phase = ri.phase",
+	).Where(
+		"l.status_type = ?", entity.ReviewStatusTypeApproval,
+	).Order("l.created_at_utc DESC").Limit(1)
+
+	stmt := db.Table("t_review_info AS ri").Select(
+		"ri.id AS review_info_id, ri.root, ri.group_1 AS `group`, ri.relation, ri.phase, "+
+			"ri.submitted_at_utc, COALESCE((?), '') AS reviewer",
+		lastReviewer,
+	).Where(
+		"ri.deleted = ?", 0,
+	).Where(
+		"ri.project = ?", params.Project,
+	).Where(
+		"ri.intent <> ?", entity.ReviewIntentWIP,
+	).Where(
+		vocab.column(entity.StatusKindApproval, "ri.approval_status")+" <> ?",
+		entity.ApprovalStatusApproved,
+	).Where(
+		"NOT EXISTS (?)", db.Table("t_review_status_log AS s").Select("1").Where(
+			"s.review_info_id = ri.id",
+		).Where(
+			"s.status_type = ?", entity.ReviewStatusTypeApproval,
+		),
+	)
+	stmt = whereReportTarget(stmt, params)
+
+	var entities []*entity.PendingReview
+	if err := stmt.Order(
+		"ri.submitted_at_utc ASC, ri.id ASC",
+	).Scan(&entities).Error; err != nil {
+		return nil, fmt.Errorf("ListPendingReviews: %w", err)
+	}
+	return entities, nil
+}
+
+func whereReportTarget(stmt *gorm.DB, params *entity.GetReviewerLoadParams) *gorm.DB {
+	if params.Root != nil {
+		stmt = stmt.Where("ri.root = ?", *params.Root)
+	}
+	if params.Phase != nil {
+		stmt = stmt.Where("ri.phase = ?", *params.Phase)
+	}
+	return stmt
+}
+
+var heatmapDateFormats = map[string]string{
+	entity.HeatmapGroupByDay:   "DATE_FORMAT(submitted_at_utc, '%Y-%m-%d')",
+	entity.HeatmapGroupByWeek:  "DATE_FORMAT(DATE_SUB(submitted_at_utc, INTERVAL WEEKDAY(submitted_at_utc) DAY), '%Y-%m-%d')",
+	entity.HeatmapGroupByMonth: "DATE_FORMAT(submitted_at_utc, '%Y-%m-01')",
+}
+
+// ListSubmissionCounts counts the submitted reviews per period, root and phase in a single
+// query.
+func (r *Report) ListSubmissionCounts(
+	db *gorm.DB,
+	params *entity.GetSubmissionHeatmapParams,
+) ([]*entity.SubmissionCount, error) {
+	date, ok := heatmapDateFormats[params.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid group_by %q", entity.ErrBadRequest, params.GroupBy)
+	}
+	var entities []*entity.SubmissionCount
+	if err := db.Table("t_review_info").Select(
+		date+" AS date, root, phase, COUNT(*) AS count",
+	).Where(
+		"deleted = ?", 0,
+	).Where(
+		"project = ?", params.Project,
+	).Where(
+		"submitted_at_utc >= ?", params.From,
+	).Where(
+		"submitted_at_utc < ?", params.To.AddDate(0, 0, 1),
+	).Group(
+		"date, root, phase",
+	).Order("date, root, phase").Scan(&entities).Error; err != nil {
+		return nil, fmt.Errorf("ListSubmissionCounts: %w", err)
+	}
+	return entities, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"expvar"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// retryMetrics counts, per client name, the retried requests, the ones which succeeded after
+// retries and the ones which failed once the attempts or the budget ran out. They are
+// exported with the other expvar variables.
+var retryMetrics = expvar.NewMap("http_retries")
+
+const (
+	retryBaseDelay = 200 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// RetryTransport retries the idempotent requests, GET and HEAD, which failed with a network
+// error, 429 or a 5xx status. Retries wait with full jitter exponential backoff, and stop
+// when either the attempts or the time budget of the request run out, so that a failing
+// service still fails fast enough for the user.
+type RetryTransport struct {
+	base        http.RoundTripper
+	name        string
+	maxAttempts int
+	budget      time.Duration
+}
+
+// NewRetryTransportFromEnv wraps base, reading the number of attempts and the budget from
+// PPI_<PREFIX>_RETRY_ATTEMPTS and PPI_<PREFIX>_RETRY_BUDGET. name labels the metrics.
+func NewRetryTransportFromEnv(
+	base http.RoundTripper,
+	name string,
+	prefix string,
+) (*RetryTransport, error) {
+	budget, err := durationFromEnv("PPI_"+prefix+"_RETRY_BUDGET", 20*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	maxAttempts := 4
+	key := "PPI_" + prefix + "_RETRY_ATTEMPTS"
+	if v := os.Getenv(key); v != "" {
+		maxAttempts, err = strconv.Atoi(v)
+		if err != nil || maxAttempts < 1 {
+			return nil, fmt.Errorf("invalid %s: %q", key, v)
+		}
+	}
+	return &RetryTransport{
+		base:        base,
+		name:        name,
+		maxAttempts: maxAttempts,
+		budget:      budget,
+	}, nil
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryDelay returns the wait before the given retry, honoring the Retry-After header in
+// seconds.
+func retryDelay(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+			if d := time.Duration(s) * time.Second; d < retryMaxDelay {
+				return d
+			}
+			return retryMaxDelay
+		}
+	}
+	ceiling := retryBaseDelay << uint(retry)
+	if ceiling <= 0 || ceiling > retryMaxDelay {
+		ceiling = retryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+	deadline := time.Now().Add(t.budget)
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if !retryable(resp, err) {
+			if attempt > 1 {
+				retryMetrics.Add(t.name+".recovered", 1)
+			}
+			return resp, err
+		}
+		delay := retryDelay(attempt-1, resp)
+		if attempt >= t.maxAttempts || time.Now().Add(delay).After(deadline) {
+			if attempt > 1 {
+				retryMetrics.Add(t.name+".exhausted", 1)
+			}
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		retryMetrics.Add(t.name+".retried", 1)
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
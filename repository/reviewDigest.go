@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// reviewDigestLimit limits the pending reviews of a phase of a project listed in a digest.
+const reviewDigestLimit = 500
+
+// defaultDigestPendingHours is how long the reviews are pending before they are listed in the
+// digests, unless PPI_DIGEST_PENDING_HOURS is set.
+const defaultDigestPendingHours = 24
+
+// ReviewDigest lists the pending reviews compiled into the digests of the supervisors, and
+// sends the digests with the SMTP server of PPI_DIGEST_SMTP_ADDRESS.
+type ReviewDigest struct {
+	db           *gorm.DB
+	smtpAddress  string
+	smtpAuth     smtp.Auth
+	sender       mail.Address
+	pendingHours int32
+	interval     time.Duration
+}
+
+// NewReviewDigestFromEnv returns the digests configured by
+//   - PPI_DIGEST_SMTP_ADDRESS: the host:port of the SMTP server; digests are not sent without it
+//   - PPI_DIGEST_SMTP_USERNAME and PPI_DIGEST_SMTP_PASSWORD: the PLAIN authentication, if any
+//   - PPI_DIGEST_SENDER_ADDRESS and PPI_DIGEST_SENDER_NAME: the sender, PPI_EMAIL_SENDER_ADDRESS
+//     and PPI_EMAIL_SENDER_NAME by default
+//   - PPI_DIGEST_PENDING_HOURS: the default pending time of the listed reviews, 24 by default
+//   - PPI_DIGEST_INTERVAL: the interval of the scheduled digests, e.g. 24h; none when empty
+func NewReviewDigestFromEnv(db *gorm.DB) (*ReviewDigest, error) {
+	r := &ReviewDigest{
+		db:           db,
+		smtpAddress:  os.Getenv("PPI_DIGEST_SMTP_ADDRESS"),
+		pendingHours: defaultDigestPendingHours,
+	}
+	if username := os.Getenv("PPI_DIGEST_SMTP_USERNAME"); username != "" {
+		host, _, err := net.SplitHostPort(r.smtpAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PPI_DIGEST_SMTP_ADDRESS: %q", r.smtpAddress)
+		}
+		r.smtpAuth = smtp.PlainAuth("", username, os.Getenv("PPI_DIGEST_SMTP_PASSWORD"), host)
+	}
+	r.sender = mail.Address{
+		Name:    firstEnv("PPI_DIGEST_SENDER_NAME", "PPI_EMAIL_SENDER_NAME"),
+		Address: firstEnv("PPI_DIGEST_SENDER_ADDRESS", "PPI_EMAIL_SENDER_ADDRESS"),
+	}
+	if r.sender.Address == "" {
+		r.sender.Address = "noreply@ppi.co.jp"
+	}
+	if v := os.Getenv("PPI_DIGEST_PENDING_HOURS"); v != "" {
+		hours, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || hours < 1 {
+			return nil, fmt.Errorf("invalid PPI_DIGEST_PENDING_HOURS: %q", v)
+		}
+		r.pendingHours = int32(hours)
+	}
+	if v := os.Getenv("PPI_DIGEST_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid PPI_DIGEST_INTERVAL: %q", v)
+		}
+		r.interval = interval
+	}
+	return r, nil
+}
+
+// firstEnv returns the first of the environment variables which is set.
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (r *ReviewDigest) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// PendingHours is the pending time of the reviews listed by the scheduled digests.
+func (r *ReviewDigest) PendingHours() int32 {
+	return r.pendingHours
+}
+
+// Interval is the interval of the scheduled digests, 0 when they are only run on demand.
+func (r *ReviewDigest) Interval() time.Duration {
+	return r.interval
+}
+
+// CanSend tells whether the SMTP server of the digests is set.
+func (r *ReviewDigest) CanSend() bool {
+	return r.smtpAddress != ""
+}
+
+// ListPending returns the assets of a phase of a project whose latest review has had one of
+// the entity.PendingReviewStatuses since before the given time, the longest pending first.
+func (r *ReviewDigest) ListPending(
+	db *gorm.DB,
+	project string,
+	phase string,
+	before time.Time,
+) ([]*entity.PendingReview, error) {
+	type row struct {
+		ID                         int32
+		Group1                     string `gorm:"column:group_1"`
+		Relation                   string
+		Phase                      string
+		Take                       string
+		ApprovalStatus             string
+		ApprovalStatusUpdatedAtUtc time.Time
+	}
+	var rows []row
+	if err := db.Table("t_review_info AS ri").Select(
+		"ri.id, ri.group_1, ri.relation, ri.phase, ri.take, ri.approval_status, "+
+			"ri.approval_status_updated_at_utc",
+	).Where(
+		"ri.project = ?", project,
+	).Where(
+		"ri.root = ?", "assets",
+	).Where(
+		"ri.phase = ?", phase,
+	).Where(
+		"ri.deleted = ?", 0,
+	).Where(
+		"ri.approval_status IN ?", entity.PendingReviewStatuses,
+	).Where(
+		"ri.approval_status_updated_at_utc < ?", before,
+	).Where(
+		"NOT EXISTS (?)", db.Table("t_review_info AS n").Select("1").Where(
+			"n.project = ri.project AND n.root = ri.root AND n.group_1 = ri.group_1 AND "+
+				"n.relation = ri.relation AND n.phase = ri.phase",
+		).Where(
+			"n.deleted = ?", 0,
+		).Where(
+			"(n.submitted_at_utc > ri.submitted_at_utc OR "+
+				"n.submitted_at_utc = ri.submitted_at_utc AND n.id > ri.id)",
+		),
+	).Order(
+		"ri.approval_status_updated_at_utc asc",
+	).Order(
+		"ri.id asc",
+	).Limit(reviewDigestLimit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	reviews := make([]*entity.PendingReview, len(rows))
+	for i, row := range rows {
+		reviews[i] = &entity.PendingReview{
+			Project:         project,
+			ReviewInfoID:    row.ID,
+			Group:           row.Group1,
+			Relation:        row.Relation,
+			Phase:           row.Phase,
+			Take:            row.Take,
+			ApprovalStatus:  row.ApprovalStatus,
+			PendingSinceUTC: row.ApprovalStatusUpdatedAtUtc,
+		}
+	}
+	return reviews, nil
+}
+
+// Send mails a digest to its supervisor.
+func (r *ReviewDigest) Send(digest *entity.SupervisorDigest, pendingHours int32) error {
+	if !r.CanSend() {
+		return fmt.Errorf("%w: PPI_DIGEST_SMTP_ADDRESS is not set", entity.ErrBadRequest)
+	}
+	t, err := template.ParseFiles("template/reviewDigestEmail.html")
+	if err != nil {
+		return fmt.Errorf("[EmailSender] failed to parse html template: %w", err)
+	}
+	var body bytes.Buffer
+	if err := t.Execute(&body, map[string]interface{}{
+		"PendingHours": pendingHours,
+		"Reviews":      digest.Reviews,
+	}); err != nil {
+		return fmt.Errorf("[EmailSender] failed to apply a parsed template: %w", err)
+	}
+	subject := fmt.Sprintf(
+		"%d reviews pending for more than %d hours", len(digest.Reviews), pendingHours,
+	)
+	message := buildEmail(&entity.EmailData{
+		Sender:  r.sender.Address,
+		To:      []string{digest.MailAddress},
+		Subject: subject,
+		Body:    body.String(),
+	})
+	if err := smtp.SendMail(
+		r.smtpAddress, r.smtpAuth, r.sender.Address, []string{digest.MailAddress}, message,
+	); err != nil {
+		return fmt.Errorf("[EmailSender] failed to send review digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// reviewLatestColumns are the columns of t_review_latest copied from t_review_info.
+const reviewLatestColumns = "id, project, root, group_1, relation, phase, intent, take, " +
+	"take_number, approval_status, work_status, submitted_at_utc, modified_at_utc, " +
+	"leaf_group_name, group_category_path, top_group_node, publish_transaction_id"
+
+// categoryColumns are the group category columns of t_review_info, which are not created by
+// the migrations of this service and may be missing from fresh databases.
+var categoryColumns = []string{"leaf_group_name", "group_category_path", "top_group_node"}
+
+// hasCategoryColumns tells whether the table has all the group category columns.
+func hasCategoryColumns(db *gorm.DB, table string) bool {
+	migrator := db.Migrator()
+	for _, column := range categoryColumns {
+		if !migrator.HasColumn(table, column) {
+			return false
+		}
+	}
+	return true
+}
+
+// reviewInfoLatestColumns are the columns of t_review_info copied to t_review_latest, the
+// group category columns being NULL when t_review_info has none.
+func reviewInfoLatestColumns(db *gorm.DB) string {
+	if hasCategoryColumns(db, "t_review_info") {
+		return reviewLatestColumns
+	}
+	nulls := make([]string, len(categoryColumns))
+	for i, column := range categoryColumns {
+		nulls[i] = "NULL AS " + column
+	}
+	return strings.Replace(
+		reviewLatestColumns,
+		strings.Join(categoryColumns, ", "),
+		strings.Join(nulls, ", "),
+		1,
+	)
+}
+
+// refreshReviewLatest replaces the t_review_latest rows of the assets or shots of a project
+// with the latest of their reviews, in the order of the latest submission listings. The whole
+// root is refreshed when groups is nil, and the whole project when root is also empty. It
+// returns the number of rows written.
+func refreshReviewLatest(tx *gorm.DB, project, root string, groups []string) (int64, error) {
+	stale := tx.Where("`project` = ?", project)
+	ranked := tx.Table("t_review_info").Select(
+		reviewInfoLatestColumns(tx)+", "+
+			"ROW_NUMBER() OVER ("+
+			"PARTITION BY root, group_1, relation, phase, intent "+
+			"ORDER BY modified_at_utc DESC, id DESC"+
+			") AS rn, "+
+			"ROW_NUMBER() OVER ("+
+			"PARTITION BY root, group_1, relation, phase "+
+			"ORDER BY modified_at_utc DESC, id DESC"+
+			") AS phase_rn",
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`deleted` = ?", 0,
+	).Where("`group_1` IS NOT NULL")
+	if root != "" {
+		stale = stale.Where("`root` = ?", root)
+		ranked = ranked.Where("`root` = ?", root)
+		if groups != nil {
+			stale = stale.Where("`group_1` IN ?", groups)
+			ranked = ranked.Where("`group_1` IN ?", groups)
+		}
+	}
+	if err := stale.Delete(&model.ReviewLatest{}).Error; err != nil {
+		return 0, err
+	}
+	result := tx.Exec(
+		"INSERT INTO `t_review_latest` ("+reviewLatestColumns+", phase_latest) ?",
+		tx.Table("(?) AS r", ranked).Select(reviewLatestColumns+", phase_rn = 1").Where(
+			"rn = ?", 1,
+		),
+	)
+	return result.RowsAffected, result.Error
+}
+
+// reviewLatestReady tells whether the t_review_latest rows of the project were rebuilt, so
+// that they hold all its reviews.
+func reviewLatestReady(db *gorm.DB, project string) (bool, error) {
+	var count int64
+	if err := db.Model(&model.ReviewLatestState{}).Where(
+		"`project` = ?", project,
+	).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count != 0, nil
+}
+
+// saveReviewLatestState records that the t_review_latest rows of the project were rebuilt.
+func saveReviewLatestState(
+	tx *gorm.DB,
+	project string,
+	rows int64,
+	rebuiltBy string,
+) (*entity.ReviewLatestState, error) {
+	var m model.ReviewLatestState
+	err := tx.Where("`project` = ?", project).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.ReviewLatestState{
+			Project: project,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	m.Rows = rows
+	m.RebuiltAtUTC = time.Now().UTC()
+	m.RebuiltBy = rebuiltBy
+	if err := tx.Save(&m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// slaEvaluationBatchSize limits the number of breaches recorded per SLA and evaluation.
+const slaEvaluationBatchSize = 500
+
+type ReviewSLA struct {
+	db *gorm.DB
+}
+
+func NewReviewSLA(db *gorm.DB) (*ReviewSLA, error) {
+	if err := db.AutoMigrate(&model.ReviewSLA{}, &model.ReviewSLABreach{}); err != nil {
+		return nil, err
+	}
+	return &ReviewSLA{
+		db: db,
+	}, nil
+}
+
+func (r *ReviewSLA) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ReviewSLA) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *ReviewSLA) List(
+	db *gorm.DB,
+	params *entity.ListReviewSLAsParams,
+) ([]*entity.ReviewSLA, error) {
+	var models []*model.ReviewSLA
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Order("`phase` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ReviewSLA, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// ListAll returns the SLAs of every project for the evaluator.
+func (r *ReviewSLA) ListAll(db *gorm.DB) ([]*entity.ReviewSLA, error) {
+	var models []*model.ReviewSLA
+	if err := db.Where("`deleted` = ?", 0).Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ReviewSLA, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *ReviewSLA) Update(
+	tx *gorm.DB,
+	params *entity.UpdateReviewSLAParams,
+) (*entity.ReviewSLA, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	addresses := model.MailAddresses(params.MailAddresses)
+	if addresses == nil {
+		addresses = model.MailAddresses{}
+	}
+
+	var m model.ReviewSLA
+	err := tx.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`phase` = ?", params.Phase,
+	).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.ReviewSLA{
+			Project:             params.Project,
+			Phase:               params.Phase,
+			FeedbackWithinHours: params.FeedbackWithinHours,
+			MailAddresses:       addresses,
+			CreatedAtUTC:        now,
+			ModifiedAtUTC:       now,
+			ModifiedBy:          modifiedBy,
+			CreatedBy:           modifiedBy,
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return nil, err
+		}
+		return m.Entity(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.FeedbackWithinHours = params.FeedbackWithinHours
+	m.MailAddresses = addresses
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	return m.Entity(), tx.Save(&m).Error
+}
+
+func (r *ReviewSLA) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteReviewSLAParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.ReviewSLA
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`phase` = ?", params.Phase,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: SLA of phase %q not found", entity.ErrRecordNotFound, params.Phase,
+		)
+	}
+	return nil
+}
+
+func (r *ReviewSLA) ListBreaches(
+	db *gorm.DB,
+	params *entity.ListReviewSLABreachesParams,
+) ([]*entity.ReviewSLABreach, int, error) {
+	stmt := db.Where("`project` = ?", params.Project)
+	if params.Phase != nil {
+		stmt = stmt.Where("`phase` = ?", *params.Phase)
+	}
+	if params.Unresolved {
+		stmt = stmt.Where("`resolved_at_utc` IS NULL")
+	}
+
+	var total int64
+	if err := stmt.Model(&model.ReviewSLABreach{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	stmt = stmt.Order("`detected_at_utc` desc").Order("`id` desc")
+	stmt = limitOffset(stmt, params.BaseListParams)
+
+	var models []*model.ReviewSLABreach
+	if err := stmt.Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.ReviewSLABreach, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, int(total), nil
+}
+
+// DetectBreaches records a breach for each review of the SLA's phase which did not get any
+// status log within the SLA and returns the new breaches. The SLA counts the working time of
+// the business clock of the review's studio, or every hour when the studio has none. Reviews
+// submitted before the SLA was created, deleted reviews and work in progress submissions are
+// not evaluated.
+func (r *ReviewSLA) DetectBreaches(
+	tx *gorm.DB,
+	sla *entity.ReviewSLA,
+	clocks map[string]*entity.BusinessClock,
+	now time.Time,
+) ([]*entity.ReviewSLABreach, error) {
+	within := time.Duration(sla.FeedbackWithinHours) * time.Hour
+
+	type candidate struct {
+		ID                 int32
+		Studio             string
+		Root               string
+		Group1             string `gorm:"column:group_1"`
+		Relation           string
+		Phase              string
+		Take               string
+		SubmittedAtUtc     time.Time
+		FirstFeedbackAtUtc *time.Time
+	}
+	firstFeedback := tx.Table("t_review_status_log AS f").Select(
+		"MIN(f.created_at_utc)",
+	).Where("f.review_info_id = ri.id")
+
+	// Working time never elapses faster than the wall clock, so the reviews which are not
+	// overdue by the wall clock are not overdue by any business clock either. The remaining
+	// candidates are paged through by id until enough breaches are found.
+	var models []*model.ReviewSLABreach
+	var lastID int32
+	for len(models) < slaEvaluationBatchSize {
+		var candidates []candidate
+		if err := tx.Table("t_review_info AS ri").Select(
+			"ri.id, ri.studio, ri.root, ri.group_1, ri.relation, ri.phase, ri.take, "+
+				"ri.submitted_at_utc, (?) AS first_feedback_at_utc",
+			firstFeedback,
+		).Where(
+			"ri.id > ?", lastID,
+		).Where(
+			"ri.deleted = ?", 0,
+		).Where(
+			"ri.project = ?", sla.Project,
+		).Where(
+			"ri.phase = ?", sla.Phase,
+		).Where(
+			"ri.intent <> ?", entity.ReviewIntentWIP,
+		).Where(
+			"ri.submitted_at_utc >= ?", sla.CreatedAtUTC,
+		).Where(
+			"ri.submitted_at_utc < ?", now.Add(-within),
+		).Where(
+			"NOT EXISTS (?)", tx.Table("t_review_status_log AS l").Select("1").Where(
+				"l.review_info_id = ri.id",
+			).Where(
+				"l.created_at_utc <= DATE_ADD(ri.submitted_at_utc, INTERVAL ? HOUR)",
+				sla.FeedbackWithinHours,
+			),
+		).Where(
+			"NOT EXISTS (?)", tx.Table("t_review_sla_breach AS b").Select("1").Where(
+				"b.review_info_id = ri.id",
+			),
+		).Order("ri.id asc").Limit(slaEvaluationBatchSize).Scan(&candidates).Error; err != nil {
+			return nil, fmt.Errorf("DetectBreaches: %w", err)
+		}
+
+		for _, c := range candidates {
+			lastID = c.ID
+			due := clocks[c.Studio].Add(c.SubmittedAtUtc, within)
+			if !due.Before(now) {
+				continue
+			}
+			if c.FirstFeedbackAtUtc != nil && !c.FirstFeedbackAtUtc.After(due) {
+				continue
+			}
+			models = append(models, &model.ReviewSLABreach{
+				Project:        sla.Project,
+				ReviewInfoID:   c.ID,
+				SLAID:          sla.ID,
+				Root:           c.Root,
+				Group:          c.Group1,
+				Relation:       c.Relation,
+				Phase:          c.Phase,
+				Take:           c.Take,
+				SubmittedAtUTC: c.SubmittedAtUtc,
+				DueAtUTC:       due,
+				DetectedAtUTC:  now,
+			})
+		}
+		if len(candidates) < slaEvaluationBatchSize {
+			break
+		}
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	// The evaluators of the API instances may record the same breach at once. A breach
+	// already recorded by another one is skipped, so that only the inserted ones are notified.
+	var entities []*entity.ReviewSLABreach
+	for _, m := range models {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(m)
+		if err := result.Error; err != nil {
+			return nil, err
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		entities = append(entities, m.Entity())
+	}
+	return entities, nil
+}
+
+// ResolveBreaches marks the breaches whose review got feedback since, and returns the number
+// of resolved breaches.
+func (r *ReviewSLA) ResolveBreaches(tx *gorm.DB) (int64, error) {
+	result := tx.Exec(
+		"UPDATE `t_review_sla_breach` AS b SET b.resolved_at_utc = (" +
+			"SELECT MIN(l.created_at_utc) FROM `t_review_status_log` AS l " +
+			"WHERE l.review_info_id = b.review_info_id" +
+			") WHERE b.resolved_at_utc IS NULL AND EXISTS (" +
+			"SELECT 1 FROM `t_review_status_log` AS l WHERE l.review_info_id = b.review_info_id" +
+			")",
+	)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/service"
+)
+
+// thumbnailProjectsRoot is the directory of the projects on the file server.
+const thumbnailProjectsRoot = "/mnt/ppip30-data01/datasync30/projects"
+
+// thumbnailNames are the files of a thumbnail directory, by order of preference.
+var thumbnailNames = []string{
+	"thumbnail_s.png", "thumbnail_m.png", "thumbnail_l.png", "animated.gif",
+}
+
+type ReviewThumbnail struct {
+	cs *service.CentralService
+}
+
+func NewReviewThumbnail(cs *service.CentralService) *ReviewThumbnail {
+	return &ReviewThumbnail{
+		cs: cs,
+	}
+}
+
+func (rt *ReviewThumbnail) GetAssetThumbnail(
+	params *entity.GetAssetThumbnailParams,
+) (string, error) {
+	return findAssetThumbnail(params.Project, params.Asset, params.Relation)
+}
+
+// GetAssetThumbnails resolves the thumbnails of several assets at once. The lookups are run
+// concurrently with a bounded number of workers because each of them globs the file server.
+// Assets without a thumbnail are omitted from the returned map.
+func (rt *ReviewThumbnail) GetAssetThumbnails(
+	params *entity.BatchGetAssetThumbnailsParams,
+) (map[entity.AssetThumbnailKey]string, error) {
+	const maxWorkers = 8
+
+	type result struct {
+		key  entity.AssetThumbnailKey
+		path string
+		err  error
+	}
+
+	keys := make(chan entity.AssetThumbnailKey)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < maxWorkers && i < len(params.Keys); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				path, err := findAssetThumbnail(params.Project, key.Asset, key.Relation)
+				results <- result{key: key, path: path, err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, key := range params.Keys {
+			keys <- key
+		}
+		close(keys)
+		wg.Wait()
+		close(results)
+	}()
+
+	thumbnails := make(map[entity.AssetThumbnailKey]string, len(params.Keys))
+	var firstErr error
+	for r := range results {
+		if r.err != nil {
+			if !errors.Is(r.err, os.ErrNotExist) && firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		thumbnails[r.key] = r.path
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return thumbnails, nil
+}
+
+func findAssetThumbnail(project, asset, relation string) (string, error) {
+	var thumbnailDirs []string
+	for _, phase := range []string{"mdl", "rig", "bld", "dsn", "ldv"} {
+		matches, err := filepath.Glob(filepath.Join(
+			thumbnailProjectsRoot,
+			project,
+			"shared/publish/assets",
+			asset,
+			relation,
+			phase,
+			"_tmb/20*.s???r????/thumbnail",
+		))
+		if err != nil {
+			return "", err
+		}
+		thumbnailDirs = append(thumbnailDirs, matches...)
+	}
+
+	// リビジョン名の日付部分で降順ソート
+	timestamp := func(thumbnailDir string) string {
+		return strings.Split(filepath.Base(filepath.Dir(thumbnailDir)), ".")[0]
+	}
+	sort.Slice(thumbnailDirs, func(i, j int) bool {
+		return timestamp(thumbnailDirs[j]) < timestamp(thumbnailDirs[i])
+	})
+
+	for _, thumbnailDir := range thumbnailDirs {
+		for _, name := range thumbnailNames {
+			thumbnailPath := filepath.Join(thumbnailDir, name)
+			if f, err := os.Stat(thumbnailPath); err == nil && f.Mode().IsRegular() {
+				return thumbnailPath, nil
+			}
+		}
+	}
+
+	return "", os.ErrNotExist
+}
+
+// thumbnailRevisionPattern matches the names of the thumbnail directories of the takes.
+const thumbnailRevisionPattern = "20*.s???r????"
+
+// ValidThumbnailRevision tells whether revision names a thumbnail directory of a take.
+func ValidThumbnailRevision(revision string) bool {
+	ok, err := filepath.Match(thumbnailRevisionPattern, revision)
+	return err == nil && ok
+}
+
+// GetPinnedAssetThumbnail returns the thumbnail file pinned for an asset, or os.ErrNotExist
+// when it is missing, e.g. after the revision was cleaned up.
+func (rt *ReviewThumbnail) GetPinnedAssetThumbnail(pin *entity.ThumbnailPin) (string, error) {
+	if !ValidThumbnailRevision(pin.Revision) {
+		return "", os.ErrNotExist
+	}
+	thumbnailPath := filepath.Join(
+		thumbnailProjectsRoot,
+		pin.Project,
+		"shared/publish/assets",
+		pin.Asset,
+		pin.Relation,
+		pin.Phase,
+		"_tmb",
+		pin.Revision,
+		"thumbnail",
+		pin.Name,
+	)
+	f, err := os.Stat(thumbnailPath)
+	if err != nil {
+		return "", err
+	}
+	if !f.Mode().IsRegular() {
+		return "", os.ErrNotExist
+	}
+	return thumbnailPath, nil
+}
+
+func (rt *ReviewThumbnail) GetShotThumbnail(
+	params *entity.GetShotThumbnailParams,
+) (string, error) {
+	var thumbnailDirs []string
+	for _, phase := range []string{"lay", "anm", "gnz", "mat", "cmp"} {
+		matches, err := filepath.Glob(filepath.Join(
+			thumbnailProjectsRoot,
+			params.Project,
+			"shared/publish/shots",
+			params.Group1,
+			params.Group2,
+			params.Group3,
+			params.Relation,
+			phase,
+			"_tmb/20*.s???r????/thumbnail",
+		))
+		if err != nil {
+			return "", err
+		}
+		thumbnailDirs = append(thumbnailDirs, matches...)
+	}
+
+	// リビジョン名の日付部分で降順ソート
+	timestamp := func(thumbnailDir string) string {
+		return strings.Split(filepath.Base(filepath.Dir(thumbnailDir)), ".")[0]
+	}
+	sort.Slice(thumbnailDirs, func(i, j int) bool {
+		return timestamp(thumbnailDirs[j]) < timestamp(thumbnailDirs[i])
+	})
+
+	for _, thumbnailDir := range thumbnailDirs {
+		for _, name := range thumbnailNames {
+			thumbnailPath := filepath.Join(thumbnailDir, name)
+			if f, err := os.Stat(thumbnailPath); err == nil && f.Mode().IsRegular() {
+				return thumbnailPath, nil
+			}
+		}
+	}
+
+	return "", os.ErrNotExist
+}
+
+// ListThumbnails returns the thumbnail files of all the revisions of the assets and the shots
+// of a project.
+func (rt *ReviewThumbnail) ListThumbnails(project string) ([]string, error) {
+	var thumbnailPaths []string
+	for _, pattern := range []string{
+		"shared/publish/assets/*/*/*/_tmb/20*.s???r????/thumbnail",
+		"shared/publish/shots/*/*/*/*/*/_tmb/20*.s???r????/thumbnail",
+	} {
+		thumbnailDirs, err := filepath.Glob(filepath.Join(thumbnailProjectsRoot, project, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, thumbnailDir := range thumbnailDirs {
+			for _, name := range thumbnailNames {
+				thumbnailPath := filepath.Join(thumbnailDir, name)
+				if f, err := os.Stat(thumbnailPath); err == nil && f.Mode().IsRegular() {
+					thumbnailPaths = append(thumbnailPaths, thumbnailPath)
+				}
+			}
+		}
+	}
+	return thumbnailPaths, nil
+}
+
+// OpenThumbnail opens a thumbnail file, which may be encrypted.
+func (rt *ReviewThumbnail) OpenThumbnail(thumbnailPath string) (*os.File, error) {
+	return os.Open(thumbnailPath)
+}
+
+// ReplaceThumbnail rewrites a thumbnail file with write, which reads the current file from
+// r. The file is only replaced once fully written, keeping its mode.
+func (rt *ReviewThumbnail) ReplaceThumbnail(
+	thumbnailPath string,
+	write func(w io.Writer, r io.Reader) error,
+) error {
+	src, err := os.Open(thumbnailPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(
+		filepath.Dir(thumbnailPath), fmt.Sprintf(".%s-*.tmp", filepath.Base(thumbnailPath)),
+	)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := write(f, src); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(info.Mode().Perm()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), thumbnailPath)
+}
+
+// ThumbnailContentType returns the content type of a thumbnail file from its name.
+func ThumbnailContentType(thumbnailPath string) string {
+	if strings.HasSuffix(thumbnailPath, ".gif") {
+		return "image/gif"
+	}
+	return "image/png"
+}
+
+// ThumbnailDataURI returns the content of a thumbnail file as a base64 data URI.
+func ThumbnailDataURI(thumbnailPath string, data []byte) string {
+	return "data:" + ThumbnailContentType(thumbnailPath) + ";base64," +
+		base64.StdEncoding.EncodeToString(data)
+}
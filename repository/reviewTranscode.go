@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// transcodeBatchSize limits the number of transcodes processed per run of the worker.
+const transcodeBatchSize = 100
+
+type ReviewTranscode struct {
+	db *gorm.DB
+}
+
+func NewReviewTranscode(db *gorm.DB) (*ReviewTranscode, error) {
+	if err := db.AutoMigrate(&model.ReviewTranscode{}); err != nil {
+		return nil, err
+	}
+	return &ReviewTranscode{
+		db: db,
+	}, nil
+}
+
+func (r *ReviewTranscode) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ReviewTranscode) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *ReviewTranscode) List(
+	db *gorm.DB,
+	params *entity.ListReviewTranscodesParams,
+) ([]*entity.ReviewTranscode, error) {
+	var models []*model.ReviewTranscode
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`review_info_id` = ?", params.ReviewInfoID,
+	).Order("`id` desc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ReviewTranscode, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// FindActive returns the transcode of the source which is not done yet, or nil.
+func (r *ReviewTranscode) FindActive(
+	db *gorm.DB,
+	project string,
+	reviewInfoID int32,
+	source string,
+) (*entity.ReviewTranscode, error) {
+	var m model.ReviewTranscode
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`review_info_id` = ?", reviewInfoID,
+	).Where(
+		"`source` = ?", source,
+	).Where(
+		"`status` IN ?", []string{string(entity.TranscodeQueued), string(entity.TranscodeRunning)},
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *ReviewTranscode) Create(
+	tx *gorm.DB,
+	params *entity.CreateReviewTranscodeParams,
+	source string,
+	frames []string,
+	output string,
+	backend string,
+) (*entity.ReviewTranscode, error) {
+	m := model.NewReviewTranscode(params, source, frames, output, backend)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// ListActive returns the oldest transcodes which are not done yet.
+func (r *ReviewTranscode) ListActive(db *gorm.DB) ([]*entity.ReviewTranscode, error) {
+	var models []*model.ReviewTranscode
+	if err := db.Where(
+		"`status` IN ?", []string{string(entity.TranscodeQueued), string(entity.TranscodeRunning)},
+	).Order("`id` asc").Limit(transcodeBatchSize).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ReviewTranscode, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// MarkSubmitted records the job of a queued transcode. It reports false when the transcode was
+// not queued anymore, i.e. another worker submitted it.
+func (r *ReviewTranscode) MarkSubmitted(tx *gorm.DB, id int32, jobID string) (bool, error) {
+	now := time.Now().UTC()
+	result := tx.Model(&model.ReviewTranscode{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` = ?", string(entity.TranscodeQueued),
+	).Updates(map[string]interface{}{
+		"status":           string(entity.TranscodeRunning),
+		"job_id":           jobID,
+		"submitted_at_utc": now,
+		"modified_at_utc":  now,
+	})
+	if err := result.Error; err != nil {
+		return false, err
+	}
+	return result.RowsAffected != 0, nil
+}
+
+// Finish completes or fails a transcode which is not done yet. It reports false when the
+// transcode was already done, i.e. another worker finished it.
+func (r *ReviewTranscode) Finish(
+	tx *gorm.DB,
+	id int32,
+	status entity.TranscodeStatus,
+	errMessage *string,
+) (bool, error) {
+	now := time.Now().UTC()
+	result := tx.Model(&model.ReviewTranscode{}).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` IN ?", []string{string(entity.TranscodeQueued), string(entity.TranscodeRunning)},
+	).Updates(map[string]interface{}{
+		"status":           string(status),
+		"error":            errMessage,
+		"completed_at_utc": now,
+		"modified_at_utc":  now,
+	})
+	if err := result.Error; err != nil {
+		return false, err
+	}
+	return result.RowsAffected != 0, nil
+}
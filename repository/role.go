@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Role stores the roles and their assignments to the users of projects.
+type Role struct {
+	db *gorm.DB
+}
+
+// NewRole migrates the tables of the roles and creates the built-in roles which are missing,
+// with their default permissions.
+func NewRole(db *gorm.DB) (*Role, error) {
+	if err := db.AutoMigrate(&model.Role{}, &model.UserRole{}); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for name, permissions := range entity.BuiltInRoles {
+		m := model.Role{
+			Name:          name,
+			Permissions:   permissionStrings(permissions),
+			BuiltIn:       true,
+			CreatedAtUTC:  now,
+			ModifiedAtUTC: now,
+			ModifiedBy:    "system",
+			CreatedBy:     "system",
+		}
+		if err := db.Where(
+			"`name` = ?", name,
+		).FirstOrCreate(&m).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &Role{
+		db: db,
+	}, nil
+}
+
+func (r *Role) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Role) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func permissionStrings(permissions []entity.Permission) model.Permissions {
+	s := make(model.Permissions, len(permissions))
+	for i, p := range permissions {
+		s[i] = string(p)
+	}
+	return s
+}
+
+func (r *Role) get(db *gorm.DB, name string) (*model.Role, error) {
+	var m model.Role
+	if err := db.Where("`name` = ?", name).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: role %q", entity.ErrRecordNotFound, name)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// List returns all the roles by name.
+func (r *Role) List(db *gorm.DB) ([]*entity.Role, error) {
+	var models []*model.Role
+	if err := db.Order("`name` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.Role, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *Role) Get(db *gorm.DB, params *entity.GetRoleParams) (*entity.Role, error) {
+	m, err := r.get(db, params.Name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Update creates the role or replaces its description and permissions.
+func (r *Role) Update(tx *gorm.DB, params *entity.UpdateRoleParams) (*entity.Role, error) {
+	now := time.Now().UTC()
+	m, err := r.get(tx, params.Name)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		m = &model.Role{
+			Name:         params.Name,
+			CreatedAtUTC: now,
+			CreatedBy:    params.ModifiedBy,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	m.Description = params.Description
+	m.Permissions = permissionStrings(params.Permissions)
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = params.ModifiedBy
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Delete deletes a role which is neither built in nor assigned to any user.
+func (r *Role) Delete(tx *gorm.DB, params *entity.DeleteRoleParams) error {
+	m, err := r.get(tx, params.Name)
+	if err != nil {
+		return err
+	}
+	if m.BuiltIn {
+		return fmt.Errorf("%w: built-in role %q cannot be deleted", entity.ErrBadRequest, m.Name)
+	}
+	var assigned int64
+	if err := tx.Model(&model.UserRole{}).Where(
+		"`role` = ?", m.Name,
+	).Count(&assigned).Error; err != nil {
+		return err
+	}
+	if assigned != 0 {
+		return fmt.Errorf(
+			"%w: role %q is assigned to %d users", entity.ErrBadRequest, m.Name, assigned,
+		)
+	}
+	return tx.Delete(m).Error
+}
+
+// ListUserRoles returns the assignments of roles, latest first.
+func (r *Role) ListUserRoles(
+	db *gorm.DB,
+	params *entity.ListUserRolesParams,
+) ([]*entity.UserRole, uint, error) {
+	stmt := db.Model(&model.UserRole{})
+	if params.Role != nil {
+		stmt = stmt.Where("`role` = ?", *params.Role)
+	}
+	if params.Project != nil {
+		stmt = stmt.Where("`project` = ?", *params.Project)
+	}
+	if params.User != nil {
+		stmt = stmt.Where("`user` = ?", *params.User)
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.UserRole
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.UserRole, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, uint(total), nil
+}
+
+func (r *Role) CreateUserRole(
+	tx *gorm.DB,
+	params *entity.CreateUserRoleParams,
+) (*entity.UserRole, error) {
+	if _, err := r.get(tx, params.Role); err != nil {
+		return nil, err
+	}
+	m := model.NewUserRole(params)
+	if err := tx.Create(m).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, fmt.Errorf(
+				"%w: role %q is already assigned to %q in %s",
+				entity.ErrBadRequest, params.Role, params.User, params.Project,
+			)
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *Role) DeleteUserRole(tx *gorm.DB, params *entity.DeleteUserRoleParams) error {
+	result := tx.Where(
+		"`role` = ?", params.Role,
+	).Where(
+		"`id` = ?", params.ID,
+	).Delete(&model.UserRole{})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: assignment with ID %d of role %q", entity.ErrRecordNotFound, params.ID, params.Role,
+		)
+	}
+	return nil
+}
+
+// ProjectEnforced tells whether roles are assigned to any user of the project, which enables
+// the permission checks in it.
+func (r *Role) ProjectEnforced(db *gorm.DB, project string) (bool, error) {
+	var n int64
+	if err := db.Model(&model.UserRole{}).Where(
+		"`project` = ?", project,
+	).Limit(1).Count(&n).Error; err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
+
+// UserPermissions returns the permissions granted by the unexpired roles of the user in the
+// project.
+func (r *Role) UserPermissions(
+	db *gorm.DB,
+	project, user string,
+) ([]entity.Permission, error) {
+	var models []*model.Role
+	if err := db.Where(
+		"`name` IN (?)",
+		db.Model(&model.UserRole{}).Select("`role`").Where(
+			"`project` = ?", project,
+		).Where(
+			"`user` = ?", user,
+		).Where(
+			"`expires_at_utc` IS NULL OR `expires_at_utc` > ?", time.Now().UTC(),
+		),
+	).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	var permissions []entity.Permission
+	for _, m := range models {
+		permissions = append(permissions, m.Entity().Permissions...)
+	}
+	return permissions, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// Schema reads the settings extending the request schemas.
+type Schema struct {
+	db *gorm.DB
+}
+
+func NewSchema(db *gorm.DB) *Schema {
+	return &Schema{
+		db: db,
+	}
+}
+
+func (r *Schema) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// ListReviewStatuses returns the statuses of the status type ("approvalStatus" or
+// "workStatus") having a display name in the common section or, when given, in the section
+// of the project.
+func (r *Schema) ListReviewStatuses(
+	db *gorm.DB,
+	statusType string,
+	project *string,
+) ([]string, error) {
+	prefix := fmt.Sprintf(entity.ReviewStatusKeyPrefixFormat, statusType)
+	sections := db.Where("`section_type` = ?", entity.CommonSection.String())
+	if project != nil {
+		sections = sections.Or(
+			db.Where(
+				"`section_type` = ?", entity.ProjectSection.String(),
+			).Where(
+				"`section_name` = ?", *project,
+			),
+		)
+	}
+	var keys []string
+	if err := db.Table("t_pipeline_setting_preference").Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`key` LIKE ?", prefix+"%"+entity.ReviewStatusKeySuffix,
+	).Where(sections).Distinct().Pluck("key", &keys).Error; err != nil {
+		return nil, err
+	}
+
+	statuses := []string{}
+	for _, key := range keys {
+		status := strings.TrimSuffix(strings.TrimPrefix(key, prefix), entity.ReviewStatusKeySuffix)
+		if status != "" && !strings.Contains(status, "/") {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+	return statuses, nil
+}
@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	seedBatchSize = 500
+	seedRoot      = "assets"
+	seedArtists   = 60
+)
+
+// seedAssetTypes are the first level groups of the generated assets, with their weights.
+var seedAssetTypes = []struct {
+	group  string
+	weight float64
+}{
+	{"prp", 0.45},
+	{"chr", 0.25},
+	{"set", 0.2},
+	{"veh", 0.1},
+}
+
+// Seed generates synthetic projects, categories and reviews for load testing.
+type Seed struct {
+	db *gorm.DB
+}
+
+func NewSeed(db *gorm.DB) *Seed {
+	return &Seed{
+		db: db,
+	}
+}
+
+func (r *Seed) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Seed) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// ProjectNames returns the names of the projects generated with the given parameters.
+func (r *Seed) ProjectNames(params *entity.SeedParams) []string {
+	names := make([]string, params.Projects)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s%02d", params.Prefix, i+1)
+	}
+	return names
+}
+
+// Purge hard deletes the projects and the categories and reviews of the projects.
+func (r *Seed) Purge(db *gorm.DB, projects []string) error {
+	for _, m := range []interface{}{
+		&model.ReviewLatestState{},
+		&model.ReviewLatest{},
+		&model.ReviewInfo{},
+		&model.GroupCategoryGroup{},
+		&model.GroupCategory{},
+	} {
+		if err := db.Where("`project` IN ?", projects).Delete(m).Error; err != nil {
+			return err
+		}
+	}
+	return db.Where("`name` IN ?", projects).Delete(&model.ProjectInfo{}).Error
+}
+
+// Create generates and stores one project. The assets are spread over the groups of
+// seedAssetTypes; each asset progresses through the default asset phases, three in four
+// reaching the next one, and has an exponentially distributed number of submissions per
+// phase. A few artists submit most of the reviews. The returned dependencies are those of the
+// built assets on the rigs of other assets, which are not stored in MySQL.
+func (r *Seed) Create(
+	db *gorm.DB,
+	rng *rand.Rand,
+	project string,
+	params *entity.SeedParams,
+) (*entity.SeedResult, []*entity.SeedDependency, error) {
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	now := time.Now().UTC()
+	result := &entity.SeedResult{
+		Projects: []string{project},
+	}
+
+	if err := db.Create(&model.ProjectInfo{
+		KeyName:       project,
+		CreatedAtUTC:  &now,
+		ModifiedAtUTC: &now,
+		ModifiedBy:    params.CreatedBy,
+		CreatedBy:     params.CreatedBy,
+	}).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, nil, fmt.Errorf(
+				"%w: project %q is already exists, seed with reset to regenerate it",
+				entity.ErrBadRequest, project,
+			)
+		}
+		return nil, nil, err
+	}
+
+	categories := seedCategories(rng, project, params.CategoriesPerProject, createdBy, now)
+	if len(categories) != 0 {
+		if err := db.CreateInBatches(categories, seedBatchSize).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	result.Categories = len(categories)
+	for _, c := range categories {
+		result.Groups += len(c.Groups)
+	}
+
+	phases := entity.DefaultPhaseTemplates[seedRoot]
+	artist := rand.NewZipf(rng, 1.2, 1, seedArtists-1)
+	start := now.AddDate(0, 0, -params.Days)
+	period := now.Sub(start)
+
+	var reviews []*model.ReviewInfo
+	flush := func() error {
+		if len(reviews) == 0 {
+			return nil
+		}
+		if err := db.CreateInBatches(reviews, seedBatchSize).Error; err != nil {
+			return err
+		}
+		result.Reviews += len(reviews)
+		reviews = reviews[:0]
+		return nil
+	}
+
+	// latest holds the last content of each asset per phase, used for the dependencies.
+	latest := make([]map[string]*entity.SeedContent, params.AssetsPerProject)
+	for i := 0; i < params.AssetsPerProject; i++ {
+		group := seedAssetType(rng)
+		relation := fmt.Sprintf("%s%04d", group, i+1)
+		latest[i] = map[string]*entity.SeedContent{}
+
+		// assets are started uniformly over the first half of the period
+		at := start.Add(time.Duration(rng.Int63n(int64(period / 2))))
+		reached := 1
+		for reached < len(phases) && rng.Float64() < 0.75 {
+			reached++
+		}
+		for p, phase := range phases[:reached] {
+			n := 1 + int(math.Min(
+				rng.ExpFloat64()*float64(params.ReviewsPerPhase-1),
+				float64(params.ReviewsPerPhase*10),
+			))
+			for j := 0; j < n; j++ {
+				at = at.Add(time.Duration(rng.Int63n(int64(72 * time.Hour))))
+				if at.After(now) {
+					at = now
+				}
+				last := j == n-1
+				approval, work := "retake", "inProgress"
+				switch {
+				case last && p != reached-1:
+					approval, work = entity.ApprovalStatusApproved, "done"
+				case last:
+					approval, work = seedCurrentStatus(rng)
+				}
+				user := fmt.Sprintf("artist%02d", artist.Uint64()+1)
+				take := fmt.Sprintf("%s_%07d", at.Format("20060102T150405.000000"), rng.Intn(1e7))
+				file := fmt.Sprintf("%s_%s.usd", relation, phase)
+				reviews = append(reviews, seedReview(
+					rng, project, params.Studio, group, relation, phase, take, file,
+					approval, work, user, at,
+				))
+				if last {
+					latest[i][phase] = &entity.SeedContent{
+						Project:   project,
+						Root:      seedRoot,
+						Group:     group,
+						Relation:  relation,
+						Phase:     phase,
+						Component: "main",
+						Take:      take,
+						FileName:  file,
+					}
+				}
+			}
+		}
+		result.Assets++
+		if len(reviews) >= seedBatchSize {
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+
+	return result, seedDependencies(rng, latest, params.DependencyRatio), nil
+}
+
+func seedAssetType(rng *rand.Rand) string {
+	x := rng.Float64()
+	for _, t := range seedAssetTypes {
+		if x < t.weight {
+			return t.group
+		}
+		x -= t.weight
+	}
+	return seedAssetTypes[len(seedAssetTypes)-1].group
+}
+
+// seedCurrentStatus returns the statuses of the last submission in the phase an asset is in.
+func seedCurrentStatus(rng *rand.Rand) (string, string) {
+	x := rng.Float64()
+	switch {
+	case x < 0.4:
+		return entity.ApprovalStatusApproved, "done"
+	case x < 0.85:
+		return "check", "inProgress"
+	default:
+		return "retake", "inProgress"
+	}
+}
+
+func seedCategories(
+	rng *rand.Rand,
+	project string,
+	count int,
+	createdBy string,
+	now time.Time,
+) []*model.GroupCategory {
+	categories := make([]*model.GroupCategory, count)
+	for i := range categories {
+		path := fmt.Sprintf("cat%03d", i+1)
+		depth := 1
+		// a third of the categories are nested in the previous one
+		if i != 0 && rng.Intn(3) == 0 {
+			path = fmt.Sprintf("%s/sub%03d", categories[i-1].Path, i+1)
+			depth = 2
+		}
+		c := &model.GroupCategory{
+			Project:       project,
+			Root:          seedRoot,
+			Path:          path,
+			Depth:         uint8(depth),
+			CreatedBy:     createdBy,
+			CreatedAtUTC:  now,
+			ModifiedBy:    createdBy,
+			ModifiedAtUTC: now,
+		}
+		for _, j := range rng.Perm(len(seedAssetTypes))[:1+rng.Intn(len(seedAssetTypes))] {
+			c.Groups = append(c.Groups, &model.GroupCategoryGroup{
+				Path:          seedAssetTypes[j].group,
+				Project:       project,
+				CreatedBy:     createdBy,
+				CreatedAtUTC:  now,
+				ModifiedBy:    createdBy,
+				ModifiedAtUTC: now,
+			})
+		}
+		categories[i] = c
+	}
+	return categories
+}
+
+func seedReview(
+	rng *rand.Rand,
+	project, studio, group, relation, phase, take, file, approval, work, user string,
+	at time.Time,
+) *model.ReviewInfo {
+	size := uint64(rng.ExpFloat64() * 50 * 1024 * 1024)
+	path := fmt.Sprintf("/ppi/%s/%s/%s/%s/%s/main/%s", project, seedRoot, group, relation, phase, take)
+	return &model.ReviewInfo{
+		TaskID:                     seedUUID(rng),
+		SubtaskID:                  seedUUID(rng),
+		Studio:                     studio,
+		Project:                    project,
+		ProjectPath:                "/ppi/" + project,
+		ReviewComments:             model.Comments{},
+		TakePath:                   path,
+		Root:                       seedRoot,
+		Groups:                     model.Groups{group},
+		Relation:                   relation,
+		Phase:                      phase,
+		Component:                  "main",
+		Take:                       take,
+		TakeNumber:                 model.TakeNumber(take),
+		Intent:                     entity.ReviewIntentPublish,
+		ApprovalStatus:             approval,
+		ApprovalStatusUpdatedUser:  user,
+		ApprovalStatusUpdatedAtUtc: at,
+		WorkStatus:                 work,
+		WorkStatusUpdatedUser:      user,
+		WorkStatusUpdatedAtUtc:     at,
+		ReviewTarget:               model.Contents{},
+		ReviewData:                 model.Contents{},
+		SubmittedAtUtc:             at,
+		SubmittedComputer:          fmt.Sprintf("ws%04d", rng.Intn(500)),
+		SubmittedOS:                "win",
+		SubmittedOSVersion:         "10.0.19045",
+		SubmittedUser:              user,
+		ExecutedAtUtc:              at,
+		ExecutedComputer:           fmt.Sprintf("ws%04d", rng.Intn(500)),
+		ExecutedOS:                 "win",
+		ExecutedOSVersion:          "10.0.19045",
+		ExecutedUser:               user,
+		AllFiles:                   model.Files{},
+		NumAllFiles:                uint32(1 + rng.Intn(200)),
+		SizeAllFiles:               size,
+		TargetComponents:           model.Components{"main"},
+		CreatedAtUTC:               at,
+		ModifiedAtUTC:              at,
+		ModifiedBy:                 user,
+		CreatedBy:                  user,
+	}
+}
+
+// seedDependencies makes the given share of the built assets depend on the rigs, or models
+// when not rigged, of one to three other assets.
+func seedDependencies(
+	rng *rand.Rand,
+	latest []map[string]*entity.SeedContent,
+	ratio float64,
+) []*entity.SeedDependency {
+	var upstreams []*entity.SeedContent
+	for _, contents := range latest {
+		if c, ok := contents["rig"]; ok {
+			upstreams = append(upstreams, c)
+		} else if c, ok := contents["mdl"]; ok {
+			upstreams = append(upstreams, c)
+		}
+	}
+	var dependencies []*entity.SeedDependency
+	for _, contents := range latest {
+		c, ok := contents["bld"]
+		if !ok || len(upstreams) < 2 || rng.Float64() >= ratio {
+			continue
+		}
+		var picked []*entity.SeedContent
+		for n := 1 + rng.Intn(3); n > 0; n-- {
+			u := upstreams[rng.Intn(len(upstreams))]
+			if u.Relation == c.Relation || slices.Contains(picked, u) {
+				continue
+			}
+			picked = append(picked, u)
+			dependencies = append(dependencies, &entity.SeedDependency{
+				Content:  c,
+				Upstream: u,
+			})
+		}
+	}
+	return dependencies
+}
+
+// seedUUID returns a version 4 UUID drawn from rng so that the data stays repeatable.
+func seedUUID(rng *rand.Rand) string {
+	b := make([]byte, 16)
+	rng.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
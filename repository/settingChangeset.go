@@ -0,0 +1,768 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// SettingChangeset stores drafts of edits of pipeline setting values. The edits only reach the
+// live values when the changeset is published, all of them in the transaction of the publish.
+type SettingChangeset struct {
+	db          *gorm.DB
+	settingRepo *PipelineSetting
+}
+
+func NewSettingChangeset(
+	db *gorm.DB,
+	settingRepo *PipelineSetting,
+) (*SettingChangeset, error) {
+	if err := db.AutoMigrate(
+		&model.SettingChangeset{},
+		&model.SettingChange{},
+	); err != nil {
+		return nil, err
+	}
+	return &SettingChangeset{
+		db:          db,
+		settingRepo: settingRepo,
+	}, nil
+}
+
+func (r *SettingChangeset) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *SettingChangeset) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *SettingChangeset) whereSection(
+	stmt *gorm.DB,
+	section *entity.SettingChangesetSection,
+) *gorm.DB {
+	return stmt.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`section_type` = ?", section.Section().String(),
+	).Where(
+		"`section_name` = ?", section.Name(),
+	)
+}
+
+func (r *SettingChangeset) take(
+	tx *gorm.DB,
+	section *entity.SettingChangesetSection,
+	id int32,
+) (*model.SettingChangeset, error) {
+	var m model.SettingChangeset
+	if err := r.whereSection(tx, section).Where("`id` = ?", id).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: changeset with id %d not found", entity.ErrRecordNotFound, id,
+			)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// transit moves the changeset to status if it is in one of from. The update locks the row
+// until the end of the transaction, so concurrent transitions of a changeset are serialized
+// and only the first one succeeds.
+func (r *SettingChangeset) transit(
+	tx *gorm.DB,
+	section *entity.SettingChangesetSection,
+	id int32,
+	from []entity.SettingChangesetStatus,
+	values map[string]interface{},
+) (*model.SettingChangeset, error) {
+	result := r.whereSection(
+		tx.Model(&model.SettingChangeset{}), section,
+	).Where(
+		"`id` = ?", id,
+	).Where(
+		"`status` IN ?", from,
+	).Updates(values)
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		m, err := r.take(tx, section, id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf(
+			"%w: changeset with id %d is %s", entity.ErrBadRequest, id, m.Status,
+		)
+	}
+	// the values may have deleted the changeset
+	var m model.SettingChangeset
+	if err := tx.Where("`id` = ?", id).Take(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *SettingChangeset) List(
+	db *gorm.DB,
+	params *entity.ListSettingChangesetsParams,
+) ([]*entity.SettingChangeset, uint, error) {
+	stmt := r.whereSection(db.Model(&model.SettingChangeset{}), &params.SettingChangesetSection)
+	if params.Status != nil {
+		stmt = stmt.Where("`status` = ?", *params.Status)
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.SettingChangeset
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entities := make([]*entity.SettingChangeset, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, uint(total), nil
+}
+
+// Get returns the changeset with its changes.
+func (r *SettingChangeset) Get(
+	db *gorm.DB,
+	params *entity.GetSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	m, err := r.take(db, &params.SettingChangesetSection, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.entityWithChanges(db, &params.SettingChangesetSection, m)
+}
+
+func (r *SettingChangeset) Create(
+	tx *gorm.DB,
+	params *entity.CreateSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	m := model.NewSettingChangeset(params)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Delete discards a changeset which has not been published.
+func (r *SettingChangeset) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteSettingChangesetParams,
+) error {
+	_, err := r.transit(
+		tx, &params.SettingChangesetSection, params.ID,
+		[]entity.SettingChangesetStatus{
+			entity.SettingChangesetDraft,
+			entity.SettingChangesetScheduled,
+		},
+		map[string]interface{}{
+			"deleted":         gorm.Expr("id"),
+			"modified_at_utc": time.Now().UTC(),
+			"modified_by":     params.ModifiedBy,
+		},
+	)
+	return err
+}
+
+// touchDraft locks the changeset and records its modification, failing unless it is a draft.
+func (r *SettingChangeset) touchDraft(
+	tx *gorm.DB,
+	section *entity.SettingChangesetSection,
+	id int32,
+	modifiedBy *string,
+) (*model.SettingChangeset, error) {
+	return r.transit(
+		tx, section, id,
+		[]entity.SettingChangesetStatus{entity.SettingChangesetDraft},
+		map[string]interface{}{
+			"modified_at_utc": time.Now().UTC(),
+			"modified_by":     modifiedBy,
+		},
+	)
+}
+
+// PutChange adds the change of the key to the draft, replacing its previous change of the key.
+// The value is validated against the property when the change is drafted, and again when it
+// is published.
+func (r *SettingChangeset) PutChange(
+	tx *gorm.DB,
+	params *entity.PutSettingChangeParams,
+) (*entity.SettingChange, error) {
+	section := &params.SettingChangesetSection
+	if _, err := r.touchDraft(tx, section, params.ChangesetID, params.CreatedBy); err != nil {
+		return nil, err
+	}
+	property, err := r.property(tx, section, params.Group, params.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	var value *string
+	if params.Operation == entity.SettingChangeSet {
+		if err := property.Validate(params.Value); err != nil {
+			return nil, fmt.Errorf("%w: %s", entity.ErrBadRequest, err.Error())
+		}
+		value, err = r.sealValue(section, property, params.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	var m model.SettingChange
+	err = tx.Where(
+		"`changeset_id` = ?", params.ChangesetID,
+	).Where(
+		"`group` = ?", params.Group.String(),
+	).Where(
+		"`key` = ?", params.Key,
+	).Take(&m).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.SettingChange{
+			ChangesetID:  params.ChangesetID,
+			Group:        params.Group.String(),
+			Key:          params.Key,
+			CreatedAtUTC: &now,
+			CreatedBy:    params.CreatedBy,
+		}
+	}
+	m.Operation = string(params.Operation)
+	m.Value = value
+	m.ModifiedAtUTC = &now
+	m.ModifiedBy = params.CreatedBy
+	if err := tx.Save(&m).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, fmt.Errorf(
+				"%w: change of key %q is already exists", entity.ErrBadRequest, params.Key,
+			)
+		}
+		return nil, err
+	}
+	return r.changeEntity(tx, section, &m)
+}
+
+// DeleteChange removes the change from the draft.
+func (r *SettingChangeset) DeleteChange(
+	tx *gorm.DB,
+	params *entity.DeleteSettingChangeParams,
+) error {
+	section := &params.SettingChangesetSection
+	if _, err := r.touchDraft(tx, section, params.ChangesetID, params.ModifiedBy); err != nil {
+		return err
+	}
+	result := tx.Where(
+		"`changeset_id` = ?", params.ChangesetID,
+	).Where(
+		"`id` = ?", params.ChangeID,
+	).Delete(&model.SettingChange{})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: change with id %d not found", entity.ErrRecordNotFound, params.ChangeID,
+		)
+	}
+	return nil
+}
+
+// Diff compares the changes of the changeset with the live values.
+func (r *SettingChangeset) Diff(
+	db *gorm.DB,
+	params *entity.GetSettingChangesetParams,
+) ([]*entity.SettingChangeDiff, error) {
+	section := &params.SettingChangesetSection
+	if _, err := r.take(db, section, params.ID); err != nil {
+		return nil, err
+	}
+	models, err := r.changes(db, params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]*entity.SettingChangeDiff, len(models))
+	for i, m := range models {
+		c, err := r.changeEntity(db, section, m)
+		if err != nil {
+			return nil, err
+		}
+		live, err := r.liveValue(db, section, c.Group, c.Key)
+		if err != nil {
+			return nil, err
+		}
+		d := &entity.SettingChangeDiff{
+			Group:     c.Group,
+			Key:       c.Key,
+			Operation: c.Operation,
+			Proposed:  c.Value,
+			Encrypted: c.Encrypted,
+		}
+		if live != nil {
+			d.Current = live.Value
+			d.CurrentExists = true
+			d.Encrypted = d.Encrypted || live.Encrypted
+		}
+		switch c.Operation {
+		case entity.SettingChangeSet:
+			d.Changed = !d.CurrentExists || !sameSettingValue(d.Current, d.Proposed)
+		case entity.SettingChangeDelete:
+			d.Changed = d.CurrentExists
+		}
+		diffs[i] = d
+	}
+	return diffs, nil
+}
+
+// Schedule marks the draft to be published at the given time.
+func (r *SettingChangeset) Schedule(
+	tx *gorm.DB,
+	params *entity.PublishSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	m, err := r.transit(
+		tx, &params.SettingChangesetSection, params.ID,
+		[]entity.SettingChangesetStatus{
+			entity.SettingChangesetDraft,
+			entity.SettingChangesetScheduled,
+		},
+		map[string]interface{}{
+			"status":          entity.SettingChangesetScheduled,
+			"publish_at_utc":  params.PublishAtUTC.UTC(),
+			"modified_at_utc": time.Now().UTC(),
+			"modified_by":     params.PublishedBy,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.entityWithChanges(tx, &params.SettingChangesetSection, m)
+}
+
+// ListDue returns the scheduled changesets whose publish time has come.
+func (r *SettingChangeset) ListDue(
+	db *gorm.DB,
+	now time.Time,
+) ([]*entity.PublishSettingChangesetParams, error) {
+	var models []*model.SettingChangeset
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`status` = ?", entity.SettingChangesetScheduled,
+	).Where(
+		"`publish_at_utc` <= ?", now,
+	).Order("`publish_at_utc` asc, `id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	due := make([]*entity.PublishSettingChangesetParams, len(models))
+	for i, m := range models {
+		sectionName := m.SectionName
+		params := &entity.PublishSettingChangesetParams{
+			ID:          m.ID,
+			PublishedBy: m.ModifiedBy,
+		}
+		section, _ := entity.ParsePipelineSettingSection(m.SectionType)
+		switch section {
+		case entity.ProjectSection:
+			params.Project = &sectionName
+		case entity.StudioSection:
+			params.Studio = &sectionName
+		case entity.CommonSection:
+			params.Common = &sectionName
+		}
+		due[i] = params
+	}
+	return due, nil
+}
+
+// Publish applies all the changes of the changeset to the live values and records the values
+// they replaced for the rollback. It must be called in a transaction, which must be rolled
+// back when it fails.
+func (r *SettingChangeset) Publish(
+	tx *gorm.DB,
+	params *entity.PublishSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	section := &params.SettingChangesetSection
+	m, err := r.transit(
+		tx, section, params.ID,
+		[]entity.SettingChangesetStatus{
+			entity.SettingChangesetDraft,
+			entity.SettingChangesetScheduled,
+		},
+		map[string]interface{}{
+			"status": entity.SettingChangesetPublished,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	models, err := r.changes(tx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf(
+			"%w: changeset with id %d has no changes", entity.ErrBadRequest, params.ID,
+		)
+	}
+
+	for _, cm := range models {
+		c, err := r.changeEntity(tx, section, cm)
+		if err != nil {
+			return nil, err
+		}
+		property, err := r.property(tx, section, c.Group, c.Key)
+		if err != nil {
+			return nil, err
+		}
+		live, err := r.liveValue(tx, section, c.Group, c.Key)
+		if err != nil {
+			return nil, err
+		}
+		if live != nil {
+			previous, err := r.sealValue(section, property, live.Value)
+			if err != nil {
+				return nil, err
+			}
+			cm.PreviousValue = previous
+			cm.PreviousExists = true
+		}
+
+		switch c.Operation {
+		case entity.SettingChangeSet:
+			if err := property.Validate(c.Value); err != nil {
+				return nil, fmt.Errorf(
+					"%w: value of key %q: %s", entity.ErrBadRequest, c.Key, err.Error(),
+				)
+			}
+			if err := r.setValue(
+				tx, section, c.Group, c.Key, c.Value, live != nil, params.PublishedBy,
+			); err != nil {
+				return nil, err
+			}
+		case entity.SettingChangeDelete:
+			if live != nil {
+				if err := r.deleteValue(tx, section, c.Group, c.Key, params.PublishedBy); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := tx.Model(cm).Select(
+			"previous_value", "previous_exists",
+		).Updates(cm).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// set after the changes, so that live values modified later can be told apart
+	now := time.Now().UTC()
+	m.PublishedAtUTC = &now
+	m.PublishedBy = params.PublishedBy
+	m.ModifiedAtUTC = &now
+	m.ModifiedBy = params.PublishedBy
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return r.entityWithChanges(tx, section, m)
+}
+
+// Rollback restores the values replaced by the publish of the changeset. It fails when one of
+// them has been modified since, so that later edits are not silently lost.
+func (r *SettingChangeset) Rollback(
+	tx *gorm.DB,
+	params *entity.RollbackSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	section := &params.SettingChangesetSection
+	m, err := r.transit(
+		tx, section, params.ID,
+		[]entity.SettingChangesetStatus{entity.SettingChangesetPublished},
+		map[string]interface{}{
+			"status": entity.SettingChangesetRolledBack,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	models, err := r.changes(tx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(models) - 1; i >= 0; i-- {
+		c, err := r.changeEntity(tx, section, models[i])
+		if err != nil {
+			return nil, err
+		}
+		live, err := r.liveValue(tx, section, c.Group, c.Key)
+		if err != nil {
+			return nil, err
+		}
+		if live != nil && live.ModifiedAtUTC != nil && m.PublishedAtUTC != nil &&
+			live.ModifiedAtUTC.After(*m.PublishedAtUTC) {
+			return nil, fmt.Errorf(
+				"%w: value of key %q was modified after the publish",
+				entity.ErrBadRequest, c.Key,
+			)
+		}
+		if c.PreviousExists {
+			if err := r.setValue(
+				tx, section, c.Group, c.Key, c.PreviousValue, live != nil, params.RolledBackBy,
+			); err != nil {
+				return nil, err
+			}
+		} else if live != nil {
+			if err := r.deleteValue(tx, section, c.Group, c.Key, params.RolledBackBy); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	m.RolledBackAtUTC = &now
+	m.RolledBackBy = params.RolledBackBy
+	m.ModifiedAtUTC = &now
+	m.ModifiedBy = params.RolledBackBy
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	return r.entityWithChanges(tx, section, m)
+}
+
+func (r *SettingChangeset) changes(
+	db *gorm.DB,
+	changesetID int32,
+) ([]*model.SettingChange, error) {
+	var models []*model.SettingChange
+	if err := db.Where(
+		"`changeset_id` = ?", changesetID,
+	).Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+func (r *SettingChangeset) entityWithChanges(
+	db *gorm.DB,
+	section *entity.SettingChangesetSection,
+	m *model.SettingChangeset,
+) (*entity.SettingChangeset, error) {
+	e := m.Entity()
+	models, err := r.changes(db, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	e.Changes = make([]*entity.SettingChange, len(models))
+	for i, cm := range models {
+		c, err := r.changeEntity(db, section, cm)
+		if err != nil {
+			return nil, err
+		}
+		e.Changes[i] = c
+	}
+	return e, nil
+}
+
+func (r *SettingChangeset) changeEntity(
+	db *gorm.DB,
+	section *entity.SettingChangesetSection,
+	m *model.SettingChange,
+) (*entity.SettingChange, error) {
+	group, _ := entity.ParsePipelineSettingGroup(m.Group)
+	property, err := r.property(db, section, group, m.Key)
+	if err != nil {
+		return nil, err
+	}
+	c := &entity.SettingChange{
+		ID:             m.ID,
+		Group:          group,
+		Key:            m.Key,
+		Operation:      entity.SettingChangeOperation(m.Operation),
+		PreviousExists: m.PreviousExists,
+		Encrypted:      property.Encrypted,
+		CreatedBy:      m.CreatedBy,
+		CreatedAtUTC:   m.CreatedAtUTC,
+		ModifiedBy:     m.ModifiedBy,
+		ModifiedAtUTC:  m.ModifiedAtUTC,
+	}
+	if c.Value, err = r.openValue(section, group, m.Key, m.Value); err != nil {
+		return nil, err
+	}
+	if c.PreviousValue, err = r.openValue(section, group, m.Key, m.PreviousValue); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *SettingChangeset) property(
+	db *gorm.DB,
+	section *entity.SettingChangesetSection,
+	group entity.PipelineSettingGroup,
+	key string,
+) (*entity.PipelineSettingProperty, error) {
+	sectionType := section.Section()
+	property, err := r.settingRepo.GetProperty(db, &entity.GetPipelineSettingPropertyParams{
+		Group:   group,
+		Section: &sectionType,
+		Key:     key,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: property with key %q not found", entity.ErrBadRequest, key,
+			)
+		}
+		return nil, err
+	}
+	return property, nil
+}
+
+// liveValue returns the live value of the key in the section, or nil if it is not set.
+func (r *SettingChangeset) liveValue(
+	db *gorm.DB,
+	section *entity.SettingChangesetSection,
+	group entity.PipelineSettingGroup,
+	key string,
+) (*entity.PipelineSettingValue, error) {
+	value, err := r.settingRepo.GetValue(db, &entity.GetPipelineSettingValueParams{
+		Group:   group,
+		Common:  section.Common,
+		Studio:  section.Studio,
+		Project: section.Project,
+		Key:     key,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+func (r *SettingChangeset) setValue(
+	tx *gorm.DB,
+	section *entity.SettingChangesetSection,
+	group entity.PipelineSettingGroup,
+	key string,
+	value interface{},
+	exists bool,
+	modifiedBy *string,
+) error {
+	if exists {
+		_, err := r.settingRepo.UpdateValue(tx, &entity.UpdatePipelineSettingValueParams{
+			Group:      group,
+			Common:     section.Common,
+			Studio:     section.Studio,
+			Project:    section.Project,
+			Key:        key,
+			Value:      value,
+			ModifiedBy: modifiedBy,
+		})
+		return err
+	}
+	_, err := r.settingRepo.CreateValue(tx, &entity.CreatePipelineSettingValueParams{
+		Group:     group,
+		Common:    section.Common,
+		Studio:    section.Studio,
+		Project:   section.Project,
+		Key:       key,
+		Value:     value,
+		CreatedBy: modifiedBy,
+	})
+	return err
+}
+
+func (r *SettingChangeset) deleteValue(
+	tx *gorm.DB,
+	section *entity.SettingChangesetSection,
+	group entity.PipelineSettingGroup,
+	key string,
+	modifiedBy *string,
+) error {
+	return r.settingRepo.DeleteValue(tx, &entity.DeletePipelineSettingValueParams{
+		Group:      group,
+		Common:     section.Common,
+		Studio:     section.Studio,
+		Project:    section.Project,
+		Key:        key,
+		ModifiedBy: modifiedBy,
+	})
+}
+
+// sealValue encodes the value as JSON, encrypted like the live value when the property is.
+func (r *SettingChangeset) sealValue(
+	section *entity.SettingChangesetSection,
+	property *entity.PipelineSettingProperty,
+	value interface{},
+) (*string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	if property.Encrypted {
+		s, err = r.settingRepo.cipher.Encrypt(s, settingValueAAD(
+			property.Group, section.Section().String(), section.Name(), property.Key,
+		))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+func (r *SettingChangeset) openValue(
+	section *entity.SettingChangesetSection,
+	group entity.PipelineSettingGroup,
+	key string,
+	value *string,
+) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	s, err := r.settingRepo.cipher.Decrypt(*value, settingValueAAD(
+		group, section.Section().String(), section.Name(), key,
+	))
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// sameSettingValue compares values as they are stored, since live values are typed by their
+// schema while drafted ones are decoded from JSON.
+func sameSettingValue(a interface{}, b interface{}) bool {
+	return model.StringifyValue(a) == model.StringifyValue(b)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+)
+
+// encryptedSettingPrefix marks a stored setting value as ciphertext. Values without it are
+// plaintext written before the property was flagged as encrypted.
+const encryptedSettingPrefix = "enc:v1:"
+
+var errSettingCipherNotConfigured = errors.New(
+	"encryption of setting values is not configured",
+)
+
+// settingCipher encrypts setting values with AES-GCM. The data key is kept wrapped by
+// Cloud KMS in PPI_SETTING_DATA_KEY and unwrapped once with the key PPI_SETTING_KMS_KEY.
+type settingCipher struct {
+	aead cipher.AEAD
+}
+
+func newSettingCipherFromEnv() (*settingCipher, error) {
+	keyName := os.Getenv("PPI_SETTING_KMS_KEY")
+	wrappedKeyStr := os.Getenv("PPI_SETTING_DATA_KEY")
+	if keyName == "" && wrappedKeyStr == "" {
+		return nil, nil
+	}
+	if keyName == "" || wrappedKeyStr == "" {
+		return nil, errors.New(
+			"both PPI_SETTING_KMS_KEY and PPI_SETTING_DATA_KEY must be set",
+		)
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(wrappedKeyStr)
+	if err != nil {
+		return nil, fmt.Errorf("PPI_SETTING_DATA_KEY: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	resp, err := client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       keyName,
+		Ciphertext: wrappedKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap setting data key: %w", err)
+	}
+	return newSettingCipher(resp.Plaintext)
+}
+
+func newSettingCipher(key []byte) (*settingCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &settingCipher{aead: aead}, nil
+}
+
+// Encrypt seals the value, binding it to aad so that a ciphertext cannot be moved to another
+// setting.
+func (c *settingCipher) Encrypt(value string, aad string) (string, error) {
+	if c == nil {
+		return "", errSettingCipherNotConfigured
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), []byte(aad))
+	return encryptedSettingPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt. Plaintext values are returned unchanged.
+func (c *settingCipher) Decrypt(value string, aad string) (string, error) {
+	if !strings.HasPrefix(value, encryptedSettingPrefix) {
+		return value, nil
+	}
+	if c == nil {
+		return "", errSettingCipherNotConfigured
+	}
+	sealed, err := base64.StdEncoding.DecodeString(
+		strings.TrimPrefix(value, encryptedSettingPrefix),
+	)
+	if err != nil {
+		return "", err
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("encrypted setting value is too short")
+	}
+	plain, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt setting value: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+)
+
+// statusChatResponseLimit limits the body of an error response of a chat webhook.
+const statusChatResponseLimit = 512
+
+// statusChatColors are the colors of the MS Teams cards of the default statuses.
+var statusChatColors = map[string]string{
+	"approved": "2EB67D",
+	"retake":   "E01E5A",
+}
+
+// statusChannels returns the status chat channels of the project, set in its
+// entity.StatusChatChannelsKey config pipeline setting.
+func (r *Notification) statusChannels(
+	db *gorm.DB,
+	project string,
+) ([]*entity.StatusChatChannel, error) {
+	raw, err := r.getPipelineSettingValue(
+		db, entity.Config, nil, nil, &project, entity.StatusChatChannelsKey,
+	)
+	if errors.Is(err, entity.ErrRecordNotFound) || err == nil && raw == nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var channels []*entity.StatusChatChannel
+	if err := json.Unmarshal(b, &channels); err != nil {
+		return nil, fmt.Errorf(
+			"%w: invalid %s setting of project %s: %s",
+			entity.ErrNotificationSkipped, entity.StatusChatChannelsKey, project, err,
+		)
+	}
+	return channels, nil
+}
+
+// SendStatusChatNotification posts an approval status transition to the Slack and MS Teams
+// channels of the project receiving the phase and status. A failure of any of them fails the
+// notification, which is then retried for all of them.
+func (r *Notification) SendStatusChatNotification(
+	db *gorm.DB,
+	e *entity.StatusChatNotification,
+) error {
+	channels, err := r.statusChannels(db, e.Project)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	sent := 0
+	for _, c := range channels {
+		if c.Webhook == "" || !c.Receives(e.Phase, e.ApprovalStatus) {
+			continue
+		}
+		var message interface{}
+		switch c.Provider {
+		case entity.StatusChatSlack:
+			message = slackStatusMessage(e)
+		case entity.StatusChatTeams:
+			message = teamsStatusMessage(e)
+		default:
+			errs = append(errs, fmt.Errorf("unknown status chat provider %q", c.Provider))
+			continue
+		}
+		if err := postChatWebhook(c.Webhook, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Provider, err))
+			continue
+		}
+		sent++
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	if sent == 0 {
+		return fmt.Errorf(
+			"%w: no status chat channel of project %s receives %s of %s",
+			entity.ErrNotificationSkipped, e.Project, e.ApprovalStatus, e.Phase,
+		)
+	}
+	return nil
+}
+
+func statusChatTitle(e *entity.StatusChatNotification) string {
+	return fmt.Sprintf(
+		"[%s] %s %s %s: %s",
+		e.Project, strings.Join(e.Groups, "/"), e.Relation, e.Phase, e.ApprovalStatus,
+	)
+}
+
+func statusChatFacts(e *entity.StatusChatNotification) [][2]string {
+	previous := e.PreviousApprovalStatus
+	if previous == "" {
+		previous = "-"
+	}
+	return [][2]string{
+		{"Take", e.Take},
+		{"Status", previous + " → " + e.ApprovalStatus},
+		{"Updated by", e.UpdatedUser},
+		{"Review ID", fmt.Sprint(e.ReviewInfoID)},
+	}
+}
+
+// slackStatusMessage formats the transition for a Slack incoming webhook.
+func slackStatusMessage(e *entity.StatusChatNotification) map[string]interface{} {
+	var fields []map[string]interface{}
+	for _, f := range statusChatFacts(e) {
+		fields = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*%s*\n%s", f[0], f[1]),
+		})
+	}
+	title := statusChatTitle(e)
+	return map[string]interface{}{
+		"text": title,
+		"blocks": []map[string]interface{}{
+			{
+				"type": "header",
+				"text": map[string]interface{}{"type": "plain_text", "text": title},
+			},
+			{
+				"type":   "section",
+				"fields": fields,
+			},
+		},
+	}
+}
+
+// teamsStatusMessage formats the transition as a message card for an MS Teams incoming
+// webhook.
+func teamsStatusMessage(e *entity.StatusChatNotification) map[string]interface{} {
+	var facts []map[string]interface{}
+	for _, f := range statusChatFacts(e) {
+		facts = append(facts, map[string]interface{}{"name": f[0], "value": f[1]})
+	}
+	title := statusChatTitle(e)
+	message := map[string]interface{}{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  title,
+		"title":    title,
+		"sections": []map[string]interface{}{
+			{"facts": facts},
+		},
+	}
+	if color, ok := statusChatColors[strings.ToLower(e.ApprovalStatus)]; ok {
+		message["themeColor"] = color
+	}
+	return message
+}
+
+// postChatWebhook posts a message to a chat webhook once. Retries are left to the
+// notification outbox.
+func postChatWebhook(webhook string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(webhook, "application/json; charset=UTF-8", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, statusChatResponseLimit))
+		return fmt.Errorf("chat webhook responded %d: %s", resp.StatusCode, b)
+	}
+	return nil
+}
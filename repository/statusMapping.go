@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// statusBackfillBatchSize is the number of reviews rewritten per statement by a backfill.
+const statusBackfillBatchSize = 1000
+
+// statusColumns are the columns of the statuses of each kind in the reviews.
+var statusColumns = map[string]string{
+	entity.StatusKindApproval: "approval_status",
+	entity.StatusKindWork:     "work_status",
+}
+
+// StatusMapping stores the legacy status mappings of the projects. The cached query results
+// of a project are invalidated when its mappings change, as they are read through them.
+type StatusMapping struct {
+	db    *gorm.DB
+	cache QueryCache
+}
+
+func NewStatusMapping(db *gorm.DB, cache QueryCache) (*StatusMapping, error) {
+	if err := db.AutoMigrate(&model.StatusMapping{}); err != nil {
+		return nil, err
+	}
+	return &StatusMapping{
+		db:    db,
+		cache: cache,
+	}, nil
+}
+
+func (r *StatusMapping) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *StatusMapping) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *StatusMapping) invalidate(db *gorm.DB, project string) {
+	if r.cache != nil {
+		r.cache.Invalidate(db.Statement.Context, project)
+	}
+}
+
+func (r *StatusMapping) List(
+	db *gorm.DB,
+	params *entity.ListStatusMappingsParams,
+) ([]*entity.StatusMapping, error) {
+	var models []*model.StatusMapping
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Order("`kind` asc").Order("`legacy_value` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.StatusMapping, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// Update maps a legacy status to a value, replacing its previous mapping. Mappings are not
+// chained, so a legacy value may not be the value of another mapping of the same kind.
+func (r *StatusMapping) Update(
+	tx *gorm.DB,
+	params *entity.UpdateStatusMappingParams,
+) (*entity.StatusMapping, error) {
+	if strings.EqualFold(params.LegacyValue, params.Value) {
+		return nil, fmt.Errorf(
+			"%w: legacy status %q is mapped to itself", entity.ErrBadRequest, params.LegacyValue,
+		)
+	}
+	mappings := func() *gorm.DB {
+		return tx.Model(&model.StatusMapping{}).Where(
+			"`deleted` = ?", 0,
+		).Where(
+			"`project` = ?", params.Project,
+		).Where(
+			"`kind` = ?", params.Kind,
+		)
+	}
+	var count int64
+	if err := mappings().Where(
+		"(`legacy_value` = ? OR `value` = ?)", params.Value, params.LegacyValue,
+	).Where(
+		"`legacy_value` <> ?", params.LegacyValue,
+	).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count != 0 {
+		return nil, fmt.Errorf(
+			"%w: mapping %s status %q to %q would chain status mappings", entity.ErrBadRequest,
+			params.Kind, params.LegacyValue, params.Value,
+		)
+	}
+
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m model.StatusMapping
+	err := mappings().Where("`legacy_value` = ?", params.LegacyValue).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.StatusMapping{
+			Project:       params.Project,
+			Kind:          params.Kind,
+			LegacyValue:   params.LegacyValue,
+			Value:         params.Value,
+			CreatedAtUTC:  now,
+			ModifiedAtUTC: now,
+			ModifiedBy:    modifiedBy,
+			CreatedBy:     modifiedBy,
+		}
+		err = tx.Create(&m).Error
+	} else if err == nil {
+		m.LegacyValue = params.LegacyValue
+		m.Value = params.Value
+		m.ModifiedAtUTC = now
+		m.ModifiedBy = modifiedBy
+		err = tx.Save(&m).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(tx, params.Project)
+	return m.Entity(), nil
+}
+
+func (r *StatusMapping) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteStatusMappingParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	result := tx.Model(&model.StatusMapping{}).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: status mapping with ID %d", entity.ErrRecordNotFound, params.ID,
+		)
+	}
+	r.invalidate(tx, params.Project)
+	return nil
+}
+
+// Backfill rewrites the legacy statuses stored in the reviews of the project, and in their
+// latest reviews, to their mapped values, in batches each committed on its own. An interrupted
+// backfill is resumed by running it again, while the statuses not rewritten yet are still
+// normalized when they are read. The modification times of the reviews are left untouched, as
+// they order the reviews.
+func (r *StatusMapping) Backfill(
+	db *gorm.DB,
+	params *entity.BackfillStatusMappingsParams,
+) (*entity.StatusBackfillResult, error) {
+	vocab, err := loadStatusVocabulary(db, params.Project)
+	if err != nil {
+		return nil, err
+	}
+	result := &entity.StatusBackfillResult{
+		Project: params.Project,
+		DryRun:  params.DryRun,
+	}
+	for _, kind := range []string{entity.StatusKindApproval, entity.StatusKindWork} {
+		legacy := vocab.legacyValues(kind)
+		if len(legacy) == 0 {
+			continue
+		}
+		column := statusColumns[kind]
+		var rewritten int64
+		for _, table := range []string{"t_review_info", "t_review_latest"} {
+			stmt := func() *gorm.DB {
+				return db.Table(table).Where(
+					"project = ?", params.Project,
+				).Where(
+					column+" IN ?", legacy,
+				)
+			}
+			if params.DryRun {
+				// the latest reviews are copies of reviews, so they are not counted
+				if table == "t_review_info" {
+					var count int64
+					if err := stmt().Count(&count).Error; err != nil {
+						return nil, fmt.Errorf("Backfill: %w", err)
+					}
+					rewritten += count
+				}
+				continue
+			}
+			for {
+				batch := stmt().Limit(statusBackfillBatchSize).UpdateColumn(
+					column, gorm.Expr(vocab.column(kind, column)),
+				)
+				if err := batch.Error; err != nil {
+					return nil, fmt.Errorf("Backfill: %w", err)
+				}
+				if table == "t_review_info" {
+					rewritten += batch.RowsAffected
+				}
+				if batch.RowsAffected < statusBackfillBatchSize {
+					break
+				}
+			}
+		}
+		if kind == entity.StatusKindApproval {
+			result.ApprovalStatuses = rewritten
+		} else {
+			result.WorkStatuses = rewritten
+		}
+	}
+	if !params.DryRun {
+		r.invalidate(db, params.Project)
+	}
+	return result, nil
+}
+
+// statusVocabulary is the legacy status mappings of a project, per kind and lower case legacy
+// value. The legacy values are matched case insensitively, as by the collation of the status
+// columns.
+type statusVocabulary map[string]map[string]string
+
+func loadStatusVocabulary(db *gorm.DB, project string) (statusVocabulary, error) {
+	var models []*model.StatusMapping
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	vocab := statusVocabulary{}
+	for _, m := range models {
+		if vocab[m.Kind] == nil {
+			vocab[m.Kind] = map[string]string{}
+		}
+		vocab[m.Kind][strings.ToLower(m.LegacyValue)] = m.Value
+	}
+	return vocab, nil
+}
+
+// legacyValues returns the legacy values of a kind, sorted for the statements to be stable.
+func (v statusVocabulary) legacyValues(kind string) []string {
+	legacy := make([]string, 0, len(v[kind]))
+	for value := range v[kind] {
+		legacy = append(legacy, value)
+	}
+	sort.Strings(legacy)
+	return legacy
+}
+
+// normalize returns the status of a kind in the current vocabulary.
+func (v statusVocabulary) normalize(kind, status string) string {
+	if value, ok := v[kind][strings.ToLower(status)]; ok {
+		return value
+	}
+	return status
+}
+
+// column returns the SQL expression reading the statuses of a kind from column in the current
+// vocabulary, or column itself when the kind has no mapping.
+func (v statusVocabulary) column(kind, column string) string {
+	legacy := v.legacyValues(kind)
+	if len(legacy) == 0 {
+		return column
+	}
+	var b strings.Builder
+	b.WriteString("CASE " + column)
+	for _, value := range legacy {
+		b.WriteString(" WHEN " + sqlStringLiteral(value))
+		b.WriteString(" THEN " + sqlStringLiteral(v[kind][value]))
+	}
+	b.WriteString(" ELSE " + column + " END")
+	return b.String()
+}
+
+// sqlStringLiteral quotes s as a MySQL string literal.
+func sqlStringLiteral(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(s) + "'"
+}
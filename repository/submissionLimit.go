@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+type SubmissionLimit struct {
+	db *gorm.DB
+}
+
+func NewSubmissionLimit(db *gorm.DB) (*SubmissionLimit, error) {
+	if err := db.AutoMigrate(
+		&model.SubmissionLimit{},
+		&model.SubmissionLimitOverride{},
+	); err != nil {
+		return nil, err
+	}
+	return &SubmissionLimit{
+		db: db,
+	}, nil
+}
+
+func (r *SubmissionLimit) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *SubmissionLimit) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *SubmissionLimit) getLimit(db *gorm.DB, project string) (*model.SubmissionLimit, error) {
+	var m model.SubmissionLimit
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// activeOverrides returns the overrides of the project which have not expired at now.
+func (r *SubmissionLimit) activeOverrides(
+	db *gorm.DB,
+	project string,
+	now time.Time,
+) *gorm.DB {
+	return db.Model(&model.SubmissionLimitOverride{}).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`expires_at_utc` > ?", now,
+	)
+}
+
+// Get returns the submission limit of the project with its active overrides.
+func (r *SubmissionLimit) Get(
+	db *gorm.DB,
+	params *entity.GetSubmissionLimitParams,
+) (*entity.SubmissionLimit, error) {
+	m, err := r.getLimit(db, params.Project)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf(
+			"%w: submission limit of project %q", entity.ErrRecordNotFound, params.Project,
+		)
+	}
+	var overrides []*model.SubmissionLimitOverride
+	if err := r.activeOverrides(db, params.Project, time.Now().UTC()).Order(
+		"`expires_at_utc` asc",
+	).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	for _, o := range overrides {
+		e.Overrides = append(e.Overrides, o.Entity())
+	}
+	return e, nil
+}
+
+func (r *SubmissionLimit) Update(
+	tx *gorm.DB,
+	params *entity.UpdateSubmissionLimitParams,
+) (*entity.SubmissionLimit, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	m, err := r.getLimit(tx, params.Project)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = &model.SubmissionLimit{
+			Project:        params.Project,
+			MaxSubmissions: params.MaxSubmissions,
+			WindowMinutes:  params.WindowMinutes,
+			CreatedAtUTC:   now,
+			ModifiedAtUTC:  now,
+			ModifiedBy:     modifiedBy,
+			CreatedBy:      modifiedBy,
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return nil, err
+		}
+		return m.Entity(), nil
+	}
+	m.MaxSubmissions = params.MaxSubmissions
+	m.WindowMinutes = params.WindowMinutes
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	return m.Entity(), tx.Save(m).Error
+}
+
+func (r *SubmissionLimit) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteSubmissionLimitParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.SubmissionLimit
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: submission limit of project %q", entity.ErrRecordNotFound, params.Project,
+		)
+	}
+	return nil
+}
+
+func (r *SubmissionLimit) CreateOverride(
+	tx *gorm.DB,
+	params *entity.CreateSubmissionLimitOverrideParams,
+) (*entity.SubmissionLimitOverride, error) {
+	now := time.Now().UTC()
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	m := &model.SubmissionLimitOverride{
+		Project:       params.Project,
+		Asset:         params.Asset,
+		Reason:        params.Reason,
+		ExpiresAtUTC:  params.ExpiresAtUTC.UTC(),
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *SubmissionLimit) DeleteOverride(
+	tx *gorm.DB,
+	params *entity.DeleteSubmissionLimitOverrideParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.SubmissionLimitOverride
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: submission limit override with ID %d", entity.ErrRecordNotFound, params.ID,
+		)
+	}
+	return nil
+}
+
+// CheckSubmission counts the reviews submitted for the asset within the window of the
+// submission limit of its project, the deleted ones included so that deleting the reviews of
+// a runaway watcher does not let it submit again at once. It returns nil when the project has
+// no limit or an active override lifts it, and a *entity.SubmissionRateLimitError when the
+// limit is reached. Concurrent submissions may exceed the limit by a few reviews.
+func (r *SubmissionLimit) CheckSubmission(
+	tx *gorm.DB,
+	params *entity.CheckSubmissionParams,
+) error {
+	limit, err := r.getLimit(tx, params.Project)
+	if err != nil || limit == nil || len(params.Groups) == 0 {
+		return err
+	}
+	now := time.Now().UTC()
+	var overrides int64
+	if err := r.activeOverrides(tx, params.Project, now).Where(
+		"(`asset` IS NULL OR `asset` = ?)", params.Groups[0],
+	).Count(&overrides).Error; err != nil {
+		return err
+	}
+	if overrides != 0 {
+		return nil
+	}
+
+	groups, err := json.Marshal(params.Groups)
+	if err != nil {
+		return err
+	}
+	window := time.Duration(limit.WindowMinutes) * time.Minute
+	var submitted []time.Time
+	if err := tx.Table("t_review_info").Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`root` = ?", params.Root,
+	).Where(
+		"`group_1` = ?", params.Groups[0],
+	).Where(
+		"`relation` = ?", params.Relation,
+	).Where(
+		"`groups` = CAST(? AS JSON)", string(groups),
+	).Where(
+		"`created_at_utc` > ?", now.Add(-window),
+	).Order(
+		"`created_at_utc` desc",
+	).Limit(int(limit.MaxSubmissions)).Pluck("created_at_utc", &submitted).Error; err != nil {
+		return err
+	}
+	if len(submitted) < int(limit.MaxSubmissions) {
+		return nil
+	}
+	// the oldest of the counted submissions leaves the window first
+	return &entity.SubmissionRateLimitError{
+		Project:        params.Project,
+		Root:           params.Root,
+		Groups:         params.Groups,
+		Relation:       params.Relation,
+		MaxSubmissions: limit.MaxSubmissions,
+		WindowMinutes:  limit.WindowMinutes,
+		RetryAfter:     submitted[len(submitted)-1].Add(window).Sub(now),
+	}
+}
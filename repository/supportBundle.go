@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"gorm.io/gorm"
+)
+
+// supportPingTimeout bounds the ping of each dependency.
+const supportPingTimeout = 5 * time.Second
+
+// supportRedacted replaces the secrets in the support bundles.
+const supportRedacted = "[redacted]"
+
+// supportConfigPrefixes are the prefixes of the environment variables configuring the API.
+var supportConfigPrefixes = []string{"PPI_", "PPIP30_", "NEO4J_", "GIN_"}
+
+// supportSecretPattern matches the names of the environment variables holding secrets.
+var supportSecretPattern = regexp.MustCompile(`PASSWORD|SECRET|KEY|TOKEN|WEBHOOK|CREDENTIAL`)
+
+// SupportBundle reads the configuration of the API and the health of its dependencies. The
+// Neo4j driver is nil when the DataDependency API is not available.
+type SupportBundle struct {
+	db      *gorm.DB
+	mongoDB *mongo.Database
+	neo4j   neo4j.DriverWithContext
+}
+
+func NewSupportBundle(
+	db *gorm.DB,
+	mongoDB *mongo.Database,
+	neo4jDriver neo4j.DriverWithContext,
+) *SupportBundle {
+	return &SupportBundle{
+		db:      db,
+		mongoDB: mongoDB,
+		neo4j:   neo4jDriver,
+	}
+}
+
+// Config returns the environment variables configuring the API, with the secrets redacted,
+// and a replacer redacting their values from any text.
+func (r *SupportBundle) Config() (map[string]string, *strings.Replacer) {
+	config := map[string]string{}
+	var secrets []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		configured := false
+		for _, prefix := range supportConfigPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				configured = true
+				break
+			}
+		}
+		if !configured {
+			continue
+		}
+		if supportSecretPattern.MatchString(name) && value != "" {
+			config[name] = supportRedacted
+			// too short values would redact unrelated text
+			if len(value) >= 4 {
+				secrets = append(secrets, value, supportRedacted)
+			}
+			continue
+		}
+		config[name] = value
+	}
+	return config, strings.NewReplacer(secrets...)
+}
+
+// CheckDependencies pings MySQL, MongoDB and Neo4j.
+func (r *SupportBundle) CheckDependencies(ctx context.Context) []*entity.DependencyHealth {
+	mysql := r.ping(ctx, "mysql", func(ctx context.Context) error {
+		sqlDB, err := r.db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	mongoDB := r.ping(ctx, string(entity.DependencyMongo), func(ctx context.Context) error {
+		return r.mongoDB.Client().Ping(ctx, readpref.Primary())
+	})
+	neo4jDB := &entity.DependencyHealth{
+		Dependency: string(entity.DependencyNeo4j),
+		Status:     entity.DependencyStatusDisabled,
+	}
+	if r.neo4j != nil {
+		neo4jDB = r.ping(ctx, string(entity.DependencyNeo4j), r.neo4j.VerifyConnectivity)
+	}
+	return []*entity.DependencyHealth{mysql, mongoDB, neo4jDB}
+}
+
+func (r *SupportBundle) ping(
+	ctx context.Context,
+	dependency string,
+	ping func(ctx context.Context) error,
+) *entity.DependencyHealth {
+	timeoutCtx, cancel := context.WithTimeout(ctx, supportPingTimeout)
+	defer cancel()
+	start := time.Now()
+	err := ping(timeoutCtx)
+	h := &entity.DependencyHealth{
+		Dependency: dependency,
+		Status:     entity.DependencyStatusOK,
+		LatencyMS:  time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		h.Status = entity.DependencyStatusDown
+		h.Error = err.Error()
+	}
+	return h
+}
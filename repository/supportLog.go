@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// supportLogCapacity and supportSlowQueryCapacity bound the errors and the slow queries
+	// kept in memory, the oldest being dropped first.
+	supportLogCapacity       = 1000
+	supportSlowQueryCapacity = 500
+	// supportSQLMaxLength truncates the kept queries, such as the batch inserts.
+	supportSQLMaxLength = 4096
+	// slowQueryThreshold is the duration from which queries are slow, as for the default
+	// logger of gorm.
+	slowQueryThreshold = 200 * time.Millisecond
+)
+
+// supportErrorPattern matches the log lines reporting errors.
+var supportErrorPattern = regexp.MustCompile(`(?i)error|fail|panic`)
+
+// SupportLog keeps the recent errors and slow queries of the instance in memory, for the
+// support bundles. It receives the output of the standard logger as an io.Writer, and the
+// queries of gorm through GormLogger.
+type SupportLog struct {
+	mu          sync.Mutex
+	errors      []*entity.SupportLogEntry
+	slowQueries []*entity.SlowQuery
+	// since is when the buffers start to be complete, i.e. the start of the instance until
+	// entries are dropped.
+	since time.Time
+}
+
+func NewSupportLog() *SupportLog {
+	return &SupportLog{
+		since: time.Now().UTC(),
+	}
+}
+
+// Write keeps the error lines of the standard logger, which writes an entry at a time.
+func (r *SupportLog) Write(p []byte) (int, error) {
+	message := strings.TrimRight(string(p), "\n")
+	if supportErrorPattern.MatchString(message) {
+		r.addError(&entity.SupportLogEntry{
+			LoggedAtUTC: time.Now().UTC(),
+			Message:     message,
+		})
+	}
+	return len(p), nil
+}
+
+func (r *SupportLog) addError(e *entity.SupportLogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.errors) == supportLogCapacity {
+		r.dropped(r.errors[0].LoggedAtUTC)
+		r.errors = r.errors[1:]
+	}
+	r.errors = append(r.errors, e)
+}
+
+func (r *SupportLog) addSlowQuery(q *entity.SlowQuery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.slowQueries) == supportSlowQueryCapacity {
+		r.dropped(r.slowQueries[0].StartedAtUTC)
+		r.slowQueries = r.slowQueries[1:]
+	}
+	r.slowQueries = append(r.slowQueries, q)
+}
+
+// dropped moves the start of the buffers after an entry dropped at t. r.mu must be held.
+func (r *SupportLog) dropped(t time.Time) {
+	if t.After(r.since) {
+		r.since = t
+	}
+}
+
+// List returns the errors and the slow queries from since, only those mentioning the
+// project unless it is empty, and since when the buffers are complete.
+func (r *SupportLog) List(
+	since time.Time,
+	project string,
+) ([]*entity.SupportLogEntry, []*entity.SlowQuery, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	errs := []*entity.SupportLogEntry{}
+	for _, e := range r.errors {
+		if !e.LoggedAtUTC.Before(since) && strings.Contains(e.Message, project) {
+			copied := *e
+			errs = append(errs, &copied)
+		}
+	}
+	queries := []*entity.SlowQuery{}
+	for _, q := range r.slowQueries {
+		if !q.StartedAtUTC.Before(since) && strings.Contains(q.SQL, project) {
+			copied := *q
+			queries = append(queries, &copied)
+		}
+	}
+	return errs, queries, r.since
+}
+
+// GormLogger wraps the logger of gorm to keep the slow and the failed queries too.
+func (r *SupportLog) GormLogger(l logger.Interface) logger.Interface {
+	return &supportGormLogger{
+		Interface: l,
+		log:       r,
+	}
+}
+
+type supportGormLogger struct {
+	logger.Interface
+	log *SupportLog
+}
+
+func (l *supportGormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &supportGormLogger{
+		Interface: l.Interface.LogMode(level),
+		log:       l.log,
+	}
+}
+
+func (l *supportGormLogger) Trace(
+	ctx context.Context,
+	begin time.Time,
+	fc func() (string, int64),
+	err error,
+) {
+	l.Interface.Trace(ctx, begin, fc, err)
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	if elapsed < slowQueryThreshold && !failed {
+		return
+	}
+	sql, rows := fc()
+	if len(sql) > supportSQLMaxLength {
+		sql = sql[:supportSQLMaxLength] + "..."
+	}
+	q := &entity.SlowQuery{
+		StartedAtUTC: begin.UTC(),
+		DurationMS:   elapsed.Milliseconds(),
+		Rows:         rows,
+		SQL:          sql,
+	}
+	if failed {
+		q.Error = err.Error()
+	}
+	l.log.addSlowQuery(q)
+}
@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+type Tag struct {
+	db *gorm.DB
+}
+
+func NewTag(db *gorm.DB) (*Tag, error) {
+	if err := db.AutoMigrate(&model.Tag{}, &model.TagAssignment{}); err != nil {
+		return nil, err
+	}
+	return &Tag{
+		db: db,
+	}, nil
+}
+
+func (r *Tag) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Tag) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *Tag) List(
+	db *gorm.DB,
+	params *entity.ListTagsParams,
+) ([]*entity.Tag, int, error) {
+	stmt := db.Model(&model.Tag{}).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	)
+	if params.Query != nil {
+		stmt = stmt.Where("`name` LIKE ?", *params.Query+"%")
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.Tag
+	if err := limitOffset(
+		stmt.Order("`name` asc").Order("`id` asc"), params.BaseListParams,
+	).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int32, len(models))
+	for i, m := range models {
+		ids[i] = m.ID
+	}
+	var counts []struct {
+		TagID int32
+		Count int
+	}
+	if len(ids) > 0 {
+		if err := db.Model(&model.TagAssignment{}).Select(
+			"`tag_id`, COUNT(*) AS `count`",
+		).Where(
+			"`tag_id` IN ?", ids,
+		).Group("`tag_id`").Scan(&counts).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+	numTargets := make(map[int32]int, len(counts))
+	for _, c := range counts {
+		numTargets[c.TagID] = c.Count
+	}
+
+	entities := make([]*entity.Tag, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity(numTargets[m.ID])
+	}
+	return entities, int(total), nil
+}
+
+func (r *Tag) get(db *gorm.DB, project string, id int32) (*model.Tag, error) {
+	var m model.Tag
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: tag with ID %d not found", entity.ErrRecordNotFound, id)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *Tag) Create(
+	tx *gorm.DB,
+	params *entity.CreateTagParams,
+) (*entity.Tag, error) {
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	m := model.NewTag(params.Project, params.Name, createdBy)
+	if err := tx.Create(m).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, fmt.Errorf(
+				"%w: tag %q is already exists", entity.ErrBadRequest, params.Name,
+			)
+		}
+		return nil, err
+	}
+	return m.Entity(0), nil
+}
+
+// Update renames a tag. The assignments refer to the tag by ID and follow the new name.
+func (r *Tag) Update(
+	tx *gorm.DB,
+	params *entity.UpdateTagParams,
+) (*entity.Tag, error) {
+	m, err := r.get(tx, params.Project, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	m.Name = params.Name
+	m.ModifiedAtUTC = time.Now().UTC()
+	if params.ModifiedBy != nil {
+		m.ModifiedBy = *params.ModifiedBy
+	}
+	if err := tx.Save(m).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, fmt.Errorf(
+				"%w: tag %q is already exists", entity.ErrBadRequest, params.Name,
+			)
+		}
+		return nil, err
+	}
+	var numTargets int64
+	if err := tx.Model(&model.TagAssignment{}).Where(
+		"`tag_id` = ?", m.ID,
+	).Count(&numTargets).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(int(numTargets)), nil
+}
+
+// Delete deletes a tag and detaches it from all its targets.
+func (r *Tag) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteTagParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.Tag
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: tag with ID %d not found", entity.ErrRecordNotFound, params.ID)
+	}
+	return tx.Where("`tag_id` = ?", params.ID).Delete(&model.TagAssignment{}).Error
+}
+
+func (r *Tag) GetTarget(
+	db *gorm.DB,
+	params *entity.GetTagTargetParams,
+) (*entity.TagTarget, error) {
+	tags, err := listTargetTags(db, params.Project, params.TargetType, []string{params.Target})
+	if err != nil {
+		return nil, err
+	}
+	names := tags[params.Target]
+	if names == nil {
+		names = []string{}
+	}
+	return &entity.TagTarget{
+		Project:    params.Project,
+		TargetType: params.TargetType,
+		Target:     params.Target,
+		Tags:       names,
+	}, nil
+}
+
+// Attach attaches the tags to the target, creating the tags which do not exist yet. Tags
+// already attached are left as they are.
+func (r *Tag) Attach(
+	tx *gorm.DB,
+	params *entity.UpdateTagTargetParams,
+) (*entity.TagTarget, error) {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	tags, err := r.findByNames(tx, params.Project, params.Tags)
+	if err != nil {
+		return nil, err
+	}
+	attached, err := listTargetTags(tx, params.Project, params.TargetType, []string{params.Target})
+	if err != nil {
+		return nil, err
+	}
+	isAttached := make(map[string]bool, len(attached[params.Target]))
+	for _, name := range attached[params.Target] {
+		isAttached[name] = true
+	}
+
+	now := time.Now().UTC()
+	for _, name := range params.Tags {
+		if isAttached[name] {
+			continue
+		}
+		m, ok := tags[name]
+		if !ok {
+			m = model.NewTag(params.Project, name, modifiedBy)
+			if err := tx.Create(m).Error; err != nil {
+				return nil, err
+			}
+			tags[name] = m
+		}
+		if err := tx.Create(&model.TagAssignment{
+			Project:      params.Project,
+			TagID:        m.ID,
+			TargetType:   params.TargetType,
+			Target:       params.Target,
+			CreatedAtUTC: now,
+			CreatedBy:    modifiedBy,
+		}).Error; err != nil {
+			return nil, err
+		}
+		isAttached[name] = true
+	}
+	return r.GetTarget(tx, &entity.GetTagTargetParams{
+		Project:    params.Project,
+		TargetType: params.TargetType,
+		Target:     params.Target,
+	})
+}
+
+// Detach detaches the tags from the target. Tags which are not attached are ignored.
+func (r *Tag) Detach(
+	tx *gorm.DB,
+	params *entity.UpdateTagTargetParams,
+) (*entity.TagTarget, error) {
+	tags, err := r.findByNames(tx, params.Project, params.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		ids := make([]int32, 0, len(tags))
+		for _, m := range tags {
+			ids = append(ids, m.ID)
+		}
+		if err := tx.Where(
+			"`tag_id` IN ?", ids,
+		).Where(
+			"`target_type` = ?", params.TargetType,
+		).Where(
+			"`target` = ?", params.Target,
+		).Delete(&model.TagAssignment{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return r.GetTarget(tx, &entity.GetTagTargetParams{
+		Project:    params.Project,
+		TargetType: params.TargetType,
+		Target:     params.Target,
+	})
+}
+
+func (r *Tag) findByNames(
+	db *gorm.DB,
+	project string,
+	names []string,
+) (map[string]*model.Tag, error) {
+	var models []*model.Tag
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`name` IN ?", names,
+	).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	tags := make(map[string]*model.Tag, len(models))
+	for _, m := range models {
+		tags[m.Name] = m
+	}
+	return tags, nil
+}
+
+// ListTargetTags returns the sorted tag names of the given targets keyed by target.
+func (r *Tag) ListTargetTags(
+	db *gorm.DB,
+	project string,
+	targetType string,
+	targets []string,
+) (map[string][]string, error) {
+	return listTargetTags(db, project, targetType, targets)
+}
+
+// ListTypeTags returns the sorted tag names of all the tagged targets of the type keyed by
+// target.
+func (r *Tag) ListTypeTags(
+	db *gorm.DB,
+	project string,
+	targetType string,
+) (map[string][]string, error) {
+	return scanTargetTags(db, project, targetType)
+}
+
+func listTargetTags(
+	db *gorm.DB,
+	project string,
+	targetType string,
+	targets []string,
+) (map[string][]string, error) {
+	if len(targets) == 0 {
+		return map[string][]string{}, nil
+	}
+	return scanTargetTags(db.Where("ta.target IN ?", targets), project, targetType)
+}
+
+func scanTargetTags(
+	db *gorm.DB,
+	project string,
+	targetType string,
+) (map[string][]string, error) {
+	var rows []struct {
+		Target string
+		Name   string
+	}
+	if err := db.Table("t_tag_assignment AS ta").
+		Select("ta.target, t.name").
+		Joins("INNER JOIN t_tag AS t ON t.id = ta.tag_id").
+		Where("ta.project = ?", project).
+		Where("ta.target_type = ?", targetType).
+		Where("t.deleted = ?", 0).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("listTargetTags: %w", err)
+	}
+	tags := map[string][]string{}
+	for _, row := range rows {
+		tags[row.Target] = append(tags[row.Target], row.Name)
+	}
+	for _, names := range tags {
+		sort.Strings(names)
+	}
+	return tags, nil
+}
+
+// whereTagged keeps the records having all the given tags. targetExpr is the SQL expression
+// of the record's target, such as the review ID or "<asset>/<relation>".
+func whereTagged(
+	stmt *gorm.DB,
+	db *gorm.DB,
+	project string,
+	targetType string,
+	targetExpr string,
+	tags []string,
+) *gorm.DB {
+	for _, name := range tags {
+		sub := db.Table("t_tag_assignment AS ta").
+			Select("1").
+			Joins("INNER JOIN t_tag AS t ON t.id = ta.tag_id").
+			Where("ta.project = ?", project).
+			Where("ta.target_type = ?", targetType).
+			Where("ta.target = "+targetExpr).
+			Where("t.deleted = ?", 0).
+			Where("t.name = ?", name)
+		stmt = stmt.Where("EXISTS (?)", sub)
+	}
+	return stmt
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// ThumbnailPin stores the thumbnails pinned as the representative images of assets.
+type ThumbnailPin struct {
+	db *gorm.DB
+}
+
+func NewThumbnailPin(db *gorm.DB) (*ThumbnailPin, error) {
+	if err := db.AutoMigrate(&model.ThumbnailPin{}); err != nil {
+		return nil, err
+	}
+	return &ThumbnailPin{
+		db: db,
+	}, nil
+}
+
+func (r *ThumbnailPin) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *ThumbnailPin) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+// Get returns the pin of the thumbnail of an asset, or nil when it is not pinned.
+func (r *ThumbnailPin) Get(
+	db *gorm.DB,
+	project string,
+	asset string,
+	relation string,
+) (*entity.ThumbnailPin, error) {
+	var m model.ThumbnailPin
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`asset` = ?", asset,
+	).Where(
+		"`relation` = ?", relation,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// List returns the pins of the thumbnails of the assets among keys.
+func (r *ThumbnailPin) List(
+	db *gorm.DB,
+	project string,
+	keys []entity.AssetThumbnailKey,
+) (map[entity.AssetThumbnailKey]*entity.ThumbnailPin, error) {
+	assets := make([]string, len(keys))
+	for i, key := range keys {
+		assets[i] = key.Asset
+	}
+	var models []*model.ThumbnailPin
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`asset` IN ?", assets,
+	).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	pins := make(map[entity.AssetThumbnailKey]*entity.ThumbnailPin, len(models))
+	for _, m := range models {
+		pins[entity.AssetThumbnailKey{Asset: m.Asset, Relation: m.Relation}] = m.Entity()
+	}
+	return pins, nil
+}
+
+// Put pins a thumbnail of an asset, replacing its previous pin.
+func (r *ThumbnailPin) Put(
+	tx *gorm.DB,
+	params *entity.PinAssetThumbnailParams,
+) (*entity.ThumbnailPin, error) {
+	now := time.Now().UTC()
+	var m model.ThumbnailPin
+	err := tx.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`asset` = ?", params.Asset,
+	).Where(
+		"`relation` = ?", params.Relation,
+	).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.ThumbnailPin{
+			Project:  params.Project,
+			Asset:    params.Asset,
+			Relation: params.Relation,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	m.Phase = params.Phase
+	m.Revision = params.Revision
+	m.Name = params.Name
+	m.PinnedAtUTC = now
+	m.PinnedBy = params.PinnedBy
+	if err := tx.Save(&m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *ThumbnailPin) Delete(
+	tx *gorm.DB,
+	params *entity.UnpinAssetThumbnailParams,
+) error {
+	result := tx.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`asset` = ?", params.Asset,
+	).Where(
+		"`relation` = ?", params.Relation,
+	).Delete(&model.ThumbnailPin{})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: thumbnail pin of %s/%s", entity.ErrRecordNotFound, params.Asset, params.Relation,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DBLocation is the time zone of the datetimes stored in the database. The connections read
+// and write datetimes in it, and their sessions compute NOW() and the datetime functions in
+// it, so that the `*_at_utc` columns compare the same in Go and in raw SQL. It is UTC unless
+// set otherwise before the connections are opened.
+var DBLocation = time.UTC
+
+// ParseDBTimeZone parses the IANA name of the time zone of the database, UTC when empty.
+func ParseDBTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// SessionTimeZone is the MySQL time_zone of the sessions for loc. UTC is given as an offset,
+// which needs no time zone tables on the server, and other zones by name, so that MySQL
+// follows their daylight saving time as Go does.
+func SessionTimeZone(loc *time.Location) string {
+	if loc == time.UTC {
+		return "+00:00"
+	}
+	return loc.String()
+}
+
+// dbTime formats t as a datetime of the database, as MySQL compares it with strings.
+func dbTime(t time.Time) string {
+	return t.In(DBLocation).Format("2006-01-02 15:04:05.999999")
+}
+
+// parseDBTime parses a datetime of the database read as a string, e.g. from a JSON object.
+func parseDBTime(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 15:04:05.999999", s, DBLocation)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// setDBLocation sets DBLocation for the test, restoring it when the test finishes.
+func setDBLocation(t *testing.T, loc *time.Location) {
+	t.Helper()
+	prev := DBLocation
+	DBLocation = loc
+	t.Cleanup(func() { DBLocation = prev })
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func TestParseDBTimeZone(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: "UTC"},
+		{name: "UTC", want: "UTC"},
+		{name: "utc", want: "UTC"},
+		{name: " UTC ", want: "UTC"},
+		{name: "Asia/Tokyo", want: "Asia/Tokyo"},
+		{name: "America/Los_Angeles", want: "America/Los_Angeles"},
+		{name: " Asia/Tokyo ", want: "Asia/Tokyo"},
+		{name: "Mars/Olympus_Mons", wantErr: true},
+	}
+	for _, tt := range tests {
+		loc, err := ParseDBTimeZone(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDBTimeZone(%q) = %v, want an error", tt.name, loc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDBTimeZone(%q): %v", tt.name, err)
+			continue
+		}
+		if loc.String() != tt.want {
+			t.Errorf("ParseDBTimeZone(%q) = %v, want %v", tt.name, loc, tt.want)
+		}
+	}
+	if loc, _ := ParseDBTimeZone("utc"); loc != time.UTC {
+		t.Errorf("ParseDBTimeZone(%q) is not time.UTC", "utc")
+	}
+}
+
+func TestSessionTimeZone(t *testing.T) {
+	if got := SessionTimeZone(time.UTC); got != "+00:00" {
+		t.Errorf("SessionTimeZone(UTC) = %q, want %q", got, "+00:00")
+	}
+	la := mustLoadLocation(t, "America/Los_Angeles")
+	if got := SessionTimeZone(la); got != "America/Los_Angeles" {
+		t.Errorf("SessionTimeZone(%v) = %q, want %q", la, got, "America/Los_Angeles")
+	}
+}
+
+func TestDBTimeDST(t *testing.T) {
+	la := mustLoadLocation(t, "America/Los_Angeles")
+	tests := []struct {
+		desc   string
+		utc    time.Time
+		loc    *time.Location
+		wantDB string
+	}{
+		{
+			desc:   "before spring forward",
+			utc:    time.Date(2026, 3, 8, 9, 59, 59, 0, time.UTC),
+			loc:    la,
+			wantDB: "2026-03-08 01:59:59",
+		},
+		{
+			desc:   "after spring forward",
+			utc:    time.Date(2026, 3, 8, 10, 0, 0, 0, time.UTC),
+			loc:    la,
+			wantDB: "2026-03-08 03:00:00",
+		},
+		{
+			desc:   "first 01:30 of fall back",
+			utc:    time.Date(2026, 11, 1, 8, 30, 0, 0, time.UTC),
+			loc:    la,
+			wantDB: "2026-11-01 01:30:00",
+		},
+		{
+			desc:   "second 01:30 of fall back",
+			utc:    time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC),
+			loc:    la,
+			wantDB: "2026-11-01 01:30:00",
+		},
+		{
+			desc:   "first 01:30 of fall back in UTC",
+			utc:    time.Date(2026, 11, 1, 8, 30, 0, 0, time.UTC),
+			loc:    time.UTC,
+			wantDB: "2026-11-01 08:30:00",
+		},
+		{
+			desc:   "second 01:30 of fall back in UTC",
+			utc:    time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC),
+			loc:    time.UTC,
+			wantDB: "2026-11-01 09:30:00",
+		},
+		{
+			desc:   "microseconds",
+			utc:    time.Date(2026, 7, 1, 12, 0, 0, 123456000, time.UTC),
+			loc:    time.UTC,
+			wantDB: "2026-07-01 12:00:00.123456",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			setDBLocation(t, tt.loc)
+			got := dbTime(tt.utc)
+			if got != tt.wantDB {
+				t.Fatalf("dbTime(%v) = %q, want %q", tt.utc, got, tt.wantDB)
+			}
+			parsed, err := parseDBTime(got)
+			if err != nil {
+				t.Fatalf("parseDBTime(%q): %v", got, err)
+			}
+			// The times repeated by the fall back are ambiguous in the zone, which is why
+			// the database defaults to UTC; Go takes the first of them.
+			if tt.loc == la && tt.utc.Equal(time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC)) {
+				if want := tt.utc.Add(-time.Hour); !parsed.Equal(want) {
+					t.Fatalf("parseDBTime(%q) = %v, want %v", got, parsed, want)
+				}
+				return
+			}
+			if !parsed.Equal(tt.utc) {
+				t.Fatalf("parseDBTime(%q) = %v, want %v", got, parsed, tt.utc)
+			}
+		})
+	}
+}
+
+func TestDBTimeOffsets(t *testing.T) {
+	setDBLocation(t, time.UTC)
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	local := time.Date(2026, 10, 15, 9, 0, 0, 0, tokyo)
+	if got, want := dbTime(local), "2026-10-15 00:00:00"; got != want {
+		t.Errorf("dbTime(%v) = %q, want %q", local, got, want)
+	}
+}
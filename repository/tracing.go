@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts the spans of the steps of the repositories which are not queries of gorm or
+// Mongo, whose clients trace their queries themselves.
+var tracer = otel.Tracer("github.com/PolygonPictures/central30-web/front/repository")
+
+// startNeo4jSpan starts the span of a Cypher query, as the Neo4j driver is not instrumented.
+func startNeo4jSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	return tracer.Start(
+		ctx,
+		"neo4j.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "neo4j"),
+			attribute.String("db.statement", query),
+		),
+	)
+}
+
+// endSpan ends span, recording err when it is not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// TranscodeBackend selects the worker generating the proxy movies of review transcodes.
+type TranscodeBackend string
+
+const (
+	TranscodeBackendNone   TranscodeBackend = "none"
+	TranscodeBackendFFmpeg TranscodeBackend = "ffmpeg"
+	TranscodeBackendHTTP   TranscodeBackend = "http"
+)
+
+func ParseTranscodeBackend(s string) (TranscodeBackend, error) {
+	switch TranscodeBackend(strings.ToLower(strings.TrimSpace(s))) {
+	case "", TranscodeBackendNone:
+		return TranscodeBackendNone, nil
+	case TranscodeBackendFFmpeg:
+		return TranscodeBackendFFmpeg, nil
+	case TranscodeBackendHTTP:
+		return TranscodeBackendHTTP, nil
+	}
+	return "", fmt.Errorf("unknown transcode backend %q", s)
+}
+
+// Transcoder runs transcode jobs asynchronously. Status returns ErrRecordNotFound for the jobs
+// the backend does not know, e.g. the jobs of a local worker lost by a restart.
+type Transcoder interface {
+	Backend() TranscodeBackend
+	Submit(ctx context.Context, job *entity.TranscodeJob) (string, error)
+	Status(ctx context.Context, jobID string) (*entity.TranscodeJobState, error)
+}
+
+// NewTranscoder returns the transcoder of the given backend, or nil when transcoding is
+// disabled.
+func NewTranscoder(backend TranscodeBackend) (Transcoder, error) {
+	switch backend {
+	case TranscodeBackendFFmpeg:
+		return newFFmpegTranscoderFromEnv()
+	case TranscodeBackendHTTP:
+		return newHTTPTranscoderFromEnv()
+	}
+	return nil, nil
+}
+
+// ffmpegTranscoder runs ffmpeg on this host, which must mount the project directories under
+// PPI_TRANSCODE_PROJECTS_ROOT. Jobs are only tracked in memory, and forgotten an hour after
+// they are done.
+type ffmpegTranscoder struct {
+	command   string
+	root      string
+	frameRate string
+	timeout   time.Duration
+	slots     chan struct{}
+
+	mu     sync.Mutex
+	jobs   map[string]*entity.TranscodeJobState
+	doneAt map[string]time.Time
+}
+
+func newFFmpegTranscoderFromEnv() (*ffmpegTranscoder, error) {
+	timeout, err := durationFromEnv("PPI_TRANSCODE_TIMEOUT", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := 2
+	if v := os.Getenv("PPI_TRANSCODE_CONCURRENCY"); v != "" {
+		concurrency, err = strconv.Atoi(v)
+		if err != nil || concurrency < 1 {
+			return nil, fmt.Errorf("invalid PPI_TRANSCODE_CONCURRENCY: %q", v)
+		}
+	}
+	t := &ffmpegTranscoder{
+		command:   os.Getenv("PPI_TRANSCODE_FFMPEG"),
+		root:      os.Getenv("PPI_TRANSCODE_PROJECTS_ROOT"),
+		frameRate: os.Getenv("PPI_TRANSCODE_FRAME_RATE"),
+		timeout:   timeout,
+		slots:     make(chan struct{}, concurrency),
+		jobs:      map[string]*entity.TranscodeJobState{},
+		doneAt:    map[string]time.Time{},
+	}
+	if t.command == "" {
+		t.command = "ffmpeg"
+	}
+	if t.root == "" {
+		t.root = "/mnt/ppip30-data01/datasync30/projects"
+	}
+	if t.frameRate == "" {
+		t.frameRate = "24"
+	}
+	return t, nil
+}
+
+func (t *ffmpegTranscoder) Backend() TranscodeBackend {
+	return TranscodeBackendFFmpeg
+}
+
+func (t *ffmpegTranscoder) Submit(_ context.Context, job *entity.TranscodeJob) (string, error) {
+	jobID := strconv.Itoa(int(job.ID))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, doneAt := range t.doneAt {
+		if time.Since(doneAt) > time.Hour {
+			delete(t.jobs, id)
+			delete(t.doneAt, id)
+		}
+	}
+	if _, ok := t.jobs[jobID]; !ok {
+		t.jobs[jobID] = &entity.TranscodeJobState{Status: entity.TranscodeQueued}
+		go t.run(jobID, job)
+	}
+	return jobID, nil
+}
+
+func (t *ffmpegTranscoder) Status(
+	_ context.Context,
+	jobID string,
+) (*entity.TranscodeJobState, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("%w: transcode job %q", entity.ErrRecordNotFound, jobID)
+	}
+	s := *state
+	return &s, nil
+}
+
+func (t *ffmpegTranscoder) setState(jobID string, state *entity.TranscodeJobState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.jobs[jobID] = state
+	if state.Status.Done() {
+		t.doneAt[jobID] = time.Now()
+	}
+}
+
+func (t *ffmpegTranscoder) run(jobID string, job *entity.TranscodeJob) {
+	t.slots <- struct{}{}
+	defer func() { <-t.slots }()
+	t.setState(jobID, &entity.TranscodeJobState{Status: entity.TranscodeRunning})
+
+	size, err := t.transcode(job)
+	if err != nil {
+		t.setState(jobID, &entity.TranscodeJobState{
+			Status: entity.TranscodeFailed,
+			Error:  err.Error(),
+		})
+		return
+	}
+	t.setState(jobID, &entity.TranscodeJobState{
+		Status: entity.TranscodeCompleted,
+		Size:   size,
+	})
+}
+
+// transcode encodes the frames to a ProRes movie, written next to the output first so that an
+// incomplete movie is never visible.
+func (t *ffmpegTranscoder) transcode(job *entity.TranscodeJob) (uint64, error) {
+	projectDir := filepath.Join(t.root, job.Project)
+	output := filepath.Join(projectDir, filepath.FromSlash(job.Output))
+	if err := os.MkdirAll(filepath.Dir(output), 0o775); err != nil {
+		return 0, err
+	}
+
+	var list bytes.Buffer
+	list.WriteString("ffconcat version 1.0\n")
+	for _, frame := range job.Frames {
+		p := filepath.Join(projectDir, filepath.FromSlash(frame))
+		fmt.Fprintf(&list, "file '%s'\n", strings.ReplaceAll(p, "'", `'\''`))
+	}
+	listFile, err := os.CreateTemp("", "transcode-*.ffconcat")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(listFile.Name())
+	if _, err := listFile.Write(list.Bytes()); err != nil {
+		listFile.Close()
+		return 0, err
+	}
+	if err := listFile.Close(); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	partial := output + ".part"
+	cmd := exec.CommandContext(
+		ctx, t.command,
+		"-y", "-hide_banner", "-loglevel", "error",
+		"-f", "concat", "-safe", "0", "-r", t.frameRate, "-i", listFile.Name(),
+		"-c:v", "prores_ks", "-profile:v", "1", "-pix_fmt", "yuv422p10le",
+		"-f", "mov", partial,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(partial)
+		return 0, fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err := os.Rename(partial, output); err != nil {
+		return 0, err
+	}
+	f, err := os.Stat(output)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(f.Size()), nil
+}
+
+// httpTranscoder delegates the jobs to a transcode service at PPI_TRANSCODE_URL, which accepts
+// jobs on POST /jobs and reports their state on GET /jobs/:id.
+type httpTranscoder struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newHTTPTranscoderFromEnv() (*httpTranscoder, error) {
+	baseURL := os.Getenv("PPI_TRANSCODE_URL")
+	if baseURL == "" {
+		return nil, errors.New("PPI_TRANSCODE_URL must be set for the http transcode backend")
+	}
+	return &httpTranscoder{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (t *httpTranscoder) Backend() TranscodeBackend {
+	return TranscodeBackendHTTP
+}
+
+func (t *httpTranscoder) Submit(ctx context.Context, job *entity.TranscodeJob) (string, error) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, t.baseURL+"/jobs", bytes.NewReader(body),
+	)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var res struct {
+		ID string `json:"id"`
+	}
+	if err := t.do(req, &res); err != nil {
+		return "", err
+	}
+	if res.ID == "" {
+		return "", fmt.Errorf("%w: transcode service returned no job ID", entity.ErrBadGateway)
+	}
+	return res.ID, nil
+}
+
+func (t *httpTranscoder) Status(
+	ctx context.Context,
+	jobID string,
+) (*entity.TranscodeJobState, error) {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet, t.baseURL+"/jobs/"+url.PathEscape(jobID), nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var state entity.TranscodeJobState
+	if err := t.do(req, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (t *httpTranscoder) do(req *http.Request, v interface{}) error {
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrBadGateway, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s %s", entity.ErrRecordNotFound, req.Method, req.URL.Path)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf(
+			"%w: %s %s: %s", entity.ErrBadGateway, req.Method, req.URL.Path, resp.Status,
+		)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// Watcher stores the subscriptions of users to assets and shots. The notifications of watchers
+// without mail address are sent to <user>@PPI_WATCHER_MAIL_DOMAIN, or not at all when it is
+// not set.
+type Watcher struct {
+	db         *gorm.DB
+	mailDomain string
+}
+
+func NewWatcher(db *gorm.DB) (*Watcher, error) {
+	if err := db.AutoMigrate(&model.Watcher{}); err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		db:         db,
+		mailDomain: os.Getenv("PPI_WATCHER_MAIL_DOMAIN"),
+	}, nil
+}
+
+func (r *Watcher) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Watcher) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *Watcher) List(
+	db *gorm.DB,
+	params *entity.ListWatchersParams,
+) ([]*entity.Watcher, error) {
+	stmt := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	)
+	if params.TargetType != nil {
+		stmt = stmt.Where("`target_type` = ?", *params.TargetType)
+	}
+	if params.Target != nil {
+		stmt = stmt.Where("`target` = ?", *params.Target)
+	}
+	if params.User != nil {
+		stmt = stmt.Where("`user` = ?", *params.User)
+	}
+	var models []*model.Watcher
+	if err := stmt.Order("`target` asc").Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.Watcher, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// ListOfUser returns the subscriptions of the user for the reasons across the projects, or in
+// the project when it is given, the oldest first.
+func (r *Watcher) ListOfUser(
+	db *gorm.DB,
+	user string,
+	project *string,
+	reasons []string,
+	limit int,
+) ([]*entity.Watcher, error) {
+	stmt := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`user` = ?", user,
+	).Where(
+		"`reason` IN ?", reasons,
+	)
+	if project != nil {
+		stmt = stmt.Where("`project` = ?", *project)
+	}
+	var models []*model.Watcher
+	if err := stmt.Order(
+		"`created_at_utc` asc",
+	).Order(
+		"`id` asc",
+	).Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.Watcher, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+// find returns the subscription of the user to the target, the active one first, or nil.
+func (r *Watcher) find(
+	tx *gorm.DB,
+	project string,
+	targetType string,
+	target string,
+	user string,
+) (*model.Watcher, error) {
+	var m model.Watcher
+	if err := tx.Where(
+		"`project` = ?", project,
+	).Where(
+		"`target_type` = ?", targetType,
+	).Where(
+		"`target` = ?", target,
+	).Where(
+		"`user` = ?", user,
+	).Order("`deleted` asc").Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Create makes the user watch the target. Watching a target again updates the mail address.
+func (r *Watcher) Create(
+	tx *gorm.DB,
+	params *entity.CreateWatcherParams,
+) (*entity.Watcher, error) {
+	now := time.Now().UTC()
+	var createdBy string
+	if params.CreatedBy != nil {
+		createdBy = *params.CreatedBy
+	}
+	m, err := r.find(tx, params.Project, params.TargetType, params.Target, params.User)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil && m.Deleted == 0 {
+		if params.MailAddress == nil {
+			return m.Entity(), nil
+		}
+		m.MailAddress = params.MailAddress
+		m.ModifiedAtUTC = now
+		m.ModifiedBy = createdBy
+		return m.Entity(), tx.Save(m).Error
+	}
+	m = &model.Watcher{
+		Project:       params.Project,
+		TargetType:    params.TargetType,
+		Target:        params.Target,
+		User:          params.User,
+		MailAddress:   params.MailAddress,
+		Reason:        entity.WatchReasonManual,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    createdBy,
+		CreatedBy:     createdBy,
+	}
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// AutoWatch makes the user watch the target for the reason, unless the user has ever watched
+// it, so that an unwatch is kept.
+func (r *Watcher) AutoWatch(
+	tx *gorm.DB,
+	project string,
+	targetType string,
+	target string,
+	user string,
+	reason string,
+) error {
+	m, err := r.find(tx, project, targetType, target, user)
+	if err != nil || m != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return tx.Create(&model.Watcher{
+		Project:       project,
+		TargetType:    targetType,
+		Target:        target,
+		User:          user,
+		Reason:        reason,
+		CreatedAtUTC:  now,
+		ModifiedAtUTC: now,
+		ModifiedBy:    user,
+		CreatedBy:     user,
+	}).Error
+}
+
+func (r *Watcher) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteWatcherParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.Watcher
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: watcher with ID %d", entity.ErrRecordNotFound, params.ID)
+	}
+	return nil
+}
+
+// MailAddresses returns the addresses of the watchers of the target to notify of an event of
+// actor, who is not notified of their own events.
+func (r *Watcher) MailAddresses(
+	db *gorm.DB,
+	project string,
+	targetType string,
+	target string,
+	actor string,
+) ([]string, error) {
+	var models []*model.Watcher
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`target_type` = ?", targetType,
+	).Where(
+		"`target` = ?", target,
+	).Where(
+		"`user` <> ?", actor,
+	).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var addresses []string
+	for _, m := range models {
+		var address string
+		if m.MailAddress != nil {
+			address = *m.MailAddress
+		} else if r.mailDomain != "" {
+			address = m.User + "@" + r.mailDomain
+		}
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// countTargetWatchers returns the number of watchers of each of the targets.
+func countTargetWatchers(
+	db *gorm.DB,
+	project string,
+	targetType string,
+	targets []string,
+) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(targets) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		Target string
+		Count  int
+	}
+	if err := db.Model(&model.Watcher{}).Select(
+		"`target`, COUNT(*) AS `count`",
+	).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`target_type` = ?", targetType,
+	).Where(
+		"`target` IN ?", targets,
+	).Group("`target`").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("countTargetWatchers: %w", err)
+	}
+	for _, row := range rows {
+		counts[row.Target] = row.Count
+	}
+	return counts, nil
+}
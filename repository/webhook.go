@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// webhookResponseLimit limits the body of an error response kept in the delivery log.
+const webhookResponseLimit = 1024
+
+// Webhook stores the webhooks of the projects and their delivery log, and posts the events to
+// them. The events are queued by NotificationOutbox.EnqueueWebhookEvent.
+type Webhook struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+func NewWebhook(db *gorm.DB) (*Webhook, error) {
+	if err := db.AutoMigrate(&model.Webhook{}, &model.WebhookDelivery{}); err != nil {
+		return nil, err
+	}
+	return &Webhook{
+		db:     db,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (r *Webhook) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *Webhook) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (r *Webhook) get(db *gorm.DB, project string, id int32) (*model.Webhook, error) {
+	var m model.Webhook
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: webhook with ID %d in %s", entity.ErrRecordNotFound, id, project,
+			)
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *Webhook) List(db *gorm.DB, params *entity.ListWebhooksParams) ([]*entity.Webhook, error) {
+	var models []*model.Webhook
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Order("`id` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.Webhook, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *Webhook) Get(db *gorm.DB, params *entity.GetWebhookParams) (*entity.Webhook, error) {
+	m, err := r.get(db, params.Project, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+// Create creates a webhook with a new secret, which is returned with it.
+func (r *Webhook) Create(tx *gorm.DB, params *entity.CreateWebhookParams) (*entity.Webhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	m := model.NewWebhook(params, secret)
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	e.Secret = secret
+	return e, nil
+}
+
+// Update changes the given fields of a webhook. Its new secret is returned when rotated.
+func (r *Webhook) Update(tx *gorm.DB, params *entity.UpdateWebhookParams) (*entity.Webhook, error) {
+	m, err := r.get(tx, params.Project, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if params.URL != nil {
+		m.URL = *params.URL
+	}
+	if params.Events != nil {
+		m.Events = params.Events
+	}
+	if params.Active != nil {
+		m.Active = *params.Active
+	}
+	if params.RotateSecret {
+		if m.Secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+	m.ModifiedAtUTC = time.Now().UTC()
+	m.ModifiedBy = params.ModifiedBy
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	e := m.Entity()
+	if params.RotateSecret {
+		e.Secret = m.Secret
+	}
+	return e, nil
+}
+
+// Delete deletes a webhook with its delivery log. Its queued events are skipped.
+func (r *Webhook) Delete(tx *gorm.DB, params *entity.DeleteWebhookParams) error {
+	m, err := r.get(tx, params.Project, params.ID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where(
+		"`webhook_id` = ?", m.ID,
+	).Delete(&model.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(m).Error
+}
+
+// ListDeliveries returns the delivery log of a webhook, latest first.
+func (r *Webhook) ListDeliveries(
+	db *gorm.DB,
+	params *entity.ListWebhookDeliveriesParams,
+) ([]*entity.WebhookDelivery, uint, error) {
+	if _, err := r.get(db, params.Project, params.ID); err != nil {
+		return nil, 0, err
+	}
+	stmt := db.Model(&model.WebhookDelivery{}).Where("`webhook_id` = ?", params.ID)
+	if params.Failed != nil {
+		if *params.Failed {
+			stmt = stmt.Where("`error` <> ''")
+		} else {
+			stmt = stmt.Where("`error` = ''")
+		}
+	}
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.WebhookDelivery
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.WebhookDelivery, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, uint(total), nil
+}
+
+// signWebhook returns the signature of a body posted at the Unix time ts.
+func signWebhook(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return entity.WebhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts an event to its webhook and records the attempt in the delivery log. A response
+// other than 2xx is an error, so that the event is retried. The events of webhooks which were
+// deleted, deactivated or unsubscribed since they were queued are skipped.
+func (r *Webhook) Send(
+	ctx context.Context,
+	n *entity.WebhookNotification,
+	attempt uint32,
+) error {
+	db := r.WithContext(ctx)
+	m, err := r.get(db, n.Project, n.WebhookID)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return fmt.Errorf("%w: webhook %d was deleted", entity.ErrNotificationSkipped, n.WebhookID)
+	} else if err != nil {
+		return err
+	}
+	if !m.Active || !m.Events.Has(n.Event) {
+		return fmt.Errorf(
+			"%w: webhook %d does not receive %s anymore",
+			entity.ErrNotificationSkipped, n.WebhookID, n.Event,
+		)
+	}
+
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	start := time.Now().UTC()
+	ts := start.Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set(entity.WebhookEventHeader, string(n.Event))
+	req.Header.Set(entity.WebhookDeliveryHeader, n.DeliveryID)
+	req.Header.Set(entity.WebhookTimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(entity.WebhookSignatureHeader, signWebhook(m.Secret, ts, body))
+
+	delivery := &model.WebhookDelivery{
+		WebhookID:      m.ID,
+		DeliveryID:     n.DeliveryID,
+		Event:          string(n.Event),
+		Attempt:        attempt,
+		DeliveredAtUTC: start,
+	}
+	var sendErr error
+	resp, err := r.client.Do(req)
+	if err != nil {
+		sendErr = err
+	} else {
+		defer resp.Body.Close()
+		statusCode := resp.StatusCode
+		delivery.StatusCode = &statusCode
+		if statusCode < 200 || statusCode > 299 {
+			b, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+			sendErr = fmt.Errorf("webhook %d responded %d: %s", m.ID, statusCode, b)
+		}
+	}
+	delivery.DurationMS = time.Since(start).Milliseconds()
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+	if err := db.Create(delivery).Error; err != nil {
+		return err
+	}
+	return sendErr
+}
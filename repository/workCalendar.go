@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+type WorkCalendar struct {
+	db *gorm.DB
+}
+
+func NewWorkCalendar(db *gorm.DB) (*WorkCalendar, error) {
+	if err := db.AutoMigrate(&model.WorkCalendar{}); err != nil {
+		return nil, err
+	}
+	return &WorkCalendar{
+		db: db,
+	}, nil
+}
+
+func (r *WorkCalendar) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+func (r *WorkCalendar) TransactionWithContext(
+	ctx context.Context,
+	fc func(tx *gorm.DB) error,
+	opts ...*sql.TxOptions,
+) error {
+	db := r.WithContext(ctx)
+	return db.Transaction(fc, opts...)
+}
+
+func (r *WorkCalendar) List(db *gorm.DB) ([]*entity.WorkCalendar, error) {
+	var models []*model.WorkCalendar
+	if err := db.Where(
+		"`deleted` = ?", 0,
+	).Order("`studio` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.WorkCalendar, len(models))
+	for i, m := range models {
+		e, err := m.Entity()
+		if err != nil {
+			return nil, err
+		}
+		entities[i] = e
+	}
+	return entities, nil
+}
+
+func (r *WorkCalendar) Get(
+	db *gorm.DB,
+	params *entity.GetWorkCalendarParams,
+) (*entity.WorkCalendar, error) {
+	var m model.WorkCalendar
+	err := db.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`studio` = ?", params.Studio,
+	).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf(
+			"%w: work calendar of studio %q not found", entity.ErrRecordNotFound, params.Studio,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.Entity()
+}
+
+// Clocks returns the business clock of every studio with a work calendar.
+func (r *WorkCalendar) Clocks(db *gorm.DB) (map[string]*entity.BusinessClock, error) {
+	calendars, err := r.List(db)
+	if err != nil {
+		return nil, err
+	}
+	clocks := make(map[string]*entity.BusinessClock, len(calendars))
+	for _, c := range calendars {
+		bc, err := entity.NewBusinessClock(c)
+		if err != nil {
+			return nil, fmt.Errorf("work calendar of studio %q: %w", c.Studio, err)
+		}
+		clocks[c.Studio] = bc
+	}
+	return clocks, nil
+}
+
+// Update creates the work calendar of the studio or replaces it.
+func (r *WorkCalendar) Update(
+	tx *gorm.DB,
+	params *entity.UpdateWorkCalendarParams,
+) (*entity.WorkCalendar, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	weekends := params.Weekends
+	if weekends == nil {
+		weekends = []int{}
+	}
+	holidays := params.Holidays
+	if holidays == nil {
+		holidays = []string{}
+	}
+	weekendsJSON, err := json.Marshal(weekends)
+	if err != nil {
+		return nil, err
+	}
+	holidaysJSON, err := json.Marshal(holidays)
+	if err != nil {
+		return nil, err
+	}
+	shiftsJSON, err := json.Marshal(params.Shifts)
+	if err != nil {
+		return nil, err
+	}
+
+	var m model.WorkCalendar
+	err = tx.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`studio` = ?", params.Studio,
+	).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.WorkCalendar{
+			Studio:        params.Studio,
+			TimeZone:      params.TimeZone,
+			Weekends:      weekendsJSON,
+			Holidays:      holidaysJSON,
+			Shifts:        shiftsJSON,
+			CreatedAtUTC:  now,
+			ModifiedAtUTC: now,
+			ModifiedBy:    modifiedBy,
+			CreatedBy:     modifiedBy,
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return nil, err
+		}
+		return m.Entity()
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.TimeZone = params.TimeZone
+	m.Weekends = weekendsJSON
+	m.Holidays = holidaysJSON
+	m.Shifts = shiftsJSON
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	if err := tx.Save(&m).Error; err != nil {
+		return nil, err
+	}
+	return m.Entity()
+}
+
+func (r *WorkCalendar) Delete(
+	tx *gorm.DB,
+	params *entity.DeleteWorkCalendarParams,
+) error {
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m *model.WorkCalendar
+	result := tx.Model(m).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`studio` = ?", params.Studio,
+	).Updates(map[string]interface{}{
+		"deleted":         gorm.Expr("id"),
+		"modified_at_utc": time.Now().UTC(),
+		"modified_by":     modifiedBy,
+	})
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(
+			"%w: work calendar of studio %q not found", entity.ErrRecordNotFound, params.Studio,
+		)
+	}
+	return nil
+}
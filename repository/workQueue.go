@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"gorm.io/gorm"
+)
+
+// WorkQueue reads the reviews awaiting the work of their artists from t_review_info.
+type WorkQueue struct {
+	db *gorm.DB
+}
+
+func NewWorkQueue(db *gorm.DB) *WorkQueue {
+	return &WorkQueue{
+		db: db,
+	}
+}
+
+func (r *WorkQueue) WithContext(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// workQueueRow is a review of a work item.
+type workQueueRow struct {
+	ID                         int32
+	Project                    string
+	Root                       string
+	Groups                     model.Groups
+	Relation                   string
+	Phase                      string
+	Take                       string
+	ApprovalStatus             string
+	ApprovalStatusUpdatedAtUtc time.Time
+	WorkStatus                 string
+	SubmittedAtUtc             time.Time
+	ManifestDueAtUTC           *time.Time `gorm:"column:manifest_due_at_utc"`
+}
+
+const workQueueColumns = "ri.id, ri.project, ri.root, ri.groups, ri.relation, ri.phase, " +
+	"ri.take, ri.approval_status, ri.approval_status_updated_at_utc, ri.work_status, " +
+	"ri.submitted_at_utc, ri.manifest_due_at_utc"
+
+// item returns the work item of the review, or nil when it is not of an asset or shot.
+func (row *workQueueRow) item(kind string, since time.Time) *entity.WorkItem {
+	targetType, target, ok := entity.WatchTargetOfReview(&entity.ReviewInfo{
+		Root:     row.Root,
+		Groups:   row.Groups,
+		Relation: row.Relation,
+	})
+	if !ok {
+		return nil
+	}
+	id := row.ID
+	return &entity.WorkItem{
+		Kind:           kind,
+		Project:        row.Project,
+		TargetType:     targetType,
+		Target:         target,
+		Phase:          &row.Phase,
+		Take:           &row.Take,
+		ApprovalStatus: &row.ApprovalStatus,
+		WorkStatus:     &row.WorkStatus,
+		ReviewInfoID:   &id,
+		DueAtUTC:       row.ManifestDueAtUTC,
+		SinceUTC:       since,
+	}
+}
+
+// ListManifestPending returns the reviews submitted by the user whose AllFiles manifest has not
+// been uploaded yet, the earliest due first.
+func (r *WorkQueue) ListManifestPending(
+	db *gorm.DB,
+	user string,
+	project *string,
+	limit int,
+) ([]*entity.WorkItem, error) {
+	stmt := db.Table("t_review_info AS ri").Select(workQueueColumns).Where(
+		"ri.submitted_user = ?", user,
+	).Where(
+		"ri.manifest_pending = ?", true,
+	).Where(
+		"ri.deleted = ?", 0,
+	)
+	if project != nil {
+		stmt = stmt.Where("ri.project = ?", *project)
+	}
+	var rows []*workQueueRow
+	if err := stmt.Order(
+		"ri.manifest_due_at_utc asc",
+	).Order(
+		"ri.id asc",
+	).Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	var items []*entity.WorkItem
+	for _, row := range rows {
+		if item := row.item(entity.WorkItemManifestPending, row.SubmittedAtUtc); item != nil {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// ListRetakes returns the latest reviews of the phases of the assets and shots the user
+// submitted reviews of, when they were sent back to retake, the longest waiting first.
+func (r *WorkQueue) ListRetakes(
+	db *gorm.DB,
+	user string,
+	project *string,
+	limit int,
+) ([]*entity.WorkItem, error) {
+	samePhase := "n.project = ri.project AND n.root = ri.root AND n.group_1 = ri.group_1 AND " +
+		"n.groups = ri.groups AND n.relation = ri.relation AND n.phase = ri.phase"
+	stmt := db.Table("t_review_info AS ri").Select(workQueueColumns).Where(
+		"ri.root IN ?", []string{"assets", "shots"},
+	).Where(
+		"ri.deleted = ?", 0,
+	).Where(
+		"ri.approval_status = ?", entity.RetakeApprovalStatus,
+	).Where(
+		"EXISTS (?)", db.Table("t_review_info AS n").Select("1").Where(samePhase).Where(
+			"n.deleted = ?", 0,
+		).Where(
+			"n.submitted_user = ?", user,
+		),
+	).Where(
+		"NOT EXISTS (?)", db.Table("t_review_info AS n").Select("1").Where(samePhase).Where(
+			"n.deleted = ?", 0,
+		).Where(
+			"(n.submitted_at_utc > ri.submitted_at_utc OR "+
+				"n.submitted_at_utc = ri.submitted_at_utc AND n.id > ri.id)",
+		),
+	)
+	if project != nil {
+		stmt = stmt.Where("ri.project = ?", *project)
+	}
+	var rows []*workQueueRow
+	if err := stmt.Order(
+		"ri.approval_status_updated_at_utc asc",
+	).Order(
+		"ri.id asc",
+	).Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	var items []*entity.WorkItem
+	for _, row := range rows {
+		if item := row.item(
+			entity.WorkItemRetake, row.ApprovalStatusUpdatedAtUtc,
+		); item != nil {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
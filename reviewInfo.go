@@ -13,6 +13,42 @@
 	* - 29-10-2025 - SanjayK PSI - Implemented dynamic filtering and sorting for latest submissions.
 	* - 17-11-2025 - SanjayK PSI - Added phase-aware status filtering and sorting.
 	* - 22-11-2025 - SanjayK PSI - Fixed bugs related to phase-specific filtering and sorting.
+	* - 15-10-2026 - Added latest take and official revision badge data to the asset pivot.
+	* - 15-10-2026 - Added review intent filtering with per-project default exclusions.
+	* - 15-10-2026 - Added configurable ULID generation and lookup for new review information.
+	* - 15-10-2026 - Added approval gate settings and upstream approval lookup.
+	* - 15-10-2026 - Added SLA states to the asset pivot.
+	* - 15-10-2026 - Added custom metadata filtering and asset metadata to the asset pivot.
+	* - 15-10-2026 - Added tag filters and tags to review listings and the asset pivot.
+	* - 15-10-2026 - Restricted the asset pivot phase columns to the project's phase template.
+	* - 15-10-2026 - Added unique tiebreakers to paginated orderings.
+	* - 15-10-2026 - Added registration of transcoded proxies as review data.
+	* - 15-10-2026 - Added audited corrections of the take path, duration and review target.
+	* - 15-10-2026 - Added watcher counts to the asset pivot.
+	* - 15-10-2026 - Added cursor pagination to latest submissions and the asset pivot list view.
+	* - 15-10-2026 - Added indexed take numbers and sorted latest submissions by take with them.
+	* - 15-10-2026 - Added per phase status counts of the asset pivot.
+	* - 15-10-2026 - Generated the asset pivot phase columns from the project's phase template.
+	* - 15-10-2026 - Cached the asset pivot and latest submission counts per project.
+	* - 15-10-2026 - Maintained the latest reviews on write and read the pivot and counts from them.
+	* - 15-10-2026 - Paginated the grouped view of the asset pivot in SQL.
+	* - 15-10-2026 - Added group path prefix filtering to review listings.
+	* - 15-10-2026 - Added per phase status filters to the asset pivot.
+	* - 15-10-2026 - Added submitted and modified date range filters to review listings and the asset pivot.
+	* - 15-10-2026 - Added the per phase rollup of the shots of a sequence.
+	* - 15-10-2026 - Added multi-key sorting to the asset pivot.
+	* - 15-10-2026 - Read and compared datetimes in the configured database time zone.
+	* - 15-10-2026 - Added the listing of the reviews of a take.
+	* - 15-10-2026 - Added the latest approved phase values to the asset pivot.
+	* - 15-10-2026 - Added reviews created before their AllFiles manifest and their expiry.
+	* - 15-10-2026 - Added the link of reviews to publish transactions and their reverse lookup.
+	* - 15-10-2026 - Skipped the group categories of the asset pivot when they are missing.
+	* - 15-10-2026 - Added the single asset filter of the asset pivot and publish transaction lookup.
+	* - 15-10-2026 - Added the restore, listing and purge of deleted review information.
+	* - 15-10-2026 - Rejected the updates of review information changed since their precondition.
+	* - 15-10-2026 - Timed the stages of the asset pivot for the debug timing breakdown.
+	* - 15-10-2026 - Read the statuses through the legacy status mappings of the project.
+	* - 15-10-2026 - Traced the query cache lookups and the group category check of the asset pivot.
 
 	Functions:
 	* - List: Lists review information based on provided parameters.
@@ -20,64 +56,153 @@
 	* - Create: Creates a new review information record.
 	* - Update: Updates an existing review information record.
 	* - Delete: Marks a review information record as deleted.
+	* - Restore: Undoes the deletion of a review information record.
+	* - ListDeleted: Lists the deleted review information records of a project.
+	* - Purge: Removes for good the records deleted before the retention window, with their logs.
+	* - UpdateManifest: Uploads the pending AllFiles manifest of a review information record.
+	* - ExpireManifests: Deletes the records whose pending manifest was not uploaded in time.
+	* - refreshLatest: Refreshes the latest reviews of the asset or shot of a review.
+	* - RebuildLatest: Rebuilds the latest reviews of a project from its reviews.
+	* - AddReviewData: Appends a content to the review data of a review information record.
+	* - correct: Applies the corrections of an update and records them in the audit log.
+	* - ListAuditLogs: Lists the audit log of the corrections of a review information record.
+	* - checkPublishTransaction: Checks the publish transaction linked to a new record.
+	* - ListPublishTransactionReviewInfos: Lists the records linked to a publish transaction.
+	* - GetPublishTransaction: Retrieves the publish transaction records are linked to.
+	* - BackfillUIDs: Assigns ULIDs to existing review information records.
+	* - BackfillTakeNumbers: Sets the take numbers of existing review information records.
+	* - GetIntentSetting: Retrieves the intents hidden by default for a project.
+	* - UpdateIntentSetting: Creates or updates the intents hidden by default for a project.
+	* - ListApprovalGates: Lists the approval gates configured for a project.
+	* - UpdateApprovalGate: Creates or updates the approval gate of a project's phase.
+	* - GetApprovalGateMode: Resolves the approval gate mode applied to a project's phase.
+	* - FillUpstreamApprovals: Fills the latest approval status of upstream dependencies.
 	* - ListAssets: Lists unique assets based on review information.
 	* - ListShotReviewInfos: Lists review information for a specific shot.
+	* - ListTakeReviewInfos: Lists the review information of a take of a phase of an asset.
 	* - ListAssetReviewInfos: Lists review information for a specific asset.
 	* - CountLatestSubmissions: Counts latest submissions with dynamic filtering.
 	* - ListLatestSubmissionsDynamic: Lists latest submissions with dynamic filtering and sorting.
 	* - buildPhaseAwareStatusWhere: Constructs a WHERE clause for phase-aware status filtering.
 	* - buildOrderClause: Constructs an ORDER BY clause based on sorting parameters.
+	* - sortClause: Constructs the sort keys of an ORDER BY clause from sorting parameters.
+	* - sortKeys: Resolves the sort keys of latest submissions from sorting parameters.
+	* - latestSubmissionOrderKeys: Resolves all the sort keys of latest submissions.
+	* - keysetOrder: Constructs an ORDER BY clause from sort keys.
+	* - keysetAfter: Constructs the condition of the rows following a cursor.
+	* - encodeAssetCursor: Encodes the opaque cursor of the next page of assets.
+	* - decodeAssetCursor: Decodes a cursor and checks it was made for the sort.
+	* - assetTiebreaker: Constructs the unique final sort keys of rows per asset.
+	* - pivotOrderKeys: Resolves the sort keys of pivot rows.
+	* - pivotCursorValues: Extracts the sort key values of a pivot row for its cursor.
+	* - pivotColumnValue: Extracts the value of a sort column of a pivot row.
 	* - ListAssetsPivot: Lists pivoted assets with filtering and sorting options.
+	* - pivotCategoriesAvailable: Tells whether the pivoted assets have group category data.
+	* - pivotCategorySelect: Constructs the group category columns of the pivot query.
+	* - ListAssetsPivotGroups: Lists a page of pivoted assets in the order of the grouped view.
+	* - attachPivotDetails: Fills the badges, states, metadata, tags and watchers of pivot rows.
+	* - pivotGroupOrder: Constructs the ORDER BY of pivot rows in the grouped view.
+	* - cachedQuery: Reads the result of a query from the query cache or caches it.
+	* - invalidateQueryCache: Drops the cached query results of a project.
+	* - SummarizeAssetsPivot: Counts pivoted assets per phase and status with the same filters.
+	* - SequenceRollup: Counts the shots of a sequence per phase and status of their latest review.
+	* - rollupPercent: Computes a percentage of the shots of a sequence.
+	* - wherePivotFilters: Applies the status and official filters to pivot rows.
+	* - checkPhaseStatusFilters: Checks the per phase status filters are on included phases.
+	* - checkPivotPhaseValue: Checks the phase value of the pivot is known.
+	* - pivotSubmittedCondition: Filters pivot rows by submission date over the included phases.
+	* - attachOfficialRevisions: Fills official revision badge data into pivot rows.
+	* - attachSLAStates: Fills the SLA state of each phase into pivot rows.
+	* - whereMetadata: Filters records by custom field values stored in a JSON column.
+	* - whereGroupPrefix: Filters records by a prefix of their group path.
+	* - attachAssetMetadata: Fills custom asset field values into pivot rows.
+	* - attachReviewTags: Fills the tags of review information records.
+	* - attachAssetTags: Fills the tags of assets into pivot rows.
+	* - attachAssetWatchers: Fills the number of watchers of assets into pivot rows.
+	* - includedPivotPhases: Resolves the pivot phase columns included by a phase template.
+	* - pivotPhaseSelect: Constructs the phase columns of the pivot query.
+	* - pivotOrderColumn: Resolves the submission column pivot rows are sorted on.
+	* - pivotSortKeys: Resolves the sort keys of pivot rows from a single or multi-key sort.
+	* - pivotSortName: Formats the sort of pivot rows returned with results and cursors.
+	* - IsPivotPhaseSortKey: Tells whether a sort key sorts pivot rows on a phase column.
+	* - pivotStatusCondition: Filters pivot rows by status over the included phases.
+	* - readPivotPhases: Reads the columns of each phase of pivot rows.
+	* - fillClassicPhases: Copies the classic phases of pivot rows into their fixed fields.
 
 	────────────────────────────────────────────────────────────────────────── */
 
 package repository
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
 	"github.com/PolygonPictures/central30-web/front/repository/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ReviewInfo caches the results of the asset pivot and the latest submission counts in
+// cache, when not nil. They are invalidated by Create, Update and Delete, and expire after
+// the TTL of the cache otherwise. The manifests of the reviews created with a pending manifest
+// are due within manifestTTL, PPI_REVIEW_MANIFEST_TTL.
 type ReviewInfo struct {
-	db *gorm.DB
+	db          *gorm.DB
+	idGen       IDGenerator
+	cache       QueryCache
+	manifestTTL time.Duration
 }
 
-// buildAssetPivotQuery constructs the base pivot query for ListAssetsPivot.
-func (r *ReviewInfo) buildAssetPivotQuery(db *gorm.DB, p ListAssetsPivotParams) *gorm.DB {
-	sub := db.Model(&model.ReviewInfo{}).
+// defaultReviewManifestTTL is how long the AllFiles manifest of a review may be pending.
+const defaultReviewManifestTTL = time.Hour
+
+// buildAssetPivotQuery constructs the base pivot query for ListAssetsPivot, with the columns
+// of the phases included by p.Phases. excludedIntents is only applied when no explicit intent
+// filter is given. The query reads the latest reviews per intent when latest is true, instead
+// of all the reviews. The latest reviews are not read for the latest approved phase values,
+// which may be older than the latest reviews. The group category columns are NULL unless
+// categories is true. The statuses are read in the current vocabulary of vocab.
+func (r *ReviewInfo) buildAssetPivotQuery(
+	db *gorm.DB,
+	p ListAssetsPivotParams,
+	excludedIntents []string,
+	latest bool,
+	categories bool,
+	vocab statusVocabulary,
+) *gorm.DB {
+	approvedOnly := p.PhaseValue == PivotPhaseValueLatestApproved
+	latest = latest && !approvedOnly
+	var table interface{} = &model.ReviewInfo{}
+	tableName := "t_review_info"
+	if latest {
+		table = &model.ReviewLatest{}
+		tableName = "t_review_latest"
+	}
+	sub := db.Model(table).
 		Select(`
 			project,
 			root,
 			group_1,
 			relation,
-			MAX(CASE WHEN phase = 'MDL' THEN work_status END) AS mdl_work_status,
-			MAX(CASE WHEN phase = 'MDL' THEN approval_status END) AS mdl_approval_status,
-			MAX(CASE WHEN phase = 'MDL' THEN submitted_at_utc END) AS mdl_submitted_at_utc,
-			MAX(CASE WHEN phase = 'RIG' THEN work_status END) AS rig_work_status,
-			MAX(CASE WHEN phase = 'RIG' THEN approval_status END) AS rig_approval_status,
-			MAX(CASE WHEN phase = 'RIG' THEN submitted_at_utc END) AS rig_submitted_at_utc,
-			MAX(CASE WHEN phase = 'BLD' THEN work_status END) AS bld_work_status,
-			MAX(CASE WHEN phase = 'BLD' THEN approval_status END) AS bld_approval_status,
-			MAX(CASE WHEN phase = 'BLD' THEN submitted_at_utc END) AS bld_submitted_at_utc,
-			MAX(CASE WHEN phase = 'DSN' THEN work_status END) AS dsn_work_status,
-			MAX(CASE WHEN phase = 'DSN' THEN approval_status END) AS dsn_approval_status,
-			MAX(CASE WHEN phase = 'DSN' THEN submitted_at_utc END) AS dsn_submitted_at_utc,
-			MAX(CASE WHEN phase = 'LDV' THEN work_status END) AS ldv_work_status,
-			MAX(CASE WHEN phase = 'LDV' THEN approval_status END) AS ldv_approval_status,
-			MAX(CASE WHEN phase = 'LDV' THEN submitted_at_utc END) AS ldv_submitted_at_utc,
-			MAX(leaf_group_name) AS leaf_group_name,
-			MAX(group_category_path) AS group_category_path,
-			MAX(top_group_node) AS top_group_node
+			`+pivotPhaseSelect(includedPivotPhases(p.Phases), approvedOnly, vocab)+`
+			MAX(modified_at_utc) AS modified_at_utc,
+			`+pivotCategorySelect(categories)+`
 		`).
 		Where("project = ?", p.Project).
 		Where("root = ?", func() string {
@@ -85,14 +210,89 @@ func (r *ReviewInfo) buildAssetPivotQuery(db *gorm.DB, p ListAssetsPivotParams)
 				return "assets"
 			}
 			return p.Root
-		}()).
-		Where("deleted = ?", 0).
-		Group("project, root, group_1, relation")
+		}())
+	if !latest {
+		sub = sub.Where("deleted = ?", 0)
+	}
+
+	if p.AssetNameKey != "" {
+		sub = sub.Where("LOWER(group_1) LIKE ?", strings.ToLower(p.AssetNameKey)+"%")
+	}
+	if p.Asset != "" {
+		sub = sub.Where("group_1 = ?", p.Asset)
+	}
+	if p.Relation != "" {
+		sub = sub.Where("relation = ?", p.Relation)
+	}
+
+	if len(p.Intents) > 0 {
+		sub = sub.Where("intent IN ?", p.Intents)
+	} else if len(excludedIntents) > 0 {
+		sub = sub.Where("intent NOT IN ?", excludedIntents)
+	}
+
+	if len(p.Metadata) > 0 {
+		am := db.Table("t_asset_metadata AS am").
+			Select("1").
+			Where("am.project = " + tableName + ".project").
+			Where("am.asset = " + tableName + ".group_1").
+			Where("am.relation = " + tableName + ".relation")
+		sub = sub.Where("EXISTS (?)", whereMetadata(am, "am.metadata", p.Metadata))
+	}
+
+	sub = whereTagged(
+		sub, db, p.Project, entity.TagTargetAsset,
+		"CONCAT("+tableName+".group_1, '/', "+tableName+".relation)", p.Tags,
+	)
 
-	return sub
+	return sub.Group("project, root, group_1, relation")
 }
 
-func NewReviewInfo(db *gorm.DB) (*ReviewInfo, error) {
+// pivotCategorySelect returns the group category columns of the pivot query, NULL when the
+// categories are not available so that the rows are grouped as Unassigned.
+func pivotCategorySelect(categories bool) string {
+	if !categories {
+		return `NULL AS leaf_group_name,
+			NULL AS group_category_path,
+			NULL AS top_group_node`
+	}
+	return `MAX(leaf_group_name) AS leaf_group_name,
+			MAX(group_category_path) AS group_category_path,
+			MAX(top_group_node) AS top_group_node`
+}
+
+// pivotCategoriesAvailable tells whether the assets of the pivot have group category data:
+// the category columns exist, which are not created on fresh databases, and are set for some
+// asset of the project. The pivot reads the categories only when they are available.
+func pivotCategoriesAvailable(db *gorm.DB, p ListAssetsPivotParams, latest bool) (bool, error) {
+	ctx, span := tracer.Start(db.Statement.Context, "pivot.group_categories")
+	defer span.End()
+	db = db.WithContext(ctx)
+	if !hasCategoryColumns(db, "t_review_info") {
+		return false, nil
+	}
+	root := p.Root
+	if root == "" {
+		root = "assets"
+	}
+	q := db.Model(&model.ReviewLatest{})
+	if !latest || p.PhaseValue == PivotPhaseValueLatestApproved {
+		q = db.Model(&model.ReviewInfo{}).Where("deleted = ?", 0)
+	}
+	var found []int
+	if err := q.Select("1").Where(
+		"project = ?", p.Project,
+	).Where(
+		"root = ?", root,
+	).Where(
+		"top_group_node IS NOT NULL AND top_group_node <> ''",
+	).Limit(1).Scan(&found).Error; err != nil {
+		return false, err
+	}
+	return len(found) != 0, nil
+}
+
+func NewReviewInfo(db *gorm.DB, idGen IDGenerator, cache QueryCache) (*ReviewInfo, error) {
 	info := model.ReviewInfo{}
 
 	//Specification change: https:jira.ppi.co.jp/browse/POTOO-2406
@@ -103,12 +303,28 @@ func NewReviewInfo(db *gorm.DB) (*ReviewInfo, error) {
 		}
 	}
 
-	if err := db.AutoMigrate(&info); err != nil {
+	if err := db.AutoMigrate(
+		&info,
+		&model.ReviewIntentSetting{},
+		&model.ReviewApprovalGate{},
+		&model.ReviewInfoAuditLog{},
+		&model.ReviewLatest{},
+		&model.ReviewLatestState{},
+		// the statuses are read through the legacy status mappings
+		&model.StatusMapping{},
+	); err != nil {
+		return nil, err
+	}
+	manifestTTL, err := durationFromEnv("PPI_REVIEW_MANIFEST_TTL", defaultReviewManifestTTL)
+	if err != nil {
 		return nil, err
 	}
 
 	return &ReviewInfo{
-		db: db,
+		db:          db,
+		idGen:       idGen,
+		cache:       cache,
+		manifestTTL: manifestTTL,
 	}, nil
 }
 
@@ -149,6 +365,13 @@ func (r *ReviewInfo) List(
 	for i, g := range params.Group {
 		stmt = stmt.Where(fmt.Sprintf("`groups`->\"$[%d]\" = ?", i), g)
 	}
+	if params.GroupPrefix != nil {
+		var err error
+		stmt, err = whereGroupPrefix(stmt, *params.GroupPrefix)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
 	if params.Relation != nil {
 		stmt = stmt.Where("relation IN (?)", params.Relation)
 	}
@@ -161,14 +384,42 @@ func (r *ReviewInfo) List(
 	if params.Take != nil {
 		stmt = stmt.Where("`take` = ?", *params.Take)
 	}
+	if params.SubmittedFrom != nil {
+		stmt = stmt.Where("`submitted_at_utc` >= ?", params.SubmittedFrom.UTC())
+	}
+	if params.SubmittedTo != nil {
+		stmt = stmt.Where("`submitted_at_utc` < ?", params.SubmittedTo.UTC())
+	}
+	if params.ModifiedFrom != nil {
+		stmt = stmt.Where("`modified_at_utc` >= ?", params.ModifiedFrom.UTC())
+	}
+	if params.ModifiedTo != nil {
+		stmt = stmt.Where("`modified_at_utc` < ?", params.ModifiedTo.UTC())
+	}
+	if params.Intent != nil {
+		stmt = stmt.Where("`intent` IN (?)", params.Intent)
+	} else if params.ModifiedSince == nil {
+		excluded, err := r.excludedIntents(db, params.Project)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(excluded) > 0 {
+			stmt = stmt.Where("`intent` NOT IN (?)", excluded)
+		}
+	}
+	stmt = whereMetadata(stmt, "`metadata`", params.Metadata)
+	stmt = whereTagged(
+		stmt, db, params.Project, entity.TagTargetReview,
+		"CAST(`t_review_info`.`id` AS CHAR)", params.Tags,
+	)
 
-	order := "`id` desc"
+	order := reviewInfoTiebreaker
 	if params.OrderBy != nil {
 		order = *params.OrderBy
 	}
 	showDeleted := false
 	if params.ModifiedSince != nil {
-		stmt = stmt.Where("`modified_at_utc` >= ?", *params.ModifiedSince)
+		stmt = stmt.Where("`modified_at_utc` >= ?", params.ModifiedSince.UTC())
 		order = "`modified_at_utc` asc"
 		showDeleted = true
 	} else {
@@ -184,9 +435,11 @@ func (r *ReviewInfo) List(
 	var models []*model.ReviewInfo
 	perPage := params.GetPerPage()
 	offset := perPage * (params.GetPage() - 1)
-	if err := stmt.Order(
-		order,
-	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+	stmt = stmt.Order(order)
+	if order != reviewInfoTiebreaker {
+		stmt = stmt.Order(reviewInfoTiebreaker)
+	}
+	if err := stmt.Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -194,40 +447,220 @@ func (r *ReviewInfo) List(
 	for _, m := range models {
 		entities = append(entities, m.Entity(showDeleted))
 	}
+	if err := attachReviewTags(db, params.Project, entities); err != nil {
+		return nil, 0, err
+	}
 	return entities, int(total), nil
 }
 
+// attachReviewTags fills the tags of the given reviews.
+func attachReviewTags(db *gorm.DB, project string, entities []*entity.ReviewInfo) error {
+	targets := make([]string, len(entities))
+	for i, e := range entities {
+		targets[i] = strconv.Itoa(int(e.ID))
+	}
+	tags, err := listTargetTags(db, project, entity.TagTargetReview, targets)
+	if err != nil {
+		return err
+	}
+	for i, e := range entities {
+		e.Tags = tags[targets[i]]
+		if e.Tags == nil {
+			e.Tags = []string{}
+		}
+	}
+	return nil
+}
+
 func (r *ReviewInfo) Get(
 	db *gorm.DB,
 	params *entity.GetReviewParams,
 ) (*entity.ReviewInfo, error) {
-	var m model.ReviewInfo
-	if err := db.Where(
+	stmt := db.Where(
 		"`deleted` = ?", 0,
 	).Where(
 		"`project` = ?", params.Project,
-	).Where(
-		"`id` = ?", params.ID,
-	).Take(&m).Error; err != nil {
+	)
+	if params.UID != nil {
+		stmt = stmt.Where("`uid` = ?", *params.UID)
+	} else {
+		stmt = stmt.Where("`id` = ?", params.ID)
+	}
+	var m model.ReviewInfo
+	if err := stmt.Take(&m).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, entity.ErrRecordNotFound
 		}
 		return nil, err
 	}
-	return m.Entity(false), nil
+	e := m.Entity(false)
+	if err := attachReviewTags(db, params.Project, []*entity.ReviewInfo{e}); err != nil {
+		return nil, err
+	}
+	return e, nil
 }
 
 func (r *ReviewInfo) Create(
 	tx *gorm.DB,
 	params *entity.CreateReviewInfoParams,
 ) (*entity.ReviewInfo, error) {
+	if params.PublishTransactionID != nil {
+		if err := checkPublishTransaction(tx, params.Project, *params.PublishTransactionID); err != nil {
+			return nil, err
+		}
+	}
 	m := model.NewReviewInfo(params)
+	if r.idGen != nil {
+		uid := r.idGen.NewID()
+		m.UID = &uid
+	}
+	if m.ManifestPending {
+		due := m.CreatedAtUTC.Add(r.manifestTTL)
+		m.ManifestDueAtUTC = &due
+	}
 	if err := tx.Create(m).Error; err != nil {
 		return nil, err
 	}
+	if err := r.refreshLatest(tx, m); err != nil {
+		return nil, err
+	}
+	r.invalidateQueryCache(tx, params.Project)
 	return m.Entity(false), nil
 }
 
+// checkPublishTransaction checks that the publish transaction of the log ID exists in the
+// project.
+func checkPublishTransaction(db *gorm.DB, project, logID string) error {
+	var count int64
+	if err := db.Table("t_publish_transaction_info").Where(
+		"project = ?", project,
+	).Where(
+		"log_id = ?", logID,
+	).Where(
+		"deleted = ?", 0,
+	).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf(
+			"%w: publish transaction with log ID %q not found in %s",
+			entity.ErrBadRequest, logID, project,
+		)
+	}
+	return nil
+}
+
+// ListPublishTransactionReviewInfos lists the reviews linked to a publish transaction, the
+// latest first.
+func (r *ReviewInfo) ListPublishTransactionReviewInfos(
+	db *gorm.DB,
+	params *entity.PublishTransactionReviewInfoListParams,
+) ([]*entity.ReviewInfo, uint, error) {
+	stmt := db.Model(&model.ReviewInfo{}).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`publish_transaction_id` = ?", params.LogID,
+	).Where(
+		"`deleted` = ?", 0,
+	)
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []*model.ReviewInfo
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Order(
+		"`modified_at_utc` desc",
+	).Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*entity.ReviewInfo, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity(false)
+	}
+	if err := attachReviewTags(db, params.Project, entities); err != nil {
+		return nil, 0, err
+	}
+	return entities, uint(total), nil
+}
+
+// GetPublishTransaction returns the publish transaction of a log ID, as linked to reviews.
+func (r *ReviewInfo) GetPublishTransaction(
+	db *gorm.DB,
+	params *entity.GetPublishTransactionSummaryParams,
+) (*entity.PublishTransactionSummary, error) {
+	var e entity.PublishTransactionSummary
+	if err := db.Table("t_publish_transaction_info").Select(
+		"log_id, project, studio, revision_path, operation, event, `user`, computer, "+
+			"created_at_utc, created_by",
+	).Where(
+		"project = ?", params.Project,
+	).Where(
+		"log_id = ?", params.LogID,
+	).Where(
+		"deleted = ?", 0,
+	).Order("id desc").Take(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf(
+				"%w: publish transaction with log ID %q in %s",
+				entity.ErrRecordNotFound, params.LogID, params.Project,
+			)
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// BackfillUIDs assigns IDs to at most batchSize records created before the ULID mode was
+// enabled and returns the number of updated records. It does nothing in the serial mode.
+func (r *ReviewInfo) BackfillUIDs(db *gorm.DB, batchSize int) (int64, error) {
+	return backfillUIDs(db, &model.ReviewInfo{}, r.idGen, batchSize)
+}
+
+// BackfillTakeNumbers sets the take numbers of the records created before they were stored,
+// one batch at a time. The records whose take has no number are read again on every run.
+func (r *ReviewInfo) BackfillTakeNumbers(db *gorm.DB, batchSize int) (int64, error) {
+	var updated int64
+	var afterID int32
+	for {
+		var models []*model.ReviewInfo
+		if err := db.Select("id", "take").Where(
+			"`take_number` IS NULL",
+		).Where(
+			"`id` > ?", afterID,
+		).Order("`id` asc").Limit(batchSize).Find(&models).Error; err != nil {
+			return updated, err
+		}
+		for _, m := range models {
+			afterID = m.ID
+			number := model.TakeNumber(m.Take)
+			if number == nil {
+				continue
+			}
+			result := db.Model(m).Where(
+				"`take_number` IS NULL",
+			).UpdateColumn("take_number", *number)
+			if err := result.Error; err != nil {
+				return updated, err
+			}
+			if err := db.Model(&model.ReviewLatest{}).Where(
+				"`id` = ?", m.ID,
+			).UpdateColumn("take_number", *number).Error; err != nil {
+				return updated, err
+			}
+			updated += result.RowsAffected
+		}
+		if len(models) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
 func (r *ReviewInfo) Update(
 	tx *gorm.DB,
 	params *entity.UpdateReviewInfoParams,
@@ -237,8 +670,10 @@ func (r *ReviewInfo) Update(
 	if params.ModifiedBy != nil {
 		modifiedBy = *params.ModifiedBy
 	}
+	// the review is locked until the transaction ends, so that a concurrent update waits for it
+	// and then fails its precondition
 	var m model.ReviewInfo
-	if err := tx.Where(
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(
 		"`deleted` = ?", 0,
 	).Where(
 		"`project` = ?", params.Project,
@@ -250,6 +685,12 @@ func (r *ReviewInfo) Update(
 		}
 		return nil, err
 	}
+	// If-Unmodified-Since has a precision of a second
+	if params.Version != nil && *params.Version != m.Version ||
+		params.UnmodifiedSince != nil &&
+			m.ModifiedAtUTC.Truncate(time.Second).After(*params.UnmodifiedSince) {
+		return nil, &entity.StaleReviewInfoError{Current: m.Entity(false)}
+	}
 	var modified = false
 	if params.ApprovalStatus != nil {
 		m.ApprovalStatus = *params.ApprovalStatus
@@ -271,12 +712,30 @@ func (r *ReviewInfo) Update(
 		m.WorkStatusUpdatedAtUtc = now
 		modified = true
 	}
+	if params.Metadata != nil {
+		m.Metadata = model.GormJSONObject(params.Metadata)
+		modified = true
+	}
+	if params.HasCorrections() {
+		if err := r.correct(tx, &m, params); err != nil {
+			return nil, err
+		}
+		modified = true
+	}
 	if !modified {
 		return nil, errors.New("no value is given to change")
 	}
 	m.ModifiedAtUTC = now
 	m.ModifiedBy = modifiedBy
-	return m.Entity(false), tx.Save(m).Error
+	m.Version++
+	if err := tx.Save(m).Error; err != nil {
+		return nil, err
+	}
+	if err := r.refreshLatest(tx, &m); err != nil {
+		return nil, err
+	}
+	r.invalidateQueryCache(tx, params.Project)
+	return m.Entity(false), nil
 }
 
 func (r *ReviewInfo) Delete(
@@ -304,73 +763,692 @@ func (r *ReviewInfo) Delete(
 	m.Deleted = m.ID
 	m.ModifiedAtUTC = now
 	m.ModifiedBy = modifiedBy
-	return tx.Save(m).Error
+	m.Version++
+	if err := tx.Save(m).Error; err != nil {
+		return err
+	}
+	if err := r.refreshLatest(tx, &m); err != nil {
+		return err
+	}
+	r.invalidateQueryCache(tx, params.Project)
+	return nil
 }
 
-func (r *ReviewInfo) ListAssets(
-	db *gorm.DB,
-	params *entity.AssetListParams,
-) ([]*entity.Asset, int, error) {
-	stmt := db.Model(
-		&ReviewInfo{},
+// Restore undoes the deletion of a review, unless its pending manifest has expired since.
+func (r *ReviewInfo) Restore(
+	tx *gorm.DB,
+	params *entity.RestoreReviewInfoParams,
+) (*entity.ReviewInfo, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var m model.ReviewInfo
+	if err := tx.Where(
+		"`deleted` <> ?", 0,
 	).Where(
-		"deleted = ?", 0,
+		"`project` = ?", params.Project,
 	).Where(
-		"project = ?", params.Project,
+		"`id` = ?", params.ID,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	if m.ManifestPending && m.ManifestDueAtUTC != nil && !m.ManifestDueAtUTC.After(now) {
+		return nil, fmt.Errorf(
+			"%w: manifest of review info with ID %d has expired", entity.ErrBadRequest, m.ID,
+		)
+	}
+	m.Deleted = 0
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	m.Version++
+	if err := tx.Save(&m).Error; err != nil {
+		return nil, err
+	}
+	if err := r.refreshLatest(tx, &m); err != nil {
+		return nil, err
+	}
+	r.invalidateQueryCache(tx, params.Project)
+	e := m.Entity(false)
+	if err := attachReviewTags(tx, params.Project, []*entity.ReviewInfo{e}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ListDeleted returns the deleted reviews of a project, the latest deleted first. A review is
+// deleted at its modification time.
+func (r *ReviewInfo) ListDeleted(
+	db *gorm.DB,
+	params *entity.ListDeletedReviewInfosParams,
+) ([]*entity.ReviewInfo, int, error) {
+	stmt := db.Model(&model.ReviewInfo{}).Where(
+		"`deleted` <> ?", 0,
 	).Where(
-		"root = ?", "assets",
-	).Group(
-		"project",
-	).Group(
-		"root",
-	).Group(
-		"group_1",
-	).Group(
-		"relation",
+		"`project` = ?", params.Project,
 	)
-
+	if params.DeletedBefore != nil {
+		stmt = stmt.Where("`modified_at_utc` < ?", *params.DeletedBefore)
+	}
 	var total int64
 	if err := stmt.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-
-	stmt = stmt.Order(
-		"group_1",
-	).Order(
-		"relation",
-	)
-
-	var reviews []*model.ReviewInfo
+	var models []*model.ReviewInfo
 	perPage := params.GetPerPage()
 	offset := perPage * (params.GetPage() - 1)
-	if err := stmt.Select(
-		"project", "root", "group_1", "relation",
-	).Limit(perPage).Offset(offset).Find(&reviews).Error; err != nil {
+	if err := stmt.Order(
+		"`modified_at_utc` desc",
+	).Order(
+		"`id` desc",
+	).Limit(perPage).Offset(offset).Find(&models).Error; err != nil {
 		return nil, 0, err
 	}
+	entities := make([]*entity.ReviewInfo, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity(true)
+	}
+	return entities, int(total), nil
+}
 
-	assets := make([]*entity.Asset, len(reviews))
-	for i, review := range reviews {
-		assets[i] = &entity.Asset{
-			Name:     review.Group1,
-			Relation: review.Relation,
+// reviewPurgeBatchSize limits the reviews removed by each statement of a purge.
+const reviewPurgeBatchSize = 500
+
+// Purge removes for good the reviews of a project deleted more than the retention days ago,
+// with their status logs, audit logs, file hashes, transcodes, SLA breaches and tags. The
+// transcoded files are left to the storage lifecycle.
+func (r *ReviewInfo) Purge(
+	tx *gorm.DB,
+	params *entity.PurgeReviewInfosParams,
+) (*entity.PurgeReviewInfosResult, error) {
+	deletedBefore := time.Now().UTC().AddDate(0, 0, -int(params.RetentionDays))
+	purgeable := func() *gorm.DB {
+		return tx.Model(&model.ReviewInfo{}).Where(
+			"`deleted` <> ?", 0,
+		).Where(
+			"`project` = ?", params.Project,
+		).Where(
+			"`modified_at_utc` < ?", deletedBefore,
+		)
+	}
+	result := &entity.PurgeReviewInfosResult{
+		Project:       params.Project,
+		DeletedBefore: deletedBefore,
+		DryRun:        params.DryRun,
+	}
+	if params.DryRun {
+		if err := purgeable().Count(&result.Purged).Error; err != nil {
+			return nil, err
 		}
+		return result, nil
 	}
-	return assets, int(total), nil
+	for {
+		var ids []int32
+		if err := purgeable().Order(
+			"`id` asc",
+		).Limit(reviewPurgeBatchSize).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, m := range []interface{}{
+			&model.ReviewStatusLog{},
+			&model.ReviewInfoAuditLog{},
+			&model.FileHash{},
+			&model.ReviewTranscode{},
+			&model.ReviewSLABreach{},
+		} {
+			if err := tx.Where("`review_info_id` IN ?", ids).Delete(m).Error; err != nil {
+				return nil, err
+			}
+		}
+		targets := make([]string, len(ids))
+		for i, id := range ids {
+			targets[i] = strconv.Itoa(int(id))
+		}
+		if err := tx.Where(
+			"`project` = ?", params.Project,
+		).Where(
+			"`target_type` = ?", entity.TagTargetReview,
+		).Where(
+			"`target` IN ?", targets,
+		).Delete(&model.TagAssignment{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where("`id` IN ?", ids).Delete(&model.ReviewInfo{}).Error; err != nil {
+			return nil, err
+		}
+		result.Purged += int64(len(ids))
+	}
+	return result, nil
 }
 
-func (r *ReviewInfo) ListAssetReviewInfos(
-	db *gorm.DB,
-	params *entity.AssetReviewInfoListParams,
-) ([]*entity.ReviewInfo, error) {
-	stmtA := db.Select(
-		"project",
-		"root",
-		"group_1",
-		"relation",
-		"phase",
-		"MAX(modified_at_utc) AS modified_at_utc",
-	).Model(
+// UpdateManifest sets the AllFiles manifest of a review created with a pending manifest, with
+// its number and total size of files.
+func (r *ReviewInfo) UpdateManifest(
+	tx *gorm.DB,
+	params *entity.UpdateReviewManifestParams,
+) (*entity.ReviewInfo, error) {
+	var m model.ReviewInfo
+	if err := tx.Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`id` = ?", params.ID,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	if !m.ManifestPending {
+		return nil, fmt.Errorf(
+			"%w: manifest of review info with ID %d is not pending", entity.ErrBadRequest, m.ID,
+		)
+	}
+	var size uint64
+	for _, f := range params.AllFiles {
+		size += f.Size
+	}
+	m.AllFiles = params.AllFiles
+	m.NumAllFiles = uint32(len(params.AllFiles))
+	m.SizeAllFiles = size
+	m.ManifestPending = false
+	m.ManifestDueAtUTC = nil
+	m.ModifiedAtUTC = time.Now().UTC()
+	m.Version++
+	if params.ModifiedBy != nil {
+		m.ModifiedBy = *params.ModifiedBy
+	}
+	if err := tx.Save(&m).Error; err != nil {
+		return nil, err
+	}
+	if err := r.refreshLatest(tx, &m); err != nil {
+		return nil, err
+	}
+	r.invalidateQueryCache(tx, params.Project)
+	return m.Entity(false), nil
+}
+
+// ExpireManifests deletes the reviews whose pending manifest was due by now, as their publish
+// was abandoned, and returns their number.
+func (r *ReviewInfo) ExpireManifests(tx *gorm.DB, now time.Time) (int64, error) {
+	var models []*model.ReviewInfo
+	if err := tx.Where(
+		"`manifest_pending` = ?", true,
+	).Where(
+		"`manifest_due_at_utc` <= ?", now,
+	).Where(
+		"`deleted` = ?", 0,
+	).Find(&models).Error; err != nil {
+		return 0, err
+	}
+	for _, m := range models {
+		m.Deleted = m.ID
+		m.ModifiedAtUTC = now
+		m.ModifiedBy = "system"
+		m.Version++
+		if err := tx.Save(m).Error; err != nil {
+			return 0, err
+		}
+		if err := r.refreshLatest(tx, m); err != nil {
+			return 0, err
+		}
+		r.invalidateQueryCache(tx, m.Project)
+	}
+	return int64(len(models)), nil
+}
+
+// refreshLatest refreshes the latest reviews of the asset or shot of the review, in the
+// transaction changing it.
+func (r *ReviewInfo) refreshLatest(tx *gorm.DB, m *model.ReviewInfo) error {
+	if len(m.Groups) == 0 {
+		return nil
+	}
+	_, err := refreshReviewLatest(tx, m.Project, m.Root, []string{m.Groups[0]})
+	return err
+}
+
+// RebuildLatest rebuilds the latest reviews of a project from all its reviews, and records
+// the rebuild so that the asset pivot and the latest submission counts read them from then
+// on. It must be called in a transaction.
+func (r *ReviewInfo) RebuildLatest(
+	tx *gorm.DB,
+	params *entity.RebuildReviewLatestParams,
+) (*entity.ReviewLatestState, error) {
+	rows, err := refreshReviewLatest(tx, params.Project, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	state, err := saveReviewLatestState(tx, params.Project, rows, params.RebuiltBy)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateQueryCache(tx, params.Project)
+	return state, nil
+}
+
+// invalidateQueryCache drops the cached query results of a project. A query racing the
+// transaction of a change may cache its previous result, until the TTL of the cache.
+func (r *ReviewInfo) invalidateQueryCache(db *gorm.DB, project string) {
+	if r.cache != nil {
+		r.cache.Invalidate(db.Statement.Context, project)
+	}
+}
+
+// cachedQuery reads the result of a query of a project from the cache into result, unless it
+// is older than maxStaleness, or runs query to fill result and caches it. A maxStaleness of 0
+// bypasses the cache. It returns when the result was read from the database.
+func (r *ReviewInfo) cachedQuery(
+	ctx context.Context,
+	project string,
+	name string,
+	params interface{},
+	maxStaleness *time.Duration,
+	result interface{},
+	query func() error,
+) (time.Time, error) {
+	now := time.Now().UTC()
+	if r.cache == nil || (maxStaleness != nil && *maxStaleness <= 0) {
+		return now, query()
+	}
+	key, err := queryCacheKey(name, params)
+	if err != nil {
+		return now, err
+	}
+	_, span := tracer.Start(ctx, "cache.get", trace.WithAttributes(
+		attribute.String("cache.query", name),
+		attribute.String("project", project),
+	))
+	data, cachedAt, ok := r.cache.Get(ctx, project, key)
+	hit := ok && (maxStaleness == nil || now.Sub(cachedAt) <= *maxStaleness)
+	span.SetAttributes(attribute.Bool("cache.hit", hit))
+	span.End()
+	if hit {
+		if err := json.Unmarshal(data, result); err == nil {
+			return cachedAt, nil
+		}
+	}
+	if err := query(); err != nil {
+		return now, err
+	}
+	if data, err := json.Marshal(result); err == nil {
+		r.cache.Set(ctx, project, key, data)
+	}
+	return now, nil
+}
+
+// correct applies the corrections of the update to m. Only the values which differ from the
+// current ones are changed and recorded in the audit log.
+func (r *ReviewInfo) correct(
+	tx *gorm.DB,
+	m *model.ReviewInfo,
+	params *entity.UpdateReviewInfoParams,
+) error {
+	var logs []*model.ReviewInfoAuditLog
+	record := func(field string, before, after interface{}) error {
+		l, err := model.NewReviewInfoAuditLog(params, field, before, after)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, l)
+		return nil
+	}
+	if params.TakePath != nil && *params.TakePath != m.TakePath {
+		if err := record("take_path", m.TakePath, *params.TakePath); err != nil {
+			return err
+		}
+		m.TakePath = *params.TakePath
+	}
+	if params.Duration != nil && (m.Duration == nil || *m.Duration != *params.Duration) {
+		if err := record("duration", m.Duration, *params.Duration); err != nil {
+			return err
+		}
+		duration := *params.Duration
+		m.Duration = &duration
+	}
+	if params.ReviewTarget != nil {
+		before, err := json.Marshal(m.ReviewTarget)
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(params.ReviewTarget)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(before, after) {
+			if err := record("review_target", m.ReviewTarget, params.ReviewTarget); err != nil {
+				return err
+			}
+			m.ReviewTarget = model.Contents(params.ReviewTarget)
+		}
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+	return tx.Create(logs).Error
+}
+
+// ListAuditLogs returns the corrections of the review, the latest first.
+func (r *ReviewInfo) ListAuditLogs(
+	db *gorm.DB,
+	params *entity.ListReviewInfoAuditLogsParams,
+) ([]*entity.ReviewInfoAuditLog, error) {
+	var models []*model.ReviewInfoAuditLog
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`review_info_id` = ?", params.ReviewInfoID,
+	).Order("`id` desc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ReviewInfoAuditLog, len(models))
+	for i, m := range models {
+		e, err := m.Entity()
+		if err != nil {
+			return nil, err
+		}
+		entities[i] = e
+	}
+	return entities, nil
+}
+
+// AddReviewData appends the content to the review data of the review, unless a content of the
+// same path is already registered.
+func (r *ReviewInfo) AddReviewData(
+	tx *gorm.DB,
+	project string,
+	id int32,
+	content *libs.Content,
+	modifiedBy string,
+) error {
+	var m model.ReviewInfo
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(
+		"`deleted` = ?", 0,
+	).Where(
+		"`project` = ?", project,
+	).Where(
+		"`id` = ?", id,
+	).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ErrRecordNotFound
+		}
+		return err
+	}
+	for _, c := range m.ReviewData {
+		if c.Path == content.Path {
+			return nil
+		}
+	}
+	m.ReviewData = append(m.ReviewData, content)
+	m.ModifiedAtUTC = time.Now().UTC()
+	m.ModifiedBy = modifiedBy
+	if err := tx.Save(&m).Error; err != nil {
+		return err
+	}
+	return r.refreshLatest(tx, &m)
+}
+
+// excludedIntents returns the intents hidden from the project's listings unless they are
+// requested explicitly.
+func (r *ReviewInfo) excludedIntents(db *gorm.DB, project string) ([]string, error) {
+	var m model.ReviewIntentSetting
+	if err := db.Where("`project` = ?", project).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.DefaultExcludedReviewIntents, nil
+		}
+		return nil, err
+	}
+	return m.ExcludedIntents, nil
+}
+
+func (r *ReviewInfo) GetIntentSetting(
+	db *gorm.DB,
+	params *entity.GetReviewIntentSettingParams,
+) (*entity.ReviewIntentSetting, error) {
+	var m model.ReviewIntentSetting
+	if err := db.Where("`project` = ?", params.Project).Take(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &entity.ReviewIntentSetting{
+				Project:         params.Project,
+				ExcludedIntents: entity.DefaultExcludedReviewIntents,
+			}, nil
+		}
+		return nil, err
+	}
+	return m.Entity(), nil
+}
+
+func (r *ReviewInfo) UpdateIntentSetting(
+	tx *gorm.DB,
+	params *entity.UpdateReviewIntentSettingParams,
+) (*entity.ReviewIntentSetting, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	intents := model.Intents(params.ExcludedIntents)
+	if intents == nil {
+		intents = model.Intents{}
+	}
+
+	var m model.ReviewIntentSetting
+	err := tx.Where("`project` = ?", params.Project).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.ReviewIntentSetting{
+			Project:         params.Project,
+			ExcludedIntents: intents,
+			CreatedAtUTC:    now,
+			ModifiedAtUTC:   now,
+			ModifiedBy:      modifiedBy,
+			CreatedBy:       modifiedBy,
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return nil, err
+		}
+		return m.Entity(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.ExcludedIntents = intents
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	return m.Entity(), tx.Save(&m).Error
+}
+
+func (r *ReviewInfo) ListApprovalGates(
+	db *gorm.DB,
+	params *entity.ListReviewApprovalGatesParams,
+) ([]*entity.ReviewApprovalGate, error) {
+	var models []*model.ReviewApprovalGate
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Order("`phase` asc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.ReviewApprovalGate, len(models))
+	for i, m := range models {
+		entities[i] = m.Entity()
+	}
+	return entities, nil
+}
+
+func (r *ReviewInfo) UpdateApprovalGate(
+	tx *gorm.DB,
+	params *entity.UpdateReviewApprovalGateParams,
+) (*entity.ReviewApprovalGate, error) {
+	now := time.Now().UTC()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+
+	var m model.ReviewApprovalGate
+	err := tx.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`phase` = ?", params.Phase,
+	).Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		m = model.ReviewApprovalGate{
+			Project:       params.Project,
+			Phase:         params.Phase,
+			Mode:          params.Mode,
+			CreatedAtUTC:  now,
+			ModifiedAtUTC: now,
+			ModifiedBy:    modifiedBy,
+			CreatedBy:     modifiedBy,
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return nil, err
+		}
+		return m.Entity(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.Mode = params.Mode
+	m.ModifiedAtUTC = now
+	m.ModifiedBy = modifiedBy
+	return m.Entity(), tx.Save(&m).Error
+}
+
+// GetApprovalGateMode returns the mode of the phase's gate, falling back to the project-wide
+// gate and then to ApprovalGateOff.
+func (r *ReviewInfo) GetApprovalGateMode(db *gorm.DB, project, phase string) (string, error) {
+	var models []*model.ReviewApprovalGate
+	if err := db.Where(
+		"`project` = ?", project,
+	).Where(
+		"`phase` IN ?", []string{phase, entity.ApprovalGateAllPhases},
+	).Find(&models).Error; err != nil {
+		return "", err
+	}
+	mode := entity.ApprovalGateOff
+	for _, m := range models {
+		if m.Phase == phase {
+			return m.Mode, nil
+		}
+		mode = m.Mode
+	}
+	return mode, nil
+}
+
+// FillUpstreamApprovals sets the approval status and ID of the latest review of each upstream,
+// in the current vocabulary. Upstreams which have never been reviewed are left untouched. The
+// latest reviews are read with a shared lock, so that they cannot change before the
+// transaction of an approval commits.
+func (r *ReviewInfo) FillUpstreamApprovals(
+	db *gorm.DB,
+	project string,
+	upstreams []*entity.UpstreamReview,
+) error {
+	vocab, err := loadStatusVocabulary(db, project)
+	if err != nil {
+		return err
+	}
+	for _, u := range upstreams {
+		var m model.ReviewInfo
+		err := db.Clauses(clause.Locking{Strength: "SHARE"}).Select(
+			"`id`", "`approval_status`",
+		).Where(
+			"`deleted` = ?", 0,
+		).Where(
+			"`project` = ?", project,
+		).Where(
+			"`root` = ?", u.Root,
+		).Where(
+			"`group_1` = ?", u.Group,
+		).Where(
+			"`relation` = ?", u.Relation,
+		).Where(
+			"`phase` = ?", u.Phase,
+		).Order("`submitted_at_utc` desc").Order("`id` desc").Take(&m).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		id := m.ID
+		u.ReviewInfoID = &id
+		u.ApprovalStatus = vocab.normalize(entity.StatusKindApproval, m.ApprovalStatus)
+	}
+	return nil
+}
+
+func (r *ReviewInfo) ListAssets(
+	db *gorm.DB,
+	params *entity.AssetListParams,
+) ([]*entity.Asset, int, error) {
+	stmt := db.Model(
+		&ReviewInfo{},
+	).Where(
+		"deleted = ?", 0,
+	).Where(
+		"project = ?", params.Project,
+	).Where(
+		"root = ?", "assets",
+	).Group(
+		"project",
+	).Group(
+		"root",
+	).Group(
+		"group_1",
+	).Group(
+		"relation",
+	)
+
+	var total int64
+	if err := stmt.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	stmt = stmt.Order(
+		"group_1",
+	).Order(
+		"relation",
+	)
+
+	var reviews []*model.ReviewInfo
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if err := stmt.Select(
+		"project", "root", "group_1", "relation",
+	).Limit(perPage).Offset(offset).Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+
+	assets := make([]*entity.Asset, len(reviews))
+	for i, review := range reviews {
+		assets[i] = &entity.Asset{
+			Name:     review.Group1,
+			Relation: review.Relation,
+		}
+	}
+	return assets, int(total), nil
+}
+
+func (r *ReviewInfo) ListAssetReviewInfos(
+	db *gorm.DB,
+	params *entity.AssetReviewInfoListParams,
+) ([]*entity.ReviewInfo, error) {
+	stmtA := db.Select(
+		"project",
+		"root",
+		"group_1",
+		"relation",
+		"phase",
+		"MAX(modified_at_utc) AS modified_at_utc",
+	).Model(
 		&model.ReviewInfo{},
 	).Where(
 		"project = ?", params.Project,
@@ -430,6 +1508,41 @@ func (r *ReviewInfo) ListAssetReviewInfos(
 	return reviewInfos, nil
 }
 
+// ListTakeReviewInfos lists the reviews of a take of a phase of an asset, the latest first.
+func (r *ReviewInfo) ListTakeReviewInfos(
+	db *gorm.DB,
+	params *entity.TakeReviewInfoListParams,
+) ([]*entity.ReviewInfo, error) {
+	var reviews []*model.ReviewInfo
+	if err := db.Where(
+		"`project` = ?", params.Project,
+	).Where(
+		"`root` = ?", "assets",
+	).Where(
+		"`group_1` = ?", params.Asset,
+	).Where(
+		"`relation` = ?", params.Relation,
+	).Where(
+		"`phase` = ?", strings.ToUpper(params.Phase),
+	).Where(
+		"`take` = ?", params.Take,
+	).Where(
+		"`deleted` = ?", 0,
+	).Order(
+		"`modified_at_utc` desc",
+	).Order(
+		"`id` desc",
+	).Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	reviewInfos := make([]*entity.ReviewInfo, len(reviews))
+	for i, review := range reviews {
+		reviewInfos[i] = review.Entity(false)
+	}
+	return reviewInfos, nil
+}
+
 func (r *ReviewInfo) ListShotReviewInfos(
 	db *gorm.DB,
 	params *entity.ShotReviewInfoListParams,
@@ -526,6 +1639,9 @@ type LatestSubmissionRow struct {
 	Relation       string     `json:"relation"          gorm:"column:relation"`
 	Phase          string     `json:"phase"             gorm:"column:phase"`
 	SubmittedAtUTC *time.Time `json:"submitted_at_utc"  gorm:"column:submitted_at_utc"`
+	// ModifiedAtUTC and TakeNumber are only read for the cursor of the next page.
+	ModifiedAtUTC *time.Time `json:"-" gorm:"column:modified_at_utc"`
+	TakeNumber    *uint32    `json:"-" gorm:"column:take_number"`
 }
 
 // ---- Pivot result ----
@@ -540,25 +1656,72 @@ type AssetPivot struct {
 	GroupCategoryPath string `json:"group_category_path"`
 	TopGroupNode      string `json:"top_group_node"`
 
-	MDLWorkStatus     *string    `json:"mdl_work_status"`
-	MDLApprovalStatus *string    `json:"mdl_approval_status"`
-	MDLSubmittedAtUTC *time.Time `json:"mdl_submitted_at_utc"`
-
-	RIGWorkStatus     *string    `json:"rig_work_status"`
-	RIGApprovalStatus *string    `json:"rig_approval_status"`
-	RIGSubmittedAtUTC *time.Time `json:"rig_submitted_at_utc"`
-
-	BLDWorkStatus     *string    `json:"bld_work_status"`
-	BLDApprovalStatus *string    `json:"bld_approval_status"`
-	BLDSubmittedAtUTC *time.Time `json:"bld_submitted_at_utc"`
-
-	DSNWorkStatus     *string    `json:"dsn_work_status"`
-	DSNApprovalStatus *string    `json:"dsn_approval_status"`
-	DSNSubmittedAtUTC *time.Time `json:"dsn_submitted_at_utc"`
+	MDLWorkStatus       *string    `json:"mdl_work_status"`
+	MDLApprovalStatus   *string    `json:"mdl_approval_status"`
+	MDLSubmittedAtUTC   *time.Time `json:"mdl_submitted_at_utc"`
+	MDLTake             *string    `json:"mdl_take"`
+	MDLOfficialRevision *string    `json:"mdl_official_revision" gorm:"-"`
+	MDLIsOfficial       bool       `json:"mdl_is_official" gorm:"-"`
+	MDLSLAState         *string    `json:"mdl_sla_state" gorm:"-"`
+
+	RIGWorkStatus       *string    `json:"rig_work_status"`
+	RIGApprovalStatus   *string    `json:"rig_approval_status"`
+	RIGSubmittedAtUTC   *time.Time `json:"rig_submitted_at_utc"`
+	RIGTake             *string    `json:"rig_take"`
+	RIGOfficialRevision *string    `json:"rig_official_revision" gorm:"-"`
+	RIGIsOfficial       bool       `json:"rig_is_official" gorm:"-"`
+	RIGSLAState         *string    `json:"rig_sla_state" gorm:"-"`
+
+	BLDWorkStatus       *string    `json:"bld_work_status"`
+	BLDApprovalStatus   *string    `json:"bld_approval_status"`
+	BLDSubmittedAtUTC   *time.Time `json:"bld_submitted_at_utc"`
+	BLDTake             *string    `json:"bld_take"`
+	BLDOfficialRevision *string    `json:"bld_official_revision" gorm:"-"`
+	BLDIsOfficial       bool       `json:"bld_is_official" gorm:"-"`
+	BLDSLAState         *string    `json:"bld_sla_state" gorm:"-"`
+
+	DSNWorkStatus       *string    `json:"dsn_work_status"`
+	DSNApprovalStatus   *string    `json:"dsn_approval_status"`
+	DSNSubmittedAtUTC   *time.Time `json:"dsn_submitted_at_utc"`
+	DSNTake             *string    `json:"dsn_take"`
+	DSNOfficialRevision *string    `json:"dsn_official_revision" gorm:"-"`
+	DSNIsOfficial       bool       `json:"dsn_is_official" gorm:"-"`
+	DSNSLAState         *string    `json:"dsn_sla_state" gorm:"-"`
+
+	LDVWorkStatus       *string    `json:"ldv_work_status"`
+	LDVApprovalStatus   *string    `json:"ldv_approval_status"`
+	LDVSubmittedAtUTC   *time.Time `json:"ldv_submitted_at_utc"`
+	LDVTake             *string    `json:"ldv_take"`
+	LDVOfficialRevision *string    `json:"ldv_official_revision" gorm:"-"`
+	LDVIsOfficial       bool       `json:"ldv_is_official" gorm:"-"`
+	LDVSLAState         *string    `json:"ldv_sla_state" gorm:"-"`
+
+	// Phases holds the columns of every included phase, keyed by lower case phase, so that the
+	// phases of a template other than the classic ones above are returned too.
+	Phases map[string]*AssetPivotPhase `json:"phases,omitempty" gorm:"-"`
+	// PhaseColumns is the JSON object Phases are read from.
+	PhaseColumns *string `json:"-" gorm:"column:phase_columns"`
+
+	Metadata map[string]interface{} `json:"metadata,omitempty" gorm:"-"`
+	Tags     []string               `json:"tags,omitempty" gorm:"-"`
+	Watchers int                    `json:"watchers" gorm:"-"`
+
+	// GlobalSubmittedAt is the latest submission of all the phases, only read for the cursor of
+	// the next page.
+	GlobalSubmittedAt *time.Time `json:"-" gorm:"column:global_submitted_at"`
+}
 
-	LDVWorkStatus     *string    `json:"ldv_work_status"`
-	LDVApprovalStatus *string    `json:"ldv_approval_status"`
-	LDVSubmittedAtUTC *time.Time `json:"ldv_submitted_at_utc"`
+// AssetPivotPhase holds the columns of a phase of a pivot row.
+type AssetPivotPhase struct {
+	WorkStatus       *string    `json:"work_status"`
+	ApprovalStatus   *string    `json:"approval_status"`
+	SubmittedAtUTC   *time.Time `json:"submitted_at_utc"`
+	Take             *string    `json:"take"`
+	OfficialRevision *string    `json:"official_revision"`
+	IsOfficial       bool       `json:"is_official"`
+	SLAState         *string    `json:"sla_state"`
+	// PublishTransactionID is the log ID of the publish transaction of Take, when linked.
+	PublishTransactionID *string `json:"publish_transaction_id"`
 }
 
 // ---- phase row for internal pivot fetch ----
@@ -595,16 +1758,20 @@ type GroupedAssetBucket struct {
 	TotalCount   *int         `json:"total_count"`
 }
 
-func buildGlobalSubmittedAtExpr() string {
-	return `
-		GREATEST(
-			mdl_submitted_at_utc,
-			rig_submitted_at_utc,
-			bld_submitted_at_utc,
-			dsn_submitted_at_utc,
-			ldv_submitted_at_utc
-		)
-	`
+// buildGlobalSubmittedAtExpr returns the latest submission over the phase columns of the pivot.
+func buildGlobalSubmittedAtExpr(phases []string) string {
+	columns := make([]string, len(phases))
+	for i, phase := range phases {
+		columns[i] = phase + "_submitted_at_utc"
+	}
+	switch len(columns) {
+	case 0:
+		return "NULL"
+	case 1:
+		// GREATEST takes two arguments at least
+		return columns[0]
+	}
+	return "GREATEST(" + strings.Join(columns, ", ") + ")"
 }
 
 func GroupAndSortByTopNode(
@@ -682,8 +1849,12 @@ func toLowerSlice(strs []string) []string {
 	return result
 }
 
-// Helper to build status condition
-func buildStatusCondition(db *gorm.DB, approvalStatuses, workStatuses []string) *gorm.DB {
+// Helper to build status condition, on the statuses normalized by vocab
+func buildStatusCondition(
+	db *gorm.DB,
+	vocab statusVocabulary,
+	approvalStatuses, workStatuses []string,
+) *gorm.DB {
 	if len(approvalStatuses) == 0 && len(workStatuses) == 0 {
 		return db
 	}
@@ -692,12 +1863,18 @@ func buildStatusCondition(db *gorm.DB, approvalStatuses, workStatuses []string)
 	var args []interface{}
 
 	if len(approvalStatuses) > 0 {
-		conditions = append(conditions, "LOWER(approval_status) IN (?)")
+		conditions = append(
+			conditions,
+			"LOWER("+vocab.column(entity.StatusKindApproval, "approval_status")+") IN (?)",
+		)
 		args = append(args, toLowerSlice(approvalStatuses))
 	}
 
 	if len(workStatuses) > 0 {
-		conditions = append(conditions, "LOWER(work_status) IN (?)")
+		conditions = append(
+			conditions,
+			"LOWER("+vocab.column(entity.StatusKindWork, "work_status")+") IN (?)",
+		)
 		args = append(args, toLowerSlice(workStatuses))
 	}
 
@@ -709,125 +1886,234 @@ func buildStatusCondition(db *gorm.DB, approvalStatuses, workStatuses []string)
 	return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
 }
 
-// ORDER BY builder - FIXED for global sorting
-func buildOrderClause(alias, key, dir string) string {
-	dir = strings.ToUpper(strings.TrimSpace(dir))
-	if dir != "ASC" && dir != "DESC" {
-		dir = "ASC"
-	}
+// reviewInfoTiebreaker is the last ORDER BY of paginated review info queries, so that records
+// with equal sort keys keep the same order on every page.
+const reviewInfoTiebreaker = "`id` desc"
 
-	col := func(c string) string {
-		if alias == "" {
-			return c
-		}
-		return alias + "." + c
+// keysetKey is a sort key of the queries paginated by cursor. fn is applied to both the
+// column and the cursor value: "LOWER", or "ISNULL" to sort the missing values apart.
+type keysetKey struct {
+	column string
+	desc   bool
+	fn     string
+}
+
+func (k keysetKey) expr(alias string) string {
+	col := k.column
+	if alias != "" {
+		col = alias + "." + col
+	}
+	switch k.fn {
+	case "LOWER":
+		return "LOWER(" + col + ")"
+	case "ISNULL":
+		return "(" + col + " IS NULL)"
 	}
+	return col
+}
 
-	switch key {
-	case "submitted_at_utc", "modified_at_utc", "phase":
-		return col(key) + " " + dir
+func (k keysetKey) placeholder() string {
+	switch k.fn {
+	case "LOWER":
+		return "LOWER(?)"
+	case "ISNULL":
+		return "(? IS NULL)"
+	}
+	return "?"
+}
 
-	case "group1_only", "name", "group_1":
-		return fmt.Sprintf(
-			"LOWER(%s) %s, LOWER(%s) %s",
-			col("group_1"), dir,
-			col("relation"), dir,
-		)
+// keysetOrder builds the ORDER BY of the keys.
+func keysetOrder(alias string, keys []keysetKey) string {
+	terms := make([]string, len(keys))
+	for i, k := range keys {
+		dir := "ASC"
+		if k.desc {
+			dir = "DESC"
+		}
+		terms[i] = k.expr(alias) + " " + dir
+	}
+	return strings.Join(terms, ", ")
+}
 
-	case "relation_only":
-		return fmt.Sprintf(
-			"LOWER(%s) %s, LOWER(%s) %s",
-			col("relation"), dir,
-			col("group_1"), dir,
-		)
+// keysetAfter builds the condition of the rows following the cursor values, keyed by column,
+// in the order of the keys. The equal keys are compared null-safely, so that the rows missing
+// a value are positioned by their ISNULL key and the keys after it.
+func keysetAfter(alias string, keys []keysetKey, values map[string]interface{}) (
+	string,
+	[]interface{},
+) {
+	var conditions []string
+	var args []interface{}
+	for i, k := range keys {
+		v := values[k.column]
+		if v == nil && k.fn != "ISNULL" {
+			continue
+		}
+		terms := make([]string, 0, i+1)
+		for _, prev := range keys[:i] {
+			terms = append(terms, prev.expr(alias)+" <=> "+prev.placeholder())
+			args = append(args, values[prev.column])
+		}
+		op := ">"
+		if k.desc {
+			op = "<"
+		}
+		terms = append(terms, k.expr(alias)+" "+op+" "+k.placeholder())
+		args = append(args, v)
+		conditions = append(conditions, "("+strings.Join(terms, " AND ")+")")
+	}
+	if len(conditions) == 0 {
+		return "1 = 0", nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
 
-	case "group_rel_submitted":
-		return fmt.Sprintf(
-			"LOWER(%s) %s, LOWER(%s) %s, (%s IS NULL) ASC, %s %s",
-			col("group_1"), dir,
-			col("relation"), dir,
-			col("submitted_at_utc"),
-			col("submitted_at_utc"), dir,
-		)
+// assetCursor is the opaque position after the last row of a page of assets. It is only
+// valid for the sort it was made for.
+type assetCursor struct {
+	Sort   string                 `json:"s"`
+	Dir    string                 `json:"d"`
+	Values map[string]interface{} `json:"v"`
+}
 
-	// Phase-specific sorting - these will be handled in post-processing
-	case "mdl_submitted", "rig_submitted", "bld_submitted", "dsn_submitted", "ldv_submitted",
-		"mdl_work", "rig_work", "bld_work", "dsn_work", "ldv_work",
-		"mdl_appr", "rig_appr", "bld_appr", "dsn_appr", "ldv_appr":
-		// Default ordering for SQL query - final sorting done in memory
-		return fmt.Sprintf(
-			"LOWER(%s) ASC, LOWER(%s) ASC",
-			col("group_1"),
-			col("relation"),
-		)
+func encodeAssetCursor(sort, dir string, values map[string]interface{}) (string, error) {
+	b, err := json.Marshal(&assetCursor{Sort: sort, Dir: dir, Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	default:
-		return fmt.Sprintf(
-			"LOWER(%s) %s, LOWER(%s) %s",
-			col("group_1"), dir,
-			col("relation"), dir,
-		)
+func decodeAssetCursor(s, sort, dir string) (map[string]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", entity.ErrBadRequest)
+	}
+	var c assetCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", entity.ErrBadRequest)
+	}
+	if c.Sort != sort || c.Dir != dir {
+		return nil, fmt.Errorf("%w: cursor of another sort", entity.ErrBadRequest)
 	}
+	return c.Values, nil
 }
 
-// Get pivot column value for sorting
-func getPivotColumnValue(row AssetPivot, orderKey string) interface{} {
-	parts := strings.Split(orderKey, "_")
-	if len(parts) < 2 {
+// cursorTime formats a datetime value of a cursor as MySQL compares it, in the time zone of
+// the database.
+func cursorTime(t *time.Time) interface{} {
+	if t == nil {
 		return nil
 	}
+	return dbTime(*t)
+}
 
-	phase := strings.ToUpper(parts[0])
-	column := strings.Join(parts[1:], "_")
+// assetTiebreakerKeys are the last keys of the queries returning a row per asset. group_1 and
+// relation are compared as is, which also orders assets differing only in letter case.
+var assetTiebreakerKeys = []keysetKey{
+	{column: "group_1"},
+	{column: "relation"},
+}
 
-	switch phase {
-	case "MDL":
-		switch column {
-		case "work":
-			return row.MDLWorkStatus
-		case "appr":
-			return row.MDLApprovalStatus
-		case "submitted":
-			return row.MDLSubmittedAtUTC
+// assetTiebreaker is the last ORDER BY of the queries returning a row per asset.
+func assetTiebreaker(alias string) string {
+	return keysetOrder(alias, assetTiebreakerKeys)
+}
+
+// pivotOrderKeys sort pivot rows on a submission column, missing submissions last.
+func pivotOrderKeys(orderCol, dir string) []keysetKey {
+	return append([]keysetKey{
+		{column: orderCol, fn: "ISNULL"},
+		{column: orderCol, desc: dir == "DESC"},
+	}, assetTiebreakerKeys...)
+}
+
+// buildOrderClause builds the ORDER BY of latest submissions, ending with assetTiebreaker.
+func buildOrderClause(alias, key, dir string) string {
+	return sortClause(alias, key, dir) + ", " + assetTiebreaker(alias)
+}
+
+// latestSubmissionOrderKeys are the keys of buildOrderClause.
+func latestSubmissionOrderKeys(key, dir string) []keysetKey {
+	return append(sortKeys(key, dir), assetTiebreakerKeys...)
+}
+
+// ORDER BY builder - FIXED for global sorting
+func sortClause(alias, key, dir string) string {
+	return keysetOrder(alias, sortKeys(key, dir))
+}
+
+// sortKeys returns the sort keys of latest submissions for sorting parameters.
+func sortKeys(key, dir string) []keysetKey {
+	dir = strings.ToUpper(strings.TrimSpace(dir))
+	desc := dir == "DESC"
+
+	switch key {
+	case "submitted_at_utc", "modified_at_utc", "phase":
+		return []keysetKey{{column: key, desc: desc}}
+
+	// takes without number last
+	case "take":
+		return []keysetKey{
+			{column: "take_number", fn: "ISNULL"},
+			{column: "take_number", desc: desc},
+			{column: "group_1", fn: "LOWER"},
 		}
-	case "RIG":
-		switch column {
-		case "work":
-			return row.RIGWorkStatus
-		case "appr":
-			return row.RIGApprovalStatus
-		case "submitted":
-			return row.RIGSubmittedAtUTC
+
+	case "group1_only", "name", "group_1":
+		return []keysetKey{
+			{column: "group_1", desc: desc, fn: "LOWER"},
+			{column: "relation", desc: desc, fn: "LOWER"},
 		}
-	case "BLD":
-		switch column {
-		case "work":
-			return row.BLDWorkStatus
-		case "appr":
-			return row.BLDApprovalStatus
-		case "submitted":
-			return row.BLDSubmittedAtUTC
+
+	case "relation_only":
+		return []keysetKey{
+			{column: "relation", desc: desc, fn: "LOWER"},
+			{column: "group_1", desc: desc, fn: "LOWER"},
+		}
+
+	case "group_rel_submitted":
+		return []keysetKey{
+			{column: "group_1", desc: desc, fn: "LOWER"},
+			{column: "relation", desc: desc, fn: "LOWER"},
+			{column: "submitted_at_utc", fn: "ISNULL"},
+			{column: "submitted_at_utc", desc: desc},
 		}
-	case "DSN":
-		switch column {
-		case "work":
-			return row.DSNWorkStatus
-		case "appr":
-			return row.DSNApprovalStatus
-		case "submitted":
-			return row.DSNSubmittedAtUTC
+
+	default:
+		// Phase-specific sorting - these will be handled in post-processing
+		if IsPivotPhaseSortKey(key) {
+			// Default ordering for SQL query - final sorting done in memory
+			return []keysetKey{
+				{column: "group_1", fn: "LOWER"},
+				{column: "relation", fn: "LOWER"},
+			}
 		}
-	case "LDV":
-		switch column {
-		case "work":
-			return row.LDVWorkStatus
-		case "appr":
-			return row.LDVApprovalStatus
-		case "submitted":
-			return row.LDVSubmittedAtUTC
+		return []keysetKey{
+			{column: "group_1", desc: desc, fn: "LOWER"},
+			{column: "relation", desc: desc, fn: "LOWER"},
 		}
 	}
+}
 
+// Get pivot column value for sorting
+func getPivotColumnValue(row AssetPivot, orderKey string) interface{} {
+	phase, column, ok := strings.Cut(orderKey, "_")
+	if !ok {
+		return nil
+	}
+	ph := row.Phases[phase]
+	if ph == nil {
+		return nil
+	}
+	switch column {
+	case "work":
+		return ph.WorkStatus
+	case "appr":
+		return ph.ApprovalStatus
+	case "submitted":
+		return ph.SubmittedAtUTC
+	}
 	return nil
 }
 
@@ -899,7 +2185,8 @@ func sortPivotRowsByPhase(rows []AssetPivot, orderKey, direction string) []Asset
 // ========================= GORM QUERY METHODS ===========================
 // ========================================================================
 
-// CountLatestSubmissions returns total asset count (for pagination) after filters.
+// CountLatestSubmissions returns total asset count (for pagination) after filters. Counts are
+// read from the query cache when cached, and from the latest reviews once they were rebuilt.
 func (r *ReviewInfo) CountLatestSubmissions(
 	ctx context.Context,
 	project, root, assetNameKey string,
@@ -914,6 +2201,53 @@ func (r *ReviewInfo) CountLatestSubmissions(
 		root = "assets"
 	}
 
+	var total int64
+	_, err := r.cachedQuery(ctx, project, "CountLatestSubmissions", []interface{}{
+		root, assetNameKey, approvalStatuses, workStatuses,
+	}, nil, &total, func() error {
+		var err error
+		total, err = r.countLatestSubmissions(
+			ctx, project, root, assetNameKey, approvalStatuses, workStatuses,
+		)
+		return err
+	})
+	return total, err
+}
+
+func (r *ReviewInfo) countLatestSubmissions(
+	ctx context.Context,
+	project, root, assetNameKey string,
+	approvalStatuses []string,
+	workStatuses []string,
+) (int64, error) {
+	latest, err := reviewLatestReady(r.db.WithContext(ctx), project)
+	if err != nil {
+		return 0, fmt.Errorf("CountLatestSubmissions: %w", err)
+	}
+	vocab, err := loadStatusVocabulary(r.db.WithContext(ctx), project)
+	if err != nil {
+		return 0, fmt.Errorf("CountLatestSubmissions: %w", err)
+	}
+	if latest {
+		// the latest record per asset-phase is materialized
+		countQuery := r.db.WithContext(ctx).Model(&model.ReviewLatest{}).
+			Select("COUNT(DISTINCT group_1, relation)").
+			Where("project = ?", project).
+			Where("root = ?", root).
+			Where("phase_latest = ?", true)
+		if assetNameKey != "" {
+			countQuery = countQuery.
+				Where("LOWER(group_1) LIKE ?", strings.ToLower(assetNameKey)+"%")
+		}
+		countQuery = buildStatusCondition(countQuery, vocab, approvalStatuses, workStatuses)
+
+		var total int64
+		if err := countQuery.Scan(&total).Error; err != nil {
+			return 0, fmt.Errorf("CountLatestSubmissions: %w", err)
+		}
+		return total, nil
+	}
+
 	db := r.db.WithContext(ctx).Model(&model.ReviewInfo{})
 
 	// Subquery: latest record per asset-phase
@@ -930,7 +2264,7 @@ func (r *ReviewInfo) CountLatestSubmissions(
 			modified_at_utc,
 			ROW_NUMBER() OVER (
 				PARTITION BY project, root, group_1, relation, phase
-				ORDER BY modified_at_utc DESC
+				ORDER BY modified_at_utc DESC, id DESC
 			) AS rn
 		`).
 		Where("project = ?", project).
@@ -949,10 +2283,10 @@ func (r *ReviewInfo) CountLatestSubmissions(
 		Where("rn = ?", 1)
 
 	// Apply status filters
-	countQuery = buildStatusCondition(countQuery, approvalStatuses, workStatuses)
+	countQuery = buildStatusCondition(countQuery, vocab, approvalStatuses, workStatuses)
 
 	var total int64
-	err := countQuery.Scan(&total).Error
+	err = countQuery.Scan(&total).Error
 	if err != nil {
 		return 0, fmt.Errorf("CountLatestSubmissions: %w", err)
 	}
@@ -960,7 +2294,9 @@ func (r *ReviewInfo) CountLatestSubmissions(
 	return total, nil
 }
 
-// ListLatestSubmissionsDynamic returns one "primary" row per asset for a page.
+// ListLatestSubmissionsDynamic returns one "primary" row per asset for a page, and the cursor
+// of the next page, empty on the last page. A cursor continues after the page it was returned
+// with, instead of offset.
 func (r *ReviewInfo) ListLatestSubmissionsDynamic(
 	ctx context.Context,
 	project string,
@@ -969,12 +2305,13 @@ func (r *ReviewInfo) ListLatestSubmissionsDynamic(
 	orderKey string,
 	direction string,
 	limit, offset int,
+	cursor string,
 	assetNameKey string,
 	approvalStatuses []string,
 	workStatuses []string,
-) ([]LatestSubmissionRow, error) {
+) ([]LatestSubmissionRow, string, error) {
 	if project == "" {
-		return nil, fmt.Errorf("project is required")
+		return nil, "", fmt.Errorf("project is required")
 	}
 	if root == "" {
 		root = "assets"
@@ -985,6 +2322,10 @@ func (r *ReviewInfo) ListLatestSubmissionsDynamic(
 	if offset < 0 {
 		offset = 0
 	}
+	vocab, err := loadStatusVocabulary(r.db.WithContext(ctx), project)
+	if err != nil {
+		return nil, "", fmt.Errorf("ListLatestSubmissionsDynamic: %w", err)
+	}
 
 	// Step 1: Get latest modified_at_utc per asset-phase
 	latestPhaseQuery := r.db.WithContext(ctx).
@@ -1018,8 +2359,9 @@ func (r *ReviewInfo) ListLatestSubmissionsDynamic(
 			lp.relation,
 			lp.phase,
 			ri.submitted_at_utc,
-			ri.work_status,
-			ri.approval_status,
+			ri.take_number,
+			`+vocab.column(entity.StatusKindWork, "ri.work_status")+` AS work_status,
+			`+vocab.column(entity.StatusKindApproval, "ri.approval_status")+` AS approval_status,
 			lp.modified_at_utc
 		`).
 		Table("(?) as lp", latestPhaseQuery).
@@ -1035,7 +2377,7 @@ func (r *ReviewInfo) ListLatestSubmissionsDynamic(
 		`)
 
 	// Apply status filters
-	joinQuery = buildStatusCondition(joinQuery, approvalStatuses, workStatuses)
+	joinQuery = buildStatusCondition(joinQuery, vocab, approvalStatuses, workStatuses)
 
 	// Step 3: Window function to rank assets with phase preference
 	// FIXED: Removed conflicting ordering from window function
@@ -1050,7 +2392,8 @@ func (r *ReviewInfo) ListLatestSubmissionsDynamic(
 						WHEN phase = ? THEN 0
 						ELSE 1
 					END,
-					modified_at_utc DESC
+					modified_at_utc DESC,
+					phase ASC
 			) as asset_rank
 		`, func() int {
 			if preferredPhase == "" || strings.EqualFold(preferredPhase, "none") {
@@ -1068,21 +2411,57 @@ func (r *ReviewInfo) ListLatestSubmissionsDynamic(
 			group_1,
 			relation,
 			phase,
-			submitted_at_utc
+			submitted_at_utc,
+			modified_at_utc,
+			take_number
 		`).
 		Table("(?) as ranked", rankedQuery).
-		Where("asset_rank = ?", 1).
+		Where("asset_rank = ?", 1)
+
+	dir := strings.ToUpper(strings.TrimSpace(direction))
+	if dir != "DESC" {
+		dir = "ASC"
+	}
+	keys := latestSubmissionOrderKeys(orderKey, dir)
+	if cursor != "" {
+		values, err := decodeAssetCursor(cursor, orderKey, dir)
+		if err != nil {
+			return nil, "", err
+		}
+		condition, args := keysetAfter("", keys, values)
+		finalQuery = finalQuery.Where(condition, args...)
+		offset = 0
+	}
+
+	// one more row tells whether there is a next page
+	finalQuery = finalQuery.
 		Order(buildOrderClause("", orderKey, direction)).
-		Limit(limit).
+		Limit(limit + 1).
 		Offset(offset)
 
 	var rows []LatestSubmissionRow
-	err := finalQuery.Scan(&rows).Error
+	err = finalQuery.Scan(&rows).Error
 	if err != nil {
-		return nil, fmt.Errorf("ListLatestSubmissionsDynamic: %w", err)
+		return nil, "", fmt.Errorf("ListLatestSubmissionsDynamic: %w", err)
+	}
+	if len(rows) <= limit {
+		return rows, "", nil
 	}
 
-	return rows, nil
+	rows = rows[:limit]
+	last := rows[limit-1]
+	next, err := encodeAssetCursor(orderKey, dir, map[string]interface{}{
+		"group_1":          last.Group1,
+		"relation":         last.Relation,
+		"phase":            last.Phase,
+		"submitted_at_utc": cursorTime(last.SubmittedAtUTC),
+		"modified_at_utc":  cursorTime(last.ModifiedAtUTC),
+		"take_number":      last.TakeNumber,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return rows, next, nil
 }
 
 // ListAssetsPivotResult is the result structure for ListAssetsPivot.
@@ -1097,25 +2476,761 @@ type ListAssetsPivotResult struct {
 	HasPrev  bool                 `json:"has_prev,omitempty"`
 	Sort     string               `json:"sort,omitempty"`
 	Dir      string               `json:"dir,omitempty"`
+	// NextCursor is the cursor of the next page of the list view, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// CategoriesAvailable tells whether the assets have group category data. All the assets
+	// are Unassigned otherwise.
+	CategoriesAvailable bool `json:"categories_available"`
+	// DataAsOf is when the result was read from the database, earlier when it was cached.
+	DataAsOf time.Time `json:"data_as_of"`
+}
+
+// ListAssetsPivotParams defines the parameters for ListAssetsPivot.
+type ListAssetsPivotParams struct {
+	Project          string   `json:"project"`
+	Root             string   `json:"root"`
+	View             string   `json:"view"`
+	Page             int      `json:"page"`
+	PerPage          int      `json:"per_page"`
+	OrderKey         string   `json:"order_key"`
+	Direction        string   `json:"direction"`
+	ApprovalStatuses []string `json:"approval_statuses"`
+	WorkStatuses     []string `json:"work_statuses"`
+	// PhaseApprovalStatuses and PhaseWorkStatuses keep the assets having one of the statuses
+	// in the phase they are keyed by, for all the phases given.
+	PhaseApprovalStatuses map[string][]string `json:"phase_approval_statuses"`
+	PhaseWorkStatuses     map[string][]string `json:"phase_work_statuses"`
+	// SubmittedFrom and SubmittedTo keep the assets with a latest submission of an included
+	// phase in the range, and ModifiedFrom and ModifiedTo the assets last modified in the
+	// range. The lower bounds are inclusive and the upper ones exclusive.
+	SubmittedFrom *time.Time `json:"submitted_from"`
+	SubmittedTo   *time.Time `json:"submitted_to"`
+	ModifiedFrom  *time.Time `json:"modified_from"`
+	ModifiedTo    *time.Time `json:"modified_to"`
+	AssetNameKey  string     `json:"name"`
+	// Asset and Relation keep the rows of a single asset when set, e.g. for its asset card.
+	Asset        string   `json:"asset"`
+	Relation     string   `json:"relation"`
+	OfficialOnly bool     `json:"official_only"`
+	Intents      []string `json:"intents"`
+
+	// Metadata filters assets by their custom field values, keyed by field key.
+	Metadata map[string]string `json:"metadata"`
+	// Tags keeps the assets having all the given tags.
+	Tags []string `json:"tags"`
+	// Phases are the phase columns of the pivot, from the project's phase template, which may
+	// have phases other than the classic ones. The classic phases are used when empty.
+	Phases []string `json:"phases"`
+	// Cursor continues the list view after the page it was returned with, instead of Page,
+	// without scanning the rows before. It is ignored by the grouped view.
+	Cursor string `json:"cursor"`
+	// Sort sorts on each of its keys in turn, instead of OrderKey and Direction when not
+	// empty.
+	Sort []PivotSortKey `json:"sort"`
+	// PhaseValue selects the review of each phase the phase columns are read from, the latest
+	// one when empty.
+	PhaseValue string `json:"phase_value"`
+	// MaxStaleness is the age of the oldest cached result the caller accepts, any age of the
+	// cache when nil. 0 bypasses the cache.
+	MaxStaleness *time.Duration `json:"-"`
+}
+
+// officialOnlyCondition keeps only pivot rows that have at least one official revision.
+const officialOnlyCondition = `EXISTS (
+	SELECT 1 FROM t_official_revision AS o
+	WHERE o.project = p.project
+	AND o.root = p.root
+	AND o.` + "`group`" + ` = p.group_1
+	AND o.relation = p.relation
+	AND o.is_official = 1
+)`
+
+// officialRevisionRow is a row of t_official_revision used to decorate pivot rows.
+type officialRevisionRow struct {
+	Group    string `gorm:"column:group"`
+	Relation string `gorm:"column:relation"`
+	Phase    string `gorm:"column:phase"`
+	Revision string `gorm:"column:revision"`
+}
+
+// attachOfficialRevisions looks up the official revisions of all given rows with a single
+// query and fills the per-phase official revision and is_official badge fields. A phase is
+// marked official when its latest take is the official revision.
+func (r *ReviewInfo) attachOfficialRevisions(
+	db *gorm.DB,
+	project, root string,
+	rows []AssetPivot,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	groups := make([]string, 0, len(rows))
+	relations := make([]string, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, row.Group1)
+		relations = append(relations, row.Relation)
+	}
+
+	var officials []officialRevisionRow
+	if err := db.Table("t_official_revision").
+		Select("`group`, relation, phase, revision").
+		Where("project = ?", project).
+		Where("root = ?", root).
+		Where("`group` IN ?", groups).
+		Where("relation IN ?", relations).
+		Where("is_official = ?", true).
+		Order("modified_at_utc ASC").
+		Scan(&officials).Error; err != nil {
+		return fmt.Errorf("attachOfficialRevisions: %w", err)
+	}
+
+	// Later rows overwrite earlier ones, so the most recently marked revision wins.
+	byKey := make(map[string]string, len(officials))
+	for _, o := range officials {
+		byKey[o.Group+"|"+o.Relation+"|"+strings.ToUpper(o.Phase)] = o.Revision
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		key := row.Group1 + "|" + row.Relation + "|"
+		for phase, ph := range row.Phases {
+			revision, ok := byKey[key+strings.ToUpper(phase)]
+			if !ok {
+				continue
+			}
+			ph.OfficialRevision = &revision
+			ph.IsOfficial = ph.Take != nil && *ph.Take == revision
+		}
+	}
+	return nil
+}
+
+// attachSLAStates sets the SLA state of the phases which have an SLA in the project. A phase is
+// breached while any review of it has an unresolved SLA breach.
+func (r *ReviewInfo) attachSLAStates(
+	db *gorm.DB,
+	project, root string,
+	rows []AssetPivot,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var slaPhases []string
+	if err := db.Table("t_review_sla").
+		Where("project = ?", project).
+		Where("deleted = ?", 0).
+		Pluck("phase", &slaPhases).Error; err != nil {
+		return fmt.Errorf("attachSLAStates: %w", err)
+	}
+	if len(slaPhases) == 0 {
+		return nil
+	}
+	hasSLA := make(map[string]bool, len(slaPhases))
+	for _, phase := range slaPhases {
+		hasSLA[strings.ToUpper(phase)] = true
+	}
+
+	groups := make([]string, 0, len(rows))
+	relations := make([]string, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, row.Group1)
+		relations = append(relations, row.Relation)
+	}
+
+	var breaches []struct {
+		Group    string
+		Relation string
+		Phase    string
+	}
+	if err := db.Table("t_review_sla_breach").
+		Select("`group`, relation, phase").
+		Where("project = ?", project).
+		Where("root = ?", root).
+		Where("`group` IN ?", groups).
+		Where("relation IN ?", relations).
+		Where("resolved_at_utc IS NULL").
+		Scan(&breaches).Error; err != nil {
+		return fmt.Errorf("attachSLAStates: %w", err)
+	}
+	breached := make(map[string]bool, len(breaches))
+	for _, b := range breaches {
+		breached[b.Group+"|"+b.Relation+"|"+strings.ToUpper(b.Phase)] = true
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		key := row.Group1 + "|" + row.Relation + "|"
+		for phase, ph := range row.Phases {
+			phase = strings.ToUpper(phase)
+			if !hasSLA[phase] {
+				continue
+			}
+			state := entity.SLAStateOK
+			if breached[key+phase] {
+				state = entity.SLAStateBreached
+			}
+			ph.SLAState = &state
+		}
+	}
+	return nil
+}
+
+// pivotPhases are the phase columns of the asset pivot without a phase template. They are
+// also the classic phases, which have fixed fields in AssetPivot.
+var pivotPhases = []string{"mdl", "rig", "bld", "dsn", "ldv"}
+
+// pivotPhasePattern restricts the phases of templates to names which can be inlined in the
+// column names of the pivot query.
+var pivotPhasePattern = regexp.MustCompile(`^[a-z][a-z0-9]{0,19}$`)
+
+// includedPivotPhases returns the phases of the template in its order, or the classic phases
+// when the template is empty. Phases which are not valid column name prefixes are skipped.
+func includedPivotPhases(template []string) []string {
+	if len(template) == 0 {
+		return pivotPhases
+	}
+	phases := make([]string, 0, len(template))
+	for _, phase := range template {
+		phase = strings.ToLower(strings.TrimSpace(phase))
+		if pivotPhasePattern.MatchString(phase) && !slices.Contains(phases, phase) {
+			phases = append(phases, phase)
+		}
+	}
+	return phases
+}
+
+// pivotPhaseSelect returns the columns of the phases of the pivot query, prefixed by the
+// phase. Phases are stored in upper case in the reviews. The columns only read the approved
+// reviews when approvedOnly is true, while the assets without any are still pivoted. The
+// statuses are normalized by vocab, so that the filters on them do not miss legacy values.
+func pivotPhaseSelect(phases []string, approvedOnly bool, vocab statusVocabulary) string {
+	workStatus := vocab.column(entity.StatusKindWork, "work_status")
+	approvalStatus := vocab.column(entity.StatusKindApproval, "approval_status")
+	approved := ""
+	if approvedOnly {
+		approved = "AND " + approvalStatus + " = '" + entity.ApprovalStatusApproved + "' "
+	}
+	var b strings.Builder
+	for _, phase := range phases {
+		when := "CASE WHEN phase = '" + strings.ToUpper(phase) + "' " + approved + "THEN "
+		fmt.Fprintf(&b, "MAX(%s%s END) AS %s_work_status,\n", when, workStatus, phase)
+		fmt.Fprintf(&b, "MAX(%s%s END) AS %s_approval_status,\n", when, approvalStatus, phase)
+		fmt.Fprintf(&b, "MAX(%ssubmitted_at_utc END) AS %s_submitted_at_utc,\n", when, phase)
+		fmt.Fprintf(
+			&b,
+			"SUBSTRING_INDEX(GROUP_CONCAT(%stake END ORDER BY submitted_at_utc DESC, id DESC), "+
+				"',', 1) AS %s_take,\n",
+			when, phase,
+		)
+		// empty when the review of the take is not linked, so that the link of an older one
+		// is not read instead
+		fmt.Fprintf(
+			&b,
+			"NULLIF(SUBSTRING_INDEX(GROUP_CONCAT(%sIFNULL(publish_transaction_id, '') END "+
+				"ORDER BY submitted_at_utc DESC, id DESC), ',', 1), '') "+
+				"AS %s_publish_transaction_id,\n",
+			when, phase,
+		)
+	}
+	return b.String()
+}
+
+// pivotOuterSelect returns the columns of the filtered pivot rows, with the latest submission
+// of all the phases and the columns of each phase as a JSON object for readPivotPhases.
+func pivotOuterSelect(phases []string) string {
+	objects := make([]string, len(phases))
+	for i, phase := range phases {
+		objects[i] = fmt.Sprintf(
+			"'%[1]s', JSON_OBJECT('work_status', %[1]s_work_status, "+
+				"'approval_status', %[1]s_approval_status, "+
+				"'submitted_at_utc', %[1]s_submitted_at_utc, 'take', %[1]s_take, "+
+				"'publish_transaction_id', %[1]s_publish_transaction_id)",
+			phase,
+		)
+	}
+	return "p.*, " + buildGlobalSubmittedAtExpr(phases) + " AS global_submitted_at, " +
+		"JSON_OBJECT(" + strings.Join(objects, ", ") + ") AS phase_columns"
+}
+
+// pivotPhaseSortPattern matches the sort keys of the phase columns, e.g. fx_submitted.
+var pivotPhaseSortPattern = regexp.MustCompile(`^[a-z][a-z0-9]{0,19}_(submitted|work|appr)$`)
+
+// IsPivotPhaseSortKey tells whether the key sorts pivot rows on the submission, work status
+// or approval status of a phase.
+func IsPivotPhaseSortKey(key string) bool {
+	return pivotPhaseSortPattern.MatchString(key)
+}
+
+// pivotOrderColumn returns the submission column of the phase the key sorts on, or the latest
+// submission of all the phases.
+func pivotOrderColumn(orderKey string, phases []string) string {
+	if phase, ok := strings.CutSuffix(orderKey, "_submitted"); ok &&
+		slices.Contains(phases, phase) {
+		return phase + "_submitted_at_utc"
+	}
+	return "global_submitted_at"
+}
+
+// PivotSortKey is a key of a multi-key sort of the pivot rows, e.g. fx_appr descending.
+type PivotSortKey struct {
+	Key  string `json:"key"`
+	Desc bool   `json:"desc"`
+}
+
+// pivotPhaseSortColumns are the column suffixes of the phase sort keys, by key suffix.
+var pivotPhaseSortColumns = map[string]string{
+	"submitted": "_submitted_at_utc",
+	"work":      "_work_status",
+	"appr":      "_approval_status",
+}
+
+// pivotSortKeys resolves the sort keys of the pivot rows. The keys of p.Sort are sorted on in
+// turn, missing values last, or p.OrderKey in the direction when p.Sort is empty. Statuses and
+// names are compared case-insensitively.
+func pivotSortKeys(p ListAssetsPivotParams, phases []string, dir string) ([]keysetKey, error) {
+	if len(p.Sort) == 0 {
+		return pivotOrderKeys(pivotOrderColumn(p.OrderKey, phases), dir), nil
+	}
+	var keys []keysetKey
+	for _, s := range p.Sort {
+		var column, fn string
+		switch s.Key {
+		case "group_1", "group1", "group1_only", "name":
+			column, fn = "group_1", "LOWER"
+		case "relation", "relation_only":
+			column, fn = "relation", "LOWER"
+		case "submitted", "submitted_at", "submitted_at_utc":
+			column = "global_submitted_at"
+		default:
+			m := pivotPhaseSortPattern.FindStringSubmatch(s.Key)
+			if m == nil {
+				return nil, fmt.Errorf("%w: unknown sort key %q", entity.ErrBadRequest, s.Key)
+			}
+			phase := strings.TrimSuffix(s.Key, "_"+m[1])
+			if !slices.Contains(phases, phase) {
+				return nil, fmt.Errorf(
+					"%w: phase %q is not a phase of the pivot %v",
+					entity.ErrBadRequest, phase, phases,
+				)
+			}
+			column = phase + pivotPhaseSortColumns[m[1]]
+			if m[1] != "submitted" {
+				fn = "LOWER"
+			}
+		}
+		if column != "group_1" && column != "relation" {
+			keys = append(keys, keysetKey{column: column, fn: "ISNULL"})
+		}
+		keys = append(keys, keysetKey{column: column, desc: s.Desc, fn: fn})
+	}
+	return append(keys, assetTiebreakerKeys...), nil
+}
+
+// pivotSortName is the sort of the params as returned with the results and kept by cursors,
+// the multi-key sort as key:dir terms separated by commas.
+func pivotSortName(p ListAssetsPivotParams) string {
+	if len(p.Sort) == 0 {
+		return p.OrderKey
+	}
+	terms := make([]string, len(p.Sort))
+	for i, s := range p.Sort {
+		terms[i] = s.Key + ":asc"
+		if s.Desc {
+			terms[i] = s.Key + ":desc"
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+// pivotStatusCondition keeps the pivot rows having one of the statuses in any of the phases.
+func pivotStatusCondition(phases []string, column string, statuses []string) clause.Expr {
+	if len(phases) == 0 {
+		return gorm.Expr("1 = 0")
+	}
+	conditions := make([]string, len(phases))
+	args := make([]interface{}, len(phases))
+	for i, phase := range phases {
+		conditions[i] = phase + "_" + column + " IN ?"
+		args[i] = statuses
+	}
+	return gorm.Expr("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// wherePivotFilters applies the status and official filters of the params to the pivot rows.
+func wherePivotFilters(q *gorm.DB, p ListAssetsPivotParams, phases []string) *gorm.DB {
+	if len(p.ApprovalStatuses) > 0 {
+		q = q.Where(pivotStatusCondition(phases, "approval_status", p.ApprovalStatuses))
+	}
+	if len(p.WorkStatuses) > 0 {
+		q = q.Where(pivotStatusCondition(phases, "work_status", p.WorkStatuses))
+	}
+	// the phases are checked against the included ones, so they are valid column prefixes
+	for _, phase := range phases {
+		if statuses := p.PhaseApprovalStatuses[phase]; len(statuses) > 0 {
+			q = q.Where(phase+"_approval_status IN ?", statuses)
+		}
+		if statuses := p.PhaseWorkStatuses[phase]; len(statuses) > 0 {
+			q = q.Where(phase+"_work_status IN ?", statuses)
+		}
+	}
+	if p.SubmittedFrom != nil || p.SubmittedTo != nil {
+		q = q.Where(pivotSubmittedCondition(phases, p.SubmittedFrom, p.SubmittedTo))
+	}
+	if p.ModifiedFrom != nil {
+		q = q.Where("p.modified_at_utc >= ?", p.ModifiedFrom.UTC())
+	}
+	if p.ModifiedTo != nil {
+		q = q.Where("p.modified_at_utc < ?", p.ModifiedTo.UTC())
+	}
+	if p.OfficialOnly {
+		q = q.Where(officialOnlyCondition)
+	}
+	return q
+}
+
+// pivotSubmittedCondition keeps the pivot rows whose latest submission of any of the phases is
+// in the range, from inclusive and to exclusive. The bounds are compared in UTC whatever the
+// offset they were given with.
+func pivotSubmittedCondition(phases []string, from, to *time.Time) clause.Expr {
+	if len(phases) == 0 {
+		return gorm.Expr("1 = 0")
+	}
+	conditions := make([]string, len(phases))
+	var args []interface{}
+	for i, phase := range phases {
+		column := phase + "_submitted_at_utc"
+		var bounds []string
+		if from != nil {
+			bounds = append(bounds, column+" >= ?")
+			args = append(args, from.UTC())
+		}
+		if to != nil {
+			bounds = append(bounds, column+" < ?")
+			args = append(args, to.UTC())
+		}
+		conditions[i] = "(" + strings.Join(bounds, " AND ") + ")"
+	}
+	return gorm.Expr("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// checkPhaseStatusFilters checks that the per phase status filters of the params are all on
+// phases included in the pivot.
+func checkPhaseStatusFilters(p ListAssetsPivotParams, phases []string) error {
+	for _, filters := range []map[string][]string{p.PhaseApprovalStatuses, p.PhaseWorkStatuses} {
+		for phase := range filters {
+			if !slices.Contains(phases, phase) {
+				return fmt.Errorf(
+					"%w: phase %q is not a phase of the pivot %v",
+					entity.ErrBadRequest, phase, phases,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// The reviews of each phase the phase columns of the pivot are read from.
+const (
+	PivotPhaseValueLatest         = "latest"
+	PivotPhaseValueLatestApproved = "latest_approved"
+)
+
+// checkPivotPhaseValue checks that the phase value of the params is known.
+func checkPivotPhaseValue(p ListAssetsPivotParams) error {
+	switch p.PhaseValue {
+	case "", PivotPhaseValueLatest, PivotPhaseValueLatestApproved:
+		return nil
+	}
+	return fmt.Errorf(
+		"%w: unknown phase value %q, expected %s or %s",
+		entity.ErrBadRequest, p.PhaseValue, PivotPhaseValueLatest, PivotPhaseValueLatestApproved,
+	)
+}
+
+// pivotPhaseColumns is a phase of the phase_columns of a pivot row. MySQL formats datetimes
+// in JSON without time zone, in the time zone the datetimes are read in.
+type pivotPhaseColumns struct {
+	WorkStatus     *string `json:"work_status"`
+	ApprovalStatus *string `json:"approval_status"`
+	SubmittedAtUTC *string `json:"submitted_at_utc"`
+	Take           *string `json:"take"`
+	// PublishTransactionID is the link of the review of the take to its publish transaction.
+	PublishTransactionID *string `json:"publish_transaction_id"`
+}
+
+// readPivotPhases reads the phase_columns of the rows into their Phases, with an entry for
+// each of the phases.
+func readPivotPhases(rows []AssetPivot, phases []string) error {
+	for i := range rows {
+		row := &rows[i]
+		var columns map[string]*pivotPhaseColumns
+		if row.PhaseColumns != nil {
+			if err := json.Unmarshal([]byte(*row.PhaseColumns), &columns); err != nil {
+				return fmt.Errorf("readPivotPhases: %w", err)
+			}
+		}
+		row.Phases = make(map[string]*AssetPivotPhase, len(phases))
+		for _, phase := range phases {
+			ph := &AssetPivotPhase{}
+			if c := columns[phase]; c != nil {
+				ph.WorkStatus, ph.ApprovalStatus, ph.Take = c.WorkStatus, c.ApprovalStatus, c.Take
+				ph.PublishTransactionID = c.PublishTransactionID
+				if c.SubmittedAtUTC != nil {
+					t, err := parseDBTime(*c.SubmittedAtUTC)
+					if err != nil {
+						return fmt.Errorf("readPivotPhases: %w", err)
+					}
+					ph.SubmittedAtUTC = &t
+				}
+			}
+			row.Phases[phase] = ph
+		}
+	}
+	return nil
+}
+
+// fillClassicPhases copies the classic phases of the rows into their fixed fields, which are
+// empty for the phases which are not included.
+func fillClassicPhases(rows []AssetPivot) {
+	for i := range rows {
+		row := &rows[i]
+		for _, phase := range pivotPhases {
+			ph := row.Phases[phase]
+			if ph == nil {
+				ph = &AssetPivotPhase{}
+			}
+			switch phase {
+			case "mdl":
+				row.MDLWorkStatus, row.MDLApprovalStatus = ph.WorkStatus, ph.ApprovalStatus
+				row.MDLSubmittedAtUTC, row.MDLTake = ph.SubmittedAtUTC, ph.Take
+				row.MDLOfficialRevision, row.MDLIsOfficial = ph.OfficialRevision, ph.IsOfficial
+				row.MDLSLAState = ph.SLAState
+			case "rig":
+				row.RIGWorkStatus, row.RIGApprovalStatus = ph.WorkStatus, ph.ApprovalStatus
+				row.RIGSubmittedAtUTC, row.RIGTake = ph.SubmittedAtUTC, ph.Take
+				row.RIGOfficialRevision, row.RIGIsOfficial = ph.OfficialRevision, ph.IsOfficial
+				row.RIGSLAState = ph.SLAState
+			case "bld":
+				row.BLDWorkStatus, row.BLDApprovalStatus = ph.WorkStatus, ph.ApprovalStatus
+				row.BLDSubmittedAtUTC, row.BLDTake = ph.SubmittedAtUTC, ph.Take
+				row.BLDOfficialRevision, row.BLDIsOfficial = ph.OfficialRevision, ph.IsOfficial
+				row.BLDSLAState = ph.SLAState
+			case "dsn":
+				row.DSNWorkStatus, row.DSNApprovalStatus = ph.WorkStatus, ph.ApprovalStatus
+				row.DSNSubmittedAtUTC, row.DSNTake = ph.SubmittedAtUTC, ph.Take
+				row.DSNOfficialRevision, row.DSNIsOfficial = ph.OfficialRevision, ph.IsOfficial
+				row.DSNSLAState = ph.SLAState
+			case "ldv":
+				row.LDVWorkStatus, row.LDVApprovalStatus = ph.WorkStatus, ph.ApprovalStatus
+				row.LDVSubmittedAtUTC, row.LDVTake = ph.SubmittedAtUTC, ph.Take
+				row.LDVOfficialRevision, row.LDVIsOfficial = ph.OfficialRevision, ph.IsOfficial
+				row.LDVSLAState = ph.SLAState
+			}
+		}
+	}
+}
+
+// whereMetadata keeps the records whose JSON column has all the given custom field values.
+// Values are compared as strings, so numbers match their JSON representation.
+func whereMetadata(stmt *gorm.DB, column string, filters map[string]string) *gorm.DB {
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		stmt = stmt.Where(
+			fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, ?)) = ?", column),
+			fmt.Sprintf("$.%q", k), filters[k],
+		)
+	}
+	return stmt
+}
+
+// groupPrefixLike escapes the wildcards of a LIKE pattern.
+var groupPrefixLike = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// whereGroupPrefix keeps the records whose group path ("<group_1>/<group_2>/...") starts with
+// the prefix. The complete segments are compared with the generated group columns, and a last
+// segment without a trailing slash matches the groups starting with it, so "seqA/shot01"
+// matches "seqA/shot010" and "seqA/shot011/..." while "seqA/" matches everything under seqA.
+func whereGroupPrefix(stmt *gorm.DB, prefix string) (*gorm.DB, error) {
+	segments := strings.Split(prefix, "/")
+	partial := segments[len(segments)-1]
+	segments = segments[:len(segments)-1]
+	if len(segments) > 5 || len(segments) == 5 && partial != "" {
+		return nil, fmt.Errorf(
+			"%w: group prefix %q is deeper than 5 groups", entity.ErrBadRequest, prefix,
+		)
+	}
+	for i, g := range segments {
+		stmt = stmt.Where(fmt.Sprintf("`group_%d` = ?", i+1), g)
+	}
+	if partial != "" {
+		stmt = stmt.Where(
+			fmt.Sprintf("`group_%d` LIKE ?", len(segments)+1),
+			groupPrefixLike.Replace(partial)+"%",
+		)
+	}
+	return stmt, nil
+}
+
+// attachAssetMetadata fills the custom asset field values of the given rows.
+func (r *ReviewInfo) attachAssetMetadata(
+	db *gorm.DB,
+	project string,
+	rows []AssetPivot,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	groups := make([]string, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, row.Group1)
+	}
+
+	var models []*model.AssetMetadata
+	if err := db.Where("project = ?", project).
+		Where("asset IN ?", groups).
+		Find(&models).Error; err != nil {
+		return fmt.Errorf("attachAssetMetadata: %w", err)
+	}
+	metadata := make(map[string]model.GormJSONObject, len(models))
+	for _, m := range models {
+		metadata[AssetMetadataKey(m.Asset, m.Relation)] = m.Metadata
+	}
+
+	for i := range rows {
+		if m, ok := metadata[AssetMetadataKey(rows[i].Group1, rows[i].Relation)]; ok {
+			rows[i].Metadata = m
+		}
+	}
+	return nil
+}
+
+// attachAssetTags fills the tags of the given rows.
+func (r *ReviewInfo) attachAssetTags(
+	db *gorm.DB,
+	project string,
+	rows []AssetPivot,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	targets := make([]string, len(rows))
+	for i, row := range rows {
+		targets[i] = AssetMetadataKey(row.Group1, row.Relation)
+	}
+	tags, err := listTargetTags(db, project, entity.TagTargetAsset, targets)
+	if err != nil {
+		return fmt.Errorf("attachAssetTags: %w", err)
+	}
+	for i := range rows {
+		rows[i].Tags = tags[targets[i]]
+	}
+	return nil
+}
+
+// attachAssetWatchers fills the number of watchers of the given rows.
+func (r *ReviewInfo) attachAssetWatchers(
+	db *gorm.DB,
+	project string,
+	rows []AssetPivot,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	targets := make([]string, len(rows))
+	for i, row := range rows {
+		targets[i] = AssetMetadataKey(row.Group1, row.Relation)
+	}
+	counts, err := countTargetWatchers(db, project, entity.TagTargetAsset, targets)
+	if err != nil {
+		return fmt.Errorf("attachAssetWatchers: %w", err)
+	}
+	for i := range rows {
+		rows[i].Watchers = counts[targets[i]]
+	}
+	return nil
 }
 
-// ListAssetsPivotParams defines the parameters for ListAssetsPivot.
-type ListAssetsPivotParams struct {
-	Project          string   `json:"project"`
-	Root             string   `json:"root"`
-	View             string   `json:"view"`
-	Page             int      `json:"page"`
-	PerPage          int      `json:"per_page"`
-	OrderKey         string   `json:"order_key"`
-	Direction        string   `json:"direction"`
-	ApprovalStatuses []string `json:"approval_statuses"`
-	WorkStatuses     []string `json:"work_statuses"`
+// pivotCursorValues returns the values of the sort keys of a pivot row, for its cursor.
+func pivotCursorValues(row AssetPivot, keys []keysetKey) map[string]interface{} {
+	values := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		values[k.column] = pivotColumnValue(row, k.column)
+	}
+	return values
+}
+
+// pivotColumnValue returns the value of a sort column of a pivot row, nil when missing.
+func pivotColumnValue(row AssetPivot, column string) interface{} {
+	switch column {
+	case "group_1":
+		return row.Group1
+	case "relation":
+		return row.Relation
+	case "global_submitted_at":
+		return cursorTime(row.GlobalSubmittedAt)
+	}
+	for key, suffix := range pivotPhaseSortColumns {
+		phase, ok := strings.CutSuffix(column, suffix)
+		if !ok {
+			continue
+		}
+		ph := row.Phases[phase]
+		if ph == nil {
+			return nil
+		}
+		var status *string
+		switch key {
+		case "submitted":
+			return cursorTime(ph.SubmittedAtUTC)
+		case "work":
+			status = ph.WorkStatus
+		case "appr":
+			status = ph.ApprovalStatus
+		}
+		if status == nil {
+			return nil
+		}
+		return *status
+	}
+	return nil
 }
 
+// ListAssetsPivot lists the pivoted assets, read from the query cache when they were cached
+// within p.MaxStaleness.
 func (r *ReviewInfo) ListAssetsPivot(
 	db *gorm.DB,
 	p ListAssetsPivotParams,
 ) (*ListAssetsPivotResult, error) {
+	if p.Project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	var result *ListAssetsPivotResult
+	dataAsOf, err := r.cachedQuery(
+		db.Statement.Context, p.Project, "ListAssetsPivot", p, p.MaxStaleness, &result,
+		func() error {
+			var err error
+			result, err = r.listAssetsPivot(db, p)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.DataAsOf = dataAsOf
+	return result, nil
+}
+
+func (r *ReviewInfo) listAssetsPivot(
+	db *gorm.DB,
+	p ListAssetsPivotParams,
+) (*ListAssetsPivotResult, error) {
 
 	if p.Project == "" {
 		return nil, fmt.Errorf("project is required")
@@ -1146,98 +3261,130 @@ func (r *ReviewInfo) ListAssetsPivot(
 			p.View == "grouped" ||
 			p.View == "category"
 
+	phases := includedPivotPhases(p.Phases)
+	if err := checkPhaseStatusFilters(p, phases); err != nil {
+		return nil, err
+	}
+	if err := checkPivotPhaseValue(p); err != nil {
+		return nil, err
+	}
+
+	// ---------------------------------------------------------------------
+	// INTENT EXCLUSIONS (PROJECT DEFAULT WHEN NO INTENT IS REQUESTED)
+	// ---------------------------------------------------------------------
+	var excludedIntents []string
+	if len(p.Intents) == 0 {
+		var err error
+		if excludedIntents, err = r.excludedIntents(db, p.Project); err != nil {
+			return nil, err
+		}
+	}
+	latest, err := reviewLatestReady(db, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := pivotCategoriesAvailable(db, p, latest)
+	if err != nil {
+		return nil, err
+	}
+	vocab, err := loadStatusVocabulary(db, p.Project)
+	if err != nil {
+		return nil, err
+	}
+
 	// ---------------------------------------------------------------------
 	// BASE PIVOT QUERY (ALREADY EXISTS IN YOUR FILE)
 	// ---------------------------------------------------------------------
-	pivotQuery := r.buildAssetPivotQuery(db, p)
+	pivotQuery := r.buildAssetPivotQuery(db, p, excludedIntents, latest, categories, vocab)
 
 	// ---------------------------------------------------------------------
-	// GLOBAL SUBMITTED AT (FOR GLOBAL SORTING)
+	// PHASE COLUMNS AND GLOBAL SUBMITTED AT (FOR GLOBAL SORTING)
 	// ---------------------------------------------------------------------
-	globalSubmittedExpr := `
-		GREATEST(
-			mdl_submitted_at_utc,
-			rig_submitted_at_utc,
-			bld_submitted_at_utc,
-			dsn_submitted_at_utc,
-			ldv_submitted_at_utc
-		)
-	`
+	outerSelect := pivotOuterSelect(phases)
+	orderKeys, err := pivotSortKeys(p, phases, dir)
+	if err != nil {
+		return nil, err
+	}
+	sortName := pivotSortName(p)
 
 	// =====================================================================
 	// ============================ LIST VIEW ===============================
 	// =====================================================================
 	if !isGroupedView {
 
-		q := db.Table("(?) AS p", pivotQuery).
-			Select("p.*, " + globalSubmittedExpr + " AS global_submitted_at")
+		q := db.Table("(?) AS p", pivotQuery).Select(outerSelect)
 
 		// ---------- FILTERS ----------
-		if len(p.ApprovalStatuses) > 0 {
-			q = q.Where(
-				"(mdl_approval_status IN ? OR rig_approval_status IN ? OR bld_approval_status IN ? OR dsn_approval_status IN ? OR ldv_approval_status IN ?)",
-				p.ApprovalStatuses,
-				p.ApprovalStatuses,
-				p.ApprovalStatuses,
-				p.ApprovalStatuses,
-				p.ApprovalStatuses,
-			)
-		}
-
-		if len(p.WorkStatuses) > 0 {
-			q = q.Where(
-				"(mdl_work_status IN ? OR rig_work_status IN ? OR bld_work_status IN ? OR dsn_work_status IN ? OR ldv_work_status IN ?)",
-				p.WorkStatuses,
-				p.WorkStatuses,
-				p.WorkStatuses,
-				p.WorkStatuses,
-				p.WorkStatuses,
-			)
-		}
+		q = wherePivotFilters(q, p, phases)
 
 		// ---------- COUNT ----------
 		var total int64
+		stopCount := entity.StartTiming(db.Statement.Context, entity.TimingCount)
 		if err := q.Count(&total).Error; err != nil {
 			return nil, err
 		}
+		stopCount()
 
-		// ---------- SORT COLUMN ----------
-		orderCol := "global_submitted_at"
-
-		switch p.OrderKey {
-		case "mdl_submitted":
-			orderCol = "mdl_submitted_at_utc"
-		case "rig_submitted":
-			orderCol = "rig_submitted_at_utc"
-		case "bld_submitted":
-			orderCol = "bld_submitted_at_utc"
-		case "dsn_submitted":
-			orderCol = "dsn_submitted_at_utc"
-		case "ldv_submitted":
-			orderCol = "ldv_submitted_at_utc"
+		if p.Cursor != "" {
+			values, err := decodeAssetCursor(p.Cursor, sortName, dir)
+			if err != nil {
+				return nil, err
+			}
+			// the computed sort columns are only known to the conditions of an outer query
+			condition, args := keysetAfter("", orderKeys, values)
+			q = db.Table("(?) AS c", q).Where(condition, args...)
+			offset = 0
 		}
 
-		q = q.Order(fmt.Sprintf("%s %s NULLS LAST", orderCol, dir)).
-			Limit(limit).
+		// one more row tells whether there is a next page
+		q = q.Order(keysetOrder("", orderKeys)).
+			Limit(limit + 1).
 			Offset(offset)
 
 		var rows []AssetPivot
+		stopKeys := entity.StartTiming(db.Statement.Context, entity.TimingKeys)
 		if err := q.Scan(&rows).Error; err != nil {
 			return nil, err
 		}
+		stopKeys()
+		stopPhases := entity.StartTiming(db.Statement.Context, entity.TimingPhases)
+		if err := readPivotPhases(rows, phases); err != nil {
+			return nil, err
+		}
+		var nextCursor string
+		if len(rows) > limit {
+			rows = rows[:limit]
+			var err error
+			nextCursor, err = encodeAssetCursor(
+				sortName, dir, pivotCursorValues(rows[limit-1], orderKeys),
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := r.attachPivotDetails(db, p, rows); err != nil {
+			return nil, err
+		}
+		stopPhases()
 
 		lastPage := int(math.Ceil(float64(total) / float64(limit)))
+		hasNext, hasPrev := p.Page < lastPage, p.Page > 1
+		if p.Cursor != "" {
+			hasNext, hasPrev = nextCursor != "", true
+		}
 
 		return &ListAssetsPivotResult{
-			Assets:   rows,
-			Total:    total,
-			Page:     p.Page,
-			PerPage:  p.PerPage,
-			PageLast: lastPage,
-			HasNext:  p.Page < lastPage,
-			HasPrev:  p.Page > 1,
-			Sort:     p.OrderKey,
-			Dir:      dir,
+			Assets:              rows,
+			Total:               total,
+			Page:                p.Page,
+			PerPage:             p.PerPage,
+			PageLast:            lastPage,
+			HasNext:             hasNext,
+			HasPrev:             hasPrev,
+			Sort:                sortName,
+			Dir:                 dir,
+			NextCursor:          nextCursor,
+			CategoriesAvailable: categories,
 		}, nil
 	}
 
@@ -1245,64 +3392,482 @@ func (r *ReviewInfo) ListAssetsPivot(
 	// ========================== GROUPED VIEW ==============================
 	// =====================================================================
 
-	q := db.Table("(?) AS p", pivotQuery).
-		Select("p.*, " + globalSubmittedExpr + " AS global_submitted_at")
+	q := db.Table("(?) AS p", pivotQuery).Select(outerSelect)
 
 	// ---------- FILTERS ----------
-	if len(p.ApprovalStatuses) > 0 {
-		q = q.Where(
-			"(mdl_approval_status IN ? OR rig_approval_status IN ? OR bld_approval_status IN ? OR dsn_approval_status IN ? OR ldv_approval_status IN ?)",
-			p.ApprovalStatuses,
-			p.ApprovalStatuses,
-			p.ApprovalStatuses,
-			p.ApprovalStatuses,
-			p.ApprovalStatuses,
-		)
+	q = wherePivotFilters(q, p, phases)
+
+	q = q.Order(keysetOrder("", orderKeys))
+
+	var rows []AssetPivot
+	stopKeys := entity.StartTiming(db.Statement.Context, entity.TimingKeys)
+	if err := q.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	stopKeys()
+	stopPhases := entity.StartTiming(db.Statement.Context, entity.TimingPhases)
+	if err := readPivotPhases(rows, phases); err != nil {
+		return nil, err
+	}
+	if err := r.attachPivotDetails(db, p, rows); err != nil {
+		return nil, err
 	}
+	stopPhases()
 
-	if len(p.WorkStatuses) > 0 {
-		q = q.Where(
-			"(mdl_work_status IN ? OR rig_work_status IN ? OR bld_work_status IN ? OR dsn_work_status IN ? OR ldv_work_status IN ?)",
-			p.WorkStatuses,
-			p.WorkStatuses,
-			p.WorkStatuses,
-			p.WorkStatuses,
-			p.WorkStatuses,
-		)
+	// ---------- GROUP (ORDER PRESERVED) ----------
+	stopStitch := entity.StartTiming(db.Statement.Context, entity.TimingStitch)
+	groups := GroupAndSortByTopNode(rows, SortDirection(dir))
+	stopStitch()
+
+	return &ListAssetsPivotResult{
+		Groups:              groups,
+		Total:               int64(len(rows)),
+		Page:                1,
+		PerPage:             len(rows),
+		Sort:                sortName,
+		Dir:                 dir,
+		CategoriesAvailable: categories,
+	}, nil
+}
+
+// attachPivotDetails fills the official revisions, SLA states, metadata, tags and watchers of
+// the pivot rows, and their classic phase fields.
+func (r *ReviewInfo) attachPivotDetails(
+	db *gorm.DB,
+	p ListAssetsPivotParams,
+	rows []AssetPivot,
+) error {
+	if err := r.attachOfficialRevisions(db, p.Project, p.Root, rows); err != nil {
+		return err
+	}
+	if err := r.attachSLAStates(db, p.Project, p.Root, rows); err != nil {
+		return err
+	}
+	if err := r.attachAssetMetadata(db, p.Project, rows); err != nil {
+		return err
+	}
+	if err := r.attachAssetTags(db, p.Project, rows); err != nil {
+		return err
+	}
+	if err := r.attachAssetWatchers(db, p.Project, rows); err != nil {
+		return err
+	}
+	fillClassicPhases(rows)
+	return nil
+}
+
+// pivotGroupKey is the top group node of a pivot row, as grouped by GroupAndSortByTopNode.
+const pivotGroupKey = "COALESCE(NULLIF(TRIM(f.top_group_node), ''), 'Unassigned')"
+
+// pivotGroupOrder sorts the pivot rows by group as GroupAndSortByTopNode, Unassigned last,
+// then within each group on the sort of the list view.
+func pivotGroupOrder(keys []keysetKey) string {
+	return "LOWER(group_key) = 'unassigned', LOWER(group_key), group_key, " +
+		keysetOrder("", keys)
+}
+
+// ListAssetsPivotGroups lists a page of the pivoted assets in the order of the grouped view,
+// with the groups of the page. Groups are ordered and the page is cut in SQL, so that only the
+// rows of the page are read. A group split over pages has its total_count on each of them.
+// The result is read from the query cache when it was cached within p.MaxStaleness.
+func (r *ReviewInfo) ListAssetsPivotGroups(
+	db *gorm.DB,
+	p ListAssetsPivotParams,
+) (*ListAssetsPivotResult, error) {
+	if p.Project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	var result *ListAssetsPivotResult
+	dataAsOf, err := r.cachedQuery(
+		db.Statement.Context, p.Project, "ListAssetsPivotGroups", p, p.MaxStaleness, &result,
+		func() error {
+			var err error
+			result, err = r.listAssetsPivotGroups(db, p)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
 	}
+	result.DataAsOf = dataAsOf
+	return result, nil
+}
 
-	// ---------- SORT COLUMN ----------
-	orderCol := "global_submitted_at"
+func (r *ReviewInfo) listAssetsPivotGroups(
+	db *gorm.DB,
+	p ListAssetsPivotParams,
+) (*ListAssetsPivotResult, error) {
+	if p.Root == "" {
+		p.Root = "assets"
+	}
+	if p.PerPage <= 0 {
+		p.PerPage = 15
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	limit := p.PerPage
+	offset := (p.Page - 1) * p.PerPage
+	dir := strings.ToUpper(strings.TrimSpace(p.Direction))
+	if dir != "ASC" && dir != "DESC" {
+		dir = "ASC"
+	}
+	phases := includedPivotPhases(p.Phases)
+	if err := checkPhaseStatusFilters(p, phases); err != nil {
+		return nil, err
+	}
+	if err := checkPivotPhaseValue(p); err != nil {
+		return nil, err
+	}
+	orderKeys, err := pivotSortKeys(p, phases, dir)
+	if err != nil {
+		return nil, err
+	}
 
-	switch p.OrderKey {
-	case "mdl_submitted":
-		orderCol = "mdl_submitted_at_utc"
-	case "rig_submitted":
-		orderCol = "rig_submitted_at_utc"
-	case "bld_submitted":
-		orderCol = "bld_submitted_at_utc"
-	case "dsn_submitted":
-		orderCol = "dsn_submitted_at_utc"
-	case "ldv_submitted":
-		orderCol = "ldv_submitted_at_utc"
+	var excludedIntents []string
+	if len(p.Intents) == 0 {
+		var err error
+		if excludedIntents, err = r.excludedIntents(db, p.Project); err != nil {
+			return nil, err
+		}
+	}
+	latest, err := reviewLatestReady(db, p.Project)
+	if err != nil {
+		return nil, err
 	}
+	categories, err := pivotCategoriesAvailable(db, p, latest)
+	if err != nil {
+		return nil, err
+	}
+	vocab, err := loadStatusVocabulary(db, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	q := wherePivotFilters(
+		db.Table(
+			"(?) AS p", r.buildAssetPivotQuery(db, p, excludedIntents, latest, categories, vocab),
+		).Select(pivotOuterSelect(phases)),
+		p, phases,
+	)
+	keyed := db.Table("(?) AS f", q).Select("f.*, " + pivotGroupKey + " AS group_key")
 
-	q = q.Order(fmt.Sprintf("%s %s NULLS LAST", orderCol, dir))
+	var groupTotals []struct {
+		GroupKey string
+		Total    int
+	}
+	stopCount := entity.StartTiming(db.Statement.Context, entity.TimingCount)
+	if err := db.Table("(?) AS g", keyed).Select(
+		"group_key, COUNT(*) AS total",
+	).Group("group_key").Scan(&groupTotals).Error; err != nil {
+		return nil, fmt.Errorf("ListAssetsPivotGroups: %w", err)
+	}
+	stopCount()
+	var total int64
+	totals := make(map[string]int, len(groupTotals))
+	for _, g := range groupTotals {
+		total += int64(g.Total)
+		totals[g.GroupKey] = g.Total
+	}
 
+	ranked := db.Table("(?) AS g", keyed).Select(
+		"g.*, ROW_NUMBER() OVER (ORDER BY " +
+			pivotGroupOrder(orderKeys) +
+			") AS group_rn",
+	)
 	var rows []AssetPivot
-	if err := q.Scan(&rows).Error; err != nil {
+	stopKeys := entity.StartTiming(db.Statement.Context, entity.TimingKeys)
+	if err := db.Table("(?) AS r", ranked).Where(
+		"group_rn > ?", offset,
+	).Where(
+		"group_rn <= ?", offset+limit,
+	).Order("group_rn").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("ListAssetsPivotGroups: %w", err)
+	}
+	stopKeys()
+	stopPhases := entity.StartTiming(db.Statement.Context, entity.TimingPhases)
+	if err := readPivotPhases(rows, phases); err != nil {
+		return nil, err
+	}
+	if err := r.attachPivotDetails(db, p, rows); err != nil {
 		return nil, err
 	}
+	stopPhases()
 
-	// ---------- GROUP (ORDER PRESERVED) ----------
+	stopStitch := entity.StartTiming(db.Statement.Context, entity.TimingStitch)
 	groups := GroupAndSortByTopNode(rows, SortDirection(dir))
-
+	for i := range groups {
+		if n, ok := totals[groups[i].TopGroupNode]; ok {
+			groups[i].TotalCount = &n
+		}
+	}
+	stopStitch()
+	lastPage := int(math.Ceil(float64(total) / float64(limit)))
 	return &ListAssetsPivotResult{
-		Groups:  groups,
-		Total:   int64(len(rows)),
-		Page:    1,
-		PerPage: len(rows),
-		Sort:    p.OrderKey,
-		Dir:     dir,
+		Assets:              rows,
+		Groups:              groups,
+		Total:               total,
+		Page:                p.Page,
+		PerPage:             p.PerPage,
+		PageLast:            lastPage,
+		HasNext:             p.Page < lastPage,
+		HasPrev:             p.Page > 1,
+		Sort:                pivotSortName(p),
+		Dir:                 dir,
+		CategoriesAvailable: categories,
 	}, nil
 }
+
+// PivotPhaseSummary counts the assets of the pivot with a submission in a phase, in total and
+// per latest approval and work status.
+type PivotPhaseSummary struct {
+	Phase            string           `json:"phase"`
+	Total            int64            `json:"total"`
+	ApprovalStatuses map[string]int64 `json:"approval_statuses"`
+	WorkStatuses     map[string]int64 `json:"work_statuses"`
+}
+
+// AssetsPivotSummary summarizes the statuses of the assets matching the pivot filters. Total
+// is the number of assets, including the ones without submissions in some phases.
+type AssetsPivotSummary struct {
+	Total  int64                `json:"total"`
+	Phases []*PivotPhaseSummary `json:"phases"`
+}
+
+// SummarizeAssetsPivot counts the assets matching the filters of the pivot per phase and
+// status in a single aggregation, crossing the pivot rows with the included phases. Paging
+// and sort parameters are ignored.
+func (r *ReviewInfo) SummarizeAssetsPivot(
+	db *gorm.DB,
+	p ListAssetsPivotParams,
+) (*AssetsPivotSummary, error) {
+	if p.Project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	if p.Root == "" {
+		p.Root = "assets"
+	}
+	phases := includedPivotPhases(p.Phases)
+	if err := checkPhaseStatusFilters(p, phases); err != nil {
+		return nil, err
+	}
+	if err := checkPivotPhaseValue(p); err != nil {
+		return nil, err
+	}
+	summary := &AssetsPivotSummary{Phases: make([]*PivotPhaseSummary, len(phases))}
+	if len(phases) == 0 {
+		return summary, nil
+	}
+
+	var excludedIntents []string
+	if len(p.Intents) == 0 {
+		var err error
+		if excludedIntents, err = r.excludedIntents(db, p.Project); err != nil {
+			return nil, err
+		}
+	}
+	latest, err := reviewLatestReady(db, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	vocab, err := loadStatusVocabulary(db, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	// the summary does not read the group categories
+	q := wherePivotFilters(
+		db.Table("(?) AS p", r.buildAssetPivotQuery(db, p, excludedIntents, latest, false, vocab)).
+			Select("p.*"),
+		p, phases,
+	)
+
+	// the phases are valid column name prefixes, so they are inlined in the statement
+	phaseRows := make([]string, len(phases))
+	byPhase := make(map[string]*PivotPhaseSummary, len(phases))
+	for i, phase := range phases {
+		phaseRows[i] = "SELECT '" + phase + "' AS phase"
+		summary.Phases[i] = &PivotPhaseSummary{
+			Phase:            strings.ToUpper(phase),
+			ApprovalStatuses: map[string]int64{},
+			WorkStatuses:     map[string]int64{},
+		}
+		byPhase[phase] = summary.Phases[i]
+	}
+	column := func(name string) string {
+		expr := "CASE ph.phase"
+		for _, phase := range phases {
+			expr += " WHEN '" + phase + "' THEN f." + phase + "_" + name
+		}
+		return expr + " END"
+	}
+
+	var counts []struct {
+		Phase          string
+		ApprovalStatus *string
+		WorkStatus     *string
+		Submitted      bool
+		Count          int64
+	}
+	stopCount := entity.StartTiming(db.Statement.Context, entity.TimingCount)
+	if err := db.Table("(?) AS f", q).Joins(
+		"CROSS JOIN (" + strings.Join(phaseRows, " UNION ALL ") + ") AS ph",
+	).Select(
+		"ph.phase, " +
+			column("approval_status") + " AS approval_status, " +
+			column("work_status") + " AS work_status, " +
+			column("submitted_at_utc") + " IS NOT NULL AS submitted, " +
+			"COUNT(*) AS count",
+	).Group(
+		"ph.phase, approval_status, work_status, submitted",
+	).Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("SummarizeAssetsPivot: %w", err)
+	}
+	stopCount()
+
+	stopStitch := entity.StartTiming(db.Statement.Context, entity.TimingStitch)
+	defer stopStitch()
+	for _, c := range counts {
+		s, ok := byPhase[c.Phase]
+		if !ok {
+			continue
+		}
+		// every asset is crossed with each phase once
+		if c.Phase == phases[0] {
+			summary.Total += c.Count
+		}
+		if !c.Submitted {
+			continue
+		}
+		s.Total += c.Count
+		if c.ApprovalStatus != nil {
+			s.ApprovalStatuses[*c.ApprovalStatus] += c.Count
+		}
+		if c.WorkStatus != nil {
+			s.WorkStatuses[*c.WorkStatus] += c.Count
+		}
+	}
+	return summary, nil
+}
+
+// SequenceRollup counts the shots of a sequence per phase and status of their latest review,
+// the last modified one of the shot and phase. The phases are the ones the shots have reviews
+// of. The rollup is read from the query cache when it was cached within params.MaxStaleness.
+func (r *ReviewInfo) SequenceRollup(
+	db *gorm.DB,
+	params *entity.GetSequenceRollupParams,
+) (*entity.SequenceRollup, error) {
+	var result *entity.SequenceRollup
+	dataAsOf, err := r.cachedQuery(
+		db.Statement.Context, params.Project, "SequenceRollup", params, params.MaxStaleness,
+		&result,
+		func() error {
+			var err error
+			result, err = r.sequenceRollup(db, params)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	result.DataAsOf = dataAsOf
+	return result, nil
+}
+
+func (r *ReviewInfo) sequenceRollup(
+	db *gorm.DB,
+	params *entity.GetSequenceRollupParams,
+) (*entity.SequenceRollup, error) {
+	excluded, err := r.excludedIntents(db, params.Project)
+	if err != nil {
+		return nil, err
+	}
+	vocab, err := loadStatusVocabulary(db, params.Project)
+	if err != nil {
+		return nil, err
+	}
+	shots := func() *gorm.DB {
+		stmt := db.Model(&model.ReviewInfo{}).Where(
+			"project = ?", params.Project,
+		).Where(
+			"root = ?", "shots",
+		).Where(
+			"group_2 = ?", params.Sequence,
+		).Where(
+			"deleted = ?", 0,
+		)
+		if params.Episode != nil {
+			stmt = stmt.Where("group_1 = ?", *params.Episode)
+		}
+		if len(excluded) > 0 {
+			stmt = stmt.Where("intent NOT IN ?", excluded)
+		}
+		return stmt
+	}
+
+	rollup := &entity.SequenceRollup{
+		Project:  params.Project,
+		Episode:  params.Episode,
+		Sequence: params.Sequence,
+		Phases:   []*entity.PhaseRollup{},
+	}
+	var total int64
+	if err := shots().Select(
+		"COUNT(DISTINCT group_1, group_3, relation)",
+	).Scan(&total).Error; err != nil {
+		return nil, fmt.Errorf("SequenceRollup: %w", err)
+	}
+	rollup.Shots = int(total)
+
+	latest := shots().Select(
+		"phase, " +
+			vocab.column(entity.StatusKindApproval, "approval_status") + " AS approval_status, " +
+			vocab.column(entity.StatusKindWork, "work_status") + " AS work_status, " +
+			"ROW_NUMBER() OVER (PARTITION BY group_1, group_3, relation, phase " +
+			"ORDER BY modified_at_utc DESC, id DESC) AS rn",
+	)
+	var counts []struct {
+		Phase          string
+		ApprovalStatus string
+		WorkStatus     string
+		Count          int
+	}
+	if err := db.Table("(?) AS l", latest).Select(
+		"phase, approval_status, work_status, COUNT(*) AS count",
+	).Where(
+		"rn = ?", 1,
+	).Group(
+		"phase, approval_status, work_status",
+	).Order("phase").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("SequenceRollup: %w", err)
+	}
+
+	var phase *entity.PhaseRollup
+	for _, c := range counts {
+		if phase == nil || phase.Phase != c.Phase {
+			phase = &entity.PhaseRollup{
+				Phase:            c.Phase,
+				ApprovalStatuses: map[string]int{},
+				WorkStatuses:     map[string]int{},
+			}
+			rollup.Phases = append(rollup.Phases, phase)
+		}
+		phase.Submitted += c.Count
+		phase.ApprovalStatuses[c.ApprovalStatus] += c.Count
+		phase.WorkStatuses[c.WorkStatus] += c.Count
+		if c.ApprovalStatus == entity.ApprovalStatusApproved {
+			phase.Approved += c.Count
+		}
+	}
+	for _, phase := range rollup.Phases {
+		phase.SubmittedPercent = rollupPercent(phase.Submitted, rollup.Shots)
+		phase.ApprovedPercent = rollupPercent(phase.Approved, rollup.Shots)
+	}
+	return rollup, nil
+}
+
+// rollupPercent returns n in percent of the shots, rounded to 2 decimals.
+func rollupPercent(n, shots int) float64 {
+	if shots == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*10000/float64(shots)) / 100
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type Activity struct {
+	repo        *repository.Activity
+	prjRepo     *repository.ProjectInfo
+	ReadTimeout time.Duration
+}
+
+func NewActivity(
+	repo *repository.Activity,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+) *Activity {
+	return &Activity{
+		repo:        repo,
+		prjRepo:     pr,
+		ReadTimeout: readTimeout,
+	}
+}
+
+func (uc *Activity) List(
+	ctx context.Context,
+	params *entity.ListActivitiesParams,
+) ([]*entity.Activity, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if _, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: params.Project,
+	}); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.List(db, params)
+}
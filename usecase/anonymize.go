@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Anonymize struct {
+	repo         *repository.Anonymizer
+	WriteTimeout time.Duration
+}
+
+func NewAnonymize(
+	repo *repository.Anonymizer,
+	writeTimeout time.Duration,
+) *Anonymize {
+	return &Anonymize{
+		repo:         repo,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+// Anonymize rewrites the personal data of the MySQL tables one transaction per batch of rows,
+// then the documents of MongoDB. A failed run must be started over from a fresh clone, as the
+// rows already anonymized would be faked again.
+func (uc *Anonymize) Anonymize(
+	ctx context.Context,
+	lgr entity.Logger,
+	params *entity.AnonymizeParams,
+) (*entity.AnonymizeResult, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+
+	db := uc.repo.WithContext(timeoutCtx)
+	tables, err := uc.repo.Tables(db)
+	if err != nil {
+		return nil, err
+	}
+	faker, err := uc.repo.Faker(db, tables, params.Salt)
+	if err != nil {
+		return nil, err
+	}
+	result := &entity.AnonymizeResult{
+		Users:     faker.Users(),
+		Computers: faker.Computers(),
+		Rows:      map[string]int64{},
+		Documents: map[string]int64{},
+		DryRun:    params.DryRun,
+	}
+	for _, t := range tables {
+		var afterID int64
+		for {
+			var read int
+			var changed int64
+			if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+				var err error
+				afterID, read, changed, err = uc.repo.AnonymizeRows(
+					tx, faker, t, afterID, params.BatchSize, params.DryRun,
+				)
+				return err
+			}); err != nil {
+				return nil, err
+			}
+			result.Rows[t.Name] += changed
+			if read < params.BatchSize {
+				break
+			}
+		}
+		lgr.Infof("%d rows of %s anonymized", result.Rows[t.Name], t.Name)
+	}
+
+	if params.SkipMongo {
+		return result, nil
+	}
+	collections, err := uc.repo.Collections(timeoutCtx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range collections {
+		n, err := uc.repo.AnonymizeDocuments(timeoutCtx, faker, c, params.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		result.Documents[c] = n
+		lgr.Infof("%d documents of %s anonymized", n, c)
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type APIKey struct {
+	repo         *repository.APIKey
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewAPIKey(
+	repo *repository.APIKey,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *APIKey {
+	return &APIKey{
+		repo:         repo,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *APIKey) List(
+	ctx context.Context,
+	params *entity.ListAPIKeysParams,
+) ([]*entity.APIKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.List(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *APIKey) Get(
+	ctx context.Context,
+	params *entity.GetAPIKeyParams,
+) (*entity.APIKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+// Create creates an API key and returns it with its key, which cannot be retrieved later.
+func (uc *APIKey) Create(
+	ctx context.Context,
+	params *entity.CreateAPIKeyParams,
+) (*entity.APIKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if params.ExpiresAtUTC != nil && !params.ExpiresAtUTC.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at_utc must be in the future", entity.ErrBadRequest)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.APIKey
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Rotate replaces the key of an API key and returns it with its new key.
+func (uc *APIKey) Rotate(
+	ctx context.Context,
+	params *entity.RotateAPIKeyParams,
+) (*entity.APIKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.APIKey
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Rotate(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *APIKey) Revoke(
+	ctx context.Context,
+	params *entity.RevokeAPIKeyParams,
+) (*entity.APIKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.APIKey
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Revoke(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Authenticate returns the API key of a request when it may call the route.
+func (uc *APIKey) Authenticate(
+	ctx context.Context,
+	params *entity.AuthenticateAPIKeyParams,
+) (*entity.APIKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrUnauthorized, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.Authenticate(uc.repo.WithContext(timeoutCtx), params)
+}
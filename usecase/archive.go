@@ -0,0 +1,677 @@
+package usecase
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+const (
+	// archiveJobRetention is how long the bundles of the archive jobs are kept, for them to be
+	// moved to the cold storage.
+	archiveJobRetention = 7 * 24 * time.Hour
+	// archiveManifestFile is the name of the manifest in the bundles.
+	archiveManifestFile = "manifest.json"
+)
+
+type Archive struct {
+	repo     *repository.ArchiveJob
+	archiver *repository.Archiver
+	prjRepo  *repository.ProjectInfo
+	// ArchiveTimeout bounds the export of a project.
+	ArchiveTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+}
+
+func NewArchive(
+	repo *repository.ArchiveJob,
+	archiver *repository.Archiver,
+	pr *repository.ProjectInfo,
+	archiveTimeout time.Duration,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Archive {
+	return &Archive{
+		repo:           repo,
+		archiver:       archiver,
+		prjRepo:        pr,
+		ArchiveTimeout: archiveTimeout,
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
+	}
+}
+
+func (uc *Archive) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// withArchiveDownloadURL sets the URL the bundle of a completed job is downloaded from.
+func withArchiveDownloadURL(e *entity.ArchiveJob) *entity.ArchiveJob {
+	if e.Status == entity.ArchiveCompleted {
+		e.DownloadURL = entity.PublicPath(
+			fmt.Sprintf("/api/projects/%s/archives/%d/download", e.Project, e.ID),
+		)
+	}
+	return e
+}
+
+func (uc *Archive) List(
+	ctx context.Context,
+	params *entity.ListArchiveJobsParams,
+) ([]*entity.ArchiveJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	entities, err := uc.repo.List(db, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entities {
+		withArchiveDownloadURL(e)
+	}
+	return entities, nil
+}
+
+func (uc *Archive) Get(
+	ctx context.Context,
+	params *entity.GetArchiveJobParams,
+) (*entity.ArchiveJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	e, err := uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+	if err != nil {
+		return nil, err
+	}
+	return withArchiveDownloadURL(e), nil
+}
+
+// Create queues the archive of a project, one at a time per project.
+func (uc *Archive) Create(
+	ctx context.Context,
+	params *entity.CreateArchiveJobParams,
+) (*entity.ArchiveJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ArchiveJob
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Open returns a completed archive job and its bundle, which the caller must close.
+func (uc *Archive) Open(
+	ctx context.Context,
+	params *entity.GetArchiveJobParams,
+) (*entity.ArchiveJob, *os.File, error) {
+	e, err := uc.Get(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if e.Status != entity.ArchiveCompleted {
+		return nil, nil, fmt.Errorf(
+			"%w: archive job with ID %d is %s", entity.ErrBadRequest, e.ID, e.Status,
+		)
+	}
+	f, err := uc.repo.Open(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, f, nil
+}
+
+// RunWorker runs the queued archive jobs every interval until ctx is done.
+func (uc *Archive) RunWorker(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.Process(ctx, lgr); err != nil {
+			lgr.Errorf("[Archive] failed to process archive jobs: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[Archive] finished %d archive jobs", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process fails the interrupted jobs, removes the expired bundles and runs the queued jobs
+// one by one. It returns the number of the jobs it ran.
+func (uc *Archive) Process(ctx context.Context, lgr entity.Logger) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	now := time.Now().UTC()
+	// the progress of a running job is recorded per section, each bounded by the timeout
+	if n, err := uc.repo.FailStale(db, now.Add(-uc.ArchiveTimeout-time.Minute)); err != nil {
+		return 0, err
+	} else if n > 0 {
+		lgr.Warnf("[Archive] failed %d interrupted archive jobs", n)
+	}
+	if _, err := uc.repo.Expire(db, now); err != nil {
+		return 0, err
+	}
+
+	var finished int
+	for ctx.Err() == nil {
+		claimCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		e, err := uc.repo.Claim(uc.repo.WithContext(claimCtx))
+		cancel()
+		if err != nil {
+			return finished, err
+		}
+		if e == nil {
+			break
+		}
+		if err := uc.run(ctx, lgr, e); err != nil {
+			lgr.Warnf("[Archive] failed to run archive job %d: %v", e.ID, err)
+		}
+		finished++
+	}
+	return finished, nil
+}
+
+// progress records the section the job starts, logging the failures which do not stop it.
+func (uc *Archive) progress(
+	ctx context.Context,
+	lgr entity.Logger,
+	e *entity.ArchiveJob,
+	step string,
+	done int64,
+) {
+	writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	if err := uc.repo.Progress(
+		uc.repo.WithContext(writeCtx), e.ID, step, e.Total, done,
+	); err != nil {
+		lgr.Warnf("[Archive] failed to record the progress of archive job %d: %v", e.ID, err)
+	}
+}
+
+// createArchiveFile creates a file of a section in dir and writes it with write.
+func createArchiveFile(dir string, name string, write func(w io.Writer) error) error {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// dump exports the sections of the project of a claimed job into dir and returns their
+// manifest, recording the progress per section.
+func (uc *Archive) dump(
+	ctx context.Context,
+	lgr entity.Logger,
+	e *entity.ArchiveJob,
+	dir string,
+) (*entity.ArchiveManifest, error) {
+	readCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	tables, err := uc.archiver.Tables(uc.archiver.WithContext(readCtx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	collections, err := uc.archiver.Collections(readCtx)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &entity.ArchiveManifest{
+		FormatVersion:   entity.ArchiveFormatVersion,
+		Project:         e.Project,
+		AttachmentsFile: "attachments.jsonl",
+		CreatedAtUTC:    time.Now().UTC(),
+		CreatedBy:       e.CreatedBy,
+	}
+	// the sections, the attachments and the bundle itself
+	e.Total = int64(len(tables)+len(collections)) + 2
+	if uc.archiver.HasGraph() {
+		e.Total++
+	}
+	var done int64
+
+	for _, t := range tables {
+		uc.progress(ctx, lgr, e, "mysql:"+t.Name, done)
+		t.File = "mysql/" + t.Name + ".sql"
+		dumpCtx, cancel := context.WithTimeout(ctx, uc.ArchiveTimeout)
+		err := createArchiveFile(dir, t.File, func(w io.Writer) error {
+			var err error
+			t.Rows, err = uc.archiver.DumpTable(uc.archiver.WithContext(dumpCtx), t, e.Project, w)
+			return err
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		manifest.Tables = append(manifest.Tables, t)
+		done++
+	}
+
+	for _, name := range collections {
+		uc.progress(ctx, lgr, e, "mongo:"+name, done)
+		c := &entity.ArchiveCollection{
+			Name: name,
+			File: "mongo/" + name + ".jsonl",
+		}
+		dumpCtx, cancel := context.WithTimeout(ctx, uc.ArchiveTimeout)
+		err := createArchiveFile(dir, c.File, func(w io.Writer) error {
+			var err error
+			c.Documents, err = uc.archiver.DumpCollection(dumpCtx, name, e.Project, w)
+			return err
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("collection %s: %w", name, err)
+		}
+		manifest.Collections = append(manifest.Collections, c)
+		done++
+	}
+
+	if uc.archiver.HasGraph() {
+		uc.progress(ctx, lgr, e, "neo4j", done)
+		g := &entity.ArchiveGraph{
+			NodesFile:         "neo4j/nodes.jsonl",
+			RelationshipsFile: "neo4j/relationships.jsonl",
+		}
+		dumpCtx, cancel := context.WithTimeout(ctx, uc.ArchiveTimeout)
+		err := createArchiveFile(dir, g.NodesFile, func(nodes io.Writer) error {
+			return createArchiveFile(dir, g.RelationshipsFile, func(rels io.Writer) error {
+				var err error
+				g.Nodes, g.Relationships, err = uc.archiver.DumpGraph(dumpCtx, e.Project, nodes, rels)
+				return err
+			})
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("graph: %w", err)
+		}
+		manifest.Graph = g
+		done++
+	} else {
+		lgr.Warnf("[Archive] archive job %d leaves the graph out, Neo4j is not configured", e.ID)
+	}
+
+	uc.progress(ctx, lgr, e, "attachments", done)
+	dumpCtx, cancel := context.WithTimeout(ctx, uc.ArchiveTimeout)
+	err = createArchiveFile(dir, manifest.AttachmentsFile, func(w io.Writer) error {
+		var err error
+		manifest.Attachments, err = uc.archiver.Attachments(
+			uc.archiver.WithContext(dumpCtx), e.Project, w,
+		)
+		return err
+	})
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("attachments: %w", err)
+	}
+	done++
+
+	uc.progress(ctx, lgr, e, "bundle", done)
+	return manifest, nil
+}
+
+// archiveFiles returns the files of the sections of a manifest, in the order of the bundle.
+func archiveFiles(m *entity.ArchiveManifest) []string {
+	var files []string
+	for _, t := range m.Tables {
+		files = append(files, t.File)
+	}
+	for _, c := range m.Collections {
+		files = append(files, c.File)
+	}
+	if m.Graph != nil {
+		files = append(files, m.Graph.NodesFile, m.Graph.RelationshipsFile)
+	}
+	return append(files, m.AttachmentsFile)
+}
+
+// writeArchiveBundle writes the manifest and the files of its sections in dir as a gzipped
+// tarball, the manifest first so that it is read without extracting the bundle.
+func writeArchiveBundle(w io.Writer, dir string, m *entity.ArchiveManifest) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    archiveManifestFile,
+		Mode:    0o644,
+		Size:    int64(len(b)),
+		ModTime: m.CreatedAtUTC,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return err
+	}
+	for _, name := range archiveFiles(m) {
+		if err := func() error {
+			f, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			if err := tw.WriteHeader(&tar.Header{
+				Name:    name,
+				Mode:    0o644,
+				Size:    info.Size(),
+				ModTime: m.CreatedAtUTC,
+			}); err != nil {
+				return err
+			}
+			_, err = io.Copy(tw, f)
+			return err
+		}(); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// run exports the project of a claimed job into its bundle and records the outcome.
+func (uc *Archive) run(ctx context.Context, lgr entity.Logger, e *entity.ArchiveJob) error {
+	runErr := func() error {
+		dir, err := uc.repo.WorkDir(e)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		manifest, err := uc.dump(ctx, lgr, e, dir)
+		if err != nil {
+			return err
+		}
+		e.Size, err = uc.repo.Store(e, func(w io.Writer) error {
+			return writeArchiveBundle(w, dir, manifest)
+		})
+		return err
+	}()
+
+	writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(writeCtx)
+	if runErr != nil {
+		if err := uc.repo.Fail(db, e.ID, runErr.Error()); err != nil {
+			return err
+		}
+		return runErr
+	}
+	return uc.repo.Complete(db, e.ID, e.Size, time.Now().UTC().Add(archiveJobRetention))
+}
+
+// ArchiveImport restores the bundles of the archive jobs, typically into a cold-storage
+// instance.
+type ArchiveImport struct {
+	archiver     *repository.Archiver
+	WriteTimeout time.Duration
+}
+
+func NewArchiveImport(
+	archiver *repository.Archiver,
+	writeTimeout time.Duration,
+) *ArchiveImport {
+	return &ArchiveImport{
+		archiver:     archiver,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+// extractArchiveBundle extracts a bundle into dir and returns its manifest.
+func extractArchiveBundle(r io.Reader, dir string) (*entity.ArchiveManifest, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	var manifest *entity.ArchiveManifest
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(h.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("%w: invalid entry %q in bundle", entity.ErrBadRequest, h.Name)
+		}
+		if name == archiveManifestFile {
+			manifest = &entity.ArchiveManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+			}
+			continue
+		}
+		if err := createArchiveFile(dir, name, func(w io.Writer) error {
+			_, err := io.Copy(w, tr)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: bundle without manifest", entity.ErrBadRequest)
+	}
+	return manifest, nil
+}
+
+// openArchiveFile opens a file of a section extracted into dir.
+func openArchiveFile(dir string, name string) (*os.File, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(path.Clean(name))))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s is missing from the bundle", entity.ErrBadRequest, name)
+	}
+	return f, err
+}
+
+// Import restores a bundle. The target is checked before anything is written: its tables
+// must have the columns of the archive, and none of its databases the project. The tables
+// are restored one transaction each, then the documents and the subgraph, so that a failed
+// import must be cleaned up before it is started over.
+func (uc *ArchiveImport) Import(
+	ctx context.Context,
+	lgr entity.Logger,
+	params *entity.ArchiveImportParams,
+) (*entity.ArchiveImportResult, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	f, err := os.Open(params.File)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dir, err := os.MkdirTemp("", "ppi-archive-import-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	manifest, err := extractArchiveBundle(f, dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > entity.ArchiveFormatVersion {
+		return nil, fmt.Errorf(
+			"%w: unsupported format version %d of the bundle",
+			entity.ErrBadRequest, manifest.FormatVersion,
+		)
+	}
+	lgr.Infof(
+		"bundle of project %s created at %s: %d tables, %d collections",
+		manifest.Project, manifest.CreatedAtUTC.Format(time.RFC3339),
+		len(manifest.Tables), len(manifest.Collections),
+	)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	restoreMongo := !params.SkipMongo && len(manifest.Collections) != 0
+	restoreGraph := !params.SkipGraph && manifest.Graph != nil
+	if err := uc.archiver.CheckTables(
+		uc.archiver.WithContext(timeoutCtx), manifest.Project, manifest.Tables,
+	); err != nil {
+		return nil, err
+	}
+	if restoreMongo {
+		for _, c := range manifest.Collections {
+			if err := uc.archiver.CheckCollection(timeoutCtx, c.Name, manifest.Project); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if restoreGraph {
+		if !uc.archiver.HasGraph() {
+			return nil, fmt.Errorf(
+				"%w: Neo4j is not configured, skip the graph to import without it", entity.ErrBadRequest,
+			)
+		}
+		if err := uc.archiver.CheckGraph(timeoutCtx, manifest.Project); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &entity.ArchiveImportResult{
+		Project:       manifest.Project,
+		FormatVersion: manifest.FormatVersion,
+		Rows:          map[string]int64{},
+		Documents:     map[string]int64{},
+		DryRun:        params.DryRun,
+	}
+	if params.DryRun {
+		for _, t := range manifest.Tables {
+			result.Rows[t.Name] = t.Rows
+		}
+		if restoreMongo {
+			for _, c := range manifest.Collections {
+				result.Documents[c.Name] = c.Documents
+			}
+		}
+		if restoreGraph {
+			result.Nodes = manifest.Graph.Nodes
+			result.Relationships = manifest.Graph.Relationships
+		}
+		return result, nil
+	}
+
+	for _, t := range manifest.Tables {
+		if err := func() error {
+			f, err := openArchiveFile(dir, t.File)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return uc.archiver.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+				_, err := uc.archiver.RestoreTable(tx, f)
+				return err
+			})
+		}(); err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		result.Rows[t.Name] = t.Rows
+		lgr.Infof("%d rows of %s restored", t.Rows, t.Name)
+	}
+
+	if restoreMongo {
+		for _, c := range manifest.Collections {
+			if err := func() error {
+				f, err := openArchiveFile(dir, c.File)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				result.Documents[c.Name], err = uc.archiver.RestoreCollection(timeoutCtx, c.Name, f)
+				return err
+			}(); err != nil {
+				return nil, fmt.Errorf("collection %s: %w", c.Name, err)
+			}
+			lgr.Infof("%d documents of %s restored", result.Documents[c.Name], c.Name)
+		}
+	}
+
+	if restoreGraph {
+		nodes, err := openArchiveFile(dir, manifest.Graph.NodesFile)
+		if err != nil {
+			return nil, err
+		}
+		defer nodes.Close()
+		rels, err := openArchiveFile(dir, manifest.Graph.RelationshipsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer rels.Close()
+		result.Nodes, result.Relationships, result.SkippedRelationships, err =
+			uc.archiver.RestoreGraph(timeoutCtx, nodes, rels)
+		if err != nil {
+			return nil, fmt.Errorf("graph: %w", err)
+		}
+		lgr.Infof(
+			"%d nodes and %d relationships restored, %d relationships to other projects skipped",
+			result.Nodes, result.Relationships, result.SkippedRelationships,
+		)
+	}
+	return result, nil
+}
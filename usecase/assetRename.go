@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// errAssetRenameDryRun rolls back the transaction of a dry run.
+var errAssetRenameDryRun = errors.New("dry run")
+
+type AssetRename struct {
+	repo         *repository.AssetRename
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewAssetRename(
+	repo *repository.AssetRename,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *AssetRename {
+	return &AssetRename{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *AssetRename) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *AssetRename) List(
+	ctx context.Context,
+	params *entity.ListAssetRenamesParams,
+) ([]*entity.AssetRename, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.List(db, params)
+}
+
+// Rename renames the asset in a single transaction and records it in the audit log. A dry run
+// renames it the same way and rolls the transaction back, so that it reports the exact changes.
+func (uc *AssetRename) Rename(
+	ctx context.Context,
+	params *entity.RenameAssetParams,
+) (*entity.AssetRename, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.AssetRename
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Rename(tx, params)
+		if err != nil {
+			return err
+		}
+		if params.DryRun {
+			return errAssetRenameDryRun
+		}
+		return uc.repo.CreateLog(tx, e)
+	}); err != nil && !errors.Is(err, errAssetRenameDryRun) {
+		return nil, err
+	}
+	return e, nil
+}
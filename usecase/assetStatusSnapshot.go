@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type AssetStatusSnapshot struct {
+	repo         *repository.AssetStatusSnapshot
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewAssetStatusSnapshot(
+	repo *repository.AssetStatusSnapshot,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *AssetStatusSnapshot {
+	return &AssetStatusSnapshot{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *AssetStatusSnapshot) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// Diff compares the phase statuses of the assets of a project between two dates.
+func (uc *AssetStatusSnapshot) Diff(
+	ctx context.Context,
+	params *entity.GetPivotDiffParams,
+) (*entity.PivotDiff, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.Diff(db, params)
+}
+
+// RunSnapshotter takes the snapshots of the day missing every interval until ctx is done, so
+// that every project is snapshotted once a day.
+func (uc *AssetStatusSnapshot) RunSnapshotter(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.Snapshot(ctx); err != nil {
+			lgr.Errorf("[Snapshot] failed to take asset status snapshots: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[Snapshot] took asset status snapshots of %d projects", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Snapshot takes the snapshots of the current UTC date of the projects which have none yet,
+// and returns their number.
+func (uc *AssetStatusSnapshot) Snapshot(ctx context.Context) (int, error) {
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	projects, err := uc.repo.ListUnsnapshotted(uc.repo.WithContext(timeoutCtx), date)
+	if err != nil {
+		return 0, err
+	}
+	for i, project := range projects {
+		if err := uc.take(ctx, project, date); err != nil {
+			return i, fmt.Errorf("project %s: %w", project, err)
+		}
+	}
+	return len(projects), nil
+}
+
+func (uc *AssetStatusSnapshot) take(ctx context.Context, project string, date time.Time) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		_, err := uc.repo.Take(tx, project, date)
+		return err
+	})
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type Auth struct {
+	repo         *repository.Auth
+	oidc         *repository.OIDC
+	roleRepo     *repository.Role
+	apiKeyRepo   *repository.APIKey
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func NewAuth(
+	repo *repository.Auth,
+	oidc *repository.OIDC,
+	roleRepo *repository.Role,
+	apiKeyRepo *repository.APIKey,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Auth {
+	return &Auth{
+		repo:         repo,
+		oidc:         oidc,
+		roleRepo:     roleRepo,
+		apiKeyRepo:   apiKeyRepo,
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+	}
+}
+
+func (uc *Auth) ParseHeaderToken(
+	ctx context.Context,
+	params *entity.StudioAuthParams,
+) (*entity.TokenInfo, error) {
+	tokenStr, err := uc.checkHeader(params.AuthHeader)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.ParseToken(db, tokenStr)
+}
+
+func (uc *Auth) ParseQueryToken(ctx context.Context, tokenStr string) (*entity.TokenInfo, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.ParseQueryToken(db, tokenStr)
+}
+
+func (uc *Auth) CheckProjectAccess(ctx context.Context, params *entity.ProjectAccessParams) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.CheckProjectAccess(db, params)
+}
+
+// GetTokenPermissions returns the scope of the token presented in the Authorization header,
+// or of the API key the request was authenticated with: the projects it has access to and the
+// permissions of its user in them. Tokens are not stored, so params.ID must be either the ID of
+// this token or entity.TokenCurrent, which is the only ID of an API key.
+func (uc *Auth) GetTokenPermissions(
+	ctx context.Context,
+	params *entity.GetTokenPermissionsParams,
+) (*entity.TokenScope, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+
+	scope := &entity.TokenScope{}
+	if params.APIKeyID != nil {
+		if params.ID != entity.TokenCurrent {
+			return nil, fmt.Errorf(
+				"%w: only the permissions of the current API key can be listed",
+				entity.ErrForbidden,
+			)
+		}
+		key, err := uc.apiKeyRepo.Get(db, &entity.GetAPIKeyParams{ID: *params.APIKeyID})
+		if err != nil {
+			return nil, err
+		}
+		scope.APIKey, scope.Studio, scope.User = key, key.Studio, key.Name
+	} else {
+		tokenStr, err := uc.checkHeader(params.AuthHeader)
+		if err != nil {
+			return nil, err
+		}
+		token, err := uc.repo.GetToken(db, tokenStr)
+		if err != nil {
+			return nil, err
+		}
+		if params.ID != entity.TokenCurrent && params.ID != token.ID {
+			return nil, fmt.Errorf(
+				"%w: only the permissions of the token in the %s header can be listed",
+				entity.ErrForbidden, entity.AuthHeader,
+			)
+		}
+		scope.Token, scope.Studio, scope.User = token, token.Studio, token.User
+	}
+
+	projects, err := uc.repo.ListStudioProjects(db, scope.Studio)
+	if err != nil {
+		return nil, err
+	}
+	if scope.APIKey != nil && len(scope.APIKey.Projects) != 0 {
+		projects = slices.DeleteFunc(projects, func(p string) bool {
+			return !slices.Contains(scope.APIKey.Projects, p)
+		})
+	}
+	scope.Projects = projects
+	scope.Enforced = map[string][]entity.Permission{}
+	for _, p := range projects {
+		enforced, err := uc.roleRepo.ProjectEnforced(db, p)
+		if err != nil {
+			return nil, err
+		}
+		if !enforced {
+			continue
+		}
+		var permissions []entity.Permission
+		if scope.User != "" {
+			if permissions, err = uc.roleRepo.UserPermissions(db, p, scope.User); err != nil {
+				return nil, err
+			}
+		}
+		scope.Enforced[p] = permissions
+	}
+	return scope, nil
+}
+
+// CreateNewToken issues a token of the studio, acting as the authenticated user when user is
+// not empty.
+func (uc *Auth) CreateNewToken(name string, user string) (string, error) {
+	return uc.repo.CreateNewToken(name, user)
+}
+
+func (uc *Auth) Login(ctx context.Context, params *entity.LoginParams) (string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.Login(db, params)
+}
+
+// OIDCLogin returns the URL of the OpenID Connect provider the user logs in at, and the state
+// to keep in their browser until the callback returns them to returnTo.
+func (uc *Auth) OIDCLogin(ctx context.Context, returnTo string) (string, string, error) {
+	if !uc.oidc.Enabled() {
+		return "", "", fmt.Errorf("%w: OIDC login is not configured", entity.ErrRecordNotFound)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	return uc.oidc.AuthURL(timeoutCtx, returnTo)
+}
+
+// OIDCCallback logs the user returned by the OpenID Connect provider in to their studio, and
+// returns the token of the studio, as Login does, with the path to return the user to. The
+// token carries the email of the user, whose roles are checked by the routes requiring a
+// permission.
+func (uc *Auth) OIDCCallback(
+	ctx context.Context,
+	params *entity.OIDCCallbackParams,
+) (string, string, error) {
+	if !uc.oidc.Enabled() {
+		return "", "", fmt.Errorf("%w: OIDC login is not configured", entity.ErrRecordNotFound)
+	}
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return "", "", fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	identity, returnTo, err := uc.oidc.Callback(timeoutCtx, params)
+	if err != nil {
+		return "", "", err
+	}
+	studio, err := uc.oidc.Studio(identity)
+	if err != nil {
+		return "", "", err
+	}
+	if err := uc.repo.CheckStudio(uc.repo.WithContext(timeoutCtx), studio); err != nil {
+		return "", "", err
+	}
+	token, err := uc.repo.CreateNewToken(studio, identity.Email)
+	if err != nil {
+		return "", "", err
+	}
+	return token, returnTo, nil
+}
+
+func (uc *Auth) checkHeader(tokenStr string) (string, error) {
+	if tokenStr == "" {
+		return "", entity.ErrUnauthorized
+	}
+	bearerToken := strings.Split(tokenStr, entity.Bearer+" ")
+	if len(bearerToken) == entity.BearerLength {
+		tokenStr = strings.TrimSpace(bearerToken[entity.TokenPos])
+	} else {
+		return "", entity.ErrUnauthorized
+	}
+	return tokenStr, nil
+}
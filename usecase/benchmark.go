@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type Benchmark struct {
+	repo *repository.Benchmark
+}
+
+func NewBenchmark(repo *repository.Benchmark) *Benchmark {
+	return &Benchmark{
+		repo: repo,
+	}
+}
+
+// Run measures the scenarios one after another, each with the given number of requests sent
+// by the given number of concurrent clients.
+func (uc *Benchmark) Run(
+	ctx context.Context,
+	lgr entity.Logger,
+	params *entity.BenchmarkParams,
+) (*entity.BenchmarkReport, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	scenarios := entity.DefaultBenchmarkScenarios(params.Project)
+	if len(params.Scenarios) != 0 {
+		var selected []*entity.BenchmarkScenario
+		for _, name := range params.Scenarios {
+			i := slices.IndexFunc(scenarios, func(s *entity.BenchmarkScenario) bool {
+				return s.Name == name
+			})
+			if i < 0 {
+				return nil, fmt.Errorf("%w: unknown scenario %q", entity.ErrBadRequest, name)
+			}
+			selected = append(selected, scenarios[i])
+		}
+		scenarios = selected
+	}
+
+	report := &entity.BenchmarkReport{
+		StartedAtUTC: time.Now().UTC(),
+		BaseURL:      params.BaseURL,
+		Project:      params.Project,
+		Iterations:   params.Iterations,
+		Concurrency:  params.Concurrency,
+	}
+	for _, s := range scenarios {
+		for i := 0; i < params.Warmup; i++ {
+			if _, err := uc.repo.Request(ctx, params.BaseURL, s.Path); err != nil {
+				return nil, fmt.Errorf("warmup of %s failed: %w", s.Name, err)
+			}
+		}
+		stats := uc.measure(ctx, lgr, params, s)
+		lgr.Infof(
+			"[Benchmark] %s: p50=%.1fms p95=%.1fms errors=%d",
+			s.Name, stats.P50Ms, stats.P95Ms, stats.Errors,
+		)
+		report.Stats = append(report.Stats, stats)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func (uc *Benchmark) measure(
+	ctx context.Context,
+	lgr entity.Logger,
+	params *entity.BenchmarkParams,
+	s *entity.BenchmarkScenario,
+) *entity.BenchmarkStats {
+	jobs := make(chan struct{})
+	var mu sync.Mutex
+	var latencies []float64
+	errs := 0
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < params.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				d, err := uc.repo.Request(ctx, params.BaseURL, s.Path)
+				mu.Lock()
+				if err != nil {
+					errs++
+					lgr.Warnf("[Benchmark] %s: %v", s.Name, err)
+				} else {
+					latencies = append(latencies, float64(d)/float64(time.Millisecond))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < params.Iterations; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	stats := &entity.BenchmarkStats{
+		Name:       s.Name,
+		Requests:   params.Iterations,
+		Errors:     errs,
+		Throughput: float64(len(latencies)) / elapsed.Seconds(),
+	}
+	if len(latencies) == 0 {
+		return stats
+	}
+	slices.Sort(latencies)
+	var sum float64
+	for _, l := range latencies {
+		sum += l
+	}
+	stats.MeanMs = sum / float64(len(latencies))
+	stats.P50Ms = percentile(latencies, 0.5)
+	stats.P95Ms = percentile(latencies, 0.95)
+	stats.P99Ms = percentile(latencies, 0.99)
+	stats.MaxMs = latencies[len(latencies)-1]
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"expvar"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+)
+
+const (
+	// capacityBusyLoad and capacitySaturatedLoad are the loads from which the API is busy
+	// and saturated.
+	capacityBusyLoad      = 0.7
+	capacitySaturatedLoad = 0.9
+	// capacityMaxRetryAfter is the delay advised at full load.
+	capacityMaxRetryAfter = 60 * time.Second
+)
+
+// inFlightMetric exports the requests being served with the other expvar variables.
+var inFlightMetric = expvar.NewInt("http_in_flight")
+
+type Capacity struct {
+	repo     *repository.Capacity
+	inFlight atomic.Int64
+}
+
+func NewCapacity(repo *repository.Capacity) *Capacity {
+	return &Capacity{
+		repo: repo,
+	}
+}
+
+// Enter counts a request being served until Leave is called.
+func (uc *Capacity) Enter() {
+	uc.inFlight.Add(1)
+	inFlightMetric.Add(1)
+}
+
+func (uc *Capacity) Leave() {
+	uc.inFlight.Add(-1)
+	inFlightMetric.Add(-1)
+}
+
+// Get reports the current load. The advised delay grows linearly from the busy load up to
+// capacityMaxRetryAfter.
+func (uc *Capacity) Get() (*entity.Capacity, error) {
+	pool, err := uc.repo.DBPool()
+	if err != nil {
+		return nil, err
+	}
+	c := &entity.Capacity{
+		InFlight:     uc.inFlight.Load(),
+		MaxInFlight:  uc.repo.MaxInFlight(),
+		DBPool:       *pool,
+		Level:        entity.CapacityLevelOK,
+		CheckedAtUTC: time.Now().UTC(),
+	}
+	c.Load = math.Max(float64(c.InFlight)/float64(c.MaxInFlight), pool.Saturation)
+	if c.Load < capacityBusyLoad {
+		return c, nil
+	}
+	c.Level = entity.CapacityLevelBusy
+	if c.Load >= capacitySaturatedLoad {
+		c.Level = entity.CapacityLevelSaturated
+	}
+	ratio := math.Min((c.Load-capacityBusyLoad)/(1-capacityBusyLoad), 1)
+	c.RetryAfterSeconds = int(math.Ceil(ratio * capacityMaxRetryAfter.Seconds()))
+	if c.RetryAfterSeconds < 1 {
+		c.RetryAfterSeconds = 1
+	}
+	return c, nil
+}
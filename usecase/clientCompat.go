@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type ClientCompat struct {
+	repo         *repository.ClientCompat
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewClientCompat(
+	repo *repository.ClientCompat,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ClientCompat {
+	return &ClientCompat{
+		repo:         repo,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+// clientVersion is a version of a desktop tool like "2.3.1", "v2.3" or "2.3.1-beta.2". Its
+// build metadata after "+" is ignored.
+type clientVersion struct {
+	numbers    []int
+	prerelease string
+}
+
+func parseClientVersion(s string) (*clientVersion, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, prerelease, _ := strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) > 4 {
+		return nil, fmt.Errorf("%w: invalid version %q", entity.ErrBadRequest, s)
+	}
+	numbers := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: invalid version %q", entity.ErrBadRequest, s)
+		}
+		numbers[i] = n
+	}
+	return &clientVersion{numbers: numbers, prerelease: prerelease}, nil
+}
+
+// compare returns -1, 0 or 1 when v is older than, the same as or newer than o. Missing
+// numbers are 0, and a prerelease is older than its release.
+func (v *clientVersion) compare(o *clientVersion) int {
+	for i := 0; i < len(v.numbers) || i < len(o.numbers); i++ {
+		var a, b int
+		if i < len(v.numbers) {
+			a = v.numbers[i]
+		}
+		if i < len(o.numbers) {
+			b = o.numbers[i]
+		}
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.prerelease == o.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case o.prerelease == "":
+		return -1
+	case v.prerelease < o.prerelease:
+		return -1
+	}
+	return 1
+}
+
+// Get tells whether a version of a desktop tool is supported, deprecated or blocked.
+func (uc *ClientCompat) Get(
+	ctx context.Context,
+	params *entity.GetClientCompatParams,
+) (*entity.ClientCompat, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	version, err := parseClientVersion(params.Version)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	rule, err := uc.repo.Get(uc.repo.WithContext(timeoutCtx), params.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	status := entity.ClientCompatSupported
+	minVersion, err := parseClientVersion(rule.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	if version.compare(minVersion) < 0 {
+		status = entity.ClientCompatBlocked
+	} else if rule.DeprecatedBelow != nil {
+		deprecatedBelow, err := parseClientVersion(*rule.DeprecatedBelow)
+		if err != nil {
+			return nil, err
+		}
+		if version.compare(deprecatedBelow) < 0 {
+			status = entity.ClientCompatDeprecated
+		}
+	}
+	return &entity.ClientCompat{
+		Client:          rule.Client,
+		Version:         params.Version,
+		Status:          status,
+		MinVersion:      rule.MinVersion,
+		DeprecatedBelow: rule.DeprecatedBelow,
+		LatestVersion:   rule.LatestVersion,
+		ChangelogURL:    rule.ChangelogURL,
+		Message:         rule.Message,
+	}, nil
+}
+
+func (uc *ClientCompat) ListRules(ctx context.Context) ([]*entity.ClientCompatRule, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.List(uc.repo.WithContext(timeoutCtx))
+}
+
+// UpdateRule creates or replaces the row of a desktop tool, whose versions must be ordered
+// from MinVersion to DeprecatedBelow to LatestVersion.
+func (uc *ClientCompat) UpdateRule(
+	ctx context.Context,
+	params *entity.UpdateClientCompatRuleParams,
+) (*entity.ClientCompatRule, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	versions := []*string{&params.MinVersion, params.DeprecatedBelow, params.LatestVersion}
+	var previous *clientVersion
+	for _, s := range versions {
+		if s == nil {
+			continue
+		}
+		v, err := parseClientVersion(*s)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil && v.compare(previous) < 0 {
+			return nil, fmt.Errorf(
+				"%w: min_version, deprecated_below and latest_version must be in ascending order",
+				entity.ErrBadRequest,
+			)
+		}
+		previous = v
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ClientCompatRule
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *ClientCompat) DeleteRule(
+	ctx context.Context,
+	params *entity.DeleteClientCompatRuleParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.Delete(tx, params)
+	})
+}
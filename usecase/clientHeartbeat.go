@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// clientAdoptionDefaultDays is the range of the adoption reports without dates.
+const clientAdoptionDefaultDays = 30
+
+type ClientHeartbeat struct {
+	repo         *repository.ClientHeartbeat
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewClientHeartbeat(
+	repo *repository.ClientHeartbeat,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ClientHeartbeat {
+	return &ClientHeartbeat{
+		repo:         repo,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *ClientHeartbeat) Record(
+	ctx context.Context,
+	params *entity.RecordClientHeartbeatParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.Record(uc.repo.WithContext(timeoutCtx), params)
+}
+
+// ListConnected returns the clients which sent a heartbeat lately and the number of hosts and
+// studios running each of their versions.
+func (uc *ClientHeartbeat) ListConnected(
+	ctx context.Context,
+	params *entity.ListConnectedClientsParams,
+) (*entity.ConnectedClients, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	within := params.Within
+	if within == 0 {
+		within = entity.DefaultConnectedClientsWithin
+	}
+	if within < 0 || within > entity.MaxConnectedClientsWithin {
+		return nil, fmt.Errorf(
+			"%w: the duration must be positive and at most %s",
+			entity.ErrBadRequest, entity.MaxConnectedClientsWithin,
+		)
+	}
+	since := time.Now().UTC().Add(-within)
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	clients, err := uc.repo.ListConnected(uc.repo.WithContext(timeoutCtx), params, since)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ConnectedClients{
+		Clients:  clients,
+		Versions: countClientVersions(clients),
+		SinceUTC: since,
+	}, nil
+}
+
+// countClientVersions counts the distinct hosts and studios per version of the clients,
+// ordered by client and version.
+func countClientVersions(clients []*entity.ClientHeartbeat) []*entity.ClientVersionCount {
+	type key struct{ client, version string }
+	hosts := map[key]map[string]bool{}
+	studios := map[key]map[string]bool{}
+	for _, e := range clients {
+		k := key{e.Client, e.Version}
+		if hosts[k] == nil {
+			hosts[k] = map[string]bool{}
+			studios[k] = map[string]bool{}
+		}
+		hosts[k][e.Host] = true
+		studios[k][e.Studio] = true
+	}
+	counts := make([]*entity.ClientVersionCount, 0, len(hosts))
+	for k := range hosts {
+		counts = append(counts, &entity.ClientVersionCount{
+			Client:  k.client,
+			Version: k.version,
+			Hosts:   len(hosts[k]),
+			Studios: len(studios[k]),
+		})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Client != counts[j].Client {
+			return counts[i].Client < counts[j].Client
+		}
+		return counts[i].Version < counts[j].Version
+	})
+	return counts
+}
+
+// Adoption reports the adoption of the versions of the clients per day.
+func (uc *ClientHeartbeat) Adoption(
+	ctx context.Context,
+	params *entity.ListClientAdoptionParams,
+) ([]*entity.ClientAdoption, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if params.To != nil {
+		to = params.To.UTC().Truncate(24 * time.Hour)
+	}
+	from := to.AddDate(0, 0, 1-clientAdoptionDefaultDays)
+	if params.From != nil {
+		from = params.From.UTC().Truncate(24 * time.Hour)
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from must not be after to", entity.ErrBadRequest)
+	}
+	if to.Sub(from) >= entity.MaxClientAdoptionDays*24*time.Hour {
+		return nil, fmt.Errorf(
+			"%w: the range must be at most %d days",
+			entity.ErrBadRequest, entity.MaxClientAdoptionDays,
+		)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Adoption(uc.repo.WithContext(timeoutCtx), params, from, to)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// complianceMaxRange limits the period a compliance report covers.
+const complianceMaxRange = 366 * 24 * time.Hour
+
+type Compliance struct {
+	repo         *repository.Compliance
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewCompliance(
+	repo *repository.Compliance,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Compliance {
+	return &Compliance{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *Compliance) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// GetReport reports the data shared with each external studio of the project over the period,
+// or with the given one only.
+func (uc *Compliance) GetReport(
+	ctx context.Context,
+	params *entity.GetComplianceReportParams,
+) (*entity.ComplianceReport, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if params.To.Sub(params.From) > complianceMaxRange {
+		return nil, fmt.Errorf(
+			"%w: the report can not cover more than %d days",
+			entity.ErrBadRequest, complianceMaxRange/(24*time.Hour),
+		)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	studios, err := uc.repo.ExternalStudios(db, params.Project)
+	if err != nil {
+		return nil, err
+	}
+	if params.Studio != nil {
+		found := false
+		for _, s := range studios {
+			if s == *params.Studio {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf(
+				"%w: %q is not an external studio of the project",
+				entity.ErrBadRequest, *params.Studio,
+			)
+		}
+		studios = []string{*params.Studio}
+	}
+	transfers, err := uc.repo.ListTransfers(db, params, studios)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]*entity.StudioTransfers, len(studios))
+	report := &entity.ComplianceReport{
+		Project:        params.Project,
+		From:           params.From.Format("2006-01-02"),
+		To:             params.To.Format("2006-01-02"),
+		Studios:        make([]*entity.StudioTransfers, len(studios)),
+		Transfers:      transfers,
+		GeneratedAtUTC: time.Now().UTC(),
+	}
+	for i, s := range studios {
+		report.Studios[i] = &entity.StudioTransfers{Studio: s}
+		summaries[s] = report.Studios[i]
+	}
+	for _, t := range transfers {
+		s, ok := summaries[t.Studio]
+		if !ok {
+			continue
+		}
+		switch t.Kind {
+		case entity.TransferPublish:
+			s.Publishes++
+		case entity.TransferDirectoryDeletion:
+			s.DirectoryDeletions++
+		case entity.TransferAttachmentDownload:
+			s.AttachmentDownloads++
+		}
+	}
+	return report, nil
+}
+
+// RecordTransfer records a transfer to a studio in the audit log of the compliance report.
+func (uc *Compliance) RecordTransfer(
+	ctx context.Context,
+	params *entity.RecordTransferParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.RecordTransfer(uc.repo.WithContext(timeoutCtx), params)
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+)
+
+type Consistency struct {
+	repo        *repository.Consistency
+	ReadTimeout time.Duration
+}
+
+func NewConsistency(repo *repository.Consistency, readTimeout time.Duration) *Consistency {
+	return &Consistency{
+		repo:        repo,
+		ReadTimeout: readTimeout,
+	}
+}
+
+// Token returns the consistency token to be returned by a write request once it is committed.
+func (uc *Consistency) Token(ctx context.Context) *entity.ConsistencyToken {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Token(timeoutCtx)
+}
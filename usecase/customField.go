@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type CustomField struct {
+	repo         *repository.CustomField
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewCustomField(
+	repo *repository.CustomField,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *CustomField {
+	return &CustomField{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *CustomField) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *CustomField) List(
+	ctx context.Context,
+	params *entity.ListCustomFieldDefinitionsParams,
+) ([]*entity.CustomFieldDefinition, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *CustomField) Create(
+	ctx context.Context,
+	params *entity.CreateCustomFieldDefinitionParams,
+) (*entity.CustomFieldDefinition, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.CustomFieldDefinition
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *CustomField) Update(
+	ctx context.Context,
+	params *entity.UpdateCustomFieldDefinitionParams,
+) (*entity.CustomFieldDefinition, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.CustomFieldDefinition
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *CustomField) Delete(
+	ctx context.Context,
+	params *entity.DeleteCustomFieldDefinitionParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+func (uc *CustomField) GetAssetMetadata(
+	ctx context.Context,
+	params *entity.GetAssetMetadataParams,
+) (*entity.AssetMetadata, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetAssetMetadata(db, params)
+}
+
+// UpdateAssetMetadata merges the changed values into the asset's values after validating them
+// against the project's asset field definitions.
+func (uc *CustomField) UpdateAssetMetadata(
+	ctx context.Context,
+	params *entity.UpdateAssetMetadataParams,
+) (*entity.AssetMetadata, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var modifiedBy string
+	if params.ModifiedBy != nil {
+		modifiedBy = *params.ModifiedBy
+	}
+	var e *entity.AssetMetadata
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		defs, err := uc.repo.ListDefinitions(tx, params.Project, entity.CustomFieldTargetAsset)
+		if err != nil {
+			return err
+		}
+		current, err := uc.repo.GetAssetMetadata(tx, &entity.GetAssetMetadataParams{
+			Project:  params.Project,
+			Asset:    params.Asset,
+			Relation: params.Relation,
+		})
+		if err != nil {
+			return err
+		}
+		metadata, err := entity.MergeCustomMetadata(defs, current.Metadata, params.Metadata)
+		if err != nil {
+			return err
+		}
+		e, err = uc.repo.UpdateAssetMetadata(
+			tx, params.Project, params.Asset, params.Relation, metadata, modifiedBy,
+		)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Delegation struct {
+	repo         *repository.Delegation
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewDelegation(
+	repo *repository.Delegation,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Delegation {
+	return &Delegation{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *Delegation) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *Delegation) List(
+	ctx context.Context,
+	params *entity.ListDelegationsParams,
+) ([]*entity.Delegation, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.List(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Delegation) Get(
+	ctx context.Context,
+	params *entity.GetDelegationParams,
+) (*entity.Delegation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Delegation) Create(
+	ctx context.Context,
+	params *entity.CreateDelegationParams,
+) (*entity.Delegation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if !params.EndsAtUTC.After(time.Now().UTC()) {
+		return nil, fmt.Errorf("%w: the delegation has already ended", entity.ErrBadRequest)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Delegation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if params.Project != nil {
+			if err := uc.checkForProject(tx, *params.Project); err != nil {
+				return err
+			}
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Delegation) Revoke(
+	ctx context.Context,
+	params *entity.RevokeDelegationParams,
+) (*entity.Delegation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Delegation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Revoke(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RunExpiry marks the delegations whose window has ended as expired every interval until ctx
+// is done. Approvals never rely on it, as they check the window of the delegation.
+func (uc *Delegation) RunExpiry(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		n, err := uc.repo.ExpireEnded(uc.repo.WithContext(timeoutCtx), time.Now().UTC())
+		cancel()
+		if err != nil {
+			lgr.Errorf("[Delegation] failed to expire delegations: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[Delegation] expired %d delegations", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+var directoryTokenPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+type DirectoryTemplate struct {
+	repo              *repository.DirectoryTemplate
+	prjRepo           *repository.ProjectInfo
+	phaseTemplateRepo *repository.PhaseTemplate
+	dirUc             *Directory
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+}
+
+func NewDirectoryTemplate(
+	repo *repository.DirectoryTemplate,
+	pr *repository.ProjectInfo,
+	ptr *repository.PhaseTemplate,
+	dirUc *Directory,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *DirectoryTemplate {
+	return &DirectoryTemplate{
+		repo:              repo,
+		prjRepo:           pr,
+		phaseTemplateRepo: ptr,
+		dirUc:             dirUc,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+	}
+}
+
+func (uc *DirectoryTemplate) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// checkForPatterns checks that the patterns are relative paths only using known tokens.
+func checkForPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/") {
+			return fmt.Errorf(
+				"%w: pattern must not start or end with a slash: %q", entity.ErrBadRequest, p,
+			)
+		}
+		for _, part := range strings.Split(p, "/") {
+			if part == "" || part == "." || part == ".." {
+				return fmt.Errorf("%w: invalid pattern %q", entity.ErrBadRequest, p)
+			}
+		}
+		for _, token := range directoryTokenPattern.FindAllString(p, -1) {
+			switch token {
+			case entity.DirectoryTokenProject,
+				entity.DirectoryTokenAsset,
+				entity.DirectoryTokenRelation,
+				entity.DirectoryTokenPhase:
+			default:
+				return fmt.Errorf(
+					"%w: unknown token %s in pattern %q", entity.ErrBadRequest, token, p,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *DirectoryTemplate) List(
+	ctx context.Context,
+	params *entity.ListDirectoryTemplatesParams,
+) ([]*entity.DirectoryTemplate, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.List(db, params.Project, nil)
+}
+
+func (uc *DirectoryTemplate) Create(
+	ctx context.Context,
+	params *entity.CreateDirectoryTemplateParams,
+) (*entity.DirectoryTemplate, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	if err := checkForPatterns(params.Patterns); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.DirectoryTemplate
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *DirectoryTemplate) Update(
+	ctx context.Context,
+	params *entity.UpdateDirectoryTemplateParams,
+) (*entity.DirectoryTemplate, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	if err := checkForPatterns(params.Patterns); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.DirectoryTemplate
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *DirectoryTemplate) Delete(
+	ctx context.Context,
+	params *entity.DeleteDirectoryTemplateParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+// expandPatterns replaces the tokens of the patterns and returns the resulting paths, without
+// duplicates and in the order they first appear.
+func expandPatterns(
+	templates []*entity.DirectoryTemplate,
+	params *entity.ScaffoldAssetParams,
+	phases []string,
+) ([]string, error) {
+	var relation string
+	if params.Relation != nil {
+		relation = *params.Relation
+	}
+	r := strings.NewReplacer(
+		entity.DirectoryTokenProject, params.Project,
+		entity.DirectoryTokenAsset, params.Asset,
+		entity.DirectoryTokenRelation, relation,
+	)
+	var paths []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	for _, t := range templates {
+		for _, p := range t.Patterns {
+			if relation == "" && strings.Contains(p, entity.DirectoryTokenRelation) {
+				return nil, fmt.Errorf(
+					"%w: relation is required by pattern %q of directory template %q",
+					entity.ErrBadRequest, p, t.Name,
+				)
+			}
+			p = r.Replace(p)
+			if !strings.Contains(p, entity.DirectoryTokenPhase) {
+				add(p)
+				continue
+			}
+			for _, phase := range phases {
+				add(strings.ReplaceAll(p, entity.DirectoryTokenPhase, phase))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// ScaffoldAsset creates the directories of the templates for the asset through the Directory
+// usecase. Nothing is created on a dry run, the result then listing what would be created.
+func (uc *DirectoryTemplate) ScaffoldAsset(
+	ctx context.Context,
+	params *entity.ScaffoldAssetParams,
+) (*entity.ScaffoldResult, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	templates, err := uc.repo.List(db, params.Project, params.TemplateIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf(
+			"%w: no directory template for project %q", entity.ErrBadRequest, params.Project,
+		)
+	}
+	phases := params.Phases
+	if len(phases) == 0 {
+		pt, err := uc.phaseTemplateRepo.Get(db, &entity.GetPhaseTemplateParams{
+			Project: params.Project,
+			Root:    "assets",
+		})
+		if err != nil {
+			return nil, err
+		}
+		phases = pt.Phases
+	}
+	paths, err := expandPatterns(templates, params, phases)
+	if err != nil {
+		return nil, err
+	}
+	cancel()
+
+	// Parent directories are created together with their children, so only the paths which
+	// are not a parent of another one need to be passed to the Directory usecase.
+	var candidates []string
+	seen := map[string]bool{}
+	for _, path := range paths {
+		parts := strings.Split(path, "/")
+		for i := range parts {
+			p := strings.Join(parts[:i+1], "/")
+			if !seen[p] {
+				seen[p] = true
+				candidates = append(candidates, p)
+			}
+		}
+	}
+	dirs, _, err := uc.dirUc.List(ctx, &entity.ListDirectoryParams{
+		Project: params.Project,
+		Paths:   candidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, dir := range dirs {
+		existing[strings.ToLower(dir.Path)] = true
+	}
+
+	result := &entity.ScaffoldResult{
+		DryRun:   params.DryRun,
+		Created:  []string{},
+		Existing: []string{},
+	}
+	for _, p := range candidates {
+		if existing[strings.ToLower(p)] {
+			result.Existing = append(result.Existing, p)
+		} else if params.DryRun {
+			result.Created = append(result.Created, p)
+		}
+	}
+	if params.DryRun {
+		return result, nil
+	}
+
+	// Directories created as the parents of a previous path are marked as existing too.
+	for _, path := range paths {
+		if existing[strings.ToLower(path)] {
+			continue
+		}
+		created, err := uc.dirUc.Create(ctx, &entity.CreateDirectoryParams{
+			Project:   params.Project,
+			Path:      path,
+			CreatedBy: params.CreatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range created {
+			if dir != nil {
+				existing[strings.ToLower(dir.Path)] = true
+				result.Created = append(result.Created, dir.Path)
+			}
+		}
+	}
+	sort.Strings(result.Created)
+	return result, nil
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// exportJobRetention is how long the documents of the export jobs are kept.
+const exportJobRetention = 24 * time.Hour
+
+type ExportJob struct {
+	repo     *repository.ExportJob
+	prjRepo  *repository.ProjectInfo
+	renderer entity.ExportRenderer
+	// ExportTimeout bounds the generation of a document.
+	ExportTimeout time.Duration
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+}
+
+func NewExportJob(
+	repo *repository.ExportJob,
+	pr *repository.ProjectInfo,
+	renderer entity.ExportRenderer,
+	exportTimeout time.Duration,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ExportJob {
+	return &ExportJob{
+		repo:          repo,
+		prjRepo:       pr,
+		renderer:      renderer,
+		ExportTimeout: exportTimeout,
+		ReadTimeout:   readTimeout,
+		WriteTimeout:  writeTimeout,
+	}
+}
+
+func (uc *ExportJob) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// withDownloadURL sets the URL the document of a completed job is downloaded from.
+func withDownloadURL(e *entity.ExportJob) *entity.ExportJob {
+	if e.Status == entity.ExportCompleted {
+		e.DownloadURL = entity.PublicPath(
+			fmt.Sprintf("/api/projects/%s/exports/%d/download", e.Project, e.ID),
+		)
+	}
+	return e
+}
+
+func (uc *ExportJob) List(
+	ctx context.Context,
+	params *entity.ListExportJobsParams,
+) ([]*entity.ExportJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	entities, err := uc.repo.List(db, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entities {
+		withDownloadURL(e)
+	}
+	return entities, nil
+}
+
+func (uc *ExportJob) Get(
+	ctx context.Context,
+	params *entity.GetExportJobParams,
+) (*entity.ExportJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	e, err := uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+	if err != nil {
+		return nil, err
+	}
+	return withDownloadURL(e), nil
+}
+
+// Create queues an export job. The pivot query of the pivot exports is parsed by the caller
+// as for the pivot API.
+func (uc *ExportJob) Create(
+	ctx context.Context,
+	params *entity.CreateExportJobParams,
+	query repository.ListAssetsPivotParams,
+) (*entity.ExportJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	var data []byte
+	if params.Kind == entity.ExportKindPivot {
+		var err error
+		if data, err = json.Marshal(query); err != nil {
+			return nil, err
+		}
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ExportJob
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params, data)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Open returns a completed export job and its document, which the caller must close.
+func (uc *ExportJob) Open(
+	ctx context.Context,
+	params *entity.GetExportJobParams,
+) (*entity.ExportJob, *os.File, error) {
+	e, err := uc.Get(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if e.Status != entity.ExportCompleted {
+		return nil, nil, fmt.Errorf(
+			"%w: export job with ID %d is %s", entity.ErrBadRequest, e.ID, e.Status,
+		)
+	}
+	f, err := uc.repo.Open(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, f, nil
+}
+
+// Workers returns the number of workers to run per instance.
+func (uc *ExportJob) Workers() int {
+	return uc.repo.Workers()
+}
+
+// RunWorker runs the queued export jobs every interval until ctx is done. Several workers
+// may run at once, each job being claimed by a single one.
+func (uc *ExportJob) RunWorker(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.Process(ctx, lgr); err != nil {
+			lgr.Errorf("[ExportJob] failed to process export jobs: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[ExportJob] finished %d export jobs", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process fails the interrupted jobs, removes the expired documents and runs the queued jobs
+// one by one. It returns the number of the jobs it ran.
+func (uc *ExportJob) Process(ctx context.Context, lgr entity.Logger) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	now := time.Now().UTC()
+	// a job running longer than its timeout was left by a stopped worker
+	if n, err := uc.repo.FailStale(db, now.Add(-uc.ExportTimeout-time.Minute)); err != nil {
+		return 0, err
+	} else if n > 0 {
+		lgr.Warnf("[ExportJob] failed %d interrupted export jobs", n)
+	}
+	if _, err := uc.repo.Expire(db, now); err != nil {
+		return 0, err
+	}
+
+	var finished int
+	for ctx.Err() == nil {
+		claimCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		e, err := uc.repo.Claim(uc.repo.WithContext(claimCtx))
+		cancel()
+		if err != nil {
+			return finished, err
+		}
+		if e == nil {
+			break
+		}
+		if err := uc.run(ctx, e); err != nil {
+			lgr.Warnf("[ExportJob] failed to run export job %d: %v", e.ID, err)
+		}
+		finished++
+	}
+	return finished, nil
+}
+
+// run generates the document of a claimed job and records the outcome.
+func (uc *ExportJob) run(ctx context.Context, e *entity.ExportJob) error {
+	renderCtx, cancel := context.WithTimeout(ctx, uc.ExportTimeout)
+	size, renderErr := uc.repo.Store(e, func(w io.Writer) error {
+		return uc.renderer.RenderExport(renderCtx, e, w)
+	})
+	cancel()
+
+	writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(writeCtx)
+	if renderErr != nil {
+		if err := uc.repo.Fail(db, e.ID, renderErr.Error()); err != nil {
+			return err
+		}
+		return renderErr
+	}
+	return uc.repo.Complete(db, e.ID, size, time.Now().UTC().Add(exportJobRetention))
+}
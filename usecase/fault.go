@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type Fault struct {
+	repo *repository.FaultInjector
+}
+
+func NewFault(repo *repository.FaultInjector) *Fault {
+	return &Fault{
+		repo: repo,
+	}
+}
+
+func (uc *Fault) List() []*entity.Fault {
+	return uc.repo.List()
+}
+
+func (uc *Fault) Set(params *entity.SetFaultParams) (*entity.Fault, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	return uc.repo.Set(params), nil
+}
+
+func (uc *Fault) Clear(params *entity.ClearFaultParams) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	return uc.repo.Clear(params.Dependency)
+}
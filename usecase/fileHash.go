@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type FileHash struct {
+	repo         *repository.FileHash
+	reviewRepo   *repository.ReviewInfo
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewFileHash(
+	repo *repository.FileHash,
+	rr *repository.ReviewInfo,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *FileHash {
+	return &FileHash{
+		repo:         repo,
+		reviewRepo:   rr,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *FileHash) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// manifestSizes returns the size of each file of the AllFiles manifests of the review by path.
+func manifestSizes(review *entity.ReviewInfo) map[string]uint64 {
+	sizes := map[string]uint64{}
+	for _, contents := range []entity.Contents{review.ReviewTarget, review.ReviewData} {
+		for _, c := range contents {
+			for _, f := range c.AllFiles {
+				sizes[f.Path] = f.Size
+			}
+		}
+	}
+	return sizes
+}
+
+// Register records the content hashes of files of the AllFiles manifests of a review.
+func (uc *FileHash) Register(
+	ctx context.Context,
+	params *entity.RegisterFileHashesParams,
+) ([]*entity.FileHash, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	seen := make(map[string]bool, len(params.Files))
+	for _, f := range params.Files {
+		if seen[f.Path] {
+			return nil, fmt.Errorf("%w: duplicate path %q", entity.ErrBadRequest, f.Path)
+		}
+		seen[f.Path] = true
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var entities []*entity.FileHash
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		review, err := uc.reviewRepo.Get(tx, &entity.GetReviewParams{
+			Project: params.Project,
+			ID:      params.ReviewInfoID,
+		})
+		if err != nil {
+			return err
+		}
+		sizes := manifestSizes(review)
+		for _, f := range params.Files {
+			if _, ok := sizes[f.Path]; !ok {
+				return fmt.Errorf(
+					"%w: %q is not in the manifests of review info with ID %d",
+					entity.ErrBadRequest, f.Path, params.ReviewInfoID,
+				)
+			}
+		}
+		entities, err = uc.repo.Register(tx, params, sizes)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (uc *FileHash) ListByHash(
+	ctx context.Context,
+	params *entity.ListFilesByHashParams,
+) ([]*entity.FileHash, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListByHash(db, params)
+}
+
+func (uc *FileHash) DuplicateReport(
+	ctx context.Context,
+	params *entity.GetDuplicateReportParams,
+) (*entity.DuplicateReport, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.DuplicateReport(db, params)
+}
+
+// NegotiateUpload tells which files of an upload manifest must be transferred, and which have
+// a content the project already has and may be copied from there. A content whose registered
+// size differs from the given size is transferred.
+func (uc *FileHash) NegotiateUpload(
+	ctx context.Context,
+	params *entity.NegotiateUploadParams,
+) (*entity.UploadNegotiation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	seen := make(map[string]bool, len(params.Files))
+	var hashes []string
+	hashSeen := make(map[string]bool, len(params.Files))
+	for _, f := range params.Files {
+		if seen[f.Path] {
+			return nil, fmt.Errorf("%w: duplicate path %q", entity.ErrBadRequest, f.Path)
+		}
+		seen[f.Path] = true
+		if !hashSeen[f.Hash] {
+			hashSeen[f.Hash] = true
+			hashes = append(hashes, f.Hash)
+		}
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	sources, err := uc.repo.FindSources(db, params.Project, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entity.UploadNegotiation{
+		Transfer: []*entity.UploadManifestEntry{},
+		Reuse:    []*entity.ReusedFile{},
+	}
+	for _, f := range params.Files {
+		source, ok := sources[f.Hash]
+		if !ok || (f.Size != nil && *f.Size != source.Size) {
+			e.Transfer = append(e.Transfer, f)
+			if f.Size != nil {
+				e.TransferSize += *f.Size
+			}
+			continue
+		}
+		e.Reuse = append(e.Reuse, &entity.ReusedFile{
+			Path:   f.Path,
+			Hash:   f.Hash,
+			Size:   source.Size,
+			Source: source,
+		})
+		e.ReusedSize += source.Size
+	}
+	return e, nil
+}
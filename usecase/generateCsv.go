@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// pivotExportPageSize is the number of pivot rows read at a time by the pivot export.
+const pivotExportPageSize = 1000
+
+// pivotExportAllLimit bounds the rows of the grouped view, which is read at once.
+const pivotExportAllLimit = 1000000
+
+type GenerateCsv struct {
+	repo                 *repository.GenerateCsv
+	reviewInfoRepo       *repository.ReviewInfo
+	groupCategoryRepo    *repository.GroupCategory
+	pubOperationInfoRepo *repository.PublishOperationInfo
+	mongoRepo            entity.DocumentRepository
+	customFieldRepo      *repository.CustomField
+	tagRepo              *repository.Tag
+	phaseTemplateRepo    *repository.PhaseTemplate
+	ReadTimeout          time.Duration
+}
+
+func NewGenerateCsv(
+	repo *repository.GenerateCsv,
+	reviewInfoRepo *repository.ReviewInfo,
+	groupCategoryRepo *repository.GroupCategory,
+	pubOperationInfoRepo *repository.PublishOperationInfo,
+	mongoRepo entity.DocumentRepository,
+	customFieldRepo *repository.CustomField,
+	tagRepo *repository.Tag,
+	phaseTemplateRepo *repository.PhaseTemplate,
+	readTimeout time.Duration,
+) *GenerateCsv {
+	return &GenerateCsv{
+		repo:                 repo,
+		reviewInfoRepo:       reviewInfoRepo,
+		groupCategoryRepo:    groupCategoryRepo,
+		pubOperationInfoRepo: pubOperationInfoRepo,
+		mongoRepo:            mongoRepo,
+		customFieldRepo:      customFieldRepo,
+		tagRepo:              tagRepo,
+		phaseTemplateRepo:    phaseTemplateRepo,
+		ReadTimeout:          readTimeout,
+	}
+}
+
+func (gc *GenerateCsv) ListAssetsGroupCategory(
+	ctx context.Context,
+	project string,
+) ([]*entity.GroupPathInfo, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.repo.WithContext(timeoutCtx)
+	return gc.repo.ListAssetsGroupCategory(db, project)
+}
+
+// ListAssetPhases returns the asset phases of the project's phase template, or nil when the
+// project has none.
+func (gc *GenerateCsv) ListAssetPhases(ctx context.Context, project string) ([]string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.repo.WithContext(timeoutCtx)
+	template, err := gc.phaseTemplateRepo.Get(db, &entity.GetPhaseTemplateParams{
+		Project: project,
+		Root:    "assets",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if template.Default {
+		return nil, nil
+	}
+	return template.Phases, nil
+}
+
+// ListPivotPhases returns the phase columns of the asset pivot of a root, in the order of the
+// project's phase template. Roots without any template have the phases of the assets root.
+func (gc *GenerateCsv) ListPivotPhases(
+	ctx context.Context,
+	project string,
+	root string,
+) ([]string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.repo.WithContext(timeoutCtx)
+	template, err := gc.phaseTemplateRepo.Get(db, &entity.GetPhaseTemplateParams{
+		Project: project,
+		Root:    root,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(template.Phases) == 0 {
+		return entity.DefaultPhaseTemplates["assets"], nil
+	}
+	return template.Phases, nil
+}
+
+// ExportAssetsPivot reads every asset matching the pivot filters of p, not only a page, and
+// passes them to write a batch at a time in the order of the pivot view.
+func (gc *GenerateCsv) ExportAssetsPivot(
+	ctx context.Context,
+	p repository.ListAssetsPivotParams,
+	write func(rows []repository.AssetPivot) error,
+) error {
+	if err := binding.Validator.ValidateStruct(&entity.GenerateTrackerCsvParams{
+		Project: p.Project,
+	}); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.reviewInfoRepo.WithContext(timeoutCtx)
+
+	view := strings.ToLower(strings.TrimSpace(p.View))
+	if view == "group" || view == "grouped" || view == "category" {
+		// The groups are sorted on their top node, so all the rows are read first.
+		dir := strings.ToUpper(strings.TrimSpace(p.Direction))
+		if dir != "ASC" && dir != "DESC" {
+			dir = "ASC"
+		}
+		p.View, p.Page, p.PerPage, p.Cursor = "list", 1, pivotExportAllLimit, ""
+		p.OrderKey, p.Direction = "group1_only", "ASC"
+		result, err := gc.reviewInfoRepo.ListAssetsPivot(db, p)
+		if err != nil {
+			return fmt.Errorf("exportAssetsPivot query failed: %w", err)
+		}
+		groups := repository.GroupAndSortByTopNode(result.Assets, repository.SortDirection(dir))
+		for _, g := range groups {
+			if err := write(g.Items); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p.View, p.Page, p.PerPage, p.Cursor = "list", 1, pivotExportPageSize, ""
+	for {
+		result, err := gc.reviewInfoRepo.ListAssetsPivot(db, p)
+		if err != nil {
+			return fmt.Errorf("exportAssetsPivot query failed: %w", err)
+		}
+		if len(result.Assets) > 0 {
+			if err := write(result.Assets); err != nil {
+				return err
+			}
+		}
+		if result.NextCursor == "" {
+			return nil
+		}
+		p.Cursor = result.NextCursor
+	}
+}
+
+// ListAssetCustomFields returns the project's asset field definitions and the values of every
+// asset keyed by "<asset>/<relation>".
+func (gc *GenerateCsv) ListAssetCustomFields(
+	ctx context.Context,
+	project string,
+) ([]*entity.CustomFieldDefinition, map[string]entity.JSONObject, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.customFieldRepo.WithContext(timeoutCtx)
+	defs, err := gc.customFieldRepo.ListDefinitions(db, project, entity.CustomFieldTargetAsset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listAssetCustomFields query failed: %w", err)
+	}
+	metadata, err := gc.customFieldRepo.ListAssetMetadata(db, project)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listAssetCustomFields query failed: %w", err)
+	}
+	return defs, metadata, nil
+}
+
+// ListAssetTags returns the tags of every asset of the project keyed by "<asset>/<relation>".
+func (gc *GenerateCsv) ListAssetTags(
+	ctx context.Context,
+	project string,
+) (map[string][]string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.tagRepo.WithContext(timeoutCtx)
+	tags, err := gc.tagRepo.ListTypeTags(db, project, entity.TagTargetAsset)
+	if err != nil {
+		return nil, fmt.Errorf("listAssetTags query failed: %w", err)
+	}
+	return tags, nil
+}
+
+func (gc *GenerateCsv) ListAllBldReviews(
+	ctx context.Context,
+	project string,
+) ([]*entity.BldComponentReviewInfo, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.repo.WithContext(timeoutCtx)
+	return gc.repo.ListAllBldReviews(db, project)
+}
+
+func (gc *GenerateCsv) ListComments(
+	ctx context.Context,
+	project string,
+) ([]*entity.DocumentInfo, error) {
+	filterPath := "^shared/publish/assets/[^/]+/[^/]+/(mdl|rig|ldv)/_review"
+	commentParam := &entity.QueryDocumentsParam{
+		Filters: map[string]interface{}{
+			"root": "assets",
+			"path": bson.M{"$regex": filterPath},
+		},
+		OrderBy: []*libs.OrderColumn{
+			{Name: "_id", Direction: libs.Desc},
+		},
+	}
+	comments, _, err := gc.mongoRepo.GetDocumentsByFields(ctx, project, "comment", commentParam)
+	if err != nil {
+		return nil, fmt.Errorf("listComments query failed: %w", err)
+	}
+	return comments, nil
+}
+
+func (gc *GenerateCsv) ListShotAssetsAll(
+	ctx context.Context,
+	project string,
+) ([]*entity.DocumentInfo, error) {
+	shotAssetsAllParam := &entity.QueryDocumentsParam{
+		Filters: map[string]interface{}{
+			"root":      "shots",
+			"phase":     "anm",
+			"component": "anm",
+		},
+	}
+	shotAssetsAllInfos, _, err := gc.mongoRepo.GetDocumentsByFields(ctx, project, "publishOperationInfo", shotAssetsAllParam)
+	if err != nil {
+		return nil, fmt.Errorf("listShotAssetsAll query failed: %w", err)
+	}
+	return shotAssetsAllInfos, nil
+}
+
+func (gc *GenerateCsv) ListBldAnmBldRendDocuments(
+	ctx context.Context,
+	project string,
+) ([]*entity.DocumentInfo, error) {
+	bldDocumentsParam := &entity.QueryDocumentsParam{
+		Filters: map[string]interface{}{
+			"root":      "assets",
+			"phase":     "bld",
+			"component": bson.M{"$in": []string{"bldAnm", "bldRend"}},
+		},
+		OrderBy: []*libs.OrderColumn{
+			{Name: "_id", Direction: libs.Desc},
+		},
+	}
+	bldDocuments, _, err := gc.mongoRepo.GetDocumentsByFields(ctx, project, "publishOperationInfo", bldDocumentsParam)
+	if err != nil {
+		return nil, fmt.Errorf("list bldAnm bldRend Documents query failed: %w", err)
+	}
+	return bldDocuments, nil
+}
+
+func (gc *GenerateCsv) ListPublishOperationInfos(
+	ctx context.Context,
+	project string,
+) ([]*entity.DocumentInfo, error) {
+	publishOperationInfoParam := &entity.QueryDocumentsParam{
+		Filters: map[string]interface{}{
+			"root":      "assets",
+			"phase":     bson.M{"$in": []string{"mdl", "rig", "ldv"}},
+			"component": bson.M{"$regex": "^(mdl|rig|ldv)"},
+		},
+	}
+	publishOperationInfos, _, err := gc.mongoRepo.GetDocumentsByFields(ctx, project, "publishOperationInfo", publishOperationInfoParam)
+	if err != nil {
+		return nil, fmt.Errorf("list PublishOperationInfos query failed: %w", err)
+	}
+	return publishOperationInfos, nil
+}
+
+func (gc *GenerateCsv) ListLatestAssetsReviews(
+	ctx context.Context,
+	project string,
+) ([]*entity.AssetReviewInfoCsv, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, gc.ReadTimeout)
+	defer cancel()
+	db := gc.repo.WithContext(timeoutCtx)
+	return gc.repo.ListLatestAssetsReviews(db, project)
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// GraphQL resolves the types of the GraphQL API: the reviews, the pivot rows of the assets,
+// the publish transactions and the dependencies of the revisions.
+type GraphQL struct {
+	riUC        *ReviewInfo
+	ptRepo      *repository.PhaseTemplate
+	authUC      *Auth
+	ReadTimeout time.Duration
+}
+
+func NewGraphQL(
+	riUC *ReviewInfo,
+	ptr *repository.PhaseTemplate,
+	authUC *Auth,
+	readTimeout time.Duration,
+) *GraphQL {
+	return &GraphQL{
+		riUC:        riUC,
+		ptRepo:      ptr,
+		authUC:      authUC,
+		ReadTimeout: readTimeout,
+	}
+}
+
+// CheckProjectAccess checks the studio may read the project, as the routes under /projects
+// do, since a query may read any project.
+func (uc *GraphQL) CheckProjectAccess(ctx context.Context, studio, project string) error {
+	if entity.SkipAuth {
+		return nil
+	}
+	return uc.authUC.CheckProjectAccess(ctx, &entity.ProjectAccessParams{
+		Studio:  studio,
+		Project: project,
+	})
+}
+
+func (uc *GraphQL) GetReviewInfo(
+	ctx context.Context,
+	params *entity.GetReviewParams,
+) (*entity.ReviewInfo, error) {
+	return uc.riUC.Get(ctx, params)
+}
+
+func (uc *GraphQL) ListAssetReviewInfos(
+	ctx context.Context,
+	params *entity.AssetReviewInfoListParams,
+) ([]*entity.ReviewInfo, error) {
+	return uc.riUC.ListAssetReviewInfos(ctx, params)
+}
+
+func (uc *GraphQL) ListPublishTransactionReviewInfos(
+	ctx context.Context,
+	params *entity.PublishTransactionReviewInfoListParams,
+) ([]*entity.ReviewInfo, uint, error) {
+	return uc.riUC.ListPublishTransactionReviewInfos(ctx, params)
+}
+
+// GetAssetPivot returns the pivot row of an asset, with the phase columns of the phase
+// template of the project.
+func (uc *GraphQL) GetAssetPivot(
+	ctx context.Context,
+	params *entity.GetAssetPivotParams,
+) (*repository.AssetPivot, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.riUC.repo.WithContext(timeoutCtx)
+	if err := uc.riUC.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	phaseTemplate, err := uc.ptRepo.Get(db, &entity.GetPhaseTemplateParams{
+		Project: params.Project,
+		Root:    params.Root,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query := repository.ListAssetsPivotParams{
+		Project:  params.Project,
+		Root:     params.Root,
+		View:     "list",
+		Page:     1,
+		PerPage:  1,
+		Asset:    params.Asset,
+		Relation: params.Relation,
+	}
+	if !phaseTemplate.Default {
+		query.Phases = phaseTemplate.Phases
+	}
+	result, err := uc.riUC.repo.ListAssetsPivot(db, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Assets) == 0 {
+		return nil, fmt.Errorf(
+			"%w: asset %s %s in %s", entity.ErrRecordNotFound,
+			params.Asset, params.Relation, params.Project,
+		)
+	}
+	return &result.Assets[0], nil
+}
+
+func (uc *GraphQL) GetPublishTransaction(
+	ctx context.Context,
+	params *entity.GetPublishTransactionSummaryParams,
+) (*entity.PublishTransactionSummary, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.riUC.repo.WithContext(timeoutCtx)
+	if err := uc.riUC.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.riUC.repo.GetPublishTransaction(db, params)
+}
+
+// ListRevisionDependencies returns the upstream contents of the take of a review in the
+// DataDependency graph.
+func (uc *GraphQL) ListRevisionDependencies(
+	ctx context.Context,
+	lgr entity.Logger,
+	review *entity.ReviewInfo,
+) ([]*entity.RevisionDependency, error) {
+	if uc.riUC.depRepo == nil {
+		return nil, entity.ErrDataDependencyUnavailable
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	var group string
+	if len(review.Groups) > 0 {
+		group = review.Groups[0]
+	}
+	dependencies, _, err := uc.riUC.depRepo.ListRevisionDependencies(
+		timeoutCtx, lgr,
+		review.Project, review.Root, group, review.Relation, review.Phase, review.Take,
+	)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.RevisionDependency, len(dependencies))
+	for i, d := range dependencies {
+		entities[i] = &entity.RevisionDependency{
+			Root:      d.Content.Root,
+			Group:     d.Content.Group,
+			Relation:  d.Content.Relation,
+			Phase:     d.Content.Phase,
+			Component: d.Content.Component,
+			Revision:  d.Content.Revision,
+			FileName:  d.Content.FileName,
+		}
+	}
+	return entities, nil
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/entity/groupCategory"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// Assign assigns groups to a category in one transaction.
+func (uc *GroupCategory) Assign(
+	ctx context.Context,
+	params *groupCategory.AssignParams,
+) (*groupCategory.CategoryEntity, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *groupCategory.CategoryEntity
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Assign(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
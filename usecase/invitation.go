@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Invitation struct {
+	repo         *repository.Invitation
+	roleRepo     *repository.Role
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewInvitation(
+	repo *repository.Invitation,
+	roleRepo *repository.Role,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Invitation {
+	return &Invitation{
+		repo:         repo,
+		roleRepo:     roleRepo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *Invitation) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *Invitation) List(
+	ctx context.Context,
+	params *entity.ListInvitationsParams,
+) ([]*entity.Invitation, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.List(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Invitation) Get(
+	ctx context.Context,
+	params *entity.GetInvitationParams,
+) (*entity.Invitation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+// Create creates an invitation to an existing role of a project, and returns it with its
+// code, which cannot be retrieved later.
+func (uc *Invitation) Create(
+	ctx context.Context,
+	params *entity.CreateInvitationParams,
+) (*entity.Invitation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if !params.ExpiresAtUTC.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at_utc must be in the future", entity.ErrBadRequest)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Invitation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		if _, err := uc.roleRepo.Get(tx, &entity.GetRoleParams{Name: params.Role}); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Redeem assigns the role of a pending invitation to the user redeeming its code. The access
+// of the studio of the user to the project is still granted by the project's studios.
+func (uc *Invitation) Redeem(
+	ctx context.Context,
+	params *entity.RedeemInvitationParams,
+) (*entity.Invitation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Invitation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		invitation, err := uc.repo.GetForRedemption(tx, params.Code)
+		if err != nil {
+			return err
+		}
+		if invitation.Status != entity.InvitationPending {
+			return fmt.Errorf(
+				"%w: the invitation is %s", entity.ErrBadRequest, invitation.Status,
+			)
+		}
+		if invitation.Invitee != nil && *invitation.Invitee != params.User {
+			return fmt.Errorf(
+				"%w: the invitation is for another user", entity.ErrForbidden,
+			)
+		}
+		userRole, err := uc.roleRepo.CreateUserRole(tx, &entity.CreateUserRoleParams{
+			Role:         invitation.Role,
+			Project:      invitation.Project,
+			User:         params.User,
+			ExpiresAtUTC: invitation.MembershipExpiresAtUTC,
+			CreatedBy:    fmt.Sprintf("invitation:%d", invitation.ID),
+		})
+		if err != nil {
+			return err
+		}
+		e, err = uc.repo.Redeemed(tx, invitation.ID, params, userRole)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Invitation) Revoke(
+	ctx context.Context,
+	params *entity.RevokeInvitationParams,
+) (*entity.Invitation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Invitation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Revoke(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
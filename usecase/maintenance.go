@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+const (
+	// reindexBatchSize is the number of assets or shots whose latest reviews are rebuilt per
+	// transaction, so that a reindex never locks a whole project.
+	reindexBatchSize = 200
+	// reindexStaleAfter is how long a running reindex may go without progress before it is
+	// considered interrupted.
+	reindexStaleAfter = 10 * time.Minute
+)
+
+// Maintenance lets admins drop the caches and rebuild the materialized data of projects after
+// fixing their data in the database by hand.
+type Maintenance struct {
+	repo         *repository.ReindexJob
+	prjRepo      *repository.ProjectInfo
+	invalidators map[string]entity.CacheInvalidator
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// NewMaintenance returns the maintenance usecase. invalidators maps the cache namespaces to
+// the functions dropping them; the namespaces without a cache in the instance are omitted.
+func NewMaintenance(
+	repo *repository.ReindexJob,
+	pr *repository.ProjectInfo,
+	invalidators map[string]entity.CacheInvalidator,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Maintenance {
+	return &Maintenance{
+		repo:         repo,
+		prjRepo:      pr,
+		invalidators: invalidators,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *Maintenance) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// InvalidateCache drops a cache namespace of a project, or of all the projects.
+func (uc *Maintenance) InvalidateCache(
+	ctx context.Context,
+	params *entity.InvalidateCacheParams,
+) (*entity.CacheInvalidation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	invalidate, ok := uc.invalidators[params.Namespace]
+	if !ok {
+		namespaces := make([]string, 0, len(uc.invalidators))
+		for n := range uc.invalidators {
+			namespaces = append(namespaces, n)
+		}
+		sort.Strings(namespaces)
+		return nil, fmt.Errorf(
+			"%w: no %s cache to invalidate, the caches are: %s",
+			entity.ErrBadRequest, params.Namespace, strings.Join(namespaces, ", "),
+		)
+	}
+	project := ""
+	if params.Project != nil {
+		timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+		defer cancel()
+		if err := uc.checkForProject(uc.repo.WithContext(timeoutCtx), *params.Project); err != nil {
+			return nil, err
+		}
+		project = *params.Project
+	}
+	invalidate(ctx, project)
+	return &entity.CacheInvalidation{
+		Namespace:        params.Namespace,
+		Project:          params.Project,
+		InvalidatedAtUTC: time.Now().UTC(),
+	}, nil
+}
+
+func (uc *Maintenance) ListReindexJobs(
+	ctx context.Context,
+	params *entity.ListReindexJobsParams,
+) ([]*entity.ReindexJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.List(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Maintenance) GetReindexJob(
+	ctx context.Context,
+	params *entity.GetReindexJobParams,
+) (*entity.ReindexJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+// CreateReindexJob queues the rebuild of the materialized data of a project.
+func (uc *Maintenance) CreateReindexJob(
+	ctx context.Context,
+	params *entity.CreateReindexJobParams,
+) (*entity.ReindexJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReindexJob
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RunReindexWorker runs the queued reindex jobs every interval until ctx is done.
+func (uc *Maintenance) RunReindexWorker(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.ProcessReindexJobs(ctx, lgr); err != nil {
+			lgr.Errorf("[Maintenance] failed to process reindex jobs: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[Maintenance] finished %d reindex jobs", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessReindexJobs fails the interrupted jobs and runs the queued jobs one by one. It
+// returns the number of the jobs it ran.
+func (uc *Maintenance) ProcessReindexJobs(ctx context.Context, lgr entity.Logger) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	n, err := uc.repo.FailStale(
+		uc.repo.WithContext(timeoutCtx), time.Now().UTC().Add(-reindexStaleAfter),
+	)
+	cancel()
+	if err != nil {
+		return 0, err
+	} else if n > 0 {
+		lgr.Warnf("[Maintenance] failed %d interrupted reindex jobs", n)
+	}
+
+	var finished int
+	for ctx.Err() == nil {
+		claimCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		e, err := uc.repo.Claim(uc.repo.WithContext(claimCtx))
+		cancel()
+		if err != nil {
+			return finished, err
+		}
+		if e == nil {
+			break
+		}
+		if err := uc.reindex(ctx, e); err != nil {
+			lgr.Warnf("[Maintenance] failed to run reindex job %d: %v", e.ID, err)
+			failCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+			if err := uc.repo.Fail(uc.repo.WithContext(failCtx), e.ID, err.Error()); err != nil {
+				lgr.Errorf("[Maintenance] failed to fail reindex job %d: %v", e.ID, err)
+			}
+			cancel()
+		}
+		finished++
+	}
+	return finished, nil
+}
+
+// reindex rebuilds the latest reviews of the assets and shots of the project of a claimed job
+// a batch at a time, recording its progress, then drops the pivot cache of the project.
+func (uc *Maintenance) reindex(ctx context.Context, e *entity.ReindexJob) error {
+	listCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	groups, err := uc.repo.ListLatestGroups(uc.repo.WithContext(listCtx), e.Project)
+	cancel()
+	if err != nil {
+		return err
+	}
+	total := int64(len(groups))
+	var done, rows int64
+	for start := 0; start < len(groups); {
+		// a batch holds the groups of a single root
+		root := groups[start].Root
+		end := start
+		batch := make([]string, 0, reindexBatchSize)
+		for end < len(groups) && len(batch) < reindexBatchSize && groups[end].Root == root {
+			batch = append(batch, groups[end].Group1)
+			end++
+		}
+		writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		err := uc.repo.TransactionWithContext(writeCtx, func(tx *gorm.DB) error {
+			n, err := uc.repo.RefreshLatestGroups(tx, e.Project, root, batch)
+			if err != nil {
+				return err
+			}
+			rows += n
+			done += int64(len(batch))
+			return uc.repo.Progress(tx, e.ID, total, done, rows)
+		})
+		cancel()
+		if err != nil {
+			return err
+		}
+		start = end
+	}
+
+	writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	if err := uc.repo.TransactionWithContext(writeCtx, func(tx *gorm.DB) error {
+		if _, err := uc.repo.SaveLatestState(tx, e.Project, rows, e.CreatedBy); err != nil {
+			return err
+		}
+		return uc.repo.Complete(tx, e.ID)
+	}); err != nil {
+		return err
+	}
+	if invalidate, ok := uc.invalidators[entity.CacheNamespacePivot]; ok {
+		invalidate(ctx, e.Project)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// maxEncryptThumbnailsErrors limits the errors reported by EncryptThumbnails.
+const maxEncryptThumbnailsErrors = 100
+
+type MediaKey struct {
+	repo         *repository.MediaKey
+	thumbRepo    *repository.ReviewThumbnail
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewMediaKey(
+	repo *repository.MediaKey,
+	thumbRepo *repository.ReviewThumbnail,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *MediaKey {
+	return &MediaKey{
+		repo:         repo,
+		thumbRepo:    thumbRepo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *MediaKey) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *MediaKey) List(
+	ctx context.Context,
+	params *entity.ListMediaKeysParams,
+) ([]*entity.MediaKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.List(db, params)
+}
+
+// Rotate creates a new active key of the project. The media encrypted before keep their key
+// until EncryptThumbnails re-encrypts them.
+func (uc *MediaKey) Rotate(
+	ctx context.Context,
+	params *entity.RotateMediaKeyParams,
+) (*entity.MediaKey, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.MediaKey
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Rotate(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// EncryptThumbnails encrypts the plaintext thumbnails of the project with its active key and
+// re-encrypts those encrypted with a retired key. It continues after a failed thumbnail, so
+// that it can be run again until none fails.
+func (uc *MediaKey) EncryptThumbnails(
+	ctx context.Context,
+	params *entity.EncryptThumbnailsParams,
+) (*entity.EncryptThumbnailsResult, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		cancel()
+		return nil, err
+	}
+	version, err := uc.repo.ActiveVersion(db, params.Project)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, fmt.Errorf(
+			"%w: project %s has no media key", entity.ErrBadRequest, params.Project,
+		)
+	}
+	thumbnailPaths, err := uc.thumbRepo.ListThumbnails(params.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.EncryptThumbnailsResult{
+		KeyVersion: version,
+		Errors:     []string{},
+	}
+	for _, thumbnailPath := range thumbnailPaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		encrypted, err := uc.encryptThumbnail(ctx, params.Project, version, thumbnailPath)
+		switch {
+		case err != nil:
+			result.Failed++
+			if len(result.Errors) < maxEncryptThumbnailsErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", thumbnailPath, err))
+			}
+		case encrypted:
+			result.Encrypted++
+		default:
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+// encryptThumbnail encrypts a thumbnail file with the given version of the key of the project
+// unless it already is. It reports whether the file was encrypted.
+func (uc *MediaKey) encryptThumbnail(
+	ctx context.Context,
+	project string,
+	version int32,
+	thumbnailPath string,
+) (bool, error) {
+	f, err := uc.thumbRepo.OpenThumbnail(thumbnailPath)
+	if err != nil {
+		return false, err
+	}
+	current, err := uc.repo.KeyVersion(f)
+	f.Close()
+	if err != nil || current == version {
+		return false, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.thumbRepo.ReplaceThumbnail(thumbnailPath, func(w io.Writer, r io.Reader) error {
+		plain, _, err := uc.repo.Decrypt(db, project, r)
+		if err != nil {
+			return err
+		}
+		mw, err := uc.repo.Encrypt(db, project, version, w)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(mw, plain); err != nil {
+			return err
+		}
+		return mw.Close()
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListAccessLogs lists the accesses to the encrypted media of the project.
+func (uc *MediaKey) ListAccessLogs(
+	ctx context.Context,
+	params *entity.ListMediaAccessLogsParams,
+) ([]*entity.MediaAccessLog, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListAccessLogs(db, params)
+}
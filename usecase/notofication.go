@@ -0,0 +1,472 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxLease       = 5 * time.Minute
+	outboxMaxAttempts = 8
+	outboxBaseDelay   = 30 * time.Second
+	outboxMaxDelay    = time.Hour
+)
+
+// deadLetterMetric exports the depth of the dead letter queue with the other expvar variables.
+// It is refreshed by the dispatcher and by the dead letter API.
+var deadLetterMetric = expvar.NewInt("notification_dead_letters")
+
+type Notification struct {
+	repo         *repository.Notification
+	outboxRepo   *repository.NotificationOutbox
+	webhookRepo  *repository.Webhook
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func NewNotification(
+	repo *repository.Notification,
+	outboxRepo *repository.NotificationOutbox,
+	webhookRepo *repository.Webhook,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Notification {
+	return &Notification{
+		repo:         repo,
+		outboxRepo:   outboxRepo,
+		webhookRepo:  webhookRepo,
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// EnqueuePublishNotification stores the publish notification in the outbox, with the
+// publish.completed event of the webhooks of the project. They are sent later by
+// RunOutboxDispatcher.
+func (uc *Notification) EnqueuePublishNotification(
+	ctx context.Context,
+	params *entity.PublishTransactionInfoNotification,
+) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	return uc.outboxRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.outboxRepo.EnqueuePublishNotification(tx, params)
+	})
+}
+
+func (uc *Notification) SendPublishNotification(
+	ctx context.Context,
+	lgr entity.Logger,
+	params *entity.PublishTransactionInfoNotification,
+) error {
+	if params.Root == nil {
+		return errors.New("[EmailSender] root in PublishTransactionInfo is nil")
+	}
+	if params.Phase == nil {
+		return errors.New("[EmailSender] phase in PublishTransactionInfo is nil")
+	}
+	if params.Component == nil {
+		return errors.New("[EmailSender] component in PublishTransactionInfo is nil")
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.SendPublishNotification(db, lgr, params)
+}
+
+func (uc *Notification) SendReviewStatusNotification(
+	ctx context.Context,
+	params *entity.ReviewStatusLogNotification,
+) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.SendReviewStatusNotification(db, params)
+}
+
+func (uc *Notification) SendSLABreachNotification(
+	ctx context.Context,
+	params *entity.SLABreachNotification,
+) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.SendSLABreachNotification(db, params)
+}
+
+func (uc *Notification) SendWatchNotification(
+	ctx context.Context,
+	params *entity.WatchNotification,
+) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.SendWatchNotification(db, params)
+}
+
+func (uc *Notification) SendStatusChatNotification(
+	ctx context.Context,
+	params *entity.StatusChatNotification,
+) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	return uc.repo.SendStatusChatNotification(db, params)
+}
+
+func (uc *Notification) SendApiProcessFailure(err *entity.ApiProcessError) {
+	uc.repo.SendApiProcessFailure(err)
+}
+
+func (uc *Notification) SendGeneralFailure(err error) {
+	uc.repo.SendGeneralFailure(err)
+}
+
+// RunOutboxDispatcher sends the notifications of the outbox every interval until ctx is done.
+func (uc *Notification) RunOutboxDispatcher(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := uc.DispatchOutbox(ctx, lgr)
+			if err != nil {
+				lgr.Errorf("[Outbox] failed to dispatch notifications: %v", err)
+				break
+			}
+			if n < outboxBatchSize {
+				break
+			}
+		}
+		if _, err := uc.GetDeadLetterStats(ctx); err != nil {
+			lgr.Errorf("[Outbox] failed to count dead letters: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOutbox sends one batch of due notifications and returns the number of processed
+// entries. Failed entries are retried with an exponential backoff and moved to the dead
+// letters after outboxMaxAttempts attempts.
+func (uc *Notification) DispatchOutbox(ctx context.Context, lgr entity.Logger) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	entries, err := uc.outboxRepo.Claim(
+		uc.outboxRepo.WithContext(timeoutCtx), outboxBatchSize, outboxLease,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range entries {
+		sendErr := uc.sendOutboxEntry(ctx, lgr, e)
+		db := uc.outboxRepo.WithContext(ctx)
+		if sendErr == nil || errors.Is(sendErr, entity.ErrNotificationSkipped) {
+			if sendErr != nil {
+				lgr.Debug(sendErr.Error())
+			}
+			if err := uc.outboxRepo.MarkSent(db, e.ID); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		attempts := e.Attempts + 1
+		var nextAttempt *time.Time
+		if attempts < outboxMaxAttempts {
+			delay := outboxBaseDelay << (attempts - 1)
+			if delay > outboxMaxDelay {
+				delay = outboxMaxDelay
+			}
+			t := time.Now().UTC().Add(delay)
+			nextAttempt = &t
+		}
+		lgr.Warnf("[Outbox] attempt %d of notification %d failed: %v", attempts, e.ID, sendErr)
+		if err := uc.outboxRepo.MarkFailed(db, e.ID, sendErr, nextAttempt); err != nil {
+			return 0, err
+		}
+		if nextAttempt == nil {
+			uc.repo.SendGeneralFailure(fmt.Errorf(
+				"notification %d (%s) of project %s moved to dead letters: %w",
+				e.ID, e.Kind, e.Project, sendErr,
+			))
+		}
+	}
+	return len(entries), nil
+}
+
+func (uc *Notification) sendOutboxEntry(
+	ctx context.Context,
+	lgr entity.Logger,
+	e *entity.NotificationOutboxEntry,
+) error {
+	switch e.Kind {
+	case entity.PublishNotificationKind:
+		var info entity.PublishTransactionInfoNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.SendPublishNotification(ctx, lgr, &info)
+	case entity.ReviewStatusNotificationKind:
+		var info entity.ReviewStatusLogNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.SendReviewStatusNotification(ctx, &info)
+	case entity.SLABreachNotificationKind:
+		var info entity.SLABreachNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.SendSLABreachNotification(ctx, &info)
+	case entity.PropagationOverdueNotificationKind:
+		var info entity.PropagationOverdueNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.repo.SendPropagationOverdueNotification(&info)
+	case entity.SettingChangesetNotificationKind:
+		var info entity.SettingChangesetNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.repo.SendSettingChangesetNotification(&info)
+	case entity.WatchNotificationKind:
+		var info entity.WatchNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.SendWatchNotification(ctx, &info)
+	case entity.WebhookNotificationKind:
+		var info entity.WebhookNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.webhookRepo.Send(ctx, &info, e.Attempts+1)
+	case entity.StatusChatNotificationKind:
+		var info entity.StatusChatNotification
+		if err := json.Unmarshal(e.Payload, &info); err != nil {
+			return err
+		}
+		return uc.SendStatusChatNotification(ctx, &info)
+	}
+	return fmt.Errorf("unknown notification kind %q", e.Kind)
+}
+
+func (uc *Notification) ListDeadLetters(
+	ctx context.Context,
+	params *entity.ListDeadLettersParams,
+) ([]*entity.NotificationOutboxEntry, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	return uc.outboxRepo.ListDeadLetters(uc.outboxRepo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Notification) GetDeadLetter(
+	ctx context.Context,
+	params *entity.GetDeadLetterParams,
+) (*entity.NotificationOutboxEntry, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	return uc.outboxRepo.GetDeadLetter(uc.outboxRepo.WithContext(timeoutCtx), params)
+}
+
+// RetryDeadLetter sends a dead letter again with the next dispatch, and returns it as it was
+// before the retry.
+func (uc *Notification) RetryDeadLetter(
+	ctx context.Context,
+	params *entity.GetDeadLetterParams,
+) (*entity.NotificationOutboxEntry, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	var e *entity.NotificationOutboxEntry
+	if err := uc.outboxRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.outboxRepo.GetDeadLetter(tx, params)
+		if err != nil {
+			return err
+		}
+		_, err = uc.outboxRepo.RetryDeadLetters(tx, &entity.RetryDeadLettersParams{
+			IDs: []int32{params.ID},
+		})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	deadLetterMetric.Add(-1)
+	return e, nil
+}
+
+// RetryDeadLetters sends the selected dead letters again with the next dispatches.
+func (uc *Notification) RetryDeadLetters(
+	ctx context.Context,
+	params *entity.RetryDeadLettersParams,
+) (*entity.DeadLetterBatch, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	var n int64
+	if err := uc.outboxRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		n, err = uc.outboxRepo.RetryDeadLetters(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	deadLetterMetric.Add(-n)
+	return &entity.DeadLetterBatch{Count: n}, nil
+}
+
+// PurgeDeadLetters deletes the selected dead letters, which are not sent anymore.
+func (uc *Notification) PurgeDeadLetters(
+	ctx context.Context,
+	params *entity.PurgeDeadLettersParams,
+) (*entity.DeadLetterBatch, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	var n int64
+	if err := uc.outboxRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		n, err = uc.outboxRepo.PurgeDeadLetters(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	deadLetterMetric.Add(-n)
+	return &entity.DeadLetterBatch{Count: n}, nil
+}
+
+// GetDeadLetterStats counts the dead letters and refreshes the exported depth with it.
+func (uc *Notification) GetDeadLetterStats(ctx context.Context) (*entity.DeadLetterStats, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	stats, err := uc.outboxRepo.DeadLetterStats(uc.outboxRepo.WithContext(timeoutCtx))
+	if err != nil {
+		return nil, err
+	}
+	deadLetterMetric.Set(stats.Depth)
+	return stats, nil
+}
+
+func (uc *Notification) ListWebhooks(
+	ctx context.Context,
+	params *entity.ListWebhooksParams,
+) ([]*entity.Webhook, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	return uc.webhookRepo.List(uc.webhookRepo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Notification) GetWebhook(
+	ctx context.Context,
+	params *entity.GetWebhookParams,
+) (*entity.Webhook, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	return uc.webhookRepo.Get(uc.webhookRepo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Notification) CreateWebhook(
+	ctx context.Context,
+	params *entity.CreateWebhookParams,
+) (*entity.Webhook, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	var e *entity.Webhook
+	if err := uc.webhookRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.webhookRepo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Notification) UpdateWebhook(
+	ctx context.Context,
+	params *entity.UpdateWebhookParams,
+) (*entity.Webhook, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	var e *entity.Webhook
+	if err := uc.webhookRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.webhookRepo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Notification) DeleteWebhook(
+	ctx context.Context,
+	params *entity.DeleteWebhookParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.writeTimeout)
+	defer cancel()
+	return uc.webhookRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.webhookRepo.Delete(tx, params)
+	})
+}
+
+// ListWebhookDeliveries returns the attempts to post the events to a webhook, with their
+// response status or error.
+func (uc *Notification) ListWebhookDeliveries(
+	ctx context.Context,
+	params *entity.ListWebhookDeliveriesParams,
+) ([]*entity.WebhookDelivery, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.readTimeout)
+	defer cancel()
+	return uc.webhookRepo.ListDeliveries(uc.webhookRepo.WithContext(timeoutCtx), params)
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type PhaseTemplate struct {
+	repo        *repository.PhaseTemplate
+	prjRepo     *repository.ProjectInfo
+	ReadTimeout time.Duration
+}
+
+func NewPhaseTemplate(
+	repo *repository.PhaseTemplate,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+) *PhaseTemplate {
+	return &PhaseTemplate{
+		repo:        repo,
+		prjRepo:     pr,
+		ReadTimeout: readTimeout,
+	}
+}
+
+func (uc *PhaseTemplate) Get(
+	ctx context.Context,
+	params *entity.GetPhaseTemplateParams,
+) (*entity.PhaseTemplate, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if _, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: params.Project,
+	}); err != nil {
+		return nil, err
+	}
+	return uc.repo.Get(db, params)
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// pivotSnapshotMaxRows bounds the assets of a snapshot, which are stored as a whole.
+const pivotSnapshotMaxRows = 5000
+
+type PivotSnapshot struct {
+	repo         *repository.PivotSnapshot
+	riRepo       *repository.ReviewInfo
+	ptRepo       *repository.PhaseTemplate
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewPivotSnapshot(
+	repo *repository.PivotSnapshot,
+	rir *repository.ReviewInfo,
+	ptr *repository.PhaseTemplate,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *PivotSnapshot {
+	return &PivotSnapshot{
+		repo:         repo,
+		riRepo:       rir,
+		ptRepo:       ptr,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *PivotSnapshot) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// Create freezes the assets matching the filters of the pivot query into a snapshot, and
+// returns it with its signed public URL. The paging of the query is ignored.
+func (uc *PivotSnapshot) Create(
+	ctx context.Context,
+	params *entity.CreatePivotSnapshotParams,
+	query repository.ListAssetsPivotParams,
+) (*entity.PivotSnapshot, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if !uc.repo.Enabled() {
+		return nil, fmt.Errorf("%w: share links are not enabled", entity.ErrForbidden)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.PivotSnapshot
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		phaseTemplate, err := uc.ptRepo.Get(tx, &entity.GetPhaseTemplateParams{
+			Project: params.Project,
+			Root:    params.Root,
+		})
+		if err != nil {
+			return err
+		}
+		query.Project = params.Project
+		query.Root = params.Root
+		query.View = "list"
+		query.Page = 1
+		query.PerPage = pivotSnapshotMaxRows
+		query.Phases = nil
+		if !phaseTemplate.Default {
+			query.Phases = phaseTemplate.Phases
+		}
+		dir := strings.ToUpper(query.Direction)
+		if dir != "DESC" {
+			dir = "ASC"
+		}
+		if params.View == "group" {
+			// grouped like the pivot API does, from the assets in name order
+			query.OrderKey = "group1_only"
+			query.Direction = "ASC"
+		}
+		result, err := uc.riRepo.ListAssetsPivot(tx, query)
+		if err != nil {
+			return err
+		}
+		if result.Total > pivotSnapshotMaxRows {
+			return fmt.Errorf(
+				"%w: %d assets match the filters, a snapshot is limited to %d",
+				entity.ErrBadRequest, result.Total, pivotSnapshotMaxRows,
+			)
+		}
+		var rows interface{} = result.Assets
+		if params.View == "group" {
+			rows = repository.GroupAndSortByTopNode(result.Assets, repository.SortDirection(dir))
+		} else if result.Assets == nil {
+			rows = []repository.AssetPivot{}
+		}
+		data, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		e, err = uc.repo.Create(tx, params, phaseTemplate.Phases, data, result.Total)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	expires := e.ExpiresAtUTC.Unix()
+	e.URL = entity.PublicPath(fmt.Sprintf(
+		"/api/public/pivotSnapshots/%d?expires=%d&sig=%s",
+		e.ID, expires, uc.repo.Sign(e.ID, expires),
+	))
+	return e, nil
+}
+
+// GetShared returns the snapshot of a public link and counts the view. Links with a wrong
+// signature are not found, so that they cannot be told from revoked ones.
+func (uc *PivotSnapshot) GetShared(
+	ctx context.Context,
+	params *entity.GetSharedPivotSnapshotParams,
+) (*entity.PivotSnapshot, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if !uc.repo.Verify(params.ID, params.Expires, params.Signature) {
+		return nil, fmt.Errorf(
+			"%w: invalid share link of pivot snapshot with ID %d",
+			entity.ErrRecordNotFound, params.ID,
+		)
+	}
+	if time.Now().Unix() >= params.Expires {
+		return nil, fmt.Errorf(
+			"%w: pivot snapshot with ID %d", entity.ErrShareLinkExpired, params.ID,
+		)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.PivotSnapshot
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.View(tx, params.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Delete revokes the public link of the snapshot.
+func (uc *PivotSnapshot) Delete(
+	ctx context.Context,
+	params *entity.DeletePivotSnapshotParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.Delete(tx, params)
+	})
+}
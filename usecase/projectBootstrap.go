@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type ProjectBootstrap struct {
+	repo         *repository.ProjectBootstrapJob
+	prjRepo      *repository.ProjectInfo
+	settingRepo  *repository.PipelineSetting
+	dirRepo      *repository.DirectoryTemplate
+	categoryRepo *repository.GroupCategory
+	// BootstrapTimeout bounds the bootstrap of a project.
+	BootstrapTimeout time.Duration
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+}
+
+func NewProjectBootstrap(
+	repo *repository.ProjectBootstrapJob,
+	pr *repository.ProjectInfo,
+	sr *repository.PipelineSetting,
+	dr *repository.DirectoryTemplate,
+	cr *repository.GroupCategory,
+	bootstrapTimeout time.Duration,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ProjectBootstrap {
+	return &ProjectBootstrap{
+		repo:             repo,
+		prjRepo:          pr,
+		settingRepo:      sr,
+		dirRepo:          dr,
+		categoryRepo:     cr,
+		BootstrapTimeout: bootstrapTimeout,
+		ReadTimeout:      readTimeout,
+		WriteTimeout:     writeTimeout,
+	}
+}
+
+func (uc *ProjectBootstrap) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *ProjectBootstrap) Get(
+	ctx context.Context,
+	params *entity.GetProjectBootstrapJobParams,
+) (*entity.ProjectBootstrapJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+// Create queues the bootstrap of a project which does not exist yet, one at a time per
+// project.
+func (uc *ProjectBootstrap) Create(
+	ctx context.Context,
+	params *entity.CreateProjectBootstrapJobParams,
+) (*entity.ProjectBootstrapJob, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if params.Template == nil && (params.Settings || params.Directories || params.Categories) {
+		return nil, fmt.Errorf(
+			"%w: a template project is required to copy its scaffolding", entity.ErrBadRequest,
+		)
+	}
+	if params.Template != nil && *params.Template == params.Project {
+		return nil, fmt.Errorf(
+			"%w: project %s cannot be its own template", entity.ErrBadRequest, params.Project,
+		)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ProjectBootstrapJob
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		err := uc.checkForProject(tx, params.Project)
+		if err == nil {
+			return fmt.Errorf(
+				"%w: project %s is already exists", entity.ErrConflict, params.Project,
+			)
+		}
+		if !errors.Is(err, entity.ErrRecordNotFound) {
+			return err
+		}
+		if params.Template != nil {
+			if err := uc.checkForProject(tx, *params.Template); err != nil {
+				return err
+			}
+		}
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RunWorker runs the queued bootstrap jobs every interval until ctx is done.
+func (uc *ProjectBootstrap) RunWorker(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.Process(ctx, lgr); err != nil {
+			lgr.Errorf("[ProjectBootstrap] failed to process bootstrap jobs: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[ProjectBootstrap] finished %d bootstrap jobs", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process fails the interrupted jobs and runs the queued jobs one by one. It returns the
+// number of the jobs it ran.
+func (uc *ProjectBootstrap) Process(ctx context.Context, lgr entity.Logger) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	// the steps of a job run in one transaction bounded by the timeout
+	before := time.Now().UTC().Add(-uc.BootstrapTimeout - time.Minute)
+	if n, err := uc.repo.FailStale(db, before); err != nil {
+		return 0, err
+	} else if n > 0 {
+		lgr.Warnf("[ProjectBootstrap] failed %d interrupted bootstrap jobs", n)
+	}
+
+	var finished int
+	for ctx.Err() == nil {
+		claimCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		e, err := uc.repo.Claim(uc.repo.WithContext(claimCtx))
+		cancel()
+		if err != nil {
+			return finished, err
+		}
+		if e == nil {
+			break
+		}
+		if err := uc.run(ctx, lgr, e); err != nil {
+			lgr.Warnf("[ProjectBootstrap] failed to run bootstrap job %d: %v", e.ID, err)
+		}
+		finished++
+	}
+	return finished, nil
+}
+
+// progress records the step the job starts, logging the failures which do not stop it. It is
+// written outside of the transaction of the steps for the step to be seen while it runs.
+func (uc *ProjectBootstrap) progress(
+	ctx context.Context,
+	lgr entity.Logger,
+	e *entity.ProjectBootstrapJob,
+	step string,
+	done int64,
+) {
+	writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	if err := uc.repo.Progress(
+		uc.repo.WithContext(writeCtx), e.ID, step, e.Total, done, &e.Result,
+	); err != nil {
+		lgr.Warnf(
+			"[ProjectBootstrap] failed to record the progress of bootstrap job %d: %v", e.ID, err,
+		)
+	}
+}
+
+// step runs a step of a claimed job in tx.
+func (uc *ProjectBootstrap) step(tx *gorm.DB, e *entity.ProjectBootstrapJob, step string) error {
+	switch step {
+	case entity.ProjectBootstrapStepProject:
+		return uc.repo.CreateProject(tx, e.Project, e.CreatedBy)
+	case entity.ProjectBootstrapStepSettings:
+		for _, group := range []entity.PipelineSettingGroup{entity.Config, entity.Preference} {
+			n, err := uc.settingRepo.CopyProjectValues(
+				tx, group, *e.Template, e.Project, &e.CreatedBy,
+			)
+			if err != nil {
+				return err
+			}
+			e.Result.Settings += n
+		}
+	case entity.ProjectBootstrapStepDirectories:
+		n, err := uc.dirRepo.Copy(tx, *e.Template, e.Project, e.CreatedBy)
+		if err != nil {
+			return err
+		}
+		e.Result.Directories = n
+	case entity.ProjectBootstrapStepCategories:
+		categories, groups, err := uc.categoryRepo.Copy(tx, *e.Template, e.Project, e.CreatedBy)
+		if err != nil {
+			return err
+		}
+		e.Result.Categories = categories
+		e.Result.Groups = groups
+	default:
+		return fmt.Errorf("unknown bootstrap step %q", step)
+	}
+	return nil
+}
+
+// run creates the project of a claimed job and its scaffolding in one transaction, so that a
+// failed bootstrap leaves no partial project and can be queued again.
+func (uc *ProjectBootstrap) run(
+	ctx context.Context,
+	lgr entity.Logger,
+	e *entity.ProjectBootstrapJob,
+) error {
+	steps := e.Steps()
+	e.Total = int64(len(steps))
+	runCtx, cancelRun := context.WithTimeout(ctx, uc.BootstrapTimeout)
+	defer cancelRun()
+	runErr := uc.repo.TransactionWithContext(runCtx, func(tx *gorm.DB) error {
+		for i, step := range steps {
+			uc.progress(ctx, lgr, e, step, int64(i))
+			if err := uc.step(tx, e, step); err != nil {
+				return fmt.Errorf("step %s: %w", step, err)
+			}
+		}
+		return nil
+	})
+
+	writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(writeCtx)
+	if runErr != nil {
+		if err := uc.repo.Fail(db, e.ID, runErr.Error()); err != nil {
+			return err
+		}
+		return runErr
+	}
+	return uc.repo.Complete(db, e.ID, &e.Result)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type ProjectQuota struct {
+	repo         *repository.ProjectQuota
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewProjectQuota(
+	repo *repository.ProjectQuota,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ProjectQuota {
+	return &ProjectQuota{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *ProjectQuota) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *ProjectQuota) List(
+	ctx context.Context,
+	params *entity.ListProjectQuotasParams,
+) ([]*entity.ProjectQuota, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *ProjectQuota) Update(
+	ctx context.Context,
+	params *entity.UpdateProjectQuotaParams,
+) (*entity.ProjectQuota, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ProjectQuota
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *ProjectQuota) Delete(
+	ctx context.Context,
+	params *entity.DeleteProjectQuotaParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+// Consume counts a request of the operation against the quota of the project. The usage is
+// nil when the operation has no quota.
+func (uc *ProjectQuota) Consume(
+	ctx context.Context,
+	params *entity.ConsumeProjectQuotaParams,
+) (*entity.QuotaUsage, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var usage *entity.QuotaUsage
+	var consumeErr error
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		usage, err = uc.repo.Consume(tx, params)
+		if err != nil && usage != nil {
+			// used up, there is nothing to roll back
+			consumeErr = err
+			return nil
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return usage, consumeErr
+}
+
+// Release gives back a request counted by Consume.
+func (uc *ProjectQuota) Release(
+	ctx context.Context,
+	params *entity.ConsumeProjectQuotaParams,
+	usage *entity.QuotaUsage,
+) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.Release(uc.repo.WithContext(timeoutCtx), params, usage)
+}
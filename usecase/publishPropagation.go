@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type PublishPropagation struct {
+	repo         *repository.PublishPropagation
+	prjRepo      *repository.ProjectInfo
+	outboxRepo   *repository.NotificationOutbox
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewPublishPropagation(
+	repo *repository.PublishPropagation,
+	pr *repository.ProjectInfo,
+	or *repository.NotificationOutbox,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *PublishPropagation {
+	return &PublishPropagation{
+		repo:         repo,
+		prjRepo:      pr,
+		outboxRepo:   or,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *PublishPropagation) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *PublishPropagation) Get(
+	ctx context.Context,
+	params *entity.GetPublishPropagationParams,
+) (*entity.PublishPropagation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.Get(db, params)
+}
+
+func (uc *PublishPropagation) Report(
+	ctx context.Context,
+	params *entity.ReportPublishPropagationParams,
+) (*entity.StudioPropagation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.StudioPropagation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Report(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RunOverdueChecker checks the overdue propagations every interval until ctx is done.
+func (uc *PublishPropagation) RunOverdueChecker(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.CheckOverdue(ctx); err != nil {
+			lgr.Errorf("[Propagation] failed to check overdue propagations: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[Propagation] detected %d overdue propagations", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOverdue records the new overdue propagations and enqueues their alerts in the same
+// transaction, so that each of them is alerted exactly once. It returns their number.
+func (uc *PublishPropagation) CheckOverdue(ctx context.Context) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var detected int
+	err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		overdue, err := uc.repo.DetectOverdue(tx, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		for project, propagations := range overdue {
+			detected += len(propagations)
+			if err := uc.outboxRepo.Enqueue(
+				tx, entity.PropagationOverdueNotificationKind, project,
+				&entity.PropagationOverdueNotification{
+					Project:      project,
+					Deadline:     uc.repo.Deadline(),
+					Propagations: propagations,
+				},
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return detected, err
+}
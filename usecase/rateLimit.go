@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"expvar"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+)
+
+// rateLimitedMetric exports the requests rejected by the rate limits with the other expvar
+// variables.
+var rateLimitedMetric = expvar.NewInt("http_rate_limited")
+
+type RateLimit struct {
+	repo *repository.RateLimiter
+}
+
+func NewRateLimit(repo *repository.RateLimiter) *RateLimit {
+	return &RateLimit{
+		repo: repo,
+	}
+}
+
+// Allow takes a request of a client from its buckets, and tells when it may retry otherwise.
+func (uc *RateLimit) Allow(params *entity.RateLimitParams) *entity.RateLimitDecision {
+	decision := uc.repo.Allow(params)
+	if !decision.Allowed {
+		rateLimitedMetric.Add(1)
+	}
+	return decision
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Reclamation struct {
+	repo         *repository.Reclamation
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewReclamation(
+	repo *repository.Reclamation,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Reclamation {
+	return &Reclamation{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *Reclamation) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *Reclamation) List(
+	ctx context.Context,
+	params *entity.ListReclamationsParams,
+) ([]*entity.Reclamation, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *Reclamation) Get(
+	ctx context.Context,
+	params *entity.GetReclamationParams,
+) (*entity.Reclamation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+// Manifest returns an approved or completed reclamation, whose takes are listed in the
+// deletion manifest of the storage team.
+func (uc *Reclamation) Manifest(
+	ctx context.Context,
+	params *entity.GetReclamationParams,
+) (*entity.Reclamation, error) {
+	e, err := uc.Get(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != entity.ReclamationApproved && e.Status != entity.ReclamationCompleted {
+		return nil, fmt.Errorf(
+			"%w: reclamation with ID %d is %s", entity.ErrBadRequest, e.ID, e.Status,
+		)
+	}
+	return e, nil
+}
+
+func (uc *Reclamation) Create(
+	ctx context.Context,
+	params *entity.CreateReclamationParams,
+) (*entity.Reclamation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Reclamation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Reclamation) Review(
+	ctx context.Context,
+	params *entity.ReviewReclamationParams,
+) (*entity.Reclamation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Reclamation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Review(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Reclamation) Complete(
+	ctx context.Context,
+	params *entity.CompleteReclamationParams,
+) (*entity.Reclamation, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Reclamation
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Complete(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
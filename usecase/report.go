@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+const (
+	// submissionHeatmapTTL is how long submission heatmaps are cached.
+	submissionHeatmapTTL = time.Hour
+	// submissionHeatmapMaxRange limits the period a heatmap covers.
+	submissionHeatmapMaxRange = 3 * 366 * 24 * time.Hour
+)
+
+type Report struct {
+	repo         *repository.Report
+	prjRepo      *repository.ProjectInfo
+	calRepo      *repository.WorkCalendar
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	heatmapMu    sync.Mutex
+	heatmapCache map[string]*entity.SubmissionHeatmap
+}
+
+func NewReport(
+	repo *repository.Report,
+	pr *repository.ProjectInfo,
+	cr *repository.WorkCalendar,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Report {
+	return &Report{
+		repo:         repo,
+		prjRepo:      pr,
+		calRepo:      cr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		heatmapCache: map[string]*entity.SubmissionHeatmap{},
+	}
+}
+
+func (uc *Report) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// GetReviewerLoad reports the pending reviews and the response latency of each reviewer per
+// phase, in the working time of the studios, and suggests how to redistribute pending reviews evenly among the reviewers of each
+// phase.
+func (uc *Report) GetReviewerLoad(
+	ctx context.Context,
+	params *entity.GetReviewerLoadParams,
+) (*entity.ReviewerLoadReport, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	since := time.Now().UTC().AddDate(0, 0, -params.Days)
+	clocks, err := uc.calRepo.Clocks(db)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := uc.repo.ListReviewerActivities(db, params, since, clocks)
+	if err != nil {
+		return nil, err
+	}
+	pendings, err := uc.repo.ListPendingReviews(db, params)
+	if err != nil {
+		return nil, err
+	}
+
+	type loadKey struct {
+		phase    string
+		reviewer string
+	}
+	loads := map[loadKey]*entity.ReviewerLoad{}
+	getLoad := func(phase, reviewer string) *entity.ReviewerLoad {
+		k := loadKey{phase, reviewer}
+		l, ok := loads[k]
+		if !ok {
+			l = &entity.ReviewerLoad{
+				Reviewer: reviewer,
+				Phase:    phase,
+			}
+			loads[k] = l
+		}
+		return l
+	}
+	for _, a := range activities {
+		l := getLoad(a.Phase, a.Reviewer)
+		l.ReviewedReviews = a.ReviewedReviews
+		hours := a.AverageLatencySeconds / 3600
+		l.AverageLatencyHours = &hours
+	}
+
+	// Pending reviews of each phase and reviewer, oldest first. Reviews without a reviewer
+	// are kept under the empty name.
+	queues := map[string]map[string][]int32{}
+	unassigned := 0
+	for _, p := range pendings {
+		if p.Reviewer == "" {
+			unassigned++
+		} else {
+			getLoad(p.Phase, p.Reviewer).PendingReviews++
+		}
+		if queues[p.Phase] == nil {
+			queues[p.Phase] = map[string][]int32{}
+		}
+		queues[p.Phase][p.Reviewer] = append(queues[p.Phase][p.Reviewer], p.ReviewInfoID)
+	}
+
+	report := &entity.ReviewerLoadReport{
+		Project:           params.Project,
+		SinceUTC:          since,
+		Loads:             make([]*entity.ReviewerLoad, 0, len(loads)),
+		UnassignedReviews: unassigned,
+		Suggestions:       []*entity.ReviewerLoadSuggestion{},
+	}
+	reviewers := map[string][]*entity.ReviewerLoad{}
+	for _, l := range loads {
+		report.Loads = append(report.Loads, l)
+		reviewers[l.Phase] = append(reviewers[l.Phase], l)
+	}
+	sort.Slice(report.Loads, func(i, j int) bool {
+		a, b := report.Loads[i], report.Loads[j]
+		if a.Phase != b.Phase {
+			return a.Phase < b.Phase
+		}
+		if a.PendingReviews != b.PendingReviews {
+			return a.PendingReviews > b.PendingReviews
+		}
+		return a.Reviewer < b.Reviewer
+	})
+
+	phases := make([]string, 0, len(queues))
+	for phase := range queues {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	for _, phase := range phases {
+		report.Suggestions = append(
+			report.Suggestions, suggestRedistribution(phase, reviewers[phase], queues[phase])...,
+		)
+	}
+	return report, nil
+}
+
+// suggestRedistribution moves the most recent pending reviews of the reviewers above the even
+// share of the phase, and the unassigned ones, to the reviewers below it. Reviewers with the
+// least pending reviews, then the fastest ones, receive reviews first.
+func suggestRedistribution(
+	phase string,
+	loads []*entity.ReviewerLoad,
+	queues map[string][]int32,
+) []*entity.ReviewerLoadSuggestion {
+	if len(loads) == 0 {
+		return nil
+	}
+	total := 0
+	for _, q := range queues {
+		total += len(q)
+	}
+	share := (total + len(loads) - 1) / len(loads)
+
+	receivers := make([]*entity.ReviewerLoad, len(loads))
+	copy(receivers, loads)
+	sort.Slice(receivers, func(i, j int) bool {
+		a, b := receivers[i], receivers[j]
+		if a.PendingReviews != b.PendingReviews {
+			return a.PendingReviews < b.PendingReviews
+		}
+		if (a.AverageLatencyHours == nil) != (b.AverageLatencyHours == nil) {
+			return a.AverageLatencyHours != nil
+		}
+		if a.AverageLatencyHours != nil && *a.AverageLatencyHours != *b.AverageLatencyHours {
+			return *a.AverageLatencyHours < *b.AverageLatencyHours
+		}
+		return a.Reviewer < b.Reviewer
+	})
+	room := map[string]int{}
+	for _, l := range receivers {
+		room[l.Reviewer] = share - len(queues[l.Reviewer])
+	}
+
+	// Unassigned reviews are handed out first, then the excess of the most loaded reviewers.
+	donors := []string{""}
+	for i := len(receivers) - 1; i >= 0; i-- {
+		donors = append(donors, receivers[i].Reviewer)
+	}
+
+	var suggestions []*entity.ReviewerLoadSuggestion
+	for _, from := range donors {
+		excess := queues[from]
+		if from != "" {
+			if len(excess) <= share {
+				continue
+			}
+			excess = excess[share:]
+		}
+		for _, to := range receivers {
+			if len(excess) == 0 {
+				break
+			}
+			n := room[to.Reviewer]
+			if n <= 0 || to.Reviewer == from {
+				continue
+			}
+			if n > len(excess) {
+				n = len(excess)
+			}
+			suggestions = append(suggestions, &entity.ReviewerLoadSuggestion{
+				Phase:         phase,
+				From:          from,
+				To:            to.Reviewer,
+				ReviewInfoIDs: excess[:n],
+			})
+			room[to.Reviewer] -= n
+			excess = excess[n:]
+		}
+	}
+	return suggestions
+}
+
+// GetSubmissionHeatmap counts the submissions per period, root and phase. Heatmaps are cached
+// in memory for an hour, so recent submissions may take that long to show up unless the
+// caller limits the staleness.
+func (uc *Report) GetSubmissionHeatmap(
+	ctx context.Context,
+	params *entity.GetSubmissionHeatmapParams,
+) (*entity.SubmissionHeatmap, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if params.To.Sub(params.From) > submissionHeatmapMaxRange {
+		return nil, fmt.Errorf(
+			"%w: heatmap range must not exceed %d days",
+			entity.ErrBadRequest, submissionHeatmapMaxRange/(24*time.Hour),
+		)
+	}
+	from := params.From.UTC().Format("2006-01-02")
+	to := params.To.UTC().Format("2006-01-02")
+	key := params.Project + "/" + from + "/" + to + "/" + params.GroupBy
+	now := time.Now().UTC()
+
+	maxAge := submissionHeatmapTTL
+	if params.MaxStaleness != nil && *params.MaxStaleness < maxAge {
+		maxAge = *params.MaxStaleness
+	}
+
+	uc.heatmapMu.Lock()
+	cached, ok := uc.heatmapCache[key]
+	uc.heatmapMu.Unlock()
+	if ok && now.Sub(cached.DataAsOf) < maxAge {
+		return cached, nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	counts, err := uc.repo.ListSubmissionCounts(db, params)
+	if err != nil {
+		return nil, err
+	}
+	if counts == nil {
+		counts = []*entity.SubmissionCount{}
+	}
+	heatmap := &entity.SubmissionHeatmap{
+		Project:        params.Project,
+		From:           from,
+		To:             to,
+		GroupBy:        params.GroupBy,
+		Counts:         counts,
+		GeneratedAtUTC: now,
+		DataAsOf:       now,
+	}
+
+	uc.heatmapMu.Lock()
+	for k, h := range uc.heatmapCache {
+		if now.Sub(h.DataAsOf) >= submissionHeatmapTTL {
+			delete(uc.heatmapCache, k)
+		}
+	}
+	uc.heatmapCache[key] = heatmap
+	uc.heatmapMu.Unlock()
+	return heatmap, nil
+}
+
+// InvalidateHeatmaps drops the cached submission heatmaps of a project, or of all the
+// projects when project is empty.
+func (uc *Report) InvalidateHeatmaps(ctx context.Context, project string) {
+	uc.heatmapMu.Lock()
+	defer uc.heatmapMu.Unlock()
+	for k, h := range uc.heatmapCache {
+		if project == "" || h.Project == project {
+			delete(uc.heatmapCache, k)
+		}
+	}
+}
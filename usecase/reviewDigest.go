@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type ReviewDigest struct {
+	repo         *repository.ReviewDigest
+	slaRepo      *repository.ReviewSLA
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewReviewDigest(
+	repo *repository.ReviewDigest,
+	sr *repository.ReviewSLA,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ReviewDigest {
+	return &ReviewDigest{
+		repo:         repo,
+		slaRepo:      sr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+// PendingHours is the pending time of the reviews listed by the scheduled digests.
+func (uc *ReviewDigest) PendingHours() int32 {
+	return uc.repo.PendingHours()
+}
+
+// Interval is the interval of the scheduled digests, 0 when they are only run on demand.
+func (uc *ReviewDigest) Interval() time.Duration {
+	return uc.repo.Interval()
+}
+
+// RunScheduler sends the digests every interval until ctx is done.
+func (uc *ReviewDigest) RunScheduler(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		run, err := uc.Run(ctx, &entity.RunReviewDigestsParams{
+			PendingHours: uc.repo.PendingHours(),
+		})
+		if err != nil {
+			lgr.Errorf("[Digest] failed to run review digests: %v", err)
+			continue
+		}
+		for _, f := range run.Failures {
+			lgr.Errorf("[Digest] failed to send review digest to %s: %s", f.MailAddress, f.Error)
+		}
+		if run.Sent > 0 {
+			lgr.Infof("[Digest] sent %d review digests", run.Sent)
+		}
+	}
+}
+
+// Run compiles the digests of the supervisors listed by the review SLAs, each with the reviews
+// of the assets of the phases they lead which have been pending for longer than
+// params.PendingHours, and sends them unless params.DryRun. A digest which fails to be sent is
+// reported in the run without failing the others.
+func (uc *ReviewDigest) Run(
+	ctx context.Context,
+	params *entity.RunReviewDigestsParams,
+) (*entity.ReviewDigestRun, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if !params.DryRun && !uc.repo.CanSend() {
+		return nil, fmt.Errorf("%w: PPI_DIGEST_SMTP_ADDRESS is not set", entity.ErrBadRequest)
+	}
+	now := time.Now().UTC()
+	before := now.Add(-time.Duration(params.PendingHours) * time.Hour)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	slas, err := uc.slaRepo.ListAll(db)
+	if err != nil {
+		return nil, err
+	}
+	digests := map[string]*entity.SupervisorDigest{}
+	for _, sla := range slas {
+		if len(sla.MailAddresses) == 0 {
+			continue
+		}
+		reviews, err := uc.repo.ListPending(db, sla.Project, sla.Phase, before)
+		if err != nil {
+			return nil, err
+		}
+		if len(reviews) == 0 {
+			continue
+		}
+		for _, address := range sla.MailAddresses {
+			d, ok := digests[address]
+			if !ok {
+				d = &entity.SupervisorDigest{MailAddress: address}
+				digests[address] = d
+			}
+			d.Reviews = append(d.Reviews, reviews...)
+		}
+	}
+
+	run := &entity.ReviewDigestRun{
+		PendingHours: params.PendingHours,
+		Failures:     []*entity.ReviewDigestFailure{},
+		RanAtUTC:     now,
+	}
+	addresses := make([]string, 0, len(digests))
+	for address := range digests {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		d := digests[address]
+		sort.SliceStable(d.Reviews, func(i, j int) bool {
+			return d.Reviews[i].PendingSinceUTC.Before(d.Reviews[j].PendingSinceUTC)
+		})
+		run.Supervisors++
+		run.Reviews += len(d.Reviews)
+		if params.DryRun {
+			run.Digests = append(run.Digests, d)
+			continue
+		}
+		if err := uc.repo.Send(d, params.PendingHours); err != nil {
+			run.Failures = append(run.Failures, &entity.ReviewDigestFailure{
+				MailAddress: address,
+				Error:       err.Error(),
+			})
+			continue
+		}
+		run.Sent++
+	}
+	return run, nil
+}
@@ -0,0 +1,891 @@
+/* ──────────────────────────────────────────────────────────────────────────
+	Module Name:
+    	usecase/reviewInfo.go
+
+	Module Description:
+		Usecase layer for managing review information.
+
+	Details:
+
+	Update and Modification History:
+	* - 29-10-2025 - SanjayK PSI - Implemented dynamic filtering and sorting for latest submissions.
+	* - 17-11-2025 - SanjayK PSI - Added phase-aware status filtering and sorting.
+	* - 22-11-2025 - SanjayK PSI - Fixed bugs related to phase-specific filtering and sorting.
+	* - 15-10-2026 - Added review intent settings and moved pivot paging into the repository.
+	* - 15-10-2026 - Added approval gating on upstream dependencies.
+	* - 15-10-2026 - Added validation of custom metadata on reviews.
+	* - 15-10-2026 - Added the audit log of corrected submitted fields.
+	* - 15-10-2026 - Added automatic watching and watcher notifications of review events.
+	* - 15-10-2026 - Added the rebuild of the materialized latest reviews.
+	* - 15-10-2026 - Added the per phase rollup of the shots of a sequence.
+	* - 15-10-2026 - Added reviews created before their AllFiles manifest and their expiry.
+	* - 15-10-2026 - Added the webhook events of created reviews and approval changes.
+	* - 15-10-2026 - Added the reverse lookup of the reviews linked to a publish transaction.
+	* - 15-10-2026 - Added the chat notifications of approval status transitions of assets.
+	* - 15-10-2026 - Added the per asset submission rate limit of projects to Create.
+	* - 15-10-2026 - Added the restore, listing and purge of deleted reviews.
+	* - 15-10-2026 - Kept the validation errors wrapped for the field errors of the responses.
+
+	Functions:
+	* - List: Retrieves a list of review information based on parameters.
+	* - Get: Fetches a specific review information entry.
+	* - Create: Creates a new review information entry, within the submission limit of its asset.
+	* - Update: Updates an existing review information entry, through the approval gate of its phase.
+	* - UpdateManifest: Uploads the pending AllFiles manifest of a review information entry.
+	* - RunManifestExpiry: Deletes the entries whose pending manifest was not uploaded in time.
+	* - Restore: Undoes the deletion of a review information entry.
+	* - ListDeleted: Lists the deleted review information entries of a project.
+	* - Purge: Removes for good the entries deleted before the retention window.
+	* - notifyWatchers: Auto-watches the asset or shot of a review and queues its watcher notification.
+	* - ListAuditLogs: Lists the corrections of the submitted fields of a review.
+	* - ListPublishTransactionReviewInfos: Lists the reviews linked to a publish transaction.
+	* - mergeMetadata: Validates custom metadata against the project's field definitions.
+	* - GetIntentSetting: Fetches the intents hidden by default for a project.
+	* - UpdateIntentSetting: Changes the intents hidden by default for a project.
+	* - ListApprovalGates: Lists the approval gates of a project.
+	* - UpdateApprovalGate: Changes the approval gate of a project's phase.
+	* - CheckApprovalGate: Checks the upstream dependencies of a review before approving it.
+	* - approvalGate: Collects the unapproved upstream dependencies of a review for its gate.
+	* - RebuildLatest: Rebuilds the latest reviews of a project read by the pivot and counts.
+	* - GetSequenceRollup: Counts the shots of a sequence per phase and status.
+	* - ListAssetsPivot: Provides filtered, phase-aware pivoted asset data.
+
+	────────────────────────────────────────────────────────────────────────── */
+
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type ReviewInfo struct {
+	repo         *repository.ReviewInfo
+	prjRepo      *repository.ProjectInfo
+	stuRepo      *repository.StudioInfo
+	docRepo      entity.DocumentRepository
+	depRepo      *repository.DataDepRepository
+	cfRepo       *repository.CustomField
+	watcherRepo  *repository.Watcher
+	outboxRepo   *repository.NotificationOutbox
+	slRepo       *repository.SubmissionLimit
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewReviewInfo(
+	repo *repository.ReviewInfo,
+	pr *repository.ProjectInfo,
+	sr *repository.StudioInfo,
+	dr entity.DocumentRepository,
+	ddr *repository.DataDepRepository,
+	cfr *repository.CustomField,
+	wr *repository.Watcher,
+	obr *repository.NotificationOutbox,
+	slr *repository.SubmissionLimit,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ReviewInfo {
+	return &ReviewInfo{
+		repo:         repo,
+		prjRepo:      pr,
+		stuRepo:      sr,
+		docRepo:      dr,
+		depRepo:      ddr,
+		cfRepo:       cfr,
+		watcherRepo:  wr,
+		outboxRepo:   obr,
+		slRepo:       slr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *ReviewInfo) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *ReviewInfo) checkForStudio(db *gorm.DB, studio string) error {
+	_, err := uc.stuRepo.Get(db, &entity.GetStudioInfoParams{
+		KeyName: studio,
+	})
+	return err
+}
+
+func (uc *ReviewInfo) List(
+	ctx context.Context,
+	params *entity.ListReviewInfoParams,
+) ([]*entity.ReviewInfo, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	if params.Studio != nil {
+		if err := uc.checkForStudio(db, *params.Studio); err != nil {
+			return nil, 0, err
+		}
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *ReviewInfo) Get(
+	ctx context.Context,
+	params *entity.GetReviewParams,
+) (*entity.ReviewInfo, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.Get(db, params)
+}
+
+func (uc *ReviewInfo) Create(
+	ctx context.Context,
+	params *entity.CreateReviewInfoParams,
+) (*entity.ReviewInfo, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	if params.ManifestPending &&
+		(len(params.AllFiles) != 0 || params.NumAllFiles != 0 || params.SizeAllFiles != 0) {
+		return nil, fmt.Errorf(
+			"%w: all_files, num_all_files and size_all_files are set by the pending manifest",
+			entity.ErrBadRequest,
+		)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	if err := uc.checkForStudio(db, params.Studio); err != nil {
+		return nil, err
+	}
+	metadata, err := uc.mergeMetadata(db, params.Project, nil, params.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	params.Metadata = metadata
+	var e *entity.ReviewInfo
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.slRepo.CheckSubmission(tx, &entity.CheckSubmissionParams{
+			Project:  params.Project,
+			Root:     params.Root,
+			Groups:   params.Groups,
+			Relation: params.Relation,
+		}); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		if err != nil {
+			return err
+		}
+		if err := uc.outboxRepo.EnqueueWebhookEvent(
+			tx, e.Project, entity.WebhookReviewCreated, e,
+		); err != nil {
+			return err
+		}
+		return uc.notifyWatchers(
+			tx, e, entity.ActivityReviewSubmitted, params.SubmittedUser, entity.WatchReasonSubmitter,
+		)
+	}); err != nil {
+		return nil, err
+	}
+
+	// Create a comment when creating a review.
+	// https://docs.google.com/spreadsheets/d/14VSOi7h_zh5TP0JK3nBXjVoAQhrete3XahPZ96h30Wo/edit#gid=734852926
+	var user string
+	if params.CreatedBy != nil {
+		user = *params.CreatedBy
+	}
+	commentdata := []map[string]interface{}{}
+	defaultrole := "artist"
+	for _, commentinfo := range params.ReviewComments {
+		role := commentinfo.ResponsiblePersonRole
+		if role == nil {
+			role = &defaultrole
+		}
+		comment := map[string]interface{}{
+			"language":                commentinfo.Language,
+			"text":                    commentinfo.Text,
+			"attachments":             commentinfo.Attachments,
+			"need_translation":        commentinfo.NeedTranslation,
+			"is_translated":           commentinfo.IsTranslated,
+			"responsible_person_role": role,
+		}
+		commentdata = append(commentdata, comment)
+	}
+
+	if _, err := uc.docRepo.CreateDocument(
+		context.WithValue(timeoutCtx, entity.KeyUser, user),
+		params.Project,
+		"comment",
+		map[string]interface{}{
+			"root":                 params.Root,
+			"groups":               params.Groups,
+			"relation":             params.Relation,
+			"phase":                params.Phase,
+			"original_comment_id":  nil,
+			"task_id":              params.TaskID,
+			"subtask_id":           params.SubtaskID,
+			"path":                 params.TakePath,
+			"take":                 params.Take,
+			"comment_data":         commentdata,
+			"studio":               params.Studio,
+			"project":              params.Project,
+			"submitted_at_utc":     params.SubmittedAtUtc.Format(time.RFC3339Nano),
+			"submitted_user":       params.SubmittedUser,
+			"submitted_computer":   params.SubmittedComputer,
+			"submitted_os":         params.SubmittedOS,
+			"submitted_os_version": params.SubmittedOSVersion,
+			"component":            params.Component,
+			"type":                 "review",
+			"tool":                 "ppiCentralWeb",
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Update updates the review. An approval goes through the approval gate of the review's phase
+// in the same transaction: it fails with an *entity.ApprovalGateError when the gate blocks it,
+// and the unapproved upstreams are set as ApprovalWarnings when the gate warns about them.
+func (uc *ReviewInfo) Update(
+	ctx context.Context,
+	lgr entity.Logger,
+	params *entity.UpdateReviewInfoParams,
+) (*entity.ReviewInfo, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	var e *entity.ReviewInfo
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var current *entity.ReviewInfo
+		if params.Metadata != nil || params.ApprovalStatus != nil {
+			var err error
+			current, err = uc.repo.Get(tx, &entity.GetReviewParams{
+				Project: params.Project,
+				ID:      params.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		var warnings []*entity.UpstreamReview
+		if params.ApprovalStatus != nil && *params.ApprovalStatus == entity.ApprovalStatusApproved {
+			result, err := uc.approvalGate(timeoutCtx, tx, lgr, current)
+			if err != nil {
+				return err
+			}
+			if len(result.Unapproved) > 0 {
+				if result.Mode == entity.ApprovalGateBlock {
+					return &entity.ApprovalGateError{
+						ID:         current.ID,
+						Unapproved: result.Unapproved,
+					}
+				}
+				warnings = result.Unapproved
+			}
+		}
+		if params.Metadata != nil {
+			metadata, err := uc.mergeMetadata(tx, params.Project, current.Metadata, params.Metadata)
+			if err != nil {
+				return err
+			}
+			params.Metadata = metadata
+		}
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		if err != nil {
+			return err
+		}
+		e.ApprovalWarnings = warnings
+		if params.ApprovalStatus == nil && params.WorkStatus == nil {
+			return nil
+		}
+		if params.ApprovalStatus != nil && current.ApprovalStatus != e.ApprovalStatus {
+			if err := uc.outboxRepo.EnqueueApprovalChange(tx, current.ApprovalStatus, e); err != nil {
+				return err
+			}
+		}
+		var actor string
+		if params.ModifiedBy != nil {
+			actor = *params.ModifiedBy
+		}
+		return uc.notifyWatchers(
+			tx, e, entity.ActivityReviewStatusChanged, actor, entity.WatchReasonAssignee,
+		)
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// UpdateManifest uploads the AllFiles manifest of a review created with a pending manifest.
+func (uc *ReviewInfo) UpdateManifest(
+	ctx context.Context,
+	params *entity.UpdateReviewManifestParams,
+) (*entity.ReviewInfo, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReviewInfo
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.UpdateManifest(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RunManifestExpiry deletes the reviews whose pending manifest is overdue every interval until
+// ctx is done.
+func (uc *ReviewInfo) RunManifestExpiry(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		var n int64
+		err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+			var err error
+			n, err = uc.repo.ExpireManifests(tx, time.Now().UTC())
+			return err
+		})
+		cancel()
+		if err != nil {
+			lgr.Errorf("[ReviewInfo] failed to expire pending manifests: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[ReviewInfo] deleted %d reviews whose manifest was not uploaded", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyWatchers makes the actor watch the asset or shot of the review for the reason, and
+// queues the event of the review for the other watchers.
+func (uc *ReviewInfo) notifyWatchers(
+	tx *gorm.DB,
+	review *entity.ReviewInfo,
+	event entity.ActivityType,
+	actor string,
+	reason string,
+) error {
+	targetType, target, ok := entity.WatchTargetOfReview(review)
+	if !ok {
+		return nil
+	}
+	if actor != "" {
+		if err := uc.watcherRepo.AutoWatch(
+			tx, review.Project, targetType, target, actor, reason,
+		); err != nil {
+			return err
+		}
+	}
+	addresses, err := uc.watcherRepo.MailAddresses(tx, review.Project, targetType, target, actor)
+	if err != nil || len(addresses) == 0 {
+		return err
+	}
+	return uc.outboxRepo.Enqueue(
+		tx, entity.WatchNotificationKind, review.Project, &entity.WatchNotification{
+			Project:        review.Project,
+			TargetType:     targetType,
+			Target:         target,
+			Event:          event,
+			Actor:          actor,
+			ReviewInfoID:   review.ID,
+			Phase:          review.Phase,
+			Take:           review.Take,
+			ApprovalStatus: review.ApprovalStatus,
+			WorkStatus:     review.WorkStatus,
+			MailAddresses:  addresses,
+		},
+	)
+}
+
+// ListAuditLogs returns the corrections of the take path, duration and review target of the
+// review.
+func (uc *ReviewInfo) ListAuditLogs(
+	ctx context.Context,
+	params *entity.ListReviewInfoAuditLogsParams,
+) ([]*entity.ReviewInfoAuditLog, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListAuditLogs(db, params)
+}
+
+// mergeMetadata validates the changed custom field values of a review against the project's
+// review field definitions and returns the values to store.
+func (uc *ReviewInfo) mergeMetadata(
+	db *gorm.DB,
+	project string,
+	current entity.JSONObject,
+	changes entity.JSONObject,
+) (entity.JSONObject, error) {
+	defs, err := uc.cfRepo.ListDefinitions(db, project, entity.CustomFieldTargetReview)
+	if err != nil {
+		return nil, err
+	}
+	return entity.MergeCustomMetadata(defs, current, changes)
+}
+
+func (uc *ReviewInfo) Delete(
+	ctx context.Context,
+	params *entity.DeleteReviewInfoParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+// Restore undoes the deletion of a review.
+func (uc *ReviewInfo) Restore(
+	ctx context.Context,
+	params *entity.RestoreReviewInfoParams,
+) (*entity.ReviewInfo, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReviewInfo
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Restore(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *ReviewInfo) ListDeleted(
+	ctx context.Context,
+	params *entity.ListDeletedReviewInfosParams,
+) ([]*entity.ReviewInfo, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.ListDeleted(db, params)
+}
+
+// Purge removes for good the reviews of a project deleted before the retention window.
+func (uc *ReviewInfo) Purge(
+	ctx context.Context,
+	params *entity.PurgeReviewInfosParams,
+) (*entity.PurgeReviewInfosResult, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.PurgeReviewInfosResult
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Purge(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *ReviewInfo) ListAssets(
+	ctx context.Context,
+	params *entity.AssetListParams,
+) ([]*entity.Asset, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	if params.Studio != nil {
+		if err := uc.checkForStudio(db, *params.Studio); err != nil {
+			return nil, 0, err
+		}
+	}
+	return uc.repo.ListAssets(db, params)
+}
+
+func (uc *ReviewInfo) ListAssetReviewInfos(
+	ctx context.Context,
+	params *entity.AssetReviewInfoListParams,
+) ([]*entity.ReviewInfo, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	if params.Studio != nil {
+		if err := uc.checkForStudio(db, *params.Studio); err != nil {
+			return nil, err
+		}
+	}
+	return uc.repo.ListAssetReviewInfos(db, params)
+}
+
+// ListPublishTransactionReviewInfos lists the reviews linked to a publish transaction, to
+// trace a publish back to its reviews.
+func (uc *ReviewInfo) ListPublishTransactionReviewInfos(
+	ctx context.Context,
+	params *entity.PublishTransactionReviewInfoListParams,
+) ([]*entity.ReviewInfo, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.ListPublishTransactionReviewInfos(db, params)
+}
+
+func (uc *ReviewInfo) ListShotReviewInfos(
+	ctx context.Context,
+	params *entity.ShotReviewInfoListParams,
+) ([]*entity.ReviewInfo, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	if params.Studio != nil {
+		if err := uc.checkForStudio(db, *params.Studio); err != nil {
+			return nil, err
+		}
+	}
+	return uc.repo.ListShotReviewInfos(db, params)
+}
+
+func (uc *ReviewInfo) GetIntentSetting(
+	ctx context.Context,
+	params *entity.GetReviewIntentSettingParams,
+) (*entity.ReviewIntentSetting, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetIntentSetting(db, params)
+}
+
+func (uc *ReviewInfo) UpdateIntentSetting(
+	ctx context.Context,
+	params *entity.UpdateReviewIntentSettingParams,
+) (*entity.ReviewIntentSetting, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReviewIntentSetting
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.UpdateIntentSetting(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+/*
+	──────────────────────────────────────────────────────────────────────────
+
+	ListAssetsPivot retrieves a paginated list of asset pivots for a given project.
+	It supports both flat and grouped views, with sorting and filtering options.
+	Paging, sorting and grouping are done by the repository.
+
+	Parameters:
+	- ctx: Context for request-scoped values and cancellation.
+	- p: repository.ListAssetsPivotParams containing query parameters such as project, root, pagination, sorting, filtering, and view type.
+
+	Returns:
+	- *repository.ListAssetsPivotResult: The result containing assets, groups, pagination info, and sorting details.
+	- error: Non-nil if an error occurred during retrieval.
+
+──────────────────────────────────────────────────────────────────────────
+*/
+func (uc *ReviewInfo) ListAssetsPivot(
+	ctx context.Context,
+	p repository.ListAssetsPivotParams,
+) (*repository.ListAssetsPivotResult, error) {
+	if p.Project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.ListAssetsPivot(uc.repo.WithContext(timeoutCtx), p)
+}
+
+func (uc *ReviewInfo) ListApprovalGates(
+	ctx context.Context,
+	params *entity.ListReviewApprovalGatesParams,
+) ([]*entity.ReviewApprovalGate, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListApprovalGates(db, params)
+}
+
+func (uc *ReviewInfo) UpdateApprovalGate(
+	ctx context.Context,
+	params *entity.UpdateReviewApprovalGateParams,
+) (*entity.ReviewApprovalGate, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReviewApprovalGate
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.UpdateApprovalGate(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RebuildLatest rebuilds the latest reviews of the project from all its reviews. The asset
+// pivot and latest submission counts of a project only read them after its first rebuild.
+func (uc *ReviewInfo) RebuildLatest(
+	ctx context.Context,
+	params *entity.RebuildReviewLatestParams,
+) (*entity.ReviewLatestState, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReviewLatestState
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.RebuildLatest(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetSequenceRollup counts the shots of a sequence approved and submitted per phase, from
+// their latest reviews.
+func (uc *ReviewInfo) GetSequenceRollup(
+	ctx context.Context,
+	params *entity.GetSequenceRollupParams,
+) (*entity.SequenceRollup, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.SequenceRollup(db, params)
+}
+
+// CheckApprovalGate looks up the upstream dependencies of the review in the DataDependency
+// graph and returns those whose latest review is not approved, without approving it. When the
+// gate of the review's phase is in block mode and any of them is unapproved, an
+// *entity.ApprovalGateError is returned along with the result.
+func (uc *ReviewInfo) CheckApprovalGate(
+	ctx context.Context,
+	lgr entity.Logger,
+	project string,
+	id int32,
+) (*entity.ApprovalGateResult, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+
+	review, err := uc.repo.Get(db, &entity.GetReviewParams{
+		Project: project,
+		ID:      id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := uc.approvalGate(timeoutCtx, db, lgr, review)
+	if err != nil {
+		return nil, err
+	}
+	if result.Mode == entity.ApprovalGateBlock && len(result.Unapproved) > 0 {
+		return result, &entity.ApprovalGateError{ID: id, Unapproved: result.Unapproved}
+	}
+	return result, nil
+}
+
+// approvalGate returns the gate mode of the review's phase with the upstream dependencies
+// whose latest review is not approved. With the transaction of an approval, the latest
+// upstream reviews stay locked until it commits.
+func (uc *ReviewInfo) approvalGate(
+	ctx context.Context,
+	db *gorm.DB,
+	lgr entity.Logger,
+	review *entity.ReviewInfo,
+) (*entity.ApprovalGateResult, error) {
+	project := review.Project
+	mode, err := uc.repo.GetApprovalGateMode(db, project, review.Phase)
+	if err != nil {
+		return nil, err
+	}
+	result := &entity.ApprovalGateResult{
+		Mode:       mode,
+		Unapproved: []*entity.UpstreamReview{},
+	}
+	if mode == entity.ApprovalGateOff {
+		return result, nil
+	}
+	if uc.depRepo == nil {
+		lgr.Warnf(
+			"approval gate of phase %q is skipped: DataDependency is not available", review.Phase,
+		)
+		return result, nil
+	}
+	var group string
+	if len(review.Groups) > 0 {
+		group = review.Groups[0]
+	}
+
+	dependencies, _, err := uc.depRepo.ListRevisionDependencies(
+		ctx, lgr,
+		project, review.Root, group, review.Relation, review.Phase, review.Take,
+	)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	var upstreams []*entity.UpstreamReview
+	for _, d := range dependencies {
+		key := strings.Join(
+			[]string{d.Content.Root, d.Content.Group, d.Content.Relation, d.Content.Phase}, "|",
+		)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		upstreams = append(upstreams, &entity.UpstreamReview{
+			Root:     d.Content.Root,
+			Group:    d.Content.Group,
+			Relation: d.Content.Relation,
+			Phase:    d.Content.Phase,
+		})
+	}
+	if err := uc.repo.FillUpstreamApprovals(db, project, upstreams); err != nil {
+		return nil, err
+	}
+	for _, u := range upstreams {
+		if u.ApprovalStatus != entity.ApprovalStatusApproved {
+			result.Unapproved = append(result.Unapproved, u)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type ReviewSLA struct {
+	repo         *repository.ReviewSLA
+	prjRepo      *repository.ProjectInfo
+	outboxRepo   *repository.NotificationOutbox
+	calRepo      *repository.WorkCalendar
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewReviewSLA(
+	repo *repository.ReviewSLA,
+	pr *repository.ProjectInfo,
+	or *repository.NotificationOutbox,
+	cr *repository.WorkCalendar,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ReviewSLA {
+	return &ReviewSLA{
+		repo:         repo,
+		prjRepo:      pr,
+		outboxRepo:   or,
+		calRepo:      cr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *ReviewSLA) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *ReviewSLA) List(
+	ctx context.Context,
+	params *entity.ListReviewSLAsParams,
+) ([]*entity.ReviewSLA, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *ReviewSLA) Update(
+	ctx context.Context,
+	params *entity.UpdateReviewSLAParams,
+) (*entity.ReviewSLA, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReviewSLA
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *ReviewSLA) Delete(
+	ctx context.Context,
+	params *entity.DeleteReviewSLAParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+func (uc *ReviewSLA) ListBreaches(
+	ctx context.Context,
+	params *entity.ListReviewSLABreachesParams,
+) ([]*entity.ReviewSLABreach, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.ListBreaches(db, params)
+}
+
+// RunEvaluator evaluates the SLAs every interval until ctx is done.
+func (uc *ReviewSLA) RunEvaluator(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.Evaluate(ctx); err != nil {
+			lgr.Errorf("[SLA] failed to evaluate SLAs: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[SLA] detected %d breaches", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate resolves the breaches of reviews which got feedback, then records the new breaches
+// of every SLA, in the working time of the studios, and returns their number. The notification to the leads is enqueued in the
+// same transaction as the breaches of each SLA, so that it is sent exactly when they are
+// recorded.
+func (uc *ReviewSLA) Evaluate(ctx context.Context) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if _, err := uc.repo.ResolveBreaches(db); err != nil {
+		return 0, err
+	}
+	slas, err := uc.repo.ListAll(db)
+	if err != nil {
+		return 0, err
+	}
+	clocks, err := uc.calRepo.Clocks(db)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	var detected int
+	for _, sla := range slas {
+		if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+			breaches, err := uc.repo.DetectBreaches(tx, sla, clocks, now)
+			if err != nil {
+				return err
+			}
+			detected += len(breaches)
+			if len(breaches) == 0 || len(sla.MailAddresses) == 0 {
+				return nil
+			}
+			return uc.outboxRepo.Enqueue(
+				tx, entity.SLABreachNotificationKind, sla.Project, &entity.SLABreachNotification{
+					Project:       sla.Project,
+					Phase:         sla.Phase,
+					MailAddresses: sla.MailAddresses,
+					Breaches:      breaches,
+				},
+			)
+		}); err != nil {
+			return detected, err
+		}
+	}
+	return detected, nil
+}
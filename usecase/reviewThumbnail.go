@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type ReviewThumbnail struct {
+	repo         *repository.ReviewThumbnail
+	keyRepo      *repository.MediaKey
+	pinRepo      *repository.ThumbnailPin
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewReviewThumbnail(
+	repo *repository.ReviewThumbnail,
+	keyRepo *repository.MediaKey,
+	pinRepo *repository.ThumbnailPin,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ReviewThumbnail {
+	return &ReviewThumbnail{
+		repo:         repo,
+		keyRepo:      keyRepo,
+		pinRepo:      pinRepo,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+// GetAssetThumbnail returns the thumbnail pinned for the asset, or its latest thumbnail when
+// it is not pinned or the pinned one is missing.
+func (uc *ReviewThumbnail) GetAssetThumbnail(
+	ctx context.Context,
+	params *entity.GetAssetThumbnailParams,
+) (string, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return "", err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	pin, err := uc.pinRepo.Get(
+		uc.pinRepo.WithContext(timeoutCtx), params.Project, params.Asset, params.Relation,
+	)
+	if err != nil {
+		return "", err
+	}
+	if pin != nil {
+		thumbnailPath, err := uc.repo.GetPinnedAssetThumbnail(pin)
+		if err == nil {
+			return thumbnailPath, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	thumbnailPath, err := uc.repo.GetAssetThumbnail(params)
+	return thumbnailPath, err
+}
+
+// PinAssetThumbnail pins an existing thumbnail of a take as the representative image of the
+// asset.
+func (uc *ReviewThumbnail) PinAssetThumbnail(
+	ctx context.Context,
+	params *entity.PinAssetThumbnailParams,
+) (*entity.ThumbnailPin, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if !repository.ValidThumbnailRevision(params.Revision) {
+		return nil, fmt.Errorf("%w: invalid revision %q", entity.ErrBadRequest, params.Revision)
+	}
+	if _, err := uc.repo.GetPinnedAssetThumbnail(&entity.ThumbnailPin{
+		Project:  params.Project,
+		Asset:    params.Asset,
+		Relation: params.Relation,
+		Phase:    params.Phase,
+		Revision: params.Revision,
+		Name:     params.Name,
+	}); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf(
+				"%w: thumbnail %s of revision %s of %s not found",
+				entity.ErrBadRequest, params.Name, params.Revision, params.Phase,
+			)
+		}
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ThumbnailPin
+	if err := uc.pinRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.pinRepo.Put(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// UnpinAssetThumbnail makes the latest thumbnail of the asset its representative image again.
+func (uc *ReviewThumbnail) UnpinAssetThumbnail(
+	ctx context.Context,
+	params *entity.UnpinAssetThumbnailParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.pinRepo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.pinRepo.Delete(tx, params)
+	})
+}
+
+func (uc *ReviewThumbnail) GetShotThumbnail(
+	params *entity.GetShotThumbnailParams,
+) (string, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return "", err
+	}
+	thumbnailPath, err := uc.repo.GetShotThumbnail(params)
+	return thumbnailPath, err
+}
+
+type thumbnailReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// OpenEncryptedThumbnail returns the plaintext of a thumbnail file encrypted with a key of the
+// project, which the caller must close, and records the access of the studio. It returns nil
+// for the plaintext files, which are served as they are.
+func (uc *ReviewThumbnail) OpenEncryptedThumbnail(
+	ctx context.Context,
+	project string,
+	thumbnailPath string,
+	studio string,
+) (io.ReadCloser, error) {
+	f, err := uc.repo.OpenThumbnail(thumbnailPath)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	r, version, err := uc.keyRepo.Decrypt(uc.keyRepo.WithContext(timeoutCtx), project, f)
+	if err != nil || version == 0 {
+		f.Close()
+		return nil, err
+	}
+	if err := uc.recordAccess(ctx, []*entity.MediaAccessLog{{
+		Project:       project,
+		Kind:          entity.MediaKindThumbnail,
+		Path:          thumbnailPath,
+		KeyVersion:    version,
+		Studio:        studio,
+		AccessedAtUTC: time.Now().UTC(),
+	}}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &thumbnailReadCloser{Reader: r, Closer: f}, nil
+}
+
+func (uc *ReviewThumbnail) recordAccess(ctx context.Context, logs []*entity.MediaAccessLog) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.keyRepo.RecordAccess(uc.keyRepo.WithContext(timeoutCtx), logs)
+}
+
+// readThumbnailPreview returns the plaintext of a thumbnail file and the version of its key,
+// or nil when the thumbnail is larger than maxSize.
+func (uc *ReviewThumbnail) readThumbnailPreview(
+	ctx context.Context,
+	project string,
+	thumbnailPath string,
+	maxSize int64,
+) ([]byte, int32, error) {
+	f, err := uc.repo.OpenThumbnail(thumbnailPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	r, version, err := uc.keyRepo.Decrypt(uc.keyRepo.WithContext(timeoutCtx), project, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, 0, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, version, nil
+	}
+	return data, version, nil
+}
+
+// maxInlinePreviewSize is the largest thumbnail file that is embedded into a batch response.
+const maxInlinePreviewSize = 32 * 1024
+
+// BatchGetAssetThumbnails resolves the thumbnails for up to 100 assets. The result keeps the
+// order of params.Keys so the pivot can map it directly onto its rows. Pinned thumbnails are
+// preferred to the latest ones. Inline previews of encrypted thumbnails are decrypted, and
+// their accesses recorded.
+func (uc *ReviewThumbnail) BatchGetAssetThumbnails(
+	ctx context.Context,
+	params *entity.BatchGetAssetThumbnailsParams,
+) ([]*entity.AssetThumbnail, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	paths, err := uc.repo.GetAssetThumbnails(params)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	pins, err := uc.pinRepo.List(uc.pinRepo.WithContext(timeoutCtx), params.Project, params.Keys)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	pinned := make(map[entity.AssetThumbnailKey]bool, len(pins))
+	for key, pin := range pins {
+		thumbnailPath, err := uc.repo.GetPinnedAssetThumbnail(pin)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		paths[key] = thumbnailPath
+		pinned[key] = true
+	}
+
+	thumbnails := make([]*entity.AssetThumbnail, len(params.Keys))
+	var accesses []*entity.MediaAccessLog
+	for i, key := range params.Keys {
+		t := &entity.AssetThumbnail{
+			Asset:    key.Asset,
+			Relation: key.Relation,
+		}
+		thumbnails[i] = t
+		thumbnailPath, ok := paths[key]
+		if !ok {
+			continue
+		}
+		t.URL = entity.PublicPath(fmt.Sprintf(
+			"/api/projects/%s/assets/%s/relations/%s/reviewthumbnail",
+			url.PathEscape(params.Project),
+			url.PathEscape(key.Asset),
+			url.PathEscape(key.Relation),
+		))
+		t.Pinned = pinned[key]
+		if params.Inline {
+			data, version, err := uc.readThumbnailPreview(
+				ctx, params.Project, thumbnailPath, maxInlinePreviewSize,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if data == nil {
+				continue
+			}
+			preview := repository.ThumbnailDataURI(thumbnailPath, data)
+			t.Preview = &preview
+			if version != 0 {
+				accesses = append(accesses, &entity.MediaAccessLog{
+					Project:       params.Project,
+					Kind:          entity.MediaKindThumbnail,
+					Path:          thumbnailPath,
+					KeyVersion:    version,
+					Studio:        params.Studio,
+					AccessedAtUTC: time.Now().UTC(),
+				})
+			}
+		}
+	}
+	if err := uc.recordAccess(ctx, accesses); err != nil {
+		return nil, err
+	}
+	return thumbnails, nil
+}
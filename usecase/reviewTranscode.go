@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// transcodeWorkerUser is recorded as the modifier of the reviews the proxies are registered to.
+const transcodeWorkerUser = "transcode"
+
+type ReviewTranscode struct {
+	repo         *repository.ReviewTranscode
+	reviewRepo   *repository.ReviewInfo
+	prjRepo      *repository.ProjectInfo
+	transcoder   repository.Transcoder
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewReviewTranscode(
+	repo *repository.ReviewTranscode,
+	rr *repository.ReviewInfo,
+	pr *repository.ProjectInfo,
+	transcoder repository.Transcoder,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ReviewTranscode {
+	return &ReviewTranscode{
+		repo:         repo,
+		reviewRepo:   rr,
+		prjRepo:      pr,
+		transcoder:   transcoder,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *ReviewTranscode) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *ReviewTranscode) List(
+	ctx context.Context,
+	params *entity.ListReviewTranscodesParams,
+) ([]*entity.ReviewTranscode, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.List(db, params)
+}
+
+// Create queues the transcode of an image sequence of the review data. The transcode of the
+// sequence which is already queued or running is returned instead of queuing it twice.
+func (uc *ReviewTranscode) Create(
+	ctx context.Context,
+	params *entity.CreateReviewTranscodeParams,
+) (*entity.ReviewTranscode, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	if uc.transcoder == nil {
+		return nil, fmt.Errorf("%w: transcoding is not enabled", entity.ErrBadRequest)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.ReviewTranscode
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		review, err := uc.reviewRepo.Get(tx, &entity.GetReviewParams{
+			Project: params.Project,
+			ID:      params.ReviewInfoID,
+		})
+		if err != nil {
+			return err
+		}
+		source, err := transcodeSource(review, params.Source)
+		if err != nil {
+			return err
+		}
+		e, err = uc.repo.FindActive(tx, params.Project, params.ReviewInfoID, source.Path)
+		if err != nil || e != nil {
+			return err
+		}
+		frames := make([]string, len(source.AllFiles))
+		for i, f := range source.AllFiles {
+			frames[i] = f.Path
+		}
+		sort.Strings(frames)
+		e, err = uc.repo.Create(
+			tx,
+			params,
+			source.Path,
+			frames,
+			transcodeOutput(source.Path),
+			string(uc.transcoder.Backend()),
+		)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// transcodeSource returns the image sequence of the review data at sourcePath, or the first
+// one when sourcePath is nil.
+func transcodeSource(review *entity.ReviewInfo, sourcePath *string) (*libs.Content, error) {
+	for _, c := range review.ReviewData {
+		if !c.IsSequence || len(c.AllFiles) == 0 {
+			continue
+		}
+		if sourcePath == nil || c.Path == *sourcePath {
+			return c, nil
+		}
+	}
+	if sourcePath != nil {
+		return nil, fmt.Errorf(
+			"%w: %q is not an image sequence of the review data", entity.ErrBadRequest, *sourcePath,
+		)
+	}
+	return nil, fmt.Errorf("%w: the review data has no image sequence", entity.ErrBadRequest)
+}
+
+// transcodeOutput returns the path of the proxy movie of the sequence, e.g.
+// "_transcode/name.mov" next to "name.####.exr".
+func transcodeOutput(source string) string {
+	name := strings.TrimSuffix(path.Base(source), path.Ext(source))
+	name = strings.TrimRight(strings.TrimRight(name, "#"), "._")
+	return path.Join(path.Dir(source), "_transcode", name+".mov")
+}
+
+// RunWorker submits the queued transcodes and follows the running ones every interval until
+// ctx is done.
+func (uc *ReviewTranscode) RunWorker(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.Process(ctx, lgr); err != nil {
+			lgr.Errorf("[ReviewTranscode] failed to process transcodes: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[ReviewTranscode] finished %d transcodes", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process advances the transcodes which are not done yet, and returns the number of those
+// which completed or failed.
+func (uc *ReviewTranscode) Process(ctx context.Context, lgr entity.Logger) (int, error) {
+	if uc.transcoder == nil {
+		return 0, nil
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	active, err := uc.repo.ListActive(uc.repo.WithContext(timeoutCtx))
+	if err != nil {
+		return 0, err
+	}
+
+	var finished int
+	for _, e := range active {
+		writeCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		done, err := uc.advance(writeCtx, e)
+		cancel()
+		if err != nil {
+			lgr.Warnf("[ReviewTranscode] failed to process transcode %d: %v", e.ID, err)
+			continue
+		}
+		if done {
+			finished++
+		}
+	}
+	return finished, nil
+}
+
+func (uc *ReviewTranscode) advance(ctx context.Context, e *entity.ReviewTranscode) (bool, error) {
+	if e.Status == entity.TranscodeQueued {
+		jobID, err := uc.transcoder.Submit(ctx, &entity.TranscodeJob{
+			ID:      e.ID,
+			Project: e.Project,
+			Frames:  e.Frames,
+			Output:  e.Output,
+		})
+		if err != nil {
+			return false, err
+		}
+		_, err = uc.repo.MarkSubmitted(uc.repo.WithContext(ctx), e.ID, jobID)
+		return false, err
+	}
+
+	state, err := uc.transcoder.Status(ctx, *e.JobID)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		state = &entity.TranscodeJobState{
+			Status: entity.TranscodeFailed,
+			Error:  "the job is unknown to the transcoder",
+		}
+	} else if err != nil {
+		return false, err
+	}
+	if !state.Status.Done() {
+		return false, nil
+	}
+	return true, uc.repo.TransactionWithContext(ctx, func(tx *gorm.DB) error {
+		if state.Status == entity.TranscodeFailed {
+			_, err := uc.repo.Finish(tx, e.ID, entity.TranscodeFailed, &state.Error)
+			return err
+		}
+		ok, err := uc.repo.Finish(tx, e.ID, entity.TranscodeCompleted, nil)
+		if err != nil || !ok {
+			return err
+		}
+		err = uc.reviewRepo.AddReviewData(tx, e.Project, e.ReviewInfoID, &libs.Content{
+			Path:         e.Output,
+			ToReview:     true,
+			IsAttachment: false,
+			Size:         state.Size,
+			AllFiles: []*libs.File{
+				{Path: e.Output, Size: state.Size},
+			},
+			Type:       "content",
+			IsSequence: false,
+		}, transcodeWorkerUser)
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			// the review was deleted meanwhile, the proxy is left unregistered
+			return nil
+		}
+		return err
+	})
+}
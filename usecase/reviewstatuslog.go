@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type ReviewStatusLog struct {
+	repo         *repository.ReviewStatusLog
+	riRepo       *repository.ReviewInfo
+	prjRepo      *repository.ProjectInfo
+	stuRepo      *repository.StudioInfo
+	outboxRepo   *repository.NotificationOutbox
+	dlgRepo      *repository.Delegation
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewReviewStatusLog(
+	repo *repository.ReviewStatusLog,
+	rr *repository.ReviewInfo,
+	pr *repository.ProjectInfo,
+	sr *repository.StudioInfo,
+	or *repository.NotificationOutbox,
+	dr *repository.Delegation,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *ReviewStatusLog {
+	return &ReviewStatusLog{
+		repo:         repo,
+		riRepo:       rr,
+		prjRepo:      pr,
+		stuRepo:      sr,
+		outboxRepo:   or,
+		dlgRepo:      dr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *ReviewStatusLog) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *ReviewStatusLog) checkForStudio(db *gorm.DB, studio string) error {
+	_, err := uc.stuRepo.Get(db, &entity.GetStudioInfoParams{
+		KeyName: studio,
+	})
+	return err
+}
+
+// checkDelegation checks that the author of the status log may act on behalf of its
+// OnBehalfOf, through a delegation of the project active now.
+func (uc *ReviewStatusLog) checkDelegation(
+	db *gorm.DB,
+	params *entity.CreateReviewStatusLogParams,
+) error {
+	if params.OnBehalfOf == nil {
+		return nil
+	}
+	d, err := uc.dlgRepo.FindActive(
+		db, params.Project, *params.OnBehalfOf, params.CreatedBy, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return fmt.Errorf(
+			"%w: %s has no active delegation from %s in %s",
+			entity.ErrForbidden, params.CreatedBy, *params.OnBehalfOf, params.Project,
+		)
+	}
+	return nil
+}
+
+func (uc *ReviewStatusLog) CheckForReviewStatusesParams(
+	params *entity.GetReviewStatusesParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return err
+	}
+	for _, s := range params.MailAddresses {
+		_, err := mail.ParseAddress(s)
+		if err != nil {
+			return err
+		}
+	}
+	for _, s := range params.MailCCAddresses {
+		_, err := mail.ParseAddress(s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *ReviewStatusLog) List(
+	ctx context.Context,
+	params *entity.ListReviewStatusLogParams,
+) ([]*entity.ReviewStatusLog, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	if params.Studio != nil {
+		if err := uc.checkForStudio(db, *params.Studio); err != nil {
+			return nil, 0, err
+		}
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *ReviewStatusLog) Get(
+	ctx context.Context,
+	params *entity.GetReviewParams,
+) (*entity.ReviewStatusLog, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.Get(db, params)
+}
+
+func (uc *ReviewStatusLog) Create(
+	ctx context.Context,
+	params *entity.CreateReviewStatusLogParams,
+) (*entity.ReviewStatusLog, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	if err := uc.checkForStudio(db, params.Studio); err != nil {
+		return nil, err
+	}
+	var e *entity.ReviewStatusLog
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkDelegation(tx, params); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// updateReviewInfo applies a status change to a review info, queueing the notifications of
+// the change of its approval status, if any.
+func (uc *ReviewStatusLog) updateReviewInfo(
+	tx *gorm.DB,
+	params *entity.UpdateReviewInfoParams,
+) error {
+	if params.ApprovalStatus == nil {
+		_, err := uc.riRepo.Update(tx, params)
+		return err
+	}
+	current, err := uc.riRepo.Get(tx, &entity.GetReviewParams{
+		Project: params.Project,
+		ID:      params.ID,
+	})
+	if err != nil {
+		return err
+	}
+	e, err := uc.riRepo.Update(tx, params)
+	if err != nil || current.ApprovalStatus == e.ApprovalStatus {
+		return err
+	}
+	return uc.outboxRepo.EnqueueApprovalChange(tx, current.ApprovalStatus, e)
+}
+
+// CreateWithNotification applies the status changes to the review infos, records them as
+// status logs and enqueues the notification email in a single transaction. Status logs made
+// on behalf of another supervisor require an active delegation.
+func (uc *ReviewStatusLog) CreateWithNotification(
+	ctx context.Context,
+	updates []*entity.UpdateReviewInfoParams,
+	logs []*entity.CreateReviewStatusLogParams,
+	notification *entity.ReviewStatusLogNotification,
+) error {
+	for _, params := range updates {
+		if err := binding.Validator.ValidateStruct(params); err != nil {
+			return err
+		}
+	}
+	for _, params := range logs {
+		if err := binding.Validator.ValidateStruct(params); err != nil {
+			return err
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, notification.Project); err != nil {
+		return err
+	}
+	if err := uc.checkForStudio(db, notification.Studio); err != nil {
+		return err
+	}
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		for _, params := range logs {
+			if err := uc.checkDelegation(tx, params); err != nil {
+				return err
+			}
+		}
+		for _, params := range updates {
+			if err := uc.updateReviewInfo(tx, params); err != nil {
+				return err
+			}
+		}
+		for _, params := range logs {
+			if _, err := uc.repo.Create(tx, params); err != nil {
+				return err
+			}
+		}
+		if !notification.IsSendEmail {
+			return nil
+		}
+		return uc.outboxRepo.Enqueue(
+			tx, entity.ReviewStatusNotificationKind, notification.Project, notification,
+		)
+	})
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Role struct {
+	repo         *repository.Role
+	prjRepo      *repository.ProjectInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewRole(
+	repo *repository.Role,
+	pr *repository.ProjectInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Role {
+	return &Role{
+		repo:         repo,
+		prjRepo:      pr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *Role) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func (uc *Role) List(ctx context.Context) ([]*entity.Role, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.List(uc.repo.WithContext(timeoutCtx))
+}
+
+func (uc *Role) Get(
+	ctx context.Context,
+	params *entity.GetRoleParams,
+) (*entity.Role, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Role) Update(
+	ctx context.Context,
+	params *entity.UpdateRoleParams,
+) (*entity.Role, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Role
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Role) Delete(
+	ctx context.Context,
+	params *entity.DeleteRoleParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+func (uc *Role) ListUserRoles(
+	ctx context.Context,
+	params *entity.ListUserRolesParams,
+) ([]*entity.UserRole, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.ListUserRoles(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *Role) CreateUserRole(
+	ctx context.Context,
+	params *entity.CreateUserRoleParams,
+) (*entity.UserRole, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.UserRole
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.CreateUserRole(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Role) DeleteUserRole(
+	ctx context.Context,
+	params *entity.DeleteUserRoleParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.DeleteUserRole(tx, params)
+	})
+}
+
+// CheckPermission returns ErrForbidden unless the roles of the user in the project grant the
+// permission. Projects without any role assigned are not enforced, so that the permissions
+// are enabled per project by assigning the roles of its users.
+func (uc *Role) CheckPermission(
+	ctx context.Context,
+	params *entity.CheckPermissionParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	enforced, err := uc.repo.ProjectEnforced(db, params.Project)
+	if err != nil || !enforced {
+		return err
+	}
+	if params.User == "" {
+		return fmt.Errorf(
+			"%w: a user logged in with the SSO or an API key is required in project %s",
+			entity.ErrForbidden, params.Project,
+		)
+	}
+	permissions, err := uc.repo.UserPermissions(db, params.Project, params.User)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(permissions, params.Permission) {
+		return fmt.Errorf(
+			"%w: %q has no role granting %s in project %s",
+			entity.ErrForbidden, params.User, params.Permission, params.Project,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type Schema struct {
+	repo        *repository.Schema
+	prjRepo     *repository.ProjectInfo
+	cfRepo      *repository.CustomField
+	ReadTimeout time.Duration
+}
+
+func NewSchema(
+	repo *repository.Schema,
+	pr *repository.ProjectInfo,
+	cfr *repository.CustomField,
+	readTimeout time.Duration,
+) *Schema {
+	return &Schema{
+		repo:        repo,
+		prjRepo:     pr,
+		cfRepo:      cfr,
+		ReadTimeout: readTimeout,
+	}
+}
+
+// GetReviewInfoExtensions returns the statuses and, for a project, the review custom fields
+// to be added to the review info schema.
+func (uc *Schema) GetReviewInfoExtensions(
+	ctx context.Context,
+	params *entity.GetReviewInfoSchemaParams,
+) (*entity.ReviewInfoSchemaExtensions, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+
+	e := &entity.ReviewInfoSchemaExtensions{}
+	if params.Project != nil {
+		if _, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+			KeyName: *params.Project,
+		}); err != nil {
+			return nil, err
+		}
+		defs, err := uc.cfRepo.ListDefinitions(
+			db, *params.Project, entity.CustomFieldTargetReview,
+		)
+		if err != nil {
+			return nil, err
+		}
+		e.CustomFields = defs
+	}
+	var err error
+	if e.ApprovalStatuses, err = uc.repo.ListReviewStatuses(
+		db, entity.ReviewStatusTypeApproval, params.Project,
+	); err != nil {
+		return nil, err
+	}
+	if e.WorkStatuses, err = uc.repo.ListReviewStatuses(
+		db, entity.ReviewStatusTypeWork, params.Project,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
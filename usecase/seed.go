@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	dataDepEntity "github.com/PolygonPictures/central30-web/front/entity/dataDependency"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Seed struct {
+	repo         *repository.Seed
+	dataDepRepo  *repository.DataDepRepository
+	WriteTimeout time.Duration
+}
+
+// NewSeed returns the usecase of the load testing data. dataDepRepo may be nil, the generated
+// dependencies then being skipped.
+func NewSeed(
+	repo *repository.Seed,
+	dataDepRepo *repository.DataDepRepository,
+	writeTimeout time.Duration,
+) *Seed {
+	return &Seed{
+		repo:         repo,
+		dataDepRepo:  dataDepRepo,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+// Seed generates the projects one transaction each, so that a failure leaves whole projects
+// behind only.
+func (uc *Seed) Seed(
+	ctx context.Context,
+	lgr entity.Logger,
+	params *entity.SeedParams,
+) (*entity.SeedResult, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+
+	projects := uc.repo.ProjectNames(params)
+	if params.Reset {
+		if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+			return uc.repo.Purge(tx, projects)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	rng := rand.New(rand.NewSource(params.Seed))
+	result := &entity.SeedResult{
+		Seed:                params.Seed,
+		Projects:            projects,
+		DependenciesSkipped: uc.dataDepRepo == nil,
+	}
+	for _, project := range projects {
+		var r *entity.SeedResult
+		var dependencies []*entity.SeedDependency
+		if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+			var err error
+			r, dependencies, err = uc.repo.Create(tx, rng, project, params)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		result.Categories += r.Categories
+		result.Groups += r.Groups
+		result.Assets += r.Assets
+		result.Reviews += r.Reviews
+		result.Dependencies += len(dependencies)
+
+		if uc.dataDepRepo == nil {
+			continue
+		}
+		if err := uc.addDependencies(timeoutCtx, lgr, dependencies, params.CreatedBy); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (uc *Seed) addDependencies(
+	ctx context.Context,
+	lgr entity.Logger,
+	dependencies []*entity.SeedDependency,
+	createdBy *string,
+) error {
+	for _, d := range dependencies {
+		c, u := d.Content, d.Upstream
+		if _, err := uc.dataDepRepo.AddContentDependencies(
+			ctx, lgr, c.Project, c.Root, c.Group, c.Relation, c.Phase, c.Component, c.Take, c.FileName,
+			&dataDepEntity.AddContentDependenciesParams{
+				CreatedBy: createdBy,
+				Dependencies: []*dataDepEntity.Dependency{{
+					Root:           u.Root,
+					Group:          u.Group,
+					Relation:       u.Relation,
+					Phase:          u.Phase,
+					Component:      u.Component,
+					Revision:       u.Take,
+					FileName:       u.FileName,
+					StorageSection: dataDepEntity.Shared,
+					FilePath: fmt.Sprintf(
+						"/ppi/%s/%s/%s/%s/%s/%s/%s/%s",
+						u.Project, u.Root, u.Group, u.Relation, u.Phase, u.Component, u.Take,
+						u.FileName,
+					),
+					Strength: dataDepEntity.Strong,
+				}},
+			},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type SettingChangeset struct {
+	repo         *repository.SettingChangeset
+	pr           *repository.ProjectInfo
+	sr           *repository.StudioInfo
+	outboxRepo   *repository.NotificationOutbox
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewSettingChangeset(
+	repo *repository.SettingChangeset,
+	pr *repository.ProjectInfo,
+	sr *repository.StudioInfo,
+	or *repository.NotificationOutbox,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *SettingChangeset {
+	return &SettingChangeset{
+		repo:         repo,
+		pr:           pr,
+		sr:           sr,
+		outboxRepo:   or,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *SettingChangeset) checkForSection(
+	db *gorm.DB,
+	section *entity.SettingChangesetSection,
+) error {
+	var err error
+	switch section.Section() {
+	case entity.ProjectSection:
+		_, err = uc.pr.Get(db, &entity.GetProjectInfoParams{
+			KeyName: *section.Project,
+		})
+	case entity.StudioSection:
+		_, err = uc.sr.Get(db, &entity.GetStudioInfoParams{
+			KeyName: *section.Studio,
+		})
+	case entity.CommonSection:
+		if *section.Common != "default" {
+			return fmt.Errorf("%w: common %q not found", entity.ErrBadRequest, *section.Common)
+		}
+	default:
+		return fmt.Errorf("%w: %s", entity.ErrBadRequest, entity.ErrSectionNotSelected)
+	}
+	if err != nil && errors.Is(err, entity.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %s %q not found", entity.ErrBadRequest, section.Section(), section.Name())
+	}
+	return err
+}
+
+func maskSettingChangeset(e *entity.SettingChangeset) {
+	for _, c := range e.Changes {
+		c.Mask()
+	}
+}
+
+func (uc *SettingChangeset) List(
+	ctx context.Context,
+	params *entity.ListSettingChangesetsParams,
+) ([]*entity.SettingChangeset, uint, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForSection(db, &params.SettingChangesetSection); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *SettingChangeset) Get(
+	ctx context.Context,
+	params *entity.GetSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	e, err := uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+	if err != nil {
+		return nil, err
+	}
+	maskSettingChangeset(e)
+	return e, nil
+}
+
+// Diff compares the changes of the changeset with the live values.
+func (uc *SettingChangeset) Diff(
+	ctx context.Context,
+	params *entity.GetSettingChangesetParams,
+) ([]*entity.SettingChangeDiff, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	diffs, err := uc.repo.Diff(uc.repo.WithContext(timeoutCtx), params)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range diffs {
+		if !d.Encrypted {
+			continue
+		}
+		if d.Current != nil {
+			d.Current = entity.MaskedSettingValue
+		}
+		if d.Proposed != nil {
+			d.Proposed = entity.MaskedSettingValue
+		}
+	}
+	return diffs, nil
+}
+
+func (uc *SettingChangeset) Create(
+	ctx context.Context,
+	params *entity.CreateSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.SettingChangeset
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForSection(tx, &params.SettingChangesetSection); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *SettingChangeset) Delete(
+	ctx context.Context,
+	params *entity.DeleteSettingChangesetParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+func (uc *SettingChangeset) PutChange(
+	ctx context.Context,
+	params *entity.PutSettingChangeParams,
+) (*entity.SettingChange, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.SettingChange
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.PutChange(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	e.Mask()
+	return e, nil
+}
+
+func (uc *SettingChangeset) DeleteChange(
+	ctx context.Context,
+	params *entity.DeleteSettingChangeParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.DeleteChange(tx, params)
+	})
+}
+
+// Publish applies the changes of the draft atomically, or schedules it when PublishAtUTC is in
+// the future.
+func (uc *SettingChangeset) Publish(
+	ctx context.Context,
+	params *entity.PublishSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.SettingChangeset
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		if params.PublishAtUTC != nil && params.PublishAtUTC.After(time.Now()) {
+			e, err = uc.repo.Schedule(tx, params)
+			return err
+		}
+		e, err = uc.publish(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	maskSettingChangeset(e)
+	return e, nil
+}
+
+// Rollback restores the values replaced by the publish of the changeset.
+func (uc *SettingChangeset) Rollback(
+	ctx context.Context,
+	params *entity.RollbackSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.SettingChangeset
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		var err error
+		e, err = uc.repo.Rollback(tx, params)
+		if err != nil {
+			return err
+		}
+		return uc.enqueueChangeEvent(tx, e, params.RolledBackBy)
+	}); err != nil {
+		return nil, err
+	}
+	maskSettingChangeset(e)
+	return e, nil
+}
+
+func (uc *SettingChangeset) publish(
+	tx *gorm.DB,
+	params *entity.PublishSettingChangesetParams,
+) (*entity.SettingChangeset, error) {
+	e, err := uc.repo.Publish(tx, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.enqueueChangeEvent(tx, e, params.PublishedBy); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// enqueueChangeEvent emits the single change event of the publish or rollback, in its
+// transaction.
+func (uc *SettingChangeset) enqueueChangeEvent(
+	tx *gorm.DB,
+	e *entity.SettingChangeset,
+	actor *string,
+) error {
+	n := &entity.SettingChangesetNotification{
+		ChangesetID: e.ID,
+		Title:       e.Title,
+		Section:     e.Section,
+		Entry:       e.Entry,
+		Status:      e.Status,
+	}
+	if actor != nil {
+		n.Actor = *actor
+	}
+	for _, c := range e.Changes {
+		n.Changes = append(n.Changes, &entity.SettingChangeSummary{
+			Group:     c.Group.String(),
+			Key:       c.Key,
+			Operation: c.Operation,
+		})
+	}
+	var project string
+	if e.Section == entity.ProjectSection.String() {
+		project = e.Entry
+	}
+	return uc.outboxRepo.Enqueue(tx, entity.SettingChangesetNotificationKind, project, n)
+}
+
+// RunScheduler publishes the scheduled changesets which are due every interval until ctx is
+// done.
+func (uc *SettingChangeset) RunScheduler(
+	ctx context.Context,
+	lgr entity.Logger,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.PublishDue(ctx, lgr); err != nil {
+			lgr.Errorf("[SettingChangeset] failed to publish scheduled changesets: %v", err)
+		} else if n > 0 {
+			lgr.Infof("[SettingChangeset] published %d scheduled changesets", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue publishes the scheduled changesets which are due, each in its own transaction,
+// and returns their number. A changeset which fails to publish is left scheduled and retried.
+func (uc *SettingChangeset) PublishDue(ctx context.Context, lgr entity.Logger) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	due, err := uc.repo.ListDue(uc.repo.WithContext(timeoutCtx), time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	var published int
+	for _, params := range due {
+		publishCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+		err := uc.repo.TransactionWithContext(publishCtx, func(tx *gorm.DB) error {
+			_, err := uc.publish(tx, params)
+			return err
+		})
+		cancel()
+		if err != nil {
+			lgr.Warnf("[SettingChangeset] failed to publish changeset %d: %v", params.ID, err)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
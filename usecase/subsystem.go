@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+)
+
+// SubsystemProbe checks that an optional subsystem is reachable and usable.
+type SubsystemProbe func(ctx context.Context) error
+
+type subsystem struct {
+	probe SubsystemProbe
+	state entity.Subsystem
+}
+
+// Subsystems tracks the states of the optional subsystems, so that the API starts and keeps
+// serving without them and their routes come back once they recover.
+type Subsystems struct {
+	mu           sync.RWMutex
+	subsystems   map[string]*subsystem
+	names        []string
+	ProbeTimeout time.Duration
+}
+
+func NewSubsystems(probeTimeout time.Duration) *Subsystems {
+	return &Subsystems{
+		subsystems:   map[string]*subsystem{},
+		ProbeTimeout: probeTimeout,
+	}
+}
+
+func (uc *Subsystems) add(name string, s *subsystem) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if _, ok := uc.subsystems[name]; !ok {
+		uc.names = append(uc.names, name)
+	}
+	uc.subsystems[name] = s
+}
+
+// Register adds a subsystem checked by the probe, which is down until it is first probed.
+func (uc *Subsystems) Register(name string, probe SubsystemProbe) {
+	uc.add(name, &subsystem{
+		probe: probe,
+		state: entity.Subsystem{
+			Name:     name,
+			State:    entity.SubsystemDown,
+			SinceUTC: time.Now().UTC(),
+		},
+	})
+}
+
+// Disable adds a subsystem which is not configured, or could not be set up for the reason.
+func (uc *Subsystems) Disable(name string, reason error) {
+	msg := "not configured"
+	if reason != nil {
+		msg = reason.Error()
+	}
+	uc.add(name, &subsystem{
+		state: entity.Subsystem{
+			Name:     name,
+			State:    entity.SubsystemDisabled,
+			Error:    &msg,
+			SinceUTC: time.Now().UTC(),
+		},
+	})
+}
+
+// Probe checks the subsystems which are not disabled and logs the changes of their states.
+func (uc *Subsystems) Probe(ctx context.Context, lgr entity.Logger) {
+	uc.mu.RLock()
+	names := append([]string(nil), uc.names...)
+	uc.mu.RUnlock()
+
+	for _, name := range names {
+		uc.mu.RLock()
+		s := uc.subsystems[name]
+		uc.mu.RUnlock()
+		if s.probe == nil {
+			continue
+		}
+		timeoutCtx, cancel := context.WithTimeout(ctx, uc.ProbeTimeout)
+		err := s.probe(timeoutCtx)
+		cancel()
+
+		now := time.Now().UTC()
+		state := entity.SubsystemUp
+		var msg *string
+		if err != nil {
+			state = entity.SubsystemDown
+			m := err.Error()
+			msg = &m
+		}
+		uc.mu.Lock()
+		if s.state.State != state {
+			if err != nil {
+				lgr.Warnf("[Subsystem] %s is down: %v", name, err)
+			} else {
+				lgr.Infof("[Subsystem] %s is up", name)
+			}
+			s.state.State = state
+			s.state.SinceUTC = now
+		}
+		s.state.Error = msg
+		s.state.CheckedAtUTC = &now
+		uc.mu.Unlock()
+	}
+}
+
+// Run probes the subsystems at the interval until the context is done.
+func (uc *Subsystems) Run(ctx context.Context, lgr entity.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		uc.Probe(ctx, lgr)
+	}
+}
+
+// Check returns an entity.ErrSubsystemUnavailable error unless the subsystem is up.
+func (uc *Subsystems) Check(name string) error {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	s, ok := uc.subsystems[name]
+	if !ok {
+		return fmt.Errorf("%w: %s is not registered", entity.ErrSubsystemUnavailable, name)
+	}
+	if s.state.State == entity.SubsystemUp {
+		return nil
+	}
+	if s.state.Error != nil {
+		return fmt.Errorf(
+			"%w: %s is %s: %s",
+			entity.ErrSubsystemUnavailable, name, s.state.State, *s.state.Error,
+		)
+	}
+	return fmt.Errorf("%w: %s is %s", entity.ErrSubsystemUnavailable, name, s.state.State)
+}
+
+// List returns the states of the subsystems in the order they were added.
+func (uc *Subsystems) List() []*entity.Subsystem {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	entities := make([]*entity.Subsystem, len(uc.names))
+	for i, name := range uc.names {
+		e := uc.subsystems[name].state
+		entities[i] = &e
+	}
+	return entities
+}
+
+// Degraded tells whether a subsystem is not up.
+func (uc *Subsystems) Degraded() bool {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	for _, s := range uc.subsystems {
+		if s.state.State != entity.SubsystemUp {
+			return true
+		}
+	}
+	return false
+}
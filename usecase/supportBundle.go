@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type SupportBundle struct {
+	repo    *repository.SupportBundle
+	logRepo *repository.SupportLog
+}
+
+func NewSupportBundle(
+	repo *repository.SupportBundle,
+	logRepo *repository.SupportLog,
+) *SupportBundle {
+	return &SupportBundle{
+		repo:    repo,
+		logRepo: logRepo,
+	}
+}
+
+// Create gathers the errors and the slow queries of the window, the configuration and the
+// health of the dependencies. The secrets of the configuration are redacted everywhere.
+func (uc *SupportBundle) Create(
+	ctx context.Context,
+	params *entity.CreateSupportBundleParams,
+) (*entity.SupportBundle, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	now := time.Now().UTC()
+	var project string
+	if params.Project != nil {
+		project = *params.Project
+	}
+	since := now.Add(-time.Duration(params.WindowSeconds) * time.Second)
+	errs, queries, bufferedSince := uc.logRepo.List(since, project)
+	config, redactor := uc.repo.Config()
+	for _, e := range errs {
+		e.Message = redactor.Replace(e.Message)
+	}
+	for _, q := range queries {
+		q.SQL = redactor.Replace(q.SQL)
+		q.Error = redactor.Replace(q.Error)
+	}
+	dependencies := uc.repo.CheckDependencies(ctx)
+	for _, d := range dependencies {
+		d.Error = redactor.Replace(d.Error)
+	}
+	return &entity.SupportBundle{
+		Project:          params.Project,
+		WindowSeconds:    params.WindowSeconds,
+		BufferedSinceUTC: bufferedSince,
+		Errors:           errs,
+		SlowQueries:      queries,
+		Config:           config,
+		Dependencies:     dependencies,
+		GeneratedAtUTC:   now,
+		GeneratedBy:      params.CreatedBy,
+	}, nil
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Tag struct {
+	repo         *repository.Tag
+	prjRepo      *repository.ProjectInfo
+	reviewRepo   *repository.ReviewInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewTag(
+	repo *repository.Tag,
+	pr *repository.ProjectInfo,
+	rr *repository.ReviewInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *Tag {
+	return &Tag{
+		repo:         repo,
+		prjRepo:      pr,
+		reviewRepo:   rr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *Tag) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+// checkForTarget checks the format of the target, and that the review exists for reviews.
+func (uc *Tag) checkForTarget(db *gorm.DB, project, targetType, target string) error {
+	switch targetType {
+	case entity.TagTargetReview:
+		id, err := strconv.Atoi(target)
+		if err != nil {
+			return fmt.Errorf("%w: invalid review ID %q", entity.ErrBadRequest, target)
+		}
+		if _, err := uc.reviewRepo.Get(db, &entity.GetReviewParams{
+			Project: project,
+			ID:      int32(id),
+		}); err != nil {
+			return err
+		}
+	case entity.TagTargetAsset, entity.TagTargetShot:
+		return checkTargetFormat(targetType, target)
+	}
+	return nil
+}
+
+// checkTargetFormat checks the format of the target of an asset or shot.
+func checkTargetFormat(targetType, target string) error {
+	switch targetType {
+	case entity.TagTargetAsset:
+		if parts := strings.Split(target, "/"); len(parts) != 2 {
+			return fmt.Errorf(
+				"%w: asset target must be <asset>/<relation>: %q", entity.ErrBadRequest, target,
+			)
+		}
+	case entity.TagTargetShot:
+		if parts := strings.Split(target, "/"); len(parts) != 4 {
+			return fmt.Errorf(
+				"%w: shot target must be <group_1>/<group_2>/<group_3>/<relation>: %q",
+				entity.ErrBadRequest, target,
+			)
+		}
+	}
+	return nil
+}
+
+func (uc *Tag) List(
+	ctx context.Context,
+	params *entity.ListTagsParams,
+) ([]*entity.Tag, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.List(db, params)
+}
+
+func (uc *Tag) Create(
+	ctx context.Context,
+	params *entity.CreateTagParams,
+) (*entity.Tag, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Tag
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Create(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Tag) Update(
+	ctx context.Context,
+	params *entity.UpdateTagParams,
+) (*entity.Tag, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.Tag
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *Tag) Delete(
+	ctx context.Context,
+	params *entity.DeleteTagParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		return uc.repo.Delete(tx, params)
+	})
+}
+
+func (uc *Tag) GetTarget(
+	ctx context.Context,
+	params *entity.GetTagTargetParams,
+) (*entity.TagTarget, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetTarget(db, params)
+}
+
+func (uc *Tag) Attach(
+	ctx context.Context,
+	params *entity.UpdateTagTargetParams,
+) (*entity.TagTarget, error) {
+	return uc.updateTarget(ctx, params, uc.repo.Attach)
+}
+
+func (uc *Tag) Detach(
+	ctx context.Context,
+	params *entity.UpdateTagTargetParams,
+) (*entity.TagTarget, error) {
+	return uc.updateTarget(ctx, params, uc.repo.Detach)
+}
+
+func (uc *Tag) updateTarget(
+	ctx context.Context,
+	params *entity.UpdateTagTargetParams,
+	update func(*gorm.DB, *entity.UpdateTagTargetParams) (*entity.TagTarget, error),
+) (*entity.TagTarget, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.TagTarget
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForProject(tx, params.Project); err != nil {
+			return err
+		}
+		if err := uc.checkForTarget(
+			tx, params.Project, params.TargetType, params.Target,
+		); err != nil {
+			return err
+		}
+		var err error
+		e, err = update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
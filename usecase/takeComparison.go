@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/libs"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// stillExtensions are the image formats contact sheets can be composed of.
+var stillExtensions = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+type TakeComparison struct {
+	sheetRepo   *repository.ContactSheet
+	reviewRepo  *repository.ReviewInfo
+	prjRepo     *repository.ProjectInfo
+	logRepo     *repository.ReviewStatusLog
+	docRepo     entity.DocumentRepository
+	ReadTimeout time.Duration
+}
+
+func NewTakeComparison(
+	sheetRepo *repository.ContactSheet,
+	rr *repository.ReviewInfo,
+	pr *repository.ProjectInfo,
+	lr *repository.ReviewStatusLog,
+	dr entity.DocumentRepository,
+	readTimeout time.Duration,
+) *TakeComparison {
+	return &TakeComparison{
+		sheetRepo:   sheetRepo,
+		reviewRepo:  rr,
+		prjRepo:     pr,
+		logRepo:     lr,
+		docRepo:     dr,
+		ReadTimeout: readTimeout,
+	}
+}
+
+func (uc *TakeComparison) checkForProject(db *gorm.DB, project string) error {
+	_, err := uc.prjRepo.Get(db, &entity.GetProjectInfoParams{
+		KeyName: project,
+	})
+	return err
+}
+
+func isStill(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	for _, e := range stillExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// takeStills returns the frames of the first image sequence of the review data, or its still
+// images when it has no sequence.
+func takeStills(review *entity.ReviewInfo) []string {
+	var stills []string
+	for _, c := range review.ReviewData {
+		if c.IsAttachment || len(c.AllFiles) == 0 || !isStill(c.AllFiles[0].Path) {
+			continue
+		}
+		if c.IsSequence {
+			frames := make([]string, len(c.AllFiles))
+			for i, f := range c.AllFiles {
+				frames[i] = f.Path
+			}
+			sort.Strings(frames)
+			return frames
+		}
+		stills = append(stills, c.AllFiles[0].Path)
+	}
+	sort.Strings(stills)
+	return stills
+}
+
+// matchFrames picks n frames evenly spread over the stills, so that takes of different
+// lengths are compared at the same relative positions.
+func matchFrames(stills []string, n int) []string {
+	if len(stills) <= n {
+		return stills
+	}
+	frames := make([]string, n)
+	for i := range frames {
+		frames[i] = stills[(2*i+1)*len(stills)/(2*n)]
+	}
+	return frames
+}
+
+func (uc *TakeComparison) getTake(
+	db *gorm.DB,
+	project string,
+	id int32,
+	n int,
+) (*entity.ContactSheetTake, error) {
+	review, err := uc.reviewRepo.Get(db, &entity.GetReviewParams{
+		Project: project,
+		ID:      id,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: review info with ID %d", entity.ErrRecordNotFound, id)
+		}
+		return nil, err
+	}
+	stills := takeStills(review)
+	if len(stills) == 0 {
+		return nil, fmt.Errorf(
+			"%w: review info with ID %d has no stills to compare", entity.ErrBadRequest, id,
+		)
+	}
+	return &entity.ContactSheetTake{
+		ReviewInfoID: id,
+		Stills:       stills,
+		Frames:       matchFrames(stills, n),
+	}, nil
+}
+
+// GetContactSheet returns the before/after contact sheet of two takes, composed of matched
+// frames of their review data.
+func (uc *TakeComparison) GetContactSheet(
+	ctx context.Context,
+	params *entity.GetContactSheetParams,
+) (*entity.ContactSheet, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.reviewRepo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	before, err := uc.getTake(db, params.Project, params.Before, params.Frames)
+	if err != nil {
+		return nil, err
+	}
+	after, err := uc.getTake(db, params.Project, params.After, params.Frames)
+	if err != nil {
+		return nil, err
+	}
+	return uc.sheetRepo.Get(params, before, after)
+}
+
+// takeFilePath is the path of a file within its take, with the take name replaced by {take},
+// so that the files of two takes are matched.
+func takeFilePath(review *entity.ReviewInfo, p string) string {
+	if review.TakePath != "" {
+		p = strings.TrimPrefix(p, strings.TrimSuffix(review.TakePath, "/")+"/")
+	}
+	return strings.ReplaceAll(p, review.Take, "{take}")
+}
+
+// compareTake reads a take of the comparison: its reviews, latest first, merged into one, the
+// status logs of its reviews and the publish operations of its revision. It also returns the
+// sizes of the files of the take by their path within it.
+func (uc *TakeComparison) compareTake(
+	ctx context.Context,
+	db *gorm.DB,
+	params *entity.CompareTakesParams,
+	take string,
+) (*entity.ComparedTake, map[string]uint64, error) {
+	reviews, err := uc.reviewRepo.ListTakeReviewInfos(db, &entity.TakeReviewInfoListParams{
+		Project:  params.Project,
+		Asset:    params.Asset,
+		Relation: params.Relation,
+		Phase:    params.Phase,
+		Take:     take,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, nil, fmt.Errorf(
+			"%w: take %s of %s/%s/%s", entity.ErrRecordNotFound,
+			take, params.Asset, params.Relation, params.Phase,
+		)
+	}
+	latest := reviews[0]
+	compared := &entity.ComparedTake{
+		Take:           take,
+		ApprovalStatus: latest.ApprovalStatus,
+		WorkStatus:     latest.WorkStatus,
+		SubmittedAtUTC: latest.SubmittedAtUtc,
+		SubmittedUser:  latest.SubmittedUser,
+		Comments:       []*libs.CommentInfo{},
+		StatusLogs:     []*entity.ReviewStatusLog{},
+	}
+	sizes := map[string]uint64{}
+	for _, review := range reviews {
+		compared.ReviewInfoIDs = append(compared.ReviewInfoIDs, review.ID)
+		for _, component := range append([]string{review.Component}, review.TargetComponents...) {
+			if component != "" && !slices.Contains(compared.Components, component) {
+				compared.Components = append(compared.Components, component)
+			}
+		}
+		for _, f := range review.AllFiles {
+			sizes[takeFilePath(review, f.Path)] = f.Size
+		}
+		compared.Comments = append(compared.Comments, review.ReviewComments...)
+
+		id := review.ID
+		logs, _, err := uc.logRepo.List(db, &entity.ListReviewStatusLogParams{
+			Project:        params.Project,
+			ReviewInfoID:   &id,
+			BaseListParams: &entity.BaseListParams{},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		compared.StatusLogs = append(compared.StatusLogs, logs...)
+	}
+	sort.Strings(compared.Components)
+	sort.SliceStable(compared.StatusLogs, func(i, j int) bool {
+		return compared.StatusLogs[i].CreatedAtUTC.Before(compared.StatusLogs[j].CreatedAtUTC)
+	})
+	compared.NumFiles = len(sizes)
+	for _, size := range sizes {
+		compared.SizeFiles += size
+	}
+
+	publishes, _, err := uc.docRepo.GetDocumentsByFields(
+		ctx, params.Project, "publishOperationInfo", &entity.QueryDocumentsParam{
+			Filters: map[string]interface{}{
+				"root":     "assets",
+				"groups.0": params.Asset,
+				"relation": params.Relation,
+				"phase":    strings.ToLower(params.Phase),
+				"revision": take,
+			},
+		},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list publish operation infos of take %s: %w", take, err)
+	}
+	compared.Publishes = publishes
+	if compared.Publishes == nil {
+		compared.Publishes = []*entity.DocumentInfo{}
+	}
+	return compared, sizes, nil
+}
+
+// CompareTakes returns the diff of two takes of a phase of an asset: their files, sizes,
+// components, comments and statuses, with the publish operations of their revisions.
+func (uc *TakeComparison) CompareTakes(
+	ctx context.Context,
+	params *entity.CompareTakesParams,
+) (*entity.TakeComparison, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.reviewRepo.WithContext(timeoutCtx)
+	if err := uc.checkForProject(db, params.Project); err != nil {
+		return nil, err
+	}
+	from, fromSizes, err := uc.compareTake(timeoutCtx, db, params, params.From)
+	if err != nil {
+		return nil, err
+	}
+	to, toSizes, err := uc.compareTake(timeoutCtx, db, params, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &entity.TakeComparison{
+		Project:           params.Project,
+		Asset:             params.Asset,
+		Relation:          params.Relation,
+		Phase:             params.Phase,
+		From:              from,
+		To:                to,
+		Files:             []*entity.TakeFileChange{},
+		SizeDelta:         int64(to.SizeFiles) - int64(from.SizeFiles),
+		AddedComponents:   []string{},
+		RemovedComponents: []string{},
+		StatusChanges:     []*entity.TakeStatusChange{},
+	}
+	for p, size := range fromSizes {
+		fromSize := size
+		toSize, ok := toSizes[p]
+		switch {
+		case !ok:
+			comparison.Files = append(comparison.Files, &entity.TakeFileChange{
+				Path: p, Change: entity.TakeChangeRemoved, FromSize: &fromSize,
+			})
+		case toSize != size:
+			comparison.Files = append(comparison.Files, &entity.TakeFileChange{
+				Path: p, Change: entity.TakeChangeModified, FromSize: &fromSize, ToSize: &toSize,
+			})
+		}
+	}
+	for p, size := range toSizes {
+		if _, ok := fromSizes[p]; !ok {
+			toSize := size
+			comparison.Files = append(comparison.Files, &entity.TakeFileChange{
+				Path: p, Change: entity.TakeChangeAdded, ToSize: &toSize,
+			})
+		}
+	}
+	sort.Slice(comparison.Files, func(i, j int) bool {
+		return comparison.Files[i].Path < comparison.Files[j].Path
+	})
+	for _, component := range to.Components {
+		if !slices.Contains(from.Components, component) {
+			comparison.AddedComponents = append(comparison.AddedComponents, component)
+		}
+	}
+	for _, component := range from.Components {
+		if !slices.Contains(to.Components, component) {
+			comparison.RemovedComponents = append(comparison.RemovedComponents, component)
+		}
+	}
+	for _, status := range []struct{ statusType, from, to string }{
+		{entity.ReviewStatusTypeApproval, from.ApprovalStatus, to.ApprovalStatus},
+		{entity.ReviewStatusTypeWork, from.WorkStatus, to.WorkStatus},
+	} {
+		if status.from != status.to {
+			comparison.StatusChanges = append(comparison.StatusChanges, &entity.TakeStatusChange{
+				StatusType: status.statusType,
+				From:       status.from,
+				To:         status.to,
+			})
+		}
+	}
+	return comparison, nil
+}
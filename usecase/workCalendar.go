@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type WorkCalendar struct {
+	repo         *repository.WorkCalendar
+	stuRepo      *repository.StudioInfo
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+func NewWorkCalendar(
+	repo *repository.WorkCalendar,
+	sr *repository.StudioInfo,
+	readTimeout time.Duration,
+	writeTimeout time.Duration,
+) *WorkCalendar {
+	return &WorkCalendar{
+		repo:         repo,
+		stuRepo:      sr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func (uc *WorkCalendar) checkForStudio(db *gorm.DB, studio string) error {
+	_, err := uc.stuRepo.Get(db, &entity.GetStudioInfoParams{
+		KeyName: studio,
+	})
+	return err
+}
+
+func (uc *WorkCalendar) List(ctx context.Context) ([]*entity.WorkCalendar, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.List(uc.repo.WithContext(timeoutCtx))
+}
+
+func (uc *WorkCalendar) Get(
+	ctx context.Context,
+	params *entity.GetWorkCalendarParams,
+) (*entity.WorkCalendar, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	return uc.repo.Get(uc.repo.WithContext(timeoutCtx), params)
+}
+
+func (uc *WorkCalendar) Update(
+	ctx context.Context,
+	params *entity.UpdateWorkCalendarParams,
+) (*entity.WorkCalendar, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	// Check that the calendar gives a business clock before storing it.
+	if _, err := entity.NewBusinessClock(&entity.WorkCalendar{
+		TimeZone: params.TimeZone,
+		Weekends: params.Weekends,
+		Holidays: params.Holidays,
+		Shifts:   params.Shifts,
+	}); err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	var e *entity.WorkCalendar
+	if err := uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		if err := uc.checkForStudio(tx, params.Studio); err != nil {
+			return err
+		}
+		var err error
+		e, err = uc.repo.Update(tx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (uc *WorkCalendar) Delete(
+	ctx context.Context,
+	params *entity.DeleteWorkCalendarParams,
+) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.WriteTimeout)
+	defer cancel()
+	return uc.repo.TransactionWithContext(timeoutCtx, func(tx *gorm.DB) error {
+		return uc.repo.Delete(tx, params)
+	})
+}
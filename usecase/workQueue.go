@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PolygonPictures/central30-web/front/entity"
+	"github.com/PolygonPictures/central30-web/front/repository"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// workQueueSourceLimit limits the items read from each source of a work queue.
+const workQueueSourceLimit = 1000
+
+// workItemRanks orders the kinds of the work items from the most urgent.
+var workItemRanks = map[string]int{
+	entity.WorkItemManifestPending: 0,
+	entity.WorkItemRetake:          1,
+	entity.WorkItemAssigned:        2,
+}
+
+type WorkQueue struct {
+	repo        *repository.WorkQueue
+	watcherRepo *repository.Watcher
+	ReadTimeout time.Duration
+}
+
+func NewWorkQueue(
+	repo *repository.WorkQueue,
+	wr *repository.Watcher,
+	readTimeout time.Duration,
+) *WorkQueue {
+	return &WorkQueue{
+		repo:        repo,
+		watcherRepo: wr,
+		ReadTimeout: readTimeout,
+	}
+}
+
+// List returns a page of the work queue of the user and its total: the reviews whose manifest
+// is due, the phases sent back to retake, then the assets and shots the user watches as their
+// submitter or assignee without other work on them. The overdue items come first, then the
+// items by kind, due date and waiting time.
+func (uc *WorkQueue) List(
+	ctx context.Context,
+	params *entity.ListWorkItemsParams,
+) ([]*entity.WorkItem, int, error) {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", entity.ErrBadRequest, err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, uc.ReadTimeout)
+	defer cancel()
+	db := uc.repo.WithContext(timeoutCtx)
+	wants := func(kind string) bool {
+		return params.Kind == nil || *params.Kind == kind
+	}
+
+	var items []*entity.WorkItem
+	// targets with work tied to a review, which are not listed again as assigned
+	busy := map[string]bool{}
+	addItems := func(entities []*entity.WorkItem) {
+		for _, e := range entities {
+			busy[e.Project+"\x00"+e.TargetType+"\x00"+e.Target] = true
+			items = append(items, e)
+		}
+	}
+	if wants(entity.WorkItemManifestPending) || wants(entity.WorkItemAssigned) {
+		entities, err := uc.repo.ListManifestPending(
+			db, params.User, params.Project, workQueueSourceLimit,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		addItems(entities)
+	}
+	if wants(entity.WorkItemRetake) || wants(entity.WorkItemAssigned) {
+		entities, err := uc.repo.ListRetakes(
+			db, params.User, params.Project, workQueueSourceLimit,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		addItems(entities)
+	}
+	if wants(entity.WorkItemAssigned) {
+		watchers, err := uc.watcherRepo.ListOfUser(
+			db,
+			params.User,
+			params.Project,
+			[]string{entity.WatchReasonSubmitter, entity.WatchReasonAssignee},
+			workQueueSourceLimit,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, w := range watchers {
+			if busy[w.Project+"\x00"+w.TargetType+"\x00"+w.Target] {
+				continue
+			}
+			items = append(items, &entity.WorkItem{
+				Kind:       entity.WorkItemAssigned,
+				Project:    w.Project,
+				TargetType: w.TargetType,
+				Target:     w.Target,
+				SinceUTC:   w.CreatedAtUTC,
+			})
+		}
+	}
+	if params.Kind != nil {
+		filtered := items[:0]
+		for _, e := range items {
+			if e.Kind == *params.Kind {
+				filtered = append(filtered, e)
+			}
+		}
+		items = filtered
+	}
+
+	now := time.Now().UTC()
+	for _, e := range items {
+		e.Overdue = e.DueAtUTC != nil && e.DueAtUTC.Before(now)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Overdue != b.Overdue {
+			return a.Overdue
+		}
+		if workItemRanks[a.Kind] != workItemRanks[b.Kind] {
+			return workItemRanks[a.Kind] < workItemRanks[b.Kind]
+		}
+		if (a.DueAtUTC == nil) != (b.DueAtUTC == nil) {
+			return a.DueAtUTC != nil
+		}
+		if a.DueAtUTC != nil && !a.DueAtUTC.Equal(*b.DueAtUTC) {
+			return a.DueAtUTC.Before(*b.DueAtUTC)
+		}
+		return a.SinceUTC.Before(b.SinceUTC)
+	})
+
+	total := len(items)
+	perPage := params.GetPerPage()
+	offset := perPage * (params.GetPage() - 1)
+	if offset > total {
+		offset = total
+	}
+	end := offset + perPage
+	if end > total {
+		end = total
+	}
+	return items[offset:end], total, nil
+}